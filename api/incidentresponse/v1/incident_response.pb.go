@@ -0,0 +1,1109 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: incidentresponse/v1/incident_response.proto
+
+package incidentresponsev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type IncidentChange_ChangeType int32
+
+const (
+	IncidentChange_CHANGE_TYPE_UNSPECIFIED IncidentChange_ChangeType = 0
+	IncidentChange_CHANGE_TYPE_CREATED     IncidentChange_ChangeType = 1
+	IncidentChange_CHANGE_TYPE_UPDATED     IncidentChange_ChangeType = 2
+)
+
+// Enum value maps for IncidentChange_ChangeType.
+var (
+	IncidentChange_ChangeType_name = map[int32]string{
+		0: "CHANGE_TYPE_UNSPECIFIED",
+		1: "CHANGE_TYPE_CREATED",
+		2: "CHANGE_TYPE_UPDATED",
+	}
+	IncidentChange_ChangeType_value = map[string]int32{
+		"CHANGE_TYPE_UNSPECIFIED": 0,
+		"CHANGE_TYPE_CREATED":     1,
+		"CHANGE_TYPE_UPDATED":     2,
+	}
+)
+
+func (x IncidentChange_ChangeType) Enum() *IncidentChange_ChangeType {
+	p := new(IncidentChange_ChangeType)
+	*p = x
+	return p
+}
+
+func (x IncidentChange_ChangeType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (IncidentChange_ChangeType) Descriptor() protoreflect.EnumDescriptor {
+	return file_incidentresponse_v1_incident_response_proto_enumTypes[0].Descriptor()
+}
+
+func (IncidentChange_ChangeType) Type() protoreflect.EnumType {
+	return &file_incidentresponse_v1_incident_response_proto_enumTypes[0]
+}
+
+func (x IncidentChange_ChangeType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use IncidentChange_ChangeType.Descriptor instead.
+func (IncidentChange_ChangeType) EnumDescriptor() ([]byte, []int) {
+	return file_incidentresponse_v1_incident_response_proto_rawDescGZIP(), []int{9, 0}
+}
+
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventId       string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Source        string                 `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	EventType     string                 `protobuf:"bytes,4,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	Severity      string                 `protobuf:"bytes,5,opt,name=severity,proto3" json:"severity,omitempty"`
+	RawData       *structpb.Struct       `protobuf:"bytes,6,opt,name=raw_data,json=rawData,proto3" json:"raw_data,omitempty"`
+	Normalized    *structpb.Struct       `protobuf:"bytes,7,opt,name=normalized,proto3" json:"normalized,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ProcessedAt   *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=processed_at,json=processedAt,proto3" json:"processed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_incidentresponse_v1_incident_response_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Event) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *Event) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Event) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Event) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *Event) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *Event) GetRawData() *structpb.Struct {
+	if x != nil {
+		return x.RawData
+	}
+	return nil
+}
+
+func (x *Event) GetNormalized() *structpb.Struct {
+	if x != nil {
+		return x.Normalized
+	}
+	return nil
+}
+
+func (x *Event) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Event) GetProcessedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ProcessedAt
+	}
+	return nil
+}
+
+type Incident struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	IncidentId      string                 `protobuf:"bytes,1,opt,name=incident_id,json=incidentId,proto3" json:"incident_id,omitempty"`
+	CreatedAt       *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Status          string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Severity        string                 `protobuf:"bytes,5,opt,name=severity,proto3" json:"severity,omitempty"`
+	Category        string                 `protobuf:"bytes,6,opt,name=category,proto3" json:"category,omitempty"`
+	Title           string                 `protobuf:"bytes,7,opt,name=title,proto3" json:"title,omitempty"`
+	Description     string                 `protobuf:"bytes,8,opt,name=description,proto3" json:"description,omitempty"`
+	TriggeredByRule string                 `protobuf:"bytes,9,opt,name=triggered_by_rule,json=triggeredByRule,proto3" json:"triggered_by_rule,omitempty"`
+	RelatedEvents   []string               `protobuf:"bytes,10,rep,name=related_events,json=relatedEvents,proto3" json:"related_events,omitempty"`
+	AssignedTo      string                 `protobuf:"bytes,11,opt,name=assigned_to,json=assignedTo,proto3" json:"assigned_to,omitempty"`
+	Notes           string                 `protobuf:"bytes,12,opt,name=notes,proto3" json:"notes,omitempty"`
+	CorrelationKey  string                 `protobuf:"bytes,13,opt,name=correlation_key,json=correlationKey,proto3" json:"correlation_key,omitempty"`
+	RunbookId       string                 `protobuf:"bytes,14,opt,name=runbook_id,json=runbookId,proto3" json:"runbook_id,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Incident) Reset() {
+	*x = Incident{}
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Incident) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Incident) ProtoMessage() {}
+
+func (x *Incident) ProtoReflect() protoreflect.Message {
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Incident.ProtoReflect.Descriptor instead.
+func (*Incident) Descriptor() ([]byte, []int) {
+	return file_incidentresponse_v1_incident_response_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Incident) GetIncidentId() string {
+	if x != nil {
+		return x.IncidentId
+	}
+	return ""
+}
+
+func (x *Incident) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Incident) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Incident) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Incident) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *Incident) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Incident) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Incident) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Incident) GetTriggeredByRule() string {
+	if x != nil {
+		return x.TriggeredByRule
+	}
+	return ""
+}
+
+func (x *Incident) GetRelatedEvents() []string {
+	if x != nil {
+		return x.RelatedEvents
+	}
+	return nil
+}
+
+func (x *Incident) GetAssignedTo() string {
+	if x != nil {
+		return x.AssignedTo
+	}
+	return ""
+}
+
+func (x *Incident) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *Incident) GetCorrelationKey() string {
+	if x != nil {
+		return x.CorrelationKey
+	}
+	return ""
+}
+
+func (x *Incident) GetRunbookId() string {
+	if x != nil {
+		return x.RunbookId
+	}
+	return ""
+}
+
+type PlaybookRun struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RunId         string                 `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	PlaybookId    string                 `protobuf:"bytes,2,opt,name=playbook_id,json=playbookId,proto3" json:"playbook_id,omitempty"`
+	IncidentId    string                 `protobuf:"bytes,3,opt,name=incident_id,json=incidentId,proto3" json:"incident_id,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	CompletedAt   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlaybookRun) Reset() {
+	*x = PlaybookRun{}
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaybookRun) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaybookRun) ProtoMessage() {}
+
+func (x *PlaybookRun) ProtoReflect() protoreflect.Message {
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaybookRun.ProtoReflect.Descriptor instead.
+func (*PlaybookRun) Descriptor() ([]byte, []int) {
+	return file_incidentresponse_v1_incident_response_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PlaybookRun) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *PlaybookRun) GetPlaybookId() string {
+	if x != nil {
+		return x.PlaybookId
+	}
+	return ""
+}
+
+func (x *PlaybookRun) GetIncidentId() string {
+	if x != nil {
+		return x.IncidentId
+	}
+	return ""
+}
+
+func (x *PlaybookRun) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *PlaybookRun) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *PlaybookRun) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *PlaybookRun) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+type IngestEventRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventType     string                 `protobuf:"bytes,1,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Severity      string                 `protobuf:"bytes,3,opt,name=severity,proto3" json:"severity,omitempty"`
+	RawData       *structpb.Struct       `protobuf:"bytes,4,opt,name=raw_data,json=rawData,proto3" json:"raw_data,omitempty"`
+	Normalized    *structpb.Struct       `protobuf:"bytes,5,opt,name=normalized,proto3" json:"normalized,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IngestEventRequest) Reset() {
+	*x = IngestEventRequest{}
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IngestEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestEventRequest) ProtoMessage() {}
+
+func (x *IngestEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestEventRequest.ProtoReflect.Descriptor instead.
+func (*IngestEventRequest) Descriptor() ([]byte, []int) {
+	return file_incidentresponse_v1_incident_response_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *IngestEventRequest) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *IngestEventRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *IngestEventRequest) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *IngestEventRequest) GetRawData() *structpb.Struct {
+	if x != nil {
+		return x.RawData
+	}
+	return nil
+}
+
+func (x *IngestEventRequest) GetNormalized() *structpb.Struct {
+	if x != nil {
+		return x.Normalized
+	}
+	return nil
+}
+
+type IngestEventsSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Accepted      int64                  `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	Rejected      int64                  `protobuf:"varint,2,opt,name=rejected,proto3" json:"rejected,omitempty"`
+	Errors        []string               `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IngestEventsSummary) Reset() {
+	*x = IngestEventsSummary{}
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IngestEventsSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestEventsSummary) ProtoMessage() {}
+
+func (x *IngestEventsSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestEventsSummary.ProtoReflect.Descriptor instead.
+func (*IngestEventsSummary) Descriptor() ([]byte, []int) {
+	return file_incidentresponse_v1_incident_response_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *IngestEventsSummary) GetAccepted() int64 {
+	if x != nil {
+		return x.Accepted
+	}
+	return 0
+}
+
+func (x *IngestEventsSummary) GetRejected() int64 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+func (x *IngestEventsSummary) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type GetIncidentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IncidentId    string                 `protobuf:"bytes,1,opt,name=incident_id,json=incidentId,proto3" json:"incident_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetIncidentRequest) Reset() {
+	*x = GetIncidentRequest{}
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetIncidentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetIncidentRequest) ProtoMessage() {}
+
+func (x *GetIncidentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetIncidentRequest.ProtoReflect.Descriptor instead.
+func (*GetIncidentRequest) Descriptor() ([]byte, []int) {
+	return file_incidentresponse_v1_incident_response_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetIncidentRequest) GetIncidentId() string {
+	if x != nil {
+		return x.IncidentId
+	}
+	return ""
+}
+
+type ListIncidentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Severity      string                 `protobuf:"bytes,2,opt,name=severity,proto3" json:"severity,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListIncidentsRequest) Reset() {
+	*x = ListIncidentsRequest{}
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListIncidentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListIncidentsRequest) ProtoMessage() {}
+
+func (x *ListIncidentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListIncidentsRequest.ProtoReflect.Descriptor instead.
+func (*ListIncidentsRequest) Descriptor() ([]byte, []int) {
+	return file_incidentresponse_v1_incident_response_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListIncidentsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListIncidentsRequest) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *ListIncidentsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListIncidentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Incidents     []*Incident            `protobuf:"bytes,1,rep,name=incidents,proto3" json:"incidents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListIncidentsResponse) Reset() {
+	*x = ListIncidentsResponse{}
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListIncidentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListIncidentsResponse) ProtoMessage() {}
+
+func (x *ListIncidentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListIncidentsResponse.ProtoReflect.Descriptor instead.
+func (*ListIncidentsResponse) Descriptor() ([]byte, []int) {
+	return file_incidentresponse_v1_incident_response_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListIncidentsResponse) GetIncidents() []*Incident {
+	if x != nil {
+		return x.Incidents
+	}
+	return nil
+}
+
+type WatchIncidentsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only incidents changed after this time are sent; defaults to now.
+	Since *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=since,proto3" json:"since,omitempty"`
+	// Optional severity filter.
+	Severities    []string `protobuf:"bytes,2,rep,name=severities,proto3" json:"severities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchIncidentsRequest) Reset() {
+	*x = WatchIncidentsRequest{}
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchIncidentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchIncidentsRequest) ProtoMessage() {}
+
+func (x *WatchIncidentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchIncidentsRequest.ProtoReflect.Descriptor instead.
+func (*WatchIncidentsRequest) Descriptor() ([]byte, []int) {
+	return file_incidentresponse_v1_incident_response_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WatchIncidentsRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *WatchIncidentsRequest) GetSeverities() []string {
+	if x != nil {
+		return x.Severities
+	}
+	return nil
+}
+
+type IncidentChange struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Type          IncidentChange_ChangeType `protobuf:"varint,1,opt,name=type,proto3,enum=incidentresponse.v1.IncidentChange_ChangeType" json:"type,omitempty"`
+	Incident      *Incident                 `protobuf:"bytes,2,opt,name=incident,proto3" json:"incident,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IncidentChange) Reset() {
+	*x = IncidentChange{}
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IncidentChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncidentChange) ProtoMessage() {}
+
+func (x *IncidentChange) ProtoReflect() protoreflect.Message {
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncidentChange.ProtoReflect.Descriptor instead.
+func (*IncidentChange) Descriptor() ([]byte, []int) {
+	return file_incidentresponse_v1_incident_response_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *IncidentChange) GetType() IncidentChange_ChangeType {
+	if x != nil {
+		return x.Type
+	}
+	return IncidentChange_CHANGE_TYPE_UNSPECIFIED
+}
+
+func (x *IncidentChange) GetIncident() *Incident {
+	if x != nil {
+		return x.Incident
+	}
+	return nil
+}
+
+type ListPlaybookRunsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IncidentId    string                 `protobuf:"bytes,1,opt,name=incident_id,json=incidentId,proto3" json:"incident_id,omitempty"`
+	PlaybookId    string                 `protobuf:"bytes,2,opt,name=playbook_id,json=playbookId,proto3" json:"playbook_id,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPlaybookRunsRequest) Reset() {
+	*x = ListPlaybookRunsRequest{}
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPlaybookRunsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPlaybookRunsRequest) ProtoMessage() {}
+
+func (x *ListPlaybookRunsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPlaybookRunsRequest.ProtoReflect.Descriptor instead.
+func (*ListPlaybookRunsRequest) Descriptor() ([]byte, []int) {
+	return file_incidentresponse_v1_incident_response_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListPlaybookRunsRequest) GetIncidentId() string {
+	if x != nil {
+		return x.IncidentId
+	}
+	return ""
+}
+
+func (x *ListPlaybookRunsRequest) GetPlaybookId() string {
+	if x != nil {
+		return x.PlaybookId
+	}
+	return ""
+}
+
+func (x *ListPlaybookRunsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListPlaybookRunsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Runs          []*PlaybookRun         `protobuf:"bytes,1,rep,name=runs,proto3" json:"runs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPlaybookRunsResponse) Reset() {
+	*x = ListPlaybookRunsResponse{}
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPlaybookRunsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPlaybookRunsResponse) ProtoMessage() {}
+
+func (x *ListPlaybookRunsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_incidentresponse_v1_incident_response_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPlaybookRunsResponse.ProtoReflect.Descriptor instead.
+func (*ListPlaybookRunsResponse) Descriptor() ([]byte, []int) {
+	return file_incidentresponse_v1_incident_response_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListPlaybookRunsResponse) GetRuns() []*PlaybookRun {
+	if x != nil {
+		return x.Runs
+	}
+	return nil
+}
+
+var File_incidentresponse_v1_incident_response_proto protoreflect.FileDescriptor
+
+const file_incidentresponse_v1_incident_response_proto_rawDesc = "" +
+	"\n" +
+	"+incidentresponse/v1/incident_response.proto\x12\x13incidentresponse.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x96\x03\n" +
+	"\x05Event\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x128\n" +
+	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x04 \x01(\tR\teventType\x12\x1a\n" +
+	"\bseverity\x18\x05 \x01(\tR\bseverity\x122\n" +
+	"\braw_data\x18\x06 \x01(\v2\x17.google.protobuf.StructR\arawData\x127\n" +
+	"\n" +
+	"normalized\x18\a \x01(\v2\x17.google.protobuf.StructR\n" +
+	"normalized\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12=\n" +
+	"\fprocessed_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\vprocessedAt\"\xfb\x03\n" +
+	"\bIncident\x12\x1f\n" +
+	"\vincident_id\x18\x01 \x01(\tR\n" +
+	"incidentId\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x1a\n" +
+	"\bseverity\x18\x05 \x01(\tR\bseverity\x12\x1a\n" +
+	"\bcategory\x18\x06 \x01(\tR\bcategory\x12\x14\n" +
+	"\x05title\x18\a \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\b \x01(\tR\vdescription\x12*\n" +
+	"\x11triggered_by_rule\x18\t \x01(\tR\x0ftriggeredByRule\x12%\n" +
+	"\x0erelated_events\x18\n" +
+	" \x03(\tR\rrelatedEvents\x12\x1f\n" +
+	"\vassigned_to\x18\v \x01(\tR\n" +
+	"assignedTo\x12\x14\n" +
+	"\x05notes\x18\f \x01(\tR\x05notes\x12'\n" +
+	"\x0fcorrelation_key\x18\r \x01(\tR\x0ecorrelationKey\x12\x1d\n" +
+	"\n" +
+	"runbook_id\x18\x0e \x01(\tR\trunbookId\"\x8e\x02\n" +
+	"\vPlaybookRun\x12\x15\n" +
+	"\x06run_id\x18\x01 \x01(\tR\x05runId\x12\x1f\n" +
+	"\vplaybook_id\x18\x02 \x01(\tR\n" +
+	"playbookId\x12\x1f\n" +
+	"\vincident_id\x18\x03 \x01(\tR\n" +
+	"incidentId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x129\n" +
+	"\n" +
+	"started_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12=\n" +
+	"\fcompleted_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\"\xd4\x01\n" +
+	"\x12IngestEventRequest\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x01 \x01(\tR\teventType\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x1a\n" +
+	"\bseverity\x18\x03 \x01(\tR\bseverity\x122\n" +
+	"\braw_data\x18\x04 \x01(\v2\x17.google.protobuf.StructR\arawData\x127\n" +
+	"\n" +
+	"normalized\x18\x05 \x01(\v2\x17.google.protobuf.StructR\n" +
+	"normalized\"e\n" +
+	"\x13IngestEventsSummary\x12\x1a\n" +
+	"\baccepted\x18\x01 \x01(\x03R\baccepted\x12\x1a\n" +
+	"\brejected\x18\x02 \x01(\x03R\brejected\x12\x16\n" +
+	"\x06errors\x18\x03 \x03(\tR\x06errors\"5\n" +
+	"\x12GetIncidentRequest\x12\x1f\n" +
+	"\vincident_id\x18\x01 \x01(\tR\n" +
+	"incidentId\"`\n" +
+	"\x14ListIncidentsRequest\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x1a\n" +
+	"\bseverity\x18\x02 \x01(\tR\bseverity\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"T\n" +
+	"\x15ListIncidentsResponse\x12;\n" +
+	"\tincidents\x18\x01 \x03(\v2\x1d.incidentresponse.v1.IncidentR\tincidents\"i\n" +
+	"\x15WatchIncidentsRequest\x120\n" +
+	"\x05since\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x12\x1e\n" +
+	"\n" +
+	"severities\x18\x02 \x03(\tR\n" +
+	"severities\"\xec\x01\n" +
+	"\x0eIncidentChange\x12B\n" +
+	"\x04type\x18\x01 \x01(\x0e2..incidentresponse.v1.IncidentChange.ChangeTypeR\x04type\x129\n" +
+	"\bincident\x18\x02 \x01(\v2\x1d.incidentresponse.v1.IncidentR\bincident\"[\n" +
+	"\n" +
+	"ChangeType\x12\x1b\n" +
+	"\x17CHANGE_TYPE_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13CHANGE_TYPE_CREATED\x10\x01\x12\x17\n" +
+	"\x13CHANGE_TYPE_UPDATED\x10\x02\"q\n" +
+	"\x17ListPlaybookRunsRequest\x12\x1f\n" +
+	"\vincident_id\x18\x01 \x01(\tR\n" +
+	"incidentId\x12\x1f\n" +
+	"\vplaybook_id\x18\x02 \x01(\tR\n" +
+	"playbookId\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"P\n" +
+	"\x18ListPlaybookRunsResponse\x124\n" +
+	"\x04runs\x18\x01 \x03(\v2 .incidentresponse.v1.PlaybookRunR\x04runs2\xe0\x04\n" +
+	"\x10IncidentResponse\x12R\n" +
+	"\vCreateEvent\x12'.incidentresponse.v1.IngestEventRequest\x1a\x1a.incidentresponse.v1.Event\x12c\n" +
+	"\fIngestEvents\x12'.incidentresponse.v1.IngestEventRequest\x1a(.incidentresponse.v1.IngestEventsSummary(\x01\x12U\n" +
+	"\vGetIncident\x12'.incidentresponse.v1.GetIncidentRequest\x1a\x1d.incidentresponse.v1.Incident\x12f\n" +
+	"\rListIncidents\x12).incidentresponse.v1.ListIncidentsRequest\x1a*.incidentresponse.v1.ListIncidentsResponse\x12c\n" +
+	"\x0eWatchIncidents\x12*.incidentresponse.v1.WatchIncidentsRequest\x1a#.incidentresponse.v1.IncidentChange0\x01\x12o\n" +
+	"\x10ListPlaybookRuns\x12,.incidentresponse.v1.ListPlaybookRunsRequest\x1a-.incidentresponse.v1.ListPlaybookRunsResponseBYZWgithub.com/gixxerblade/incident-response-mvp/api/incidentresponse/v1;incidentresponsev1b\x06proto3"
+
+var (
+	file_incidentresponse_v1_incident_response_proto_rawDescOnce sync.Once
+	file_incidentresponse_v1_incident_response_proto_rawDescData []byte
+)
+
+func file_incidentresponse_v1_incident_response_proto_rawDescGZIP() []byte {
+	file_incidentresponse_v1_incident_response_proto_rawDescOnce.Do(func() {
+		file_incidentresponse_v1_incident_response_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_incidentresponse_v1_incident_response_proto_rawDesc), len(file_incidentresponse_v1_incident_response_proto_rawDesc)))
+	})
+	return file_incidentresponse_v1_incident_response_proto_rawDescData
+}
+
+var file_incidentresponse_v1_incident_response_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_incidentresponse_v1_incident_response_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_incidentresponse_v1_incident_response_proto_goTypes = []any{
+	(IncidentChange_ChangeType)(0),   // 0: incidentresponse.v1.IncidentChange.ChangeType
+	(*Event)(nil),                    // 1: incidentresponse.v1.Event
+	(*Incident)(nil),                 // 2: incidentresponse.v1.Incident
+	(*PlaybookRun)(nil),              // 3: incidentresponse.v1.PlaybookRun
+	(*IngestEventRequest)(nil),       // 4: incidentresponse.v1.IngestEventRequest
+	(*IngestEventsSummary)(nil),      // 5: incidentresponse.v1.IngestEventsSummary
+	(*GetIncidentRequest)(nil),       // 6: incidentresponse.v1.GetIncidentRequest
+	(*ListIncidentsRequest)(nil),     // 7: incidentresponse.v1.ListIncidentsRequest
+	(*ListIncidentsResponse)(nil),    // 8: incidentresponse.v1.ListIncidentsResponse
+	(*WatchIncidentsRequest)(nil),    // 9: incidentresponse.v1.WatchIncidentsRequest
+	(*IncidentChange)(nil),           // 10: incidentresponse.v1.IncidentChange
+	(*ListPlaybookRunsRequest)(nil),  // 11: incidentresponse.v1.ListPlaybookRunsRequest
+	(*ListPlaybookRunsResponse)(nil), // 12: incidentresponse.v1.ListPlaybookRunsResponse
+	(*timestamppb.Timestamp)(nil),    // 13: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),          // 14: google.protobuf.Struct
+}
+var file_incidentresponse_v1_incident_response_proto_depIdxs = []int32{
+	13, // 0: incidentresponse.v1.Event.timestamp:type_name -> google.protobuf.Timestamp
+	14, // 1: incidentresponse.v1.Event.raw_data:type_name -> google.protobuf.Struct
+	14, // 2: incidentresponse.v1.Event.normalized:type_name -> google.protobuf.Struct
+	13, // 3: incidentresponse.v1.Event.created_at:type_name -> google.protobuf.Timestamp
+	13, // 4: incidentresponse.v1.Event.processed_at:type_name -> google.protobuf.Timestamp
+	13, // 5: incidentresponse.v1.Incident.created_at:type_name -> google.protobuf.Timestamp
+	13, // 6: incidentresponse.v1.Incident.updated_at:type_name -> google.protobuf.Timestamp
+	13, // 7: incidentresponse.v1.PlaybookRun.started_at:type_name -> google.protobuf.Timestamp
+	13, // 8: incidentresponse.v1.PlaybookRun.completed_at:type_name -> google.protobuf.Timestamp
+	14, // 9: incidentresponse.v1.IngestEventRequest.raw_data:type_name -> google.protobuf.Struct
+	14, // 10: incidentresponse.v1.IngestEventRequest.normalized:type_name -> google.protobuf.Struct
+	2,  // 11: incidentresponse.v1.ListIncidentsResponse.incidents:type_name -> incidentresponse.v1.Incident
+	13, // 12: incidentresponse.v1.WatchIncidentsRequest.since:type_name -> google.protobuf.Timestamp
+	0,  // 13: incidentresponse.v1.IncidentChange.type:type_name -> incidentresponse.v1.IncidentChange.ChangeType
+	2,  // 14: incidentresponse.v1.IncidentChange.incident:type_name -> incidentresponse.v1.Incident
+	3,  // 15: incidentresponse.v1.ListPlaybookRunsResponse.runs:type_name -> incidentresponse.v1.PlaybookRun
+	4,  // 16: incidentresponse.v1.IncidentResponse.CreateEvent:input_type -> incidentresponse.v1.IngestEventRequest
+	4,  // 17: incidentresponse.v1.IncidentResponse.IngestEvents:input_type -> incidentresponse.v1.IngestEventRequest
+	6,  // 18: incidentresponse.v1.IncidentResponse.GetIncident:input_type -> incidentresponse.v1.GetIncidentRequest
+	7,  // 19: incidentresponse.v1.IncidentResponse.ListIncidents:input_type -> incidentresponse.v1.ListIncidentsRequest
+	9,  // 20: incidentresponse.v1.IncidentResponse.WatchIncidents:input_type -> incidentresponse.v1.WatchIncidentsRequest
+	11, // 21: incidentresponse.v1.IncidentResponse.ListPlaybookRuns:input_type -> incidentresponse.v1.ListPlaybookRunsRequest
+	1,  // 22: incidentresponse.v1.IncidentResponse.CreateEvent:output_type -> incidentresponse.v1.Event
+	5,  // 23: incidentresponse.v1.IncidentResponse.IngestEvents:output_type -> incidentresponse.v1.IngestEventsSummary
+	2,  // 24: incidentresponse.v1.IncidentResponse.GetIncident:output_type -> incidentresponse.v1.Incident
+	8,  // 25: incidentresponse.v1.IncidentResponse.ListIncidents:output_type -> incidentresponse.v1.ListIncidentsResponse
+	10, // 26: incidentresponse.v1.IncidentResponse.WatchIncidents:output_type -> incidentresponse.v1.IncidentChange
+	12, // 27: incidentresponse.v1.IncidentResponse.ListPlaybookRuns:output_type -> incidentresponse.v1.ListPlaybookRunsResponse
+	22, // [22:28] is the sub-list for method output_type
+	16, // [16:22] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
+}
+
+func init() { file_incidentresponse_v1_incident_response_proto_init() }
+func file_incidentresponse_v1_incident_response_proto_init() {
+	if File_incidentresponse_v1_incident_response_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_incidentresponse_v1_incident_response_proto_rawDesc), len(file_incidentresponse_v1_incident_response_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_incidentresponse_v1_incident_response_proto_goTypes,
+		DependencyIndexes: file_incidentresponse_v1_incident_response_proto_depIdxs,
+		EnumInfos:         file_incidentresponse_v1_incident_response_proto_enumTypes,
+		MessageInfos:      file_incidentresponse_v1_incident_response_proto_msgTypes,
+	}.Build()
+	File_incidentresponse_v1_incident_response_proto = out.File
+	file_incidentresponse_v1_incident_response_proto_goTypes = nil
+	file_incidentresponse_v1_incident_response_proto_depIdxs = nil
+}
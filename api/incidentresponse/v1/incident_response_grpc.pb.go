@@ -0,0 +1,328 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: incidentresponse/v1/incident_response.proto
+
+package incidentresponsev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	IncidentResponse_CreateEvent_FullMethodName      = "/incidentresponse.v1.IncidentResponse/CreateEvent"
+	IncidentResponse_IngestEvents_FullMethodName     = "/incidentresponse.v1.IncidentResponse/IngestEvents"
+	IncidentResponse_GetIncident_FullMethodName      = "/incidentresponse.v1.IncidentResponse/GetIncident"
+	IncidentResponse_ListIncidents_FullMethodName    = "/incidentresponse.v1.IncidentResponse/ListIncidents"
+	IncidentResponse_WatchIncidents_FullMethodName   = "/incidentresponse.v1.IncidentResponse/WatchIncidents"
+	IncidentResponse_ListPlaybookRuns_FullMethodName = "/incidentresponse.v1.IncidentResponse/ListPlaybookRuns"
+)
+
+// IncidentResponseClient is the client API for IncidentResponse service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// IncidentResponse is the gRPC counterpart of the REST API, aimed at agents
+// and high-volume forwarders.
+type IncidentResponseClient interface {
+	// CreateEvent ingests a single event.
+	CreateEvent(ctx context.Context, in *IngestEventRequest, opts ...grpc.CallOption) (*Event, error)
+	// IngestEvents ingests a client stream of events and returns a summary
+	// once the client closes the stream.
+	IngestEvents(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[IngestEventRequest, IngestEventsSummary], error)
+	// GetIncident returns one incident by ID.
+	GetIncident(ctx context.Context, in *GetIncidentRequest, opts ...grpc.CallOption) (*Incident, error)
+	// ListIncidents returns incidents, newest first.
+	ListIncidents(ctx context.Context, in *ListIncidentsRequest, opts ...grpc.CallOption) (*ListIncidentsResponse, error)
+	// WatchIncidents streams incidents as they are created or updated.
+	WatchIncidents(ctx context.Context, in *WatchIncidentsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[IncidentChange], error)
+	// ListPlaybookRuns returns playbook runs, newest first.
+	ListPlaybookRuns(ctx context.Context, in *ListPlaybookRunsRequest, opts ...grpc.CallOption) (*ListPlaybookRunsResponse, error)
+}
+
+type incidentResponseClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIncidentResponseClient(cc grpc.ClientConnInterface) IncidentResponseClient {
+	return &incidentResponseClient{cc}
+}
+
+func (c *incidentResponseClient) CreateEvent(ctx context.Context, in *IngestEventRequest, opts ...grpc.CallOption) (*Event, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Event)
+	err := c.cc.Invoke(ctx, IncidentResponse_CreateEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *incidentResponseClient) IngestEvents(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[IngestEventRequest, IngestEventsSummary], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &IncidentResponse_ServiceDesc.Streams[0], IncidentResponse_IngestEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[IngestEventRequest, IngestEventsSummary]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IncidentResponse_IngestEventsClient = grpc.ClientStreamingClient[IngestEventRequest, IngestEventsSummary]
+
+func (c *incidentResponseClient) GetIncident(ctx context.Context, in *GetIncidentRequest, opts ...grpc.CallOption) (*Incident, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Incident)
+	err := c.cc.Invoke(ctx, IncidentResponse_GetIncident_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *incidentResponseClient) ListIncidents(ctx context.Context, in *ListIncidentsRequest, opts ...grpc.CallOption) (*ListIncidentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListIncidentsResponse)
+	err := c.cc.Invoke(ctx, IncidentResponse_ListIncidents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *incidentResponseClient) WatchIncidents(ctx context.Context, in *WatchIncidentsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[IncidentChange], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &IncidentResponse_ServiceDesc.Streams[1], IncidentResponse_WatchIncidents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchIncidentsRequest, IncidentChange]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IncidentResponse_WatchIncidentsClient = grpc.ServerStreamingClient[IncidentChange]
+
+func (c *incidentResponseClient) ListPlaybookRuns(ctx context.Context, in *ListPlaybookRunsRequest, opts ...grpc.CallOption) (*ListPlaybookRunsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPlaybookRunsResponse)
+	err := c.cc.Invoke(ctx, IncidentResponse_ListPlaybookRuns_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IncidentResponseServer is the server API for IncidentResponse service.
+// All implementations must embed UnimplementedIncidentResponseServer
+// for forward compatibility.
+//
+// IncidentResponse is the gRPC counterpart of the REST API, aimed at agents
+// and high-volume forwarders.
+type IncidentResponseServer interface {
+	// CreateEvent ingests a single event.
+	CreateEvent(context.Context, *IngestEventRequest) (*Event, error)
+	// IngestEvents ingests a client stream of events and returns a summary
+	// once the client closes the stream.
+	IngestEvents(grpc.ClientStreamingServer[IngestEventRequest, IngestEventsSummary]) error
+	// GetIncident returns one incident by ID.
+	GetIncident(context.Context, *GetIncidentRequest) (*Incident, error)
+	// ListIncidents returns incidents, newest first.
+	ListIncidents(context.Context, *ListIncidentsRequest) (*ListIncidentsResponse, error)
+	// WatchIncidents streams incidents as they are created or updated.
+	WatchIncidents(*WatchIncidentsRequest, grpc.ServerStreamingServer[IncidentChange]) error
+	// ListPlaybookRuns returns playbook runs, newest first.
+	ListPlaybookRuns(context.Context, *ListPlaybookRunsRequest) (*ListPlaybookRunsResponse, error)
+	mustEmbedUnimplementedIncidentResponseServer()
+}
+
+// UnimplementedIncidentResponseServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedIncidentResponseServer struct{}
+
+func (UnimplementedIncidentResponseServer) CreateEvent(context.Context, *IngestEventRequest) (*Event, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateEvent not implemented")
+}
+func (UnimplementedIncidentResponseServer) IngestEvents(grpc.ClientStreamingServer[IngestEventRequest, IngestEventsSummary]) error {
+	return status.Errorf(codes.Unimplemented, "method IngestEvents not implemented")
+}
+func (UnimplementedIncidentResponseServer) GetIncident(context.Context, *GetIncidentRequest) (*Incident, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetIncident not implemented")
+}
+func (UnimplementedIncidentResponseServer) ListIncidents(context.Context, *ListIncidentsRequest) (*ListIncidentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListIncidents not implemented")
+}
+func (UnimplementedIncidentResponseServer) WatchIncidents(*WatchIncidentsRequest, grpc.ServerStreamingServer[IncidentChange]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchIncidents not implemented")
+}
+func (UnimplementedIncidentResponseServer) ListPlaybookRuns(context.Context, *ListPlaybookRunsRequest) (*ListPlaybookRunsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPlaybookRuns not implemented")
+}
+func (UnimplementedIncidentResponseServer) mustEmbedUnimplementedIncidentResponseServer() {}
+func (UnimplementedIncidentResponseServer) testEmbeddedByValue()                          {}
+
+// UnsafeIncidentResponseServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IncidentResponseServer will
+// result in compilation errors.
+type UnsafeIncidentResponseServer interface {
+	mustEmbedUnimplementedIncidentResponseServer()
+}
+
+func RegisterIncidentResponseServer(s grpc.ServiceRegistrar, srv IncidentResponseServer) {
+	// If the following call pancis, it indicates UnimplementedIncidentResponseServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&IncidentResponse_ServiceDesc, srv)
+}
+
+func _IncidentResponse_CreateEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IngestEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IncidentResponseServer).CreateEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IncidentResponse_CreateEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IncidentResponseServer).CreateEvent(ctx, req.(*IngestEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IncidentResponse_IngestEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IncidentResponseServer).IngestEvents(&grpc.GenericServerStream[IngestEventRequest, IngestEventsSummary]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IncidentResponse_IngestEventsServer = grpc.ClientStreamingServer[IngestEventRequest, IngestEventsSummary]
+
+func _IncidentResponse_GetIncident_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetIncidentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IncidentResponseServer).GetIncident(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IncidentResponse_GetIncident_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IncidentResponseServer).GetIncident(ctx, req.(*GetIncidentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IncidentResponse_ListIncidents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListIncidentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IncidentResponseServer).ListIncidents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IncidentResponse_ListIncidents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IncidentResponseServer).ListIncidents(ctx, req.(*ListIncidentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IncidentResponse_WatchIncidents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchIncidentsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IncidentResponseServer).WatchIncidents(m, &grpc.GenericServerStream[WatchIncidentsRequest, IncidentChange]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IncidentResponse_WatchIncidentsServer = grpc.ServerStreamingServer[IncidentChange]
+
+func _IncidentResponse_ListPlaybookRuns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPlaybookRunsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IncidentResponseServer).ListPlaybookRuns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IncidentResponse_ListPlaybookRuns_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IncidentResponseServer).ListPlaybookRuns(ctx, req.(*ListPlaybookRunsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// IncidentResponse_ServiceDesc is the grpc.ServiceDesc for IncidentResponse service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IncidentResponse_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "incidentresponse.v1.IncidentResponse",
+	HandlerType: (*IncidentResponseServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateEvent",
+			Handler:    _IncidentResponse_CreateEvent_Handler,
+		},
+		{
+			MethodName: "GetIncident",
+			Handler:    _IncidentResponse_GetIncident_Handler,
+		},
+		{
+			MethodName: "ListIncidents",
+			Handler:    _IncidentResponse_ListIncidents_Handler,
+		},
+		{
+			MethodName: "ListPlaybookRuns",
+			Handler:    _IncidentResponse_ListPlaybookRuns_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "IngestEvents",
+			Handler:       _IncidentResponse_IngestEvents_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "WatchIncidents",
+			Handler:       _IncidentResponse_WatchIncidents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "incidentresponse/v1/incident_response.proto",
+}
@@ -0,0 +1,38 @@
+// Command agent collects logs on a remote host and ships them to the
+// incident response server.
+//
+//	go run ./cmd/agent -config data/agent/agent.example.yaml
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/agent"
+)
+
+func main() {
+	configPath := flag.String("config", "/etc/ir-agent/agent.yaml", "path to the agent config file")
+	flag.Parse()
+
+	cfg, err := agent.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load agent config: %v", err)
+	}
+
+	a, err := agent.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to start agent: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Agent shipping %d sources to %s", len(cfg.Sources), cfg.Server.URL)
+	a.Run(ctx)
+	log.Println("Agent stopped")
+}
@@ -0,0 +1,99 @@
+// Command dbbench measures sustained event ingest throughput against a scratch
+// SQLite database, comparing direct writes with the batch writer.
+//
+//	go run ./cmd/dbbench -duration 10s -workers 32
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/config"
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+func main() {
+	duration := flag.Duration("duration", 5*time.Second, "how long to run each scenario")
+	workers := flag.Int("workers", 16, "concurrent writers")
+	batchSize := flag.Int("batch", 100, "batch size for the batched scenario")
+	interval := flag.Duration("interval", 0, "how long a partial batch lingers for more writes")
+	busyTimeout := flag.Int("busy-timeout", 5000, "SQLite busy_timeout in milliseconds")
+	payload := flag.Int("payload", 512, "approximate normalized payload size in bytes")
+	flag.Parse()
+
+	scenarios := []struct {
+		name  string
+		wal   bool
+		batch int
+	}{
+		{"rollback journal, unbatched", false, 1},
+		{"WAL, unbatched", true, 1},
+		{"WAL, batched", true, *batchSize},
+	}
+
+	fmt.Printf("%-30s %10s %12s %10s\n", "scenario", "events", "events/sec", "errors")
+	for _, sc := range scenarios {
+		events, errors, elapsed := run(sc.wal, sc.batch, *busyTimeout, *workers, *interval, *duration, *payload)
+		fmt.Printf("%-30s %10d %12.0f %10d\n", sc.name, events, float64(events)/elapsed.Seconds(), errors)
+	}
+}
+
+// run ingests events from concurrent workers for the given duration
+func run(wal bool, batch, busyTimeout, workers int, interval, duration time.Duration, payload int) (int64, int64, time.Duration) {
+	dir, err := os.MkdirTemp("", "dbbench")
+	if err != nil {
+		log.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := &config.Config{
+		DatabaseURL:         filepath.Join(dir, "bench.db"),
+		DatabaseWAL:         wal,
+		DatabaseBusyTimeout: busyTimeout,
+	}
+	db, err := database.Open(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close(db)
+
+	writer := database.NewBatchWriter(db, batch, interval)
+	normalized := fmt.Sprintf(`{"source_ip":"10.0.0.1","username":"bench","padding":"%s"}`, strings.Repeat("x", payload))
+
+	var events, errors int64
+	deadline := time.Now().Add(duration)
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for time.Now().Before(deadline) {
+				event := &models.Event{
+					Source:     "dbbench",
+					EventType:  "authentication_failed",
+					Severity:   models.SeverityLow,
+					Normalized: normalized,
+				}
+				if err := writer.Write(event); err != nil {
+					atomic.AddInt64(&errors, 1)
+					continue
+				}
+				atomic.AddInt64(&events, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	writer.Close()
+
+	return events, errors, time.Since(start)
+}
@@ -0,0 +1,286 @@
+// Command loadbench drives synthetic event load against a running server at
+// a fixed request rate, reporting ingest latency percentiles and detection
+// lag, the time from the server receiving an event to detection finishing
+// with it.
+//
+//	go run ./cmd/loadbench -url http://localhost:8000 -key $KEY -rps 200 -duration 30s
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Report is a run's results; -json prints it for recording baselines
+type Report struct {
+	Target      string  `json:"target"`
+	RPS         int     `json:"rps"`
+	Batch       int     `json:"batch"`
+	PayloadSize int     `json:"payload_bytes"`
+	Duration    string  `json:"duration"`
+	Requests    int     `json:"requests"`
+	Errors      int     `json:"errors"`
+	Skipped     int     `json:"skipped"` // ticks with every worker busy
+	AchievedRPS float64 `json:"achieved_rps"`
+	EventsSec   float64 `json:"events_per_sec"`
+
+	Statuses map[string]int `json:"statuses"`
+	Latency  Percentiles    `json:"latency_ms"`
+
+	// Detection lag over the events of sampled requests; Unprocessed
+	// counts those detection hadn't finished when polling gave up
+	Lag         Percentiles `json:"detection_lag_ms"`
+	LagSamples  int         `json:"detection_lag_samples"`
+	Unprocessed int         `json:"unprocessed"`
+}
+
+// Percentiles summarizes durations in milliseconds
+type Percentiles struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// traced is a sampled request whose events are polled for detection lag
+type traced struct {
+	requestID string
+	events    int
+}
+
+func main() {
+	target := flag.String("url", "http://localhost:8000", "server base URL")
+	key := flag.String("key", os.Getenv("API_KEY"), "API key with the ingest role (default $API_KEY)")
+	rps := flag.Int("rps", 100, "requests per second")
+	duration := flag.Duration("duration", 30*time.Second, "how long to send load")
+	batch := flag.Int("batch", 1, "events per request; above 1 uses /events/batch")
+	payload := flag.Int("payload", 512, "approximate raw payload size in bytes")
+	workers := flag.Int("workers", 64, "maximum requests in flight")
+	sample := flag.Int("sample", 10, "trace detection lag for every nth request")
+	eventType := flag.String("event-type", "loadbench", "event_type of generated events; use one rules match to load detection too")
+	lagTimeout := flag.Duration("lag-timeout", 30*time.Second, "how long to wait for sampled events to be processed")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	if *rps < 1 || *batch < 1 || *batch > 1000 || *workers < 1 || *sample < 1 {
+		log.Fatal("rps, workers and sample must be positive and batch between 1 and 1000")
+	}
+	base := strings.TrimSuffix(*target, "/") + "/api/v1"
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{MaxIdleConnsPerHost: *workers},
+	}
+	run := fmt.Sprintf("loadbench-%d", time.Now().Unix())
+	padding := strings.Repeat("x", max(*payload-200, 0))
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		statuses  = make(map[string]int)
+		errors    int
+		traces    []traced
+		wg        sync.WaitGroup
+	)
+	send := func(n int) {
+		defer wg.Done()
+		events := make([]map[string]interface{}, *batch)
+		for i := range events {
+			events[i] = syntheticEvent(*eventType, n, i, padding)
+		}
+		path, body := "/events", interface{}(events[0])
+		if *batch > 1 {
+			path, body = "/events/batch", map[string]interface{}{"events": events}
+		}
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPost, base+path, bytes.NewReader(encoded))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", *key)
+		requestID := ""
+		if n%*sample == 0 {
+			requestID = fmt.Sprintf("%s-%d", run, n)
+			req.Header.Set("X-Request-ID", requestID)
+		}
+
+		start := time.Now()
+		resp, err := client.Do(req)
+		elapsed := time.Since(start)
+		status := "error"
+		if err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			status = fmt.Sprintf("%d", resp.StatusCode)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		statuses[status]++
+		if err != nil || resp.StatusCode >= 300 {
+			errors++
+			return
+		}
+		latencies = append(latencies, elapsed)
+		if requestID != "" {
+			traces = append(traces, traced{requestID: requestID, events: *batch})
+		}
+	}
+
+	// Open loop: requests start on schedule whether or not earlier ones
+	// returned, up to the worker limit
+	slots := make(chan struct{}, *workers)
+	ticker := time.NewTicker(time.Second / time.Duration(*rps))
+	started := time.Now()
+	deadline := started.Add(*duration)
+	sent, skipped := 0, 0
+	for now := range ticker.C {
+		if now.After(deadline) {
+			break
+		}
+		select {
+		case slots <- struct{}{}:
+			wg.Add(1)
+			sent++
+			go func(n int) {
+				defer func() { <-slots }()
+				send(n)
+			}(sent)
+		default:
+			skipped++
+		}
+	}
+	ticker.Stop()
+	wg.Wait()
+	elapsed := time.Since(started)
+
+	lags, unprocessed := detectionLag(client, base, *key, traces, *lagTimeout)
+
+	report := Report{
+		Target:      *target,
+		RPS:         *rps,
+		Batch:       *batch,
+		PayloadSize: *payload,
+		Duration:    duration.String(),
+		Requests:    sent,
+		Errors:      errors,
+		Skipped:     skipped,
+		AchievedRPS: float64(sent-errors) / elapsed.Seconds(),
+		EventsSec:   float64((sent-errors)**batch) / elapsed.Seconds(),
+		Statuses:    statuses,
+		Latency:     percentiles(latencies),
+		Lag:         percentiles(lags),
+		LagSamples:  len(lags),
+		Unprocessed: unprocessed,
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+		return
+	}
+	fmt.Printf("%d requests (%d events each) in %s: %.0f req/s, %.0f events/s, %d errors, %d skipped\n",
+		report.Requests, report.Batch, elapsed.Round(time.Millisecond), report.AchievedRPS, report.EventsSec, report.Errors, report.Skipped)
+	fmt.Printf("statuses: %v\n\n", report.Statuses)
+	fmt.Printf("%-16s %10s %10s %10s %10s\n", "ms", "p50", "p90", "p99", "max")
+	for _, row := range []struct {
+		name string
+		p    Percentiles
+	}{{"ingest latency", report.Latency}, {"detection lag", report.Lag}} {
+		fmt.Printf("%-16s %10.1f %10.1f %10.1f %10.1f\n", row.name, row.p.P50, row.p.P90, row.p.P99, row.p.Max)
+	}
+	fmt.Printf("\ndetection lag over %d sampled events; %d not processed within %s\n", report.LagSamples, report.Unprocessed, *lagTimeout)
+}
+
+// syntheticEvent builds event i of request n, its raw payload padded to
+// roughly the requested size
+func syntheticEvent(eventType string, n, i int, padding string) map[string]interface{} {
+	ip := fmt.Sprintf("10.%d.%d.%d", rand.Intn(256), rand.Intn(256), 1+rand.Intn(254))
+	return map[string]interface{}{
+		"source":     "loadbench",
+		"event_type": eventType,
+		"severity":   "info",
+		"normalized": map[string]interface{}{
+			"event_type": eventType,
+			"source_ip":  ip,
+			"username":   fmt.Sprintf("user-%d", rand.Intn(1000)),
+			"host":       fmt.Sprintf("host-%d", rand.Intn(100)),
+		},
+		"raw_data": map[string]interface{}{
+			"message":  fmt.Sprintf("synthetic event %d.%d from %s", n, i, ip),
+			"sequence": n,
+			"padding":  padding,
+		},
+	}
+}
+
+// detectionLag polls the events of the traced requests until detection
+// processed them all or timeout passes, returning each processed event's lag
+// from received_at to processed_at, and how many events were left
+func detectionLag(client *http.Client, base, key string, traces []traced, timeout time.Duration) ([]time.Duration, int) {
+	type summary struct {
+		ReceivedAt  *time.Time `json:"received_at"`
+		ProcessedAt *time.Time `json:"processed_at"`
+	}
+	var lags []time.Duration
+	pending := traces
+	giveUp := time.Now().Add(timeout)
+	for len(pending) > 0 && time.Now().Before(giveUp) {
+		var next []traced
+		for _, trace := range pending {
+			req, _ := http.NewRequest(http.MethodGet, base+"/events?limit=1000&request_id="+url.QueryEscape(trace.requestID), nil)
+			req.Header.Set("X-API-Key", key)
+			resp, err := client.Do(req)
+			if err != nil {
+				next = append(next, trace)
+				continue
+			}
+			var events []summary
+			err = json.NewDecoder(resp.Body).Decode(&events)
+			resp.Body.Close()
+			done := err == nil && len(events) == trace.events
+			for _, event := range events {
+				if event.ProcessedAt == nil || event.ReceivedAt == nil {
+					done = false
+				}
+			}
+			if !done {
+				next = append(next, trace)
+				continue
+			}
+			for _, event := range events {
+				lags = append(lags, event.ProcessedAt.Sub(*event.ReceivedAt))
+			}
+		}
+		if pending = next; len(pending) > 0 {
+			time.Sleep(500 * time.Millisecond)
+		}
+	}
+	unprocessed := 0
+	for _, trace := range pending {
+		unprocessed += trace.events
+	}
+	return lags, unprocessed
+}
+
+// percentiles summarizes durations in milliseconds
+func percentiles(durations []time.Duration) Percentiles {
+	if len(durations) == 0 {
+		return Percentiles{}
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	at := func(q float64) float64 {
+		index := int(q * float64(len(durations)-1))
+		return float64(durations[index]) / float64(time.Millisecond)
+	}
+	return Percentiles{P50: at(0.50), P90: at(0.90), P99: at(0.99), Max: at(1)}
+}
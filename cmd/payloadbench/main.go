@@ -0,0 +1,242 @@
+// Command payloadbench measures the storage and CPU trade-off of compressing
+// raw event payloads, at gzip levels for realistic event shapes and end to
+// end against scratch SQLite databases.
+//
+//	go run ./cmd/payloadbench -events 20000
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/config"
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// profile generates raw payloads of one realistic shape
+type profile struct {
+	name     string
+	generate func(r *rand.Rand) map[string]interface{}
+}
+
+var profiles = []profile{
+	{"syslog auth line", syslogPayload},
+	{"EDR process event", processPayload},
+	{"CloudTrail record", cloudTrailPayload},
+}
+
+func main() {
+	eventCount := flag.Int("events", 20000, "events per measurement")
+	minSize := flag.Int("min-size", 512, "smallest payload compressed in the database scenario")
+	flag.Parse()
+
+	r := rand.New(rand.NewSource(1))
+	fmt.Printf("%-20s %-8s %10s %10s %7s %14s %14s\n", "payload", "level", "avg bytes", "stored", "ratio", "compress/evt", "decompress/evt")
+	for _, p := range profiles {
+		payloads := make([][]byte, *eventCount)
+		for i := range payloads {
+			payloads[i], _ = json.Marshal(p.generate(r))
+		}
+		for _, level := range []struct {
+			name  string
+			level int
+		}{
+			{"speed", gzip.BestSpeed},
+			{"default", gzip.DefaultCompression},
+			{"best", gzip.BestCompression},
+		} {
+			measureLevel(p.name, level.name, level.level, payloads)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("%-12s %10s %12s %12s\n", "database", "size", "insert", "read")
+	for _, codec := range []string{"none", "gzip"} {
+		if err := measureDatabase(codec, *minSize, *eventCount, r); err != nil {
+			log.Fatalf("%s: %v", codec, err)
+		}
+	}
+}
+
+// measureLevel compresses and decompresses every payload at a gzip level,
+// reusing one writer and reader as the service pools them
+func measureLevel(name, levelName string, level int, payloads [][]byte) {
+	var in, out int
+	compressed := make([][]byte, len(payloads))
+	w, _ := gzip.NewWriterLevel(nil, level)
+	start := time.Now()
+	for i, payload := range payloads {
+		var buf bytes.Buffer
+		w.Reset(&buf)
+		w.Write(payload)
+		w.Close()
+		compressed[i] = buf.Bytes()
+		in += len(payload)
+		out += buf.Len()
+	}
+	compressTime := time.Since(start)
+
+	var reader gzip.Reader
+	start = time.Now()
+	for _, data := range compressed {
+		reader.Reset(bytes.NewReader(data))
+		io.Copy(io.Discard, &reader)
+	}
+	decompressTime := time.Since(start)
+
+	n := time.Duration(len(payloads))
+	fmt.Printf("%-20s %-8s %10d %10d %6.1fx %14s %14s\n", name, levelName,
+		in/len(payloads), out/len(payloads), float64(in)/float64(out),
+		compressTime/n, decompressTime/n)
+}
+
+// measureDatabase stores events with a mix of the profiles' payloads in a
+// scratch database and reports its size and the insert and read times
+func measureDatabase(codec string, minSize, eventCount int, r *rand.Rand) error {
+	if err := models.SetPayloadCompression(codec, minSize); err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp("", "payloadbench")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := &config.Config{DatabaseURL: filepath.Join(dir, "bench.db"), DatabaseWAL: true, DatabaseBusyTimeout: 5000}
+	log.SetOutput(io.Discard)
+	db, err := database.Open(cfg)
+	log.SetOutput(os.Stderr)
+	if err != nil {
+		return err
+	}
+	defer database.Close(db)
+
+	events := make([]*models.Event, eventCount)
+	for i := range events {
+		raw, _ := json.Marshal(profiles[i%len(profiles)].generate(r))
+		events[i] = &models.Event{
+			Source:     "bench",
+			EventType:  "bench_event",
+			Severity:   models.SeverityInfo,
+			RawData:    string(raw),
+			Normalized: `{"source_ip":"10.0.0.1"}`,
+		}
+	}
+
+	start := time.Now()
+	if err := db.CreateInBatches(events, 500).Error; err != nil {
+		return err
+	}
+	insertTime := time.Since(start)
+
+	start = time.Now()
+	var stored []models.Event
+	if err := db.Find(&stored).Error; err != nil {
+		return err
+	}
+	readTime := time.Since(start)
+
+	if err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)").Error; err != nil {
+		return err
+	}
+	if err := db.Exec("VACUUM").Error; err != nil {
+		return err
+	}
+	info, err := os.Stat(cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%-12s %9.1fM %12s %12s\n", codec, float64(info.Size())/(1<<20), insertTime.Round(time.Millisecond), readTime.Round(time.Millisecond))
+	return nil
+}
+
+func randomIP(r *rand.Rand) string {
+	return fmt.Sprintf("10.%d.%d.%d", r.Intn(256), r.Intn(256), r.Intn(256))
+}
+
+func randomHex(r *rand.Rand, n int) string {
+	const digits = "0123456789abcdef"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = digits[r.Intn(len(digits))]
+	}
+	return string(b)
+}
+
+var users = []string{"alice", "bob", "carol", "dave", "erin", "admin", "svc-backup", "root"}
+
+// syslogPayload is a collected sshd line, about 200 bytes
+func syslogPayload(r *rand.Rand) map[string]interface{} {
+	return map[string]interface{}{
+		"line": fmt.Sprintf("Oct 17 12:%02d:%02d bastion-%02d sshd[%d]: Failed password for invalid user %s from %s port %d ssh2",
+			r.Intn(60), r.Intn(60), r.Intn(20), 1000+r.Intn(60000), users[r.Intn(len(users))], randomIP(r), 1024+r.Intn(60000)),
+		"collector": "auth-logs",
+		"file":      "/var/log/auth.log",
+		"offset":    r.Int63n(1 << 30),
+	}
+}
+
+// processPayload is an endpoint process creation event, about 900 bytes
+func processPayload(r *rand.Rand) map[string]interface{} {
+	return map[string]interface{}{
+		"event": map[string]interface{}{"kind": "event", "category": "process", "type": "start", "id": randomHex(r, 32)},
+		"host": map[string]interface{}{
+			"name": fmt.Sprintf("ws-%04d", r.Intn(5000)), "os": map[string]interface{}{"family": "windows", "version": "10.0.19045"},
+			"ip": []string{randomIP(r)},
+		},
+		"user": map[string]interface{}{"name": users[r.Intn(len(users))], "domain": "CORP"},
+		"process": map[string]interface{}{
+			"pid": r.Intn(65536), "name": "powershell.exe",
+			"executable":   `C:\Windows\System32\WindowsPowerShell\v1.0\powershell.exe`,
+			"command_line": fmt.Sprintf(`powershell.exe -NoProfile -ExecutionPolicy Bypass -File C:\ProgramData\scripts\inventory-%d.ps1 -Verbose`, r.Intn(100)),
+			"hash":         map[string]interface{}{"sha256": randomHex(r, 64), "md5": randomHex(r, 32)},
+			"parent": map[string]interface{}{
+				"pid": r.Intn(65536), "name": "explorer.exe", "executable": `C:\Windows\explorer.exe`,
+				"command_line": `C:\Windows\Explorer.EXE`,
+			},
+			"working_directory": `C:\Users\Public`,
+		},
+		"agent": map[string]interface{}{"type": "endpoint", "version": "8.11.1", "id": randomHex(r, 36)},
+	}
+}
+
+// cloudTrailPayload is an AWS API call record, about 2.3KB
+func cloudTrailPayload(r *rand.Rand) map[string]interface{} {
+	resources := make([]map[string]interface{}, 10)
+	for i := range resources {
+		resources[i] = map[string]interface{}{
+			"ARN":       fmt.Sprintf("arn:aws:ec2:us-east-1:123456789012:instance/i-%s", randomHex(r, 17)),
+			"accountId": "123456789012",
+			"type":      "AWS::EC2::Instance",
+		}
+	}
+	return map[string]interface{}{
+		"eventVersion": "1.08",
+		"userIdentity": map[string]interface{}{
+			"type": "AssumedRole", "principalId": "AROA" + randomHex(r, 16) + ":" + users[r.Intn(len(users))],
+			"arn":       "arn:aws:sts::123456789012:assumed-role/Operators/" + users[r.Intn(len(users))],
+			"accountId": "123456789012", "accessKeyId": "ASIA" + randomHex(r, 16),
+			"sessionContext": map[string]interface{}{
+				"attributes": map[string]interface{}{"creationDate": "2026-10-17T11:02:03Z", "mfaAuthenticated": "true"},
+			},
+		},
+		"eventTime": "2026-10-17T12:00:00Z", "eventSource": "ec2.amazonaws.com", "eventName": "DescribeInstances",
+		"awsRegion": "us-east-1", "sourceIPAddress": randomIP(r),
+		"userAgent":         "aws-cli/2.15.0 Python/3.11.6 Linux/6.5.0 exe/x86_64.ubuntu.22 prompt/off command/ec2.describe-instances",
+		"requestParameters": map[string]interface{}{"instancesSet": map[string]interface{}{"items": resources}, "filterSet": map[string]interface{}{}},
+		"requestID":         randomHex(r, 36), "eventID": randomHex(r, 36),
+		"readOnly": true, "eventType": "AwsApiCall", "managementEvent": true,
+		"recipientAccountId": "123456789012", "eventCategory": "Management",
+		"tlsDetails": map[string]interface{}{"tlsVersion": "TLSv1.3", "cipherSuite": "TLS_AES_128_GCM_SHA256", "clientProvidedHostHeader": "ec2.us-east-1.amazonaws.com"},
+	}
+}
@@ -0,0 +1,116 @@
+// Command rulebench measures detection rule matching latency with large
+// synthetic rule sets, exercising the rule index and precompiled regexes.
+//
+//	go run ./cmd/rulebench -rules 1000,5000 -events 20000
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+func main() {
+	ruleCounts := flag.String("rules", "100,1000,5000", "comma-separated rule set sizes")
+	eventCount := flag.Int("events", 20000, "events evaluated per rule set")
+	eventTypes := flag.Int("event-types", 200, "distinct event types across rules and events")
+	flag.Parse()
+
+	// Rule loading logs one line per rule
+	log.SetOutput(io.Discard)
+
+	fmt.Printf("%8s %12s %12s %12s %12s\n", "rules", "p50", "p95", "p99", "events/sec")
+	for _, field := range strings.Split(*ruleCounts, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			fmt.Printf("invalid rule count %q\n", field)
+			continue
+		}
+
+		engine := services.NewDetectionEngine(nil, nil, nil, nil)
+		engine.SetRules(syntheticRules(n, *eventTypes))
+
+		events, normalized := syntheticEvents(*eventCount, *eventTypes)
+		latencies := make([]time.Duration, len(events))
+
+		start := time.Now()
+		for i, event := range events {
+			t := time.Now()
+			engine.MatchingRules(event, normalized[i])
+			latencies[i] = time.Since(t)
+		}
+		elapsed := time.Since(start)
+
+		sort.Slice(latencies, func(a, b int) bool { return latencies[a] < latencies[b] })
+		fmt.Printf("%8d %12s %12s %12s %12.0f\n", n,
+			percentile(latencies, 0.50), percentile(latencies, 0.95), percentile(latencies, 0.99),
+			float64(len(events))/elapsed.Seconds())
+	}
+}
+
+// syntheticRules builds rules spread across event types, mixing equals, in
+// and regex conditions, with a few unindexed rules that see every event
+func syntheticRules(n, eventTypes int) []services.Rule {
+	rules := make([]services.Rule, n)
+	for i := range rules {
+		r := &rules[i].Rule
+		r.ID = fmt.Sprintf("bench-%05d", i)
+		r.Name = r.ID
+		r.Enabled = true
+
+		if i%50 == 0 {
+			r.Conditions = append(r.Conditions, services.Condition{
+				Field: "severity", Operator: "equals", Value: "critical",
+			})
+		} else {
+			r.Conditions = append(r.Conditions, services.Condition{
+				Field: "event_type", Operator: "equals", Value: fmt.Sprintf("type_%d", i%eventTypes),
+			})
+		}
+
+		r.Conditions = append(r.Conditions,
+			services.Condition{Field: "user", Operator: "in", Values: []string{"root", "admin", fmt.Sprintf("svc%d", i)}},
+			services.Condition{Field: "process", Operator: "regex", Pattern: fmt.Sprintf(`^[a-f0-9]{8,}_%d\.exe$`, i%17)},
+		)
+	}
+	return rules
+}
+
+// syntheticEvents builds events with normalized payloads over the same event types
+func syntheticEvents(n, eventTypes int) ([]*models.Event, []map[string]interface{}) {
+	rng := rand.New(rand.NewSource(1))
+	users := []string{"root", "admin", "alice", "bob"}
+
+	events := make([]*models.Event, n)
+	normalized := make([]map[string]interface{}, n)
+	for i := range events {
+		events[i] = &models.Event{
+			EventID:   strconv.Itoa(i),
+			EventType: fmt.Sprintf("type_%d", rng.Intn(eventTypes)),
+			Source:    "rulebench",
+			Severity:  models.SeverityLow,
+		}
+		normalized[i] = map[string]interface{}{
+			"user":    users[rng.Intn(len(users))],
+			"process": fmt.Sprintf("%08x_%d.exe", rng.Uint32(), rng.Intn(17)),
+		}
+	}
+	return events, normalized
+}
+
+// percentile returns the value at quantile q of sorted durations
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(float64(len(sorted)-1)*q)]
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/config"
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+const commandUsage = `usage: server [command]
+
+Without a command the service runs. Commands:
+  backup [-verify]          snapshot the database and content directories
+  verify <backup>           check a backup's checksums and database
+  restore [-yes] <backup>   replace the database and content with a backup;
+                            stop the service first
+  seed [-profile name]      generate sample events, incidents, playbook runs,
+                            rules and playbooks (profiles: small, large,
+                            attack-simulation); seed -h lists the options
+
+A backup is a file path or the name of an archive in BACKUP_DIR or
+BACKUP_STORE.
+`
+
+// runCommand runs a maintenance command and returns the exit status
+func runCommand(name string, args []string) int {
+	var err error
+	switch name {
+	case "backup":
+		err = backupCommand(args)
+	case "verify":
+		err = verifyCommand(args)
+	case "restore":
+		err = restoreCommand(args)
+	case "seed":
+		err = seedCommand(args)
+	case "help", "-h", "-help", "--help":
+		fmt.Print(commandUsage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", name, commandUsage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+// databasePath is the database file, without connection options
+func databasePath(cfg *config.Config) string {
+	path, _, _ := strings.Cut(cfg.DatabaseURL, "?")
+	return path
+}
+
+// s3Config is the S3 connection shared by cold storage and backups
+func s3Config(cfg *config.Config) services.S3Config {
+	return services.S3Config{
+		Endpoint:        cfg.S3Endpoint,
+		Region:          cfg.S3Region,
+		AccessKeyID:     cfg.S3AccessKeyID,
+		SecretAccessKey: cfg.S3SecretAccessKey,
+		SessionToken:    cfg.S3SessionToken,
+	}
+}
+
+// backupConfig describes what backups capture and where they go
+func backupConfig(cfg *config.Config) (services.BackupConfig, error) {
+	backup := services.BackupConfig{
+		DatabasePath: databasePath(cfg),
+		ContentDirs: map[string]string{
+			"rules":               cfg.RulesDir,
+			"playbooks":           cfg.PlaybooksDir,
+			"watchlists":          cfg.WatchlistsDir,
+			"scenarios":           cfg.ScenariosDir,
+			"notification_routes": cfg.NotificationRoutesDir,
+		},
+		Dir:    cfg.BackupDir,
+		Retain: cfg.BackupRetain,
+	}
+	tenants, err := database.TenantDatabases(cfg)
+	if err != nil {
+		return backup, err
+	}
+	backup.TenantDatabases = make(map[string]string, len(tenants))
+	for tenant, databaseURL := range tenants {
+		backup.TenantDatabases[tenant], _, _ = strings.Cut(databaseURL, "?")
+	}
+	if cfg.BackupStore != "" {
+		store, err := services.OpenObjectStore(cfg.BackupStore, s3Config(cfg))
+		if err != nil {
+			return backup, fmt.Errorf("invalid BACKUP_STORE: %w", err)
+		}
+		backup.Store = store
+	}
+	return backup, nil
+}
+
+// loadBackupConfig loads the configuration for a backup command
+func loadBackupConfig() (services.BackupConfig, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return services.BackupConfig{}, fmt.Errorf("failed to load config: %w", err)
+	}
+	return backupConfig(cfg)
+}
+
+func backupCommand(args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	verify := flags.Bool("verify", false, "verify the archive after writing it")
+	flags.Parse(args)
+
+	backup, err := loadBackupConfig()
+	if err != nil {
+		return err
+	}
+	db, err := services.OpenBackupDatabase(backup.DatabasePath)
+	if err != nil {
+		return err
+	}
+	info, err := services.NewBackupManager(db, backup).Create()
+	if info != nil {
+		fmt.Printf("%s\t%d bytes\n", info.Name, info.Size)
+	}
+	if err != nil {
+		return err
+	}
+	if *verify {
+		manifest, err := services.VerifyBackup(filepath.Join(backup.Dir, info.Name))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("verified %d files\n", len(manifest.Files))
+	}
+	return nil
+}
+
+// resolveBackup finds a backup given as a path or an archive name,
+// downloading it from the store if needed
+func resolveBackup(cfg services.BackupConfig, backup string) (string, error) {
+	if _, err := os.Stat(backup); err == nil {
+		return backup, nil
+	}
+	path, err := services.NewBackupManager(nil, cfg).Fetch(backup)
+	if errors.Is(err, services.ErrBackupNotFound) {
+		return "", fmt.Errorf("no backup %q", backup)
+	}
+	return path, err
+}
+
+func verifyCommand(args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("expected one backup")
+	}
+
+	backup, err := loadBackupConfig()
+	if err != nil {
+		return err
+	}
+	archive, err := resolveBackup(backup, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	manifest, err := services.VerifyBackup(archive)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: %d files verified, taken %s\n", archive, len(manifest.Files), manifest.CreatedAt.Format("2006-01-02 15:04:05Z"))
+	return nil
+}
+
+func restoreCommand(args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	yes := flags.Bool("yes", false, "don't ask for confirmation")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("expected one backup")
+	}
+
+	backup, err := loadBackupConfig()
+	if err != nil {
+		return err
+	}
+	archive, err := resolveBackup(backup, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if !*yes {
+		fmt.Printf("Replace %s, the tenant databases and the content directories with %s? The service must be stopped. [y/N] ", backup.DatabasePath, archive)
+		var answer string
+		fmt.Scanln(&answer)
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			return fmt.Errorf("cancelled")
+		}
+	}
+
+	manifest, err := services.RestoreBackup(archive, backup.DatabasePath, backup.TenantDatabases, backup.ContentDirs)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("restored %s taken %s; replaced files were kept with a .pre-restore suffix\n",
+		manifest.Name, manifest.CreatedAt.Format("2006-01-02 15:04:05Z"))
+	return nil
+}
+
+func seedCommand(args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	profileName := flags.String("profile", "small", "volume profile: "+strings.Join(services.SeedProfileNames(), ", "))
+	seed := flags.Int64("seed", 1, "random seed; the same seed generates the same IDs and content")
+	events := flags.Int("events", -1, "background events (default from the profile)")
+	incidents := flags.Int("incidents", -1, "incidents (default from the profile)")
+	runs := flags.Int("runs", -1, "playbook runs (default from the profile)")
+	rules := flags.Int("rules", -1, "rules written to RULES_DIR (default from the profile)")
+	playbooks := flags.Int("playbooks", -1, "playbooks written to PLAYBOOKS_DIR (default from the profile)")
+	days := flags.Int("days", -1, "days back from now to spread events over (default from the profile)")
+	attacks := flags.Int("attacks", -1, "scripted attack chains (default from the profile)")
+	content := flags.Bool("content", true, "write the generated rules and playbooks")
+	flags.Parse(args)
+
+	profile, ok := services.SeedProfiles[*profileName]
+	if !ok {
+		return fmt.Errorf("unknown profile %q (profiles: %s)", *profileName, strings.Join(services.SeedProfileNames(), ", "))
+	}
+	for flagValue, target := range map[*int]*int{
+		events: &profile.Events, incidents: &profile.Incidents, runs: &profile.Runs, rules: &profile.Rules,
+		playbooks: &profile.Playbooks, days: &profile.Days, attacks: &profile.Attacks,
+	} {
+		if *flagValue >= 0 {
+			*target = *flagValue
+		}
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	db, err := database.Open(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close(db)
+
+	seeder := services.NewSeeder(db, *seed)
+	if *content {
+		seeder.RulesDir, seeder.PlaybooksDir = cfg.RulesDir, cfg.PlaybooksDir
+	}
+	ctx := database.WithAuditActor(context.Background(), "seed")
+	result, err := seeder.Seed(ctx, profile)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("seeded %s: %d events, %d incidents, %d playbook runs (%d actions), %d rules, %d playbooks\n",
+		databasePath(cfg), result.Events, result.Incidents, result.Runs, result.Actions, result.Rules, result.Playbooks)
+	if result.Rules > 0 || result.Playbooks > 0 {
+		fmt.Println("generated rules and playbooks are loaded when the service starts or reloads content")
+	}
+	return nil
+}
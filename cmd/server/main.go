@@ -1,97 +1,860 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
 
 	"github.com/gixxerblade/incident-response-mvp/internal/config"
 	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/graphqlapi"
+	"github.com/gixxerblade/incident-response-mvp/internal/grpcapi"
 	"github.com/gixxerblade/incident-response-mvp/internal/handlers"
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
 	"github.com/gixxerblade/incident-response-mvp/internal/services"
+	"github.com/gixxerblade/incident-response-mvp/internal/store"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	if err := models.SetPayloadCompression(cfg.PayloadCompression, cfg.PayloadCompressionMinSize); err != nil {
+		log.Fatalf("Invalid PAYLOAD_COMPRESSION: %v", err)
+	}
+
 	// Initialize database
-	if err := database.InitDatabase(cfg); err != nil {
+	db, err := database.Open(cfg)
+	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
-	defer database.CloseDatabase()
+	defer database.Close(db)
+
+	// Group event inserts and action-log updates into shared transactions
+	writer := database.NewBatchWriter(db, cfg.DatabaseBatchSize, time.Duration(cfg.DatabaseBatchInterval)*time.Millisecond)
+	defer writer.Close()
+
+	// Stores for the records most services share
+	eventStore := store.NewGormEvents(db, writer)
+	incidentStore := store.NewGormIncidents(db)
+	actionLogStore := store.NewGormActionLogs(db, writer)
+
+	// Tenants with residency requirements keep their events in their own
+	// databases
+	tenants, err := database.OpenTenants(cfg)
+	if err != nil {
+		log.Fatalf("Failed to open tenant databases: %v", err)
+	}
+	defer tenants.Close()
+
+	// Lists, exports and statistics read from the replica when there is one
+	reads := db
+	replica, err := database.OpenReplica(cfg)
+	if err != nil {
+		log.Fatalf("Failed to open read replica: %v", err)
+	}
+	if replica != nil {
+		reads = replica
+		defer database.Close(replica)
+	}
 
-	db := database.GetDB()
+	// Each connection has a SQL logger of its own, so runtime logging
+	// settings are applied to all of them
+	connections := []*gorm.DB{db, replica}
+	for _, tenant := range tenants.Names() {
+		connections = append(connections, tenants.Lookup(tenant))
+	}
 
 	// Initialize services
-	detectionEngine := services.NewDetectionEngine(db)
+	flagConfig, err := services.ParseFeatureFlags(cfg.FeatureFlags)
+	if err != nil {
+		log.Fatalf("Invalid FEATURE_FLAGS: %v", err)
+	}
+	featureFlags := services.NewFeatureFlags(db, flagConfig)
+	locks := services.NewLockManager(db)
+	outbox := services.NewOutbox(db, cfg.OutboxMaxAttempts)
+	detectionEngine := services.NewDetectionEngine(db, eventStore, locks, outbox)
+	detectionEngine.SetTenants(tenants)
+	campaigns := services.NewCampaignManager(db, time.Duration(cfg.CampaignWindow)*time.Second, cfg.CampaignRuleBurst)
+	detectionEngine.SetCampaignManager(campaigns)
+	serviceCatalog := services.NewServiceCatalog(db)
+	detectionEngine.SetServiceCatalog(serviceCatalog)
+	slaCalendars, err := services.LoadSLACalendars(cfg.SLACalendarsFile)
+	if err != nil {
+		log.Fatalf("Failed to load SLA calendars: %v", err)
+	}
+	serviceCatalog.SetSLACalendars(slaCalendars)
+	businessHours, err := services.ParseBusinessHours(cfg.BusinessHours, cfg.BusinessHoursTimezone)
+	if err != nil {
+		log.Fatalf("Invalid BUSINESS_HOURS: %v", err)
+	}
+	detectionEngine.SetBusinessHours(businessHours)
+	severityMatrix, err := services.ParseSeverityMatrix(cfg.SeverityMatrix)
+	if err != nil {
+		log.Fatalf("Invalid SEVERITY_MATRIX: %v", err)
+	}
+	detectionEngine.SetSeverityMatrix(severityMatrix)
+	if err := detectionEngine.LoadWatchlists(cfg.WatchlistsDir); err != nil {
+		log.Printf("Warning: Failed to load watchlists: %v", err)
+	}
+	detectionEngine.SetTracing(cfg.DetectionTraces)
+	detectionEngine.StartWorkers(cfg.DetectionWorkers, cfg.DetectionBatchSize,
+		time.Duration(cfg.DetectionBatchInterval)*time.Millisecond, time.Duration(cfg.DetectionMaxWait)*time.Millisecond)
 	if err := detectionEngine.LoadRules(cfg.RulesDir); err != nil {
 		log.Printf("Warning: Failed to load rules: %v", err)
 	}
 
-	actionRegistry := services.NewActionRegistry(db)
-	orchestrator := services.NewOrchestrator(db, actionRegistry)
+	actionLimits, err := services.ParseActionLimits(cfg.ActionConcurrencyLimits)
+	if err != nil {
+		log.Fatalf("Invalid ACTION_CONCURRENCY_LIMITS: %v", err)
+	}
+	actionLimiter := services.NewActionLimiter(actionLimits, time.Duration(cfg.ActionQueueTimeout)*time.Second)
+	actionRegistry := services.NewActionRegistry(db, actionLogStore, actionLimiter)
+	actionRegistry.SetFeatureFlags(featureFlags)
+	actionRegistry.Register("create_incident", services.NewCreateIncidentAction(incidentStore, severityMatrix))
+
+	// Status changes by hand and by update_incident follow the workflows
+	workflows, err := services.LoadWorkflows(cfg.WorkflowsFile)
+	if err != nil {
+		log.Fatalf("Failed to load workflows: %v", err)
+	}
+	actionRegistry.Register("update_incident", services.NewUpdateIncidentAction(incidentStore, workflows))
+
+	// Telephony actions text or phone responders; without Twilio credentials
+	// they only log
+	telephony := services.NewSwitchableTelephonyProvider(
+		services.NewTelephonyProvider(cfg.TwilioAccountSID, cfg.TwilioAuthToken, cfg.TwilioFromNumber))
+	voiceCallAction := services.NewVoiceCallAction(telephony, cfg.PublicAPIURL)
+	actionRegistry.Register("sms_notify", services.NewSMSNotifyAction(telephony))
+	actionRegistry.Register("voice_call", voiceCallAction)
+	actionRegistry.Register("python_script", services.NewPythonScriptAction(services.NewVirtualenvCache(cfg.PythonVenvsDir)))
+	// War rooms open on each provider whose credentials are set
+	warRoomProviders := make(map[string]services.WarRoomProvider)
+	if cfg.SlackBotToken != "" {
+		warRoomProviders["slack"] = services.NewSlackWarRooms(cfg.SlackBotToken)
+	}
+	if cfg.TeamsClientID != "" {
+		warRoomProviders["teams"] = services.NewTeamsWarRooms(cfg.TeamsTenantID, cfg.TeamsClientID, cfg.TeamsClientSecret, cfg.TeamsOrganizer)
+	}
+	if cfg.ZoomClientID != "" {
+		warRoomProviders["zoom"] = services.NewZoomWarRooms(cfg.ZoomAccountID, cfg.ZoomClientID, cfg.ZoomClientSecret)
+	}
+	warRoomTeams, err := services.ParseWarRoomTeams(cfg.WarRoomTeams)
+	if err != nil {
+		log.Fatalf("Invalid WAR_ROOM_TEAMS: %v", err)
+	}
+	actionRegistry.Register("open_war_room", services.NewWarRoomAction(db, warRoomProviders, services.SplitList(cfg.WarRoomOncall), warRoomTeams))
+	orchestrator := services.NewOrchestrator(db, actionRegistry, locks)
+	orchestrator.SetDefaultEnvironment(cfg.PlaybookEnvironment)
+	// Runs started in the background finish before the database closes
+	defer orchestrator.Wait()
+	// Actions wait for approval where the execution policy requires it,
+	// and auto-remediation actions do during change freezes
+	approvalGates := services.NewApprovalGates(db, actionRegistry)
+	orchestrator.SetApprovalGates(approvalGates)
+	executionPolicy, err := services.LoadExecutionPolicy(db, cfg.ExecutionPolicyFile)
+	if err != nil {
+		log.Fatalf("Failed to load execution policy: %v", err)
+	}
+	approvalGates.SetPolicy(executionPolicy)
 	if err := orchestrator.LoadPlaybooks(cfg.PlaybooksDir); err != nil {
 		log.Printf("Warning: Failed to load playbooks: %v", err)
 	}
 
+	// Background jobs run only on the instance holding the leader lease
+	elector := services.NewLeaderElector(db, "background-jobs", cfg.InstanceID, time.Duration(cfg.LeaderLeaseTTL)*time.Second)
+	elector.Start()
+	defer elector.Stop()
+
+	// Side effects queued by detection are dispatched from the outbox
+	notificationRouter := services.NewNotificationRouter(db, actionRegistry)
+	if err := notificationRouter.LoadRoutes(cfg.NotificationRoutesDir); err != nil {
+		log.Printf("Warning: Failed to load notification routes: %v", err)
+	}
+	outbox.RegisterHandler(services.TopicNotify, func(payload map[string]interface{}) error {
+		return notificationRouter.Dispatch(services.NotificationFromParams(payload))
+	})
+	watcherNotifier := services.NewWatcherNotifier(db, notificationRouter)
+	outbox.RegisterHandler(services.TopicNotifyWatchers, watcherNotifier.Dispatch)
+	outbox.RegisterHandler(services.TopicNotifyUser, watcherNotifier.DispatchUser)
+
+	// Stakeholder updates render comms templates and go out through the
+	// notification router
+	comms := services.NewComms(db, notificationRouter)
+	if err := comms.LoadTemplates(cfg.CommsTemplatesDir); err != nil {
+		log.Printf("Warning: Failed to load comms templates: %v", err)
+	}
+	actionRegistry.Register("send_update", services.NewSendUpdateAction(comms))
+	updateCadences, err := services.ParseUpdateCadence(cfg.StakeholderUpdateCadence)
+	if err != nil {
+		log.Fatalf("Invalid STAKEHOLDER_UPDATE_CADENCE: %v", err)
+	}
+	updateCadence := services.NewUpdateCadence(db, outbox, updateCadences)
+	outbox.RegisterHandler(services.TopicExecutePlaybook, func(payload map[string]interface{}) error {
+		playbookID, _ := payload["playbook_id"].(string)
+		inputs, _ := payload["inputs"].(map[string]interface{})
+		// Checked at dispatch so turning the flag off also stops runs
+		// already queued
+		if payload["trigger"] == services.PlaybookTriggerRule && !featureFlags.Enabled(services.FlagAutoRemediation) {
+			log.Printf("Skipping rule-triggered playbook %s: auto-remediation disabled by feature flag", playbookID)
+			return nil
+		}
+		requestID, _ := payload[services.RequestIDField].(string)
+		environment, _ := payload["environment"].(string)
+		eventID, _ := payload["event_id"].(string)
+		ruleID, _ := payload["rule_id"].(string)
+		tenant, _ := payload["tenant"].(string)
+		triggeredBy, _ := payload["triggered_by"].(string)
+		exercise, _ := payload["exercise"].(bool)
+		ctx := services.WithPlaybookEnvironment(services.WithRequestID(context.Background(), requestID), environment)
+		ctx = services.WithTriggerEvent(ctx, eventID)
+		ctx = services.WithExecutionScope(ctx, services.ExecutionScope{RuleID: ruleID, Tenant: tenant})
+		ctx = services.WithTriggeredBy(ctx, triggeredBy)
+		ctx = services.WithExercise(ctx, exercise)
+		// Steps run off the dispatch loop, so a long playbook doesn't hold
+		// up the messages behind it. Only a run that couldn't start is
+		// retried; one that started and failed is recorded on its run, and
+		// retrying it would repeat the steps that already took effect.
+		err := orchestrator.StartPlaybookContext(ctx, playbookID, inputs)
+		if errors.Is(err, services.ErrQuotaExceeded) {
+			// Retrying would only add to the load the quota sheds; the
+			// run or action is recorded as refused
+			log.Printf("Playbook %s refused: %v", playbookID, err)
+			return nil
+		}
+		return err
+	})
+
+	ingestor := services.NewIngestor(eventStore, detectionEngine)
+	ingestor.SetClockSkew(time.Duration(cfg.EventMaxFutureSkew)*time.Second, time.Duration(cfg.EventMaxPastSkew)*time.Second)
+	piiScrubber, err := services.LoadPIIPolicy(cfg.PIIPolicyFile, cfg.PIIHashKey)
+	if err != nil {
+		log.Fatalf("Failed to load PII policy: %v", err)
+	}
+	ingestor.SetPIIScrubber(piiScrubber)
+	ingestor.SetTenants(tenants)
+	fastAckKeys := services.SplitList(cfg.FastAckKeys)
+	if len(fastAckKeys) > 0 {
+		ingestor.StartAsync(cfg.FastAckQueueSize)
+	}
+	defer ingestor.Close()
+
+	// Quotas bound what rules and tenants can execute per hour
+	quotaLimits, err := services.ParseExecutionQuotas(cfg.ExecutionQuotas)
+	if err != nil {
+		log.Fatalf("Invalid EXECUTION_QUOTAS: %v", err)
+	}
+	quotas := services.NewExecutionQuotas(db, quotaLimits, actionRegistry, ingestor)
+	orchestrator.SetQuotas(quotas)
+	actionRegistry.SetQuotas(quotas)
+
+	// Risk scoring and impossible travel raise their own events for rules to
+	// act on
+	riskScorer := services.NewRiskScorer(db, ingestor, time.Duration(cfg.RiskHalfLife)*time.Second, float64(cfg.RiskThreshold))
+	detectionEngine.SetRiskScorer(riskScorer)
+	geo, err := services.LoadGeoIPDatabase(services.SplitList(cfg.GeoIPDatabase)...)
+	if err != nil {
+		log.Fatalf("Failed to load GEOIP_DATABASE: %v", err)
+	}
+	log.Printf("Loaded %d GeoIP networks", geo.Len())
+	enrichment, err := services.LoadEnrichmentPipeline(cfg.EnrichmentFile, geo, detectionEngine)
+	if err != nil {
+		log.Fatalf("Failed to load ENRICHMENT_FILE: %v", err)
+	}
+	detectionEngine.SetEnrichmentPipeline(enrichment)
+	log.Printf("Loaded %d enrichment stages", len(enrichment.Stages()))
+	if cfg.ImpossibleTravelSpeed > 0 {
+		detectionEngine.SetTravelDetector(services.NewTravelDetector(db, geo, ingestor, services.TravelDetectorConfig{
+			MaxSpeed:    float64(cfg.ImpossibleTravelSpeed),
+			MinDistance: float64(cfg.ImpossibleTravelMinDistance),
+			EventTypes:  services.SplitList(cfg.ImpossibleTravelEventTypes),
+		}))
+	}
+
+	// Exercise scenarios inject tagged synthetic events on demand or on a schedule
+	scenarioEngine := services.NewScenarioEngine(db, ingestor)
+	if err := scenarioEngine.LoadScenarios(cfg.ScenariosDir); err != nil {
+		log.Printf("Warning: Failed to load scenarios: %v", err)
+	}
+
+	staleThresholds, err := services.ParseStaleThresholds(cfg.StaleIncidentThresholds)
+	if err != nil {
+		log.Fatalf("Invalid STALE_INCIDENT_THRESHOLDS: %v", err)
+	}
+	staleGrace, err := time.ParseDuration(cfg.StaleIncidentGrace)
+	if err != nil {
+		log.Fatalf("Invalid STALE_INCIDENT_GRACE: %v", err)
+	}
+	stalePolicy, err := services.NewStalePolicy(db, outbox, featureFlags, staleThresholds, staleGrace, cfg.StaleIncidentAction)
+	if err != nil {
+		log.Fatalf("Invalid stale incident policy: %v", err)
+	}
+
+	calendars, err := services.ParseMaintenanceCalendars(cfg.MaintenanceCalendars)
+	if err != nil {
+		log.Fatalf("Invalid MAINTENANCE_CALENDARS: %v", err)
+	}
+	calendarSync := services.NewCalendarSync(db, calendars)
+
+	// Large raw payloads move to an object store, leaving a key behind.
+	// Tenant databases offload to the same store on passes of their own.
+	var coldStorage *services.ColdStorage
+	tenantColdStorage := make(map[string]*services.ColdStorage)
+	if cfg.RawDataStore != "" {
+		store, err := services.OpenObjectStore(cfg.RawDataStore, s3Config(cfg))
+		if err != nil {
+			log.Fatalf("Invalid RAW_DATA_STORE: %v", err)
+		}
+		coldStorage = services.NewColdStorage(db, store, cfg.RawDataOffloadThreshold)
+		for _, tenant := range tenants.Names() {
+			tenantColdStorage[tenant] = services.NewColdStorage(tenants.Lookup(tenant), store, cfg.RawDataOffloadThreshold)
+		}
+	}
+
+	backupCfg, err := backupConfig(cfg)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	backups := services.NewBackupManager(db, backupCfg)
+
+	scheduler := services.NewScheduler(elector)
+	if cfg.SelfMonitoringEnabled {
+		selfMonitor := services.NewSelfMonitor(db, ingestor, outbox, services.SelfMonitorConfig{
+			ActionFailureRate: cfg.SelfMonitoringActionFailureRate,
+			ActionFailureMin:  cfg.SelfMonitoringActionFailureMin,
+			QueueBacklog:      int64(cfg.SelfMonitoringQueueBacklog),
+			QueueMaxAge:       time.Duration(cfg.SelfMonitoringQueueMaxAge) * time.Second,
+			Cooldown:          time.Duration(cfg.SelfMonitoringCooldown) * time.Second,
+		})
+		for _, conn := range connections {
+			database.SetErrorHook(conn, selfMonitor.RecordDatabaseError)
+		}
+		interval := time.Duration(cfg.SelfMonitoringInterval) * time.Second
+		scheduler.Register("self-monitoring", interval, selfMonitor.Check)
+		selfMonitor.Start(interval)
+		defer selfMonitor.Stop()
+	}
+	scheduler.Register("outbox-dispatch", time.Duration(cfg.OutboxPollInterval)*time.Second, outbox.Dispatch)
+	scheduler.Register("notification-digests", time.Minute, notificationRouter.SendDigests)
+	if cfg.BackupInterval > 0 {
+		scheduler.Register("backup", time.Duration(cfg.BackupInterval)*time.Second, func() error {
+			_, err := backups.Create()
+			return err
+		})
+	}
+	reprocessor := services.NewReprocessor(db, tenants, detectionEngine, time.Duration(cfg.ReprocessAfter)*time.Second, cfg.ReprocessMaxAttempts)
+	if cfg.DetectionTraces && cfg.DetectionTraceRetention > 0 {
+		retention := time.Duration(cfg.DetectionTraceRetention) * time.Second
+		scheduler.Register("detection-trace-prune", time.Hour, func() error {
+			return detectionEngine.PruneTraces(retention)
+		})
+	}
+	if cfg.ReprocessInterval > 0 {
+		scheduler.Register("reprocess-events", time.Duration(cfg.ReprocessInterval)*time.Second, reprocessor.Sweep)
+	}
+	if coldStorage != nil {
+		scheduler.Register("raw-data-offload", time.Duration(cfg.RawDataOffloadInterval)*time.Second, coldStorage.Offload)
+	}
+	for tenant, tenantCold := range tenantColdStorage {
+		scheduler.Register("raw-data-offload:"+tenant, time.Duration(cfg.RawDataOffloadInterval)*time.Second, tenantCold.Offload)
+	}
+	if stalePolicy.Enabled() {
+		scheduler.Register("stale-incidents", 5*time.Minute, stalePolicy.Run)
+	}
+	if updateCadence.Enabled() {
+		scheduler.Register("stakeholder-update-reminders", time.Minute, updateCadence.Run)
+	}
+	sessionLifetime, err := time.ParseDuration(cfg.SessionLifetime)
+	if err != nil || sessionLifetime <= 0 {
+		log.Fatalf("Invalid SESSION_LIFETIME %q: expected a positive duration", cfg.SessionLifetime)
+	}
+	sessionIdle, err := time.ParseDuration(cfg.SessionIdleTimeout)
+	if err != nil || sessionIdle < 0 {
+		log.Fatalf("Invalid SESSION_IDLE_TIMEOUT %q: expected a duration", cfg.SessionIdleTimeout)
+	}
+	sessions := services.NewSessions(db, sessionLifetime, sessionIdle)
+	if cfg.OIDCIssuerURL != "" {
+		scheduler.Register("session-prune", time.Hour, sessions.Prune)
+	}
+	apiAccessLog := services.NewAPIAccessLog(db, reads, writer)
+	if cfg.APIAccessLogEnabled && cfg.APIAccessRetentionDays > 0 {
+		retention := time.Duration(cfg.APIAccessRetentionDays) * 24 * time.Hour
+		scheduler.Register("api-access-prune", time.Hour, func() error {
+			return apiAccessLog.Prune(retention)
+		})
+	}
+	if len(calendars) > 0 {
+		scheduler.Register("maintenance-calendars", time.Duration(cfg.MaintenanceCalendarSyncInterval)*time.Second, calendarSync.Sync)
+	}
+	for scenarioID, interval := range scenarioEngine.Schedules() {
+		scenarioID := scenarioID
+		scheduler.Register("scenario:"+scenarioID, interval, func() error {
+			return scenarioEngine.RunScheduled(scenarioID)
+		})
+	}
+	scheduler.Start()
+	defer scheduler.Stop()
+
 	// Initialize handlers
-	eventsHandler := handlers.NewEventsHandler(db, detectionEngine)
-	incidentsHandler := handlers.NewIncidentsHandler(db)
+	healthHandler := handlers.NewHealthHandler(db, detectionEngine, outbox, scheduler, ingestor)
+	eventsHandler := handlers.NewEventsHandler(db, reads, eventStore, ingestor, fastAckKeys, coldStorage, tenants)
+	incidentsHandler := handlers.NewIncidentsHandler(db, reads, incidentStore, outbox, workflows, serviceCatalog, severityMatrix, slaCalendars, piiScrubber)
+	piiHandler := handlers.NewPIIHandler(db, tenants, coldStorage, piiScrubber)
+	incidentTasksHandler := handlers.NewIncidentTasksHandler(db)
+	incidentCommentsHandler := handlers.NewIncidentCommentsHandler(db, outbox)
+	watchersHandler := handlers.NewWatchersHandler(db)
+	campaignsHandler := handlers.NewCampaignsHandler(campaigns)
+	riskHandler := handlers.NewRiskHandler(riskScorer)
+	servicesHandler := handlers.NewServicesHandler(serviceCatalog)
+	entitiesHandler := handlers.NewEntitiesHandler(services.NewEntityProfiler(db, detectionEngine, riskScorer, geo))
+	suppressionsHandler := handlers.NewSuppressionsHandler(db, calendarSync)
+	changeFreezesHandler := handlers.NewChangeFreezesHandler(db)
+	approvalsHandler := handlers.NewApprovalsHandler(db, approvalGates)
+	commsHandler := handlers.NewCommsHandler(db, comms)
+	incidentRoles := services.NewIncidentRoles(db, outbox)
+	incidentRolesHandler := handlers.NewIncidentRolesHandler(incidentRoles)
+	// Handoffs are drafted by an LLM when one is configured, otherwise
+	// summarized from the timeline
+	var handoffDrafter services.HandoffDrafter
+	if cfg.LLMAPIKey != "" {
+		handoffDrafter = services.NewLLMHandoffDrafter(cfg.LLMAPIKey, cfg.LLMModel, cfg.LLMAPIURL)
+	}
+	handoffsHandler := handlers.NewHandoffsHandler(services.NewHandoffs(db, outbox, incidentRoles, handoffDrafter))
+	runbooksHandler := handlers.NewRunbooksHandler(db)
+	actionsHandler := handlers.NewActionsHandler(db, actionRegistry)
+	notificationsHandler := handlers.NewNotificationsHandler(db)
+	telephonyHandler := handlers.NewTelephonyHandler(db, cfg.TwilioAuthToken, cfg.PublicAPIURL)
+	alertResolver := services.NewAlertResolver(db, detectionEngine, featureFlags, cfg.AlertAutoResolve)
+	alertmanagerHandler := handlers.NewAlertmanagerHandler(db, ingestor, alertResolver)
+	playbooksHandler := handlers.NewPlaybooksHandler(db, orchestrator, outbox, executionPolicy, quotas)
+	scenariosHandler := handlers.NewScenariosHandler(db, scenarioEngine)
+	simulationHandler := handlers.NewSimulationHandler(db, services.NewSimulator(detectionEngine))
+	auditHandler := handlers.NewAuditHandler(db)
+	apiAccessHandler := handlers.NewAPIAccessHandler(apiAccessLog)
+	statsHandler := handlers.NewStatsHandler(reads, time.Duration(cfg.StatsCacheTTL)*time.Second)
+	graphqlHandler, err := graphqlapi.NewHandler(db)
+	if err != nil {
+		log.Fatalf("Failed to build GraphQL schema: %v", err)
+	}
+
+	// API keys identify callers; their role decides what responses redact
+	apiKeys, anonymousRole, err := parseAuthConfig(cfg)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	authenticator := handlers.NewAuthenticator(apiKeys, anonymousRole)
+	revocations := services.NewRevocations(db, sessions)
+	authenticator.SetRevocations(revocations)
+	adminIPs, ingestIPs, err := parseAllowlists(cfg)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	adminAllowlist, ingestAllowlist := services.NewIPAllowlist(adminIPs), services.NewIPAllowlist(ingestIPs)
+	var oidcHandler *handlers.OIDCHandler
+	if cfg.OIDCIssuerURL != "" {
+		oidcConfig, err := parseOIDCConfig(cfg)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		authenticator.SetSessions(sessions)
+		oidcHandler = handlers.NewOIDCHandler(services.NewOIDC(db, oidcConfig), sessions,
+			strings.HasPrefix(oidcConfig.RedirectURL, "https://"))
+		log.Printf("OIDC sign-in enabled with %s", cfg.OIDCIssuerURL)
+	}
+	redactionPolicy, err := services.LoadRedactionPolicy(cfg.RedactionPolicyFile)
+	if err != nil {
+		log.Fatalf("Failed to load redaction policy: %v", err)
+	}
+
+	// Reload non-structural settings on SIGHUP or through the admin API
+	reloader := config.NewReloader(cfg)
+	reloader.OnReload(func(next *config.Config) (func(), error) {
+		keys, role, err := parseAuthConfig(next)
+		if err != nil {
+			return nil, err
+		}
+		adminIPs, ingestIPs, err := parseAllowlists(next)
+		if err != nil {
+			return nil, err
+		}
+		flags, err := services.ParseFeatureFlags(next.FeatureFlags)
+		if err != nil {
+			return nil, fmt.Errorf("invalid FEATURE_FLAGS: %w", err)
+		}
+		return func() {
+			authenticator.SetKeys(keys, role)
+			adminAllowlist.Set(adminIPs)
+			ingestAllowlist.Set(ingestIPs)
+			featureFlags.SetConfig(flags)
+			telephony.Set(services.NewTelephonyProvider(next.TwilioAccountSID, next.TwilioAuthToken, next.TwilioFromNumber))
+			voiceCallAction.SetCallbackURL(next.PublicAPIURL)
+			telephonyHandler.SetCredentials(next.TwilioAuthToken, next.PublicAPIURL)
+			alertResolver.SetAutoResolve(next.AlertAutoResolve)
+			for _, conn := range connections {
+				database.SetSQLLogging(conn, next.DatabaseEcho || strings.EqualFold(next.LogLevel, "DEBUG"))
+			}
+		}, nil
+	})
+	adminHandler := handlers.NewAdminHandler(reloader)
+	credentialsHandler := handlers.NewCredentialsHandler(authenticator, sessions, revocations)
+	featureFlagsHandler := handlers.NewFeatureFlagsHandler(featureFlags)
+	perfHandler := handlers.NewPerfHandler(detectionEngine)
+	backupsHandler := handlers.NewBackupsHandler(backups)
+	reprocessHandler := handlers.NewReprocessHandler(reprocessor)
+	eventPurgeHandler := handlers.NewEventPurgeHandler(db, tenants, coldStorage)
+	contentManager := services.NewContentManager(detectionEngine, orchestrator, actionRegistry, services.ContentDirs{
+		Rules:      cfg.RulesDir,
+		Playbooks:  cfg.PlaybooksDir,
+		Watchlists: cfg.WatchlistsDir,
+	})
+	contentHandler := handlers.NewContentHandler(contentManager)
+	contentPacks, err := services.NewContentPackManager(db, contentManager, cfg.ContentPacksDir, cfg.ContentPackRegistryURL, cfg.ContentPackRegistryKey)
+	if err != nil {
+		log.Fatalf("Invalid content pack registry: %v", err)
+	}
+	contentPacksHandler := handlers.NewContentPacksHandler(contentPacks)
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		for range hangup {
+			result, err := reloader.Reload()
+			if err != nil {
+				log.Printf("Config reload failed: %v", err)
+				continue
+			}
+			log.Printf("Config reloaded on SIGHUP: applied %v, restart required for %v",
+				result.Applied, result.RestartRequired)
+		}
+	}()
 
 	// Set up Gin router
 	if !cfg.Debug {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	router := gin.Default()
+	router := gin.New()
+	router.Use(handlers.RequestID(), gin.LoggerWithFormatter(handlers.LogFormatter), gin.Recovery())
+	if err := router.SetTrustedProxies(services.SplitList(cfg.TrustedProxies)); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// Health check
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
-			"status":  "ok",
-			"service": cfg.AppName,
-			"version": cfg.AppVersion,
+			"status":   "ok",
+			"service":  cfg.AppName,
+			"version":  cfg.AppVersion,
+			"instance": elector.InstanceID(),
+			"leader":   elector.IsLeader(),
 		})
 	})
 
+	// Kubernetes-style probes
+	router.GET("/healthz", healthHandler.Liveness)
+	router.GET("/readyz", healthHandler.Readiness)
+
+	// Public status page (only when a token is configured)
+	if cfg.StatusPageToken != "" {
+		statusPageHandler := handlers.NewStatusPageHandler(db, cfg.StatusPageToken, cfg.AppName)
+		router.GET("/status", statusPageHandler.GetStatusPage)
+	}
+
+	// Twilio can't send an API key; the callback verifies its own signature
+	router.POST(cfg.APIPrefix+"/telephony/twilio/gather", telephonyHandler.TwilioGather)
+
+	// Single sign-on happens before the caller has any credential
+	if oidcHandler != nil {
+		router.GET(cfg.APIPrefix+"/auth/oidc/login", oidcHandler.Login)
+		router.GET(cfg.APIPrefix+"/auth/oidc/callback", oidcHandler.Callback)
+		router.POST(cfg.APIPrefix+"/auth/logout", oidcHandler.Logout)
+	}
+
 	// API v1 routes
-	v1 := router.Group(cfg.APIPrefix)
+	v1Middleware := []gin.HandlerFunc{authenticator.Authenticate(), handlers.RouteTenant(tenants), handlers.Redact(redactionPolicy)}
+	if cfg.APIAccessLogEnabled {
+		// Ahead of authentication, so rejected requests are recorded too
+		v1Middleware = append([]gin.HandlerFunc{handlers.AuditAPIAccess(apiAccessLog)}, v1Middleware...)
+	}
+	v1 := router.Group(cfg.APIPrefix, v1Middleware...)
+	allowIngest := handlers.RequireSourceIP(ingestAllowlist)
+	requireAdmin := handlers.RequireAdmin(adminAllowlist)
+	conditional := handlers.Conditional()
 	{
+		v1.GET("/me", handlers.GetMe)
+		v1.GET("/me/sessions", credentialsHandler.ListMySessions)
+		v1.DELETE("/me/sessions", credentialsHandler.RevokeMySessions)
+		v1.DELETE("/me/sessions/:id", credentialsHandler.RevokeMySession)
+		v1.GET("/me/watched", watchersHandler.ListWatched)
+		v1.GET("/me/notifications", watchersHandler.GetNotificationPreference)
+		v1.PUT("/me/notifications", watchersHandler.SetNotificationPreference)
+
 		// Events
 		events := v1.Group("/events")
 		{
-			events.POST("", eventsHandler.CreateEvent)
-			events.GET("", eventsHandler.ListEvents)
-			events.GET("/:id", eventsHandler.GetEvent)
+			events.POST("", allowIngest, eventsHandler.CreateEvent)
+			events.POST("/batch", allowIngest, eventsHandler.CreateEventsBatch)
+			events.POST("/upload", allowIngest, eventsHandler.UploadEvents)
+			events.GET("", conditional, eventsHandler.ListEvents)
+			events.GET("/:id", conditional, eventsHandler.GetEvent)
+			events.GET("/:id/detections", eventsHandler.GetDetections)
 		}
 
 		// Incidents
 		incidents := v1.Group("/incidents")
 		{
-			incidents.GET("", incidentsHandler.ListIncidents)
-			incidents.GET("/:id", incidentsHandler.GetIncident)
+			incidents.GET("", conditional, incidentsHandler.ListIncidents)
+			incidents.GET("/:id", conditional, incidentsHandler.GetIncident)
 			incidents.PATCH("/:id", incidentsHandler.UpdateIncident)
 			incidents.POST("/:id/resolve", incidentsHandler.ResolveIncident)
+			incidents.POST("/:id/reopen", incidentsHandler.ReopenIncident)
+			incidents.POST("/:id/acknowledge", incidentsHandler.AcknowledgeIncident)
+			incidents.GET("/:id/report", incidentsHandler.GetReport)
+			incidents.GET("/:id/blast-radius", incidentsHandler.GetBlastRadius)
+			incidents.GET("/:id/sla", incidentsHandler.GetSLA)
+			incidents.GET("/:id/detection", incidentsHandler.GetDetection)
+			incidents.GET("/:id/actions", incidentsHandler.ListActionsTaken)
+
+			// Tasks
+			incidents.GET("/:id/tasks", incidentTasksHandler.ListTasks)
+			incidents.POST("/:id/tasks", incidentTasksHandler.CreateTask)
+			incidents.GET("/:id/tasks/:task_id", incidentTasksHandler.GetTask)
+			incidents.PATCH("/:id/tasks/:task_id", incidentTasksHandler.UpdateTask)
+			incidents.DELETE("/:id/tasks/:task_id", incidentTasksHandler.DeleteTask)
+
+			// Comments
+			incidents.GET("/:id/comments", incidentCommentsHandler.ListComments)
+			incidents.POST("/:id/comments", incidentCommentsHandler.CreateComment)
+
+			// Watchers
+			incidents.POST("/:id/watch", watchersHandler.Watch)
+			incidents.DELETE("/:id/watch", watchersHandler.Unwatch)
+			incidents.GET("/:id/watchers", watchersHandler.ListWatchers)
+
+			// Roles
+			incidents.GET("/:id/roles", incidentRolesHandler.ListRoles)
+			incidents.PUT("/:id/roles/:role", handlers.RequireRole(services.RoleResponder), incidentRolesHandler.AssignRole)
+			incidents.DELETE("/:id/roles/:role", handlers.RequireRole(services.RoleResponder), incidentRolesHandler.UnassignRole)
+
+			// Shift handoffs
+			incidents.GET("/:id/handoffs", handoffsHandler.ListHandoffs)
+			incidents.POST("/:id/handoff", handlers.RequireRole(services.RoleResponder), handoffsHandler.CreateHandoff)
+			incidents.POST("/:id/handoff/draft", handoffsHandler.DraftHandoff)
+
+			// Stakeholder updates
+			incidents.GET("/:id/updates", commsHandler.ListUpdates)
+			incidents.POST("/:id/updates", handlers.RequireRole(services.RoleResponder), commsHandler.SendUpdate)
+			incidents.POST("/:id/updates/preview", commsHandler.PreviewUpdate)
 		}
+		v1.GET("/workflows", incidentsHandler.ListWorkflows)
+		v1.GET("/severity-matrix", incidentsHandler.GetSeverityMatrix)
+		v1.GET("/sla/calendars", incidentsHandler.ListSLACalendars)
 
-		// Stats endpoint
-		v1.GET("/stats", func(c *gin.Context) {
-			var eventCount, incidentCount, actionCount int64
-			db.Table("events").Count(&eventCount)
-			db.Table("incidents").Count(&incidentCount)
-			db.Table("action_logs").Count(&actionCount)
+		// Campaigns
+		campaignRoutes := v1.Group("/campaigns")
+		{
+			campaignRoutes.GET("", campaignsHandler.ListCampaigns)
+			campaignRoutes.POST("", campaignsHandler.CreateCampaign)
+			campaignRoutes.GET("/:id", campaignsHandler.GetCampaign)
+			campaignRoutes.PATCH("/:id", campaignsHandler.UpdateCampaign)
+			campaignRoutes.POST("/:id/incidents", campaignsHandler.AddIncidents)
+			campaignRoutes.DELETE("/:id/incidents/:incident_id", campaignsHandler.RemoveIncident)
+		}
 
-			c.JSON(200, gin.H{
-				"events":    eventCount,
-				"incidents": incidentCount,
-				"actions":   actionCount,
-			})
-		})
+		// Service catalog
+		serviceRoutes := v1.Group("/services")
+		{
+			serviceRoutes.GET("", servicesHandler.ListServices)
+			serviceRoutes.GET("/:name", servicesHandler.GetService)
+			serviceRoutes.POST("", requireAdmin, servicesHandler.CreateService)
+			serviceRoutes.PATCH("/:name", requireAdmin, servicesHandler.UpdateService)
+			serviceRoutes.DELETE("/:name", requireAdmin, servicesHandler.DeleteService)
+		}
+
+		// Entities
+		v1.GET("/entities/:type/:value", entitiesHandler.GetEntity)
+
+		// Entity risk
+		risk := v1.Group("/risk")
+		{
+			risk.GET("/entities", riskHandler.ListEntities)
+			risk.GET("/entities/:type/:value", riskHandler.GetEntity)
+			risk.DELETE("/entities/:type/:value", handlers.RequireRole(services.RoleResponder), riskHandler.ResetEntity)
+		}
+
+		// Runbooks
+		runbooks := v1.Group("/runbooks")
+		{
+			runbooks.GET("", runbooksHandler.ListRunbooks)
+			runbooks.POST("", runbooksHandler.CreateRunbook)
+			runbooks.GET("/:id", runbooksHandler.GetRunbook)
+			runbooks.PUT("/:id", runbooksHandler.UpdateRunbook)
+			runbooks.DELETE("/:id", runbooksHandler.DeleteRunbook)
+		}
+
+		// Stats
+		v1.GET("/stats", statsHandler.GetStats)
+		v1.GET("/stats/responders", statsHandler.GetResponderMetrics)
+
+		// Audit log
+		audit := v1.Group("/audit")
+		{
+			audit.GET("", auditHandler.ListEntries)
+			audit.GET("/verify", auditHandler.Verify)
+			audit.GET("/access", requireAdmin, apiAccessHandler.ListAccess)
+			audit.GET("/access/export", requireAdmin, apiAccessHandler.ExportAccess)
+		}
+
+		// Actions
+		actions := v1.Group("/actions")
+		{
+			actions.GET("/catalog", actionsHandler.GetCatalog)
+			actions.GET("/queues", actionsHandler.GetQueues)
+		}
+
+		// Notification routing
+		notifications := v1.Group("/notifications")
+		{
+			notifications.GET("/routes", notificationsHandler.ListRoutes)
+			notifications.POST("/routes", notificationsHandler.CreateRoute)
+			notifications.PUT("/routes/:id", notificationsHandler.UpdateRoute)
+			notifications.DELETE("/routes/:id", notificationsHandler.DeleteRoute)
+			notifications.GET("/deliveries", notificationsHandler.ListDeliveries)
+		}
+
+		// Upstream alert webhooks
+		v1.POST("/webhooks/alertmanager", allowIngest, alertmanagerHandler.Receive)
+
+		// Playbooks
+		playbooks := v1.Group("/playbooks")
+		{
+			playbooks.GET("", playbooksHandler.ListPlaybooks)
+			playbooks.POST("/:id/execute", playbooksHandler.ExecutePlaybook)
+		}
+
+		// Exercise scenarios
+		scenarios := v1.Group("/scenarios")
+		{
+			scenarios.GET("", scenariosHandler.ListScenarios)
+			scenarios.GET("/runs", scenariosHandler.ListRuns)
+			scenarios.POST("/:id/run", scenariosHandler.RunScenario)
+		}
+
+		// Simulation (replay without side effects)
+		simulate := v1.Group("/simulate")
+		{
+			simulate.POST("/replay", simulationHandler.Replay)
+			simulate.POST("/tune", simulationHandler.Tune)
+			simulate.GET("/runs", simulationHandler.ListRuns)
+			simulate.GET("/runs/:id", simulationHandler.GetRun)
+		}
+
+		// GraphQL
+		v1.POST("/graphql", graphqlHandler.Query)
+
+		// Suppressions
+		v1.GET("/suppressions", suppressionsHandler.ListSuppressions)
+		v1.POST("/suppressions", suppressionsHandler.CreateSuppression)
+		v1.DELETE("/suppressions/:id", suppressionsHandler.DeleteSuppression)
+
+		// Change freezes and the approvals they gate actions behind
+		v1.GET("/change-freezes", changeFreezesHandler.ListChangeFreezes)
+		v1.POST("/change-freezes", requireAdmin, changeFreezesHandler.CreateChangeFreeze)
+		v1.DELETE("/change-freezes/:id", requireAdmin, changeFreezesHandler.DeleteChangeFreeze)
+		v1.GET("/comms/templates", commsHandler.ListTemplates)
+		v1.GET("/approvals", approvalsHandler.ListApprovals)
+		v1.GET("/approvals/:id", approvalsHandler.GetApproval)
+		v1.POST("/approvals/:id/approve", handlers.RequireRole(services.RoleResponder), approvalsHandler.ApproveAction)
+		v1.POST("/approvals/:id/reject", handlers.RequireRole(services.RoleResponder), approvalsHandler.RejectAction)
+
+		// Feature flags
+		v1.GET("/flags", featureFlagsHandler.ListFlags)
+
+		// Rule and playbook changes
+		content := v1.Group("/config", requireAdmin)
+		{
+			content.POST("/plan", contentHandler.Plan)
+			content.POST("/apply", contentHandler.Apply)
+		}
+
+		// Content packs
+		packs := v1.Group("/content-packs", requireAdmin)
+		{
+			packs.GET("", contentPacksHandler.ListInstalled)
+			packs.GET("/available", contentPacksHandler.ListAvailable)
+			packs.POST("", contentPacksHandler.Install)
+			packs.DELETE("/:name", contentPacksHandler.Uninstall)
+		}
+
+		// Administration
+		admin := v1.Group("/admin", requireAdmin)
+		{
+			admin.GET("/config", adminHandler.GetConfig)
+			admin.POST("/config/reload", adminHandler.ReloadConfig)
+			admin.PUT("/flags/:name", featureFlagsHandler.SetFlag)
+			admin.DELETE("/flags/:name", featureFlagsHandler.ClearFlag)
+			admin.POST("/maintenance-calendars/sync", suppressionsHandler.SyncCalendars)
+			admin.GET("/perf", perfHandler.GetPerf)
+			admin.POST("/perf/reset", perfHandler.ResetPerf)
+			admin.POST("/events/reprocess", reprocessHandler.ReprocessEvents)
+			admin.POST("/events/purge", eventPurgeHandler.PurgeEvents)
+			admin.GET("/quotas", playbooksHandler.GetQuotas)
+			admin.GET("/backups", backupsHandler.ListBackups)
+			admin.POST("/backups", backupsHandler.CreateBackup)
+			admin.POST("/backups/:name/verify", backupsHandler.VerifyBackup)
+			admin.GET("/pprof/*profile", perfHandler.Pprof)
+			admin.GET("/sessions", credentialsHandler.ListSessions)
+			admin.DELETE("/sessions/:id", credentialsHandler.RevokeSession)
+			admin.DELETE("/users/:name/sessions", credentialsHandler.RevokeUserSessions)
+			admin.GET("/api-keys", credentialsHandler.ListAPIKeys)
+			admin.POST("/api-keys/:name/revoke", credentialsHandler.RevokeAPIKey)
+			admin.GET("/revocations", credentialsHandler.ListRevocations)
+			admin.POST("/revocations", credentialsHandler.RevokeToken)
+			admin.POST("/pii/erase", piiHandler.Erase)
+			admin.POST("/pprof/*profile", perfHandler.Pprof)
+		}
+	}
+
+	// Start gRPC server alongside REST
+	if cfg.GRPCPort != "" {
+		grpcAddr := fmt.Sprintf("%s:%s", cfg.APIHost, cfg.GRPCPort)
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			log.Fatalf("Failed to listen for gRPC: %v", err)
+		}
+
+		grpcOptions := append(grpcapi.IngestAllowlist(ingestAllowlist), grpcapi.Authenticate(authenticator, redactionPolicy)...)
+		grpcServer := grpc.NewServer(grpcOptions...)
+		grpcapi.NewServer(db, ingestor, tenants).Register(grpcServer)
+		defer grpcServer.GracefulStop()
+
+		go func() {
+			log.Printf("Starting gRPC API on %s", grpcAddr)
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Printf("gRPC server stopped: %v", err)
+			}
+		}()
 	}
 
 	// Start server
@@ -99,7 +862,109 @@ func main() {
 	log.Printf("Starting %s v%s on %s", cfg.AppName, cfg.AppVersion, addr)
 	log.Printf("Swagger UI available at http://%s/swagger/index.html (when implemented)", addr)
 
-	if err := router.Run(addr); err != nil {
+	if cfg.TLSCertFile == "" {
+		if err := router.Run(addr); err != nil {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+		return
+	}
+
+	tlsConfig, err := serverTLSConfig(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure TLS: %v", err)
+	}
+	server := &http.Server{
+		Addr:      addr,
+		Handler:   router,
+		TLSConfig: tlsConfig,
+	}
+	if err := server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// parseAuthConfig parses API_KEYS and ANONYMOUS_ROLE; "none" disables
+// anonymous access
+func parseAuthConfig(cfg *config.Config) (services.APIKeys, services.Role, error) {
+	keys, err := services.ParseAPIKeys(cfg.APIKeys)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid API_KEYS: %w", err)
+	}
+	if cfg.AnonymousRole == "none" {
+		return keys, "", nil
+	}
+	role, err := services.ParseRole(cfg.AnonymousRole)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid ANONYMOUS_ROLE: %w", err)
+	}
+	return keys, role, nil
+}
+
+// parseAllowlists parses the admin and ingestion source IP allowlists
+func parseAllowlists(cfg *config.Config) (admin, ingest []*net.IPNet, err error) {
+	if admin, err = services.ParseIPNets(cfg.AdminAllowedIPs); err != nil {
+		return nil, nil, fmt.Errorf("invalid ADMIN_ALLOWED_IPS: %w", err)
+	}
+	if ingest, err = services.ParseIPNets(cfg.IngestAllowedIPs); err != nil {
+		return nil, nil, fmt.Errorf("invalid INGEST_ALLOWED_IPS: %w", err)
+	}
+	return admin, ingest, nil
+}
+
+// parseOIDCConfig parses the OIDC_* settings. The redirect URL defaults to
+// the callback under PUBLIC_API_URL.
+func parseOIDCConfig(cfg *config.Config) (services.OIDCConfig, error) {
+	groupRoles, err := services.ParseGroupRoles(cfg.OIDCGroupRoles)
+	if err != nil {
+		return services.OIDCConfig{}, fmt.Errorf("invalid OIDC_GROUP_ROLES: %w", err)
+	}
+	var defaultRole services.Role
+	if cfg.OIDCDefaultRole != "none" {
+		if defaultRole, err = services.ParseRole(cfg.OIDCDefaultRole); err != nil {
+			return services.OIDCConfig{}, fmt.Errorf("invalid OIDC_DEFAULT_ROLE: %w", err)
+		}
+	}
+	redirectURL := cfg.OIDCRedirectURL
+	if redirectURL == "" {
+		if cfg.PublicAPIURL == "" {
+			return services.OIDCConfig{}, fmt.Errorf("OIDC_REDIRECT_URL or PUBLIC_API_URL is required for OIDC sign-in")
+		}
+		redirectURL = strings.TrimSuffix(cfg.PublicAPIURL, "/") + "/auth/oidc/callback"
+	}
+	if cfg.OIDCClientID == "" {
+		return services.OIDCConfig{}, fmt.Errorf("OIDC_CLIENT_ID is required for OIDC sign-in")
+	}
+	return services.OIDCConfig{
+		IssuerURL:     cfg.OIDCIssuerURL,
+		ClientID:      cfg.OIDCClientID,
+		ClientSecret:  cfg.OIDCClientSecret,
+		RedirectURL:   redirectURL,
+		Scopes:        services.SplitList(cfg.OIDCScopes),
+		GroupsClaim:   cfg.OIDCGroupsClaim,
+		UsernameClaim: cfg.OIDCUsernameClaim,
+		GroupRoles:    groupRoles,
+		DefaultRole:   defaultRole,
+	}, nil
+}
+
+// serverTLSConfig requires client certificates signed by TLS_CLIENT_CA_FILE
+// when one is configured, so remote agents authenticate with mTLS
+func serverTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.TLSClientCAFile == "" {
+		return tlsConfig, nil
+	}
+
+	caPEM, err := os.ReadFile(cfg.TLSClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in %s", cfg.TLSClientCAFile)
+	}
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	log.Printf("mTLS enabled: client certificates required")
+	return tlsConfig, nil
+}
@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+)
+
+// Agent runs a set of sources and ships what they produce
+type Agent struct {
+	shipper *shipper
+	sources []runningSource
+}
+
+type runningSource struct {
+	name      string
+	source    Source
+	processor *processor
+}
+
+// New builds an agent from its configuration
+func New(cfg *Config) (*Agent, error) {
+	shipper, err := newShipper(cfg.Server, cfg.Batch)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+
+	a := &Agent{shipper: shipper}
+	for _, srcCfg := range cfg.Sources {
+		source, err := sourceFactories[srcCfg.Type](srcCfg)
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", srcCfg.Name, err)
+		}
+		proc, err := newProcessor(srcCfg, hostname)
+		if err != nil {
+			return nil, err
+		}
+		a.sources = append(a.sources, runningSource{name: srcCfg.Name, source: source, processor: proc})
+	}
+	return a, nil
+}
+
+// Run collects and ships events until the context is cancelled, then flushes
+// the buffer before returning
+func (a *Agent) Run(ctx context.Context) {
+	shipperCtx, stopShipper := context.WithCancel(context.Background())
+	shipperDone := make(chan struct{})
+	go func() {
+		a.shipper.run(shipperCtx)
+		close(shipperDone)
+	}()
+
+	var wg sync.WaitGroup
+	for _, rs := range a.sources {
+		wg.Add(1)
+		go func(rs runningSource) {
+			defer wg.Done()
+			a.runSource(ctx, rs)
+		}(rs)
+	}
+
+	wg.Wait()
+	stopShipper()
+	<-shipperDone
+}
+
+func (a *Agent) runSource(ctx context.Context, rs runningSource) {
+	records := make(chan Record, 256)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		for rec := range records {
+			for _, event := range rs.processor.process(rec) {
+				a.shipper.enqueue(event)
+			}
+		}
+	}()
+
+	log.Printf("agent: started source %s", rs.name)
+	if err := rs.source.Run(ctx, records); err != nil {
+		log.Printf("agent: source %s stopped: %v", rs.name, err)
+	}
+	close(records)
+	<-done
+}
@@ -0,0 +1,159 @@
+// Package agent implements the remote collector: it reads log sources on a
+// host, filters and normalizes lines locally, and ships them to the server's
+// batch ingest endpoint.
+package agent
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the agent configuration file
+type Config struct {
+	Server  ServerConfig   `yaml:"server"`
+	Batch   BatchConfig    `yaml:"batch"`
+	Sources []SourceConfig `yaml:"sources"`
+}
+
+// ServerConfig describes where and how to ship events
+type ServerConfig struct {
+	URL      string        `yaml:"url"` // e.g. https://ir.example.com:8000/api/v1
+	CAFile   string        `yaml:"ca_file"`
+	CertFile string        `yaml:"cert_file"` // client certificate for mTLS
+	KeyFile  string        `yaml:"key_file"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+// BatchConfig controls batching and the retry buffer
+type BatchConfig struct {
+	Size          int           `yaml:"size"`           // events per request
+	FlushInterval time.Duration `yaml:"flush_interval"` // max time an event waits before shipping
+	BufferSize    int           `yaml:"buffer_size"`    // events held while the server is unreachable
+	MaxBackoff    time.Duration `yaml:"max_backoff"`
+}
+
+// SourceConfig describes one log source and how its lines become events
+type SourceConfig struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"` // file, journald, wineventlog
+
+	// file
+	Path          string `yaml:"path"`
+	FromBeginning bool   `yaml:"from_beginning"`
+
+	// journald
+	Units []string `yaml:"units"`
+
+	// wineventlog: channels such as Security or
+	// Microsoft-Windows-Sysmon/Operational, with an optional XPath query
+	Channels []string `yaml:"channels"`
+	Query    string   `yaml:"query"`
+
+	// Event defaults
+	EventType string `yaml:"event_type"`
+	Source    string `yaml:"source"`
+	Severity  string `yaml:"severity"`
+
+	// Parsing: "text" lines may be matched against Pattern, whose named
+	// groups become normalized fields; "json" lines are decoded directly;
+	// "auditd" groups audit.log records into process, login and
+	// permission-denied events.
+	Format  string            `yaml:"format"`
+	Pattern string            `yaml:"pattern"`
+	Fields  map[string]string `yaml:"fields"` // static fields added to every event
+
+	// Local filtering on the raw line
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+// LoadConfig reads and validates an agent config file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	cfg.applyDefaults()
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (c *Config) applyDefaults() {
+	if c.Server.Timeout <= 0 {
+		c.Server.Timeout = 10 * time.Second
+	}
+	if c.Batch.Size <= 0 {
+		c.Batch.Size = 200
+	}
+	if c.Batch.FlushInterval <= 0 {
+		c.Batch.FlushInterval = 5 * time.Second
+	}
+	if c.Batch.BufferSize < c.Batch.Size {
+		c.Batch.BufferSize = 10000
+	}
+	if c.Batch.MaxBackoff <= 0 {
+		c.Batch.MaxBackoff = time.Minute
+	}
+
+	for i := range c.Sources {
+		src := &c.Sources[i]
+		if src.Name == "" {
+			src.Name = fmt.Sprintf("%s-%d", src.Type, i)
+		}
+		if src.Format == "" {
+			src.Format = "text"
+		}
+		if src.Severity == "" {
+			src.Severity = "info"
+		}
+		if src.Type == "wineventlog" && src.EventType == "" {
+			// Known event IDs set their own event_type during normalization
+			src.EventType = "windows_event"
+		}
+		if src.Source == "" {
+			src.Source = src.Type
+		}
+	}
+}
+
+func (c *Config) validate() error {
+	if c.Server.URL == "" {
+		return fmt.Errorf("server.url is required")
+	}
+	if (c.Server.CertFile == "") != (c.Server.KeyFile == "") {
+		return fmt.Errorf("server.cert_file and server.key_file must be set together")
+	}
+	if len(c.Sources) == 0 {
+		return fmt.Errorf("at least one source is required")
+	}
+
+	for _, src := range c.Sources {
+		if _, ok := sourceFactories[src.Type]; !ok {
+			return fmt.Errorf("source %s: unknown type %q", src.Name, src.Type)
+		}
+		if src.Type == "file" && src.Path == "" {
+			return fmt.Errorf("source %s: path is required", src.Name)
+		}
+		if src.Type == "wineventlog" && len(src.Channels) == 0 {
+			return fmt.Errorf("source %s: channels is required", src.Name)
+		}
+		if src.EventType == "" && src.Format == "text" {
+			return fmt.Errorf("source %s: event_type is required", src.Name)
+		}
+		if src.Format != "text" && src.Format != "json" && src.Format != "auditd" {
+			return fmt.Errorf("source %s: format must be text, json or auditd", src.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/parsers"
+)
+
+// Event is the wire format accepted by POST /api/v1/events/batch
+type Event struct {
+	EventType  string                 `json:"event_type"`
+	Source     string                 `json:"source"`
+	Severity   string                 `json:"severity"`
+	RawData    map[string]interface{} `json:"raw_data,omitempty"`
+	Normalized map[string]interface{} `json:"normalized"`
+}
+
+// Record is a raw line read by a source plus anything the source already
+// parsed from it (e.g. journald metadata). A non-empty EventType or Severity
+// overrides the source defaults.
+type Record struct {
+	Line      string
+	Fields    map[string]interface{}
+	Time      time.Time
+	EventType string
+	Severity  string
+}
+
+// processor filters records and turns them into events for one source
+type processor struct {
+	cfg      SourceConfig
+	hostname string
+	include  []*regexp.Regexp
+	exclude  []*regexp.Regexp
+	pattern  *regexp.Regexp
+	audit    *parsers.AuditAssembler
+}
+
+func newProcessor(cfg SourceConfig, hostname string) (*processor, error) {
+	p := &processor{cfg: cfg, hostname: hostname}
+	if cfg.Format == "auditd" {
+		p.audit = parsers.NewAuditAssembler()
+	}
+
+	var err error
+	if p.include, err = compileAll(cfg.Include); err != nil {
+		return nil, fmt.Errorf("source %s: include: %w", cfg.Name, err)
+	}
+	if p.exclude, err = compileAll(cfg.Exclude); err != nil {
+		return nil, fmt.Errorf("source %s: exclude: %w", cfg.Name, err)
+	}
+	if cfg.Pattern != "" {
+		if p.pattern, err = regexp.Compile(cfg.Pattern); err != nil {
+			return nil, fmt.Errorf("source %s: pattern: %w", cfg.Name, err)
+		}
+	}
+	return p, nil
+}
+
+// process returns the events completed by a record. Most formats produce at
+// most one event per line; auditd groups several lines into one event.
+func (p *processor) process(rec Record) []Event {
+	if p.audit == nil {
+		if event, ok := p.toEvent(rec); ok {
+			return []Event{event}
+		}
+		return nil
+	}
+
+	var events []Event
+	for _, parsed := range p.audit.Add(rec.Line) {
+		if event, ok := p.toEvent(auditRecord(parsed)); ok {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// auditRecord wraps an assembled auditd event as a record
+func auditRecord(parsed parsers.Parsed) Record {
+	return Record{
+		Line:      parsed.Raw,
+		Fields:    parsed.Fields,
+		Time:      parsed.Timestamp,
+		EventType: parsed.EventType,
+		Severity:  parsed.Severity,
+	}
+}
+
+// toEvent filters and normalizes a record, returning false if it is dropped
+func (p *processor) toEvent(rec Record) (Event, bool) {
+	if !p.keep(rec.Line) {
+		return Event{}, false
+	}
+
+	normalized := make(map[string]interface{}, len(rec.Fields)+len(p.cfg.Fields)+4)
+	for k, v := range rec.Fields {
+		normalized[k] = v
+	}
+
+	switch p.cfg.Format {
+	case "json":
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(rec.Line), &fields); err != nil {
+			return Event{}, false
+		}
+		for k, v := range fields {
+			normalized[k] = v
+		}
+	default:
+		if p.pattern != nil {
+			match := p.pattern.FindStringSubmatch(rec.Line)
+			if match == nil {
+				// A pattern is a filter too: unmatched lines aren't shipped
+				return Event{}, false
+			}
+			for i, name := range p.pattern.SubexpNames() {
+				if name != "" && match[i] != "" {
+					normalized[name] = match[i]
+				}
+			}
+		}
+		if _, ok := normalized["message"]; !ok {
+			normalized["message"] = rec.Line
+		}
+	}
+
+	for k, v := range p.cfg.Fields {
+		normalized[k] = v
+	}
+	if _, ok := normalized["host"]; !ok && p.hostname != "" {
+		normalized["host"] = p.hostname
+	}
+	if !rec.Time.IsZero() {
+		normalized["@timestamp"] = rec.Time.UTC().Format(time.RFC3339Nano)
+	}
+
+	event := Event{
+		EventType:  p.cfg.EventType,
+		Source:     p.cfg.Source,
+		Severity:   p.cfg.Severity,
+		RawData:    map[string]interface{}{"line": rec.Line, "collector": p.cfg.Name},
+		Normalized: normalized,
+	}
+
+	// Parsed fields may override the source defaults
+	if rec.EventType != "" {
+		event.EventType = rec.EventType
+	}
+	if rec.Severity != "" {
+		event.Severity = rec.Severity
+	}
+	if v, ok := normalized["event_type"].(string); ok && v != "" {
+		event.EventType = v
+	}
+	if v, ok := normalized["severity"].(string); ok && v != "" {
+		event.Severity = v
+	}
+	if event.EventType == "" {
+		return Event{}, false
+	}
+	return event, true
+}
+
+// keep applies include/exclude filters: a line must match some include
+// pattern (when any are set) and no exclude pattern
+func (p *processor) keep(line string) bool {
+	for _, re := range p.exclude {
+		if re.MatchString(line) {
+			return false
+		}
+	}
+	if len(p.include) == 0 {
+		return true
+	}
+	for _, re := range p.include {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func compileAll(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
@@ -0,0 +1,240 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// shipper buffers events and posts them to the server in batches. While the
+// server is unreachable events stay buffered (dropping the oldest once the
+// buffer is full) and delivery is retried with exponential backoff.
+type shipper struct {
+	client *http.Client
+	url    string
+	cfg    BatchConfig
+
+	mu      sync.Mutex
+	buffer  []Event
+	dropped int
+	ready   chan struct{}
+}
+
+func newShipper(server ServerConfig, batch BatchConfig) (*shipper, error) {
+	client, err := newHTTPClient(server)
+	if err != nil {
+		return nil, err
+	}
+	return &shipper{
+		client: client,
+		url:    strings.TrimRight(server.URL, "/") + "/events/batch",
+		cfg:    batch,
+		ready:  make(chan struct{}, 1),
+	}, nil
+}
+
+// newHTTPClient builds a client that trusts ca_file and presents the client
+// certificate when one is configured (mTLS)
+func newHTTPClient(server ServerConfig) (*http.Client, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if server.CAFile != "" {
+		caPEM, err := os.ReadFile(server.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca_file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %s", server.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if server.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(server.CertFile, server.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return &http.Client{
+		Timeout:   server.Timeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}, nil
+}
+
+// enqueue adds an event to the buffer, evicting the oldest when full
+func (s *shipper) enqueue(event Event) {
+	s.mu.Lock()
+	if len(s.buffer) >= s.cfg.BufferSize {
+		s.buffer = s.buffer[1:]
+		s.dropped++
+	}
+	s.buffer = append(s.buffer, event)
+	full := len(s.buffer) >= s.cfg.Size
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.ready <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run ships batches until the context is cancelled, then makes one final
+// attempt to flush what is buffered
+func (s *shipper) run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	backoff := time.Duration(0)
+	for {
+		select {
+		case <-ctx.Done():
+			s.drain()
+			return
+		case <-ticker.C:
+		case <-s.ready:
+		}
+
+		for {
+			err := s.flushOne(ctx)
+			if err == errEmpty {
+				backoff = 0
+				break
+			}
+			if err != nil {
+				backoff = nextBackoff(backoff, s.cfg.MaxBackoff)
+				log.Printf("agent: ship failed, retrying in %s: %v", backoff, err)
+				select {
+				case <-ctx.Done():
+					s.drain()
+					return
+				case <-time.After(backoff):
+				}
+				continue
+			}
+			backoff = 0
+			if s.pending() < s.cfg.Size {
+				break
+			}
+		}
+	}
+}
+
+var errEmpty = fmt.Errorf("buffer empty")
+
+// flushOne ships the oldest batch and removes it from the buffer on success
+func (s *shipper) flushOne(ctx context.Context) error {
+	s.mu.Lock()
+	n := len(s.buffer)
+	if n == 0 {
+		s.mu.Unlock()
+		return errEmpty
+	}
+	if n > s.cfg.Size {
+		n = s.cfg.Size
+	}
+	batch := append([]Event(nil), s.buffer[:n]...)
+	dropped := s.dropped
+	s.dropped = 0
+	s.mu.Unlock()
+
+	if dropped > 0 {
+		log.Printf("agent: buffer full, dropped %d oldest events", dropped)
+	}
+
+	retry, err := s.post(ctx, batch)
+	if err != nil && retry {
+		return err
+	}
+	if err != nil {
+		// The server rejected the batch outright; resending won't help
+		log.Printf("agent: discarding batch of %d: %v", len(batch), err)
+	}
+
+	s.mu.Lock()
+	// Evictions while posting may already have removed part of the batch
+	remove := n
+	if remove > len(s.buffer) {
+		remove = len(s.buffer)
+	}
+	s.buffer = s.buffer[remove:]
+	s.mu.Unlock()
+	return nil
+}
+
+// post sends a batch; retry reports whether a failure is worth retrying
+func (s *shipper) post(ctx context.Context, batch []Event) (retry bool, err error) {
+	body, err := json.Marshal(map[string]interface{}{"events": batch})
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("server returned %d: %s", resp.StatusCode, msg)
+	default:
+		return false, fmt.Errorf("server returned %d: %s", resp.StatusCode, msg)
+	}
+}
+
+// drain makes a best-effort attempt to ship the buffer on shutdown
+func (s *shipper) drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for s.pending() > 0 {
+		if err := s.flushOne(ctx); err != nil {
+			if err != errEmpty {
+				log.Printf("agent: %d events not shipped before shutdown: %v", s.pending(), err)
+			}
+			return
+		}
+	}
+}
+
+func (s *shipper) pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buffer)
+}
+
+// nextBackoff doubles the previous delay from 1s, capped at max
+func nextBackoff(prev, max time.Duration) time.Duration {
+	next := prev * 2
+	if next < time.Second {
+		next = time.Second
+	}
+	if next > max {
+		next = max
+	}
+	return next
+}
@@ -0,0 +1,15 @@
+package agent
+
+import "context"
+
+// Source reads records from one log source until the context is cancelled
+type Source interface {
+	Run(ctx context.Context, out chan<- Record) error
+}
+
+// sourceFactories maps a source type to its constructor. Platform-specific
+// sources register themselves from files with build constraints.
+var sourceFactories = map[string]func(SourceConfig) (Source, error){
+	"file":     newFileSource,
+	"journald": newJournaldSource,
+}
@@ -0,0 +1,119 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// filePollInterval is how often a tailed file is checked for new data
+const filePollInterval = 500 * time.Millisecond
+
+// fileSource tails a log file, following truncation and rotation
+type fileSource struct {
+	path          string
+	fromBeginning bool
+}
+
+func newFileSource(cfg SourceConfig) (Source, error) {
+	return &fileSource{path: cfg.Path, fromBeginning: cfg.FromBeginning}, nil
+}
+
+// Run tails the file. It starts at the end unless from_beginning is set, and
+// reopens from the start when the file is truncated or replaced.
+func (s *fileSource) Run(ctx context.Context, out chan<- Record) error {
+	seekEnd := !s.fromBeginning
+	for {
+		err := s.follow(ctx, out, seekEnd)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil && !errors.Is(err, errRotated) {
+			log.Printf("agent: %s: %v", s.path, err)
+		}
+
+		// Anything after the first open is a new file: read it all
+		seekEnd = false
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(filePollInterval):
+		}
+	}
+}
+
+var errRotated = errors.New("file rotated")
+
+// follow reads one incarnation of the file until it is rotated or truncated
+func (s *fileSource) follow(ctx context.Context, out chan<- Record, seekEnd bool) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	var offset int64
+	if seekEnd {
+		if offset, err = f.Seek(0, io.SeekEnd); err != nil {
+			return err
+		}
+	}
+
+	reader := bufio.NewReader(f)
+	var partial strings.Builder
+	ticker := time.NewTicker(filePollInterval)
+	defer ticker.Stop()
+
+	for {
+		line, err := reader.ReadString('\n')
+		offset += int64(len(line))
+		if err == nil {
+			partial.WriteString(strings.TrimRight(line, "\r\n"))
+			record := Record{Line: partial.String(), Time: time.Now()}
+			partial.Reset()
+			if record.Line == "" {
+				continue
+			}
+			select {
+			case out <- record:
+			case <-ctx.Done():
+				return nil
+			}
+			continue
+		}
+		if !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		// Hold an unterminated line until the writer finishes it
+		partial.WriteString(line)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := os.Stat(s.path)
+		if err != nil {
+			// Mid-rotation: the old name is gone until the new file appears
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		if !os.SameFile(info, current) || current.Size() < offset {
+			return errRotated
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/parsers"
+)
+
+// journaldSource follows the systemd journal via journalctl's JSON output
+type journaldSource struct {
+	units         []string
+	fromBeginning bool
+}
+
+func newJournaldSource(cfg SourceConfig) (Source, error) {
+	if _, err := exec.LookPath("journalctl"); err != nil {
+		return nil, fmt.Errorf("journald source requires journalctl: %w", err)
+	}
+	return &journaldSource{units: cfg.Units, fromBeginning: cfg.FromBeginning}, nil
+}
+
+// Run streams journal entries until the context is cancelled
+func (s *journaldSource) Run(ctx context.Context, out chan<- Record) error {
+	args := []string{"--follow", "--output=json"}
+	if s.fromBeginning {
+		args = append(args, "--lines=all")
+	} else {
+		args = append(args, "--lines=0")
+	}
+	for _, unit := range s.units {
+		args = append(args, "--unit="+unit)
+	}
+
+	cmd := exec.CommandContext(ctx, "journalctl", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		parsed, ok := parsers.ParseJournalEntry(entry)
+		if !ok {
+			continue
+		}
+
+		record := Record{
+			Line:     parsed.Raw,
+			Fields:   parsed.Fields,
+			Time:     parsed.Timestamp,
+			Severity: parsed.Severity,
+		}
+		// Recognised security messages override the source's event_type
+		if parsed.EventType != parsers.JournalEventType {
+			record.EventType = parsed.EventType
+		}
+
+		select {
+		case out <- record:
+		case <-ctx.Done():
+		}
+	}
+
+	if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("journalctl exited: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package agent
+
+import "fmt"
+
+func init() {
+	sourceFactories["wineventlog"] = func(SourceConfig) (Source, error) {
+		return nil, fmt.Errorf("wineventlog sources are only supported on Windows")
+	}
+}
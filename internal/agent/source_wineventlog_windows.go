@@ -0,0 +1,186 @@
+//go:build windows
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+func init() {
+	sourceFactories["wineventlog"] = newWinEventLogSource
+}
+
+var (
+	modwevtapi    = windows.NewLazySystemDLL("wevtapi.dll")
+	procEvtSub    = modwevtapi.NewProc("EvtSubscribe")
+	procEvtNext   = modwevtapi.NewProc("EvtNext")
+	procEvtRender = modwevtapi.NewProc("EvtRender")
+	procEvtClose  = modwevtapi.NewProc("EvtClose")
+)
+
+const (
+	evtSubscribeToFutureEvents      = 1
+	evtSubscribeStartAtOldestRecord = 2
+	evtRenderEventXML               = 1
+
+	errorNoMoreItems        = syscall.Errno(259)
+	errorInsufficientBuffer = syscall.Errno(122)
+
+	// evtBatchSize is how many event handles are pulled per EvtNext call
+	evtBatchSize = 64
+	// evtWaitMillis bounds each wait so cancellation is noticed promptly
+	evtWaitMillis = 1000
+)
+
+// winEventLogSource subscribes to Windows Event Log channels
+type winEventLogSource struct {
+	channels      []string
+	query         string
+	fromBeginning bool
+}
+
+func newWinEventLogSource(cfg SourceConfig) (Source, error) {
+	if err := modwevtapi.Load(); err != nil {
+		return nil, fmt.Errorf("wevtapi.dll unavailable: %w", err)
+	}
+	return &winEventLogSource{
+		channels:      cfg.Channels,
+		query:         cfg.Query,
+		fromBeginning: cfg.FromBeginning,
+	}, nil
+}
+
+// Run subscribes to every configured channel and emits rendered events
+func (s *winEventLogSource) Run(ctx context.Context, out chan<- Record) error {
+	errs := make(chan error, len(s.channels))
+	for _, channel := range s.channels {
+		go func(channel string) {
+			errs <- s.subscribe(ctx, channel, out)
+		}(channel)
+	}
+
+	var firstErr error
+	for range s.channels {
+		if err := <-errs; err != nil {
+			log.Printf("agent: wineventlog %v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+func (s *winEventLogSource) subscribe(ctx context.Context, channel string, out chan<- Record) error {
+	signal, err := windows.CreateEvent(nil, 1, 1, nil)
+	if err != nil {
+		return fmt.Errorf("%s: CreateEvent: %w", channel, err)
+	}
+	defer windows.CloseHandle(signal)
+
+	channelPtr, err := windows.UTF16PtrFromString(channel)
+	if err != nil {
+		return err
+	}
+	query := s.query
+	if query == "" {
+		query = "*"
+	}
+	queryPtr, err := windows.UTF16PtrFromString(query)
+	if err != nil {
+		return err
+	}
+
+	flags := uintptr(evtSubscribeToFutureEvents)
+	if s.fromBeginning {
+		flags = evtSubscribeStartAtOldestRecord
+	}
+
+	sub, _, callErr := procEvtSub.Call(0, uintptr(signal),
+		uintptr(unsafe.Pointer(channelPtr)), uintptr(unsafe.Pointer(queryPtr)),
+		0, 0, 0, flags)
+	if sub == 0 {
+		return fmt.Errorf("%s: EvtSubscribe: %w", channel, callErr)
+	}
+	defer evtClose(sub)
+
+	handles := make([]uintptr, evtBatchSize)
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		event, err := windows.WaitForSingleObject(signal, evtWaitMillis)
+		if err != nil {
+			return fmt.Errorf("%s: wait: %w", channel, err)
+		}
+		if event == uint32(windows.WAIT_TIMEOUT) {
+			continue
+		}
+
+		for {
+			var returned uint32
+			ok, _, callErr := procEvtNext.Call(sub, evtBatchSize,
+				uintptr(unsafe.Pointer(&handles[0])), 0, 0,
+				uintptr(unsafe.Pointer(&returned)))
+			if ok == 0 {
+				if errors.Is(callErr, errorNoMoreItems) {
+					windows.ResetEvent(signal)
+					break
+				}
+				return fmt.Errorf("%s: EvtNext: %w", channel, callErr)
+			}
+
+			for _, h := range handles[:returned] {
+				rendered, err := evtRenderXML(h)
+				evtClose(h)
+				if err != nil {
+					log.Printf("agent: wineventlog %s: %v", channel, err)
+					continue
+				}
+				rec, err := parseWindowsEvent(rendered)
+				if err != nil {
+					continue
+				}
+				select {
+				case out <- rec:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// evtRenderXML renders an event handle to its XML representation
+func evtRenderXML(h uintptr) (string, error) {
+	var used, props uint32
+	procEvtRender.Call(0, h, evtRenderEventXML, 0, 0,
+		uintptr(unsafe.Pointer(&used)), uintptr(unsafe.Pointer(&props)))
+	if used == 0 {
+		return "", fmt.Errorf("EvtRender returned no data")
+	}
+
+	buf := make([]uint16, used/2+1)
+	ok, _, callErr := procEvtRender.Call(0, h, evtRenderEventXML, uintptr(len(buf)*2),
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(unsafe.Pointer(&used)), uintptr(unsafe.Pointer(&props)))
+	if ok == 0 {
+		if errors.Is(callErr, errorInsufficientBuffer) {
+			return "", fmt.Errorf("EvtRender: buffer too small")
+		}
+		return "", fmt.Errorf("EvtRender: %w", callErr)
+	}
+	return windows.UTF16ToString(buf), nil
+}
+
+func evtClose(h uintptr) {
+	procEvtClose.Call(h)
+}
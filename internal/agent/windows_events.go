@@ -0,0 +1,141 @@
+package agent
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// windowsEvent is the subset of the Windows event XML schema the agent reads
+type windowsEvent struct {
+	System struct {
+		Provider struct {
+			Name string `xml:"Name,attr"`
+		} `xml:"Provider"`
+		EventID     int `xml:"EventID"`
+		TimeCreated struct {
+			SystemTime string `xml:"SystemTime,attr"`
+		} `xml:"TimeCreated"`
+		Computer string `xml:"Computer"`
+		Channel  string `xml:"Channel"`
+	} `xml:"System"`
+	EventData struct {
+		Data []struct {
+			Name  string `xml:"Name,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"Data"`
+	} `xml:"EventData"`
+}
+
+const sysmonProvider = "Microsoft-Windows-Sysmon"
+
+// parseWindowsEvent converts rendered event XML into a record with
+// normalized fields
+func parseWindowsEvent(rendered string) (Record, error) {
+	var ev windowsEvent
+	if err := xml.Unmarshal([]byte(rendered), &ev); err != nil {
+		return Record{}, fmt.Errorf("invalid event XML: %w", err)
+	}
+
+	data := make(map[string]string, len(ev.EventData.Data))
+	for _, d := range ev.EventData.Data {
+		if d.Name != "" {
+			data[d.Name] = strings.TrimSpace(d.Value)
+		}
+	}
+
+	fields := normalizeWindowsEvent(ev.System.Provider.Name, ev.System.EventID, data)
+	fields["event_id"] = ev.System.EventID
+	fields["channel"] = ev.System.Channel
+	fields["provider"] = ev.System.Provider.Name
+	if ev.System.Computer != "" {
+		fields["host"] = ev.System.Computer
+	}
+	fields["message"] = fmt.Sprintf("%s event %d", ev.System.Channel, ev.System.EventID)
+
+	rec := Record{Line: rendered, Fields: fields}
+	if t, err := time.Parse(time.RFC3339Nano, ev.System.TimeCreated.SystemTime); err == nil {
+		rec.Time = t
+	}
+	return rec, nil
+}
+
+// normalizeWindowsEvent maps well-known Security and Sysmon event IDs onto
+// the field names detection rules use (source_ip, username, process_name,
+// parent_process, ...). Other events pass their EventData through as-is.
+func normalizeWindowsEvent(provider string, eventID int, data map[string]string) map[string]interface{} {
+	fields := make(map[string]interface{})
+	set := func(name, value string) {
+		if value != "" && value != "-" {
+			fields[name] = value
+		}
+	}
+
+	switch {
+	case provider == sysmonProvider && eventID == 1:
+		// Sysmon: process creation
+		fields["event_type"] = "process_execution"
+		set("process_name", baseName(data["Image"]))
+		set("executable", data["Image"])
+		set("command_line", data["CommandLine"])
+		set("parent_process", baseName(data["ParentImage"]))
+		set("parent_executable", data["ParentImage"])
+		set("parent_command_line", data["ParentCommandLine"])
+		set("process_id", data["ProcessId"])
+		set("username", data["User"])
+		set("hashes", data["Hashes"])
+
+	case provider == sysmonProvider && eventID == 3:
+		// Sysmon: network connection
+		fields["event_type"] = "network_connection"
+		set("source_ip", data["SourceIp"])
+		set("source_port", data["SourcePort"])
+		set("destination_ip", data["DestinationIp"])
+		set("destination_port", data["DestinationPort"])
+		set("destination_hostname", data["DestinationHostname"])
+		set("protocol", data["Protocol"])
+		set("process_name", baseName(data["Image"]))
+		set("executable", data["Image"])
+		set("username", data["User"])
+
+	case eventID == 4625:
+		// Security: an account failed to log on
+		fields["event_type"] = "authentication_failed"
+		set("username", data["TargetUserName"])
+		set("domain", data["TargetDomainName"])
+		set("source_ip", data["IpAddress"])
+		set("source_port", data["IpPort"])
+		set("workstation", data["WorkstationName"])
+		set("logon_type", data["LogonType"])
+		set("failure_reason", data["FailureReason"])
+		set("status", data["Status"])
+
+	case eventID == 4688:
+		// Security: a new process has been created
+		fields["event_type"] = "process_execution"
+		set("process_name", baseName(data["NewProcessName"]))
+		set("executable", data["NewProcessName"])
+		set("command_line", data["CommandLine"])
+		set("parent_process", baseName(data["ParentProcessName"]))
+		set("parent_executable", data["ParentProcessName"])
+		set("process_id", data["NewProcessId"])
+		set("username", data["SubjectUserName"])
+		set("domain", data["SubjectDomainName"])
+
+	default:
+		for name, value := range data {
+			set(name, value)
+		}
+	}
+	return fields
+}
+
+// baseName returns the file name from a Windows path
+func baseName(p string) string {
+	if p == "" {
+		return ""
+	}
+	return path.Base(strings.ReplaceAll(p, `\`, "/"))
+}
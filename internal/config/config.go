@@ -1,7 +1,9 @@
 package config
 
 import (
+	"fmt"
 	"log"
+	"os"
 	"github.com/spf13/viper"
 )
 
@@ -18,25 +20,279 @@ type Config struct {
 	APIHost   string `mapstructure:"API_HOST"`
 	APIPort   string `mapstructure:"API_PORT"`
 
+	// TLS (plain HTTP when cert is empty; client CA enables mTLS)
+	TLSCertFile     string `mapstructure:"TLS_CERT_FILE"`
+	TLSKeyFile      string `mapstructure:"TLS_KEY_FILE"`
+	TLSClientCAFile string `mapstructure:"TLS_CLIENT_CA_FILE"`
+
+	// API authentication: "name:role:key,..." with roles viewer, responder
+	// and admin. Requests without a key get AnonymousRole ("none" rejects them).
+	APIKeys       string `mapstructure:"API_KEYS"`
+	AnonymousRole string `mapstructure:"ANONYMOUS_ROLE"`
+	// Single sign-on through an OpenID Connect provider (Okta, Azure AD,
+	// Google); enabled when OIDC_ISSUER_URL is set. OIDC_GROUP_ROLES maps
+	// groups to roles ("group=role,..."); users in no mapped group get
+	// OIDC_DEFAULT_ROLE ("none" refuses them)
+	OIDCIssuerURL     string `mapstructure:"OIDC_ISSUER_URL"`
+	OIDCClientID      string `mapstructure:"OIDC_CLIENT_ID"`
+	OIDCClientSecret  string `mapstructure:"OIDC_CLIENT_SECRET"`
+	OIDCRedirectURL   string `mapstructure:"OIDC_REDIRECT_URL"`
+	OIDCScopes        string `mapstructure:"OIDC_SCOPES"`
+	OIDCGroupsClaim   string `mapstructure:"OIDC_GROUPS_CLAIM"`
+	OIDCUsernameClaim string `mapstructure:"OIDC_USERNAME_CLAIM"`
+	OIDCGroupRoles    string `mapstructure:"OIDC_GROUP_ROLES"`
+	OIDCDefaultRole   string `mapstructure:"OIDC_DEFAULT_ROLE"`
+	// How long a sign-in session lasts, and how long it may sit unused (0
+	// disables the idle timeout)
+	SessionLifetime    string `mapstructure:"SESSION_LIFETIME"`
+	SessionIdleTimeout string `mapstructure:"SESSION_IDLE_TIMEOUT"`
+	// Source IPs and CIDR ranges allowed to reach the admin and ingestion
+	// endpoints (empty allows any), and the proxies whose X-Forwarded-For
+	// and X-Real-IP headers name the client (empty trusts none)
+	AdminAllowedIPs  string `mapstructure:"ADMIN_ALLOWED_IPS"`
+	IngestAllowedIPs string `mapstructure:"INGEST_ALLOWED_IPS"`
+	TrustedProxies   string `mapstructure:"TRUSTED_PROXIES"`
+	// Per-role fields redacted from API responses
+	RedactionPolicyFile string `mapstructure:"REDACTION_POLICY_FILE"`
+	// PII detectors and where they mask; PIIHashKey keys hashed masks
+	PIIPolicyFile string `mapstructure:"PII_POLICY_FILE"`
+	PIIHashKey    string `mapstructure:"PII_HASH_KEY"`
+	// Roles and grants required to run playbooks and actions by hand
+	ExecutionPolicyFile string `mapstructure:"EXECUTION_POLICY_FILE"`
+	// Incident status workflows per category
+	WorkflowsFile string `mapstructure:"WORKFLOWS_FILE"`
+	// Business calendars per team that non-critical resolve-by clocks run on
+	SLACalendarsFile string `mapstructure:"SLA_CALENDARS_FILE"`
+
+	// gRPC API (disabled when port is empty)
+	GRPCPort string `mapstructure:"GRPC_PORT"`
+
 	// Database
 	DatabaseURL  string `mapstructure:"DATABASE_URL"`
 	DatabaseEcho bool   `mapstructure:"DATABASE_ECHO"`
 
+	DatabaseWAL           bool `mapstructure:"DATABASE_WAL"`
+	DatabaseBusyTimeout   int  `mapstructure:"DATABASE_BUSY_TIMEOUT"`   // milliseconds
+	DatabaseBatchSize     int  `mapstructure:"DATABASE_BATCH_SIZE"`     // 1 disables batching
+	DatabaseBatchInterval int  `mapstructure:"DATABASE_BATCH_INTERVAL"` // milliseconds
+
+	// Read replica that list, export and statistics queries use; empty
+	// reads from the primary
+	DatabaseReplicaURL string `mapstructure:"DATABASE_REPLICA_URL"`
+
+	// Dedicated databases for tenants whose events must stay apart
+	// (tenant=database URL,...), and the API key or user names belonging
+	// to each tenant (name=tenant,...)
+	TenantDatabases string `mapstructure:"TENANT_DATABASES"`
+	TenantMembers   string `mapstructure:"TENANT_MEMBERS"`
+
+	// Normalized fields kept in indexed columns, which count conditions and
+	// event filters on them use instead of scanning the JSON
+	HotFields string `mapstructure:"HOT_FIELDS"`
+
+	// Raw payloads of at least PayloadCompressionMinSize bytes are stored
+	// compressed with PayloadCompression ("gzip" or "none")
+	PayloadCompression        string `mapstructure:"PAYLOAD_COMPRESSION"`
+	PayloadCompressionMinSize int    `mapstructure:"PAYLOAD_COMPRESSION_MIN_SIZE"`
+
+	// Cold storage: raw payloads over RawDataOffloadThreshold bytes are moved
+	// every RawDataOffloadInterval seconds to RawDataStore (s3://bucket/prefix
+	// or a directory); empty keeps them in the database
+	RawDataStore            string `mapstructure:"RAW_DATA_STORE"`
+	RawDataOffloadThreshold int    `mapstructure:"RAW_DATA_OFFLOAD_THRESHOLD"`
+	RawDataOffloadInterval  int    `mapstructure:"RAW_DATA_OFFLOAD_INTERVAL"`
+	// S3Endpoint addresses an S3-compatible service such as MinIO; empty
+	// uses AWS
+	S3Endpoint        string `mapstructure:"S3_ENDPOINT"`
+	S3Region          string `mapstructure:"S3_REGION"`
+	S3AccessKeyID     string `mapstructure:"S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey string `mapstructure:"S3_SECRET_ACCESS_KEY"`
+	S3SessionToken    string `mapstructure:"S3_SESSION_TOKEN"`
+
+	// Fast-ack ingestion: events from these API key names are queued (up to
+	// FAST_ACK_QUEUE_SIZE) and acknowledged with 202 before they are stored
+	FastAckKeys      string `mapstructure:"FAST_ACK_KEYS"`
+	FastAckQueueSize int    `mapstructure:"FAST_ACK_QUEUE_SIZE"`
+
+	// Hash-chained audit log of event, incident and action writes
+	AuditLogEnabled bool `mapstructure:"AUDIT_LOG_ENABLED"`
+	// Every API request is recorded in the API access log, kept for
+	// APIAccessRetentionDays days (0 keeps them)
+	APIAccessLogEnabled    bool `mapstructure:"API_ACCESS_LOG_ENABLED"`
+	APIAccessRetentionDays int  `mapstructure:"API_ACCESS_RETENTION_DAYS"`
+
 	// Detection
 	RuleScanInterval   int `mapstructure:"RULE_SCAN_INTERVAL"`
 	CorrelationWindow  int `mapstructure:"CORRELATION_WINDOW"`
+	// A client-supplied occurred_at further than these many seconds ahead
+	// of or behind the receive time is flagged and ignored; 0 disables
+	EventMaxFutureSkew int `mapstructure:"EVENT_MAX_FUTURE_SKEW"`
+	EventMaxPastSkew   int `mapstructure:"EVENT_MAX_PAST_SKEW"`
+	// Events are evaluated in batches of up to DetectionBatchSize that share
+	// count condition queries, waiting up to DetectionBatchInterval
+	// milliseconds to fill; 1 evaluates each event on its own
+	DetectionBatchSize     int `mapstructure:"DETECTION_BATCH_SIZE"`
+	DetectionBatchInterval int `mapstructure:"DETECTION_BATCH_INTERVAL"`
+	// DetectionWorkers evaluate events highest severity first; an event
+	// waiting DetectionMaxWait milliseconds (0 disables) is taken ahead of
+	// higher severities. 0 workers evaluates every event as it arrives.
+	DetectionWorkers int `mapstructure:"DETECTION_WORKERS"`
+	DetectionMaxWait int `mapstructure:"DETECTION_MAX_WAIT"`
+	// Every ReprocessInterval seconds (0 disables) events left unprocessed
+	// for ReprocessAfter seconds are requeued, up to ReprocessMaxAttempts
+	// times each
+	ReprocessInterval    int `mapstructure:"REPROCESS_INTERVAL"`
+	ReprocessAfter       int `mapstructure:"REPROCESS_AFTER"`
+	ReprocessMaxAttempts int `mapstructure:"REPROCESS_MAX_ATTEMPTS"`
+	// DetectionTraces records how each event was evaluated, kept for
+	// DetectionTraceRetention seconds (0 keeps them)
+	DetectionTraces         bool `mapstructure:"DETECTION_TRACES"`
+	DetectionTraceRetention int  `mapstructure:"DETECTION_TRACE_RETENTION"`
+	// Incidents created within CampaignWindow seconds that share a source IP
+	// or user, or CampaignRuleBurst incidents from one rule, are grouped into
+	// a campaign; 0 disables automatic grouping
+	CampaignWindow    int `mapstructure:"CAMPAIGN_WINDOW"`
+	CampaignRuleBurst int `mapstructure:"CAMPAIGN_RULE_BURST"`
+	// Ordered enrichment stages run on events before detection
+	EnrichmentFile string `mapstructure:"ENRICHMENT_FILE"`
+	// GeoIP CSV files (comma-separated) locating login addresses
+	GeoIPDatabase string `mapstructure:"GEOIP_DATABASE"`
+	// Logins further apart than IMPOSSIBLE_TRAVEL_SPEED km/h (0 disables),
+	// ignoring moves under IMPOSSIBLE_TRAVEL_MIN_DISTANCE km
+	ImpossibleTravelSpeed       int    `mapstructure:"IMPOSSIBLE_TRAVEL_SPEED"`
+	ImpossibleTravelMinDistance int    `mapstructure:"IMPOSSIBLE_TRAVEL_MIN_DISTANCE"`
+	ImpossibleTravelEventTypes  string `mapstructure:"IMPOSSIBLE_TRAVEL_EVENT_TYPES"`
+	// Entity risk scores halve every RISK_HALF_LIFE seconds (0 never decays);
+	// reaching RISK_THRESHOLD raises risk_threshold_exceeded (0 disables)
+	RiskHalfLife  int `mapstructure:"RISK_HALF_LIFE"`
+	RiskThreshold int `mapstructure:"RISK_THRESHOLD"`
+	// Organization working hours ("mon-fri 09:00-17:00") in an IANA timezone,
+	// tested by business_hours, outside_business_hours and weekend conditions
+	BusinessHours         string `mapstructure:"BUSINESS_HOURS"`
+	BusinessHoursTimezone string `mapstructure:"BUSINESS_HOURS_TIMEZONE"`
+	// Severity by impact (rows) and urgency (columns), both scored from 1;
+	// rules and create_incident with impact and urgency take their severity
+	// from it. Empty disables
+	SeverityMatrix string `mapstructure:"SEVERITY_MATRIX"`
 
 	// Orchestration
 	PlaybookTimeout    int `mapstructure:"PLAYBOOK_TIMEOUT"`
 	MaxPlaybookRetries int `mapstructure:"MAX_PLAYBOOK_RETRIES"`
+	// Environment profile for runs that don't select one (rule-triggered runs)
+	PlaybookEnvironment string `mapstructure:"PLAYBOOK_ENVIRONMENT"`
+
+	// Action concurrency ("ssh_command=2,http_request=5"), applied per
+	// target host or domain; excess calls queue up to the timeout
+	ActionConcurrencyLimits string `mapstructure:"ACTION_CONCURRENCY_LIMITS"`
+	ActionQueueTimeout      int    `mapstructure:"ACTION_QUEUE_TIMEOUT"` // seconds
+
+	// Hourly execution quotas ("runs=100,actions=500,block_ip=50") per rule
+	// and per tenant: playbook runs, actions with external side effects and
+	// runs of named actions
+	ExecutionQuotas string `mapstructure:"EXECUTION_QUOTAS"`
+
+	// Outbox (notifications and playbook runs queued with their incident)
+	OutboxPollInterval int `mapstructure:"OUTBOX_POLL_INTERVAL"` // seconds
+	OutboxMaxAttempts  int `mapstructure:"OUTBOX_MAX_ATTEMPTS"`
+
+	// Telephony (SMS and voice escalation; simulated when the account SID is empty)
+	TwilioAccountSID string `mapstructure:"TWILIO_ACCOUNT_SID"`
+	TwilioAuthToken  string `mapstructure:"TWILIO_AUTH_TOKEN"`
+	TwilioFromNumber string `mapstructure:"TWILIO_FROM_NUMBER"`
+	// PublicAPIURL is the externally reachable API base (e.g.
+	// https://ir.example.com/api/v1) that Twilio posts keypresses to
+	PublicAPIURL string `mapstructure:"PUBLIC_API_URL"`
+
+	// War rooms (open_war_room; each provider is simulated until configured)
+	SlackBotToken     string `mapstructure:"SLACK_BOT_TOKEN"`
+	TeamsTenantID     string `mapstructure:"TEAMS_TENANT_ID"`
+	TeamsClientID     string `mapstructure:"TEAMS_CLIENT_ID"`
+	TeamsClientSecret string `mapstructure:"TEAMS_CLIENT_SECRET"`
+	TeamsOrganizer    string `mapstructure:"TEAMS_ORGANIZER"` // user the meetings are created for
+	ZoomAccountID     string `mapstructure:"ZOOM_ACCOUNT_ID"`
+	ZoomClientID      string `mapstructure:"ZOOM_CLIENT_ID"`
+	ZoomClientSecret  string `mapstructure:"ZOOM_CLIENT_SECRET"`
+	// Emails invited to every war room, and per owning team
+	// ("security=a@example.com|b@example.com,payments=c@example.com")
+	WarRoomOncall string `mapstructure:"WAR_ROOM_ONCALL"`
+	WarRoomTeams  string `mapstructure:"WAR_ROOM_TEAMS"`
+
+	// Upstream alerts: resolve incidents once all linked alerts resolve
+	// (rules can override with auto_resolve)
+	AlertAutoResolve bool `mapstructure:"ALERT_AUTO_RESOLVE"`
+
+	// Stale incidents: per-severity idle periods ("info=72h,low=168h"; empty
+	// disables), the warning lead time and resolve or flag
+	StaleIncidentThresholds string `mapstructure:"STALE_INCIDENT_THRESHOLDS"`
+	StaleIncidentGrace      string `mapstructure:"STALE_INCIDENT_GRACE"`
+	StaleIncidentAction     string `mapstructure:"STALE_INCIDENT_ACTION"`
+
+	// Stakeholder updates: per-severity intervals after which the incident
+	// commander is reminded to send one ("critical=30m"; empty disables)
+	StakeholderUpdateCadence string `mapstructure:"STAKEHOLDER_UPDATE_CADENCE"`
+
+	// LLM drafting of shift handoffs (Anthropic Messages API); without a key
+	// handoffs are summarized from the incident timeline
+	LLMAPIKey string `mapstructure:"LLM_API_KEY"`
+	LLMModel  string `mapstructure:"LLM_MODEL"`
+	LLMAPIURL string `mapstructure:"LLM_API_URL"`
+
+	// Feature flags for automated subsystems ("auto_remediation=false,...");
+	// unset flags use their built-in defaults
+	FeatureFlags string `mapstructure:"FEATURE_FLAGS"`
+
+	// Self-monitoring: emit the service's own failures as events
+	SelfMonitoringEnabled           bool    `mapstructure:"SELF_MONITORING_ENABLED"`
+	SelfMonitoringInterval          int     `mapstructure:"SELF_MONITORING_INTERVAL"` // seconds
+	SelfMonitoringActionFailureRate float64 `mapstructure:"SELF_MONITORING_ACTION_FAILURE_RATE"`
+	SelfMonitoringActionFailureMin  int     `mapstructure:"SELF_MONITORING_ACTION_FAILURE_MIN"`
+	SelfMonitoringQueueBacklog      int     `mapstructure:"SELF_MONITORING_QUEUE_BACKLOG"`
+	SelfMonitoringQueueMaxAge       int     `mapstructure:"SELF_MONITORING_QUEUE_MAX_AGE"` // seconds
+	SelfMonitoringCooldown          int     `mapstructure:"SELF_MONITORING_COOLDOWN"`      // seconds
+
+	// Maintenance calendars: iCal feeds imported as suppressions
+	// ("name=url,...") and how often they are re-fetched
+	MaintenanceCalendars            string `mapstructure:"MAINTENANCE_CALENDARS"`
+	MaintenanceCalendarSyncInterval int    `mapstructure:"MAINTENANCE_CALENDAR_SYNC_INTERVAL"` // seconds
 
 	// Logging
 	LogLevel  string `mapstructure:"LOG_LEVEL"`
 	LogFormat string `mapstructure:"LOG_FORMAT"`
 
 	// Paths
-	RulesDir     string `mapstructure:"RULES_DIR"`
-	PlaybooksDir string `mapstructure:"PLAYBOOKS_DIR"`
+	RulesDir              string `mapstructure:"RULES_DIR"`
+	PlaybooksDir          string `mapstructure:"PLAYBOOKS_DIR"`
+	ScenariosDir          string `mapstructure:"SCENARIOS_DIR"`
+	NotificationRoutesDir string `mapstructure:"NOTIFICATION_ROUTES_DIR"`
+	CommsTemplatesDir     string `mapstructure:"COMMS_TEMPLATES_DIR"`
+	WatchlistsDir         string `mapstructure:"WATCHLISTS_DIR"`
+	ContentPacksDir       string `mapstructure:"CONTENT_PACKS_DIR"`
+	PythonVenvsDir        string `mapstructure:"PYTHON_VENVS_DIR"` // cached virtualenvs for python_script requirements
+
+	// ContentPackRegistryURL serves index.json and pack archives over HTTPS;
+	// empty installs from CONTENT_PACKS_DIR only. ContentPackRegistryKey is
+	// the base64 Ed25519 public key the registry's index is signed with.
+	ContentPackRegistryURL string `mapstructure:"CONTENT_PACK_REGISTRY_URL"`
+	ContentPackRegistryKey string `mapstructure:"CONTENT_PACK_REGISTRY_KEY"`
+
+	// Backups: archives of the database and content directories kept in
+	// BackupDir (the newest BackupRetain; 0 keeps all), copied to BackupStore
+	// (s3://bucket/prefix or a directory) when set, and taken every
+	// BackupInterval seconds (0 only on demand)
+	BackupDir      string `mapstructure:"BACKUP_DIR"`
+	BackupRetain   int    `mapstructure:"BACKUP_RETAIN"`
+	BackupStore    string `mapstructure:"BACKUP_STORE"`
+	BackupInterval int    `mapstructure:"BACKUP_INTERVAL"`
+
+	// Coordination (leader election for background jobs)
+	InstanceID     string `mapstructure:"INSTANCE_ID"`
+	LeaderLeaseTTL int    `mapstructure:"LEADER_LEASE_TTL"` // seconds
+
+	// Stats
+	StatsCacheTTL int `mapstructure:"STATS_CACHE_TTL"`
+
+	// Status page (disabled when token is empty)
+	StatusPageToken string `mapstructure:"STATUS_PAGE_TOKEN"`
 }
 
 // LoadConfig loads configuration from environment variables and .env file
@@ -50,21 +306,152 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("API_PREFIX", "/api/v1")
 	viper.SetDefault("API_HOST", "0.0.0.0")
 	viper.SetDefault("API_PORT", "8000")
+	viper.SetDefault("TLS_CERT_FILE", "")
+	viper.SetDefault("TLS_KEY_FILE", "")
+	viper.SetDefault("TLS_CLIENT_CA_FILE", "")
+	viper.SetDefault("API_KEYS", "")
+	viper.SetDefault("ANONYMOUS_ROLE", "admin")
+	viper.SetDefault("ADMIN_ALLOWED_IPS", "")
+	viper.SetDefault("INGEST_ALLOWED_IPS", "")
+	viper.SetDefault("TRUSTED_PROXIES", "")
+	viper.SetDefault("OIDC_ISSUER_URL", "")
+	viper.SetDefault("OIDC_CLIENT_ID", "")
+	viper.SetDefault("OIDC_CLIENT_SECRET", "")
+	viper.SetDefault("OIDC_REDIRECT_URL", "")
+	viper.SetDefault("OIDC_SCOPES", "openid,profile,email")
+	viper.SetDefault("OIDC_GROUPS_CLAIM", "groups")
+	viper.SetDefault("OIDC_USERNAME_CLAIM", "email")
+	viper.SetDefault("OIDC_GROUP_ROLES", "")
+	viper.SetDefault("OIDC_DEFAULT_ROLE", "viewer")
+	viper.SetDefault("SESSION_LIFETIME", "12h")
+	viper.SetDefault("SESSION_IDLE_TIMEOUT", "2h")
+	viper.SetDefault("REDACTION_POLICY_FILE", "./data/redaction.yaml")
+	viper.SetDefault("PII_POLICY_FILE", "./data/pii.yaml")
+	viper.SetDefault("PII_HASH_KEY", "")
+	viper.SetDefault("EXECUTION_POLICY_FILE", "./data/execution_policy.yaml")
+	viper.SetDefault("WORKFLOWS_FILE", "./data/workflows.yaml")
+	viper.SetDefault("SLA_CALENDARS_FILE", "./data/sla_calendars.yaml")
+	viper.SetDefault("GRPC_PORT", "")
 
 	viper.SetDefault("DATABASE_URL", "./data/incidents.db")
 	viper.SetDefault("DATABASE_ECHO", false)
+	viper.SetDefault("DATABASE_WAL", true)
+	viper.SetDefault("DATABASE_BUSY_TIMEOUT", 5000)
+	viper.SetDefault("DATABASE_BATCH_SIZE", 100)
+	viper.SetDefault("DATABASE_REPLICA_URL", "")
+	viper.SetDefault("TENANT_DATABASES", "")
+	viper.SetDefault("TENANT_MEMBERS", "")
+	viper.SetDefault("HOT_FIELDS", "source_ip,username,host")
+	viper.SetDefault("PAYLOAD_COMPRESSION", "none")
+	viper.SetDefault("PAYLOAD_COMPRESSION_MIN_SIZE", 512)
+	viper.SetDefault("RAW_DATA_STORE", "")
+	viper.SetDefault("RAW_DATA_OFFLOAD_THRESHOLD", 4096)
+	viper.SetDefault("RAW_DATA_OFFLOAD_INTERVAL", 60)
+	viper.SetDefault("S3_ENDPOINT", "")
+	viper.SetDefault("S3_REGION", "us-east-1")
+	viper.SetDefault("S3_ACCESS_KEY_ID", "")
+	viper.SetDefault("S3_SECRET_ACCESS_KEY", "")
+	viper.SetDefault("S3_SESSION_TOKEN", "")
+	viper.SetDefault("DATABASE_BATCH_INTERVAL", 0)
+	viper.SetDefault("FAST_ACK_KEYS", "")
+	viper.SetDefault("FAST_ACK_QUEUE_SIZE", 10000)
+	viper.SetDefault("AUDIT_LOG_ENABLED", true)
+	viper.SetDefault("API_ACCESS_LOG_ENABLED", true)
+	viper.SetDefault("API_ACCESS_RETENTION_DAYS", 90)
 
 	viper.SetDefault("RULE_SCAN_INTERVAL", 60)
 	viper.SetDefault("CORRELATION_WINDOW", 300)
+	viper.SetDefault("EVENT_MAX_FUTURE_SKEW", 300)
+	viper.SetDefault("EVENT_MAX_PAST_SKEW", 604800)
+	viper.SetDefault("DETECTION_BATCH_SIZE", 50)
+	viper.SetDefault("DETECTION_BATCH_INTERVAL", 0)
+	viper.SetDefault("DETECTION_WORKERS", 4)
+	viper.SetDefault("DETECTION_MAX_WAIT", 5000)
+	viper.SetDefault("REPROCESS_INTERVAL", 60)
+	viper.SetDefault("REPROCESS_AFTER", 300)
+	viper.SetDefault("REPROCESS_MAX_ATTEMPTS", 3)
+	viper.SetDefault("DETECTION_TRACES", true)
+	viper.SetDefault("DETECTION_TRACE_RETENTION", 604800)
+	viper.SetDefault("CAMPAIGN_WINDOW", 86400)
+	viper.SetDefault("CAMPAIGN_RULE_BURST", 3)
+	viper.SetDefault("ENRICHMENT_FILE", "./data/enrichment.yaml")
+	viper.SetDefault("GEOIP_DATABASE", "")
+	viper.SetDefault("IMPOSSIBLE_TRAVEL_SPEED", 1000)
+	viper.SetDefault("IMPOSSIBLE_TRAVEL_MIN_DISTANCE", 500)
+	viper.SetDefault("IMPOSSIBLE_TRAVEL_EVENT_TYPES", "authentication_success")
+	viper.SetDefault("RISK_HALF_LIFE", 86400)
+	viper.SetDefault("RISK_THRESHOLD", 100)
+	viper.SetDefault("BUSINESS_HOURS", "mon-fri 09:00-17:00")
+	viper.SetDefault("BUSINESS_HOURS_TIMEZONE", "UTC")
+	viper.SetDefault("SEVERITY_MATRIX", "low,low,medium;low,medium,high;medium,high,critical")
 
 	viper.SetDefault("PLAYBOOK_TIMEOUT", 3600)
 	viper.SetDefault("MAX_PLAYBOOK_RETRIES", 3)
+	viper.SetDefault("PLAYBOOK_ENVIRONMENT", "")
+	viper.SetDefault("ACTION_CONCURRENCY_LIMITS", "ssh_command=2,http_request=5,webhook=5")
+	viper.SetDefault("ACTION_QUEUE_TIMEOUT", 300)
+	viper.SetDefault("EXECUTION_QUOTAS", "runs=100,actions=500")
+
+	viper.SetDefault("OUTBOX_POLL_INTERVAL", 1)
+	viper.SetDefault("OUTBOX_MAX_ATTEMPTS", 5)
+
+	viper.SetDefault("TWILIO_ACCOUNT_SID", "")
+	viper.SetDefault("TWILIO_AUTH_TOKEN", "")
+	viper.SetDefault("TWILIO_FROM_NUMBER", "")
+	viper.SetDefault("PUBLIC_API_URL", "")
+	viper.SetDefault("SLACK_BOT_TOKEN", "")
+	viper.SetDefault("TEAMS_TENANT_ID", "")
+	viper.SetDefault("TEAMS_CLIENT_ID", "")
+	viper.SetDefault("TEAMS_CLIENT_SECRET", "")
+	viper.SetDefault("TEAMS_ORGANIZER", "")
+	viper.SetDefault("ZOOM_ACCOUNT_ID", "")
+	viper.SetDefault("ZOOM_CLIENT_ID", "")
+	viper.SetDefault("ZOOM_CLIENT_SECRET", "")
+	viper.SetDefault("WAR_ROOM_ONCALL", "")
+	viper.SetDefault("WAR_ROOM_TEAMS", "")
+	viper.SetDefault("ALERT_AUTO_RESOLVE", true)
+	viper.SetDefault("STALE_INCIDENT_THRESHOLDS", "")
+	viper.SetDefault("STALE_INCIDENT_GRACE", "24h")
+	viper.SetDefault("STALE_INCIDENT_ACTION", "resolve")
+	viper.SetDefault("STAKEHOLDER_UPDATE_CADENCE", "critical=30m")
+	viper.SetDefault("LLM_API_KEY", "")
+	viper.SetDefault("LLM_MODEL", "claude-sonnet-4")
+	viper.SetDefault("LLM_API_URL", "https://api.anthropic.com/v1/messages")
+	viper.SetDefault("FEATURE_FLAGS", "")
+	viper.SetDefault("SELF_MONITORING_ENABLED", false)
+	viper.SetDefault("SELF_MONITORING_INTERVAL", 60)
+	viper.SetDefault("SELF_MONITORING_ACTION_FAILURE_RATE", 0.5)
+	viper.SetDefault("SELF_MONITORING_ACTION_FAILURE_MIN", 5)
+	viper.SetDefault("SELF_MONITORING_QUEUE_BACKLOG", 100)
+	viper.SetDefault("SELF_MONITORING_QUEUE_MAX_AGE", 300)
+	viper.SetDefault("SELF_MONITORING_COOLDOWN", 900)
+	viper.SetDefault("MAINTENANCE_CALENDARS", "")
+	viper.SetDefault("MAINTENANCE_CALENDAR_SYNC_INTERVAL", 900)
 
 	viper.SetDefault("LOG_LEVEL", "INFO")
 	viper.SetDefault("LOG_FORMAT", "json")
 
 	viper.SetDefault("RULES_DIR", "./data/rules")
 	viper.SetDefault("PLAYBOOKS_DIR", "./data/playbooks")
+	viper.SetDefault("SCENARIOS_DIR", "./data/scenarios")
+	viper.SetDefault("NOTIFICATION_ROUTES_DIR", "./data/notification_routes")
+	viper.SetDefault("COMMS_TEMPLATES_DIR", "./data/comms_templates")
+	viper.SetDefault("WATCHLISTS_DIR", "./data/watchlists")
+	viper.SetDefault("CONTENT_PACKS_DIR", "./data/packs")
+	viper.SetDefault("PYTHON_VENVS_DIR", "./data/venvs")
+	viper.SetDefault("CONTENT_PACK_REGISTRY_URL", "")
+	viper.SetDefault("CONTENT_PACK_REGISTRY_KEY", "")
+	viper.SetDefault("BACKUP_DIR", "./data/backups")
+	viper.SetDefault("BACKUP_RETAIN", 7)
+	viper.SetDefault("BACKUP_STORE", "")
+	viper.SetDefault("BACKUP_INTERVAL", 0)
+
+	viper.SetDefault("INSTANCE_ID", defaultInstanceID())
+	viper.SetDefault("LEADER_LEASE_TTL", 15)
+
+	viper.SetDefault("STATS_CACHE_TTL", 30)
+
+	viper.SetDefault("STATUS_PAGE_TOKEN", "")
 
 	// Read from .env file if it exists
 	viper.SetConfigFile(".env")
@@ -87,3 +474,12 @@ func LoadConfig() (*Config, error) {
 
 	return config, nil
 }
+
+// defaultInstanceID identifies this process as hostname-pid
+func defaultInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
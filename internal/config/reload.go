@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// reloadableKeys are the settings a reload applies to the running process;
+// changes to anything else are reported as needing a restart
+var reloadableKeys = map[string]bool{
+	"LOG_LEVEL":          true,
+	"API_KEYS":           true,
+	"ANONYMOUS_ROLE":     true,
+	"ADMIN_ALLOWED_IPS":  true,
+	"INGEST_ALLOWED_IPS": true,
+	"TWILIO_ACCOUNT_SID": true,
+	"TWILIO_AUTH_TOKEN":  true,
+	"TWILIO_FROM_NUMBER": true,
+	"PUBLIC_API_URL":     true,
+	"ALERT_AUTO_RESOLVE": true,
+	"FEATURE_FLAGS":      true,
+}
+
+// secretKeySuffixes mark settings whose values are hidden by Redacted
+var secretKeySuffixes = []string{"_TOKEN", "_KEYS", "_KEY", "_SECRET", "_PASSWORD", "_SID"}
+
+// ReloadHook validates a reloaded config and returns the function that
+// applies it. Every hook is validated before any is applied, so an invalid
+// value leaves the running configuration untouched.
+type ReloadHook func(cfg *Config) (apply func(), err error)
+
+// ReloadResult reports what a reload changed
+type ReloadResult struct {
+	Applied         []string `json:"applied"`
+	RestartRequired []string `json:"restart_required"`
+}
+
+// Reloader holds the effective configuration and re-reads it on demand
+type Reloader struct {
+	mu      sync.Mutex
+	current *Config
+	hooks   []ReloadHook
+}
+
+// NewReloader creates a reloader starting from cfg
+func NewReloader(cfg *Config) *Reloader {
+	return &Reloader{current: cfg}
+}
+
+// OnReload registers a hook run on every reload
+func (r *Reloader) OnReload(hook ReloadHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, hook)
+}
+
+// Current returns a copy of the effective configuration
+func (r *Reloader) Current() Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.current
+}
+
+// Reload re-reads the .env file and environment and applies changed
+// reloadable settings. Other changed settings keep their running values and
+// are listed as needing a restart.
+func (r *Reloader) Reload() (*ReloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	loaded, err := LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Start from the running config and take only reloadable changes
+	next := *r.current
+	result := &ReloadResult{Applied: []string{}, RestartRequired: []string{}}
+	cur := reflect.ValueOf(&next).Elem()
+	fresh := reflect.ValueOf(loaded).Elem()
+	for i := 0; i < cur.NumField(); i++ {
+		key := cur.Type().Field(i).Tag.Get("mapstructure")
+		if reflect.DeepEqual(cur.Field(i).Interface(), fresh.Field(i).Interface()) {
+			continue
+		}
+		if reloadableKeys[key] {
+			cur.Field(i).Set(fresh.Field(i))
+			result.Applied = append(result.Applied, key)
+		} else {
+			result.RestartRequired = append(result.RestartRequired, key)
+		}
+	}
+	sort.Strings(result.Applied)
+	sort.Strings(result.RestartRequired)
+
+	if len(result.Applied) == 0 {
+		return result, nil
+	}
+
+	applies := make([]func(), 0, len(r.hooks))
+	for _, hook := range r.hooks {
+		apply, err := hook(&next)
+		if err != nil {
+			return nil, err
+		}
+		applies = append(applies, apply)
+	}
+	for _, apply := range applies {
+		apply()
+	}
+	r.current = &next
+	return result, nil
+}
+
+// Redacted returns the configuration keyed by setting name, with secrets
+// hidden
+func Redacted(cfg Config) map[string]interface{} {
+	out := make(map[string]interface{})
+	v := reflect.ValueOf(cfg)
+	for i := 0; i < v.NumField(); i++ {
+		key := v.Type().Field(i).Tag.Get("mapstructure")
+		value := v.Field(i).Interface()
+		if isSecretKey(key) {
+			if s, ok := value.(string); ok && s != "" {
+				value = "[REDACTED]"
+			}
+		}
+		out[key] = value
+	}
+	return out
+}
+
+// ReloadableKeys lists the settings a reload can change, sorted
+func ReloadableKeys() []string {
+	keys := make([]string, 0, len(reloadableKeys))
+	for key := range reloadableKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isSecretKey(key string) bool {
+	for _, suffix := range secretKeySuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
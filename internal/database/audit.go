@@ -0,0 +1,254 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// auditedTables maps the tables whose writes are audited to the entity type
+// recorded for them
+var auditedTables = map[string]string{
+	"events":              "event",
+	"incidents":           "incident",
+	"action_logs":         "action",
+	"playbook_runs":       "playbook_run",
+	"incident_tasks":      "incident_task",
+	"incident_comments":   "incident_comment",
+	"feature_flags":       "feature_flag",
+	"action_approvals":    "approval",
+	"approval_decisions":  "approval_decision",
+	"stakeholder_updates": "stakeholder_update",
+	"incident_roles":      "incident_role",
+	"incident_handoffs":   "incident_handoff",
+	"users":               "user",
+	"revoked_credentials": "revoked_credential",
+}
+
+// auditActorKey is the context key carrying the actor recorded in the audit log
+type auditActorKey struct{}
+
+// WithAuditActor returns a context whose database writes are attributed to
+// actor in the audit log (default "system")
+func WithAuditActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// EnableAuditLog registers callbacks that append a hash-chained audit entry,
+// in the same transaction, for every insert, update and delete on an audited
+// table, and makes the audit table append-only
+func EnableAuditLog(db *gorm.DB) error {
+	for _, op := range []string{"update", "delete"} {
+		stmt := fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS audit_log_no_%s BEFORE %s ON audit_log
+BEGIN SELECT RAISE(ABORT, 'audit log is append-only'); END`, op, op)
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to create audit trigger: %w", err)
+		}
+	}
+
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:commit_or_rollback_transaction").After("gorm:after_create").
+		Register("audit:create", auditCallback(models.AuditCreate)); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:commit_or_rollback_transaction").After("gorm:after_update").
+		Register("audit:update", auditCallback(models.AuditUpdate)); err != nil {
+		return err
+	}
+	return callbacks.Delete().Before("gorm:commit_or_rollback_transaction").After("gorm:after_delete").
+		Register("audit:delete", auditCallback(models.AuditDelete))
+}
+
+// auditCallback records the rows written by a statement. It runs after the
+// write, while the transaction holds SQLite's write lock, so reading the
+// chain head and appending to it can't interleave with another writer.
+func auditCallback(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil || db.RowsAffected == 0 || db.Statement.Schema == nil {
+			return
+		}
+		entityType, ok := auditedTables[db.Statement.Schema.Table]
+		if !ok {
+			return
+		}
+
+		actor := "system"
+		if ctx := db.Statement.Context; ctx != nil {
+			if a, ok := ctx.Value(auditActorKey{}).(string); ok && a != "" {
+				actor = a
+			}
+		}
+
+		entries, err := auditEntries(db, operation, entityType, actor)
+		if err != nil {
+			db.AddError(fmt.Errorf("failed to build audit entry: %w", err))
+			return
+		}
+		if err := appendAuditEntries(db.Session(&gorm.Session{NewDB: true}), entries); err != nil {
+			db.AddError(err)
+		}
+	}
+}
+
+// auditEntries builds one unchained entry per row the statement wrote
+func auditEntries(db *gorm.DB, operation, entityType, actor string) ([]*models.AuditEntry, error) {
+	stmt := db.Statement
+	var rows []reflect.Value
+	switch rv := reflect.Indirect(stmt.ReflectValue); rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			rows = append(rows, reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		rows = append(rows, rv)
+	}
+
+	// Updates given as a map record just the changed columns
+	var changes interface{}
+	if operation == models.AuditUpdate {
+		if m, ok := stmt.Dest.(map[string]interface{}); ok {
+			changes = m
+		}
+	}
+
+	entries := make([]*models.AuditEntry, 0, len(rows))
+	for _, row := range rows {
+		entityID := ""
+		if field := stmt.Schema.PrioritizedPrimaryField; field != nil {
+			if value, zero := field.ValueOf(stmt.Context, row); !zero {
+				entityID = fmt.Sprintf("%v", value)
+			}
+		}
+
+		var data interface{}
+		switch {
+		case operation == models.AuditDelete:
+		case changes != nil:
+			data = changes
+		default:
+			data = row.Interface()
+		}
+		// Bulk updates and deletes by condition have no single row; keep
+		// the statement so the entry still says what was touched
+		if entityID == "" {
+			data = map[string]interface{}{"sql": stmt.SQL.String(), "vars": fmt.Sprintf("%v", stmt.Vars), "changes": data}
+		}
+
+		entry := &models.AuditEntry{
+			Operation:  operation,
+			EntityType: entityType,
+			EntityID:   entityID,
+			Actor:      actor,
+		}
+		if data != nil {
+			encoded, err := json.Marshal(data)
+			if err != nil {
+				return nil, err
+			}
+			entry.Data = string(encoded)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// appendAuditEntries chains entries onto the current head of the audit log
+func appendAuditEntries(tx *gorm.DB, entries []*models.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var head models.AuditEntry
+	if err := tx.Order("sequence DESC").Limit(1).Find(&head).Error; err != nil {
+		return fmt.Errorf("failed to read audit log head: %w", err)
+	}
+
+	now := time.Now().UTC()
+	seq, prev := head.Sequence, head.Hash
+	for _, entry := range entries {
+		seq++
+		entry.Sequence = seq
+		entry.CreatedAt = now
+		entry.PrevHash = prev
+		entry.Hash = auditHash(entry)
+		prev = entry.Hash
+	}
+
+	if err := tx.CreateInBatches(entries, 100).Error; err != nil {
+		return fmt.Errorf("failed to append audit log: %w", err)
+	}
+	return nil
+}
+
+// auditHash is the SHA-256 of an entry's fields and its predecessor's hash
+func auditHash(e *models.AuditEntry) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d\n%s\n%s\n%s\n%s\n%s\n%s\n%s",
+		e.Sequence, e.PrevHash, e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		e.Operation, e.EntityType, e.EntityID, e.Actor, e.Data)))
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditVerification is the result of checking the audit log's hash chain
+type AuditVerification struct {
+	Valid        bool   `json:"valid"`
+	Entries      int    `json:"entries"`
+	HeadSequence uint64 `json:"head_sequence"`
+	HeadHash     string `json:"head_hash"`
+	// Set when the chain is broken
+	FirstInvalidSequence *uint64 `json:"first_invalid_sequence,omitempty"`
+	Reason               string  `json:"reason,omitempty"`
+}
+
+// VerifyAuditLog walks the audit log in order, recomputing every hash and
+// checking each entry links to the one before it. Removing entries from the
+// end can't be detected from the chain alone; compare HeadSequence and
+// HeadHash with a previously recorded value for that.
+func VerifyAuditLog(db *gorm.DB) (*AuditVerification, error) {
+	result := &AuditVerification{Valid: true}
+	var prevSeq uint64
+	prevHash := ""
+
+	fail := func(seq uint64, reason string) {
+		result.Valid = false
+		result.FirstInvalidSequence = &seq
+		result.Reason = reason
+	}
+
+	for {
+		var batch []models.AuditEntry
+		if err := db.Where("sequence > ?", prevSeq).Order("sequence ASC").Limit(1000).Find(&batch).Error; err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+		if len(batch) == 0 {
+			return result, nil
+		}
+
+		for i := range batch {
+			entry := &batch[i]
+			switch {
+			case entry.Sequence != prevSeq+1:
+				fail(prevSeq+1, fmt.Sprintf("entry %d is missing", prevSeq+1))
+			case entry.PrevHash != prevHash:
+				fail(entry.Sequence, "prev_hash does not match the preceding entry")
+			case auditHash(entry) != entry.Hash:
+				fail(entry.Sequence, "hash does not match the entry contents")
+			}
+			if !result.Valid {
+				return result, nil
+			}
+
+			result.Entries++
+			result.HeadSequence = entry.Sequence
+			result.HeadHash = entry.Hash
+			prevSeq, prevHash = entry.Sequence, entry.Hash
+		}
+	}
+}
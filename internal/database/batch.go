@@ -0,0 +1,238 @@
+package database
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrWriterClosed is returned for writes made after the batch writer closed
+var ErrWriterClosed = errors.New("batch writer closed")
+
+// BatchWriter groups individual inserts/updates into shared transactions so a
+// burst of writes costs one SQLite commit instead of one per record
+type BatchWriter struct {
+	db       *gorm.DB
+	size     int
+	interval time.Duration
+
+	queue chan batchItem
+	wg    sync.WaitGroup
+	once  sync.Once
+
+	// mu guards closed: writers hold it shared while they queue, so Close
+	// can't close the channel under them
+	mu     sync.RWMutex
+	closed bool
+}
+
+type batchItem struct {
+	value  interface{}
+	result chan error
+}
+
+// NewBatchWriter creates a batch writer and starts its flush loop. A size of
+// 1 or less disables batching and writes go straight to the database. The
+// interval is how long a partial batch may wait for more writes; zero commits
+// as soon as the queue is drained.
+func NewBatchWriter(db *gorm.DB, size int, interval time.Duration) *BatchWriter {
+	w := &BatchWriter{
+		db:       db,
+		size:     size,
+		interval: interval,
+	}
+
+	if size > 1 {
+		w.queue = make(chan batchItem, size*4)
+		w.wg.Add(1)
+		go w.run()
+	}
+
+	return w
+}
+
+// Write saves a record and blocks until the batch containing it is committed
+func (w *BatchWriter) Write(value interface{}) error {
+	if w.queue == nil {
+		return w.db.Save(value).Error
+	}
+
+	result := make(chan error, 1)
+	if !w.enqueue(batchItem{value: value, result: result}) {
+		return ErrWriterClosed
+	}
+	return <-result
+}
+
+// WriteAll queues several records together and blocks until all of them are
+// committed, returning the first error
+func (w *BatchWriter) WriteAll(values ...interface{}) error {
+	if w.queue == nil {
+		return w.db.Transaction(func(tx *gorm.DB) error {
+			for _, value := range values {
+				if err := tx.Save(value).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return ErrWriterClosed
+	}
+	results := make([]chan error, len(values))
+	for i, value := range values {
+		results[i] = make(chan error, 1)
+		w.queue <- batchItem{value: value, result: results[i]}
+	}
+	w.mu.RUnlock()
+
+	var firstErr error
+	for _, result := range results {
+		if err := <-result; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// WriteAsync saves a record without waiting for the commit; failures are logged
+func (w *BatchWriter) WriteAsync(value interface{}) {
+	if w.queue == nil {
+		if err := w.db.Save(value).Error; err != nil {
+			log.Printf("Batch writer: failed to save %T: %v", value, err)
+		}
+		return
+	}
+
+	if !w.enqueue(batchItem{value: value}) {
+		log.Printf("Batch writer: failed to save %T: %v", value, ErrWriterClosed)
+	}
+}
+
+// enqueue hands an item to the flush loop, reporting false once the writer
+// is closed
+func (w *BatchWriter) enqueue(item batchItem) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return false
+	}
+	w.queue <- item
+	return true
+}
+
+// Close flushes pending writes and stops the flush loop
+func (w *BatchWriter) Close() {
+	w.once.Do(func() {
+		if w.queue != nil {
+			w.mu.Lock()
+			w.closed = true
+			close(w.queue)
+			w.mu.Unlock()
+			w.wg.Wait()
+		}
+	})
+}
+
+// run blocks for the first queued item, then drains whatever else is already
+// waiting (up to the batch size) and commits it all together. A positive
+// interval lets a partial batch linger briefly for stragglers.
+func (w *BatchWriter) run() {
+	defer w.wg.Done()
+
+	batch := make([]batchItem, 0, w.size)
+	for {
+		item, ok := <-w.queue
+		if !ok {
+			return
+		}
+		batch = append(batch[:0], item)
+
+		closed := w.collect(&batch)
+		w.flush(batch)
+		if closed {
+			return
+		}
+	}
+}
+
+// collect appends queued items to the batch until it is full, the queue is
+// empty and the linger interval has passed, or the queue is closed
+func (w *BatchWriter) collect(batch *[]batchItem) (closed bool) {
+	var linger <-chan time.Time
+	if w.interval > 0 {
+		timer := time.NewTimer(w.interval)
+		defer timer.Stop()
+		linger = timer.C
+	}
+
+	for len(*batch) < w.size {
+		select {
+		case item, ok := <-w.queue:
+			if !ok {
+				return true
+			}
+			*batch = append(*batch, item)
+			continue
+		default:
+		}
+
+		if linger == nil {
+			return false
+		}
+
+		select {
+		case item, ok := <-w.queue:
+			if !ok {
+				return true
+			}
+			*batch = append(*batch, item)
+		case <-linger:
+			return false
+		}
+	}
+	return false
+}
+
+// flush commits a batch in one transaction, falling back to per-record writes
+// on failure so one bad record doesn't fail its neighbours
+func (w *BatchWriter) flush(batch []batchItem) {
+	if len(batch) == 0 {
+		return
+	}
+
+	err := w.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range batch {
+			if err := tx.Save(item.value).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err == nil {
+		for _, item := range batch {
+			if item.result != nil {
+				item.result <- nil
+			}
+		}
+		return
+	}
+
+	log.Printf("Batch writer: batch of %d failed (%v), retrying individually", len(batch), err)
+	for _, item := range batch {
+		err := w.db.Save(item.value).Error
+		if item.result != nil {
+			item.result <- err
+		} else if err != nil {
+			log.Printf("Batch writer: failed to save %T: %v", item.value, err)
+		}
+	}
+}
@@ -3,41 +3,45 @@ package database
 import (
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 
 	"github.com/gixxerblade/incident-response-mvp/internal/config"
 	"github.com/gixxerblade/incident-response-mvp/internal/models"
 )
 
-// DB is the global database instance
-var DB *gorm.DB
+// Open connects to the database in DATABASE_URL and runs migrations. The
+// caller owns the connection and passes it to whatever needs it; nothing in
+// this package holds on to it, so a process can open several.
+func Open(cfg *config.Config) (*gorm.DB, error) {
+	db, err := open(cfg, cfg.DatabaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("Database initialized successfully")
+	return db, nil
+}
 
-// InitDatabase initializes the database connection and runs migrations
-func InitDatabase(cfg *config.Config) error {
+// open connects to the database at databaseURL and brings its schema up to
+// date
+func open(cfg *config.Config, databaseURL string) (*gorm.DB, error) {
 	// Create database directory if it doesn't exist
-	dbPath := cfg.DatabaseURL
+	dbPath, _, _ := strings.Cut(databaseURL, "?")
 	dbDir := filepath.Dir(dbPath)
 	if err := os.MkdirAll(dbDir, 0755); err != nil {
-		return fmt.Errorf("failed to create database directory: %w", err)
-	}
-
-	// Configure GORM logger
-	gormConfig := &gorm.Config{}
-	if cfg.DatabaseEcho {
-		gormConfig.Logger = logger.Default.LogMode(logger.Info)
-	} else {
-		gormConfig.Logger = logger.Default.LogMode(logger.Silent)
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
 	}
 
-	// Open database connection
-	db, err := gorm.Open(sqlite.Open(dbPath), gormConfig)
+	// Open database connection, with a logger of its own
+	db, err := gorm.Open(sqlite.Open(buildDSN(cfg, databaseURL)), &gorm.Config{Logger: newSQLLogger(cfg)})
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	// Run auto-migrations
@@ -45,23 +49,123 @@ func InitDatabase(cfg *config.Config) error {
 		&models.Event{},
 		&models.Incident{},
 		&models.ActionLog{},
+		&models.IncidentTask{},
+		&models.Runbook{},
+		&models.Lease{},
+		&models.OutboxMessage{},
+		&models.PlaybookRun{},
+		&models.IncidentComment{},
+		&models.SimulationRun{},
+		&models.ExerciseRun{},
+		&models.NotificationRoute{},
+		&models.NotificationDelivery{},
+		&models.IncidentAlert{},
+		&models.AuditEntry{},
+		&models.FeatureFlag{},
+		&models.IncidentWatcher{},
+		&models.UserPreference{},
+		&models.Suppression{},
+		&models.ContentPack{},
+		&models.IncidentAction{},
+		&models.Campaign{},
+		&models.SeenValue{},
+		&models.LoginLocation{},
+		&models.EntityRisk{},
+		&models.RiskContribution{},
+		&models.Service{},
+		&models.DetectionTrace{},
+		&models.IncidentDetection{},
+		&models.ChangeFreeze{},
+		&models.ActionApproval{},
+		&models.ApprovalDecision{},
+		&models.StakeholderUpdate{},
+		&models.IncidentRoleAssignment{},
+		&models.IncidentHandoff{},
+		&models.User{},
+		&models.Session{},
+		&models.RevokedCredential{},
+		&models.APIAccess{},
 	); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
-	DB = db
-	log.Println("Database initialized successfully")
-	return nil
+	if err := ensureEventGeneratedColumns(db); err != nil {
+		return nil, fmt.Errorf("failed to create generated columns: %w", err)
+	}
+
+	hotFields, err := ParseHotFields(cfg.HotFields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HOT_FIELDS: %w", err)
+	}
+	if err := ensureHotFields(db, hotFields); err != nil {
+		return nil, fmt.Errorf("failed to maintain hot field columns: %w", err)
+	}
+
+	if cfg.AuditLogEnabled {
+		if err := EnableAuditLog(db); err != nil {
+			return nil, fmt.Errorf("failed to enable audit log: %w", err)
+		}
+	}
+	return db, nil
+}
+
+// buildDSN appends SQLite pragmas for journal mode and lock waiting to a
+// database path, preserving any options already present
+func buildDSN(cfg *config.Config, databaseURL string) string {
+	path, rawQuery, _ := strings.Cut(databaseURL, "?")
+	params, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		params = url.Values{}
+	}
+
+	if cfg.DatabaseWAL && params.Get("_journal_mode") == "" {
+		params.Set("_journal_mode", "WAL")
+		// NORMAL is durable across application crashes in WAL mode
+		params.Set("_synchronous", "NORMAL")
+	}
+	if cfg.DatabaseBusyTimeout > 0 && params.Get("_busy_timeout") == "" {
+		params.Set("_busy_timeout", fmt.Sprintf("%d", cfg.DatabaseBusyTimeout))
+	}
+
+	if len(params) == 0 {
+		return path
+	}
+	return path + "?" + params.Encode()
 }
 
-// GetDB returns the database instance
-func GetDB() *gorm.DB {
-	return DB
+// eventGeneratedColumns maps virtual columns on events to the JSON expression
+// that extracts them from the normalized payload
+var eventGeneratedColumns = []struct {
+	name string
+	expr string
+}{
+	{"src_ip", "COALESCE(json_extract(normalized, '$.source_ip'), json_extract(normalized, '$.src_ip'))"},
+	{"user_name", "COALESCE(json_extract(normalized, '$.username'), json_extract(normalized, '$.user'))"},
+	{"host_name", "COALESCE(json_extract(normalized, '$.host'), json_extract(normalized, '$.hostname'))"},
+	{"service_name", "json_extract(normalized, '$.service')"},
+}
+
+// ensureEventGeneratedColumns adds indexed virtual columns for frequently
+// filtered normalized fields so list queries don't scan JSON text
+func ensureEventGeneratedColumns(db *gorm.DB) error {
+	for _, col := range eventGeneratedColumns {
+		if !db.Migrator().HasColumn(&models.Event{}, col.name) {
+			stmt := fmt.Sprintf("ALTER TABLE events ADD COLUMN %s TEXT GENERATED ALWAYS AS (%s) VIRTUAL", col.name, col.expr)
+			if err := db.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		stmt := fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_events_%s ON events(%s)", col.name, col.name)
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// CloseDatabase closes the database connection
-func CloseDatabase() error {
-	sqlDB, err := DB.DB()
+// Close closes a database connection
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// hotColumnPrefix marks the generated columns maintained for hot fields
+const hotColumnPrefix = "hot_"
+
+// hotFieldPattern restricts hot fields to plain dotted names; they become
+// column names and JSON paths in DDL
+var hotFieldPattern = regexp.MustCompile(`^[A-Za-z0-9_]+(\.[A-Za-z0-9_]+)*$`)
+
+// hotColumns maps each hot normalized field to its indexed column
+var hotColumns atomic.Pointer[map[string]string]
+
+// HotColumn returns the indexed column holding a normalized field, if the
+// field is hot. The column holds json_extract's value of the field, so it
+// compares exactly as the expression it replaces.
+func HotColumn(field string) (string, bool) {
+	columns := hotColumns.Load()
+	if columns == nil {
+		return "", false
+	}
+	column, ok := (*columns)[field]
+	return column, ok
+}
+
+// FieldExpr returns the SQL expression reading a normalized field from
+// events: its hot column, or json_extract with a bound path. ok is false for
+// names other than plain dotted fields.
+func FieldExpr(field string) (expr string, args []interface{}, ok bool) {
+	if !hotFieldPattern.MatchString(field) {
+		return "", nil, false
+	}
+	if column, ok := HotColumn(field); ok {
+		return column, nil, true
+	}
+	return "json_extract(normalized, ?)", []interface{}{"$." + field}, true
+}
+
+// hotColumnName derives a field's column name; nested fields use "__" for
+// the dots
+func hotColumnName(field string) string {
+	return hotColumnPrefix + strings.ReplaceAll(field, ".", "__")
+}
+
+// ParseHotFields splits a comma-separated list of normalized fields
+func ParseHotFields(spec string) ([]string, error) {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || seen[field] {
+			continue
+		}
+		if !hotFieldPattern.MatchString(field) {
+			return nil, fmt.Errorf("invalid hot field %q", field)
+		}
+		seen[field] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// ensureHotFields maintains an indexed virtual column on events for each hot
+// field and drops the columns of fields no longer configured. Columns are
+// virtual, so adding one doesn't rewrite the table.
+func ensureHotFields(db *gorm.DB, fields []string) error {
+	wanted := make(map[string]string, len(fields))
+	for _, field := range fields {
+		wanted[hotColumnName(field)] = field
+	}
+
+	var existing []string
+	if err := db.Raw(`SELECT name FROM pragma_table_xinfo('events') WHERE name LIKE ? ESCAPE '\'`, `hot\_%`).Scan(&existing).Error; err != nil {
+		return err
+	}
+	present := make(map[string]bool, len(existing))
+	for _, column := range existing {
+		present[column] = true
+		if _, ok := wanted[column]; ok {
+			continue
+		}
+		if err := db.Exec(fmt.Sprintf("DROP INDEX IF EXISTS idx_events_%s", column)).Error; err != nil {
+			return err
+		}
+		if err := db.Exec(fmt.Sprintf("ALTER TABLE events DROP COLUMN %s", column)).Error; err != nil {
+			return err
+		}
+		log.Printf("Dropped hot field column %s", column)
+	}
+
+	columns := make(map[string]string, len(fields))
+	for _, field := range fields {
+		column := hotColumnName(field)
+		if !present[column] {
+			// No declared type, so the column has no affinity and compares
+			// like json_extract itself
+			stmt := fmt.Sprintf("ALTER TABLE events ADD COLUMN %s GENERATED ALWAYS AS (json_extract(normalized, '$.%s')) VIRTUAL", column, field)
+			if err := db.Exec(stmt).Error; err != nil {
+				return err
+			}
+			log.Printf("Added hot field column %s for %s", column, field)
+		}
+		stmt := fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_events_%s ON events(%s)", column, column)
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+		columns[field] = column
+	}
+	hotColumns.Store(&columns)
+	return nil
+}
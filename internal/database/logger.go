@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/config"
+)
+
+// newSQLLogger returns the GORM logger for one connection. SQL is echoed
+// with DATABASE_ECHO or at LOG_LEVEL=DEBUG, which SetSQLLogging can switch.
+func newSQLLogger(cfg *config.Config) *switchableLogger {
+	l := &switchableLogger{
+		quiet:   logger.Default.LogMode(logger.Silent),
+		verbose: logger.Default.LogMode(logger.Info),
+	}
+	l.enabled.Store(cfg.DatabaseEcho || strings.EqualFold(cfg.LogLevel, "DEBUG"))
+	return l
+}
+
+// sqlLoggerOf returns the logger of a connection this package opened
+func sqlLoggerOf(db *gorm.DB) (*switchableLogger, bool) {
+	if db == nil || db.Config == nil {
+		return nil, false
+	}
+	l, ok := db.Config.Logger.(*switchableLogger)
+	return l, ok
+}
+
+// SetSQLLogging turns statement logging on or off at runtime for one
+// connection
+func SetSQLLogging(db *gorm.DB, enabled bool) {
+	if l, ok := sqlLoggerOf(db); ok {
+		l.enabled.Store(enabled)
+	}
+}
+
+// SetErrorHook registers a function called with every failed statement
+// (other than record-not-found) on one connection. It runs inline, so it
+// must not query the database.
+func SetErrorHook(db *gorm.DB, hook func(error)) {
+	if l, ok := sqlLoggerOf(db); ok {
+		l.errorHook.Store(&hook)
+	}
+}
+
+// switchableLogger delegates to a silent or statement-logging GORM logger,
+// chosen per call so it can be switched without reopening the database
+type switchableLogger struct {
+	enabled   atomic.Bool
+	errorHook atomic.Pointer[func(error)]
+	quiet     logger.Interface
+	verbose   logger.Interface
+}
+
+func (l *switchableLogger) active() logger.Interface {
+	if l.enabled.Load() {
+		return l.verbose
+	}
+	return l.quiet
+}
+
+func (l *switchableLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return l.active().LogMode(level)
+}
+
+func (l *switchableLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	l.active().Info(ctx, msg, args...)
+}
+
+func (l *switchableLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	l.active().Warn(ctx, msg, args...)
+}
+
+func (l *switchableLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	l.active().Error(ctx, msg, args...)
+}
+
+func (l *switchableLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		if hook := l.errorHook.Load(); hook != nil {
+			(*hook)(err)
+		}
+	}
+	l.active().Trace(ctx, begin, fc, err)
+}
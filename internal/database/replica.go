@@ -0,0 +1,50 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/config"
+)
+
+// OpenReplica connects to the read replica in DATABASE_REPLICA_URL, such as
+// a copy of the database kept current by Litestream or LiteFS, returning nil
+// when none is configured. The replica is opened query-only and isn't
+// migrated: its schema arrives from the primary.
+func OpenReplica(cfg *config.Config) (*gorm.DB, error) {
+	if cfg.DatabaseReplicaURL == "" {
+		return nil, nil
+	}
+	db, err := gorm.Open(sqlite.Open(replicaDSN(cfg)), &gorm.Config{Logger: newSQLLogger(cfg)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to read replica: %w", err)
+	}
+	if err := db.Exec("SELECT 1 FROM events LIMIT 1").Error; err != nil {
+		Close(db)
+		return nil, fmt.Errorf("read replica has no events table: %w", err)
+	}
+	log.Println("Read replica connected")
+	return db, nil
+}
+
+// replicaDSN adds the lock wait and query-only pragmas to the replica path.
+// The journal mode is left to whatever maintains the replica.
+func replicaDSN(cfg *config.Config) string {
+	path, rawQuery, _ := strings.Cut(cfg.DatabaseReplicaURL, "?")
+	params, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		params = url.Values{}
+	}
+	if params.Get("_query_only") == "" {
+		params.Set("_query_only", "true")
+	}
+	if cfg.DatabaseBusyTimeout > 0 && params.Get("_busy_timeout") == "" {
+		params.Set("_busy_timeout", fmt.Sprintf("%d", cfg.DatabaseBusyTimeout))
+	}
+	return path + "?" + params.Encode()
+}
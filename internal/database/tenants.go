@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/config"
+)
+
+// tenantKey is the context key holding the tenant a request acts for
+type tenantKey struct{}
+
+// WithTenant returns a context whose queries resolve to tenant's database
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant set by WithTenant, or ""
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// Tenants routes the events of tenants with data residency requirements to
+// databases of their own. Everything else, and the events of callers
+// belonging to no tenant, stays in the shared database.
+type Tenants struct {
+	dbs     map[string]*gorm.DB
+	writers map[string]*BatchWriter
+	// members maps API key and user names to their tenant
+	members map[string]string
+}
+
+// OpenTenants opens and migrates the databases in TENANT_DATABASES
+// ("acme=./data/tenants/acme.db,...") and reads which callers belong to
+// them from TENANT_MEMBERS ("acme-collector=acme,..."). Each database gets a
+// batch writer configured like the shared one.
+func OpenTenants(cfg *config.Config) (*Tenants, error) {
+	t := &Tenants{
+		dbs:     make(map[string]*gorm.DB),
+		writers: make(map[string]*BatchWriter),
+		members: make(map[string]string),
+	}
+	databases, err := TenantDatabases(cfg)
+	if err != nil {
+		return nil, err
+	}
+	members, err := parsePairs(cfg.TenantMembers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TENANT_MEMBERS: %w", err)
+	}
+	for name, tenant := range members {
+		if _, ok := databases[tenant]; !ok {
+			return nil, fmt.Errorf("invalid TENANT_MEMBERS: %s belongs to tenant %q, which has no database", name, tenant)
+		}
+		t.members[name] = tenant
+	}
+
+	interval := time.Duration(cfg.DatabaseBatchInterval) * time.Millisecond
+	for tenant, databaseURL := range databases {
+		db, err := open(cfg, databaseURL)
+		if err != nil {
+			t.Close()
+			return nil, fmt.Errorf("tenant %s: %w", tenant, err)
+		}
+		t.dbs[tenant] = db
+		t.writers[tenant] = NewBatchWriter(db, cfg.DatabaseBatchSize, interval)
+	}
+	if len(t.dbs) > 0 {
+		log.Printf("Tenant databases: %s", strings.Join(t.Names(), ", "))
+	}
+	return t, nil
+}
+
+// TenantDatabases returns the database URL of each tenant in
+// TENANT_DATABASES, by tenant name
+func TenantDatabases(cfg *config.Config) (map[string]string, error) {
+	databases, err := parsePairs(cfg.TenantDatabases)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TENANT_DATABASES: %w", err)
+	}
+	return databases, nil
+}
+
+// parsePairs parses "key=value,..." into a map
+func parsePairs(spec string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(entry, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("entry %q must be name=value", entry)
+		}
+		if _, dup := pairs[key]; dup {
+			return nil, fmt.Errorf("%s is listed twice", key)
+		}
+		pairs[key] = value
+	}
+	return pairs, nil
+}
+
+// Names returns the tenants with a database, sorted
+func (t *Tenants) Names() []string {
+	if t == nil {
+		return nil
+	}
+	names := make([]string, 0, len(t.dbs))
+	for name := range t.dbs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Tenant returns the tenant an API key or user name belongs to, or ""
+func (t *Tenants) Tenant(name string) string {
+	if t == nil {
+		return ""
+	}
+	return t.members[name]
+}
+
+// Lookup returns a tenant's database, or nil for the shared database
+func (t *Tenants) Lookup(tenant string) *gorm.DB {
+	if t == nil || tenant == "" {
+		return nil
+	}
+	return t.dbs[tenant]
+}
+
+// Writer returns a tenant's batch writer, or nil for the shared database
+func (t *Tenants) Writer(tenant string) *BatchWriter {
+	if t == nil || tenant == "" {
+		return nil
+	}
+	return t.writers[tenant]
+}
+
+// Close flushes the tenants' writers and closes their databases
+func (t *Tenants) Close() {
+	for tenant, writer := range t.writers {
+		writer.Close()
+		delete(t.writers, tenant)
+	}
+	for tenant, db := range t.dbs {
+		Close(db)
+		delete(t.dbs, tenant)
+	}
+}
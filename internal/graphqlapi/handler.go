@@ -0,0 +1,47 @@
+package graphqlapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	graphql "github.com/graph-gophers/graphql-go"
+	"gorm.io/gorm"
+)
+
+// maxQueryDepth bounds nesting so a single request can't fan out without limit
+const maxQueryDepth = 6
+
+// Handler serves GraphQL queries over HTTP
+type Handler struct {
+	schema *graphql.Schema
+}
+
+// NewHandler parses the schema and binds it to the resolvers
+func NewHandler(db *gorm.DB) (*Handler, error) {
+	schema, err := graphql.ParseSchema(schemaSDL, &queryResolver{db: db},
+		graphql.MaxDepth(maxQueryDepth),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{schema: schema}, nil
+}
+
+// graphQLRequest is the standard GraphQL-over-HTTP request body
+type graphQLRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Query handles POST /api/v1/graphql
+func (h *Handler) Query(c *gin.Context) {
+	var req graphQLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := h.schema.Exec(c.Request.Context(), req.Query, req.OperationName, req.Variables)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,340 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// maxIncidents caps the page size of the incidents query
+const maxIncidents = 200
+
+type queryResolver struct {
+	db *gorm.DB
+}
+
+// Incident resolves Query.incident
+func (q *queryResolver) Incident(ctx context.Context, args struct{ ID graphql.ID }) (*incidentResolver, error) {
+	var incident models.Incident
+	if err := q.db.WithContext(ctx).First(&incident, "incident_id = ?", string(args.ID)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	set := newIncidentSet(q.db.WithContext(ctx), []models.Incident{incident})
+	return set.resolvers[0], nil
+}
+
+// Incidents resolves Query.incidents
+func (q *queryResolver) Incidents(ctx context.Context, args struct {
+	Status   *string
+	Severity *string
+	Limit    int32
+}) ([]*incidentResolver, error) {
+	limit := int(args.Limit)
+	if limit <= 0 || limit > maxIncidents {
+		limit = maxIncidents
+	}
+
+	query := q.db.WithContext(ctx).Order("created_at DESC").Limit(limit)
+	if args.Status != nil {
+		query = query.Where("status = ?", *args.Status)
+	}
+	if args.Severity != nil {
+		query = query.Where("severity = ?", *args.Severity)
+	}
+
+	var incidents []models.Incident
+	if err := query.Find(&incidents).Error; err != nil {
+		return nil, err
+	}
+
+	return newIncidentSet(q.db.WithContext(ctx), incidents).resolvers, nil
+}
+
+// incidentSet loads nested collections for every incident in a result at
+// once, so a list of N incidents costs one query per relation rather than N.
+type incidentSet struct {
+	db        *gorm.DB
+	ids       []string
+	resolvers []*incidentResolver
+
+	eventsOnce sync.Once
+	events     map[string][]models.Event
+	eventsErr  error
+
+	actionsOnce sync.Once
+	actions     map[string][]models.ActionLog
+	actionsErr  error
+
+	commentsOnce sync.Once
+	comments     map[string][]models.IncidentComment
+	commentsErr  error
+
+	tasksOnce sync.Once
+	tasks     map[string][]models.IncidentTask
+	tasksErr  error
+
+	runsOnce sync.Once
+	runs     map[string][]models.PlaybookRun
+	runsErr  error
+}
+
+func newIncidentSet(db *gorm.DB, incidents []models.Incident) *incidentSet {
+	set := &incidentSet{db: db}
+	for i := range incidents {
+		set.ids = append(set.ids, incidents[i].IncidentID)
+		set.resolvers = append(set.resolvers, &incidentResolver{incident: &incidents[i], set: set})
+	}
+	return set
+}
+
+func (s *incidentSet) loadEvents() {
+	s.eventsOnce.Do(func() {
+		s.events = make(map[string][]models.Event)
+
+		// Related events are stored on the incident as a JSON array of IDs
+		owners := make(map[string][]string)
+		var eventIDs []string
+		for _, r := range s.resolvers {
+			var ids []string
+			if r.incident.RelatedEvents != "" {
+				if err := json.Unmarshal([]byte(r.incident.RelatedEvents), &ids); err != nil {
+					continue
+				}
+			}
+			for _, id := range ids {
+				if _, seen := owners[id]; !seen {
+					eventIDs = append(eventIDs, id)
+				}
+				owners[id] = append(owners[id], r.incident.IncidentID)
+			}
+		}
+		if len(eventIDs) == 0 {
+			return
+		}
+
+		var events []models.Event
+		if s.eventsErr = s.db.Where("event_id IN ?", eventIDs).Order("timestamp ASC").Find(&events).Error; s.eventsErr != nil {
+			return
+		}
+		for _, event := range events {
+			for _, incidentID := range owners[event.EventID] {
+				s.events[incidentID] = append(s.events[incidentID], event)
+			}
+		}
+	})
+}
+
+func (s *incidentSet) loadActions() {
+	s.actionsOnce.Do(func() {
+		var actions []models.ActionLog
+		if s.actionsErr = s.db.Where("incident_id IN ?", s.ids).Order("created_at ASC").Find(&actions).Error; s.actionsErr != nil {
+			return
+		}
+		s.actions = make(map[string][]models.ActionLog)
+		for _, action := range actions {
+			s.actions[*action.IncidentID] = append(s.actions[*action.IncidentID], action)
+		}
+	})
+}
+
+func (s *incidentSet) loadComments() {
+	s.commentsOnce.Do(func() {
+		var comments []models.IncidentComment
+		if s.commentsErr = s.db.Where("incident_id IN ?", s.ids).Order("created_at ASC").Find(&comments).Error; s.commentsErr != nil {
+			return
+		}
+		s.comments = make(map[string][]models.IncidentComment)
+		for _, comment := range comments {
+			s.comments[comment.IncidentID] = append(s.comments[comment.IncidentID], comment)
+		}
+	})
+}
+
+func (s *incidentSet) loadTasks() {
+	s.tasksOnce.Do(func() {
+		var tasks []models.IncidentTask
+		if s.tasksErr = s.db.Where("incident_id IN ?", s.ids).Order("created_at ASC").Find(&tasks).Error; s.tasksErr != nil {
+			return
+		}
+		s.tasks = make(map[string][]models.IncidentTask)
+		for _, task := range tasks {
+			s.tasks[task.IncidentID] = append(s.tasks[task.IncidentID], task)
+		}
+	})
+}
+
+func (s *incidentSet) loadRuns() {
+	s.runsOnce.Do(func() {
+		var runs []models.PlaybookRun
+		if s.runsErr = s.db.Where("incident_id IN ?", s.ids).Order("started_at ASC").Find(&runs).Error; s.runsErr != nil {
+			return
+		}
+		s.runs = make(map[string][]models.PlaybookRun)
+		for _, run := range runs {
+			s.runs[*run.IncidentID] = append(s.runs[*run.IncidentID], run)
+		}
+	})
+}
+
+type incidentResolver struct {
+	incident *models.Incident
+	set      *incidentSet
+}
+
+func (r *incidentResolver) ID() graphql.ID          { return graphql.ID(r.incident.IncidentID) }
+func (r *incidentResolver) Title() string           { return r.incident.Title }
+func (r *incidentResolver) Description() string     { return r.incident.Description }
+func (r *incidentResolver) Status() string          { return string(r.incident.Status) }
+func (r *incidentResolver) Severity() string        { return string(r.incident.Severity) }
+func (r *incidentResolver) Category() string        { return r.incident.Category }
+func (r *incidentResolver) TriggeredByRule() string { return r.incident.TriggeredByRule }
+func (r *incidentResolver) CorrelationKey() string  { return r.incident.CorrelationKey }
+func (r *incidentResolver) AssignedTo() *string     { return r.incident.AssignedTo }
+func (r *incidentResolver) Notes() string           { return r.incident.Notes }
+func (r *incidentResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.incident.CreatedAt} }
+func (r *incidentResolver) UpdatedAt() graphql.Time { return graphql.Time{Time: r.incident.UpdatedAt} }
+
+func (r *incidentResolver) RunbookID() *graphql.ID {
+	if r.incident.RunbookID == nil {
+		return nil
+	}
+	id := graphql.ID(*r.incident.RunbookID)
+	return &id
+}
+
+func (r *incidentResolver) Events() ([]*eventResolver, error) {
+	r.set.loadEvents()
+	if r.set.eventsErr != nil {
+		return nil, r.set.eventsErr
+	}
+	events := r.set.events[r.incident.IncidentID]
+	out := make([]*eventResolver, len(events))
+	for i := range events {
+		out[i] = &eventResolver{&events[i]}
+	}
+	return out, nil
+}
+
+func (r *incidentResolver) Actions() ([]*actionResolver, error) {
+	r.set.loadActions()
+	if r.set.actionsErr != nil {
+		return nil, r.set.actionsErr
+	}
+	actions := r.set.actions[r.incident.IncidentID]
+	out := make([]*actionResolver, len(actions))
+	for i := range actions {
+		out[i] = &actionResolver{&actions[i]}
+	}
+	return out, nil
+}
+
+func (r *incidentResolver) Comments() ([]*commentResolver, error) {
+	r.set.loadComments()
+	if r.set.commentsErr != nil {
+		return nil, r.set.commentsErr
+	}
+	comments := r.set.comments[r.incident.IncidentID]
+	out := make([]*commentResolver, len(comments))
+	for i := range comments {
+		out[i] = &commentResolver{&comments[i]}
+	}
+	return out, nil
+}
+
+func (r *incidentResolver) Tasks() ([]*taskResolver, error) {
+	r.set.loadTasks()
+	if r.set.tasksErr != nil {
+		return nil, r.set.tasksErr
+	}
+	tasks := r.set.tasks[r.incident.IncidentID]
+	out := make([]*taskResolver, len(tasks))
+	for i := range tasks {
+		out[i] = &taskResolver{&tasks[i]}
+	}
+	return out, nil
+}
+
+func (r *incidentResolver) PlaybookRuns() ([]*playbookRunResolver, error) {
+	r.set.loadRuns()
+	if r.set.runsErr != nil {
+		return nil, r.set.runsErr
+	}
+	runs := r.set.runs[r.incident.IncidentID]
+	out := make([]*playbookRunResolver, len(runs))
+	for i := range runs {
+		out[i] = &playbookRunResolver{&runs[i]}
+	}
+	return out, nil
+}
+
+type eventResolver struct{ event *models.Event }
+
+func (r *eventResolver) ID() graphql.ID          { return graphql.ID(r.event.EventID) }
+func (r *eventResolver) Timestamp() graphql.Time { return graphql.Time{Time: r.event.Timestamp} }
+func (r *eventResolver) Source() string          { return r.event.Source }
+func (r *eventResolver) EventType() string       { return r.event.EventType }
+func (r *eventResolver) Severity() string        { return string(r.event.Severity) }
+func (r *eventResolver) SrcIP() *string          { return r.event.SrcIP }
+func (r *eventResolver) User() *string           { return r.event.User }
+func (r *eventResolver) Normalized() string      { return r.event.Normalized }
+
+type actionResolver struct{ action *models.ActionLog }
+
+func (r *actionResolver) ID() graphql.ID          { return graphql.ID(r.action.ActionID) }
+func (r *actionResolver) ActionType() string      { return r.action.ActionType }
+func (r *actionResolver) Status() string          { return string(r.action.Status) }
+func (r *actionResolver) PlaybookID() *string     { return r.action.PlaybookID }
+func (r *actionResolver) StepID() *string         { return r.action.StepID }
+func (r *actionResolver) Parameters() string      { return r.action.Parameters }
+func (r *actionResolver) Result() *string         { return r.action.Result }
+func (r *actionResolver) Error() *string          { return r.action.Error }
+func (r *actionResolver) ExecutionTime() int32    { return int32(r.action.ExecutionTime) }
+func (r *actionResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.action.CreatedAt} }
+func (r *actionResolver) CompletedAt() *graphql.Time {
+	return optionalTime(r.action.CompletedAt)
+}
+
+type commentResolver struct{ comment *models.IncidentComment }
+
+func (r *commentResolver) ID() graphql.ID          { return graphql.ID(r.comment.CommentID) }
+func (r *commentResolver) Author() string          { return r.comment.Author }
+func (r *commentResolver) Body() string            { return r.comment.Body }
+func (r *commentResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.comment.CreatedAt} }
+
+type taskResolver struct{ task *models.IncidentTask }
+
+func (r *taskResolver) ID() graphql.ID             { return graphql.ID(r.task.TaskID) }
+func (r *taskResolver) Title() string              { return r.task.Title }
+func (r *taskResolver) Assignee() *string          { return r.task.Assignee }
+func (r *taskResolver) Status() string             { return string(r.task.Status) }
+func (r *taskResolver) DueAt() *graphql.Time       { return optionalTime(r.task.DueAt) }
+func (r *taskResolver) CompletedAt() *graphql.Time { return optionalTime(r.task.CompletedAt) }
+func (r *taskResolver) CreatedAt() graphql.Time    { return graphql.Time{Time: r.task.CreatedAt} }
+
+type playbookRunResolver struct{ run *models.PlaybookRun }
+
+func (r *playbookRunResolver) ID() graphql.ID             { return graphql.ID(r.run.RunID) }
+func (r *playbookRunResolver) PlaybookID() string         { return r.run.PlaybookID }
+func (r *playbookRunResolver) Status() string             { return string(r.run.Status) }
+func (r *playbookRunResolver) Inputs() string             { return r.run.Inputs }
+func (r *playbookRunResolver) Error() *string             { return r.run.Error }
+func (r *playbookRunResolver) StartedAt() graphql.Time    { return graphql.Time{Time: r.run.StartedAt} }
+func (r *playbookRunResolver) CompletedAt() *graphql.Time { return optionalTime(r.run.CompletedAt) }
+
+func optionalTime(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t}
+}
@@ -0,0 +1,90 @@
+// Package graphqlapi serves a read-only GraphQL view of incidents for the
+// dashboard, so one query can fetch an incident together with its events,
+// actions, comments, tasks and playbook runs.
+package graphqlapi
+
+const schemaSDL = `
+schema {
+	query: Query
+}
+
+scalar Time
+
+type Query {
+	incident(id: ID!): Incident
+	incidents(status: String, severity: String, limit: Int = 50): [Incident!]!
+}
+
+type Incident {
+	id: ID!
+	title: String!
+	description: String!
+	status: String!
+	severity: String!
+	category: String!
+	triggeredByRule: String!
+	correlationKey: String!
+	assignedTo: String
+	runbookId: ID
+	notes: String!
+	createdAt: Time!
+	updatedAt: Time!
+	events: [Event!]!
+	actions: [Action!]!
+	comments: [Comment!]!
+	tasks: [Task!]!
+	playbookRuns: [PlaybookRun!]!
+}
+
+type Event {
+	id: ID!
+	timestamp: Time!
+	source: String!
+	eventType: String!
+	severity: String!
+	srcIp: String
+	user: String
+	normalized: String!
+}
+
+type Action {
+	id: ID!
+	actionType: String!
+	status: String!
+	playbookId: String
+	stepId: String
+	parameters: String!
+	result: String
+	error: String
+	executionTime: Int!
+	createdAt: Time!
+	completedAt: Time
+}
+
+type Comment {
+	id: ID!
+	author: String!
+	body: String!
+	createdAt: Time!
+}
+
+type Task {
+	id: ID!
+	title: String!
+	assignee: String
+	status: String!
+	dueAt: Time
+	completedAt: Time
+	createdAt: Time!
+}
+
+type PlaybookRun {
+	id: ID!
+	playbookId: String!
+	status: String!
+	inputs: String!
+	error: String
+	startedAt: Time!
+	completedAt: Time
+}
+`
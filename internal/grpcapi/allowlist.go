@@ -0,0 +1,58 @@
+package grpcapi
+
+import (
+	"context"
+	"log"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/gixxerblade/incident-response-mvp/api/incidentresponse/v1"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// ingestMethods are the RPCs the ingestion allowlist restricts
+var ingestMethods = map[string]bool{
+	pb.IncidentResponse_CreateEvent_FullMethodName:  true,
+	pb.IncidentResponse_IngestEvents_FullMethodName: true,
+}
+
+// IngestAllowlist returns server options rejecting ingestion RPCs from peers
+// outside allowlist
+func IngestAllowlist(allowlist *services.IPAllowlist) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := checkPeer(ctx, info.FullMethod, allowlist); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := checkPeer(stream.Context(), info.FullMethod, allowlist); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	}
+}
+
+func checkPeer(ctx context.Context, method string, allowlist *services.IPAllowlist) error {
+	if !ingestMethods[method] {
+		return nil
+	}
+	addr := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			addr = host
+		}
+	}
+	if !allowlist.Allows(addr) {
+		log.Printf("Rejected gRPC %s from %s: source address not allowed", method, addr)
+		return status.Error(codes.PermissionDenied, "source address not allowed")
+	}
+	return nil
+}
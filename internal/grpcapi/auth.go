@@ -0,0 +1,178 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	pb "github.com/gixxerblade/incident-response-mvp/api/incidentresponse/v1"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// Authenticator resolves the credential a call carries, the same way the
+// REST API resolves its X-API-Key header or bearer token
+type Authenticator interface {
+	Resolve(credential string) (services.Principal, string, error)
+}
+
+// methodRoles is the role each RPC requires, matching its REST counterpart.
+// Methods missing here are refused, so a new RPC must be listed to be served.
+var methodRoles = map[string]services.Role{
+	pb.IncidentResponse_CreateEvent_FullMethodName:      services.RoleViewer,
+	pb.IncidentResponse_IngestEvents_FullMethodName:     services.RoleViewer,
+	pb.IncidentResponse_GetIncident_FullMethodName:      services.RoleViewer,
+	pb.IncidentResponse_ListIncidents_FullMethodName:    services.RoleViewer,
+	pb.IncidentResponse_WatchIncidents_FullMethodName:   services.RoleViewer,
+	pb.IncidentResponse_ListPlaybookRuns_FullMethodName: services.RoleViewer,
+}
+
+// principalContextKey carries a call's principal in its context
+type principalContextKey struct{}
+
+// Authenticate returns server options resolving each call's principal from
+// its x-api-key or authorization metadata, rejecting callers below the
+// method's role and redacting responses by the redaction policy, as the
+// REST middleware does. Revoked keys and sessions are refused here too.
+func Authenticate(authenticator Authenticator, policy *services.RedactionPolicy) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			principal, err := authorize(ctx, info.FullMethod, authenticator)
+			if err != nil {
+				return nil, err
+			}
+			resp, err := handler(context.WithValue(ctx, principalContextKey{}, principal), req)
+			if message, ok := resp.(proto.Message); ok && err == nil {
+				redactMessage(policy, principal.Role, message.ProtoReflect())
+			}
+			return resp, err
+		}),
+		grpc.ChainStreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			principal, err := authorize(stream.Context(), info.FullMethod, authenticator)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &authorizedStream{ServerStream: stream, principal: principal, policy: policy})
+		}),
+	}
+}
+
+// PrincipalFromContext returns the principal of an authenticated call
+func PrincipalFromContext(ctx context.Context) (services.Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(services.Principal)
+	return principal, ok
+}
+
+// authorize resolves a call's principal and checks it against the method
+func authorize(ctx context.Context, method string, authenticator Authenticator) (services.Principal, error) {
+	principal, _, err := authenticator.Resolve(incomingCredential(ctx))
+	if err != nil {
+		var rejected *services.CredentialError
+		if errors.As(err, &rejected) {
+			return services.Principal{}, status.Error(codes.Unauthenticated, rejected.Message)
+		}
+		log.Printf("gRPC %s: failed to check credential: %v", method, err)
+		return services.Principal{}, status.Error(codes.Internal, "failed to check credential")
+	}
+	role, ok := methodRoles[method]
+	if !ok {
+		return services.Principal{}, status.Error(codes.PermissionDenied, "method not allowed")
+	}
+	if principal.Role.Rank() < role.Rank() {
+		return services.Principal{}, status.Error(codes.PermissionDenied, "requires role "+string(role))
+	}
+	return principal, nil
+}
+
+// incomingCredential returns the API key or session token in a call's
+// metadata
+func incomingCredential(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get("x-api-key"); len(values) > 0 && values[0] != "" {
+		return values[0]
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		return strings.TrimPrefix(values[0], "Bearer ")
+	}
+	return ""
+}
+
+// authorizedStream carries the principal into a stream's context and
+// redacts the messages it sends
+type authorizedStream struct {
+	grpc.ServerStream
+	principal services.Principal
+	policy    *services.RedactionPolicy
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return context.WithValue(s.ServerStream.Context(), principalContextKey{}, s.principal)
+}
+
+func (s *authorizedStream) SendMsg(m interface{}) error {
+	if message, ok := m.(proto.Message); ok {
+		redactMessage(s.policy, s.principal.Role, message.ProtoReflect())
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+// redactMessage applies the role's redaction policy to a response, matching
+// fields by their proto names, which are the REST API's JSON keys. Hidden
+// strings become the redacted marker and other hidden fields are cleared;
+// Struct payloads are redacted by key at any depth.
+func redactMessage(policy *services.RedactionPolicy, role services.Role, message protoreflect.Message) {
+	if !policy.Hides(role) {
+		return
+	}
+	var hidden []protoreflect.FieldDescriptor
+	message.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		switch {
+		case policy.HidesField(role, string(field.Name())):
+			hidden = append(hidden, field)
+		case field.IsMap():
+		case field.IsList() && field.Message() != nil:
+			list := value.List()
+			for i := 0; i < list.Len(); i++ {
+				redactMessage(policy, role, list.Get(i).Message())
+			}
+		case field.Message() != nil && field.Message().FullName() == "google.protobuf.Struct":
+			redactStruct(policy, role, value.Message().Interface().(*structpb.Struct))
+		case field.Message() != nil:
+			redactMessage(policy, role, value.Message())
+		}
+		return true
+	})
+	for _, field := range hidden {
+		if field.Kind() == protoreflect.StringKind && !field.IsList() {
+			message.Set(field, protoreflect.ValueOfString(services.RedactedValue))
+			continue
+		}
+		message.Clear(field)
+	}
+}
+
+// redactStruct redacts a JSON payload held in a Struct
+func redactStruct(policy *services.RedactionPolicy, role services.Role, payload *structpb.Struct) {
+	redacted, ok := policy.Redact(role, payload.AsMap()).(map[string]interface{})
+	if !ok {
+		return
+	}
+	rebuilt, err := structpb.NewStruct(redacted)
+	if err != nil {
+		log.Printf("gRPC: failed to redact payload: %v", err)
+		payload.Fields = nil
+		return
+	}
+	payload.Fields = rebuilt.Fields
+}
@@ -0,0 +1,348 @@
+// Package grpcapi implements the gRPC API defined in api/incidentresponse/v1.
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+
+	pb "github.com/gixxerblade/incident-response-mvp/api/incidentresponse/v1"
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// watchPollInterval is how often WatchIncidents checks for changes. Polling
+// the database keeps watches correct across instances.
+const watchPollInterval = time.Second
+
+// Server implements pb.IncidentResponseServer
+type Server struct {
+	pb.UnimplementedIncidentResponseServer
+
+	db       *gorm.DB
+	ingestor *services.Ingestor
+	tenants  *database.Tenants
+}
+
+// NewServer creates a new gRPC API server. Events ingested by a caller
+// belonging to a tenant are stored in the tenant's database.
+func NewServer(db *gorm.DB, ingestor *services.Ingestor, tenants *database.Tenants) *Server {
+	return &Server{
+		db:       db,
+		ingestor: ingestor,
+		tenants:  tenants,
+	}
+}
+
+// Register attaches the service to a gRPC server
+func (s *Server) Register(grpcServer *grpc.Server) {
+	pb.RegisterIncidentResponseServer(grpcServer, s)
+}
+
+// CreateEvent ingests a single event
+func (s *Server) CreateEvent(ctx context.Context, req *pb.IngestEventRequest) (*pb.Event, error) {
+	requestID := incomingRequestID(ctx)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadataKey, requestID))
+	event, err := s.ingest(ctx, req, requestID)
+	if err != nil {
+		return nil, err
+	}
+	return eventToProto(event), nil
+}
+
+// IngestEvents ingests a client stream of events
+func (s *Server) IngestEvents(stream grpc.ClientStreamingServer[pb.IngestEventRequest, pb.IngestEventsSummary]) error {
+	summary := &pb.IngestEventsSummary{}
+	requestID := incomingRequestID(stream.Context())
+	_ = stream.SetHeader(metadata.Pairs(requestIDMetadataKey, requestID))
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(summary)
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.ingest(stream.Context(), req, requestID); err != nil {
+			summary.Rejected++
+			// Cap error detail so a bad forwarder can't balloon the response
+			if len(summary.Errors) < 100 {
+				summary.Errors = append(summary.Errors, err.Error())
+			}
+			continue
+		}
+		summary.Accepted++
+	}
+}
+
+// GetIncident returns one incident by ID
+func (s *Server) GetIncident(ctx context.Context, req *pb.GetIncidentRequest) (*pb.Incident, error) {
+	var incident models.Incident
+	if err := s.db.WithContext(ctx).First(&incident, "incident_id = ?", req.GetIncidentId()).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Error(codes.NotFound, "incident not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to fetch incident")
+	}
+	return incidentToProto(&incident), nil
+}
+
+// ListIncidents returns incidents, newest first
+func (s *Server) ListIncidents(ctx context.Context, req *pb.ListIncidentsRequest) (*pb.ListIncidentsResponse, error) {
+	query := s.db.WithContext(ctx).Order("created_at DESC").Limit(limitOrDefault(req.GetLimit()))
+	if req.GetStatus() != "" {
+		query = query.Where("status = ?", req.GetStatus())
+	}
+	if req.GetSeverity() != "" {
+		query = query.Where("severity = ?", req.GetSeverity())
+	}
+
+	var incidents []models.Incident
+	if err := query.Find(&incidents).Error; err != nil {
+		return nil, status.Error(codes.Internal, "failed to fetch incidents")
+	}
+
+	resp := &pb.ListIncidentsResponse{}
+	for i := range incidents {
+		resp.Incidents = append(resp.Incidents, incidentToProto(&incidents[i]))
+	}
+	return resp, nil
+}
+
+// WatchIncidents streams incidents as they are created or updated
+func (s *Server) WatchIncidents(req *pb.WatchIncidentsRequest, stream grpc.ServerStreamingServer[pb.IncidentChange]) error {
+	since := time.Now().UTC()
+	if req.GetSince() != nil {
+		since = req.GetSince().AsTime()
+	}
+
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		query := s.db.WithContext(stream.Context()).
+			Where("updated_at > ?", since).
+			Order("updated_at ASC")
+		if len(req.GetSeverities()) > 0 {
+			query = query.Where("severity IN ?", req.GetSeverities())
+		}
+
+		var incidents []models.Incident
+		if err := query.Find(&incidents).Error; err != nil {
+			if stream.Context().Err() != nil {
+				return nil
+			}
+			return status.Error(codes.Internal, "failed to fetch incidents")
+		}
+
+		for i := range incidents {
+			change := &pb.IncidentChange{
+				Type:     pb.IncidentChange_CHANGE_TYPE_UPDATED,
+				Incident: incidentToProto(&incidents[i]),
+			}
+			if incidents[i].CreatedAt.After(since) {
+				change.Type = pb.IncidentChange_CHANGE_TYPE_CREATED
+			}
+			if err := stream.Send(change); err != nil {
+				return err
+			}
+			since = incidents[i].UpdatedAt
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ListPlaybookRuns returns playbook runs, newest first
+func (s *Server) ListPlaybookRuns(ctx context.Context, req *pb.ListPlaybookRunsRequest) (*pb.ListPlaybookRunsResponse, error) {
+	query := s.db.WithContext(ctx).Order("started_at DESC").Limit(limitOrDefault(req.GetLimit()))
+	if req.GetIncidentId() != "" {
+		query = query.Where("incident_id = ?", req.GetIncidentId())
+	}
+	if req.GetPlaybookId() != "" {
+		query = query.Where("playbook_id = ?", req.GetPlaybookId())
+	}
+
+	var runs []models.PlaybookRun
+	if err := query.Find(&runs).Error; err != nil {
+		return nil, status.Error(codes.Internal, "failed to fetch playbook runs")
+	}
+
+	resp := &pb.ListPlaybookRunsResponse{}
+	for i := range runs {
+		resp.Runs = append(resp.Runs, playbookRunToProto(&runs[i]))
+	}
+	return resp, nil
+}
+
+// ingest validates a request and passes the event to the shared ingestor,
+// marked for the caller's tenant database as the REST API's are
+func (s *Server) ingest(ctx context.Context, req *pb.IngestEventRequest, requestID string) (*models.Event, error) {
+	if req.GetEventType() == "" || req.GetSource() == "" || req.GetNormalized() == nil {
+		return nil, status.Error(codes.InvalidArgument, "event_type, source and normalized are required")
+	}
+	if req.GetSource() == services.SelfMonitorSource {
+		return nil, status.Errorf(codes.InvalidArgument, "source %q is reserved", services.SelfMonitorSource)
+	}
+
+	severity := req.GetSeverity()
+	if severity == "" {
+		severity = "info"
+	}
+
+	normalizedJSON, err := json.Marshal(req.GetNormalized().AsMap())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "failed to marshal normalized data")
+	}
+
+	var rawDataJSON string
+	if req.GetRawData() != nil {
+		rawJSON, err := json.Marshal(req.GetRawData().AsMap())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "failed to marshal raw data")
+		}
+		rawDataJSON = string(rawJSON)
+	}
+
+	event := &models.Event{
+		Timestamp:  time.Now().UTC(),
+		Source:     req.GetSource(),
+		EventType:  req.GetEventType(),
+		Severity:   models.SeverityLevel(severity),
+		RawData:    rawDataJSON,
+		Normalized: string(normalizedJSON),
+		RequestID:  &requestID,
+	}
+	if principal, ok := PrincipalFromContext(ctx); ok {
+		event.Tenant = s.tenants.Tenant(principal.Name)
+	}
+
+	if err := s.ingestor.Ingest(event); err != nil {
+		log.Printf("gRPC ingest failed: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create event")
+	}
+	return event, nil
+}
+
+// requestIDMetadataKey carries a call's correlation ID, as X-Request-ID does
+// over REST
+const requestIDMetadataKey = "x-request-id"
+
+// incomingRequestID returns the caller's request ID, or a new one
+func incomingRequestID(ctx context.Context) string {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDMetadataKey); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	return services.NormalizeRequestID(id)
+}
+
+// limitOrDefault clamps a requested page size to 1..500, defaulting to 100
+func limitOrDefault(limit int32) int {
+	switch {
+	case limit <= 0:
+		return 100
+	case limit > 500:
+		return 500
+	default:
+		return int(limit)
+	}
+}
+
+func eventToProto(e *models.Event) *pb.Event {
+	out := &pb.Event{
+		EventId:    e.EventID,
+		Timestamp:  timestamppb.New(e.Timestamp),
+		Source:     e.Source,
+		EventType:  e.EventType,
+		Severity:   string(e.Severity),
+		RawData:    jsonToStruct(e.RawData),
+		Normalized: jsonToStruct(e.Normalized),
+		CreatedAt:  timestamppb.New(e.CreatedAt),
+	}
+	if e.ProcessedAt != nil {
+		out.ProcessedAt = timestamppb.New(*e.ProcessedAt)
+	}
+	return out
+}
+
+func incidentToProto(i *models.Incident) *pb.Incident {
+	out := &pb.Incident{
+		IncidentId:      i.IncidentID,
+		CreatedAt:       timestamppb.New(i.CreatedAt),
+		UpdatedAt:       timestamppb.New(i.UpdatedAt),
+		Status:          string(i.Status),
+		Severity:        string(i.Severity),
+		Category:        i.Category,
+		Title:           i.Title,
+		Description:     i.Description,
+		TriggeredByRule: i.TriggeredByRule,
+		Notes:           i.Notes,
+		CorrelationKey:  i.CorrelationKey,
+	}
+	if i.RelatedEvents != "" {
+		_ = json.Unmarshal([]byte(i.RelatedEvents), &out.RelatedEvents)
+	}
+	if i.AssignedTo != nil {
+		out.AssignedTo = *i.AssignedTo
+	}
+	if i.RunbookID != nil {
+		out.RunbookId = *i.RunbookID
+	}
+	return out
+}
+
+func playbookRunToProto(r *models.PlaybookRun) *pb.PlaybookRun {
+	out := &pb.PlaybookRun{
+		RunId:      r.RunID,
+		PlaybookId: r.PlaybookID,
+		Status:     string(r.Status),
+		StartedAt:  timestamppb.New(r.StartedAt),
+	}
+	if r.IncidentID != nil {
+		out.IncidentId = *r.IncidentID
+	}
+	if r.Error != nil {
+		out.Error = *r.Error
+	}
+	if r.CompletedAt != nil {
+		out.CompletedAt = timestamppb.New(*r.CompletedAt)
+	}
+	return out
+}
+
+// jsonToStruct converts a stored JSON object to a protobuf Struct, or nil
+func jsonToStruct(data string) *structpb.Struct {
+	if data == "" {
+		return nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		return nil
+	}
+	st, err := structpb.NewStruct(m)
+	if err != nil {
+		log.Printf("gRPC: failed to convert JSON to struct: %v", err)
+		return nil
+	}
+	return st
+}
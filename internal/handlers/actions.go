@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// ActionsHandler handles action endpoints
+type ActionsHandler struct {
+	db      *gorm.DB
+	actions *services.ActionRegistry
+}
+
+// NewActionsHandler creates a new actions handler
+func NewActionsHandler(db *gorm.DB, actions *services.ActionRegistry) *ActionsHandler {
+	return &ActionsHandler{
+		db:      db,
+		actions: actions,
+	}
+}
+
+// GetCatalog handles GET /api/v1/actions/catalog
+func (h *ActionsHandler) GetCatalog(c *gin.Context) {
+	c.JSON(http.StatusOK, h.actions.Catalog())
+}
+
+// GetQueues handles GET /api/v1/actions/queues
+func (h *ActionsHandler) GetQueues(c *gin.Context) {
+	c.JSON(http.StatusOK, h.actions.QueueStats())
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/config"
+)
+
+// AdminHandler handles administrative endpoints
+type AdminHandler struct {
+	reloader *config.Reloader
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(reloader *config.Reloader) *AdminHandler {
+	return &AdminHandler{reloader: reloader}
+}
+
+// GetConfig handles GET /api/v1/admin/config
+//
+// Secrets (tokens, keys, passwords) are redacted.
+func (h *AdminHandler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"config":     config.Redacted(h.reloader.Current()),
+		"reloadable": config.ReloadableKeys(),
+	})
+}
+
+// ReloadConfig handles POST /api/v1/admin/config/reload
+func (h *AdminHandler) ReloadConfig(c *gin.Context) {
+	result, err := h.reloader.Reload()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Printf("Config reloaded by %s: applied %v, restart required for %v",
+		currentPrincipal(c).Name, result.Applied, result.RestartRequired)
+	c.JSON(http.StatusOK, result)
+}
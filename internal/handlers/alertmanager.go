@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// alertmanagerSource is the event source and alert link source for
+// Alertmanager webhooks
+const alertmanagerSource = "alertmanager"
+
+// AlertmanagerHandler receives Alertmanager webhook notifications
+type AlertmanagerHandler struct {
+	db       *gorm.DB
+	ingestor *services.Ingestor
+	resolver *services.AlertResolver
+}
+
+// NewAlertmanagerHandler creates a new Alertmanager webhook handler
+func NewAlertmanagerHandler(db *gorm.DB, ingestor *services.Ingestor, resolver *services.AlertResolver) *AlertmanagerHandler {
+	return &AlertmanagerHandler{
+		db:       db,
+		ingestor: ingestor,
+		resolver: resolver,
+	}
+}
+
+// AlertmanagerWebhook is the Alertmanager webhook payload (version 4)
+type AlertmanagerWebhook struct {
+	Version  string              `json:"version"`
+	GroupKey string              `json:"groupKey"`
+	Status   string              `json:"status"`
+	Receiver string              `json:"receiver"`
+	Alerts   []AlertmanagerAlert `json:"alerts" binding:"required"`
+}
+
+// AlertmanagerAlert is one alert in an Alertmanager webhook
+type AlertmanagerAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// Receive handles POST /api/v1/webhooks/alertmanager
+//
+// Firing alerts are ingested as alertmanager_alert events for the detection
+// engine; resolved alerts are applied to the incidents linked to their
+// fingerprint.
+func (h *AlertmanagerHandler) Receive(c *gin.Context) {
+	var req AlertmanagerWebhook
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	events := make([]*models.Event, 0, len(req.Alerts))
+	resolutions := []services.AlertResolution{}
+	var rejected []gin.H
+	for i, alert := range req.Alerts {
+		if alert.Fingerprint == "" {
+			rejected = append(rejected, gin.H{"index": i, "error": "fingerprint is required"})
+			continue
+		}
+
+		status := alert.Status
+		if status == "" {
+			status = req.Status
+		}
+
+		if status == models.AlertResolved {
+			resolved, err := h.resolver.Resolve(alertmanagerSource, alert.Fingerprint, alert.EndsAt)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve alert"})
+				return
+			}
+			resolutions = append(resolutions, resolved...)
+			continue
+		}
+
+		event, err := alertmanagerEvent(alert)
+		if err != nil {
+			rejected = append(rejected, gin.H{"index": i, "error": err.Error()})
+			continue
+		}
+		events = append(events, event)
+	}
+
+	if len(events) > 0 {
+		stampRequestID(c, events...)
+		stampTenant(c, events...)
+		if err := h.ingestor.IngestBatch(events); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create events"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"ingested":    len(events),
+		"resolutions": resolutions,
+		"rejected":    rejected,
+	})
+}
+
+// alertmanagerEvent converts a firing alert into an event; labels become
+// normalized fields alongside the fingerprint used to link incidents
+func alertmanagerEvent(alert AlertmanagerAlert) (*models.Event, error) {
+	normalized := make(map[string]interface{}, len(alert.Labels)+4)
+	for k, v := range alert.Labels {
+		normalized[k] = v
+	}
+	normalized[services.AlertFingerprintField] = alert.Fingerprint
+	normalized[services.AlertSourceField] = alertmanagerSource
+	normalized["alert_status"] = models.AlertFiring
+	if len(alert.Annotations) > 0 {
+		normalized["annotations"] = alert.Annotations
+	}
+
+	raw, err := json.Marshal(alert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal alert")
+	}
+	var rawData map[string]interface{}
+	if err := json.Unmarshal(raw, &rawData); err != nil {
+		return nil, fmt.Errorf("failed to marshal alert")
+	}
+
+	event, err := newEvent(EventRequest{
+		EventType:  "alertmanager_alert",
+		Source:     alertmanagerSource,
+		Severity:   string(alertmanagerSeverity(alert.Labels["severity"])),
+		RawData:    rawData,
+		Normalized: normalized,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !alert.StartsAt.IsZero() {
+		startsAt := alert.StartsAt.UTC()
+		event.OccurredAt = &startsAt
+	}
+	return event, nil
+}
+
+// alertmanagerSeverity maps common Prometheus severity labels onto ours
+func alertmanagerSeverity(label string) models.SeverityLevel {
+	switch strings.ToLower(label) {
+	case "critical", "page":
+		return models.SeverityCritical
+	case "error", "high":
+		return models.SeverityHigh
+	case "warning", "warn", "medium":
+		return models.SeverityMedium
+	case "low":
+		return models.SeverityLow
+	case "info", "none":
+		return models.SeverityInfo
+	}
+	return models.SeverityMedium
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// ApprovalsHandler handles approval gate endpoints
+type ApprovalsHandler struct {
+	db    *gorm.DB
+	gates *services.ApprovalGates
+}
+
+// NewApprovalsHandler creates a new approvals handler
+func NewApprovalsHandler(db *gorm.DB, gates *services.ApprovalGates) *ApprovalsHandler {
+	return &ApprovalsHandler{db: db, gates: gates}
+}
+
+// ApprovalDecisionRequest represents the optional body of an approve or
+// reject request
+type ApprovalDecisionRequest struct {
+	Note string `json:"note"`
+}
+
+// ListApprovals handles GET /api/v1/approvals
+//
+// Pending approvals are listed unless ?status= names another status or
+// "all"; ?incident_id= and ?run_id= filter further.
+func (h *ApprovalsHandler) ListApprovals(c *gin.Context) {
+	query := h.db.Preload("Decisions").Order("created_at DESC")
+	if status := c.DefaultQuery("status", string(models.ApprovalPending)); status != "all" {
+		query = query.Where("status = ?", status)
+	}
+	if incidentID := c.Query("incident_id"); incidentID != "" {
+		query = query.Where("incident_id = ?", incidentID)
+	}
+	if runID := c.Query("run_id"); runID != "" {
+		query = query.Where("run_id = ?", runID)
+	}
+
+	approvals := []models.ActionApproval{}
+	if err := query.Find(&approvals).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch approvals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, approvals)
+}
+
+// GetApproval handles GET /api/v1/approvals/:id
+func (h *ApprovalsHandler) GetApproval(c *gin.Context) {
+	approval, err := h.gates.Get(c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, approval)
+}
+
+// ApproveAction handles POST /api/v1/approvals/:id/approve
+//
+// Once the approval has its required approvals from distinct responders,
+// none of them the principal that started the run, the held action executes
+// as part of its playbook step; a failed execution is reported on the
+// approval rather than as an error.
+func (h *ApprovalsHandler) ApproveAction(c *gin.Context) {
+	var req ApprovalDecisionRequest
+	if !bindDecision(c, &req) {
+		return
+	}
+	approval, err := h.gates.Approve(h.auditContext(c), c.Param("id"), currentPrincipal(c).Name, req.Note)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, approval)
+}
+
+// RejectAction handles POST /api/v1/approvals/:id/reject
+func (h *ApprovalsHandler) RejectAction(c *gin.Context) {
+	var req ApprovalDecisionRequest
+	if !bindDecision(c, &req) {
+		return
+	}
+	approval, err := h.gates.Reject(h.auditContext(c), c.Param("id"), currentPrincipal(c).Name, req.Note)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, approval)
+}
+
+// bindDecision reads an optional decision body, responding 400 when it is
+// malformed
+func bindDecision(c *gin.Context, req *ApprovalDecisionRequest) bool {
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return false
+		}
+	}
+	return true
+}
+
+// auditContext attributes the decision's writes to the caller in the audit
+// log
+func (h *ApprovalsHandler) auditContext(c *gin.Context) context.Context {
+	return database.WithAuditActor(c.Request.Context(), currentPrincipal(c).Name)
+}
+
+func (h *ApprovalsHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrApprovalNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSelfApproval):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrApprovalDecided), errors.Is(err, services.ErrAlreadyDecided),
+		errors.Is(err, services.ErrContainmentPause):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// maxAuditEntries caps the entries returned per audit log page
+const maxAuditEntries = 1000
+
+// AuditHandler handles audit log endpoints
+type AuditHandler struct {
+	db *gorm.DB
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(db *gorm.DB) *AuditHandler {
+	return &AuditHandler{db: db}
+}
+
+// ListEntries handles GET /api/v1/audit
+//
+// Entries are returned in chain order; page with ?after=<sequence>.
+func (h *AuditHandler) ListEntries(c *gin.Context) {
+	query := h.db.Order("sequence ASC")
+
+	if entityType := c.Query("entity_type"); entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	if entityID := c.Query("entity_id"); entityID != "" {
+		query = query.Where("entity_id = ?", entityID)
+	}
+	if after := c.Query("after"); after != "" {
+		seq, err := strconv.ParseUint(after, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "after must be a sequence number"})
+			return
+		}
+		query = query.Where("sequence > ?", seq)
+	}
+
+	limit := 100
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		if n > maxAuditEntries {
+			n = maxAuditEntries
+		}
+		limit = n
+	}
+
+	entries := []models.AuditEntry{}
+	if err := query.Limit(limit).Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// Verify handles GET /api/v1/audit/verify
+func (h *AuditHandler) Verify(c *gin.Context) {
+	result, err := database.VerifyAuditLog(h.db)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// principalKey is the gin context key holding the request's principal
+const principalKey = "principal"
+
+// sessionIDKey is the gin context key holding the ID of the request's session
+const sessionIDKey = "session_id"
+
+// SessionCookie is the cookie holding a signed-in user's session token
+const SessionCookie = "ir_session"
+
+// Authenticator resolves API callers from their keys or session tokens
+type Authenticator struct {
+	mu            sync.RWMutex
+	keys          services.APIKeys
+	anonymousRole services.Role
+	sessions      *services.Sessions
+	revocations   *services.Revocations
+}
+
+// NewAuthenticator creates an authenticator. Requests without a key get
+// anonymousRole, or are rejected when it is empty.
+func NewAuthenticator(keys services.APIKeys, anonymousRole services.Role) *Authenticator {
+	a := &Authenticator{}
+	a.SetKeys(keys, anonymousRole)
+	return a
+}
+
+// SetKeys replaces the API keys and anonymous role
+func (a *Authenticator) SetKeys(keys services.APIKeys, anonymousRole services.Role) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.keys = keys
+	a.anonymousRole = anonymousRole
+}
+
+// SetSessions accepts session tokens from single sign-on alongside API keys
+func (a *Authenticator) SetSessions(sessions *services.Sessions) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sessions = sessions
+}
+
+// SetRevocations rejects credentials on the denylist
+func (a *Authenticator) SetRevocations(revocations *services.Revocations) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.revocations = revocations
+}
+
+// Keys returns the configured API keys
+func (a *Authenticator) Keys() services.APIKeys {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.keys
+}
+
+// Authenticate resolves the caller from an API key in the X-API-Key header
+// or a bearer token, or from a session token as a bearer token or in the
+// session cookie. An unknown or revoked key or session is always rejected.
+func (a *Authenticator) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, sessionID, err := a.Resolve(requestCredential(c))
+		if err != nil {
+			var rejected *services.CredentialError
+			if errors.As(err, &rejected) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": rejected.Message})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Set(principalKey, principal)
+		if sessionID != "" {
+			c.Set(sessionIDKey, sessionID)
+		}
+		c.Next()
+	}
+}
+
+// Resolve returns the principal a credential authenticates, and the session
+// ID when it is a session token, for any transport: an empty credential gets
+// the anonymous role. A rejected credential returns a
+// *services.CredentialError.
+func (a *Authenticator) Resolve(key string) (services.Principal, string, error) {
+	a.mu.RLock()
+	keys, anonymousRole, sessions, revocations := a.keys, a.anonymousRole, a.sessions, a.revocations
+	a.mu.RUnlock()
+
+	if key != "" && revocations != nil {
+		revoked, err := revocations.Find(key)
+		if err != nil {
+			return services.Principal{}, "", err
+		}
+		if revoked != nil {
+			return services.Principal{}, "", &services.CredentialError{Message: "credential revoked"}
+		}
+	}
+	if sessions != nil && services.IsSessionToken(key) {
+		session, err := sessions.Lookup(key)
+		if err != nil {
+			return services.Principal{}, "", &services.CredentialError{Message: "session expired or revoked, sign in again"}
+		}
+		return services.SessionPrincipal(session), session.SessionID, nil
+	}
+
+	if key == "" {
+		if anonymousRole == "" {
+			return services.Principal{}, "", &services.CredentialError{Message: "API key required"}
+		}
+		return services.Principal{Name: "anonymous", Role: anonymousRole}, "", nil
+	}
+
+	principal, ok := keys[key]
+	if !ok {
+		return services.Principal{}, "", &services.CredentialError{Message: "invalid API key"}
+	}
+	return principal, "", nil
+}
+
+// requestCredential returns the API key or session token a request carries
+func requestCredential(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+	if key := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "); key != "" {
+		return key
+	}
+	cookie, _ := c.Cookie(SessionCookie)
+	return cookie
+}
+
+// currentPrincipal returns the request's principal; routes outside
+// Authenticate are treated as anonymous viewers
+func currentPrincipal(c *gin.Context) services.Principal {
+	if p, ok := c.Get(principalKey); ok {
+		return p.(services.Principal)
+	}
+	return services.Principal{Name: "anonymous", Role: services.RoleViewer}
+}
+
+// RequireRole rejects callers below role
+func RequireRole(role services.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !allowRole(c, role) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// allowRole aborts the request when the caller is below role
+func allowRole(c *gin.Context, role services.Role) bool {
+	if currentPrincipal(c).Role.Rank() < role.Rank() {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "requires role " + string(role)})
+		return false
+	}
+	return true
+}
+
+// GetMe handles GET /api/v1/me
+func GetMe(c *gin.Context) {
+	c.JSON(http.StatusOK, currentPrincipal(c))
+}
+
+// Redact applies the redaction policy for the caller's role to every JSON
+// response, so handlers don't each filter sensitive fields. Responses for
+// roles with nothing to hide pass through unbuffered.
+func Redact(policy *services.RedactionPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := currentPrincipal(c).Role
+		if !policy.Hides(role) {
+			c.Next()
+			return
+		}
+
+		writer := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		body := writer.body.Bytes()
+		if strings.HasPrefix(writer.Header().Get("Content-Type"), "application/json") && len(body) > 0 {
+			decoder := json.NewDecoder(bytes.NewReader(body))
+			decoder.UseNumber()
+			var value interface{}
+			if err := decoder.Decode(&value); err == nil {
+				if redacted, err := json.Marshal(policy.Redact(role, value)); err == nil {
+					body = redacted
+				}
+			}
+		}
+
+		writer.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		writer.ResponseWriter.WriteHeader(writer.status)
+		_, _ = writer.ResponseWriter.Write(body)
+	}
+}
+
+// bufferedWriter holds a handler's response so it can be rewritten
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body    bytes.Buffer
+	status  int
+	written bool
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
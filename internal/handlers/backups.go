@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// BackupsHandler takes and checks backups. Restores are only done from the
+// command line, with the service stopped.
+type BackupsHandler struct {
+	backups *services.BackupManager
+}
+
+// NewBackupsHandler creates a new backups handler
+func NewBackupsHandler(backups *services.BackupManager) *BackupsHandler {
+	return &BackupsHandler{backups: backups}
+}
+
+// ListBackups handles GET /api/v1/admin/backups
+func (h *BackupsHandler) ListBackups(c *gin.Context) {
+	backups, err := h.backups.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list backups"})
+		return
+	}
+	c.JSON(http.StatusOK, backups)
+}
+
+// CreateBackup handles POST /api/v1/admin/backups
+//
+// Snapshots the database and content directories now. A backup written
+// locally whose upload failed is returned with 502 and the error.
+func (h *BackupsHandler) CreateBackup(c *gin.Context) {
+	backup, err := h.backups.Create()
+	if err != nil {
+		if backup != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error(), "backup": backup})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, backup)
+}
+
+// VerifyBackup handles POST /api/v1/admin/backups/:name/verify
+func (h *BackupsHandler) VerifyBackup(c *gin.Context) {
+	archive, err := h.backups.Path(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "backup not found"})
+		return
+	}
+	manifest, err := services.VerifyBackup(archive)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidBackup) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"verified": true, "manifest": manifest})
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// CampaignsHandler handles campaign endpoints
+type CampaignsHandler struct {
+	campaigns *services.CampaignManager
+}
+
+// NewCampaignsHandler creates a new campaigns handler
+func NewCampaignsHandler(campaigns *services.CampaignManager) *CampaignsHandler {
+	return &CampaignsHandler{campaigns: campaigns}
+}
+
+// CreateCampaignRequest represents the request body for creating a campaign
+type CreateCampaignRequest struct {
+	Title       string   `json:"title" binding:"required"`
+	Description string   `json:"description"`
+	IncidentIDs []string `json:"incident_ids"`
+}
+
+// UpdateCampaignRequest represents the request body for updating a campaign
+type UpdateCampaignRequest struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+}
+
+// CampaignIncidentsRequest represents the request body for adding incidents
+// to a campaign
+type CampaignIncidentsRequest struct {
+	IncidentIDs []string `json:"incident_ids" binding:"required,min=1"`
+}
+
+// ListCampaigns handles GET /api/v1/campaigns
+//
+// ?status= filters on the aggregate status (open, investigating, contained,
+// resolved or empty).
+func (h *CampaignsHandler) ListCampaigns(c *gin.Context) {
+	campaigns, err := h.campaigns.List(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, campaigns)
+}
+
+// CreateCampaign handles POST /api/v1/campaigns
+func (h *CampaignsHandler) CreateCampaign(c *gin.Context) {
+	var req CreateCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	campaign, err := h.campaigns.Create(req.Title, req.Description, req.IncidentIDs)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, campaign)
+}
+
+// GetCampaign handles GET /api/v1/campaigns/:id
+func (h *CampaignsHandler) GetCampaign(c *gin.Context) {
+	campaign, err := h.campaigns.Get(c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, campaign)
+}
+
+// UpdateCampaign handles PATCH /api/v1/campaigns/:id
+func (h *CampaignsHandler) UpdateCampaign(c *gin.Context) {
+	var req UpdateCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	campaign, err := h.campaigns.Update(c.Param("id"), req.Title, req.Description)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, campaign)
+}
+
+// AddIncidents handles POST /api/v1/campaigns/:id/incidents
+func (h *CampaignsHandler) AddIncidents(c *gin.Context) {
+	var req CampaignIncidentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.campaigns.AddIncidents(c.Param("id"), req.IncidentIDs); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"campaign_id": c.Param("id"), "added": req.IncidentIDs})
+}
+
+// RemoveIncident handles DELETE /api/v1/campaigns/:id/incidents/:incident_id
+func (h *CampaignsHandler) RemoveIncident(c *gin.Context) {
+	if err := h.campaigns.RemoveIncident(c.Param("id"), c.Param("incident_id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"campaign_id": c.Param("id"), "removed": c.Param("incident_id")})
+}
+
+func (h *CampaignsHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrCampaignNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCampaignIncidentNotFound):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
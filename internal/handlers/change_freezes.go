@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// ChangeFreezesHandler handles change freeze calendar endpoints
+type ChangeFreezesHandler struct {
+	db *gorm.DB
+}
+
+// NewChangeFreezesHandler creates a new change freezes handler
+func NewChangeFreezesHandler(db *gorm.DB) *ChangeFreezesHandler {
+	return &ChangeFreezesHandler{db: db}
+}
+
+// ChangeFreezeRequest represents the request body for declaring a freeze
+type ChangeFreezeRequest struct {
+	Name     string    `json:"name" binding:"required"`
+	Reason   string    `json:"reason"`
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+}
+
+// ListChangeFreezes handles GET /api/v1/change-freezes
+//
+// Freezes that haven't ended are listed unless ?all=true; ?active=true lists
+// only those in effect now.
+func (h *ChangeFreezesHandler) ListChangeFreezes(c *gin.Context) {
+	now := time.Now().UTC()
+	query := h.db.Order("starts_at ASC")
+	if c.Query("all") != "true" {
+		query = query.Where("ends_at > ?", now)
+	}
+	if c.Query("active") == "true" {
+		query = query.Where("starts_at <= ? AND ends_at > ?", now, now)
+	}
+
+	freezes := []models.ChangeFreeze{}
+	if err := query.Find(&freezes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch change freezes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, freezes)
+}
+
+// CreateChangeFreeze handles POST /api/v1/change-freezes
+func (h *ChangeFreezesHandler) CreateChangeFreeze(c *gin.Context) {
+	var req ChangeFreezeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.EndsAt.After(req.StartsAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ends_at must be after starts_at"})
+		return
+	}
+
+	freeze := models.ChangeFreeze{
+		Name:      req.Name,
+		Reason:    req.Reason,
+		StartsAt:  req.StartsAt.UTC(),
+		EndsAt:    req.EndsAt.UTC(),
+		CreatedBy: currentPrincipal(c).Name,
+	}
+	if err := h.db.Create(&freeze).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create change freeze"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, freeze)
+}
+
+// DeleteChangeFreeze handles DELETE /api/v1/change-freezes/:id
+//
+// Actions already held by the freeze stay pending until decided.
+func (h *ChangeFreezesHandler) DeleteChangeFreeze(c *gin.Context) {
+	var freeze models.ChangeFreeze
+	if err := h.db.First(&freeze, "freeze_id = ?", c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "change freeze not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch change freeze"})
+		return
+	}
+
+	if err := h.db.Delete(&freeze).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete change freeze"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "change freeze deleted", "freeze_id": freeze.FreezeID})
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// CommsHandler handles stakeholder communication endpoints
+type CommsHandler struct {
+	db    *gorm.DB
+	comms *services.Comms
+}
+
+// NewCommsHandler creates a new comms handler
+func NewCommsHandler(db *gorm.DB, comms *services.Comms) *CommsHandler {
+	return &CommsHandler{db: db, comms: comms}
+}
+
+// StakeholderUpdateRequest represents the request body for previewing or
+// sending a stakeholder update
+type StakeholderUpdateRequest struct {
+	Template string   `json:"template" binding:"required"`
+	Message  string   `json:"message"`
+	Targets  []string `json:"targets"` // defaults to the template's
+}
+
+// ListTemplates handles GET /api/v1/comms/templates
+func (h *CommsHandler) ListTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, h.comms.Templates())
+}
+
+// ListUpdates handles GET /api/v1/incidents/:id/updates
+func (h *CommsHandler) ListUpdates(c *gin.Context) {
+	updates := []models.StakeholderUpdate{}
+	if err := h.db.Where("incident_id = ?", c.Param("id")).Order("created_at DESC").Find(&updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch updates"})
+		return
+	}
+	c.JSON(http.StatusOK, updates)
+}
+
+// PreviewUpdate handles POST /api/v1/incidents/:id/updates/preview
+func (h *CommsHandler) PreviewUpdate(c *gin.Context) {
+	var req StakeholderUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	_, rendered, err := h.comms.Render(c.Param("id"), req.Template, req.Message)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rendered)
+}
+
+// SendUpdate handles POST /api/v1/incidents/:id/updates
+//
+// The update counts as sent, restarting the incident's update cadence, when
+// at least one target received it; targets that failed are listed in
+// "failed".
+func (h *CommsHandler) SendUpdate(c *gin.Context) {
+	var req StakeholderUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user := currentPrincipal(c).Name
+	ctx := database.WithAuditActor(c.Request.Context(), user)
+	update, err := h.comms.Send(ctx, c.Param("id"), req.Template, req.Message, req.Targets, user)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, update)
+}
+
+func (h *CommsHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrCommsIncidentNotFound), errors.Is(err, services.ErrCommsTemplateNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNoUpdateTargets):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUpdateNotDelivered):
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// ContentHandler handles rule and playbook plan/apply endpoints
+type ContentHandler struct {
+	content *services.ContentManager
+}
+
+// NewContentHandler creates a new content handler
+func NewContentHandler(content *services.ContentManager) *ContentHandler {
+	return &ContentHandler{content: content}
+}
+
+// ApplyContentRequest represents the request body for applying a bundle
+type ApplyContentRequest struct {
+	services.ContentBundle
+	PlanID string `json:"plan_id"`
+}
+
+// Plan handles POST /api/v1/config/plan
+func (h *ContentHandler) Plan(c *gin.Context) {
+	var bundle services.ContentBundle
+	if err := c.ShouldBindJSON(&bundle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.content.Plan(bundle)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
+
+// Apply handles POST /api/v1/config/apply
+func (h *ContentHandler) Apply(c *gin.Context) {
+	var req ApplyContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.content.Apply(req.ContentBundle, req.PlanID, currentPrincipal(c).Name)
+	switch {
+	case errors.Is(err, services.ErrInvalidContent):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "plan": plan})
+	case errors.Is(err, services.ErrStalePlan):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "plan": plan})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, gin.H{"applied": true, "plan": plan})
+	}
+}
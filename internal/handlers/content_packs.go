@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// ContentPacksHandler handles content pack endpoints
+type ContentPacksHandler struct {
+	packs *services.ContentPackManager
+}
+
+// NewContentPacksHandler creates a new content packs handler
+func NewContentPacksHandler(packs *services.ContentPackManager) *ContentPacksHandler {
+	return &ContentPacksHandler{packs: packs}
+}
+
+// ListInstalled handles GET /api/v1/content-packs
+func (h *ContentPacksHandler) ListInstalled(c *gin.Context) {
+	packs, err := h.packs.Installed()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, packs)
+}
+
+// ListAvailable handles GET /api/v1/content-packs/available
+func (h *ContentPacksHandler) ListAvailable(c *gin.Context) {
+	packs, err := h.packs.Available(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, packs)
+}
+
+// Install handles POST /api/v1/content-packs
+func (h *ContentPacksHandler) Install(c *gin.Context) {
+	var req services.PackInstallRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.packs.Install(c.Request.Context(), req, currentPrincipal(c).Name)
+	if err != nil {
+		h.respondError(c, err, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// Uninstall handles DELETE /api/v1/content-packs/:name
+func (h *ContentPacksHandler) Uninstall(c *gin.Context) {
+	plan, err := h.packs.Uninstall(c.Request.Context(), c.Param("name"), currentPrincipal(c).Name)
+	if err != nil {
+		h.respondError(c, err, plan)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"uninstalled": c.Param("name"), "plan": plan})
+}
+
+func (h *ContentPacksHandler) respondError(c *gin.Context, err error, detail interface{}) {
+	switch {
+	case errors.Is(err, services.ErrPackNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPackConflict), errors.Is(err, services.ErrPackDependency):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidContent):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "detail": detail})
+	case errors.Is(err, services.ErrPackUntrusted):
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
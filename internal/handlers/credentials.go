@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// RevokeRequest is the body of an API key revocation
+type RevokeRequest struct {
+	Reason string `json:"reason"`
+}
+
+// RevokeTokenRequest is the body of a revocation by credential value
+type RevokeTokenRequest struct {
+	Token  string `json:"token" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+// sessionView is a session as listed to its user
+type sessionView struct {
+	models.Session
+	Current bool `json:"current"` // the session making the request
+}
+
+// CredentialsHandler handles session and credential revocation endpoints
+type CredentialsHandler struct {
+	auth        *Authenticator
+	sessions    *services.Sessions
+	revocations *services.Revocations
+}
+
+// NewCredentialsHandler creates a new credentials handler
+func NewCredentialsHandler(auth *Authenticator, sessions *services.Sessions, revocations *services.Revocations) *CredentialsHandler {
+	return &CredentialsHandler{auth: auth, sessions: sessions, revocations: revocations}
+}
+
+// ListMySessions handles GET /api/v1/me/sessions
+func (h *CredentialsHandler) ListMySessions(c *gin.Context) {
+	sessions, err := h.sessions.List(currentPrincipal(c).Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	current := c.GetString(sessionIDKey)
+	views := make([]sessionView, 0, len(sessions))
+	for _, session := range sessions {
+		views = append(views, sessionView{Session: session, Current: session.SessionID == current})
+	}
+	c.JSON(http.StatusOK, views)
+}
+
+// RevokeMySession handles DELETE /api/v1/me/sessions/:id
+func (h *CredentialsHandler) RevokeMySession(c *gin.Context) {
+	user := currentPrincipal(c).Name
+	ctx := database.WithAuditActor(c.Request.Context(), user)
+	if _, err := h.sessions.RevokeID(ctx, c.Param("id"), user); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeMySessions handles DELETE /api/v1/me/sessions, signing the caller
+// out everywhere
+func (h *CredentialsHandler) RevokeMySessions(c *gin.Context) {
+	user := currentPrincipal(c).Name
+	ctx := database.WithAuditActor(c.Request.Context(), user)
+	revoked, err := h.sessions.RevokeUser(ctx, user)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revoked": revoked})
+}
+
+// ListSessions handles GET /api/v1/admin/sessions
+//
+// ?user= limits the list to one user's sessions.
+func (h *CredentialsHandler) ListSessions(c *gin.Context) {
+	sessions, err := h.sessions.List(c.Query("user"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession handles DELETE /api/v1/admin/sessions/:id
+func (h *CredentialsHandler) RevokeSession(c *gin.Context) {
+	by := currentPrincipal(c).Name
+	ctx := database.WithAuditActor(c.Request.Context(), by)
+	session, err := h.sessions.RevokeID(ctx, c.Param("id"), "")
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	log.Printf("Session %s of %s revoked by %s", session.SessionID, session.User, by)
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeUserSessions handles DELETE /api/v1/admin/users/:name/sessions
+func (h *CredentialsHandler) RevokeUserSessions(c *gin.Context) {
+	by := currentPrincipal(c).Name
+	ctx := database.WithAuditActor(c.Request.Context(), by)
+	revoked, err := h.sessions.RevokeUser(ctx, c.Param("name"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	log.Printf("%d sessions of %s revoked by %s", revoked, c.Param("name"), by)
+	c.JSON(http.StatusOK, gin.H{"revoked": revoked})
+}
+
+// ListAPIKeys handles GET /api/v1/admin/api-keys
+func (h *CredentialsHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.revocations.APIKeys(h.auth.Keys())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey handles POST /api/v1/admin/api-keys/:name/revoke
+//
+// Every key configured under the name is rejected from then on, on every
+// instance, until it is replaced in API_KEYS.
+func (h *CredentialsHandler) RevokeAPIKey(c *gin.Context) {
+	var req RevokeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	by := currentPrincipal(c).Name
+	ctx := database.WithAuditActor(c.Request.Context(), by)
+	revoked, err := h.revocations.RevokeAPIKey(ctx, h.auth.Keys(), c.Param("name"), req.Reason, by)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	log.Printf("API key %s revoked by %s", c.Param("name"), by)
+	c.JSON(http.StatusOK, revoked)
+}
+
+// ListRevocations handles GET /api/v1/admin/revocations
+func (h *CredentialsHandler) ListRevocations(c *gin.Context) {
+	revoked, err := h.revocations.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, revoked)
+}
+
+// RevokeToken handles POST /api/v1/admin/revocations
+//
+// It denylists an API key or session token by value, such as one found in
+// a leak.
+func (h *CredentialsHandler) RevokeToken(c *gin.Context) {
+	var req RevokeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	by := currentPrincipal(c).Name
+	ctx := database.WithAuditActor(c.Request.Context(), by)
+	revoked, err := h.revocations.RevokeToken(ctx, h.auth.Keys(), req.Token, req.Reason, by)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	log.Printf("Credential %s (%s) revoked by %s", revoked.Kind, revoked.Name, by)
+	c.JSON(http.StatusCreated, revoked)
+}
+
+func (h *CredentialsHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrSessionNotFound), errors.Is(err, services.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAlreadyRevoked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// EntitiesHandler handles entity profile endpoints
+type EntitiesHandler struct {
+	profiler *services.EntityProfiler
+}
+
+// NewEntitiesHandler creates a new entities handler
+func NewEntitiesHandler(profiler *services.EntityProfiler) *EntitiesHandler {
+	return &EntitiesHandler{profiler: profiler}
+}
+
+// GetEntity handles GET /api/v1/entities/:type/:value
+func (h *EntitiesHandler) GetEntity(c *gin.Context) {
+	profile, err := h.profiler.Profile(c.Param("type"), c.Param("value"))
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownEntityType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
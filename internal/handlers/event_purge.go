@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// PurgeEventsRequest is the body of an event purge
+type PurgeEventsRequest struct {
+	services.PurgeFilter
+	// Tenant purges from a tenant's database instead of the shared one
+	Tenant string `json:"tenant"`
+}
+
+// EventPurgeHandler deletes events
+type EventPurgeHandler struct {
+	db      *gorm.DB
+	tenants *database.Tenants
+	cold    *services.ColdStorage
+}
+
+// NewEventPurgeHandler creates a new event purge handler
+func NewEventPurgeHandler(db *gorm.DB, tenants *database.Tenants, cold *services.ColdStorage) *EventPurgeHandler {
+	return &EventPurgeHandler{db: db, tenants: tenants, cold: cold}
+}
+
+// PurgeEvents handles POST /api/v1/admin/events/purge
+//
+// Deletes the events the body selects, such as test data or a poisoned
+// ingest. Events incidents refer to are tombstoned unless detach is set;
+// dry_run reports how many events match without deleting them.
+func (h *EventPurgeHandler) PurgeEvents(c *gin.Context) {
+	var req PurgeEventsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	eventsDB := h.db
+	if req.Tenant != "" {
+		if eventsDB = h.tenants.Lookup(req.Tenant); eventsDB == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown tenant: " + req.Tenant})
+			return
+		}
+	}
+
+	by := currentPrincipal(c).Name
+	ctx := database.WithAuditActor(c.Request.Context(), by)
+	result, err := services.PurgeEvents(ctx, h.db, eventsDB, h.cold, req.PurgeFilter)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPurgeFilter) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !result.DryRun {
+		log.Printf("Event purge by %s: %d deleted, %d tombstoned, %d incidents detached",
+			by, result.Deleted, result.Tombstoned, len(result.Incidents))
+	}
+	c.JSON(http.StatusOK, result)
+}
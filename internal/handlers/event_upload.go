@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/parsers"
+)
+
+// maxUploadBytes caps the size of an uploaded log file
+const maxUploadBytes = 32 << 20
+
+// UploadEvents handles POST /api/v1/events/upload
+//
+// The body (or a multipart "file" field) is a log file in the given format:
+// ndjson (one event request per line, optionally with a "timestamp"), journald (`journalctl -o json`) or
+// auditd (audit.log). Parsed events are ingested like any other event.
+func (h *EventsHandler) UploadEvents(c *gin.Context) {
+	format := c.DefaultQuery("format", "ndjson")
+	source := c.DefaultQuery("source", format)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadBytes)
+	body, closeBody, err := uploadBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer closeBody()
+
+	requests, skipped, err := parseUpload(body, format, source)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accepted := 0
+	for start := 0; start < len(requests); start += maxBatchEvents {
+		end := start + maxBatchEvents
+		if end > len(requests) {
+			end = len(requests)
+		}
+
+		events := make([]*models.Event, 0, end-start)
+		for _, item := range requests[start:end] {
+			event, err := newEvent(item.EventRequest)
+			if err != nil {
+				skipped++
+				continue
+			}
+			if !item.timestamp.IsZero() && event.OccurredAt == nil {
+				event.OccurredAt = &item.timestamp
+			}
+			events = append(events, event)
+		}
+
+		stampRequestID(c, events...)
+		stampTenant(c, events...)
+		if err := h.ingestor.IngestBatch(events); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":    "failed to create events",
+				"accepted": accepted,
+			})
+			return
+		}
+		accepted += len(events)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"format":   format,
+		"accepted": accepted,
+		"skipped":  skipped,
+	})
+}
+
+// uploadBody returns the multipart "file" field if present, else the raw body
+func uploadBody(c *gin.Context) (io.Reader, func(), error) {
+	if c.ContentType() == "multipart/form-data" {
+		file, err := c.FormFile("file")
+		if err != nil {
+			return nil, nil, fmt.Errorf("multipart upload requires a file field")
+		}
+		f, err := file.Open()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open upload: %w", err)
+		}
+		return f, func() { f.Close() }, nil
+	}
+	return c.Request.Body, func() {}, nil
+}
+
+// uploadedEvent is an event request plus its original timestamp, if known
+type uploadedEvent struct {
+	EventRequest
+	timestamp time.Time
+}
+
+// parseUpload parses a log file into event requests, counting lines skipped
+func parseUpload(r io.Reader, format, source string) ([]uploadedEvent, int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var out []uploadedEvent
+	skipped := 0
+
+	switch format {
+	case "ndjson":
+		for scanner.Scan() {
+			if len(scanner.Bytes()) == 0 {
+				continue
+			}
+			// An optional "timestamp" preserves the original event time
+			var line struct {
+				EventRequest
+				Timestamp time.Time `json:"timestamp"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil ||
+				line.EventType == "" || line.Source == "" || line.Normalized == nil {
+				skipped++
+				continue
+			}
+			out = append(out, uploadedEvent{EventRequest: line.EventRequest, timestamp: line.Timestamp})
+		}
+
+	case "journald":
+		for scanner.Scan() {
+			var entry map[string]interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				skipped++
+				continue
+			}
+			parsed, ok := parsers.ParseJournalEntry(entry)
+			if !ok {
+				skipped++
+				continue
+			}
+			out = append(out, parsedEvent(parsed, source))
+		}
+
+	case "auditd":
+		assembler := parsers.NewAuditAssembler()
+		for scanner.Scan() {
+			for _, parsed := range assembler.Add(scanner.Text()) {
+				out = append(out, parsedEvent(parsed, source))
+			}
+		}
+		for _, parsed := range assembler.Flush() {
+			out = append(out, parsedEvent(parsed, source))
+		}
+
+	default:
+		return nil, 0, fmt.Errorf("unsupported format %q (use ndjson, journald or auditd)", format)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read upload: %w", err)
+	}
+	return out, skipped, nil
+}
+
+// parsedEvent converts a parser result to an event request
+func parsedEvent(p parsers.Parsed, source string) uploadedEvent {
+	return uploadedEvent{
+		EventRequest: EventRequest{
+			EventType:  p.EventType,
+			Source:     source,
+			Severity:   p.Severity,
+			RawData:    map[string]interface{}{"line": p.Raw},
+			Normalized: p.Fields,
+		},
+		timestamp: p.Timestamp,
+	}
+}
@@ -2,28 +2,93 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
 	"github.com/gixxerblade/incident-response-mvp/internal/models"
 	"github.com/gixxerblade/incident-response-mvp/internal/services"
+	"github.com/gixxerblade/incident-response-mvp/internal/store"
 )
 
 // EventsHandler handles event-related API endpoints
 type EventsHandler struct {
-	db              *gorm.DB
-	detectionEngine *services.DetectionEngine
+	db *gorm.DB
+	// reads serves event lists, possibly from a replica
+	reads    *gorm.DB
+	events   store.EventStore
+	ingestor *services.Ingestor
+	// fastAck names the API keys whose events are acknowledged before
+	// they are stored
+	fastAck map[string]bool
+	cold    *services.ColdStorage
+	tenants *database.Tenants
 }
 
-// NewEventsHandler creates a new events handler
-func NewEventsHandler(db *gorm.DB, detectionEngine *services.DetectionEngine) *EventsHandler {
-	return &EventsHandler{
-		db:              db,
-		detectionEngine: detectionEngine,
+// NewEventsHandler creates a new events handler. Ingest requests from the
+// fastAck API key names are queued and answered with 202 immediately. Raw
+// payloads moved to cold storage are fetched back when one event is read.
+// Callers belonging to a tenant in tenants read and write that tenant's
+// events; other callers' event lists are read from reads, and single events
+// from events.
+func NewEventsHandler(db, reads *gorm.DB, events store.EventStore, ingestor *services.Ingestor, fastAck []string, cold *services.ColdStorage, tenants *database.Tenants) *EventsHandler {
+	h := &EventsHandler{
+		db:       db,
+		reads:    reads,
+		events:   events,
+		ingestor: ingestor,
+		fastAck:  make(map[string]bool, len(fastAck)),
+		cold:     cold,
+		tenants:  tenants,
 	}
+	for _, name := range fastAck {
+		h.fastAck[name] = true
+	}
+	return h
+}
+
+// eventDB returns the database holding the caller's events: their tenant's,
+// or shared
+func (h *EventsHandler) eventDB(c *gin.Context, shared *gorm.DB) *gorm.DB {
+	if db := h.tenants.Lookup(database.TenantFromContext(c.Request.Context())); db != nil {
+		return db
+	}
+	return shared
+}
+
+// eventStore returns the store holding the caller's events
+func (h *EventsHandler) eventStore(c *gin.Context) store.EventStore {
+	if db := h.tenants.Lookup(database.TenantFromContext(c.Request.Context())); db != nil {
+		return store.NewGormEvents(db, nil)
+	}
+	return h.events
+}
+
+// enqueue hands events to the fast-ack queue when the caller's key uses
+// fast-ack, answering 503 when the queue is full. It reports whether the
+// request was handled that way.
+func (h *EventsHandler) enqueue(c *gin.Context, events []*models.Event) (queued bool, ok bool) {
+	if !h.fastAck[currentPrincipal(c).Name] {
+		return false, true
+	}
+	if err := h.ingestor.Enqueue(events); err != nil {
+		if errors.Is(err, services.ErrIngestQueueFull) {
+			c.Header("Retry-After", "1")
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create events"})
+		}
+		return true, false
+	}
+	return true, true
 }
 
 // EventRequest represents the request body for creating an event
@@ -33,6 +98,8 @@ type EventRequest struct {
 	Severity   string                 `json:"severity"`
 	RawData    map[string]interface{} `json:"raw_data"`
 	Normalized map[string]interface{} `json:"normalized" binding:"required"`
+	// OccurredAt is when the event happened at its source, if known
+	OccurredAt *time.Time `json:"occurred_at"`
 }
 
 // CreateEvent handles POST /api/v1/events
@@ -43,6 +110,109 @@ func (h *EventsHandler) CreateEvent(c *gin.Context) {
 		return
 	}
 
+	event, err := newEvent(req)
+	if errors.Is(err, errReservedSource) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Store and trigger detection engine
+	stampRequestID(c, event)
+	stampTenant(c, event)
+	if queued, ok := h.enqueue(c, []*models.Event{event}); queued {
+		if ok {
+			c.JSON(http.StatusAccepted, gin.H{"event_id": event.EventID, "status": "queued"})
+		}
+		return
+	}
+	if err := h.ingestor.Ingest(event); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create event"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, event)
+}
+
+// maxBatchEvents caps the number of events accepted per batch request
+const maxBatchEvents = 1000
+
+// BatchEventRequest represents the request body for batch ingestion
+type BatchEventRequest struct {
+	Events []EventRequest `json:"events" binding:"required"`
+}
+
+// CreateEventsBatch handles POST /api/v1/events/batch
+//
+// Invalid entries are rejected individually so one malformed line from a
+// collector doesn't drop the rest of its batch.
+func (h *EventsHandler) CreateEventsBatch(c *gin.Context) {
+	var req BatchEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Events) > maxBatchEvents {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("batch exceeds %d events", maxBatchEvents)})
+		return
+	}
+
+	events := make([]*models.Event, 0, len(req.Events))
+	var rejected []gin.H
+	for i, item := range req.Events {
+		if item.EventType == "" || item.Source == "" || item.Normalized == nil {
+			rejected = append(rejected, gin.H{"index": i, "error": "event_type, source and normalized are required"})
+			continue
+		}
+		event, err := newEvent(item)
+		if err != nil {
+			rejected = append(rejected, gin.H{"index": i, "error": err.Error()})
+			continue
+		}
+		events = append(events, event)
+	}
+
+	if len(events) > 0 {
+		stampRequestID(c, events...)
+		stampTenant(c, events...)
+		if queued, ok := h.enqueue(c, events); queued {
+			if ok {
+				eventIDs := make([]string, len(events))
+				for i, event := range events {
+					eventIDs[i] = event.EventID
+				}
+				c.JSON(http.StatusAccepted, gin.H{
+					"accepted":  len(events),
+					"event_ids": eventIDs,
+					"rejected":  rejected,
+				})
+			}
+			return
+		}
+		if err := h.ingestor.IngestBatch(events); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create events"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"accepted": len(events),
+		"rejected": rejected,
+	})
+}
+
+// errReservedSource rejects external events claiming the self-monitoring source
+var errReservedSource = fmt.Errorf("source %q is reserved", services.SelfMonitorSource)
+
+// newEvent builds an event model from an ingest request
+func newEvent(req EventRequest) (*models.Event, error) {
+	if req.Source == services.SelfMonitorSource {
+		return nil, errReservedSource
+	}
+
 	// Set default severity
 	if req.Severity == "" {
 		req.Severity = "info"
@@ -51,46 +221,117 @@ func (h *EventsHandler) CreateEvent(c *gin.Context) {
 	// Convert maps to JSON strings
 	normalizedJSON, err := json.Marshal(req.Normalized)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to marshal normalized data"})
-		return
+		return nil, fmt.Errorf("failed to marshal normalized data")
 	}
 
 	var rawDataJSON string
 	if req.RawData != nil {
 		rawJSON, err := json.Marshal(req.RawData)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to marshal raw data"})
-			return
+			return nil, fmt.Errorf("failed to marshal raw data")
 		}
 		rawDataJSON = string(rawJSON)
 	}
 
-	// Create event
-	event := &models.Event{
-		Timestamp:  time.Now().UTC(),
+	// The ingestor settles Timestamp from OccurredAt and the receive time
+	return &models.Event{
+		OccurredAt: req.OccurredAt,
 		Source:     req.Source,
 		EventType:  req.EventType,
 		Severity:   models.SeverityLevel(req.Severity),
 		RawData:    rawDataJSON,
 		Normalized: string(normalizedJSON),
-	}
-
-	if err := h.db.Create(event).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create event"})
-		return
-	}
-
-	// Trigger detection engine
-	go h.detectionEngine.EvaluateEvent(event)
+	}, nil
+}
 
-	c.JSON(http.StatusCreated, event)
+// eventListFields are the columns clients may request via ?fields=
+var eventListFields = map[string]string{
+	"event_id":     "event_id",
+	"timestamp":    "timestamp",
+	"occurred_at":  "occurred_at",
+	"received_at":  "received_at",
+	"clock_skew":   "clock_skew",
+	"source":       "source",
+	"event_type":   "event_type",
+	"severity":     "severity",
+	"request_id":   "request_id",
+	"raw_data":     "raw_data",
+	"normalized":   "normalized",
+	"created_at":   "created_at",
+	"processed_at": "processed_at",
+	"src_ip":       "src_ip",
+	"user":         "user_name",
+	"service":      "service_name",
 }
 
+// maxEventPage caps ?limit= on GET /api/v1/events
+const maxEventPage = 1000
+
 // ListEvents handles GET /api/v1/events
+//
+// By default a summary without raw_data/normalized is returned. Use
+// ?fields=a,b,c to project specific fields or ?fields=* for full events.
+//
+// Events are returned newest first, ordered by (timestamp, event_id). A full
+// page sets X-Next-Cursor to the last event's ID; pass it as ?after= for the
+// next page, which continues from that event's position in the order.
 func (h *EventsHandler) ListEvents(c *gin.Context) {
-	var events []models.Event
+	db := h.eventDB(c, h.reads)
+	limit := 100
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		if n > maxEventPage {
+			n = maxEventPage
+		}
+		limit = n
+	}
+	query := db.Model(&models.Event{}).Order("timestamp DESC, event_id DESC").Limit(limit)
+
+	if after := c.Query("after"); after != "" {
+		var last models.EventSummary
+		err := db.Model(&models.Event{}).Select("event_id, timestamp").Where("event_id = ?", after).Take(&last).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "after must be the ID of an event"})
+			return
+		} else if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch events"})
+			return
+		}
+		query = query.Where("(timestamp, event_id) < (?, ?)", last.Timestamp, last.EventID)
+	}
+
+	// Filter by event time
+	for param, condition := range map[string]string{"since": "timestamp >= ?", "until": "timestamp < ?"} {
+		if v := c.Query(param); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be an RFC 3339 time"})
+				return
+			}
+			query = query.Where(condition, parsed.UTC())
+		}
+	}
 
-	query := h.db.Order("timestamp DESC").Limit(100)
+	// Filter by source, matching any of a comma-separated list
+	if sources := services.SplitList(c.Query("source")); len(sources) > 0 {
+		query = query.Where("source IN ?", sources)
+	}
+
+	// Filter events detection has or hasn't processed
+	switch processed := c.Query("processed"); processed {
+	case "":
+	case "true":
+		query = query.Where("processed_at IS NOT NULL")
+	case "false":
+		query = query.Where("processed_at IS NULL")
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "processed must be true or false"})
+		return
+	}
 
 	// Filter by event type
 	if eventType := c.Query("event_type"); eventType != "" {
@@ -102,27 +343,168 @@ func (h *EventsHandler) ListEvents(c *gin.Context) {
 		query = query.Where("severity = ?", severity)
 	}
 
-	if err := query.Find(&events).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch events"})
-		return
+	// Filter by indexed normalized fields
+	if srcIP := c.Query("src_ip"); srcIP != "" {
+		query = query.Where("src_ip = ?", srcIP)
+	}
+	if user := c.Query("user"); user != "" {
+		query = query.Where("user_name = ?", user)
+	}
+	if service := c.Query("service"); service != "" {
+		query = query.Where("service_name = ?", service)
+	}
+
+	// Filter by any normalized field as ?field.<name>=value; hot fields use
+	// their indexed column
+	for key, values := range c.Request.URL.Query() {
+		name, ok := strings.CutPrefix(key, "field.")
+		if !ok || len(values) == 0 {
+			continue
+		}
+		expr, args, ok := database.FieldExpr(name)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid field: " + name})
+			return
+		}
+		query = query.Where(expr+" IN ?", append(args, fieldFilterValues(values[0])))
+	}
+
+	// Filter events flagged for clock skew ("future", "past" or "any")
+	switch skew := c.Query("clock_skew"); skew {
+	case "":
+	case "any":
+		query = query.Where("clock_skew <> ''")
+	default:
+		query = query.Where("clock_skew = ?", skew)
+	}
+
+	// Trace an ingest request
+	if requestID := c.Query("request_id"); requestID != "" {
+		query = query.Where("request_id = ?", requestID)
 	}
 
-	c.JSON(http.StatusOK, events)
+	fields := c.Query("fields")
+	switch fields {
+	case "":
+		events := []models.EventSummary{}
+		if err := query.Select(models.EventSummaryColumns).Scan(&events).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch events"})
+			return
+		}
+		if len(events) == limit {
+			c.Header("X-Next-Cursor", events[len(events)-1].EventID)
+		}
+		c.JSON(http.StatusOK, events)
+
+	case "*":
+		events := []models.Event{}
+		if err := query.Find(&events).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch events"})
+			return
+		}
+		if len(events) == limit {
+			c.Header("X-Next-Cursor", events[len(events)-1].EventID)
+		}
+		c.JSON(http.StatusOK, events)
+
+	default:
+		names := strings.Split(fields, ",")
+		columns := make([]string, 0, len(names))
+		for _, name := range names {
+			column, ok := eventListFields[strings.TrimSpace(name)]
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "unknown field: " + name})
+				return
+			}
+			columns = append(columns, column+" AS "+strings.TrimSpace(name))
+		}
+		// The cursor needs each row's ID, whichever fields are projected
+		columns = append(columns, "event_id AS cursor_event_id")
+
+		events := []map[string]interface{}{}
+		if err := query.Select(columns).Find(&events).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch events"})
+			return
+		}
+		if len(events) == limit {
+			if id, ok := events[len(events)-1]["cursor_event_id"].(string); ok {
+				c.Header("X-Next-Cursor", id)
+			}
+		}
+		// Projected rows skip the model, so compressed payloads are
+		// decoded here
+		for _, event := range events {
+			delete(event, "cursor_event_id")
+			var stored string
+			switch v := event["raw_data"].(type) {
+			case string:
+				stored = v
+			case []byte:
+				stored = string(v)
+			default:
+				continue
+			}
+			if rawData, err := models.DecodePayload(stored); err == nil {
+				event["raw_data"] = rawData
+			}
+		}
+		c.JSON(http.StatusOK, events)
+	}
+}
+
+// fieldFilterValues returns the values a ?field. filter matches: the text,
+// and the number it spells, since payload numbers are stored as numbers
+func fieldFilterValues(value string) []interface{} {
+	if num, err := strconv.ParseFloat(value, 64); err == nil {
+		return []interface{}{value, num}
+	}
+	return []interface{}{value}
 }
 
 // GetEvent handles GET /api/v1/events/:id
 func (h *EventsHandler) GetEvent(c *gin.Context) {
 	eventID := c.Param("id")
 
-	var event models.Event
-	if err := h.db.First(&event, "event_id = ?", eventID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+	event, err := h.eventStore(c).Get(c.Request.Context(), eventID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch event"})
 		}
 		return
 	}
+	if err := h.cold.LoadRawData(c.Request.Context(), event); err != nil {
+		log.Printf("Event %s: %v", eventID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to fetch raw data from cold storage"})
+		return
+	}
 
+	// Detection is the last to change an event, enriching its payload
+	if event.ProcessedAt != nil {
+		setLastModified(c, *event.ProcessedAt)
+	} else {
+		setLastModified(c, event.CreatedAt)
+	}
 	c.JSON(http.StatusOK, event)
 }
+
+// GetDetections handles GET /api/v1/events/:id/detections
+//
+// Returns how detection evaluated the event, newest evaluation first: the
+// rules it was matched against, each condition's outcome and what the
+// matching rules did. An event not yet evaluated, or evaluated with
+// DETECTION_TRACES off, has no evaluations.
+func (h *EventsHandler) GetDetections(c *gin.Context) {
+	eventID := c.Param("id")
+	traces, err := services.EventTraces(h.eventDB(c, h.db), eventID)
+	if err != nil {
+		if errors.Is(err, services.ErrEventNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch detection traces"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"event_id": eventID, "evaluations": traces})
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// FeatureFlagsHandler handles feature flag endpoints
+type FeatureFlagsHandler struct {
+	flags *services.FeatureFlags
+}
+
+// NewFeatureFlagsHandler creates a new feature flags handler
+func NewFeatureFlagsHandler(flags *services.FeatureFlags) *FeatureFlagsHandler {
+	return &FeatureFlagsHandler{flags: flags}
+}
+
+// ListFlags handles GET /api/v1/flags
+func (h *FeatureFlagsHandler) ListFlags(c *gin.Context) {
+	states, err := h.flags.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch feature flags"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"flags": states})
+}
+
+// SetFlag handles PUT /api/v1/admin/flags/:name
+func (h *FeatureFlagsHandler) SetFlag(c *gin.Context) {
+	var req struct {
+		Enabled *bool  `json:"enabled" binding:"required"`
+		Reason  string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actor := currentPrincipal(c).Name
+	ctx := database.WithAuditActor(c.Request.Context(), actor)
+	state, err := h.flags.Set(ctx, c.Param("name"), *req.Enabled, actor, req.Reason)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, state)
+}
+
+// ClearFlag handles DELETE /api/v1/admin/flags/:name
+//
+// The flag returns to its FEATURE_FLAGS or built-in value.
+func (h *FeatureFlagsHandler) ClearFlag(c *gin.Context) {
+	actor := currentPrincipal(c).Name
+	ctx := database.WithAuditActor(c.Request.Context(), actor)
+	state, err := h.flags.Clear(ctx, c.Param("name"), actor)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, state)
+}
+
+func (h *FeatureFlagsHandler) respondError(c *gin.Context, err error) {
+	var unknown *services.UnknownFeatureFlagError
+	if errors.As(err, &unknown) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// HandoffsHandler handles shift handoff endpoints
+type HandoffsHandler struct {
+	handoffs *services.Handoffs
+}
+
+// NewHandoffsHandler creates a new handoffs handler
+func NewHandoffsHandler(handoffs *services.Handoffs) *HandoffsHandler {
+	return &HandoffsHandler{handoffs: handoffs}
+}
+
+// ListHandoffs handles GET /api/v1/incidents/:id/handoffs
+func (h *HandoffsHandler) ListHandoffs(c *gin.Context) {
+	handoffs, err := h.handoffs.List(c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, handoffs)
+}
+
+// DraftHandoff handles POST /api/v1/incidents/:id/handoff/draft
+func (h *HandoffsHandler) DraftHandoff(c *gin.Context) {
+	draft, err := h.handoffs.Draft(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, draft)
+}
+
+// CreateHandoff handles POST /api/v1/incidents/:id/handoff
+//
+// With "draft": true the summary fields left empty are drafted from the
+// incident's activity since its last handoff.
+func (h *HandoffsHandler) CreateHandoff(c *gin.Context) {
+	var req services.HandoffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.Draft && req.CurrentState == "" && req.PendingActions == "" && req.NextSteps == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "current_state, pending_actions or next_steps is required unless draft is set"})
+		return
+	}
+	user := currentPrincipal(c).Name
+	ctx := database.WithAuditActor(c.Request.Context(), user)
+	handoff, err := h.handoffs.Create(ctx, c.Param("id"), user, req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, handoff)
+}
+
+func (h *HandoffsHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrHandoffIncidentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnknownRole), errors.Is(err, services.ErrHandoffNoRecipient):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// readinessTimeout bounds each readiness check so a hung dependency fails
+// the probe instead of stalling it
+const readinessTimeout = 2 * time.Second
+
+// DependencyStatus is the result of one readiness check
+type DependencyStatus struct {
+	Status    string  `json:"status"` // "ok" or "fail"
+	LatencyMS float64 `json:"latency_ms"`
+	Detail    string  `json:"detail,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// HealthHandler handles liveness and readiness probes
+type HealthHandler struct {
+	db        *gorm.DB
+	engine    *services.DetectionEngine
+	outbox    *services.Outbox
+	scheduler *services.Scheduler
+	ingestor  *services.Ingestor
+	startedAt time.Time
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(db *gorm.DB, engine *services.DetectionEngine, outbox *services.Outbox, scheduler *services.Scheduler, ingestor *services.Ingestor) *HealthHandler {
+	return &HealthHandler{
+		db:        db,
+		engine:    engine,
+		outbox:    outbox,
+		scheduler: scheduler,
+		ingestor:  ingestor,
+		startedAt: time.Now(),
+	}
+}
+
+// Liveness handles GET /healthz
+//
+// It checks nothing beyond the process serving requests, so a slow
+// dependency never gets the instance restarted.
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":         "ok",
+		"uptime_seconds": int(time.Since(h.startedAt).Seconds()),
+	})
+}
+
+// Readiness handles GET /readyz
+//
+// Returns 503 when any dependency fails, with per-dependency status and
+// latency.
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	checks := map[string]func(ctx context.Context) (string, error){
+		"database":  h.checkDatabase,
+		"rules":     h.checkRules,
+		"queue":     h.checkQueue,
+		"scheduler": h.checkScheduler,
+	}
+	if h.ingestor.AsyncStats().Enabled {
+		checks["ingest_queue"] = h.checkIngestQueue
+	}
+	if h.engine.QueueStats().Enabled {
+		checks["detection_queue"] = h.checkDetectionQueue
+	}
+
+	results := make(map[string]DependencyStatus, len(checks))
+	ready := true
+	for name, check := range checks {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+		start := time.Now()
+		detail, err := check(ctx)
+		cancel()
+
+		result := DependencyStatus{
+			Status:    "ok",
+			LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			Detail:    detail,
+		}
+		if err != nil {
+			result.Status = "fail"
+			result.Error = err.Error()
+			ready = false
+		}
+		results[name] = result
+	}
+
+	status, code := "ready", http.StatusOK
+	if !ready {
+		status, code = "not_ready", http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{"status": status, "checks": results})
+}
+
+func (h *HealthHandler) checkDatabase(ctx context.Context) (string, error) {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return "", err
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return "", err
+	}
+	var one int
+	if err := h.db.WithContext(ctx).Raw("SELECT 1").Scan(&one).Error; err != nil {
+		return "", err
+	}
+	return "", nil
+}
+
+func (h *HealthHandler) checkRules(ctx context.Context) (string, error) {
+	count := h.engine.RuleCount()
+	if count == 0 {
+		return "", fmt.Errorf("no detection rules loaded")
+	}
+	return fmt.Sprintf("%d rules loaded", count), nil
+}
+
+func (h *HealthHandler) checkQueue(ctx context.Context) (string, error) {
+	pending, oldest, err := h.outbox.Backlog(ctx)
+	if err != nil {
+		return "", err
+	}
+	if oldest == nil {
+		return "0 pending", nil
+	}
+	return fmt.Sprintf("%d pending, oldest %s", pending, time.Since(*oldest).Round(time.Second)), nil
+}
+
+func (h *HealthHandler) checkIngestQueue(ctx context.Context) (string, error) {
+	stats := h.ingestor.AsyncStats()
+	detail := fmt.Sprintf("%d/%d queued, %d failed", stats.Queued, stats.Capacity, stats.Failed)
+	if stats.Queued >= stats.Capacity {
+		return detail, fmt.Errorf("fast-ack queue is full")
+	}
+	return detail, nil
+}
+
+func (h *HealthHandler) checkDetectionQueue(ctx context.Context) (string, error) {
+	stats := h.engine.QueueStats()
+	depths := make([]string, 0, len(stats.Priorities))
+	var oldest float64
+	var full []string
+	for _, p := range stats.Priorities {
+		depths = append(depths, fmt.Sprintf("%s %d", p.Priority, p.Depth))
+		if p.OldestWaitMS > oldest {
+			oldest = p.OldestWaitMS
+		}
+		if p.Depth >= stats.Capacity {
+			full = append(full, p.Priority)
+		}
+	}
+	detail := fmt.Sprintf("%s queued, oldest %s", strings.Join(depths, ", "),
+		time.Duration(oldest*float64(time.Millisecond)).Round(time.Millisecond))
+	if len(full) > 0 {
+		return detail, fmt.Errorf("detection queue is full: %s", strings.Join(full, ", "))
+	}
+	return detail, nil
+}
+
+func (h *HealthHandler) checkScheduler(ctx context.Context) (string, error) {
+	if !h.scheduler.Started() {
+		return "", fmt.Errorf("scheduler not running")
+	}
+	if h.scheduler.Running() {
+		return "running (leader)", nil
+	}
+	return "running (standby)", nil
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// IncidentCommentsHandler handles incident comment endpoints
+type IncidentCommentsHandler struct {
+	db     *gorm.DB
+	outbox *services.Outbox
+}
+
+// NewIncidentCommentsHandler creates a new incident comments handler
+func NewIncidentCommentsHandler(db *gorm.DB, outbox *services.Outbox) *IncidentCommentsHandler {
+	return &IncidentCommentsHandler{db: db, outbox: outbox}
+}
+
+// CreateCommentRequest represents the request body for adding a comment
+type CreateCommentRequest struct {
+	Author string `json:"author" binding:"required"`
+	Body   string `json:"body" binding:"required"`
+}
+
+// ListComments handles GET /api/v1/incidents/:id/comments
+func (h *IncidentCommentsHandler) ListComments(c *gin.Context) {
+	var comments []models.IncidentComment
+	if err := h.db.Where("incident_id = ?", c.Param("id")).Order("created_at ASC").Find(&comments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch comments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// CreateComment handles POST /api/v1/incidents/:id/comments
+func (h *IncidentCommentsHandler) CreateComment(c *gin.Context) {
+	incidentID := c.Param("id")
+
+	var incident models.Incident
+	if err := h.db.First(&incident, "incident_id = ?", incidentID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "incident not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch incident"})
+		}
+		return
+	}
+
+	var req CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment := &models.IncidentComment{
+		IncidentID: incidentID,
+		Author:     req.Author,
+		Body:       req.Body,
+	}
+
+	// Commenting watches the incident; other watchers hear about the comment
+	user := currentPrincipal(c).Name
+	summary, _, _ := strings.Cut(req.Body, "\n")
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		if err := services.WatchIncident(tx, incidentID, user, models.WatchComment); err != nil {
+			return err
+		}
+		return services.NotifyWatchers(tx, h.outbox, &incident, user,
+			fmt.Sprintf("%s commented: %s", req.Author, summary))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create comment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// IncidentRolesHandler handles incident role endpoints
+type IncidentRolesHandler struct {
+	roles *services.IncidentRoles
+}
+
+// NewIncidentRolesHandler creates a new incident roles handler
+func NewIncidentRolesHandler(roles *services.IncidentRoles) *IncidentRolesHandler {
+	return &IncidentRolesHandler{roles: roles}
+}
+
+// AssignRoleRequest represents the request body for assigning a role
+type AssignRoleRequest struct {
+	Assignee string `json:"assignee" binding:"required"`
+	Note     string `json:"note"`
+}
+
+// ListRoles handles GET /api/v1/incidents/:id/roles
+//
+// Current holders are listed unless ?history=true, which lists every
+// assignment including ended ones.
+func (h *IncidentRolesHandler) ListRoles(c *gin.Context) {
+	list := h.roles.Current
+	if c.Query("history") == "true" {
+		list = h.roles.History
+	}
+	assignments, err := list(c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, assignments)
+}
+
+// AssignRole handles PUT /api/v1/incidents/:id/roles/:role
+//
+// Assigning a commander, comms lead or scribe hands the role over from its
+// current holder; SMEs are added alongside each other.
+func (h *IncidentRolesHandler) AssignRole(c *gin.Context) {
+	var req AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user := currentPrincipal(c).Name
+	ctx := database.WithAuditActor(c.Request.Context(), user)
+	assignment, err := h.roles.Assign(ctx, c.Param("id"), c.Param("role"), req.Assignee, user, req.Note)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, assignment)
+}
+
+// UnassignRole handles DELETE /api/v1/incidents/:id/roles/:role
+//
+// ?assignee= ends one holder's assignment, e.g. one of several SMEs;
+// without it every holder of the role is unassigned.
+func (h *IncidentRolesHandler) UnassignRole(c *gin.Context) {
+	user := currentPrincipal(c).Name
+	ctx := database.WithAuditActor(c.Request.Context(), user)
+	if err := h.roles.Unassign(ctx, c.Param("id"), c.Param("role"), c.Query("assignee"), user); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *IncidentRolesHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrRoleIncidentNotFound), errors.Is(err, services.ErrRoleNotHeld):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnknownRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
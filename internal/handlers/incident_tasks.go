@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// IncidentTasksHandler handles incident task/checklist endpoints
+type IncidentTasksHandler struct {
+	db *gorm.DB
+}
+
+// NewIncidentTasksHandler creates a new incident tasks handler
+func NewIncidentTasksHandler(db *gorm.DB) *IncidentTasksHandler {
+	return &IncidentTasksHandler{db: db}
+}
+
+// CreateTaskRequest represents the request body for creating a task
+type CreateTaskRequest struct {
+	Title    string     `json:"title" binding:"required"`
+	Assignee *string    `json:"assignee"`
+	DueAt    *time.Time `json:"due_at"`
+}
+
+// UpdateTaskRequest represents the request body for updating a task
+type UpdateTaskRequest struct {
+	Title    *string    `json:"title"`
+	Assignee *string    `json:"assignee"`
+	DueAt    *time.Time `json:"due_at"`
+	Status   *string    `json:"status"`
+}
+
+// ListTasks handles GET /api/v1/incidents/:id/tasks
+func (h *IncidentTasksHandler) ListTasks(c *gin.Context) {
+	incidentID := c.Param("id")
+
+	query := h.db.Where("incident_id = ?", incidentID).Order("created_at ASC")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var tasks []models.IncidentTask
+	if err := query.Find(&tasks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch tasks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// CreateTask handles POST /api/v1/incidents/:id/tasks
+func (h *IncidentTasksHandler) CreateTask(c *gin.Context) {
+	incidentID := c.Param("id")
+
+	var incident models.Incident
+	if err := h.db.First(&incident, "incident_id = ?", incidentID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "incident not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch incident"})
+		}
+		return
+	}
+
+	var req CreateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task := &models.IncidentTask{
+		IncidentID: incidentID,
+		Title:      req.Title,
+		Assignee:   req.Assignee,
+		DueAt:      req.DueAt,
+		Status:     models.TaskOpen,
+	}
+
+	if err := h.db.Create(task).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create task"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, task)
+}
+
+// GetTask handles GET /api/v1/incidents/:id/tasks/:task_id
+func (h *IncidentTasksHandler) GetTask(c *gin.Context) {
+	task, ok := h.findTask(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// UpdateTask handles PATCH /api/v1/incidents/:id/tasks/:task_id
+func (h *IncidentTasksHandler) UpdateTask(c *gin.Context) {
+	task, ok := h.findTask(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Title != nil {
+		task.Title = *req.Title
+	}
+	if req.Assignee != nil {
+		task.Assignee = req.Assignee
+	}
+	if req.DueAt != nil {
+		task.DueAt = req.DueAt
+	}
+	if req.Status != nil {
+		switch status := models.TaskStatus(*req.Status); status {
+		case models.TaskOpen, models.TaskInProgress:
+			task.Status = status
+			task.CompletedAt = nil
+		case models.TaskDone, models.TaskCancelled:
+			task.Status = status
+			now := time.Now().UTC()
+			task.CompletedAt = &now
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task status"})
+			return
+		}
+	}
+
+	if err := h.db.Save(task).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update task"})
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// DeleteTask handles DELETE /api/v1/incidents/:id/tasks/:task_id
+func (h *IncidentTasksHandler) DeleteTask(c *gin.Context) {
+	task, ok := h.findTask(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.Delete(task).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete task"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// findTask loads the task addressed by the route, writing an error response if missing
+func (h *IncidentTasksHandler) findTask(c *gin.Context) (*models.IncidentTask, bool) {
+	var task models.IncidentTask
+	if err := h.db.First(&task, "task_id = ? AND incident_id = ?", c.Param("task_id"), c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch task"})
+		}
+		return nil, false
+	}
+	return &task, true
+}
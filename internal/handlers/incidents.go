@@ -1,38 +1,108 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
 	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+	"github.com/gixxerblade/incident-response-mvp/internal/store"
 )
 
 // IncidentsHandler handles incident-related API endpoints
 type IncidentsHandler struct {
-	db *gorm.DB
+	db        *gorm.DB
+	incidents store.IncidentStore
+	// reads serves the incident list and reports, possibly from a replica
+	reads     *gorm.DB
+	outbox    *services.Outbox
+	workflows *services.Workflows
+	catalog   *services.ServiceCatalog
+	matrix    *services.SeverityMatrix
+	sla       *services.SLACalendars
+	pii       *services.PIIScrubber
 }
 
-// NewIncidentsHandler creates a new incidents handler
-func NewIncidentsHandler(db *gorm.DB) *IncidentsHandler {
-	return &IncidentsHandler{db: db}
+// NewIncidentsHandler creates a new incidents handler. Incidents are loaded
+// and saved through incidents; the incident list and reports are read from
+// reads, and db serves the related records.
+func NewIncidentsHandler(db, reads *gorm.DB, incidents store.IncidentStore, outbox *services.Outbox, workflows *services.Workflows, catalog *services.ServiceCatalog, matrix *services.SeverityMatrix, sla *services.SLACalendars, pii *services.PIIScrubber) *IncidentsHandler {
+	return &IncidentsHandler{db: db, incidents: incidents, reads: reads, outbox: outbox, workflows: workflows, catalog: catalog, matrix: matrix, sla: sla, pii: pii}
+}
+
+// findIncident loads an incident, answering 404 or 500 when it can't
+func (h *IncidentsHandler) findIncident(c *gin.Context, incidentID string) (*models.Incident, bool) {
+	incident, err := h.incidents.Get(c.Request.Context(), incidentID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "incident not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch incident"})
+		}
+		return nil, false
+	}
+	return incident, true
 }
 
 // ListIncidents handles GET /api/v1/incidents
 func (h *IncidentsHandler) ListIncidents(c *gin.Context) {
 	var incidents []models.Incident
 
-	query := h.db.Order("created_at DESC")
+	query := h.reads.Order("created_at DESC")
+
+	// Filter by indexed columns; a comma-separated value matches any of its
+	// items (?status=open,investigating)
+	for _, column := range incidentListFilters {
+		if values := services.SplitList(c.Query(column)); len(values) > 0 {
+			query = query.Where(column+" IN ?", values)
+		}
+	}
+
+	// Filter by creation and update time
+	for param, condition := range incidentTimeFilters {
+		if v := c.Query(param); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be an RFC 3339 time"})
+				return
+			}
+			query = query.Where(condition, parsed.UTC())
+		}
+	}
+
+	// Filter by tag, matching incidents with any of those given
+	if tags := services.SplitList(c.Query("tag")); len(tags) > 0 {
+		query = query.Where("json_valid(incidents.tags) AND EXISTS (SELECT 1 FROM json_each(incidents.tags) WHERE json_each.value IN ?)", tags)
+	}
+
+	// Filter exercise incidents in or out
+	if exercise := c.Query("exercise"); exercise != "" {
+		query = query.Where("exercise = ?", exercise == "true")
+	}
 
-	// Filter by status
-	if status := c.Query("status"); status != "" {
-		query = query.Where("status = ?", status)
+	// Filter by the request that raised the incident
+	if requestID := c.Query("request_id"); requestID != "" {
+		query = query.Where("request_id = ?", requestID)
 	}
 
-	// Filter by severity
-	if severity := c.Query("severity"); severity != "" {
-		query = query.Where("severity = ?", severity)
+	// Filter by parent campaign
+	if campaignID := c.Query("campaign_id"); campaignID != "" {
+		query = query.Where("campaign_id = ?", campaignID)
+	}
+
+	// Filter by impacted service
+	if service := c.Query("service"); service != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM json_each(incidents.services) WHERE json_each.value = ?)", service)
 	}
 
 	if err := query.Find(&incidents).Error; err != nil {
@@ -43,12 +113,62 @@ func (h *IncidentsHandler) ListIncidents(c *gin.Context) {
 	c.JSON(http.StatusOK, incidents)
 }
 
+// incidentListFilters are the indexed columns GET /api/v1/incidents filters
+// by, each with a query parameter of the same name
+var incidentListFilters = []string{"status", "severity", "category", "triggered_by_rule", "assigned_to", "team"}
+
+// incidentTimeFilters maps the time range parameters of GET
+// /api/v1/incidents to their conditions
+var incidentTimeFilters = map[string]string{
+	"created_after":  "created_at >= ?",
+	"created_before": "created_at < ?",
+	"updated_after":  "updated_at >= ?",
+	"updated_before": "updated_at < ?",
+}
+
+// incidentIncludes maps the names GET /api/v1/incidents/:id?include= accepts
+// to the IncidentDetail association preloaded for them and its order
+var incidentIncludes = map[string]struct{ association, order string }{
+	"actions":  {"Actions", "created_at ASC"},
+	"runs":     {"Runs", "started_at ASC"},
+	"comments": {"Comments", "created_at ASC"},
+	"tasks":    {"Tasks", "created_at ASC"},
+	"alerts":   {"Alerts", "created_at ASC"},
+	"updates":  {"Updates", "created_at ASC"},
+	"handoffs": {"Handoffs", "created_at ASC"},
+	"events":   {}, // loaded from RelatedEvents
+}
+
 // GetIncident handles GET /api/v1/incidents/:id
+//
+// ?include=events,actions,comments,... adds those related records to the
+// incident, each loaded with a single query (see incidentIncludes).
 func (h *IncidentsHandler) GetIncident(c *gin.Context) {
 	incidentID := c.Param("id")
 
-	var incident models.Incident
-	if err := h.db.First(&incident, "incident_id = ?", incidentID).Error; err != nil {
+	query := h.db.Preload("ActionsTaken", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	})
+	includes := services.SplitList(c.Query("include"))
+	includeEvents := false
+	for _, name := range includes {
+		include, ok := incidentIncludes[name]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown include: " + name + " (valid: " + strings.Join(incidentIncludeNames(), ", ") + ")"})
+			return
+		}
+		if name == "events" {
+			includeEvents = true
+			continue
+		}
+		order := include.order
+		query = query.Preload(include.association, func(db *gorm.DB) *gorm.DB {
+			return db.Order(order)
+		})
+	}
+
+	var incident models.IncidentDetail
+	if err := query.First(&incident, "incident_id = ?", incidentID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "incident not found"})
 		} else {
@@ -57,42 +177,214 @@ func (h *IncidentsHandler) GetIncident(c *gin.Context) {
 		return
 	}
 
+	if includeEvents && incident.RelatedEvents != "" {
+		var eventIDs []string
+		if err := json.Unmarshal([]byte(incident.RelatedEvents), &eventIDs); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse related events"})
+			return
+		}
+		if len(eventIDs) > 0 {
+			err := h.db.Model(&models.Event{}).Select(models.EventSummaryColumns).
+				Where("event_id IN ?", eventIDs).Order("timestamp ASC").Scan(&incident.Events).Error
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch related events"})
+				return
+			}
+		}
+	}
+
+	// Related records change without touching the incident, so only the
+	// incident on its own has a modification time. Its actions taken are
+	// always included and are added without touching it either, so the
+	// latest of them counts too.
+	if len(includes) == 0 {
+		modified := incident.UpdatedAt
+		for _, action := range incident.ActionsTaken {
+			if action.CreatedAt.After(modified) {
+				modified = action.CreatedAt
+			}
+		}
+		setLastModified(c, modified)
+	}
 	c.JSON(http.StatusOK, incident)
 }
 
-// UpdateIncidentRequest represents the request body for updating an incident
+// incidentIncludeNames returns the names ?include= accepts, sorted
+func incidentIncludeNames() []string {
+	names := make([]string, 0, len(incidentIncludes))
+	for name := range incidentIncludes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ListActionsTaken handles GET /api/v1/incidents/:id/actions: the steps
+// playbook runs have taken for the incident, oldest first
+func (h *IncidentsHandler) ListActionsTaken(c *gin.Context) {
+	var actions []models.IncidentAction
+	if err := h.db.Where("incident_id = ?", c.Param("id")).Order("created_at ASC").Find(&actions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch actions taken"})
+		return
+	}
+
+	c.JSON(http.StatusOK, actions)
+}
+
+// UpdateIncidentRequest represents the request body for updating an
+// incident. Only the fields present are changed; nullable fields sent as
+// null are cleared, and are listed in Cleared.
 type UpdateIncidentRequest struct {
-	Status     *string `json:"status"`
-	AssignedTo *string `json:"assigned_to"`
-	Notes      *string `json:"notes"`
+	Status     *string  `json:"status"`
+	Severity   *string  `json:"severity"`
+	Resolution *string  `json:"resolution"`
+	AssignedTo *string  `json:"assigned_to"`
+	Team       *string  `json:"team"`
+	Notes      *string  `json:"notes"`    // appended to the notes
+	Services   []string `json:"services"` // added to the impacted services
+
+	Cleared map[string]bool `json:"-"`
+}
+
+// incidentPatchNullable tells, for each field PATCH /api/v1/incidents/:id
+// accepts, whether null clears it
+var incidentPatchNullable = map[string]bool{
+	"status":      false,
+	"severity":    false,
+	"resolution":  true,
+	"assigned_to": true,
+	"team":        true,
+	"notes":       false,
+	"services":    false,
+}
+
+// parseIncidentPatch decodes and validates an incident update, returning the
+// problems with each invalid field by name
+func parseIncidentPatch(body map[string]json.RawMessage) (*UpdateIncidentRequest, map[string]string) {
+	req := &UpdateIncidentRequest{Cleared: make(map[string]bool)}
+	problems := make(map[string]string)
+	for name, raw := range body {
+		nullable, known := incidentPatchNullable[name]
+		if !known {
+			problems[name] = "unknown field"
+			continue
+		}
+		if string(raw) == "null" {
+			if nullable {
+				req.Cleared[name] = true
+			} else {
+				problems[name] = "may not be null"
+			}
+			continue
+		}
+
+		if name == "services" {
+			if err := json.Unmarshal(raw, &req.Services); err != nil {
+				problems[name] = "must be an array of service names"
+				continue
+			}
+			for _, service := range req.Services {
+				if strings.TrimSpace(service) == "" {
+					problems[name] = "service names may not be empty"
+				}
+			}
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			problems[name] = "must be a string"
+			continue
+		}
+		switch name {
+		case "status":
+			req.Status = &value
+		case "severity":
+			if models.SeverityLevel(value).Rank() < 0 {
+				problems[name] = "must be one of info, low, medium, high, critical"
+				continue
+			}
+			req.Severity = &value
+		case "resolution":
+			req.Resolution = &value
+		case "assigned_to":
+			if strings.TrimSpace(value) == "" {
+				problems[name] = "may not be empty; send null to unassign"
+				continue
+			}
+			req.AssignedTo = &value
+		case "team":
+			req.Team = &value
+		case "notes":
+			if strings.TrimSpace(value) == "" {
+				problems[name] = "may not be empty"
+				continue
+			}
+			req.Notes = &value
+		}
+	}
+	if len(body) == 0 {
+		problems["body"] = "no fields to update"
+	}
+	return req, problems
 }
 
 // UpdateIncident handles PATCH /api/v1/incidents/:id
 func (h *IncidentsHandler) UpdateIncident(c *gin.Context) {
 	incidentID := c.Param("id")
 
-	var incident models.Incident
-	if err := h.db.First(&incident, "incident_id = ?", incidentID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "incident not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch incident"})
-		}
+	incident, ok := h.findIncident(c, incidentID)
+	if !ok {
 		return
 	}
 
-	var req UpdateIncidentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(c.Request.Body).Decode(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "request body must be a JSON object"})
+		return
+	}
+	req, problems := parseIncidentPatch(body)
+	if len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid incident update", "fields": problems})
 		return
 	}
 
-	// Update fields if provided
+	// Update the fields present
+	actor := currentPrincipal(c).Name
+	previous := incident.Status
+	var changes []string
 	if req.Status != nil {
-		incident.Status = models.IncidentStatus(*req.Status)
+		incident.SetStatus(models.IncidentStatus(*req.Status), actor)
+		changes = append(changes, "status "+*req.Status)
+	}
+	if req.Severity != nil {
+		// Given directly rather than derived from the severity matrix
+		incident.Severity = models.SeverityLevel(*req.Severity)
+		incident.Impact, incident.Urgency = 0, 0
+		changes = append(changes, "severity "+*req.Severity)
+	}
+	if req.Resolution != nil {
+		incident.Resolution = *req.Resolution
+		changes = append(changes, "resolution "+*req.Resolution)
+	}
+	if req.Cleared["resolution"] {
+		incident.Resolution = ""
+		changes = append(changes, "resolution cleared")
 	}
 	if req.AssignedTo != nil {
 		incident.AssignedTo = req.AssignedTo
+		changes = append(changes, "assigned to "+*req.AssignedTo)
+	}
+	if req.Cleared["assigned_to"] {
+		incident.AssignedTo = nil
+		changes = append(changes, "unassigned")
+	}
+	if req.Team != nil {
+		incident.Team = *req.Team
+		changes = append(changes, "team "+*req.Team)
+	}
+	if req.Cleared["team"] {
+		incident.Team = ""
+		changes = append(changes, "team cleared")
 	}
 	if req.Notes != nil {
 		if incident.Notes != "" {
@@ -100,9 +392,40 @@ func (h *IncidentsHandler) UpdateIncident(c *gin.Context) {
 		} else {
 			incident.Notes = *req.Notes
 		}
+		changes = append(changes, "note added")
+	}
+	if len(req.Services) > 0 {
+		changes = append(changes, "impacts "+strings.Join(req.Services, ", "))
+	}
+	if respondWorkflowError(c, h.workflows.CheckTransition(incident, previous)) {
+		return
 	}
 
-	if err := h.db.Save(&incident).Error; err != nil {
+	err := h.incidents.RunInTx(c.Request.Context(), func(ctx context.Context) error {
+		if err := h.incidents.Save(ctx, incident); err != nil {
+			return err
+		}
+		// The catalog, watchers and outbox live in the database
+		tx, ok := store.TxFrom(ctx)
+		if !ok {
+			return nil
+		}
+		if err := h.catalog.ApplyImpact(tx, incident, req.Services); err != nil {
+			return err
+		}
+		// The assignee follows the incident from now on
+		if req.AssignedTo != nil && *req.AssignedTo != "" {
+			if err := services.WatchIncident(tx, incident.IncidentID, *req.AssignedTo, models.WatchAssignment); err != nil {
+				return err
+			}
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		return services.NotifyWatchers(tx, h.outbox, incident, actor,
+			fmt.Sprintf("%s by %s", strings.Join(changes, ", "), actor))
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update incident"})
 		return
 	}
@@ -110,25 +433,293 @@ func (h *IncidentsHandler) UpdateIncident(c *gin.Context) {
 	c.JSON(http.StatusOK, incident)
 }
 
+// ResolveRequest represents the optional request body for resolving an
+// incident
+type ResolveRequest struct {
+	Resolution string `json:"resolution"`
+}
+
 // ResolveIncident handles POST /api/v1/incidents/:id/resolve
 func (h *IncidentsHandler) ResolveIncident(c *gin.Context) {
 	incidentID := c.Param("id")
 
-	var incident models.Incident
-	if err := h.db.First(&incident, "incident_id = ?", incidentID).Error; err != nil {
+	var req ResolveRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	incident, ok := h.findIncident(c, incidentID)
+	if !ok {
+		return
+	}
+
+	actor := currentPrincipal(c).Name
+	previous := incident.Status
+	incident.SetStatus(models.StatusResolved, actor)
+	if req.Resolution != "" {
+		incident.Resolution = req.Resolution
+	}
+	if respondWorkflowError(c, h.workflows.CheckTransition(incident, previous)) {
+		return
+	}
+	err := h.incidents.RunInTx(c.Request.Context(), func(ctx context.Context) error {
+		if err := h.incidents.Save(ctx, incident); err != nil {
+			return err
+		}
+		tx, ok := store.TxFrom(ctx)
+		if !ok {
+			return nil
+		}
+		return services.NotifyWatchers(tx, h.outbox, incident, actor, "resolved by "+actor)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve incident"})
+		return
+	}
+
+	c.JSON(http.StatusOK, incident)
+}
+
+// ReopenRequest represents the request body for reopening an incident
+type ReopenRequest struct {
+	Reason string `json:"reason" binding:"required"`
+	Status string `json:"status"`
+}
+
+// ReopenIncident handles POST /api/v1/incidents/:id/reopen
+func (h *IncidentsHandler) ReopenIncident(c *gin.Context) {
+	var req ReopenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actor := currentPrincipal(c).Name
+	var incident *models.Incident
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if incident, err = services.ReopenIncident(tx, h.workflows, c.Param("id"), actor, req.Reason, models.IncidentStatus(req.Status)); err != nil {
+			return err
+		}
+		return services.NotifyWatchers(tx, h.outbox, incident, actor, fmt.Sprintf("reopened by %s: %s", actor, req.Reason))
+	})
+	if respondWorkflowError(c, err) {
+		return
+	}
+	if err != nil {
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "incident not found"})
+		case errors.Is(err, services.ErrInvalidReopenStatus):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrIncidentNotResolved):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reopen incident"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, incident)
+}
+
+// respondWorkflowError answers 422 with the allowed statuses or missing
+// fields when err is a workflow violation, reporting whether it did
+func respondWorkflowError(c *gin.Context, err error) bool {
+	var workflowErr *services.WorkflowError
+	if !errors.As(err, &workflowErr) {
+		return false
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error":          workflowErr.Error(),
+		"allowed":        workflowErr.Allowed,
+		"missing_fields": workflowErr.Missing,
+	})
+	return true
+}
+
+// ListWorkflows handles GET /api/v1/workflows
+func (h *IncidentsHandler) ListWorkflows(c *gin.Context) {
+	c.JSON(http.StatusOK, h.workflows.File())
+}
+
+// GetSLA handles GET /api/v1/incidents/:id/sla
+func (h *IncidentsHandler) GetSLA(c *gin.Context) {
+	incident, ok := h.findIncident(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, h.sla.Status(incident, time.Now().UTC()))
+}
+
+// ListSLACalendars handles GET /api/v1/sla/calendars
+func (h *IncidentsHandler) ListSLACalendars(c *gin.Context) {
+	c.JSON(http.StatusOK, h.sla.File())
+}
+
+// GetSeverityMatrix handles GET /api/v1/severity-matrix
+//
+// With ?impact= and ?urgency= it also returns the severity they derive.
+func (h *IncidentsHandler) GetSeverityMatrix(c *gin.Context) {
+	response := gin.H{"matrix": h.matrix.Rows()}
+	if c.Query("impact") != "" || c.Query("urgency") != "" {
+		impact, errImpact := strconv.Atoi(c.Query("impact"))
+		urgency, errUrgency := strconv.Atoi(c.Query("urgency"))
+		if errImpact != nil || errUrgency != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "impact and urgency must both be integers"})
+			return
+		}
+		severity, err := h.matrix.Derive(impact, urgency)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		response["severity"] = severity
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// AcknowledgeRequest represents the request body for acknowledging an incident
+type AcknowledgeRequest struct {
+	AcknowledgedBy string `json:"acknowledged_by"`
+}
+
+// AcknowledgeIncident handles POST /api/v1/incidents/:id/acknowledge
+func (h *IncidentsHandler) AcknowledgeIncident(c *gin.Context) {
+	var req AcknowledgeRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.AcknowledgedBy == "" {
+		req.AcknowledgedBy = "api"
+	}
+
+	var incident *models.Incident
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		var before models.Incident
+		if err := tx.Select("acknowledged_at").First(&before, "incident_id = ?", c.Param("id")).Error; err != nil {
+			return err
+		}
+		var err error
+		if incident, err = services.AcknowledgeIncident(tx, c.Param("id"), req.AcknowledgedBy); err != nil {
+			return err
+		}
+		if before.AcknowledgedAt != nil {
+			return nil
+		}
+		return services.NotifyWatchers(tx, h.outbox, incident, currentPrincipal(c).Name,
+			"acknowledged by "+req.AcknowledgedBy)
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "incident not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to acknowledge incident"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, incident)
+}
+
+// GetBlastRadius handles GET /api/v1/incidents/:id/blast-radius
+func (h *IncidentsHandler) GetBlastRadius(c *gin.Context) {
+	radius, err := h.catalog.BlastRadius(c.Param("id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "incident not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute blast radius"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, radius)
+}
+
+// GetDetection handles GET /api/v1/incidents/:id/detection
+//
+// Explains the rule match that created the incident. Incidents created by
+// playbook actions have none.
+func (h *IncidentsHandler) GetDetection(c *gin.Context) {
+	explanation, err := services.IncidentExplanation(h.db, c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "incident not found"})
+		case errors.Is(err, services.ErrNoDetection):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch detection"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, explanation)
+}
+
+// GetReport handles GET /api/v1/incidents/:id/report
+//
+// ?format= selects markdown (default), html, pdf or json.
+func (h *IncidentsHandler) GetReport(c *gin.Context) {
+	incidentID := c.Param("id")
+	format := c.DefaultQuery("format", "markdown")
+
+	var contentType, extension string
+	switch format {
+	case "markdown", "md":
+		contentType, extension = "text/markdown; charset=utf-8", "md"
+	case "html":
+		contentType, extension = "text/html; charset=utf-8", "html"
+	case "pdf":
+		contentType, extension = "application/pdf", "pdf"
+	case "json":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be markdown, html, pdf or json"})
+		return
+	}
+
+	report, err := services.BuildIncidentReport(h.reads, incidentID)
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "incident not found"})
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch incident"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build report"})
 		}
 		return
 	}
+	report.MaskPII(h.pii)
 
-	incident.Status = models.StatusResolved
-	if err := h.db.Save(&incident).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve incident"})
+	var body []byte
+	switch extension {
+	case "":
+		if h.pii.OnExport() {
+			masked, err := h.pii.ScrubObject(report)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to render report"})
+				return
+			}
+			c.JSON(http.StatusOK, masked)
+			return
+		}
+		c.JSON(http.StatusOK, report)
+		return
+	case "md":
+		body, err = report.RenderMarkdown()
+	case "html":
+		body, err = report.RenderHTML()
+	case "pdf":
+		body, err = report.RenderPDF()
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to render report"})
 		return
 	}
 
-	c.JSON(http.StatusOK, incident)
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="incident-%s.%s"`, incidentID, extension))
+	c.Data(http.StatusOK, contentType, body)
 }
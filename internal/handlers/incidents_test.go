@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+	"github.com/gixxerblade/incident-response-mvp/internal/store"
+)
+
+// newTestIncidentsRouter serves the incident routes that only need the
+// incident store
+func newTestIncidentsRouter(t *testing.T, incidents store.IncidentStore) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	workflows, err := services.LoadWorkflows(filepath.Join(dir, "workflows.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sla, err := services.LoadSLACalendars(filepath.Join(dir, "sla.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewIncidentsHandler(nil, nil, incidents, nil, workflows, nil, nil, sla, nil)
+	router := gin.New()
+	router.PATCH("/incidents/:id", h.UpdateIncident)
+	router.POST("/incidents/:id/resolve", h.ResolveIncident)
+	router.GET("/incidents/:id/sla", h.GetSLA)
+	return router
+}
+
+func serve(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestIncidentHandlersMissingIncident(t *testing.T) {
+	router := newTestIncidentsRouter(t, store.NewMemoryIncidents())
+	for _, tt := range []struct{ method, path, body string }{
+		{http.MethodPatch, "/incidents/missing", `{"status":"investigating"}`},
+		{http.MethodPost, "/incidents/missing/resolve", ``},
+		{http.MethodGet, "/incidents/missing/sla", ``},
+	} {
+		if got := serve(router, tt.method, tt.path, tt.body); got.Code != http.StatusNotFound {
+			t.Errorf("%s %s = %d, want 404", tt.method, tt.path, got.Code)
+		}
+	}
+}
+
+func TestUpdateIncidentRejectsInvalidFields(t *testing.T) {
+	incidents := store.NewMemoryIncidents()
+	incident := &models.Incident{Status: models.StatusOpen, Severity: models.SeverityHigh, Title: "Brute force"}
+	if err := incidents.Create(context.Background(), incident); err != nil {
+		t.Fatal(err)
+	}
+	router := newTestIncidentsRouter(t, incidents)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantField  string
+	}{
+		{"not an object", `[1]`, http.StatusBadRequest, ""},
+		{"unknown field", `{"colour":"red"}`, http.StatusBadRequest, "colour"},
+		{"bad severity", `{"severity":"urgent"}`, http.StatusBadRequest, "severity"},
+		{"null status", `{"status":null}`, http.StatusBadRequest, "status"},
+		{"status outside the workflow", `{"status":"bogus"}`, http.StatusUnprocessableEntity, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := serve(router, http.MethodPatch, "/incidents/"+incident.IncidentID, tt.body)
+			if got.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", got.Code, tt.wantStatus, got.Body)
+			}
+			if tt.wantField == "" {
+				return
+			}
+			var body struct {
+				Fields map[string]string `json:"fields"`
+			}
+			if err := json.Unmarshal(got.Body.Bytes(), &body); err != nil || body.Fields[tt.wantField] == "" {
+				t.Errorf("response doesn't explain %s: %s", tt.wantField, got.Body)
+			}
+		})
+	}
+
+	stored, _ := incidents.Get(context.Background(), incident.IncidentID)
+	if stored.Status != models.StatusOpen || stored.Severity != models.SeverityHigh {
+		t.Errorf("rejected updates changed the incident: status %s, severity %s", stored.Status, stored.Severity)
+	}
+}
+
+func TestUpdateIncidentSavesChanges(t *testing.T) {
+	incidents := store.NewMemoryIncidents()
+	incident := &models.Incident{Status: models.StatusOpen, Severity: models.SeverityHigh, Title: "Brute force"}
+	if err := incidents.Create(context.Background(), incident); err != nil {
+		t.Fatal(err)
+	}
+	router := newTestIncidentsRouter(t, incidents)
+
+	got := serve(router, http.MethodPatch, "/incidents/"+incident.IncidentID,
+		`{"status":"investigating","severity":"critical","assigned_to":"alice","notes":"Looking into it"}`)
+	if got.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", got.Code, got.Body)
+	}
+	stored, _ := incidents.Get(context.Background(), incident.IncidentID)
+	if stored.Status != models.StatusInvestigating || stored.Severity != models.SeverityCritical {
+		t.Errorf("status %s, severity %s; want investigating, critical", stored.Status, stored.Severity)
+	}
+	if stored.AssignedTo == nil || *stored.AssignedTo != "alice" || stored.Notes != "Looking into it" {
+		t.Errorf("assignee or notes not saved: %+v", stored)
+	}
+
+	got = serve(router, http.MethodPatch, "/incidents/"+incident.IncidentID, `{"assigned_to":null}`)
+	if got.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", got.Code, got.Body)
+	}
+	if stored, _ = incidents.Get(context.Background(), incident.IncidentID); stored.AssignedTo != nil {
+		t.Errorf("assignee not cleared: %s", *stored.AssignedTo)
+	}
+}
+
+func TestResolveIncident(t *testing.T) {
+	incidents := store.NewMemoryIncidents()
+	incident := &models.Incident{Status: models.StatusInvestigating, Severity: models.SeverityHigh, Title: "Brute force"}
+	if err := incidents.Create(context.Background(), incident); err != nil {
+		t.Fatal(err)
+	}
+	router := newTestIncidentsRouter(t, incidents)
+
+	got := serve(router, http.MethodPost, "/incidents/"+incident.IncidentID+"/resolve", `{"resolution":"Blocked the source"}`)
+	if got.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", got.Code, got.Body)
+	}
+	stored, _ := incidents.Get(context.Background(), incident.IncidentID)
+	if stored.Status != models.StatusResolved || stored.Resolution != "Blocked the source" || stored.ResolvedAt == nil {
+		t.Errorf("incident not resolved: status %s, resolution %q", stored.Status, stored.Resolution)
+	}
+}
+
+func TestGetSLA(t *testing.T) {
+	incidents := store.NewMemoryIncidents()
+	incident := &models.Incident{Status: models.StatusOpen, Severity: models.SeverityCritical, Title: "Ransomware"}
+	if err := incidents.Create(context.Background(), incident); err != nil {
+		t.Fatal(err)
+	}
+	router := newTestIncidentsRouter(t, incidents)
+
+	got := serve(router, http.MethodGet, "/incidents/"+incident.IncidentID+"/sla", "")
+	if got.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", got.Code, got.Body)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(got.Body.Bytes(), &body); err != nil || len(body) == 0 {
+		t.Errorf("unexpected SLA response: %s", got.Body)
+	}
+}
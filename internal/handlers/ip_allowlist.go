@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// RequireSourceIP rejects requests whose client address is outside
+// allowlist. The client address is the connection's peer, or what a trusted
+// proxy forwarded in X-Forwarded-For or X-Real-IP.
+func RequireSourceIP(allowlist *services.IPAllowlist) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !allowSourceIP(c, allowlist) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAdmin admits admins calling from the admin allowlist. Every
+// admin-role route takes it, inside /admin or not, so the allowlist can't be
+// sidestepped.
+func RequireAdmin(allowlist *services.IPAllowlist) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !allowSourceIP(c, allowlist) || !allowRole(c, services.RoleAdmin) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// allowSourceIP aborts the request when its client address is outside
+// allowlist
+func allowSourceIP(c *gin.Context, allowlist *services.IPAllowlist) bool {
+	if ip := c.ClientIP(); !allowlist.Allows(ip) {
+		log.Printf("Rejected %s %s from %s: source address not allowed", c.Request.Method, c.FullPath(), ip)
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "source address not allowed"})
+		return false
+	}
+	return true
+}
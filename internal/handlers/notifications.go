@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// NotificationsHandler handles notification routing endpoints
+type NotificationsHandler struct {
+	db *gorm.DB
+}
+
+// NewNotificationsHandler creates a new notifications handler
+func NewNotificationsHandler(db *gorm.DB) *NotificationsHandler {
+	return &NotificationsHandler{db: db}
+}
+
+// NotificationRouteRequest represents the request body for creating or replacing a route
+type NotificationRouteRequest struct {
+	RouteID          string   `json:"route_id"`
+	Name             string   `json:"name"`
+	Priority         *int     `json:"priority"`
+	Disabled         bool     `json:"disabled"`
+	Stop             bool     `json:"stop"`
+	Severities       []string `json:"severities"`
+	Categories       []string `json:"categories"`
+	Tags             []string `json:"tags"`
+	Teams            []string `json:"teams"`
+	Targets          []string `json:"targets" binding:"required"`
+	Fallbacks        []string `json:"fallbacks"`
+	QuietStart       string   `json:"quiet_start"`
+	QuietEnd         string   `json:"quiet_end"`
+	QuietTimezone    string   `json:"quiet_timezone"`
+	QuietMinSeverity string   `json:"quiet_min_severity"`
+	DedupWindow      int      `json:"dedup_window"`
+	DigestSeverities []string `json:"digest_severities"`
+	DigestInterval   string   `json:"digest_interval"`
+}
+
+// toRoute builds the route model from a request
+func (req NotificationRouteRequest) toRoute(routeID string) models.NotificationRoute {
+	route := models.NotificationRoute{
+		RouteID:          routeID,
+		Name:             req.Name,
+		Priority:         100,
+		Disabled:         req.Disabled,
+		Stop:             req.Stop,
+		Severities:       req.Severities,
+		Categories:       req.Categories,
+		Tags:             req.Tags,
+		Teams:            req.Teams,
+		Targets:          req.Targets,
+		Fallbacks:        req.Fallbacks,
+		QuietStart:       req.QuietStart,
+		QuietEnd:         req.QuietEnd,
+		QuietTimezone:    req.QuietTimezone,
+		QuietMinSeverity: models.SeverityLevel(req.QuietMinSeverity),
+		DedupWindow:      req.DedupWindow,
+		DigestSeverities: req.DigestSeverities,
+		DigestInterval:   req.DigestInterval,
+	}
+	if req.Priority != nil {
+		route.Priority = *req.Priority
+	}
+	return route
+}
+
+// ListRoutes handles GET /api/v1/notifications/routes
+func (h *NotificationsHandler) ListRoutes(c *gin.Context) {
+	var routes []models.NotificationRoute
+	if err := h.db.Order("priority ASC, route_id ASC").Find(&routes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch notification routes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, routes)
+}
+
+// CreateRoute handles POST /api/v1/notifications/routes
+func (h *NotificationsHandler) CreateRoute(c *gin.Context) {
+	var req NotificationRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	route := req.toRoute(req.RouteID)
+	if err := services.ValidateNotificationRoute(&route); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existing int64
+	h.db.Model(&models.NotificationRoute{}).Where("route_id = ?", route.RouteID).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "route already exists"})
+		return
+	}
+
+	if err := h.db.Create(&route).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create notification route"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, route)
+}
+
+// UpdateRoute handles PUT /api/v1/notifications/routes/:id
+func (h *NotificationsHandler) UpdateRoute(c *gin.Context) {
+	var existing models.NotificationRoute
+	if err := h.db.First(&existing, "route_id = ?", c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "route not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch notification route"})
+		}
+		return
+	}
+
+	var req NotificationRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	route := req.toRoute(existing.RouteID)
+	route.CreatedAt = existing.CreatedAt
+	if err := services.ValidateNotificationRoute(&route); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.db.Save(&route).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update notification route"})
+		return
+	}
+
+	c.JSON(http.StatusOK, route)
+}
+
+// DeleteRoute handles DELETE /api/v1/notifications/routes/:id
+func (h *NotificationsHandler) DeleteRoute(c *gin.Context) {
+	result := h.db.Delete(&models.NotificationRoute{}, "route_id = ?", c.Param("id"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete notification route"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "route not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /api/v1/notifications/deliveries
+func (h *NotificationsHandler) ListDeliveries(c *gin.Context) {
+	query := h.db.Order("created_at DESC").Limit(100)
+	if incidentID := c.Query("incident_id"); incidentID != "" {
+		query = query.Where("incident_id = ?", incidentID)
+	}
+	if routeID := c.Query("route_id"); routeID != "" {
+		query = query.Where("route_id = ?", routeID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if digestID := c.Query("digest_id"); digestID != "" {
+		query = query.Where("digest_id = ?", digestID)
+	}
+
+	var deliveries []models.NotificationDelivery
+	if err := query.Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch notification deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// oidcStateCookie holds a sign-in in progress
+const oidcStateCookie = "ir_oidc_login"
+
+// OIDCHandler handles single sign-on through an OpenID Connect provider
+type OIDCHandler struct {
+	oidc     *services.OIDC
+	sessions *services.Sessions
+	secure   bool
+}
+
+// NewOIDCHandler creates an OIDC sign-in handler; secure marks its cookies
+// HTTPS-only
+func NewOIDCHandler(oidc *services.OIDC, sessions *services.Sessions, secure bool) *OIDCHandler {
+	return &OIDCHandler{oidc: oidc, sessions: sessions, secure: secure}
+}
+
+// Login handles GET /api/v1/auth/oidc/login
+//
+// It redirects to the identity provider; ?redirect= names a path on this
+// server to return to once signed in.
+func (h *OIDCHandler) Login(c *gin.Context) {
+	redirect := c.Query("redirect")
+	// Only local paths, so the login can't be used as an open redirect
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.Contains(redirect, "\\") {
+		redirect = ""
+	}
+	login := h.oidc.NewLoginState(redirect)
+	authURL, err := h.oidc.AuthURL(c.Request.Context(), login)
+	if err != nil {
+		log.Printf("OIDC login failed: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "identity provider unavailable"})
+		return
+	}
+	h.setCookie(c, oidcStateCookie, h.oidc.SealLoginState(login), int((10 * time.Minute).Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback handles GET /api/v1/auth/oidc/callback
+//
+// It completes the sign-in, provisioning the user on their first login, and
+// starts a session: the token is set in the session cookie and returned, or
+// the browser is sent back to the login's redirect.
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "sign-in failed: " + providerErr + " " + c.Query("error_description")})
+		return
+	}
+	sealed, _ := c.Cookie(oidcStateCookie)
+	h.setCookie(c, oidcStateCookie, "", -1)
+	login, err := h.oidc.OpenLoginState(sealed, c.Query("state"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	identity, err := h.oidc.Exchange(ctx, c.Query("code"), login)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	ctx = database.WithAuditActor(ctx, identity.Username)
+	user, err := h.oidc.Provision(ctx, identity)
+	if errors.Is(err, services.ErrOIDCLogin) {
+		h.respondError(c, err)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	principal := services.Principal{Name: user.Name, Role: services.Role(user.Role)}
+	token, session, err := h.sessions.Create(ctx, principal, "oidc")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("User %s signed in as %s", user.Name, user.Role)
+
+	h.setCookie(c, SessionCookie, token, int(time.Until(session.ExpiresAt).Seconds()))
+	if login.Redirect != "" {
+		c.Redirect(http.StatusFound, login.Redirect)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"token":      token,
+		"expires_at": session.ExpiresAt,
+		"user":       user,
+	})
+}
+
+// Logout handles POST /api/v1/auth/logout, ending the caller's session
+func (h *OIDCHandler) Logout(c *gin.Context) {
+	token := requestCredential(c)
+	h.setCookie(c, SessionCookie, "", -1)
+	if !services.IsSessionToken(token) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no session to end"})
+		return
+	}
+	if err := h.sessions.Revoke(c.Request.Context(), token); err != nil && !errors.Is(err, services.ErrSessionNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *OIDCHandler) setCookie(c *gin.Context, name, value string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(name, value, maxAge, "/", "", h.secure, true)
+}
+
+func (h *OIDCHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrOIDCNoRole):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrOIDCLogin):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	default:
+		log.Printf("OIDC sign-in failed: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "identity provider unavailable"})
+	}
+}
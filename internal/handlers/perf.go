@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// PerfHandler serves detection timings and runtime profiles
+type PerfHandler struct {
+	engine *services.DetectionEngine
+	perf   *services.PerfRecorder
+}
+
+// NewPerfHandler creates a new perf handler
+func NewPerfHandler(engine *services.DetectionEngine) *PerfHandler {
+	return &PerfHandler{engine: engine, perf: engine.Perf()}
+}
+
+// GetPerf handles GET /api/v1/admin/perf
+//
+// Lists the ?limit= (default 20) slowest rules and conditions by p95 along
+// with pipeline latency histograms and detection queue depths, for this
+// instance only.
+func (h *PerfHandler) GetPerf(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a non-negative integer"})
+		return
+	}
+
+	report := h.perf.Report(limit)
+	if stats := h.engine.QueueStats(); stats.Enabled {
+		report.Queue = &stats
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// ResetPerf handles POST /api/v1/admin/perf/reset
+func (h *PerfHandler) ResetPerf(c *gin.Context) {
+	h.perf.Reset()
+	c.JSON(http.StatusOK, gin.H{"message": "perf samples reset"})
+}
+
+// Pprof handles GET and POST /api/v1/admin/pprof/*profile, serving the
+// net/http/pprof index and profiles
+func (h *PerfHandler) Pprof(c *gin.Context) {
+	switch name := strings.TrimPrefix(c.Param("profile"), "/"); name {
+	case "":
+		// The index links to profiles relative to the trailing slash
+		pprof.Index(c.Writer, c.Request)
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+	}
+}
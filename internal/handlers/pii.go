@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// ErasureRequest is the body of a right-to-erasure request
+type ErasureRequest struct {
+	Identifier string `json:"identifier" binding:"required"`
+}
+
+// PIIHandler handles personal data endpoints
+type PIIHandler struct {
+	db      *gorm.DB
+	tenants *database.Tenants
+	cold    *services.ColdStorage
+	pii     *services.PIIScrubber
+}
+
+// NewPIIHandler creates a new PII handler
+func NewPIIHandler(db *gorm.DB, tenants *database.Tenants, cold *services.ColdStorage, pii *services.PIIScrubber) *PIIHandler {
+	return &PIIHandler{db: db, tenants: tenants, cold: cold, pii: pii}
+}
+
+// Erase handles POST /api/v1/admin/pii/erase
+//
+// It redacts an identifier, such as a data subject's email address, across
+// stored events, their cold storage payloads, incidents and their records,
+// in the shared and tenant databases.
+func (h *PIIHandler) Erase(c *gin.Context) {
+	var req ErasureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	by := currentPrincipal(c).Name
+	ctx := database.WithAuditActor(c.Request.Context(), by)
+	result, err := services.EraseIdentifier(ctx, h.db, h.tenants, h.cold, h.pii, req.Identifier)
+	if err != nil {
+		if errors.Is(err, services.ErrErasureTooShort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// The identifier itself stays out of the log
+	log.Printf("PII erasure by %s: %d events and %d cold storage payloads updated", by, result.Updated["events"], result.ColdStorageObjects)
+	c.JSON(http.StatusOK, result)
+}
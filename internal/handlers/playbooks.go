@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// PlaybooksHandler handles playbook endpoints
+type PlaybooksHandler struct {
+	db           *gorm.DB
+	orchestrator *services.Orchestrator
+	outbox       *services.Outbox
+	policy       *services.ExecutionPolicy
+	quotas       *services.ExecutionQuotas
+}
+
+// NewPlaybooksHandler creates a new playbooks handler
+func NewPlaybooksHandler(db *gorm.DB, orchestrator *services.Orchestrator, outbox *services.Outbox, policy *services.ExecutionPolicy, quotas *services.ExecutionQuotas) *PlaybooksHandler {
+	return &PlaybooksHandler{
+		db:           db,
+		orchestrator: orchestrator,
+		outbox:       outbox,
+		policy:       policy,
+		quotas:       quotas,
+	}
+}
+
+// ExecutePlaybookRequest represents the request body for running a playbook
+type ExecutePlaybookRequest struct {
+	Inputs map[string]interface{} `json:"inputs"`
+	// Environment selects one of the playbook's environment profiles;
+	// empty uses PLAYBOOK_ENVIRONMENT
+	Environment string `json:"environment"`
+}
+
+// ListPlaybooks handles GET /api/v1/playbooks
+func (h *PlaybooksHandler) ListPlaybooks(c *gin.Context) {
+	playbooks := h.orchestrator.ListPlaybooks()
+	out := make([]gin.H, 0, len(playbooks))
+	for _, playbook := range playbooks {
+		inputs := make([]gin.H, 0, len(playbook.Playbook.Inputs))
+		for _, input := range playbook.Playbook.Inputs {
+			inputs = append(inputs, gin.H{"name": input.Name, "required": input.Required})
+		}
+		environments := make([]string, 0, len(playbook.Playbook.Environments))
+		for name := range playbook.Playbook.Environments {
+			environments = append(environments, name)
+		}
+		sort.Strings(environments)
+		out = append(out, gin.H{
+			"id":           playbook.Playbook.ID,
+			"name":         playbook.Playbook.Name,
+			"description":  playbook.Playbook.Description,
+			"version":      playbook.Playbook.Version,
+			"inputs":       inputs,
+			"environments": environments,
+			"steps":        len(playbook.Playbook.Steps),
+		})
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// GetQuotas handles GET /api/v1/admin/quotas
+//
+// Reports each execution quota's use over the last hour by every rule and
+// tenant that ran playbooks in it.
+func (h *PlaybooksHandler) GetQuotas(c *gin.Context) {
+	usage, err := h.quotas.Usage()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"window": "1h", "usage": usage})
+}
+
+// ExecutePlaybook handles POST /api/v1/playbooks/:id/execute
+//
+// The run is queued on the outbox and executed by the leader, provided the
+// execution policy lets the caller run the playbook and its actions. With
+// ?dry_run=true the playbook is walked immediately instead: parameters are
+// interpolated and conditions evaluated, but every action is replaced by its
+// step's sample_output and nothing is executed or recorded.
+func (h *PlaybooksHandler) ExecutePlaybook(c *gin.Context) {
+	playbookID := c.Param("id")
+	playbook, ok := h.orchestrator.GetPlaybook(playbookID)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "playbook not found"})
+		return
+	}
+
+	var req ExecutePlaybookRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.Inputs == nil {
+		req.Inputs = make(map[string]interface{})
+	}
+
+	if err := h.orchestrator.ValidateEnvironment(playbookID, req.Environment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.Query("dry_run") == "true" {
+		preview, err := h.orchestrator.PreviewPlaybook(playbookID, req.Environment, req.Inputs)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, preview)
+		return
+	}
+
+	if err := h.policy.AuthorizePlaybook(currentPrincipal(c), playbook); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.orchestrator.ValidateInputs(playbookID, req.Inputs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Manual runs count against the caller's tenant, or its key outside
+	// any tenant
+	tenant := database.TenantFromContext(c.Request.Context())
+	if tenant == "" {
+		tenant = currentPrincipal(c).Name
+	}
+	if err := h.quotas.CheckRun(services.ExecutionScope{Tenant: tenant}); err != nil {
+		var exceeded *services.QuotaExceededError
+		if errors.As(err, &exceeded) {
+			c.Header("Retry-After", strconv.Itoa(int(exceeded.RetryAfter.Round(time.Second).Seconds())))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.outbox.Enqueue(h.db, services.TopicExecutePlaybook, map[string]interface{}{
+		"playbook_id":           playbookID,
+		"inputs":                req.Inputs,
+		"environment":           req.Environment,
+		"tenant":                tenant,
+		"triggered_by":          currentPrincipal(c).Name,
+		services.RequestIDField: c.GetString(requestIDKey),
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue playbook run"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"playbook_id": playbookID,
+		"status":      "queued",
+	})
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// ReprocessHandler requeues events for detection
+type ReprocessHandler struct {
+	reprocessor *services.Reprocessor
+}
+
+// NewReprocessHandler creates a new reprocess handler
+func NewReprocessHandler(reprocessor *services.Reprocessor) *ReprocessHandler {
+	return &ReprocessHandler{reprocessor: reprocessor}
+}
+
+// ReprocessEvents handles POST /api/v1/admin/events/reprocess
+//
+// Runs detection again for the events the body selects: unprocessed events
+// by default, narrowed by from/to and source, or the listed event_ids, in
+// the shared database or the tenant's given by tenant.
+// Detection runs in the background; the response reports how many events
+// were queued.
+func (h *ReprocessHandler) ReprocessEvents(c *gin.Context) {
+	var filter services.ReprocessFilter
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&filter); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	queued, err := h.reprocessor.Reprocess(filter)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidReprocessFilter) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"queued": queued})
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// RequestIDHeader carries a request's correlation ID in and out
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the gin context key holding the request ID
+const requestIDKey = "request_id"
+
+// RequestID accepts the caller's X-Request-ID, or generates one, and returns
+// it in the response. The ID is stored on events, incidents, playbook runs
+// and action logs the request leads to, so one ingest can be traced through
+// asynchronous processing.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := services.NormalizeRequestID(c.GetHeader(RequestIDHeader))
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(services.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// LogFormatter is gin's default access log line with the request ID added
+func LogFormatter(param gin.LogFormatterParams) string {
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency.Truncate(time.Second)
+	}
+	requestID, _ := param.Keys[requestIDKey].(string)
+	return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v | request_id=%s\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		param.StatusCode,
+		param.Latency,
+		param.ClientIP,
+		param.Method,
+		param.Path,
+		requestID,
+		param.ErrorMessage,
+	)
+}
+
+// stampRequestID records the request's ID on events it ingests
+func stampRequestID(c *gin.Context, events ...*models.Event) {
+	id := currentRequestID(c)
+	for _, event := range events {
+		event.RequestID = id
+	}
+}
+
+// currentRequestID returns the request's ID for an optional column
+func currentRequestID(c *gin.Context) *string {
+	if id := c.GetString(requestIDKey); id != "" {
+		return &id
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// RiskHandler handles entity risk score endpoints
+type RiskHandler struct {
+	risk *services.RiskScorer
+}
+
+// NewRiskHandler creates a new risk handler
+func NewRiskHandler(risk *services.RiskScorer) *RiskHandler {
+	return &RiskHandler{risk: risk}
+}
+
+// ListEntities handles GET /api/v1/risk/entities
+//
+// Entities are listed riskiest first by their decayed score; ?type= limits
+// them to user, host or ip, ?min_score= drops lower scores and ?limit= caps
+// the list (default 50).
+func (h *RiskHandler) ListEntities(c *gin.Context) {
+	entityType := c.Query("type")
+	if entityType != "" && !services.RiskEntityType(entityType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be user, host or ip"})
+		return
+	}
+	minScore, err := strconv.ParseFloat(c.DefaultQuery("min_score", "0"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid min_score"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	scores, err := h.risk.List(entityType, minScore, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, scores)
+}
+
+// GetEntity handles GET /api/v1/risk/entities/:type/:value
+func (h *RiskHandler) GetEntity(c *gin.Context) {
+	detail, err := h.risk.Get(c.Param("type"), c.Param("value"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "entity has no risk score"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// ResetEntity handles DELETE /api/v1/risk/entities/:type/:value
+func (h *RiskHandler) ResetEntity(c *gin.Context) {
+	if err := h.risk.Reset(c.Param("type"), c.Param("value")); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "entity has no risk score"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entity_type": c.Param("type"), "entity_value": c.Param("value"), "score": 0})
+}
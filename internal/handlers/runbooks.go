@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// RunbooksHandler handles runbook knowledge base endpoints
+type RunbooksHandler struct {
+	db *gorm.DB
+}
+
+// NewRunbooksHandler creates a new runbooks handler
+func NewRunbooksHandler(db *gorm.DB) *RunbooksHandler {
+	return &RunbooksHandler{db: db}
+}
+
+// RunbookRequest represents the request body for creating or replacing a runbook
+type RunbookRequest struct {
+	Slug     string `json:"slug" binding:"required"`
+	Title    string `json:"title" binding:"required"`
+	Category string `json:"category"`
+	Content  string `json:"content"`
+	URL      string `json:"url"`
+}
+
+// ListRunbooks handles GET /api/v1/runbooks
+func (h *RunbooksHandler) ListRunbooks(c *gin.Context) {
+	var runbooks []models.Runbook
+
+	query := h.db.Order("title ASC")
+
+	// Free-text search over slug, title and content
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		like := "%" + strings.ToLower(q) + "%"
+		query = query.Where("LOWER(slug) LIKE ? OR LOWER(title) LIKE ? OR LOWER(content) LIKE ?", like, like, like)
+	}
+
+	// Filter by category
+	if category := c.Query("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	if err := query.Find(&runbooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch runbooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, runbooks)
+}
+
+// GetRunbook handles GET /api/v1/runbooks/:id (accepts runbook ID or slug)
+func (h *RunbooksHandler) GetRunbook(c *gin.Context) {
+	runbook, ok := h.findRunbook(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, runbook)
+}
+
+// findRunbook loads the runbook named by the :id parameter, a runbook ID or
+// slug, responding 404 or 500 and returning false when it can't
+func (h *RunbooksHandler) findRunbook(c *gin.Context) (*models.Runbook, bool) {
+	id := c.Param("id")
+
+	var runbook models.Runbook
+	if err := h.db.First(&runbook, "runbook_id = ? OR slug = ?", id, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "runbook not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch runbook"})
+		}
+		return nil, false
+	}
+	return &runbook, true
+}
+
+// slugTaken reports whether a runbook other than runbookID uses slug
+func (h *RunbooksHandler) slugTaken(slug, runbookID string) bool {
+	var existing int64
+	h.db.Model(&models.Runbook{}).Where("slug = ? AND runbook_id <> ?", slug, runbookID).Count(&existing)
+	return existing > 0
+}
+
+// bindRunbook reads and validates a runbook request, responding 400 and
+// returning false when it's invalid
+func bindRunbook(c *gin.Context) (*RunbookRequest, bool) {
+	var req RunbookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if req.Content == "" && req.URL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "either content or url is required"})
+		return nil, false
+	}
+	return &req, true
+}
+
+// CreateRunbook handles POST /api/v1/runbooks
+func (h *RunbooksHandler) CreateRunbook(c *gin.Context) {
+	req, ok := bindRunbook(c)
+	if !ok {
+		return
+	}
+
+	if h.slugTaken(req.Slug, "") {
+		c.JSON(http.StatusConflict, gin.H{"error": "a runbook with this slug already exists"})
+		return
+	}
+
+	runbook := &models.Runbook{
+		Slug:     req.Slug,
+		Title:    req.Title,
+		Category: req.Category,
+		Content:  req.Content,
+		URL:      req.URL,
+	}
+
+	if err := h.db.Create(runbook).Error; err != nil {
+		// Lost a race for the slug
+		if h.slugTaken(req.Slug, runbook.RunbookID) {
+			c.JSON(http.StatusConflict, gin.H{"error": "a runbook with this slug already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create runbook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, runbook)
+}
+
+// UpdateRunbook handles PUT /api/v1/runbooks/:id (accepts runbook ID or
+// slug), replacing the runbook
+func (h *RunbooksHandler) UpdateRunbook(c *gin.Context) {
+	runbook, ok := h.findRunbook(c)
+	if !ok {
+		return
+	}
+	req, ok := bindRunbook(c)
+	if !ok {
+		return
+	}
+
+	if h.slugTaken(req.Slug, runbook.RunbookID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "a runbook with this slug already exists"})
+		return
+	}
+
+	runbook.Slug = req.Slug
+	runbook.Title = req.Title
+	runbook.Category = req.Category
+	runbook.Content = req.Content
+	runbook.URL = req.URL
+	if err := h.db.Save(runbook).Error; err != nil {
+		if h.slugTaken(req.Slug, runbook.RunbookID) {
+			c.JSON(http.StatusConflict, gin.H{"error": "a runbook with this slug already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update runbook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, runbook)
+}
+
+// DeleteRunbook handles DELETE /api/v1/runbooks/:id (accepts runbook ID or
+// slug)
+func (h *RunbooksHandler) DeleteRunbook(c *gin.Context) {
+	runbook, ok := h.findRunbook(c)
+	if !ok {
+		return
+	}
+
+	result := h.db.Delete(&models.Runbook{}, "runbook_id = ?", runbook.RunbookID)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete runbook"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "runbook not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// ScenariosHandler handles exercise scenario endpoints
+type ScenariosHandler struct {
+	db     *gorm.DB
+	engine *services.ScenarioEngine
+}
+
+// NewScenariosHandler creates a new scenarios handler
+func NewScenariosHandler(db *gorm.DB, engine *services.ScenarioEngine) *ScenariosHandler {
+	return &ScenariosHandler{
+		db:     db,
+		engine: engine,
+	}
+}
+
+// ListScenarios handles GET /api/v1/scenarios
+func (h *ScenariosHandler) ListScenarios(c *gin.Context) {
+	scenarios := h.engine.List()
+	out := make([]interface{}, 0, len(scenarios))
+	for _, scenario := range scenarios {
+		out = append(out, scenario.Scenario)
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// RunScenario handles POST /api/v1/scenarios/:id/run
+//
+// Events are injected in the background; poll the returned run for progress.
+func (h *ScenariosHandler) RunScenario(c *gin.Context) {
+	if _, ok := h.engine.Get(c.Param("id")); !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "scenario not found"})
+		return
+	}
+
+	run, err := h.engine.Start(c.Param("id"), "manual")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, run)
+}
+
+// ListRuns handles GET /api/v1/scenarios/runs
+func (h *ScenariosHandler) ListRuns(c *gin.Context) {
+	query := h.db.Order("started_at DESC").Limit(100)
+	if scenarioID := c.Query("scenario_id"); scenarioID != "" {
+		query = query.Where("scenario_id = ?", scenarioID)
+	}
+
+	var runs []models.ExerciseRun
+	if err := query.Find(&runs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch exercise runs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// ServicesHandler handles service catalog endpoints
+type ServicesHandler struct {
+	catalog *services.ServiceCatalog
+}
+
+// NewServicesHandler creates a new service catalog handler
+func NewServicesHandler(catalog *services.ServiceCatalog) *ServicesHandler {
+	return &ServicesHandler{catalog: catalog}
+}
+
+// CreateServiceRequest represents the request body for adding a service
+type CreateServiceRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	Description  string   `json:"description"`
+	OwnerTeam    string   `json:"owner_team"`
+	Tier         int      `json:"tier"`
+	Dependencies []string `json:"dependencies"`
+}
+
+// UpdateServiceRequest represents the request body for updating a service
+type UpdateServiceRequest struct {
+	Description  *string   `json:"description"`
+	OwnerTeam    *string   `json:"owner_team"`
+	Tier         *int      `json:"tier"`
+	Dependencies *[]string `json:"dependencies"`
+}
+
+// ListServices handles GET /api/v1/services
+func (h *ServicesHandler) ListServices(c *gin.Context) {
+	list, err := h.catalog.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// GetService handles GET /api/v1/services/:name
+func (h *ServicesHandler) GetService(c *gin.Context) {
+	service, err := h.catalog.Get(c.Param("name"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, service)
+}
+
+// CreateService handles POST /api/v1/services
+func (h *ServicesHandler) CreateService(c *gin.Context) {
+	var req CreateServiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	service := &models.Service{
+		Name:         req.Name,
+		Description:  req.Description,
+		OwnerTeam:    req.OwnerTeam,
+		Tier:         req.Tier,
+		Dependencies: req.Dependencies,
+	}
+	if err := h.catalog.Create(service); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, service)
+}
+
+// UpdateService handles PATCH /api/v1/services/:name
+func (h *ServicesHandler) UpdateService(c *gin.Context) {
+	var req UpdateServiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	service, err := h.catalog.Get(c.Param("name"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	if req.Description != nil {
+		service.Description = *req.Description
+	}
+	if req.OwnerTeam != nil {
+		service.OwnerTeam = *req.OwnerTeam
+	}
+	if req.Tier != nil {
+		service.Tier = *req.Tier
+	}
+	if req.Dependencies != nil {
+		service.Dependencies = *req.Dependencies
+	}
+	if err := h.catalog.Update(service); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, service)
+}
+
+// DeleteService handles DELETE /api/v1/services/:name
+func (h *ServicesHandler) DeleteService(c *gin.Context) {
+	if err := h.catalog.Delete(c.Param("name")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deleted": c.Param("name")})
+}
+
+// respondError maps service catalog errors to status codes
+func (h *ServicesHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrServiceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrServiceExists), errors.Is(err, services.ErrServiceInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidService):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// maxSimulationEvents caps how many events one replay evaluates
+const maxSimulationEvents = 50000
+
+// SimulationHandler handles replay/simulation endpoints
+type SimulationHandler struct {
+	db        *gorm.DB
+	simulator *services.Simulator
+}
+
+// NewSimulationHandler creates a new simulation handler
+func NewSimulationHandler(db *gorm.DB, simulator *services.Simulator) *SimulationHandler {
+	return &SimulationHandler{
+		db:        db,
+		simulator: simulator,
+	}
+}
+
+// ReplayRangeRequest selects stored events to replay
+type ReplayRangeRequest struct {
+	From      time.Time `json:"from" binding:"required"`
+	To        time.Time `json:"to" binding:"required"`
+	EventType string    `json:"event_type"`
+	Source    string    `json:"source"`
+	Label     string    `json:"label"`
+}
+
+// Replay handles POST /api/v1/simulate/replay
+//
+// A JSON body replays stored events from a time range. Any other body (or a
+// multipart "file") is parsed as an upload in the ?format= given (ndjson by
+// default, or journald/auditd) and replayed without being stored. Either
+// way, would-be incidents and actions are recorded on a simulation run and
+// nothing is executed.
+func (h *SimulationHandler) Replay(c *gin.Context) {
+	run := &models.SimulationRun{}
+	var events []*models.Event
+
+	if c.ContentType() == "application/json" {
+		var req ReplayRangeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !req.To.After(req.From) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be after from"})
+			return
+		}
+
+		query := h.db.Where("timestamp >= ? AND timestamp < ?", req.From, req.To).
+			Order("timestamp ASC").
+			Limit(maxSimulationEvents + 1)
+		if req.EventType != "" {
+			query = query.Where("event_type = ?", req.EventType)
+		}
+		if req.Source != "" {
+			query = query.Where("source = ?", req.Source)
+		}
+		if err := query.Find(&events).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch events"})
+			return
+		}
+
+		run.Mode = "range"
+		run.RangeStart = &req.From
+		run.RangeEnd = &req.To
+		run.Label = req.Label
+	} else {
+		format := c.DefaultQuery("format", "ndjson")
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadBytes)
+		body, closeBody, err := uploadBody(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer closeBody()
+
+		uploaded, _, err := parseUpload(body, format, c.DefaultQuery("source", format))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if events, err = simulatedEvents(uploaded); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		run.Mode = "upload"
+		run.Label = c.Query("label")
+	}
+
+	if len(events) > maxSimulationEvents {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("replay is limited to %d events; narrow the range", maxSimulationEvents)})
+		return
+	}
+
+	report, err := h.simulator.Replay(events)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to marshal report"})
+		return
+	}
+
+	now := time.Now().UTC()
+	run.CompletedAt = &now
+	run.EventsReplayed = report.EventsReplayed
+	run.RuleMatches = report.RuleMatches
+	run.IncidentCount = len(report.Incidents)
+	run.ActionCount = report.ActionCount()
+	run.Report = string(reportJSON)
+
+	if err := h.db.Create(run).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save simulation run"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"run": run, "report": report})
+}
+
+// TuneRequest sweeps a rule's count condition over stored events
+type TuneRequest struct {
+	services.TuningRequest
+	From   time.Time `json:"from" binding:"required"`
+	To     time.Time `json:"to" binding:"required"`
+	Source string    `json:"source"`
+}
+
+// Tune handles POST /api/v1/simulate/tune
+//
+// Replays stored events from a time range through one rule for each
+// threshold and time window of its count condition, and returns the matches
+// and incidents each would have produced. Nothing is stored.
+func (h *SimulationHandler) Tune(c *gin.Context) {
+	var req TuneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.To.After(req.From) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be after from"})
+		return
+	}
+	eventTypes, err := h.simulator.TuningEventTypes(req.TuningRequest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := h.db.Where("timestamp >= ? AND timestamp < ?", req.From, req.To).
+		Order("timestamp ASC").
+		Limit(maxSimulationEvents + 1)
+	if len(eventTypes) > 0 {
+		query = query.Where("event_type IN ?", eventTypes)
+	}
+	if req.Source != "" {
+		query = query.Where("source = ?", req.Source)
+	}
+	var events []*models.Event
+	if err := query.Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch events"})
+		return
+	}
+	if len(events) > maxSimulationEvents {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("replay is limited to %d events; narrow the range", maxSimulationEvents)})
+		return
+	}
+
+	report, err := h.simulator.TuneThresholds(events, req.TuningRequest, req.From, req.To)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTuning) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// ListRuns handles GET /api/v1/simulate/runs
+func (h *SimulationHandler) ListRuns(c *gin.Context) {
+	var runs []models.SimulationRun
+	if err := h.db.Omit("report").Order("created_at DESC").Limit(50).Find(&runs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch simulation runs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+// GetRun handles GET /api/v1/simulate/runs/:id
+func (h *SimulationHandler) GetRun(c *gin.Context) {
+	var run models.SimulationRun
+	if err := h.db.First(&run, "run_id = ?", c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "simulation run not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch simulation run"})
+		}
+		return
+	}
+
+	var report services.SimulationReport
+	if err := json.Unmarshal([]byte(run.Report), &report); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse simulation report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"run": run, "report": report})
+}
+
+// simulatedEvents builds unsaved events for an uploaded replay. Events without
+// a timestamp are spaced one millisecond apart in file order.
+func simulatedEvents(uploaded []uploadedEvent) ([]*models.Event, error) {
+	base := time.Now().UTC()
+	events := make([]*models.Event, 0, len(uploaded))
+	for i, item := range uploaded {
+		event, err := newEvent(item.EventRequest)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		event.EventID = uuid.New().String()
+		switch {
+		case !item.timestamp.IsZero():
+			event.Timestamp = item.timestamp
+		case event.OccurredAt != nil:
+			event.Timestamp = event.OccurredAt.UTC()
+		default:
+			event.Timestamp = base.Add(time.Duration(i) * time.Millisecond)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// maxResponderRange caps the time range of a responder metrics report
+const maxResponderRange = 366 * 24 * time.Hour
+
+// StatsHandler serves aggregate statistics with a short-lived cache
+type StatsHandler struct {
+	db       *gorm.DB
+	cacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[int]cachedStats
+}
+
+type cachedStats struct {
+	stats     *Stats
+	expiresAt time.Time
+}
+
+// NewStatsHandler creates a new stats handler
+func NewStatsHandler(db *gorm.DB, cacheTTL time.Duration) *StatsHandler {
+	return &StatsHandler{
+		db:       db,
+		cacheTTL: cacheTTL,
+		cache:    make(map[int]cachedStats),
+	}
+}
+
+// Stats is the response body for GET /api/v1/stats
+type Stats struct {
+	GeneratedAt time.Time `json:"generated_at"`
+
+	// Totals
+	Events    int64 `json:"events"`
+	Incidents int64 `json:"incidents"`
+	Actions   int64 `json:"actions"`
+
+	// Incident breakdowns
+	IncidentsByStatus   map[string]int64 `json:"incidents_by_status"`
+	IncidentsBySeverity map[string]int64 `json:"incidents_by_severity"`
+	IncidentsByCategory map[string]int64 `json:"incidents_by_category"`
+	OpenIncidentAges    []AgeBucket      `json:"open_incident_ages"`
+
+	// Reopens, a signal of incidents closed prematurely
+	Reopens ReopenStats `json:"reopens"`
+
+	// Time series
+	EventsPerHour []HourlyCount `json:"events_per_hour"`
+
+	// Action outcomes per action type
+	ActionSuccess []ActionSuccessRate `json:"action_success"`
+}
+
+// HourlyCount is a single point in an hourly time series
+type HourlyCount struct {
+	Hour  time.Time `json:"hour"`
+	Count int64     `json:"count"`
+}
+
+// ReopenStats counts incidents reopened after being resolved. Rate is the
+// share of incidents ever resolved that were reopened; ByRule breaks the
+// reopened incidents down by the rule that raised them.
+type ReopenStats struct {
+	Incidents int64            `json:"incidents"`
+	Total     int64            `json:"total"`
+	Rate      float64          `json:"rate"`
+	ByRule    map[string]int64 `json:"by_rule"`
+}
+
+// AgeBucket counts unresolved incidents whose age falls within a range
+type AgeBucket struct {
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+}
+
+// ActionSuccessRate summarises action outcomes for one action type
+type ActionSuccessRate struct {
+	ActionType  string  `json:"action_type"`
+	Total       int64   `json:"total"`
+	Completed   int64   `json:"completed"`
+	Failed      int64   `json:"failed"`
+	SuccessRate float64 `json:"success_rate"`
+}
+
+// ageBuckets defines the open-incident age distribution, in ascending order
+var ageBuckets = []struct {
+	label string
+	max   time.Duration
+}{
+	{"<1h", time.Hour},
+	{"1h-4h", 4 * time.Hour},
+	{"4h-24h", 24 * time.Hour},
+	{"1d-7d", 7 * 24 * time.Hour},
+	{">7d", 0},
+}
+
+// GetStats handles GET /api/v1/stats
+func (h *StatsHandler) GetStats(c *gin.Context) {
+	hours := 24
+	if v := c.Query("hours"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > 24*30 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "hours must be between 1 and 720"})
+			return
+		}
+		hours = parsed
+	}
+
+	h.mu.Lock()
+	cached, ok := h.cache[hours]
+	h.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		c.JSON(http.StatusOK, cached.stats)
+		return
+	}
+
+	stats, err := h.computeStats(hours)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute stats"})
+		return
+	}
+
+	h.mu.Lock()
+	h.cache[hours] = cachedStats{stats: stats, expiresAt: time.Now().Add(h.cacheTTL)}
+	h.mu.Unlock()
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetResponderMetrics handles GET /api/v1/stats/responders
+//
+// ?group_by= is assignee (default), team or resolver; ?from= and ?to= are
+// RFC 3339 times bounding the range (default the last 30 days) and
+// ?interval=day|week adds per-period figures to each group.
+func (h *StatsHandler) GetResponderMetrics(c *gin.Context) {
+	to := time.Now().UTC()
+	if v := c.Query("to"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 time"})
+			return
+		}
+		to = parsed.UTC()
+	}
+	from := to.Add(-30 * 24 * time.Hour)
+	if v := c.Query("from"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 time"})
+			return
+		}
+		from = parsed.UTC()
+	}
+	if !from.Before(to) || to.Sub(from) > maxResponderRange {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to and at most 366 days earlier"})
+		return
+	}
+
+	report, err := services.ComputeResponderMetrics(h.db, services.ResponderMetricsQuery{
+		GroupBy:  c.DefaultQuery("group_by", "assignee"),
+		From:     from,
+		To:       to,
+		Interval: c.Query("interval"),
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidResponderGroup) || errors.Is(err, services.ErrInvalidResponderInterval) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute responder metrics"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// computeStats runs the aggregate queries for the stats response
+func (h *StatsHandler) computeStats(hours int) (*Stats, error) {
+	now := time.Now().UTC()
+	stats := &Stats{GeneratedAt: now}
+
+	if err := h.db.Model(&models.Event{}).Count(&stats.Events).Error; err != nil {
+		return nil, err
+	}
+	if err := h.db.Model(&models.Incident{}).Count(&stats.Incidents).Error; err != nil {
+		return nil, err
+	}
+	if err := h.db.Model(&models.ActionLog{}).Count(&stats.Actions).Error; err != nil {
+		return nil, err
+	}
+
+	var err error
+	if stats.IncidentsByStatus, err = h.countIncidentsBy("status"); err != nil {
+		return nil, err
+	}
+	if stats.IncidentsBySeverity, err = h.countIncidentsBy("severity"); err != nil {
+		return nil, err
+	}
+	if stats.IncidentsByCategory, err = h.countIncidentsBy("category"); err != nil {
+		return nil, err
+	}
+
+	if stats.EventsPerHour, err = h.eventsPerHour(now, hours); err != nil {
+		return nil, err
+	}
+	if stats.OpenIncidentAges, err = h.openIncidentAges(now); err != nil {
+		return nil, err
+	}
+	if stats.ActionSuccess, err = h.actionSuccessRates(); err != nil {
+		return nil, err
+	}
+	if stats.Reopens, err = h.reopenStats(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// countIncidentsBy groups incident counts by a fixed column name
+func (h *StatsHandler) countIncidentsBy(column string) (map[string]int64, error) {
+	var rows []struct {
+		Key   string
+		Count int64
+	}
+	if err := h.db.Model(&models.Incident{}).
+		Select(column + " AS key, COUNT(*) AS count").
+		Group(column).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Key] = row.Count
+	}
+	return counts, nil
+}
+
+// eventsPerHour returns a zero-filled hourly series covering the last N hours
+func (h *StatsHandler) eventsPerHour(now time.Time, hours int) ([]HourlyCount, error) {
+	start := now.Truncate(time.Hour).Add(-time.Duration(hours-1) * time.Hour)
+
+	// Counted per UTC hour in the database; strftime normalizes offsets
+	var rows []struct {
+		Hour  string
+		Count int64
+	}
+	if err := h.db.Model(&models.Event{}).
+		Select("strftime('%Y-%m-%dT%H', timestamp) AS hour, COUNT(*) AS count").
+		Where("timestamp >= ?", start).
+		Group("hour").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	series := make([]HourlyCount, hours)
+	for i := range series {
+		series[i].Hour = start.Add(time.Duration(i) * time.Hour)
+	}
+	for _, row := range rows {
+		hour, err := time.Parse("2006-01-02T15", row.Hour)
+		if err != nil {
+			continue
+		}
+		idx := int(hour.Sub(start) / time.Hour)
+		if idx >= 0 && idx < hours {
+			series[idx].Count += row.Count
+		}
+	}
+	return series, nil
+}
+
+// openIncidentAges buckets unresolved incidents by time since creation
+func (h *StatsHandler) openIncidentAges(now time.Time) ([]AgeBucket, error) {
+	var createdAts []time.Time
+	if err := h.db.Model(&models.Incident{}).
+		Where("status <> ?", models.StatusResolved).
+		Pluck("created_at", &createdAts).Error; err != nil {
+		return nil, err
+	}
+
+	buckets := make([]AgeBucket, len(ageBuckets))
+	for i, b := range ageBuckets {
+		buckets[i].Label = b.label
+	}
+	for _, createdAt := range createdAts {
+		age := now.Sub(createdAt)
+		for i, b := range ageBuckets {
+			if b.max == 0 || age < b.max {
+				buckets[i].Count++
+				break
+			}
+		}
+	}
+	return buckets, nil
+}
+
+// reopenStats counts reopened incidents overall and per triggering rule
+func (h *StatsHandler) reopenStats() (ReopenStats, error) {
+	stats := ReopenStats{ByRule: map[string]int64{}}
+	var rows []struct {
+		Rule      string
+		Incidents int64
+		Total     int64
+	}
+	if err := h.db.Model(&models.Incident{}).
+		Select("triggered_by_rule AS rule, COUNT(*) AS incidents, SUM(reopen_count) AS total").
+		Where("reopen_count > 0").
+		Group("triggered_by_rule").
+		Scan(&rows).Error; err != nil {
+		return stats, err
+	}
+	for _, row := range rows {
+		stats.ByRule[row.Rule] = row.Incidents
+		stats.Incidents += row.Incidents
+		stats.Total += row.Total
+	}
+
+	var everResolved int64
+	if err := h.db.Model(&models.Incident{}).
+		Where("status = ? OR reopen_count > 0", models.StatusResolved).
+		Count(&everResolved).Error; err != nil {
+		return stats, err
+	}
+	if everResolved > 0 {
+		stats.Rate = float64(stats.Incidents) / float64(everResolved)
+	}
+	return stats, nil
+}
+
+// actionSuccessRates computes completed/failed ratios per action type
+func (h *StatsHandler) actionSuccessRates() ([]ActionSuccessRate, error) {
+	rows := []ActionSuccessRate{}
+	if err := h.db.Model(&models.ActionLog{}).
+		Select("action_type, COUNT(*) AS total, "+
+			"SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS completed, "+
+			"SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS failed",
+			models.ActionCompleted, models.ActionFailed).
+		Group("action_type").
+		Order("action_type").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		if finished := rows[i].Completed + rows[i].Failed; finished > 0 {
+			rows[i].SuccessRate = float64(rows[i].Completed) / float64(finished)
+		}
+	}
+	return rows, nil
+}
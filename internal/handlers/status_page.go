@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// StatusPageHandler serves a read-only public view of ongoing incidents
+type StatusPageHandler struct {
+	db      *gorm.DB
+	token   string
+	appName string
+}
+
+// NewStatusPageHandler creates a new status page handler
+func NewStatusPageHandler(db *gorm.DB, token, appName string) *StatusPageHandler {
+	return &StatusPageHandler{
+		db:      db,
+		token:   token,
+		appName: appName,
+	}
+}
+
+// StatusPageEntry is the public projection of an incident. It deliberately
+// omits descriptions, notes, rule IDs and related events.
+type StatusPageEntry struct {
+	Title     string    `json:"title"`
+	Status    string    `json:"status"`
+	Severity  string    `json:"severity"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+var statusPageTemplate = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="60">
+<title>{{ .AppName }} - Status</title>
+</head>
+<body>
+<h1>{{ .AppName }} - Current Incidents</h1>
+{{ if .Incidents }}
+<table>
+<tr><th>Title</th><th>Severity</th><th>Status</th><th>Started</th></tr>
+{{ range .Incidents }}<tr><td>{{ .Title }}</td><td>{{ .Severity }}</td><td>{{ .Status }}</td><td>{{ .StartedAt.Format "2006-01-02 15:04 MST" }}</td></tr>
+{{ end }}</table>
+{{ else }}
+<p>No ongoing high or critical incidents.</p>
+{{ end }}
+<p><small>Last updated {{ .GeneratedAt.Format "2006-01-02 15:04:05 MST" }}</small></p>
+</body>
+</html>
+`))
+
+// GetStatusPage handles GET /status
+func (h *StatusPageHandler) GetStatusPage(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or missing status page token"})
+		return
+	}
+
+	var incidents []models.Incident
+	if err := h.db.
+		Where("status <> ? AND exercise = ?", models.StatusResolved, false).
+		Where("severity IN ?", []models.SeverityLevel{models.SeverityHigh, models.SeverityCritical}).
+		Order("created_at DESC").
+		Find(&incidents).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch incidents"})
+		return
+	}
+
+	entries := make([]StatusPageEntry, 0, len(incidents))
+	for _, incident := range incidents {
+		entries = append(entries, StatusPageEntry{
+			Title:     incident.Title,
+			Status:    string(incident.Status),
+			Severity:  string(incident.Severity),
+			StartedAt: incident.CreatedAt,
+		})
+	}
+
+	if c.Query("format") == "json" {
+		c.JSON(http.StatusOK, gin.H{"incidents": entries})
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := statusPageTemplate.Execute(c.Writer, gin.H{
+		"AppName":     h.appName,
+		"Incidents":   entries,
+		"GeneratedAt": time.Now().UTC(),
+	}); err != nil {
+		c.Error(err)
+	}
+}
+
+// authorized checks the token from the query string or a bearer header
+func (h *StatusPageHandler) authorized(c *gin.Context) bool {
+	provided := c.Query("token")
+	if provided == "" {
+		provided = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	}
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(h.token)) == 1
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// SuppressionsHandler handles suppression and maintenance calendar endpoints
+type SuppressionsHandler struct {
+	db       *gorm.DB
+	calendar *services.CalendarSync
+}
+
+// NewSuppressionsHandler creates a new suppressions handler
+func NewSuppressionsHandler(db *gorm.DB, calendar *services.CalendarSync) *SuppressionsHandler {
+	return &SuppressionsHandler{db: db, calendar: calendar}
+}
+
+// SuppressionRequest represents the request body for creating a suppression
+type SuppressionRequest struct {
+	Name     string    `json:"name" binding:"required"`
+	Reason   string    `json:"reason"`
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+	Sources  []string  `json:"sources"`
+	Tags     []string  `json:"tags"`
+}
+
+// ListSuppressions handles GET /api/v1/suppressions
+//
+// Windows that haven't ended are listed unless ?all=true; ?active=true lists
+// only those in effect now.
+func (h *SuppressionsHandler) ListSuppressions(c *gin.Context) {
+	now := time.Now().UTC()
+	query := h.db.Order("starts_at ASC")
+	if c.Query("all") != "true" {
+		query = query.Where("ends_at > ?", now)
+	}
+	if c.Query("active") == "true" {
+		query = query.Where("starts_at <= ? AND ends_at > ?", now, now)
+	}
+	if origin := c.Query("origin"); origin != "" {
+		query = query.Where("origin = ?", origin)
+	}
+
+	suppressions := []models.Suppression{}
+	if err := query.Find(&suppressions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch suppressions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, suppressions)
+}
+
+// CreateSuppression handles POST /api/v1/suppressions
+func (h *SuppressionsHandler) CreateSuppression(c *gin.Context) {
+	var req SuppressionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.EndsAt.After(req.StartsAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ends_at must be after starts_at"})
+		return
+	}
+	if len(req.Sources) == 0 && len(req.Tags) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one of sources or tags is required"})
+		return
+	}
+
+	suppression := models.Suppression{
+		Name:      req.Name,
+		Reason:    req.Reason,
+		StartsAt:  req.StartsAt.UTC(),
+		EndsAt:    req.EndsAt.UTC(),
+		Sources:   req.Sources,
+		Tags:      req.Tags,
+		Origin:    models.SuppressionManual,
+		CreatedBy: currentPrincipal(c).Name,
+	}
+	if err := h.db.Create(&suppression).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create suppression"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, suppression)
+}
+
+// DeleteSuppression handles DELETE /api/v1/suppressions/:id
+//
+// Imported suppressions can't be deleted here; the next sync would restore
+// them, so they are removed from their calendar instead.
+func (h *SuppressionsHandler) DeleteSuppression(c *gin.Context) {
+	var suppression models.Suppression
+	if err := h.db.First(&suppression, "suppression_id = ?", c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "suppression not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch suppression"})
+		return
+	}
+	if suppression.Origin != models.SuppressionManual {
+		c.JSON(http.StatusConflict, gin.H{"error": "suppression was imported from " + suppression.Origin + "; remove the event from the calendar instead"})
+		return
+	}
+
+	if err := h.db.Delete(&suppression).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete suppression"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "suppression deleted", "suppression_id": suppression.SuppressionID})
+}
+
+// SyncCalendars handles POST /api/v1/admin/maintenance-calendars/sync
+func (h *SuppressionsHandler) SyncCalendars(c *gin.Context) {
+	results := h.calendar.SyncAll(c.Request.Context())
+	code := http.StatusOK
+	for _, result := range results {
+		if result.Error != "" {
+			code = http.StatusBadGateway
+		}
+	}
+	c.JSON(code, gin.H{"calendars": results})
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// TelephonyHandler handles telephony provider callbacks
+type TelephonyHandler struct {
+	db *gorm.DB
+
+	mu           sync.RWMutex
+	authToken    string
+	publicAPIURL string
+}
+
+// NewTelephonyHandler creates a new telephony handler; callbacks are
+// rejected unless a Twilio auth token is configured to verify them
+func NewTelephonyHandler(db *gorm.DB, authToken, publicAPIURL string) *TelephonyHandler {
+	h := &TelephonyHandler{db: db}
+	h.SetCredentials(authToken, publicAPIURL)
+	return h
+}
+
+// SetCredentials changes the auth token and public URL used to verify
+// callbacks
+func (h *TelephonyHandler) SetCredentials(authToken, publicAPIURL string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.authToken = authToken
+	h.publicAPIURL = strings.TrimRight(publicAPIURL, "/")
+}
+
+// TwilioGather handles POST /api/v1/telephony/twilio/gather
+//
+// Twilio posts the keypress from a voice_call escalation here; pressing 1
+// acknowledges the incident named in the incident_id query parameter.
+func (h *TelephonyHandler) TwilioGather(c *gin.Context) {
+	h.mu.RLock()
+	authToken, publicAPIURL := h.authToken, h.publicAPIURL
+	h.mu.RUnlock()
+
+	if authToken == "" || publicAPIURL == "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "telephony callbacks are not configured"})
+		return
+	}
+
+	if err := c.Request.ParseForm(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid form body"})
+		return
+	}
+
+	// Twilio signs the exact URL it was given, built from PUBLIC_API_URL
+	fullURL := publicAPIURL + "/telephony/twilio/gather"
+	if c.Request.URL.RawQuery != "" {
+		fullURL += "?" + c.Request.URL.RawQuery
+	}
+	if !services.ValidateTwilioSignature(authToken, fullURL, c.Request.PostForm, c.GetHeader("X-Twilio-Signature")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "invalid signature"})
+		return
+	}
+
+	incidentID := c.Query("incident_id")
+	if c.Request.PostForm.Get("Digits") != "1" || incidentID == "" {
+		twiml(c, "Incident not acknowledged. Goodbye.")
+		return
+	}
+
+	caller := c.Request.PostForm.Get("To")
+	if _, err := services.AcknowledgeIncident(h.db, incidentID, "phone:"+caller); err != nil {
+		log.Printf("Failed to acknowledge incident %s by phone: %v", incidentID, err)
+		twiml(c, "The incident could not be acknowledged. Please use the console.")
+		return
+	}
+
+	log.Printf("Incident %s acknowledged by phone (%s)", incidentID, caller)
+	twiml(c, "Incident acknowledged. Thank you.")
+}
+
+// twiml responds with a TwiML document that says message
+func twiml(c *gin.Context, message string) {
+	c.Data(http.StatusOK, "application/xml",
+		[]byte(`<?xml version="1.0" encoding="UTF-8"?><Response><Say>`+message+`</Say></Response>`))
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// RouteTenant sets the tenant the caller belongs to, if any, on the request
+// context, so the database layer resolves the tenant's database for it. It
+// must run after Authenticate.
+func RouteTenant(tenants *database.Tenants) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tenant := tenants.Tenant(currentPrincipal(c).Name); tenant != "" {
+			c.Request = c.Request.WithContext(database.WithTenant(c.Request.Context(), tenant))
+		}
+		c.Next()
+	}
+}
+
+// stampTenant marks events for storage in the caller's tenant database
+func stampTenant(c *gin.Context, events ...*models.Event) {
+	tenant := database.TenantFromContext(c.Request.Context())
+	for _, event := range events {
+		event.Tenant = tenant
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/services"
+)
+
+// WatchersHandler handles incident watch and per-user subscription endpoints
+type WatchersHandler struct {
+	db *gorm.DB
+}
+
+// NewWatchersHandler creates a new watchers handler
+func NewWatchersHandler(db *gorm.DB) *WatchersHandler {
+	return &WatchersHandler{db: db}
+}
+
+// WatchedIncident is an incident the caller watches
+type WatchedIncident struct {
+	models.Incident
+	WatchReason string `json:"watch_reason"`
+}
+
+// NotificationPreferenceRequest represents the request body for setting the
+// caller's notification target
+type NotificationPreferenceRequest struct {
+	NotifyTarget string `json:"notify_target" binding:"required"`
+}
+
+// Watch handles POST /api/v1/incidents/:id/watch
+func (h *WatchersHandler) Watch(c *gin.Context) {
+	incidentID := c.Param("id")
+	if !h.incidentExists(c, incidentID) {
+		return
+	}
+
+	user := currentPrincipal(c).Name
+	if err := services.WatchIncident(h.db, incidentID, user, models.WatchManual); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"incident_id": incidentID, "user": user, "watching": true})
+}
+
+// Unwatch handles DELETE /api/v1/incidents/:id/watch
+func (h *WatchersHandler) Unwatch(c *gin.Context) {
+	incidentID := c.Param("id")
+	user := currentPrincipal(c).Name
+	if err := services.UnwatchIncident(h.db, incidentID, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"incident_id": incidentID, "user": user, "watching": false})
+}
+
+// ListWatchers handles GET /api/v1/incidents/:id/watchers
+func (h *WatchersHandler) ListWatchers(c *gin.Context) {
+	var watchers []models.IncidentWatcher
+	if err := h.db.Where("incident_id = ?", c.Param("id")).Order("created_at ASC").Find(&watchers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch watchers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, watchers)
+}
+
+// ListWatched handles GET /api/v1/me/watched
+//
+// Unresolved incidents are listed unless ?all=true.
+func (h *WatchersHandler) ListWatched(c *gin.Context) {
+	query := h.db.Table("incidents").
+		Select("incidents.*, incident_watchers.reason AS watch_reason").
+		Joins("JOIN incident_watchers ON incident_watchers.incident_id = incidents.incident_id").
+		Where("incident_watchers.user = ?", currentPrincipal(c).Name).
+		Order("incidents.updated_at DESC")
+	if c.Query("all") != "true" {
+		query = query.Where("incidents.status <> ?", models.StatusResolved)
+	}
+
+	watched := []WatchedIncident{}
+	if err := query.Scan(&watched).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch watched incidents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, watched)
+}
+
+// GetNotificationPreference handles GET /api/v1/me/notifications
+func (h *WatchersHandler) GetNotificationPreference(c *gin.Context) {
+	user := currentPrincipal(c).Name
+	pref := models.UserPreference{User: user}
+	if err := h.db.Where("user = ?", user).Limit(1).Find(&pref).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch notification preference"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pref)
+}
+
+// SetNotificationPreference handles PUT /api/v1/me/notifications
+func (h *WatchersHandler) SetNotificationPreference(c *gin.Context) {
+	var req NotificationPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pref := models.UserPreference{
+		User:         currentPrincipal(c).Name,
+		NotifyTarget: strings.TrimSpace(req.NotifyTarget),
+	}
+	if err := h.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user"}},
+		DoUpdates: clause.AssignmentColumns([]string{"notify_target", "updated_at"}),
+	}).Create(&pref).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save notification preference"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pref)
+}
+
+func (h *WatchersHandler) incidentExists(c *gin.Context, incidentID string) bool {
+	var incident models.Incident
+	if err := h.db.Select("incident_id").First(&incident, "incident_id = ?", incidentID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "incident not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch incident"})
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ApprovalStatus represents the state of an approval gate
+type ApprovalStatus string
+
+const (
+	ApprovalPending  ApprovalStatus = "pending"
+	ApprovalApproved ApprovalStatus = "approved"
+	ApprovalRejected ApprovalStatus = "rejected"
+)
+
+// Approval decisions
+const (
+	DecisionApprove = "approve"
+	DecisionReject  = "reject"
+)
+
+// ActionApproval is an approval gate holding back a playbook action until
+// RequiredApprovals distinct responders approve it, which executes the
+// action, or one rejects it
+type ActionApproval struct {
+	ApprovalID string    `gorm:"primaryKey;type:varchar(36)" json:"approval_id"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Status ApprovalStatus `gorm:"index;type:varchar(20);not null" json:"status"`
+	// Reason explains why the action needs approval, e.g. the change freeze
+	// in effect
+	Reason   string  `gorm:"type:text" json:"reason"`
+	FreezeID *string `gorm:"type:varchar(36)" json:"freeze_id,omitempty"`
+
+	RequiredApprovals int `gorm:"not null;default:1" json:"required_approvals"`
+	// RequestedBy is the API key principal that started the run, who may
+	// not approve its actions; empty for rule-triggered runs
+	RequestedBy *string `gorm:"type:varchar(255)" json:"requested_by,omitempty"`
+	// Decisions are the approvals and rejection recorded so far
+	Decisions []ApprovalDecision `gorm:"foreignKey:ApprovalID" json:"decisions"`
+
+	// The held action and the playbook step it was held for
+	ActionType  string  `gorm:"type:varchar(100);not null" json:"action_type"`
+	Parameters  string  `gorm:"type:text" json:"parameters"` // JSON parameters
+	PlaybookID  string  `gorm:"type:varchar(100)" json:"playbook_id"`
+	RunID       string  `gorm:"index;type:varchar(36)" json:"run_id"`
+	StepID      string  `gorm:"type:varchar(100)" json:"step_id"`
+	IncidentID  *string `gorm:"index;type:varchar(36)" json:"incident_id"`
+	Environment string  `gorm:"type:varchar(100)" json:"environment,omitempty"`
+	RuleID      *string `gorm:"type:varchar(100)" json:"rule_id,omitempty"`
+	Tenant      *string `gorm:"type:varchar(100)" json:"tenant,omitempty"`
+
+	// HeldActionID is the action log recording the hold; ActionID the log
+	// of the execution once approved
+	HeldActionID string  `gorm:"type:varchar(36)" json:"held_action_id"`
+	ActionID     *string `gorm:"type:varchar(36)" json:"action_id"`
+
+	// DecidedBy made the final approval or the rejection
+	DecidedBy *string    `gorm:"type:varchar(255)" json:"decided_by"`
+	DecidedAt *time.Time `json:"decided_at"`
+	Note      string     `gorm:"type:text" json:"note"`
+	Error     *string    `gorm:"type:text" json:"error"` // execution error once approved
+}
+
+// BeforeCreate hook to generate UUID and set defaults
+func (a *ActionApproval) BeforeCreate(tx *gorm.DB) error {
+	if a.ApprovalID == "" {
+		a.ApprovalID = uuid.New().String()
+	}
+	if a.Status == "" {
+		a.Status = ApprovalPending
+	}
+	return nil
+}
+
+// TableName specifies the table name for ActionApproval
+func (ActionApproval) TableName() string {
+	return "action_approvals"
+}
+
+// ApprovalDecision is one responder's approval or rejection of an action
+// approval; each responder decides an approval at most once
+type ApprovalDecision struct {
+	DecisionID string    `gorm:"primaryKey;type:varchar(36)" json:"decision_id"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	ApprovalID string `gorm:"type:varchar(36);not null;uniqueIndex:idx_approval_decider" json:"approval_id"`
+	Actor      string `gorm:"type:varchar(255);not null;uniqueIndex:idx_approval_decider" json:"actor"`
+	Decision   string `gorm:"type:varchar(20);not null" json:"decision"`
+	Note       string `gorm:"type:text" json:"note"`
+}
+
+// BeforeCreate hook to generate UUID
+func (d *ApprovalDecision) BeforeCreate(tx *gorm.DB) error {
+	if d.DecisionID == "" {
+		d.DecisionID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for ApprovalDecision
+func (ApprovalDecision) TableName() string {
+	return "approval_decisions"
+}
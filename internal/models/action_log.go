@@ -14,6 +14,10 @@ const (
 	ActionRunning   ActionStatus = "running"
 	ActionCompleted ActionStatus = "completed"
 	ActionFailed    ActionStatus = "failed"
+	// ActionDryRun actions were logged but not executed
+	ActionDryRun ActionStatus = "dry_run"
+	// ActionAwaitingApproval actions are held by an approval gate
+	ActionAwaitingApproval ActionStatus = "awaiting_approval"
 )
 
 // ActionLog represents a log entry for executed actions
@@ -27,9 +31,11 @@ type ActionLog struct {
 	Status     ActionStatus `gorm:"type:varchar(20);not null" json:"status"`
 
 	// Context
-	IncidentID  *string `gorm:"type:varchar(36)" json:"incident_id"`
+	IncidentID  *string `gorm:"index;type:varchar(36)" json:"incident_id"`
 	PlaybookID  *string `gorm:"type:varchar(100)" json:"playbook_id"`
 	StepID      *string `gorm:"type:varchar(100)" json:"step_id"`
+	RunID       *string `gorm:"index;type:varchar(36)" json:"run_id,omitempty"`
+	RequestID   *string `gorm:"index;type:varchar(128)" json:"request_id,omitempty"`
 
 	// Execution details
 	Parameters string  `gorm:"type:text" json:"parameters"` // JSON parameters
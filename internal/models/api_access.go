@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// API access results
+const (
+	AccessSuccess = "success"
+	AccessDenied  = "denied" // rejected by authentication or authorization
+	AccessError   = "error"
+)
+
+// APIAccess records one API request: who made it, what it addressed and how
+// it ended
+type APIAccess struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Timestamp time.Time `gorm:"index;not null" json:"timestamp"`
+	RequestID string    `gorm:"type:varchar(128)" json:"request_id"`
+
+	Actor    string `gorm:"index;type:varchar(255)" json:"actor"` // empty when authentication failed
+	Role     string `gorm:"type:varchar(20)" json:"role"`
+	ClientIP string `gorm:"type:varchar(64)" json:"client_ip"`
+
+	Method string `gorm:"type:varchar(10);not null" json:"method"`
+	Route  string `gorm:"index;type:varchar(255)" json:"route"` // e.g. /api/v1/incidents/:id
+	Path   string `gorm:"type:text" json:"path"`
+	// ResourceIDs are the route's path parameters, e.g. {"id": "<incident ID>"}
+	ResourceIDs map[string]string `gorm:"serializer:json" json:"resource_ids"`
+
+	Status     int    `gorm:"not null" json:"status"`
+	Result     string `gorm:"index;type:varchar(20);not null" json:"result"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// TableName specifies the table name for APIAccess
+func (APIAccess) TableName() string {
+	return "api_access_log"
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// Audit operations
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditEntry is one append-only audit log record. Hash covers the entry's
+// fields and PrevHash, chaining every entry to the one before it so that
+// editing or removing a record breaks verification.
+type AuditEntry struct {
+	Sequence  uint64    `gorm:"primaryKey;autoIncrement:false" json:"sequence"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+
+	Operation  string `gorm:"type:varchar(20);not null" json:"operation"`
+	EntityType string `gorm:"index:idx_audit_entity;type:varchar(50);not null" json:"entity_type"`
+	EntityID   string `gorm:"index:idx_audit_entity;type:varchar(100)" json:"entity_id"`
+	Actor      string `gorm:"type:varchar(255);not null" json:"actor"`
+	Data       string `gorm:"type:text" json:"data"` // JSON of the written row or changes
+
+	PrevHash string `gorm:"type:varchar(64)" json:"prev_hash"`
+	Hash     string `gorm:"type:varchar(64);not null" json:"hash"`
+}
+
+// TableName specifies the table name for AuditEntry
+func (AuditEntry) TableName() string {
+	return "audit_log"
+}
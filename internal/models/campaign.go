@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Campaign groups related incidents, such as a sustained attack from one
+// source, into a parent case. Its status and severity are derived from its
+// incidents.
+type Campaign struct {
+	CampaignID string    `gorm:"primaryKey;type:varchar(36)" json:"campaign_id"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Title       string `gorm:"type:varchar(500);not null" json:"title"`
+	Description string `gorm:"type:text" json:"description"`
+
+	// GroupKey is what automatically grouped incidents share, e.g.
+	// "source_ip:203.0.113.7", "user:alice" or "rule:auth-001"; empty for
+	// campaigns created by hand
+	GroupKey string `gorm:"index;type:varchar(255)" json:"group_key,omitempty"`
+	Auto     bool   `gorm:"not null;default:false" json:"auto"`
+	Exercise bool   `gorm:"index;not null;default:false" json:"exercise"`
+}
+
+// BeforeCreate hook to generate UUID
+func (c *Campaign) BeforeCreate(tx *gorm.DB) error {
+	if c.CampaignID == "" {
+		c.CampaignID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for Campaign
+func (Campaign) TableName() string {
+	return "campaigns"
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ChangeFreeze is a declared window, such as a release or holiday freeze,
+// during which auto-remediation actions wait for approval instead of
+// running, and notifications call out the freeze
+type ChangeFreeze struct {
+	FreezeID  string    `gorm:"primaryKey;type:varchar(36)" json:"freeze_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
+	Reason    string    `gorm:"type:text" json:"reason"`
+	StartsAt  time.Time `gorm:"index;not null" json:"starts_at"`
+	EndsAt    time.Time `gorm:"index;not null" json:"ends_at"`
+	CreatedBy string    `gorm:"type:varchar(255)" json:"created_by"`
+}
+
+// BeforeCreate hook to generate UUID
+func (f *ChangeFreeze) BeforeCreate(tx *gorm.DB) error {
+	if f.FreezeID == "" {
+		f.FreezeID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for ChangeFreeze
+func (ChangeFreeze) TableName() string {
+	return "change_freezes"
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// ContentPack records an installed content pack: a versioned set of rules,
+// playbooks and watchlists for a use case. The ID lists are the content the
+// pack owns, which upgrades replace and uninstalling removes.
+type ContentPack struct {
+	Name        string    `gorm:"primaryKey;type:varchar(100)" json:"name"`
+	Version     string    `gorm:"type:varchar(50);not null" json:"version"`
+	Description string    `gorm:"type:text" json:"description"`
+	InstalledAt time.Time `gorm:"autoCreateTime" json:"installed_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	InstalledBy string    `gorm:"type:varchar(255)" json:"installed_by"`
+
+	// Source is the local directory or registry URL the pack came from, and
+	// Checksum the SHA-256 of what was installed
+	Source   string `gorm:"type:text" json:"source"`
+	Checksum string `gorm:"type:varchar(64)" json:"checksum"`
+
+	// Requires maps required pack names to version constraints
+	Requires map[string]string `gorm:"serializer:json" json:"requires"`
+
+	Rules      []string `gorm:"serializer:json" json:"rules"`
+	Playbooks  []string `gorm:"serializer:json" json:"playbooks"`
+	Watchlists []string `gorm:"serializer:json" json:"watchlists"`
+}
+
+// TableName specifies the table name for ContentPack
+func (ContentPack) TableName() string {
+	return "content_packs"
+}
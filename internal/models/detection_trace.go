@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// DetectionTrace records one evaluation of an event by detection: the rules
+// it was matched against, how each of their conditions turned out and what
+// the matches led to. An event reprocessed has one trace per evaluation.
+type DetectionTrace struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	EventID     string    `gorm:"index;type:varchar(36);not null" json:"event_id"`
+	EvaluatedAt time.Time `gorm:"index;not null" json:"evaluated_at"`
+	// Trace is the evaluation as JSON (services.EventTrace)
+	Trace string `gorm:"type:text;not null" json:"-"`
+}
+
+// TableName specifies the table name for DetectionTrace
+func (DetectionTrace) TableName() string {
+	return "detection_traces"
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EntityRisk is the accumulated risk score of a user, host or IP address.
+// Score is as of ScoredAt and decays from there; see services.RiskScorer.
+type EntityRisk struct {
+	EntityType  string    `gorm:"primaryKey;type:varchar(20)" json:"entity_type"` // user, host or ip
+	EntityValue string    `gorm:"primaryKey;type:varchar(255)" json:"entity_value"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Score      float64   `gorm:"not null;default:0" json:"score"`
+	ScoredAt   time.Time `gorm:"index;not null" json:"scored_at"`
+	LastRuleID string    `gorm:"type:varchar(100)" json:"last_rule_id"`
+
+	// ExceededAt is when the score last crossed the risk threshold; cleared
+	// once it decays back below
+	ExceededAt *time.Time `json:"exceeded_at"`
+}
+
+// TableName specifies the table name for EntityRisk
+func (EntityRisk) TableName() string {
+	return "entity_risks"
+}
+
+// RiskContribution records the points a rule match added to an entity
+type RiskContribution struct {
+	ID        string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	EntityType  string  `gorm:"index:idx_risk_contribution_entity;type:varchar(20);not null" json:"entity_type"`
+	EntityValue string  `gorm:"index:idx_risk_contribution_entity;type:varchar(255);not null" json:"entity_value"`
+	RuleID      string  `gorm:"type:varchar(100);not null" json:"rule_id"`
+	EventID     string  `gorm:"type:varchar(36)" json:"event_id"`
+	Points      float64 `gorm:"not null" json:"points"`
+}
+
+// BeforeCreate hook to generate UUID
+func (r *RiskContribution) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for RiskContribution
+func (RiskContribution) TableName() string {
+	return "risk_contributions"
+}
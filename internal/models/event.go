@@ -17,23 +17,99 @@ const (
 	SeverityCritical SeverityLevel = "critical"
 )
 
+// Rank orders severities from info (0) to critical (4); unknown values rank -1
+func (s SeverityLevel) Rank() int {
+	switch s {
+	case SeverityInfo:
+		return 0
+	case SeverityLow:
+		return 1
+	case SeverityMedium:
+		return 2
+	case SeverityHigh:
+		return 3
+	case SeverityCritical:
+		return 4
+	default:
+		return -1
+	}
+}
+
+// ClockSkew flags an event whose occurred_at is implausibly far from when it
+// was received
+type ClockSkew string
+
+const (
+	ClockSkewFuture ClockSkew = "future"
+	ClockSkewPast   ClockSkew = "past"
+)
+
 // Event represents a security event in the system
 type Event struct {
-	EventID  string        `gorm:"primaryKey;type:varchar(36)" json:"event_id"`
-	Timestamp time.Time    `gorm:"index;not null" json:"timestamp"`
+	EventID  string        `gorm:"primaryKey;type:varchar(36);index:idx_events_keyset,priority:2" json:"event_id"`
+	Timestamp time.Time    `gorm:"index;index:idx_events_keyset,priority:1;not null" json:"timestamp"` // (timestamp, event_id) orders and pages event lists
+
+	// OccurredAt is the client-supplied event time and ReceivedAt when the
+	// service accepted the event (unset on events stored before it was
+	// recorded). Timestamp, which correlation windows use, is OccurredAt
+	// unless ClockSkew flags it as too far from ReceivedAt.
+	OccurredAt *time.Time `gorm:"index" json:"occurred_at,omitempty"`
+	ReceivedAt *time.Time `gorm:"index" json:"received_at"`
+	ClockSkew  ClockSkew  `gorm:"index;type:varchar(10)" json:"clock_skew,omitempty"`
 
 	// Event metadata
 	Source    string        `gorm:"index;type:varchar(255);not null" json:"source"`
 	EventType string        `gorm:"index;type:varchar(100);not null" json:"event_type"`
 	Severity  SeverityLevel `gorm:"index;type:varchar(20);not null" json:"severity"`
 
-	// Event data (stored as JSON in SQLite)
-	RawData    string `gorm:"type:text" json:"raw_data"`
+	// RequestID is the ingest request the event arrived in
+	RequestID *string `gorm:"index;type:varchar(128)" json:"request_id,omitempty"`
+
+	// Tenant names the tenant database the event is stored in, empty for
+	// the shared database. It isn't stored; the database is the tenant.
+	Tenant string `gorm:"-" json:"-"`
+
+	// Event data (stored as JSON in SQLite; see payload.go for compression)
+	RawData    string `gorm:"type:text;serializer:payload" json:"raw_data"`
 	Normalized string `gorm:"type:text;not null" json:"normalized"`
 
+	// RawDataRef is the object store key of a raw payload moved to cold
+	// storage; RawData is then empty and fetched on demand
+	RawDataRef *string `gorm:"type:varchar(255)" json:"raw_data_ref,omitempty"`
 	// Timestamps
 	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
-	ProcessedAt *time.Time `json:"processed_at"`
+	ProcessedAt *time.Time `gorm:"index" json:"processed_at"`
+	// ReprocessCount is how many times detection was requeued for the event
+	ReprocessCount int `gorm:"not null;default:0" json:"reprocess_count,omitempty"`
+
+	// Generated columns extracted from Normalized (maintained by the database layer)
+	SrcIP *string `gorm:"column:src_ip;->;-:migration" json:"src_ip,omitempty"`
+	User  *string `gorm:"column:user_name;->;-:migration" json:"user,omitempty"`
+}
+
+// EventSummary is the list representation of an event, without payload blobs
+type EventSummary struct {
+	EventID     string        `json:"event_id"`
+	Timestamp   time.Time     `json:"timestamp"`
+	Source      string        `json:"source"`
+	EventType   string        `json:"event_type"`
+	Severity    SeverityLevel `json:"severity"`
+	OccurredAt  *time.Time    `json:"occurred_at,omitempty"`
+	ReceivedAt  *time.Time    `json:"received_at"`
+	ClockSkew   ClockSkew     `json:"clock_skew,omitempty"`
+	RequestID   *string       `json:"request_id,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	ProcessedAt *time.Time    `json:"processed_at"`
+	SrcIP       *string       `gorm:"column:src_ip" json:"src_ip,omitempty"`
+	User        *string       `gorm:"column:user_name" json:"user,omitempty"`
+	Service     *string       `gorm:"column:service_name" json:"service,omitempty"`
+}
+
+// EventSummaryColumns are the columns selected for EventSummary
+var EventSummaryColumns = []string{
+	"event_id", "timestamp", "occurred_at", "received_at", "clock_skew",
+	"source", "event_type", "severity", "request_id",
+	"created_at", "processed_at", "src_ip", "user_name", "service_name",
 }
 
 // BeforeCreate hook to generate UUID
@@ -41,8 +117,12 @@ func (e *Event) BeforeCreate(tx *gorm.DB) error {
 	if e.EventID == "" {
 		e.EventID = uuid.New().String()
 	}
+	if e.ReceivedAt == nil {
+		now := time.Now().UTC()
+		e.ReceivedAt = &now
+	}
 	if e.Timestamp.IsZero() {
-		e.Timestamp = time.Now().UTC()
+		e.Timestamp = *e.ReceivedAt
 	}
 	return nil
 }
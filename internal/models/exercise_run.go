@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExerciseRun records one injection of a scenario's synthetic events
+type ExerciseRun struct {
+	RunID       string     `gorm:"primaryKey;type:varchar(36)" json:"run_id"`
+	StartedAt   time.Time  `gorm:"autoCreateTime" json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+
+	ScenarioID     string    `gorm:"index;type:varchar(100);not null" json:"scenario_id"`
+	Trigger        string    `gorm:"type:varchar(20);not null" json:"trigger"` // manual, schedule
+	Status         RunStatus `gorm:"type:varchar(20);not null" json:"status"`
+	EventsInjected int       `json:"events_injected"`
+	Error          *string   `gorm:"type:text" json:"error"`
+}
+
+// BeforeCreate hook to generate UUID and set defaults
+func (r *ExerciseRun) BeforeCreate(tx *gorm.DB) error {
+	if r.RunID == "" {
+		r.RunID = uuid.New().String()
+	}
+	if r.Status == "" {
+		r.Status = RunRunning
+	}
+	return nil
+}
+
+// TableName specifies the table name for ExerciseRun
+func (ExerciseRun) TableName() string {
+	return "exercise_runs"
+}
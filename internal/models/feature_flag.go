@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// FeatureFlag is a runtime override of a feature flag's configured value.
+// Overrides live in the database so a toggle reaches every instance at once.
+type FeatureFlag struct {
+	Name      string    `gorm:"primaryKey;type:varchar(100)" json:"name"`
+	Enabled   bool      `gorm:"not null" json:"enabled"`
+	Reason    string    `gorm:"type:text" json:"reason,omitempty"`
+	UpdatedBy string    `gorm:"type:varchar(255)" json:"updated_by,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name for FeatureFlag
+func (FeatureFlag) TableName() string {
+	return "feature_flags"
+}
@@ -19,28 +19,78 @@ const (
 // Incident represents a security incident
 type Incident struct {
 	IncidentID string         `gorm:"primaryKey;type:varchar(36)" json:"incident_id"`
-	CreatedAt  time.Time      `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt  time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	CreatedAt  time.Time      `gorm:"index;autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time      `gorm:"index;autoUpdateTime" json:"updated_at"`
 
 	// Incident details
 	Status      IncidentStatus `gorm:"index;type:varchar(20);not null" json:"status"`
 	Severity    SeverityLevel  `gorm:"index;type:varchar(20);not null" json:"severity"`
-	Category    string         `gorm:"type:varchar(100)" json:"category"`
+	Impact      int            `gorm:"not null;default:0" json:"impact,omitempty"`  // severity matrix inputs the severity was
+	Urgency     int            `gorm:"not null;default:0" json:"urgency,omitempty"` // derived from; 0 when given directly
+	Category    string         `gorm:"index;type:varchar(100)" json:"category"`
 	Title       string         `gorm:"type:varchar(500);not null" json:"title"`
 	Description string         `gorm:"type:text" json:"description"`
 
 	// Relationships
-	TriggeredByRule string `gorm:"type:varchar(100)" json:"triggered_by_rule"`
-	RelatedEvents   string `gorm:"type:text" json:"related_events"` // JSON array of event IDs
-	ActionsTaken    string `gorm:"type:text" json:"actions_taken"`  // JSON array of action IDs
+	TriggeredByRule string  `gorm:"index;type:varchar(100)" json:"triggered_by_rule"`
+	RelatedEvents   string  `gorm:"type:text" json:"related_events"` // JSON array of event IDs
+	ActionsTaken    []IncidentAction `gorm:"foreignKey:IncidentID;references:IncidentID" json:"actions_taken,omitempty"` // steps taken by playbook runs
+	RunbookID       *string `gorm:"type:varchar(36)" json:"runbook_id"`
+	CorrelationKey  string  `gorm:"index;type:varchar(255)" json:"correlation_key"` // rule ID + grouping value, used for dedup
+	RequestID       *string `gorm:"index;type:varchar(128)" json:"request_id,omitempty"` // request that created the incident
+	CampaignID      *string `gorm:"index;type:varchar(36)" json:"campaign_id,omitempty"`  // parent campaign, if grouped
+	Services        string  `gorm:"type:text" json:"services"` // JSON array of impacted service names
 
 	// Assignment
-	AssignedTo *string `gorm:"type:varchar(255)" json:"assigned_to"`
+	AssignedTo *string `gorm:"index;type:varchar(255)" json:"assigned_to"`
+	Team       string  `gorm:"index;type:varchar(100)" json:"team"`
+	Tags       string  `gorm:"type:text" json:"tags"` // JSON array of tags
+
+	// Acknowledgement by a responder (API or phone keypress)
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	AcknowledgedBy *string    `gorm:"type:varchar(255)" json:"acknowledged_by"`
+
+	// SLA deadlines, set from the tier of the most critical impacted service
+	AcknowledgeBy *time.Time `json:"acknowledge_by"`
+	ResolveBy     *time.Time `gorm:"index" json:"resolve_by"`
+
+	// Stale policy: when the pending auto-close warning was sent, and when an
+	// idle incident was flagged (STALE_INCIDENT_ACTION=flag)
+	StaleWarnedAt  *time.Time `json:"stale_warned_at"`
+	StaleFlaggedAt *time.Time `gorm:"index" json:"stale_flagged_at"`
+
+	// Resolution attribution, recorded by SetStatus: when and by whom the
+	// incident was last resolved, and how often it was reopened after that
+	ResolvedAt  *time.Time `gorm:"index" json:"resolved_at"`
+	ResolvedBy  *string    `gorm:"index;type:varchar(255)" json:"resolved_by"`
+	Resolution  string     `gorm:"type:varchar(100)" json:"resolution"` // classification, e.g. true_positive or false_positive
+	ReopenCount int        `gorm:"not null;default:0" json:"reopen_count"`
+
+	// Exercise marks incidents raised by synthetic scenario events
+	Exercise bool `gorm:"index;not null;default:false" json:"exercise"`
+
+	// WarRooms are the chat channels and meeting bridges opened for the
+	// incident by the open_war_room action
+	WarRooms []WarRoomLink `gorm:"serializer:json" json:"war_rooms,omitempty"`
+
+	// Stakeholder updates: when the last one was sent, and when the
+	// commander was last reminded that one is due
+	LastStakeholderUpdateAt *time.Time `json:"last_stakeholder_update_at"`
+	UpdateRemindedAt        *time.Time `json:"update_reminded_at"`
 
 	// Additional metadata
 	Notes string `gorm:"type:text" json:"notes"`
 }
 
+// WarRoomLink is a channel or meeting bridge opened for an incident
+type WarRoomLink struct {
+	Provider  string    `json:"provider"` // slack, teams or zoom
+	Name      string    `json:"name"`
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 // BeforeCreate hook to generate UUID
 func (i *Incident) BeforeCreate(tx *gorm.DB) error {
 	if i.IncidentID == "" {
@@ -52,6 +102,27 @@ func (i *Incident) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// SetStatus moves the incident to status on behalf of actor. Resolving
+// records who resolved it and when; moving a resolved incident back to an
+// active status clears that, and the resolution classification, and counts
+// a reopen.
+func (i *Incident) SetStatus(status IncidentStatus, actor string) {
+	if status == i.Status {
+		return
+	}
+	if status == StatusResolved {
+		now := time.Now().UTC()
+		i.ResolvedAt = &now
+		i.ResolvedBy = &actor
+	} else if i.Status == StatusResolved {
+		i.ResolvedAt = nil
+		i.ResolvedBy = nil
+		i.Resolution = ""
+		i.ReopenCount++
+	}
+	i.Status = status
+}
+
 // TableName specifies the table name for Incident
 func (Incident) TableName() string {
 	return "incidents"
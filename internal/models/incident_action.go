@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IncidentAction records a playbook step taken in response to an incident,
+// linking the incident to the run and, for action steps, the action log
+type IncidentAction struct {
+	ID        string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	IncidentID string  `gorm:"index;type:varchar(36);not null" json:"incident_id"`
+	ActionID   *string `gorm:"index;type:varchar(36)" json:"action_id"` // nil for manual and transform steps
+	RunID      string  `gorm:"index;type:varchar(36);not null" json:"run_id"`
+	PlaybookID string  `gorm:"type:varchar(100);not null" json:"playbook_id"`
+	StepID     string  `gorm:"type:varchar(100);not null" json:"step_id"`
+	ActionType string  `gorm:"type:varchar(100)" json:"action_type"`    // action name, "manual" or "transform"
+	Status     string  `gorm:"type:varchar(20);not null" json:"status"` // completed or failed
+	Summary    string  `gorm:"type:text" json:"summary"`
+}
+
+// BeforeCreate hook to generate UUID
+func (a *IncidentAction) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for IncidentAction
+func (IncidentAction) TableName() string {
+	return "incident_actions"
+}
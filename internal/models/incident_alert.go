@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Upstream alert states
+const (
+	AlertFiring   = "firing"
+	AlertResolved = "resolved"
+)
+
+// IncidentAlert links an incident to an upstream alert (e.g. an Alertmanager
+// fingerprint) whose events it was raised or updated from
+type IncidentAlert struct {
+	ID        string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	IncidentID  string     `gorm:"uniqueIndex:idx_incident_alert;type:varchar(36);not null" json:"incident_id"`
+	Source      string     `gorm:"uniqueIndex:idx_incident_alert;type:varchar(50);not null" json:"source"`
+	Fingerprint string     `gorm:"uniqueIndex:idx_incident_alert;index;type:varchar(255);not null" json:"fingerprint"`
+	AlertName   string     `gorm:"type:varchar(255)" json:"alert_name"`
+	Status      string     `gorm:"type:varchar(20);not null" json:"status"`
+	ResolvedAt  *time.Time `json:"resolved_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (a *IncidentAlert) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for IncidentAlert
+func (IncidentAlert) TableName() string {
+	return "incident_alerts"
+}
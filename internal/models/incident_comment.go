@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IncidentComment represents a responder's comment on an incident timeline
+type IncidentComment struct {
+	CommentID string    `gorm:"primaryKey;type:varchar(36)" json:"comment_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	IncidentID string `gorm:"index;type:varchar(36);not null" json:"incident_id"`
+	Author     string `gorm:"type:varchar(255);not null" json:"author"`
+	Body       string `gorm:"type:text;not null" json:"body"`
+}
+
+// BeforeCreate hook to generate UUID
+func (c *IncidentComment) BeforeCreate(tx *gorm.DB) error {
+	if c.CommentID == "" {
+		c.CommentID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for IncidentComment
+func (IncidentComment) TableName() string {
+	return "incident_comments"
+}
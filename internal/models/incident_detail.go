@@ -0,0 +1,22 @@
+package models
+
+// IncidentDetail is an incident with the related records a client asked to
+// include. Each kind of record is preloaded with one query, however many
+// there are. Records not asked for are left out of the JSON.
+type IncidentDetail struct {
+	Incident
+
+	Events   []EventSummary      `gorm:"-" json:"events,omitempty"` // from RelatedEvents
+	Actions  []ActionLog         `gorm:"foreignKey:IncidentID;references:IncidentID" json:"actions,omitempty"`
+	Runs     []PlaybookRun       `gorm:"foreignKey:IncidentID;references:IncidentID" json:"runs,omitempty"`
+	Comments []IncidentComment   `gorm:"foreignKey:IncidentID;references:IncidentID" json:"comments,omitempty"`
+	Tasks    []IncidentTask      `gorm:"foreignKey:IncidentID;references:IncidentID" json:"tasks,omitempty"`
+	Alerts   []IncidentAlert     `gorm:"foreignKey:IncidentID;references:IncidentID" json:"alerts,omitempty"`
+	Updates  []StakeholderUpdate `gorm:"foreignKey:IncidentID;references:IncidentID" json:"updates,omitempty"`
+	Handoffs []IncidentHandoff   `gorm:"foreignKey:IncidentID;references:IncidentID" json:"handoffs,omitempty"`
+}
+
+// TableName specifies the table name for IncidentDetail
+func (IncidentDetail) TableName() string {
+	return "incidents"
+}
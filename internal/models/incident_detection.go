@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// IncidentDetection explains the rule match that created an incident: the
+// conditions it passed with the values observed, the windows its count
+// conditions counted and the events in them
+type IncidentDetection struct {
+	IncidentID string `gorm:"primaryKey;type:varchar(36)" json:"incident_id"`
+	RuleID     string `gorm:"index;type:varchar(100);not null" json:"rule_id"`
+	EventID    string `gorm:"type:varchar(36);not null" json:"event_id"`
+	// Explanation is the match as JSON (services.MatchExplanation)
+	Explanation string    `gorm:"type:text;not null" json:"-"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for IncidentDetection
+func (IncidentDetection) TableName() string {
+	return "incident_detections"
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IncidentHandoff is an end-of-shift summary passed from the outgoing
+// responder to the incoming one
+type IncidentHandoff struct {
+	HandoffID string    `gorm:"primaryKey;type:varchar(36)" json:"handoff_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+
+	IncidentID string `gorm:"index;type:varchar(36);not null" json:"incident_id"`
+	From       string `gorm:"type:varchar(255);not null" json:"from"`
+	To         string `gorm:"type:varchar(255)" json:"to"` // incoming on-call; empty notifies through routes
+
+	CurrentState   string `gorm:"type:text" json:"current_state"`
+	PendingActions string `gorm:"type:text" json:"pending_actions"`
+	NextSteps      string `gorm:"type:text" json:"next_steps"`
+
+	// Roles the outgoing responder handed to the incoming one
+	Roles []string `gorm:"serializer:json" json:"roles,omitempty"`
+
+	// DraftedBy names the model that drafted the summary ("timeline" for
+	// the built-in summary), empty when written entirely by hand
+	DraftedBy string `gorm:"type:varchar(100)" json:"drafted_by,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID
+func (h *IncidentHandoff) BeforeCreate(tx *gorm.DB) error {
+	if h.HandoffID == "" {
+		h.HandoffID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for IncidentHandoff
+func (IncidentHandoff) TableName() string {
+	return "incident_handoffs"
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Incident roles, after the usual incident command structure
+const (
+	RoleCommander = "commander"  // owns the response and its decisions
+	RoleCommsLead = "comms_lead" // owns stakeholder communication
+	RoleScribe    = "scribe"     // keeps the timeline
+	RoleSME       = "sme"        // subject matter expert; an incident may have several
+)
+
+// IncidentRoles lists the roles in the order they are shown
+var IncidentRoles = []string{RoleCommander, RoleCommsLead, RoleScribe, RoleSME}
+
+// ValidIncidentRole reports whether role is a known incident role
+func ValidIncidentRole(role string) bool {
+	for _, r := range IncidentRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// IncidentRoleAssignment records a responder holding a role on an incident.
+// Rotating a role ends the current assignment and starts a new one, so the
+// rows form the role's handover history; current holders have no EndedAt.
+type IncidentRoleAssignment struct {
+	AssignmentID string `gorm:"primaryKey;type:varchar(36)" json:"assignment_id"`
+
+	IncidentID string     `gorm:"index:idx_incident_role;type:varchar(36);not null" json:"incident_id"`
+	Role       string     `gorm:"index:idx_incident_role;type:varchar(20);not null" json:"role"`
+	Assignee   string     `gorm:"index;type:varchar(255);not null" json:"assignee"`
+	AssignedBy string     `gorm:"type:varchar(255)" json:"assigned_by"`
+	StartedAt  time.Time  `gorm:"not null" json:"started_at"`
+	EndedAt    *time.Time `gorm:"index" json:"ended_at"`
+	EndedBy    *string    `gorm:"type:varchar(255)" json:"ended_by"`
+	Note       string     `gorm:"type:text" json:"note"`
+}
+
+// BeforeCreate hook to generate UUID
+func (a *IncidentRoleAssignment) BeforeCreate(tx *gorm.DB) error {
+	if a.AssignmentID == "" {
+		a.AssignmentID = uuid.New().String()
+	}
+	if a.StartedAt.IsZero() {
+		a.StartedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// TableName specifies the table name for IncidentRoleAssignment
+func (IncidentRoleAssignment) TableName() string {
+	return "incident_roles"
+}
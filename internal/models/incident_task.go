@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaskStatus represents the state of a human action item
+type TaskStatus string
+
+const (
+	TaskOpen       TaskStatus = "open"
+	TaskInProgress TaskStatus = "in_progress"
+	TaskDone       TaskStatus = "done"
+	TaskCancelled  TaskStatus = "cancelled"
+)
+
+// IncidentTask represents a manual checklist item attached to an incident
+type IncidentTask struct {
+	TaskID    string    `gorm:"primaryKey;type:varchar(36)" json:"task_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	IncidentID string     `gorm:"index;type:varchar(36);not null" json:"incident_id"`
+	Title      string     `gorm:"type:varchar(500);not null" json:"title"`
+	Assignee   *string    `gorm:"type:varchar(255)" json:"assignee"`
+	DueAt      *time.Time `json:"due_at"`
+	Status     TaskStatus `gorm:"index;type:varchar(20);not null" json:"status"`
+
+	// Origin when created from a manual playbook step
+	PlaybookID *string `gorm:"type:varchar(100)" json:"playbook_id"`
+	StepID     *string `gorm:"type:varchar(100)" json:"step_id"`
+
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// BeforeCreate hook to generate UUID and set defaults
+func (t *IncidentTask) BeforeCreate(tx *gorm.DB) error {
+	if t.TaskID == "" {
+		t.TaskID = uuid.New().String()
+	}
+	if t.Status == "" {
+		t.Status = TaskOpen
+	}
+	return nil
+}
+
+// TableName specifies the table name for IncidentTask
+func (IncidentTask) TableName() string {
+	return "incident_tasks"
+}
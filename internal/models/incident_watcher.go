@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Reasons a user watches an incident
+const (
+	WatchManual     = "manual"
+	WatchComment    = "comment"
+	WatchAssignment = "assignment"
+	WatchRole       = "role"
+	WatchHandoff    = "handoff"
+)
+
+// IncidentWatcher subscribes a user to notifications about an incident's
+// subsequent changes
+type IncidentWatcher struct {
+	WatcherID string    `gorm:"primaryKey;type:varchar(36)" json:"watcher_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	IncidentID string `gorm:"uniqueIndex:idx_incident_watcher;type:varchar(36);not null" json:"incident_id"`
+	User       string `gorm:"uniqueIndex:idx_incident_watcher;index;type:varchar(255);not null" json:"user"`
+	Reason     string `gorm:"type:varchar(20);not null" json:"reason"`
+}
+
+// BeforeCreate hook to generate UUID
+func (w *IncidentWatcher) BeforeCreate(tx *gorm.DB) error {
+	if w.WatcherID == "" {
+		w.WatcherID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for IncidentWatcher
+func (IncidentWatcher) TableName() string {
+	return "incident_watchers"
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Lease is a named, time-limited lock held by one service instance. It backs
+// leader election for background jobs when several instances share a database.
+type Lease struct {
+	Name      string    `gorm:"primaryKey;type:varchar(255)" json:"name"`
+	Holder    string    `gorm:"type:varchar(255);not null" json:"holder"`
+	ExpiresAt time.Time `gorm:"index;not null" json:"expires_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name for Lease
+func (Lease) TableName() string {
+	return "leases"
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// LoginLocation is where a user last logged in from, as tracked by the
+// impossible travel detector. Exercise logins are tracked apart from real
+// ones.
+type LoginLocation struct {
+	Username  string    `gorm:"primaryKey;type:varchar(255)" json:"username"`
+	Exercise  bool      `gorm:"primaryKey" json:"exercise"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	SourceIP  string    `gorm:"type:varchar(64)" json:"source_ip"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Country   string    `gorm:"type:varchar(100)" json:"country"`
+	City      string    `gorm:"type:varchar(255)" json:"city"`
+	SeenAt    time.Time `gorm:"not null" json:"seen_at"` // login event timestamp
+	EventID   string    `gorm:"type:varchar(36)" json:"event_id"`
+}
+
+// TableName specifies the table name for LoginLocation
+func (LoginLocation) TableName() string {
+	return "login_locations"
+}
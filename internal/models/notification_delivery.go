@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DeliveryStatus represents the outcome of a routed notification
+type DeliveryStatus string
+
+const (
+	DeliverySent          DeliveryStatus = "sent"
+	DeliveryFailed        DeliveryStatus = "failed"
+	DeliveryQuietHours    DeliveryStatus = "suppressed_quiet_hours"
+	DeliveryDuplicate     DeliveryStatus = "suppressed_duplicate"
+	DeliveryDigestPending DeliveryStatus = "digest_pending"
+	DeliveryDigested      DeliveryStatus = "digested"
+)
+
+// NotificationDelivery records one notification sent (or held) for a route
+type NotificationDelivery struct {
+	DeliveryID string    `gorm:"primaryKey;type:varchar(36)" json:"delivery_id"`
+	CreatedAt  time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+
+	RouteID    *string        `gorm:"index;type:varchar(100)" json:"route_id"` // nil when no route matched
+	IncidentID *string        `gorm:"index;type:varchar(36)" json:"incident_id"`
+	Target     string         `gorm:"type:varchar(255)" json:"target"`
+	Fallback   bool           `gorm:"not null;default:false" json:"fallback"`
+	Status     DeliveryStatus `gorm:"index;type:varchar(30);not null" json:"status"`
+	Message    string         `gorm:"type:text" json:"message"`
+	Error      *string        `gorm:"type:text" json:"error"`
+
+	// DedupKey is route ID + incident ID (or message hash)
+	DedupKey string `gorm:"index;type:varchar(255)" json:"dedup_key"`
+
+	// DigestID links a digested notification to the summary delivery that included it
+	DigestID *string `gorm:"index;type:varchar(36)" json:"digest_id"`
+}
+
+// BeforeCreate hook to generate UUID
+func (d *NotificationDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.DeliveryID == "" {
+		d.DeliveryID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for NotificationDelivery
+func (NotificationDelivery) TableName() string {
+	return "notification_deliveries"
+}
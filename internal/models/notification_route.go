@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationRoute sends notifications for matching incidents to targets.
+// Empty match lists match anything.
+type NotificationRoute struct {
+	RouteID   string    `gorm:"primaryKey;type:varchar(100)" json:"route_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Name     string `gorm:"type:varchar(255)" json:"name"`
+	Priority int    `gorm:"index;not null;default:100" json:"priority"` // lower routes are evaluated first
+	Disabled bool   `gorm:"not null;default:false" json:"disabled"`
+	Stop     bool   `gorm:"not null;default:false" json:"stop"` // don't evaluate later routes after a match
+
+	// Match
+	Severities []string `gorm:"serializer:json" json:"severities"`
+	Categories []string `gorm:"serializer:json" json:"categories"`
+	Tags       []string `gorm:"serializer:json" json:"tags"` // any tag matches
+	Teams      []string `gorm:"serializer:json" json:"teams"`
+
+	// Targets are notify channels, e.g. "console", "slack:#security" or "pagerduty";
+	// fallbacks are used when no target could be delivered to
+	Targets   []string `gorm:"serializer:json" json:"targets"`
+	Fallbacks []string `gorm:"serializer:json" json:"fallbacks"`
+
+	// Quiet hours ("22:00"-"07:00" in QuietTimezone) hold notifications below
+	// QuietMinSeverity; with no minimum, all are held
+	QuietStart       string        `gorm:"type:varchar(5)" json:"quiet_start"`
+	QuietEnd         string        `gorm:"type:varchar(5)" json:"quiet_end"`
+	QuietTimezone    string        `gorm:"type:varchar(64)" json:"quiet_timezone"`
+	QuietMinSeverity SeverityLevel `gorm:"type:varchar(20)" json:"quiet_min_severity"`
+
+	// DedupWindow suppresses repeat notifications for the same incident
+	// (or message) on this route within the window
+	DedupWindow int `json:"dedup_window"` // seconds
+
+	// Notifications with a digest severity are batched into one summary per
+	// target every DigestInterval ("hourly", "daily" or a Go duration)
+	DigestSeverities []string `gorm:"serializer:json" json:"digest_severities"`
+	DigestInterval   string   `gorm:"type:varchar(20)" json:"digest_interval"`
+}
+
+// TableName specifies the table name for NotificationRoute
+func (NotificationRoute) TableName() string {
+	return "notification_routes"
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OutboxStatus represents the delivery state of an outbox message
+type OutboxStatus string
+
+const (
+	OutboxPending    OutboxStatus = "pending"
+	OutboxDispatched OutboxStatus = "dispatched"
+	OutboxFailed     OutboxStatus = "failed"
+)
+
+// OutboxMessage is a side effect (notification, playbook run) recorded in the
+// same transaction as the state change that caused it, and dispatched after commit
+type OutboxMessage struct {
+	MessageID string    `gorm:"primaryKey;type:varchar(36)" json:"message_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	Topic   string `gorm:"index;type:varchar(100);not null" json:"topic"`
+	Payload string `gorm:"type:text;not null" json:"payload"` // JSON payload
+
+	// Delivery state
+	Status        OutboxStatus `gorm:"index;type:varchar(20);not null" json:"status"`
+	Attempts      int          `json:"attempts"`
+	NextAttemptAt time.Time    `gorm:"index" json:"next_attempt_at"`
+	LastError     *string      `gorm:"type:text" json:"last_error"`
+	DispatchedAt  *time.Time   `json:"dispatched_at"`
+}
+
+// BeforeCreate hook to generate UUID and set defaults
+func (m *OutboxMessage) BeforeCreate(tx *gorm.DB) error {
+	if m.MessageID == "" {
+		m.MessageID = uuid.New().String()
+	}
+	if m.Status == "" {
+		m.Status = OutboxPending
+	}
+	if m.NextAttemptAt.IsZero() {
+		m.NextAttemptAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// TableName specifies the table name for OutboxMessage
+func (OutboxMessage) TableName() string {
+	return "outbox_messages"
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"gorm.io/gorm/schema"
+)
+
+func init() {
+	schema.RegisterSerializer("payload", payloadSerializer{})
+}
+
+// Compressed payloads start with payloadMarker, a byte JSON text can't
+// begin with, followed by a byte naming the codec
+const payloadMarker = 0x00
+
+// payloadCodec compresses stored payloads one way
+type payloadCodec struct {
+	id         byte
+	compress   func([]byte) ([]byte, error)
+	decompress func([]byte) ([]byte, error)
+}
+
+// payloadCodecs are the codecs by configured name
+var payloadCodecs = map[string]*payloadCodec{
+	"gzip": {id: 'g', compress: gzipCompress, decompress: gzipDecompress},
+}
+
+// payloadCompression is the codec new payloads are written with, nil to
+// store them as they are
+type payloadCompression struct {
+	codec   *payloadCodec
+	minSize int
+}
+
+var compression atomic.Pointer[payloadCompression]
+
+// SetPayloadCompression compresses raw payloads of at least minSize bytes
+// written from now on with codec ("gzip"), or stops compressing with
+// "none". Payloads already stored are read whichever way they were written.
+func SetPayloadCompression(codec string, minSize int) error {
+	if codec == "" || codec == "none" {
+		compression.Store(nil)
+		return nil
+	}
+	c, ok := payloadCodecs[codec]
+	if !ok {
+		return fmt.Errorf("unknown payload compression %q", codec)
+	}
+	compression.Store(&payloadCompression{codec: c, minSize: minSize})
+	return nil
+}
+
+// EncodePayload compresses a payload for storage when compression is on,
+// the payload is large enough and compressing saves space. Encoded payloads
+// are returned unchanged.
+func EncodePayload(payload string) string {
+	settings := compression.Load()
+	if settings == nil || len(payload) < settings.minSize || IsEncodedPayload(payload) {
+		return payload
+	}
+	compressed, err := settings.codec.compress([]byte(payload))
+	if err != nil || len(compressed)+2 >= len(payload) {
+		return payload
+	}
+	return string(append([]byte{payloadMarker, settings.codec.id}, compressed...))
+}
+
+// IsEncodedPayload reports whether a stored payload is compressed
+func IsEncodedPayload(payload string) bool {
+	return len(payload) >= 2 && payload[0] == payloadMarker
+}
+
+// DecodePayload returns a stored payload as written by clients
+func DecodePayload(payload string) (string, error) {
+	if !IsEncodedPayload(payload) {
+		return payload, nil
+	}
+	for _, codec := range payloadCodecs {
+		if codec.id == payload[1] {
+			plain, err := codec.decompress([]byte(payload[2:]))
+			if err != nil {
+				return "", fmt.Errorf("failed to decompress payload: %w", err)
+			}
+			return string(plain), nil
+		}
+	}
+	return "", fmt.Errorf("unknown payload codec %q", payload[1])
+}
+
+// payloadSerializer stores a string column through EncodePayload, as a BLOB
+// when compressed, and reads it back through DecodePayload. Queries that
+// bypass the model, such as projections into maps, must decode themselves.
+type payloadSerializer struct{}
+
+func (payloadSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored string
+	switch v := dbValue.(type) {
+	case []byte:
+		stored = string(v)
+	case string:
+		stored = v
+	}
+	payload, err := DecodePayload(stored)
+	if err != nil {
+		return err
+	}
+	field.ReflectValueOf(ctx, dst).SetString(payload)
+	return nil
+}
+
+func (payloadSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	payload, _ := fieldValue.(string)
+	if encoded := EncodePayload(payload); encoded != payload {
+		return []byte(encoded), nil
+	}
+	return payload, nil
+}
+
+// gzipWriters reuses compressors, which are costly to allocate
+var gzipWriters = sync.Pool{New: func() interface{} { return gzip.NewWriter(nil) }}
+
+func gzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzipWriters.Get().(*gzip.Writer)
+	defer gzipWriters.Put(w)
+	w.Reset(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gzipDecompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RunStatus represents the state of a playbook run
+type RunStatus string
+
+const (
+	RunRunning   RunStatus = "running"
+	RunCompleted RunStatus = "completed"
+	RunFailed    RunStatus = "failed"
+	// RunRejected runs were refused by an execution quota and never started
+	RunRejected RunStatus = "rejected"
+)
+
+// PlaybookRun records one execution of a playbook
+type PlaybookRun struct {
+	RunID       string     `gorm:"primaryKey;type:varchar(36)" json:"run_id"`
+	StartedAt   time.Time  `gorm:"autoCreateTime" json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+
+	PlaybookID string    `gorm:"index;type:varchar(100);not null" json:"playbook_id"`
+	IncidentID *string   `gorm:"index;type:varchar(36)" json:"incident_id"`
+	Status     RunStatus `gorm:"index;type:varchar(20);not null" json:"status"`
+	RequestID  *string   `gorm:"index;type:varchar(128)" json:"request_id,omitempty"`
+
+	// EventID is the event that triggered a rule-driven run
+	EventID *string `gorm:"index;type:varchar(36)" json:"event_id,omitempty"`
+
+	// RuleID is the detection rule that queued the run, and Tenant the
+	// event source it matched, or the API key that started a manual run;
+	// execution quotas are counted per rule and per tenant
+	RuleID *string `gorm:"index;type:varchar(100)" json:"rule_id,omitempty"`
+	Tenant *string `gorm:"index;type:varchar(100)" json:"tenant,omitempty"`
+
+	// TriggeredBy is the API key principal that started a manual run
+	TriggeredBy *string `gorm:"type:varchar(255)" json:"triggered_by,omitempty"`
+
+	// Exercise marks runs triggered by synthetic scenario events; their
+	// remediation actions are held back as in a dry run
+	Exercise bool `gorm:"index;not null;default:false" json:"exercise,omitempty"`
+
+	// Environment is the playbook environment profile the run used
+	Environment string `gorm:"type:varchar(50)" json:"environment,omitempty"`
+
+	Inputs string  `gorm:"type:text" json:"inputs"` // JSON inputs
+	Error  *string `gorm:"type:text" json:"error"`
+}
+
+// BeforeCreate hook to generate UUID and set defaults
+func (r *PlaybookRun) BeforeCreate(tx *gorm.DB) error {
+	if r.RunID == "" {
+		r.RunID = uuid.New().String()
+	}
+	if r.Status == "" {
+		r.Status = RunRunning
+	}
+	return nil
+}
+
+// TableName specifies the table name for PlaybookRun
+func (PlaybookRun) TableName() string {
+	return "playbook_runs"
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Credential kinds on the denylist
+const (
+	CredentialAPIKey = "api_key"
+	CredentialToken  = "token" // a leaked token revoked by value
+)
+
+// RevokedCredential is a denylisted API key or token. Only the hash of the
+// credential is kept; requests presenting it are rejected.
+type RevokedCredential struct {
+	RevocationID string    `gorm:"primaryKey;type:varchar(36)" json:"revocation_id"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"revoked_at"`
+
+	TokenHash string `gorm:"uniqueIndex;type:varchar(64);not null" json:"-"`
+	Kind      string `gorm:"type:varchar(20);not null" json:"kind"`
+	Name      string `gorm:"index;type:varchar(255)" json:"name"` // the API key's name, when known
+	Reason    string `gorm:"type:text" json:"reason"`
+	RevokedBy string `gorm:"type:varchar(255)" json:"revoked_by"`
+}
+
+// BeforeCreate hook to generate UUID
+func (r *RevokedCredential) BeforeCreate(tx *gorm.DB) error {
+	if r.RevocationID == "" {
+		r.RevocationID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for RevokedCredential
+func (RevokedCredential) TableName() string {
+	return "revoked_credentials"
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Runbook represents response documentation, stored inline as Markdown or
+// referenced by external URL
+type Runbook struct {
+	RunbookID string    `gorm:"primaryKey;type:varchar(36)" json:"runbook_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Slug is the stable identifier referenced from rule files
+	Slug     string `gorm:"uniqueIndex;type:varchar(100);not null" json:"slug"`
+	Title    string `gorm:"type:varchar(500);not null" json:"title"`
+	Category string `gorm:"index;type:varchar(100)" json:"category"`
+
+	// Content is Markdown; URL points at an external document. At least one is set.
+	Content string `gorm:"type:text" json:"content"`
+	URL     string `gorm:"type:varchar(2048)" json:"url"`
+}
+
+// BeforeCreate hook to generate UUID
+func (r *Runbook) BeforeCreate(tx *gorm.DB) error {
+	if r.RunbookID == "" {
+		r.RunbookID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for Runbook
+func (Runbook) TableName() string {
+	return "runbooks"
+}
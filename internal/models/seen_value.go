@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SeenValue records a value observed by a first_seen or rare rule condition,
+// e.g. a country a user has logged in from. Scope names the condition
+// ("<rule id>:<field>"), Entity the group_by values it is tracked per
+// ("username=alice"), and Count the times it has been seen since FirstSeen.
+type SeenValue struct {
+	ID string `gorm:"primaryKey;type:varchar(36)" json:"id"`
+
+	Scope  string `gorm:"type:varchar(255);not null;uniqueIndex:idx_seen_value" json:"scope"`
+	Entity string `gorm:"type:varchar(512);not null;uniqueIndex:idx_seen_value" json:"entity"`
+	Value  string `gorm:"type:varchar(512);not null;uniqueIndex:idx_seen_value" json:"value"`
+
+	FirstSeen time.Time `gorm:"not null" json:"first_seen"`
+	LastSeen  time.Time `gorm:"index;not null" json:"last_seen"`
+	Count     int64     `gorm:"not null;default:0" json:"count"`
+}
+
+// BeforeCreate hook to generate UUID
+func (s *SeenValue) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for SeenValue
+func (SeenValue) TableName() string {
+	return "seen_values"
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Service is an entry in the service catalog: something incidents can
+// impact, the team that owns it and how critical it is
+type Service struct {
+	ServiceID string    `gorm:"primaryKey;type:varchar(36)" json:"service_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Name        string `gorm:"uniqueIndex;type:varchar(100);not null" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+	OwnerTeam   string `gorm:"index;type:varchar(100)" json:"owner_team"`
+	// Tier is the service's criticality, from 1 (most critical) to 4
+	Tier int `gorm:"not null;default:3" json:"tier"`
+	// Dependencies are the names of the services this one depends on
+	Dependencies []string `gorm:"serializer:json" json:"dependencies"`
+}
+
+// BeforeCreate hook to generate UUID
+func (s *Service) BeforeCreate(tx *gorm.DB) error {
+	if s.ServiceID == "" {
+		s.ServiceID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for Service
+func (Service) TableName() string {
+	return "services"
+}
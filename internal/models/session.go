@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Session is a signed-in user's login. Only the hash of its token is kept.
+type Session struct {
+	SessionID string    `gorm:"primaryKey;type:varchar(36)" json:"session_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	TokenHash string `gorm:"uniqueIndex;type:varchar(64);not null" json:"-"`
+	User      string `gorm:"index;type:varchar(255);not null" json:"user"`
+	Role      string `gorm:"type:varchar(20);not null" json:"role"`
+	Provider  string `gorm:"type:varchar(50)" json:"provider"` // how the user signed in, e.g. "oidc"
+
+	ExpiresAt  time.Time `gorm:"index" json:"expires_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (s *Session) BeforeCreate(tx *gorm.DB) error {
+	if s.SessionID == "" {
+		s.SessionID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for Session
+func (Session) TableName() string {
+	return "sessions"
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SimulationRun records a replay of events through the rule set. Would-be
+// incidents and actions are stored as a JSON report; nothing is executed.
+type SimulationRun struct {
+	RunID       string     `gorm:"primaryKey;type:varchar(36)" json:"run_id"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+
+	// Input: a stored time range, or an uploaded file
+	Mode       string     `gorm:"type:varchar(20);not null" json:"mode"` // range, upload
+	RangeStart *time.Time `json:"range_start"`
+	RangeEnd   *time.Time `json:"range_end"`
+	Label      string     `gorm:"type:varchar(255)" json:"label"`
+
+	// Summary
+	EventsReplayed int `json:"events_replayed"`
+	RuleMatches    int `json:"rule_matches"`
+	IncidentCount  int `json:"incident_count"`
+	ActionCount    int `json:"action_count"`
+
+	Report string `gorm:"type:text" json:"-"` // JSON simulation report
+}
+
+// BeforeCreate hook to generate UUID
+func (r *SimulationRun) BeforeCreate(tx *gorm.DB) error {
+	if r.RunID == "" {
+		r.RunID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for SimulationRun
+func (SimulationRun) TableName() string {
+	return "simulation_runs"
+}
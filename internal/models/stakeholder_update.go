@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StakeholderUpdate is a communication about an incident rendered from a
+// comms template and sent to its audience
+type StakeholderUpdate struct {
+	UpdateID  string    `gorm:"primaryKey;type:varchar(36)" json:"update_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+
+	IncidentID string `gorm:"index;type:varchar(36);not null" json:"incident_id"`
+	Template   string `gorm:"type:varchar(100);not null" json:"template"`
+	Audience   string `gorm:"type:varchar(50)" json:"audience"` // internal, executive or customer
+	Subject    string `gorm:"type:varchar(500)" json:"subject"`
+	Body       string `gorm:"type:text" json:"body"`
+	SentBy     string `gorm:"type:varchar(255)" json:"sent_by"`
+
+	// Targets the update was delivered to, and those that failed
+	Targets []string `gorm:"serializer:json" json:"targets"`
+	Failed  []string `gorm:"serializer:json" json:"failed,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID
+func (u *StakeholderUpdate) BeforeCreate(tx *gorm.DB) error {
+	if u.UpdateID == "" {
+		u.UpdateID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for StakeholderUpdate
+func (StakeholderUpdate) TableName() string {
+	return "stakeholder_updates"
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SuppressionManual is the origin of suppressions created through the API;
+// imported ones use "calendar:<name>"
+const SuppressionManual = "manual"
+
+// Suppression stops rule matches from creating incidents or running
+// responses during a window, e.g. for planned maintenance. A match is
+// suppressed when the event's timestamp falls in the window, its source is
+// one of Sources and the rule has one of Tags; an empty list matches
+// anything, but at least one of the two must be set.
+type Suppression struct {
+	SuppressionID string    `gorm:"primaryKey;type:varchar(36)" json:"suppression_id"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Name     string    `gorm:"type:varchar(255)" json:"name"`
+	Reason   string    `gorm:"type:text" json:"reason"`
+	StartsAt time.Time `gorm:"index;not null" json:"starts_at"`
+	EndsAt   time.Time `gorm:"index;not null" json:"ends_at"`
+
+	Sources []string `gorm:"serializer:json" json:"sources"`
+	Tags    []string `gorm:"serializer:json" json:"tags"`
+
+	// Origin and ExternalID identify an imported calendar event, so
+	// re-importing updates the suppression instead of duplicating it
+	Origin     string `gorm:"type:varchar(255);not null;uniqueIndex:idx_suppression_external" json:"origin"`
+	ExternalID string `gorm:"type:varchar(512);not null;uniqueIndex:idx_suppression_external" json:"external_id"`
+	CreatedBy  string `gorm:"type:varchar(255)" json:"created_by"`
+
+	// Suppressed counts the rule matches dropped by this suppression
+	Suppressed int64 `gorm:"not null;default:0" json:"suppressed"`
+}
+
+// BeforeCreate hook to generate UUID
+func (s *Suppression) BeforeCreate(tx *gorm.DB) error {
+	if s.SuppressionID == "" {
+		s.SuppressionID = uuid.New().String()
+	}
+	if s.ExternalID == "" {
+		s.ExternalID = s.SuppressionID
+	}
+	return nil
+}
+
+// TableName specifies the table name for Suppression
+func (Suppression) TableName() string {
+	return "suppressions"
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// User is a person who signed in through single sign-on, provisioned on
+// their first login. Name is their principal name; Role is refreshed from
+// their identity provider groups at every login.
+type User struct {
+	UserID    string    `gorm:"primaryKey;type:varchar(36)" json:"user_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Name        string `gorm:"uniqueIndex;type:varchar(255);not null" json:"name"`
+	Email       string `gorm:"type:varchar(255)" json:"email"`
+	DisplayName string `gorm:"type:varchar(255)" json:"display_name"`
+	Role        string `gorm:"type:varchar(20);not null" json:"role"`
+	Groups      string `gorm:"type:text" json:"groups"` // JSON array of identity provider groups
+
+	// Issuer and Subject identify the user at their identity provider
+	Issuer  string `gorm:"uniqueIndex:idx_user_identity;type:varchar(255);not null" json:"issuer"`
+	Subject string `gorm:"uniqueIndex:idx_user_identity;type:varchar(255);not null" json:"subject"`
+
+	LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (u *User) BeforeCreate(tx *gorm.DB) error {
+	if u.UserID == "" {
+		u.UserID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for User
+func (User) TableName() string {
+	return "users"
+}
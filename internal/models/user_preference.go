@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// UserPreference holds a user's personal settings, keyed by their principal
+// name
+type UserPreference struct {
+	User string `gorm:"primaryKey;type:varchar(255)" json:"user"`
+	// NotifyTarget is where watched-incident updates go, in notification
+	// route target form (e.g. "slack", "sms:+15551234567")
+	NotifyTarget string    `gorm:"type:varchar(255)" json:"notify_target"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name for UserPreference
+func (UserPreference) TableName() string {
+	return "user_preferences"
+}
@@ -0,0 +1,285 @@
+package parsers
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// auditRecord is one line of audit.log
+type auditRecord struct {
+	Type      string
+	Timestamp time.Time
+	Serial    string
+	Fields    map[string]string
+	Raw       string
+}
+
+var auditHeader = regexp.MustCompile(`^(?:node=\S+ )?type=(\S+) msg=audit\((\d+)\.(\d+):(\d+)\):\s*(.*)$`)
+
+// auditKV matches key=value, key="quoted value" and key='quoted value'
+var auditKV = regexp.MustCompile(`([A-Za-z0-9_-]+)=("[^"]*"|'[^']*'|\S*)`)
+
+// parseAuditRecord parses a single audit.log line
+func parseAuditRecord(line string) (auditRecord, error) {
+	// Enriched logs append interpreted fields after a 0x1d separator
+	raw, enriched, _ := strings.Cut(line, "\x1d")
+
+	m := auditHeader.FindStringSubmatch(strings.TrimSpace(raw))
+	if m == nil {
+		return auditRecord{}, fmt.Errorf("not an audit record")
+	}
+
+	sec, _ := strconv.ParseInt(m[2], 10, 64)
+	msec, _ := strconv.ParseInt(m[3], 10, 64)
+	rec := auditRecord{
+		Type:      m[1],
+		Timestamp: time.Unix(sec, msec*int64(time.Millisecond)).UTC(),
+		Serial:    m[4],
+		Fields:    make(map[string]string),
+		Raw:       line,
+	}
+
+	body := m[5]
+	// USER_* records nest their interesting fields in msg='...'
+	if i := strings.Index(body, "msg='"); i >= 0 {
+		inner := body[i+5:]
+		if j := strings.LastIndex(inner, "'"); j >= 0 {
+			body = body[:i] + inner[:j]
+		}
+	}
+	for _, kv := range auditKV.FindAllStringSubmatch(body+" "+enriched, -1) {
+		rec.Fields[kv[1]] = auditValue(kv[1], kv[2])
+	}
+	return rec, nil
+}
+
+// auditHexFields are the untrusted string fields auditd hex-encodes when they
+// contain spaces or control characters
+var auditHexFields = map[string]bool{
+	"comm": true, "exe": true, "cmd": true, "name": true, "cwd": true,
+	"proctitle": true, "acct": true, "path": true,
+}
+
+// auditValue unquotes a value, decoding hex-encoded strings
+func auditValue(key, v string) string {
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') {
+		return v[1 : len(v)-1]
+	}
+	hexField := auditHexFields[key] || (len(key) > 1 && key[0] == 'a' && isDigits(key[1:]))
+	if hexField && len(v) >= 2 && len(v)%2 == 0 && isHex(v) {
+		if decoded, err := hex.DecodeString(v); err == nil && isPrintable(decoded) {
+			return string(decoded)
+		}
+	}
+	return v
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789ABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// isPrintable allows the NUL separators proctitle uses between arguments
+func isPrintable(b []byte) bool {
+	for _, c := range b {
+		if c < 0x20 && c != 0 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// AuditAssembler groups audit.log records that share a serial number (e.g.
+// SYSCALL + EXECVE + CWD + PATH) and emits one normalized event per group
+type AuditAssembler struct {
+	pending []auditRecord
+}
+
+// NewAuditAssembler creates an assembler
+func NewAuditAssembler() *AuditAssembler {
+	return &AuditAssembler{}
+}
+
+// Add feeds one line and returns any events it completes. Unparseable lines
+// are ignored.
+func (a *AuditAssembler) Add(line string) []Parsed {
+	rec, err := parseAuditRecord(line)
+	if err != nil {
+		return nil
+	}
+
+	var out []Parsed
+	if len(a.pending) > 0 && a.pending[0].Serial != rec.Serial {
+		out = append(out, a.Flush()...)
+	}
+
+	if rec.Type == "EOE" {
+		return append(out, a.Flush()...)
+	}
+
+	a.pending = append(a.pending, rec)
+
+	// Only syscall events span several records; everything else is complete
+	if a.pending[0].Type != "SYSCALL" {
+		out = append(out, a.Flush()...)
+	}
+	return out
+}
+
+// Flush emits the pending group, if any
+func (a *AuditAssembler) Flush() []Parsed {
+	if len(a.pending) == 0 {
+		return nil
+	}
+	group := a.pending
+	a.pending = nil
+	return []Parsed{normalizeAudit(group)}
+}
+
+// ParseAuditLog parses a complete audit.log
+func ParseAuditLog(lines []string) []Parsed {
+	a := NewAuditAssembler()
+	var out []Parsed
+	for _, line := range lines {
+		out = append(out, a.Add(line)...)
+	}
+	return append(out, a.Flush()...)
+}
+
+// auditDenied are syscall exit codes for permission failures (EPERM, EACCES)
+var auditDenied = map[string]bool{"-1": true, "-13": true, "EPERM": true, "EACCES": true}
+
+// normalizeAudit builds an event from a group of records
+func normalizeAudit(group []auditRecord) Parsed {
+	first := group[0]
+	p := Parsed{
+		EventType: "audit_" + strings.ToLower(first.Type),
+		Severity:  "info",
+		Timestamp: first.Timestamp,
+		Fields: map[string]interface{}{
+			"audit_type":   first.Type,
+			"audit_serial": first.Serial,
+		},
+	}
+
+	raws := make([]string, len(group))
+	byType := make(map[string]auditRecord, len(group))
+	for i, rec := range group {
+		raws[i] = rec.Raw
+		if _, seen := byType[rec.Type]; !seen {
+			byType[rec.Type] = rec
+		}
+	}
+	p.Raw = strings.Join(raws, "\n")
+
+	f := first.Fields
+	setField(p.Fields, "uid", f["uid"])
+	setField(p.Fields, "auid", f["auid"])
+	setField(p.Fields, "pid", f["pid"])
+	setField(p.Fields, "exe", f["exe"])
+	setField(p.Fields, "process_name", f["comm"])
+	setField(p.Fields, "terminal", f["terminal"])
+	setField(p.Fields, "key", f["key"])
+	// Enriched logs resolve ids to names (UID="root", AUID="alice")
+	setField(p.Fields, "user", firstNonEmpty(f["UID"], f["acct"], f["AUID"]))
+
+	switch first.Type {
+	case "SYSCALL":
+		setField(p.Fields, "syscall", firstNonEmpty(f["SYSCALL"], f["syscall"]))
+		setField(p.Fields, "success", f["success"])
+		setField(p.Fields, "exit", f["exit"])
+
+		if execve, ok := byType["EXECVE"]; ok {
+			p.EventType = "process_execution"
+			p.Fields["command_line"] = execveCommandLine(execve.Fields)
+		}
+		if cwd, ok := byType["CWD"]; ok {
+			setField(p.Fields, "cwd", cwd.Fields["cwd"])
+		}
+		if path, ok := byType["PATH"]; ok {
+			setField(p.Fields, "path", path.Fields["name"])
+		}
+		if f["success"] == "no" && auditDenied[f["exit"]] {
+			p.EventType = "permission_denied"
+			p.Severity = "medium"
+		}
+
+	case "AVC":
+		p.EventType = "permission_denied"
+		p.Severity = "medium"
+		setField(p.Fields, "process_name", f["comm"])
+		setField(p.Fields, "path", f["name"])
+		setField(p.Fields, "permission", strings.Trim(auditAVCPerm(first.Raw), " "))
+
+	case "USER_LOGIN", "USER_AUTH", "USER_ERR", "USER_START", "USER_END":
+		setField(p.Fields, "username", firstNonEmpty(f["acct"], f["id"]))
+		setField(p.Fields, "source_ip", cleanAddr(f["addr"]))
+		setField(p.Fields, "source_hostname", cleanAddr(f["hostname"]))
+		setField(p.Fields, "result", f["res"])
+		if first.Type == "USER_LOGIN" || first.Type == "USER_AUTH" || first.Type == "USER_ERR" {
+			if f["res"] == "failed" {
+				p.EventType = "authentication_failed"
+				p.Severity = "medium"
+			} else {
+				p.EventType = "authentication_success"
+			}
+		}
+	}
+	return p
+}
+
+// execveCommandLine joins the a0..aN arguments of an EXECVE record
+func execveCommandLine(fields map[string]string) string {
+	argc, err := strconv.Atoi(fields["argc"])
+	if err != nil {
+		return ""
+	}
+	args := make([]string, 0, argc)
+	for i := 0; i < argc; i++ {
+		args = append(args, fields[fmt.Sprintf("a%d", i)])
+	}
+	return strings.Join(args, " ")
+}
+
+var avcPerm = regexp.MustCompile(`denied\s+\{([^}]*)\}`)
+
+func auditAVCPerm(raw string) string {
+	if m := avcPerm.FindStringSubmatch(raw); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// cleanAddr drops auditd's placeholder for unknown addresses
+func cleanAddr(v string) string {
+	if v == "?" {
+		return ""
+	}
+	return v
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" && v != "?" && v != "unset" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+package parsers
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// journalFields maps journal entry fields to normalized field names
+var journalFields = map[string]string{
+	"_HOSTNAME":         "host",
+	"SYSLOG_IDENTIFIER": "program",
+	"_SYSTEMD_UNIT":     "unit",
+	"_PID":              "pid",
+	"_UID":              "uid",
+	"_COMM":             "process_name",
+	"_EXE":              "exe",
+	"_CMDLINE":          "command_line",
+}
+
+// journalMessages recognise common security messages so journal entries are
+// usable by rules; named groups become fields
+var journalMessages = []struct {
+	eventType string
+	severity  string
+	pattern   *regexp.Regexp
+}{
+	{"authentication_failed", "medium", regexp.MustCompile(`^Failed (?:password|publickey) for (?:invalid user )?(?P<username>\S+) from (?P<source_ip>[0-9a-fA-F:.]+) port (?P<source_port>\d+)`)},
+	{"authentication_failed", "medium", regexp.MustCompile(`^Invalid user (?P<username>\S+) from (?P<source_ip>[0-9a-fA-F:.]+)`)},
+	{"authentication_success", "info", regexp.MustCompile(`^Accepted (?P<auth_method>\S+) for (?P<username>\S+) from (?P<source_ip>[0-9a-fA-F:.]+) port (?P<source_port>\d+)`)},
+	{"permission_denied", "medium", regexp.MustCompile(`^\s*(?P<username>\S+) : (?:user NOT in sudoers|command not allowed)`)},
+	{"authentication_failed", "medium", regexp.MustCompile(`pam_unix\((?P<service>[^:)]+):auth\): authentication failure;.*?(?:rhost=(?P<source_ip>[0-9a-fA-F:.]+))?\s*(?:user=(?P<username>\S+))?$`)},
+}
+
+// JournalEventType is the event type of journal entries that don't match a
+// known security message
+const JournalEventType = "syslog"
+
+// ParseJournalEntry translates a `journalctl -o json` entry. Entries without
+// a text MESSAGE (binary payloads) are skipped.
+func ParseJournalEntry(entry map[string]interface{}) (Parsed, bool) {
+	message, ok := entry["MESSAGE"].(string)
+	if !ok {
+		return Parsed{}, false
+	}
+
+	p := Parsed{
+		EventType: JournalEventType,
+		Severity:  journalSeverity(entry["PRIORITY"]),
+		Fields:    map[string]interface{}{"message": message},
+		Raw:       message,
+	}
+	for field, name := range journalFields {
+		if v, ok := entry[field].(string); ok {
+			setField(p.Fields, name, v)
+		}
+	}
+	if uid, ok := p.Fields["uid"].(string); ok {
+		if uid == "0" {
+			p.Fields["user"] = "root"
+		}
+	}
+
+	if usec, ok := entry["__REALTIME_TIMESTAMP"].(string); ok {
+		if n, err := strconv.ParseInt(usec, 10, 64); err == nil {
+			p.Timestamp = time.UnixMicro(n).UTC()
+		}
+	}
+
+	for _, m := range journalMessages {
+		match := m.pattern.FindStringSubmatch(message)
+		if match == nil {
+			continue
+		}
+		p.EventType = m.eventType
+		p.Severity = m.severity
+		for i, name := range m.pattern.SubexpNames() {
+			if name != "" {
+				setField(p.Fields, name, match[i])
+			}
+		}
+		break
+	}
+	return p, true
+}
+
+// journalSeverity maps syslog priorities (0 emerg .. 7 debug) to severities
+func journalSeverity(priority interface{}) string {
+	s, _ := priority.(string)
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return "info"
+	}
+	switch {
+	case n <= 2:
+		return "critical"
+	case n == 3:
+		return "high"
+	case n == 4:
+		return "medium"
+	case n == 5:
+		return "low"
+	default:
+		return "info"
+	}
+}
@@ -0,0 +1,22 @@
+// Package parsers translates raw host log formats (systemd journal entries,
+// auditd records) into normalized events. It is shared by the agent and the
+// server's upload endpoint so both produce the same fields.
+package parsers
+
+import "time"
+
+// Parsed is a normalized event produced by a parser
+type Parsed struct {
+	EventType string
+	Severity  string
+	Timestamp time.Time
+	Fields    map[string]interface{}
+	Raw       string
+}
+
+// setField stores a non-empty value
+func setField(fields map[string]interface{}, name, value string) {
+	if value != "" {
+		fields[name] = value
+	}
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// AcknowledgeIncident records that a responder has seen an incident. An
+// already acknowledged incident keeps its first acknowledgement.
+func AcknowledgeIncident(db *gorm.DB, incidentID, by string) (*models.Incident, error) {
+	var incident models.Incident
+	if err := db.First(&incident, "incident_id = ?", incidentID).Error; err != nil {
+		return nil, err
+	}
+
+	if incident.AcknowledgedAt != nil {
+		return &incident, nil
+	}
+
+	now := time.Now().UTC()
+	incident.AcknowledgedAt = &now
+	incident.AcknowledgedBy = &by
+	if err := db.Save(&incident).Error; err != nil {
+		return nil, fmt.Errorf("failed to acknowledge incident: %w", err)
+	}
+	return &incident, nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import "sort"
+
+// SideEffect classifies what running an action changes
+type SideEffect string
+
+const (
+	// SideEffectNone actions only log
+	SideEffectNone SideEffect = "none"
+	// SideEffectRead actions query other systems without changing them
+	SideEffectRead SideEffect = "read"
+	// SideEffectInternal actions change this system's own data
+	SideEffectInternal SideEffect = "internal"
+	// SideEffectExternal actions change or send to other systems
+	SideEffectExternal SideEffect = "external"
+)
+
+// ActionParameter describes one parameter an action accepts
+type ActionParameter struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"` // string, integer, number, boolean, object, array or any
+	Required    bool        `json:"required"`
+	Default     interface{} `json:"default,omitempty"`
+	Description string      `json:"description"`
+}
+
+// ActionOutput describes one field of an action's result. Name may be a
+// dotted path into nested objects (e.g. "json.ips").
+type ActionOutput struct {
+	Name        string      `json:"name" yaml:"name"`
+	Type        string      `json:"type" yaml:"type"`                       // as ActionParameter.Type
+	Items       string      `json:"items,omitempty" yaml:"items,omitempty"` // element type of arrays
+	Required    bool        `json:"required" yaml:"required"`
+	Default     interface{} `json:"default,omitempty" yaml:"default,omitempty"`
+	Description string      `json:"description" yaml:"description"`
+}
+
+// ActionDescriptor documents an action for playbook authors
+type ActionDescriptor struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Parameters  []ActionParameter `json:"parameters"`
+	SideEffect  SideEffect        `json:"side_effect"`
+	// Notification actions only inform people, so they keep running
+	// during a containment pause
+	Notification bool `json:"notification,omitempty"`
+
+	// Outputs is the result's schema; results are validated and normalized
+	// against it before later steps see them
+	Outputs []ActionOutput `json:"outputs"`
+
+	// ConcurrencyKey names the parameter concurrency limits are applied per
+	// (e.g. "host"); URL values are limited per domain
+	ConcurrencyKey string `json:"concurrency_key,omitempty"`
+}
+
+// Descriptor describes the action registered under name
+func (ar *ActionRegistry) Descriptor(name string) (ActionDescriptor, bool) {
+	action, ok := ar.actions[name]
+	if !ok {
+		return ActionDescriptor{}, false
+	}
+	return action.Describe(), true
+}
+
+// Catalog describes every registered action, ordered by name
+func (ar *ActionRegistry) Catalog() []ActionDescriptor {
+	catalog := make([]ActionDescriptor, 0, len(ar.actions))
+	for name, action := range ar.actions {
+		desc := action.Describe()
+		// The registered name is what playbooks reference
+		desc.Name = name
+		if desc.Parameters == nil {
+			desc.Parameters = []ActionParameter{}
+		}
+		if desc.Outputs == nil {
+			desc.Outputs = []ActionOutput{}
+		}
+		catalog = append(catalog, desc)
+	}
+	sort.Slice(catalog, func(i, j int) bool {
+		return catalog[i].Name < catalog[j].Name
+	})
+	return catalog
+}
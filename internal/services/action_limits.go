@@ -0,0 +1,184 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrActionQueueTimeout is returned when an action waits too long for a
+// concurrency slot
+var ErrActionQueueTimeout = fmt.Errorf("timed out waiting for action concurrency slot")
+
+// ActionLimiter caps how many actions of a type run at once against the same
+// target (e.g. SSH host or HTTP domain); excess calls queue until a slot
+// frees or the queue timeout passes
+type ActionLimiter struct {
+	mu      sync.Mutex
+	limits  map[string]int
+	timeout time.Duration
+	slots   map[string]*actionSlot
+}
+
+// actionSlot is the semaphore and counters for one action type and target
+type actionSlot struct {
+	action    string
+	target    string
+	sem       chan struct{}
+	queued    int
+	completed int64
+	timedOut  int64
+	waitTotal time.Duration
+	waitMax   time.Duration
+}
+
+// ActionQueueStats reports one action type and target's queue
+type ActionQueueStats struct {
+	Action    string  `json:"action"`
+	Target    string  `json:"target"`
+	Limit     int     `json:"limit"`
+	Active    int     `json:"active"`
+	Queued    int     `json:"queued"`
+	Completed int64   `json:"completed"`
+	TimedOut  int64   `json:"timed_out"`
+	AvgWaitMS float64 `json:"avg_wait_ms"`
+	MaxWaitMS float64 `json:"max_wait_ms"`
+}
+
+// NewActionLimiter creates a limiter from per-action limits; actions without
+// a limit are not restricted
+func NewActionLimiter(limits map[string]int, timeout time.Duration) *ActionLimiter {
+	return &ActionLimiter{
+		limits:  limits,
+		timeout: timeout,
+		slots:   make(map[string]*actionSlot),
+	}
+}
+
+// ParseActionLimits parses "ssh_command=2,http_request=5"
+func ParseActionLimits(spec string) (map[string]int, error) {
+	return parseLimits(spec, "action limit")
+}
+
+// parseLimits parses comma-separated name=limit pairs with positive limits;
+// kind names them in errors
+func parseLimits(spec, kind string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid %s %q: expected name=limit", kind, part)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("invalid %s %q: limit must be a positive integer", kind, part)
+		}
+		limits[strings.TrimSpace(name)] = limit
+	}
+	return limits, nil
+}
+
+// Acquire waits for a slot for the action against its target and returns
+// the function that releases it
+func (l *ActionLimiter) Acquire(action string, target string) (func(), error) {
+	limit, ok := l.limits[action]
+	if !ok {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	key := action + "|" + target
+	slot, ok := l.slots[key]
+	if !ok {
+		slot = &actionSlot{
+			action: action,
+			target: target,
+			sem:    make(chan struct{}, limit),
+		}
+		l.slots[key] = slot
+	}
+	slot.queued++
+	l.mu.Unlock()
+
+	start := time.Now()
+	timer := time.NewTimer(l.timeout)
+	defer timer.Stop()
+
+	select {
+	case slot.sem <- struct{}{}:
+	case <-timer.C:
+		l.mu.Lock()
+		slot.queued--
+		slot.timedOut++
+		l.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s on %q after %s", ErrActionQueueTimeout, action, target, l.timeout)
+	}
+
+	wait := time.Since(start)
+	l.mu.Lock()
+	slot.queued--
+	slot.waitTotal += wait
+	if wait > slot.waitMax {
+		slot.waitMax = wait
+	}
+	l.mu.Unlock()
+
+	return func() {
+		<-slot.sem
+		l.mu.Lock()
+		slot.completed++
+		l.mu.Unlock()
+	}, nil
+}
+
+// Stats reports every action type and target that has been limited
+func (l *ActionLimiter) Stats() []ActionQueueStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := make([]ActionQueueStats, 0, len(l.slots))
+	for _, slot := range l.slots {
+		s := ActionQueueStats{
+			Action:    slot.action,
+			Target:    slot.target,
+			Limit:     cap(slot.sem),
+			Active:    len(slot.sem),
+			Queued:    slot.queued,
+			Completed: slot.completed,
+			TimedOut:  slot.timedOut,
+			MaxWaitMS: float64(slot.waitMax) / float64(time.Millisecond),
+		}
+		if started := slot.completed + int64(len(slot.sem)); started > 0 {
+			s.AvgWaitMS = float64(slot.waitTotal) / float64(started) / float64(time.Millisecond)
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Action != stats[j].Action {
+			return stats[i].Action < stats[j].Action
+		}
+		return stats[i].Target < stats[j].Target
+	})
+	return stats
+}
+
+// concurrencyTarget resolves the parameter named by an action's
+// ConcurrencyKey; URLs are reduced to their host so limits apply per domain
+func concurrencyTarget(desc ActionDescriptor, params map[string]interface{}) string {
+	if desc.ConcurrencyKey == "" {
+		return ""
+	}
+	value := getStringParam(params, desc.ConcurrencyKey, "")
+	if u, err := url.Parse(value); err == nil && u.Host != "" {
+		return u.Hostname()
+	}
+	return value
+}
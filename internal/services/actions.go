@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -9,32 +10,50 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/store"
 )
 
 // Action interface defines the contract for all actions
 type Action interface {
 	Execute(params map[string]interface{}) (interface{}, error)
+	// Describe documents the action's parameters and side effects
+	Describe() ActionDescriptor
+}
+
+// ContextAction is implemented by actions that use the execution context,
+// for cancellation or the playbook step they run for
+type ContextAction interface {
+	Action
+	ExecuteContext(ctx context.Context, params map[string]interface{}) (interface{}, error)
 }
 
 // ActionRegistry manages available actions
 type ActionRegistry struct {
 	db      *gorm.DB
+	logs    store.ActionLogStore
+	limiter *ActionLimiter
+	quotas  *ExecutionQuotas
+	flags   *FeatureFlags
 	actions map[string]Action
 }
 
-// NewActionRegistry creates a new action registry
-func NewActionRegistry(db *gorm.DB) *ActionRegistry {
+// NewActionRegistry creates a new action registry recording executions in
+// logs
+func NewActionRegistry(db *gorm.DB, logs store.ActionLogStore, limiter *ActionLimiter) *ActionRegistry {
 	registry := &ActionRegistry{
 		db:      db,
+		logs:    logs,
+		limiter: limiter,
 		actions: make(map[string]Action),
 	}
 
 	// Register all MVP actions
-	registry.Register("create_incident", &CreateIncidentAction{db: db})
+	incidents := store.NewGormIncidents(db)
+	registry.Register("create_incident", &CreateIncidentAction{incidents: incidents})
 	registry.Register("notify", &NotifyAction{db: db})
 	registry.Register("block_ip", &BlockIPAction{db: db})
 	registry.Register("log_action", &LogActionAction{db: db})
-	registry.Register("update_incident", &UpdateIncidentAction{db: db})
+	registry.Register("update_incident", &UpdateIncidentAction{incidents: incidents})
 
 	// Register advanced actions for real-world playbooks
 	registry.Register("ssh_command", &SSHCommandAction{db: db})
@@ -46,7 +65,6 @@ func NewActionRegistry(db *gorm.DB) *ActionRegistry {
 	registry.Register("http_request", &HTTPRequestAction{db: db})
 	registry.Register("shell_script", &ShellScriptAction{db: db})
 	registry.Register("webhook", &WebhookAction{db: db})
-	registry.Register("python_script", &PythonScriptAction{db: db})
 
 	return registry
 }
@@ -57,26 +75,92 @@ func (ar *ActionRegistry) Register(name string, action Action) {
 	log.Printf("Registered action: %s", name)
 }
 
+// SetQuotas limits the actions each rule and tenant's playbook runs may
+// execute per hour
+func (ar *ActionRegistry) SetQuotas(quotas *ExecutionQuotas) {
+	ar.quotas = quotas
+}
+
+// SetFeatureFlags provides the containment_pause flag
+func (ar *ActionRegistry) SetFeatureFlags(flags *FeatureFlags) {
+	ar.flags = flags
+}
+
+// Remediation reports whether an action is auto-remediation: it has
+// external side effects and isn't a notification
+func (ar *ActionRegistry) Remediation(actionType string) bool {
+	action, ok := ar.actions[actionType]
+	if !ok {
+		return false
+	}
+	desc := action.Describe()
+	return desc.SideEffect == SideEffectExternal && !desc.Notification
+}
+
+// Contained reports whether a containment pause holds the action back: it
+// is auto-remediation and the containment_pause flag is on
+func (ar *ActionRegistry) Contained(actionType string) bool {
+	return ar.Remediation(actionType) && ar.flags.Enabled(FlagContainmentPause)
+}
+
+// QueueStats reports concurrency-limited action queues
+func (ar *ActionRegistry) QueueStats() []ActionQueueStats {
+	return ar.limiter.Stats()
+}
+
+// Has reports whether an action is registered under name
+func (ar *ActionRegistry) Has(name string) bool {
+	_, ok := ar.actions[name]
+	return ok
+}
+
 // Execute executes an action by name
 func (ar *ActionRegistry) Execute(actionType string, params map[string]interface{}) (interface{}, error) {
+	return ar.ExecuteContext(context.Background(), actionType, params)
+}
+
+// ExecuteContext executes an action by name, recording the context's request
+// ID on its action log
+func (ar *ActionRegistry) ExecuteContext(ctx context.Context, actionType string, params map[string]interface{}) (interface{}, error) {
+	result, _, err := ar.ExecuteLogged(ctx, actionType, params)
+	return result, err
+}
+
+// ExecuteLogged executes an action like ExecuteContext and also returns the
+// ID of its action log, which records the playbook step in ctx, if any
+func (ar *ActionRegistry) ExecuteLogged(ctx context.Context, actionType string, params map[string]interface{}) (interface{}, string, error) {
 	action, ok := ar.actions[actionType]
 	if !ok {
-		return nil, fmt.Errorf("unknown action type: %s", actionType)
+		return nil, "", fmt.Errorf("unknown action type: %s", actionType)
+	}
+	if step, ok := StepRunFrom(ctx); ok {
+		if err := ar.quotas.CheckAction(step.Scope, actionType, action.Describe().SideEffect); err != nil {
+			ar.quotas.Raise(err, map[string]interface{}{"playbook_id": step.PlaybookID, "run_id": step.RunID, "action": actionType})
+			return nil, "", err
+		}
+	}
+	if action.Describe().Notification {
+		params = withFreezeNotice(ar.db, params)
 	}
-
-	startTime := time.Now()
 
 	// Log action start
-	paramsJSON, _ := json.Marshal(params)
-	actionLog := &models.ActionLog{
-		ActionType: actionType,
-		Status:     models.ActionRunning,
-		Parameters: string(paramsJSON),
-	}
-	ar.db.Create(actionLog)
+	actionLog := newActionLog(ctx, actionType, params)
+	actionLog.Status = models.ActionRunning
+	ar.logs.Save(ctx, actionLog)
 
-	// Execute action
-	result, err := action.Execute(params)
+	// Wait for a concurrency slot, then execute action
+	startTime := time.Now()
+	var result interface{}
+	release, err := ar.limiter.Acquire(actionType, concurrencyTarget(action.Describe(), params))
+	if err == nil {
+		startTime = time.Now()
+		if contextAction, ok := action.(ContextAction); ok {
+			result, err = contextAction.ExecuteContext(ctx, params)
+		} else {
+			result, err = action.Execute(params)
+		}
+		release()
+	}
 
 	// Update action log
 	executionTime := int(time.Since(startTime).Milliseconds())
@@ -97,14 +181,62 @@ func (ar *ActionRegistry) Execute(actionType string, params map[string]interface
 		}
 	}
 
-	ar.db.Save(actionLog)
+	ar.logs.SaveAsync(ctx, actionLog)
 
-	return result, err
+	return result, actionLog.ActionID, err
+}
+
+// LogHeld records an action that was held back rather than executed, as
+// ActionDryRun or ActionAwaitingApproval, with the result that stands in for
+// it, and returns the log's ID
+func (ar *ActionRegistry) LogHeld(ctx context.Context, actionType string, status models.ActionStatus, params map[string]interface{}, result interface{}, note string) string {
+	actionLog := newActionLog(ctx, actionType, params)
+	actionLog.Status = status
+	now := time.Now()
+	actionLog.CompletedAt = &now
+	actionLog.Notes = note
+	if result != nil {
+		resultJSON, _ := json.Marshal(result)
+		resultStr := string(resultJSON)
+		actionLog.Result = &resultStr
+	}
+	ar.logs.Save(ctx, actionLog)
+	return actionLog.ActionID
+}
+
+// newActionLog starts the log of an action, recording the request and
+// playbook step in ctx
+func newActionLog(ctx context.Context, actionType string, params map[string]interface{}) *models.ActionLog {
+	paramsJSON, _ := json.Marshal(params)
+	actionLog := &models.ActionLog{
+		ActionType: actionType,
+		Parameters: string(paramsJSON),
+		RequestID:  requestIDPtr(ctx),
+	}
+	if incidentID := getStringParam(params, "incident_id", ""); incidentID != "" {
+		actionLog.IncidentID = &incidentID
+	}
+	if step, ok := StepRunFrom(ctx); ok {
+		actionLog.PlaybookID = &step.PlaybookID
+		actionLog.StepID = &step.StepID
+		actionLog.RunID = &step.RunID
+		if actionLog.IncidentID == nil && step.IncidentID != "" {
+			actionLog.IncidentID = &step.IncidentID
+		}
+	}
+	return actionLog
 }
 
 // CreateIncidentAction creates a new incident
 type CreateIncidentAction struct {
-	db *gorm.DB
+	incidents  store.IncidentStore
+	severities *SeverityMatrix
+}
+
+// NewCreateIncidentAction creates a create_incident action that derives
+// severity from impact and urgency with matrix
+func NewCreateIncidentAction(incidents store.IncidentStore, matrix *SeverityMatrix) *CreateIncidentAction {
+	return &CreateIncidentAction{incidents: incidents, severities: matrix}
 }
 
 func (a *CreateIncidentAction) Execute(params map[string]interface{}) (interface{}, error) {
@@ -122,16 +254,24 @@ func (a *CreateIncidentAction) Execute(params map[string]interface{}) (interface
 	case "low":
 		severity = models.SeverityLow
 	}
+	impact := getIntParam(params, "impact", 0)
+	urgency := getIntParam(params, "urgency", 0)
+	severity, err := deriveSeverity(a.severities, impact, urgency, severity)
+	if err != nil {
+		return nil, err
+	}
 
 	incident := &models.Incident{
 		Status:      models.StatusOpen,
 		Severity:    severity,
+		Impact:      impact,
+		Urgency:     urgency,
 		Category:    category,
 		Title:       title,
 		Description: description,
 	}
 
-	if err := a.db.Create(incident).Error; err != nil {
+	if err := a.incidents.Create(context.Background(), incident); err != nil {
 		return nil, fmt.Errorf("failed to create incident: %w", err)
 	}
 
@@ -139,6 +279,25 @@ func (a *CreateIncidentAction) Execute(params map[string]interface{}) (interface
 	return map[string]string{"incident_id": incident.IncidentID}, nil
 }
 
+func (a *CreateIncidentAction) Describe() ActionDescriptor {
+	return ActionDescriptor{
+		Name:        "create_incident",
+		Description: "Create a new open incident",
+		Parameters: []ActionParameter{
+			{Name: "title", Type: "string", Default: "Automated Incident", Description: "Incident title"},
+			{Name: "description", Type: "string", Description: "Incident description"},
+			{Name: "priority", Type: "string", Default: "medium", Description: "Severity: critical, high, medium or low"},
+			{Name: "impact", Type: "integer", Description: "Impact score; with urgency, derives severity from the severity matrix instead of priority"},
+			{Name: "urgency", Type: "integer", Description: "Urgency score, given with impact"},
+			{Name: "category", Type: "string", Description: "Incident category"},
+		},
+		Outputs: []ActionOutput{
+			{Name: "incident_id", Type: "string", Required: true, Description: "ID of the new incident"},
+		},
+		SideEffect: SideEffectInternal,
+	}
+}
+
 // NotifyAction sends a notification
 type NotifyAction struct {
 	db *gorm.DB
@@ -159,6 +318,24 @@ func (a *NotifyAction) Execute(params map[string]interface{}) (interface{}, erro
 	}, nil
 }
 
+func (a *NotifyAction) Describe() ActionDescriptor {
+	return ActionDescriptor{
+		Name:        "notify",
+		Description: "Send a notification to a channel",
+		Parameters: []ActionParameter{
+			{Name: "channel", Type: "string", Default: "console", Description: "Destination channel, e.g. console, slack or pagerduty"},
+			{Name: "message", Type: "string", Default: "Notification", Description: "Notification text"},
+		},
+		Outputs: []ActionOutput{
+			{Name: "channel", Type: "string", Description: "Channel notified"},
+			{Name: "message", Type: "string", Description: "Text sent"},
+			{Name: "status", Type: "string", Description: "Delivery status"},
+		},
+		SideEffect:   SideEffectExternal,
+		Notification: true,
+	}
+}
+
 // BlockIPAction simulates blocking an IP address
 type BlockIPAction struct {
 	db *gorm.DB
@@ -184,6 +361,24 @@ func (a *BlockIPAction) Execute(params map[string]interface{}) (interface{}, err
 	}, nil
 }
 
+func (a *BlockIPAction) Describe() ActionDescriptor {
+	return ActionDescriptor{
+		Name:        "block_ip",
+		Description: "Block an IP address (simulated)",
+		Parameters: []ActionParameter{
+			{Name: "ip_address", Type: "string", Required: true, Description: "Address to block"},
+			{Name: "duration", Type: "integer", Default: 3600, Description: "Block duration in seconds"},
+		},
+		Outputs: []ActionOutput{
+			{Name: "ip_address", Type: "string", Required: true, Description: "Address blocked"},
+			{Name: "duration", Type: "integer", Description: "Block duration in seconds"},
+			{Name: "action", Type: "string", Description: "Action taken"},
+			{Name: "simulated", Type: "boolean", Description: "Whether the block was only logged"},
+		},
+		SideEffect: SideEffectExternal,
+	}
+}
+
 // LogActionAction logs detailed activity
 type LogActionAction struct {
 	db *gorm.DB
@@ -201,9 +396,32 @@ func (a *LogActionAction) Execute(params map[string]interface{}) (interface{}, e
 	}, nil
 }
 
+func (a *LogActionAction) Describe() ActionDescriptor {
+	return ActionDescriptor{
+		Name:        "log_action",
+		Description: "Write a message to the server log",
+		Parameters: []ActionParameter{
+			{Name: "message", Type: "string", Description: "Message to log"},
+			{Name: "level", Type: "string", Default: "info", Description: "Log level label"},
+		},
+		Outputs: []ActionOutput{
+			{Name: "logged", Type: "boolean", Description: "Whether the message was logged"},
+			{Name: "level", Type: "string", Description: "Log level label"},
+		},
+		SideEffect: SideEffectNone,
+	}
+}
+
 // UpdateIncidentAction updates an incident's status or metadata
 type UpdateIncidentAction struct {
-	db *gorm.DB
+	incidents store.IncidentStore
+	workflows *Workflows
+}
+
+// NewUpdateIncidentAction creates an update_incident action whose status
+// changes follow the incident workflows
+func NewUpdateIncidentAction(incidents store.IncidentStore, workflows *Workflows) *UpdateIncidentAction {
+	return &UpdateIncidentAction{incidents: incidents, workflows: workflows}
 }
 
 func (a *UpdateIncidentAction) Execute(params map[string]interface{}) (interface{}, error) {
@@ -212,14 +430,18 @@ func (a *UpdateIncidentAction) Execute(params map[string]interface{}) (interface
 		return nil, fmt.Errorf("incident_id parameter is required")
 	}
 
-	var incident models.Incident
-	if err := a.db.First(&incident, "incident_id = ?", incidentID).Error; err != nil {
+	incident, err := a.incidents.Get(context.Background(), incidentID)
+	if err != nil {
 		return nil, fmt.Errorf("incident not found: %w", err)
 	}
 
 	// Update status if provided
+	previous := incident.Status
 	if status, ok := params["status"].(string); ok {
-		incident.Status = models.IncidentStatus(status)
+		incident.SetStatus(models.IncidentStatus(status), "playbook")
+	}
+	if resolution, ok := params["resolution"].(string); ok {
+		incident.Resolution = resolution
 	}
 
 	// Update notes if provided
@@ -236,7 +458,10 @@ func (a *UpdateIncidentAction) Execute(params map[string]interface{}) (interface
 		incident.AssignedTo = &assignedTo
 	}
 
-	if err := a.db.Save(&incident).Error; err != nil {
+	if err := a.workflows.CheckTransition(incident, previous); err != nil {
+		return nil, err
+	}
+	if err := a.incidents.Save(context.Background(), incident); err != nil {
 		return nil, fmt.Errorf("failed to update incident: %w", err)
 	}
 
@@ -244,6 +469,25 @@ func (a *UpdateIncidentAction) Execute(params map[string]interface{}) (interface
 	return map[string]string{"incident_id": incidentID, "status": "updated"}, nil
 }
 
+func (a *UpdateIncidentAction) Describe() ActionDescriptor {
+	return ActionDescriptor{
+		Name:        "update_incident",
+		Description: "Update an incident's status, resolution, notes or assignee",
+		Parameters: []ActionParameter{
+			{Name: "incident_id", Type: "string", Required: true, Description: "Incident to update"},
+			{Name: "status", Type: "string", Description: "New status, as allowed by the incident's workflow"},
+			{Name: "resolution", Type: "string", Description: "Resolution classification"},
+			{Name: "notes", Type: "string", Description: "Notes appended to the incident"},
+			{Name: "assigned_to", Type: "string", Description: "New assignee"},
+		},
+		Outputs: []ActionOutput{
+			{Name: "incident_id", Type: "string", Required: true, Description: "Incident updated"},
+			{Name: "status", Type: "string", Description: "Update status"},
+		},
+		SideEffect: SideEffectInternal,
+	}
+}
+
 // Helper functions to extract parameters
 
 func getStringParam(params map[string]interface{}, key, defaultValue string) string {
@@ -293,6 +537,27 @@ func (a *SSHCommandAction) Execute(params map[string]interface{}) (interface{},
 	}, nil
 }
 
+func (a *SSHCommandAction) Describe() ActionDescriptor {
+	return ActionDescriptor{
+		Name:        "ssh_command",
+		Description: "Run a command on a remote host over SSH (simulated)",
+		Parameters: []ActionParameter{
+			{Name: "host", Type: "string", Required: true, Description: "Target host"},
+			{Name: "command", Type: "string", Required: true, Description: "Command to run"},
+			{Name: "description", Type: "string", Description: "Why the command is run"},
+		},
+		Outputs: []ActionOutput{
+			{Name: "host", Type: "string", Description: "Target host"},
+			{Name: "command", Type: "string", Description: "Command run"},
+			{Name: "output", Type: "string", Description: "Command output"},
+			{Name: "exit_code", Type: "integer", Description: "Exit code"},
+			{Name: "simulated", Type: "boolean", Description: "Whether the command was only logged"},
+		},
+		SideEffect:     SideEffectExternal,
+		ConcurrencyKey: "host",
+	}
+}
+
 // GrafanaQueryAction queries Grafana dashboards
 type GrafanaQueryAction struct {
 	db *gorm.DB
@@ -317,6 +582,24 @@ func (a *GrafanaQueryAction) Execute(params map[string]interface{}) (interface{}
 	}, nil
 }
 
+func (a *GrafanaQueryAction) Describe() ActionDescriptor {
+	return ActionDescriptor{
+		Name:        "grafana_query",
+		Description: "Query a Grafana dashboard metric (simulated)",
+		Parameters: []ActionParameter{
+			{Name: "dashboard", Type: "string", Description: "Dashboard to query"},
+			{Name: "environment", Type: "string", Default: "prod", Description: "Environment"},
+			{Name: "metric", Type: "string", Description: "Metric name"},
+		},
+		Outputs: []ActionOutput{
+			{Name: "value", Type: "number", Description: "Metric value"},
+			{Name: "trend", Type: "string", Description: "Metric trend"},
+			{Name: "simulated", Type: "boolean", Description: "Whether the value is simulated"},
+		},
+		SideEffect: SideEffectRead,
+	}
+}
+
 // PrometheusQueryAction queries Prometheus
 type PrometheusQueryAction struct {
 	db *gorm.DB
@@ -337,6 +620,23 @@ func (a *PrometheusQueryAction) Execute(params map[string]interface{}) (interfac
 	}, nil
 }
 
+func (a *PrometheusQueryAction) Describe() ActionDescriptor {
+	return ActionDescriptor{
+		Name:        "prometheus_query",
+		Description: "Run a PromQL query (simulated)",
+		Parameters: []ActionParameter{
+			{Name: "host", Type: "string", Description: "Prometheus host"},
+			{Name: "query", Type: "string", Description: "PromQL expression"},
+		},
+		Outputs: []ActionOutput{
+			{Name: "alerts", Type: "array", Items: "string", Description: "Firing alerts"},
+			{Name: "simulated", Type: "boolean", Description: "Whether the result is simulated"},
+		},
+		SideEffect:     SideEffectRead,
+		ConcurrencyKey: "host",
+	}
+}
+
 // AIAnalyzeAction uses Claude API for intelligent incident analysis
 type AIAnalyzeAction struct {
 	db *gorm.DB
@@ -379,3 +679,22 @@ func (a *AIAnalyzeAction) Execute(params map[string]interface{}) (interface{}, e
 		"note":           "Implement real Claude API integration for production use",
 	}, nil
 }
+
+func (a *AIAnalyzeAction) Describe() ActionDescriptor {
+	return ActionDescriptor{
+		Name:        "ai_analyze",
+		Description: "Analyze incident context with an LLM (simulated)",
+		Parameters: []ActionParameter{
+			{Name: "context", Type: "string", Required: true, Description: "Text to analyze"},
+			{Name: "incident_id", Type: "string", Description: "Incident being analyzed"},
+			{Name: "model", Type: "string", Default: "claude-sonnet-4", Description: "Model name"},
+		},
+		Outputs: []ActionOutput{
+			{Name: "root_cause", Type: "string", Description: "Likely root cause"},
+			{Name: "recommendation", Type: "string", Description: "Recommended remediation"},
+			{Name: "confidence", Type: "number", Description: "Confidence from 0 to 1"},
+			{Name: "reasoning", Type: "string", Description: "Explanation"},
+		},
+		SideEffect: SideEffectRead,
+	}
+}
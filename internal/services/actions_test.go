@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/store"
+)
+
+func loadTestWorkflows(t *testing.T) *Workflows {
+	t.Helper()
+	workflows, err := LoadWorkflows(filepath.Join(t.TempDir(), "workflows.yaml"))
+	if err != nil {
+		t.Fatalf("failed to load workflows: %v", err)
+	}
+	return workflows
+}
+
+func TestCreateIncidentAction(t *testing.T) {
+	tests := []struct {
+		name         string
+		params       map[string]interface{}
+		wantSeverity models.SeverityLevel
+		wantErr      bool
+	}{
+		{"defaults", map[string]interface{}{}, models.SeverityMedium, false},
+		{"priority", map[string]interface{}{"priority": "critical", "title": "Brute force on alice"}, models.SeverityCritical, false},
+		{"impact without urgency", map[string]interface{}{"impact": 2}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			incidents := store.NewMemoryIncidents()
+			result, err := NewCreateIncidentAction(incidents, nil).Execute(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			id := result.(map[string]string)["incident_id"]
+			incident, err := incidents.Get(context.Background(), id)
+			if err != nil {
+				t.Fatalf("created incident %q not stored: %v", id, err)
+			}
+			if incident.Status != models.StatusOpen || incident.Severity != tt.wantSeverity {
+				t.Errorf("incident status %s severity %s, want open %s", incident.Status, incident.Severity, tt.wantSeverity)
+			}
+		})
+	}
+}
+
+func TestUpdateIncidentAction(t *testing.T) {
+	ctx := context.Background()
+	incidents := store.NewMemoryIncidents()
+	incident := &models.Incident{Status: models.StatusOpen, Severity: models.SeverityHigh, Title: "Password spray", Notes: "seen by detection"}
+	if err := incidents.Create(ctx, incident); err != nil {
+		t.Fatal(err)
+	}
+	action := NewUpdateIncidentAction(incidents, loadTestWorkflows(t))
+
+	_, err := action.Execute(map[string]interface{}{
+		"incident_id": incident.IncidentID,
+		"status":      "investigating",
+		"notes":       "blocked 203.0.113.5",
+		"assigned_to": "oncall",
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	updated, _ := incidents.Get(ctx, incident.IncidentID)
+	if updated.Status != models.StatusInvestigating {
+		t.Errorf("status = %s, want investigating", updated.Status)
+	}
+	if updated.Notes != "seen by detection\nblocked 203.0.113.5" {
+		t.Errorf("notes = %q", updated.Notes)
+	}
+	if updated.AssignedTo == nil || *updated.AssignedTo != "oncall" {
+		t.Errorf("assigned_to = %v, want oncall", updated.AssignedTo)
+	}
+
+	// A status outside the workflow is refused and nothing is saved
+	_, err = action.Execute(map[string]interface{}{"incident_id": incident.IncidentID, "status": "bogus", "notes": "dropped"})
+	var workflowErr *WorkflowError
+	if !errors.As(err, &workflowErr) {
+		t.Fatalf("Execute() error = %v, want a workflow error", err)
+	}
+	unchanged, _ := incidents.Get(ctx, incident.IncidentID)
+	if unchanged.Status != models.StatusInvestigating || unchanged.Notes != updated.Notes {
+		t.Errorf("refused update was saved: status %s, notes %q", unchanged.Status, unchanged.Notes)
+	}
+
+	if _, err := action.Execute(map[string]interface{}{"incident_id": "missing"}); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Execute() on a missing incident error = %v, want ErrNotFound", err)
+	}
+	if _, err := action.Execute(map[string]interface{}{}); err == nil {
+		t.Error("Execute() without incident_id succeeded")
+	}
+}
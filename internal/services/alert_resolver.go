@@ -0,0 +1,178 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// Normalized fields that tie an event to an upstream alert
+const (
+	AlertFingerprintField = "alert_fingerprint"
+	AlertSourceField      = "alert_source"
+	AlertNameField        = "alertname"
+)
+
+// linkIncidentAlert records that an incident was raised or updated from an
+// upstream alert, so the alert's resolution can close it. Events without an
+// alert fingerprint are ignored.
+func linkIncidentAlert(tx *gorm.DB, incident *models.Incident, normalized map[string]interface{}) error {
+	fingerprint, _ := normalized[AlertFingerprintField].(string)
+	if fingerprint == "" {
+		return nil
+	}
+	source, _ := normalized[AlertSourceField].(string)
+	alertName, _ := normalized[AlertNameField].(string)
+
+	link := &models.IncidentAlert{
+		IncidentID:  incident.IncidentID,
+		Source:      source,
+		Fingerprint: fingerprint,
+		AlertName:   alertName,
+		Status:      models.AlertFiring,
+	}
+	// A re-fired alert reopens its link
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "incident_id"}, {Name: "source"}, {Name: "fingerprint"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"status": models.AlertFiring, "resolved_at": nil, "updated_at": time.Now().UTC()}),
+	}).Create(link).Error; err != nil {
+		return fmt.Errorf("failed to link incident to alert: %w", err)
+	}
+	return nil
+}
+
+// AlertResolution reports what happened to one incident when an alert resolved
+type AlertResolution struct {
+	IncidentID string `json:"incident_id"`
+	Resolved   bool   `json:"resolved"`
+}
+
+// AlertResolver applies upstream alert resolutions to linked incidents
+type AlertResolver struct {
+	db          *gorm.DB
+	engine      *DetectionEngine
+	flags       *FeatureFlags
+	autoResolve atomic.Bool
+}
+
+// NewAlertResolver creates a resolver; autoResolve is the default for rules
+// that don't set auto_resolve
+func NewAlertResolver(db *gorm.DB, engine *DetectionEngine, flags *FeatureFlags, autoResolve bool) *AlertResolver {
+	resolver := &AlertResolver{
+		db:     db,
+		engine: engine,
+		flags:  flags,
+	}
+	resolver.autoResolve.Store(autoResolve)
+	return resolver
+}
+
+// SetAutoResolve changes the default for rules that don't set auto_resolve
+func (r *AlertResolver) SetAutoResolve(autoResolve bool) {
+	r.autoResolve.Store(autoResolve)
+}
+
+// Resolve marks an alert resolved on every unresolved incident linked to it.
+// An incident whose linked alerts have all resolved is resolved when its
+// rule allows auto-resolve; either way a note records the upstream resolution.
+func (r *AlertResolver) Resolve(source, fingerprint string, endsAt time.Time) ([]AlertResolution, error) {
+	var links []models.IncidentAlert
+	if err := r.db.Where("source = ? AND fingerprint = ? AND status = ?", source, fingerprint, models.AlertFiring).
+		Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("failed to find linked incidents: %w", err)
+	}
+
+	if endsAt.IsZero() {
+		endsAt = time.Now().UTC()
+	}
+
+	resolutions := make([]AlertResolution, 0, len(links))
+	for _, link := range links {
+		resolution, err := r.resolveLink(link, endsAt)
+		if err != nil {
+			return resolutions, err
+		}
+		if resolution != nil {
+			resolutions = append(resolutions, *resolution)
+		}
+	}
+	return resolutions, nil
+}
+
+// resolveLink resolves one incident's link to the alert
+func (r *AlertResolver) resolveLink(link models.IncidentAlert, endsAt time.Time) (*AlertResolution, error) {
+	var resolution *AlertResolution
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&link).Updates(map[string]interface{}{
+			"status":      models.AlertResolved,
+			"resolved_at": endsAt,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update alert link: %w", err)
+		}
+
+		var incident models.Incident
+		if err := tx.First(&incident, "incident_id = ?", link.IncidentID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return fmt.Errorf("failed to fetch incident: %w", err)
+		}
+		if incident.Status == models.StatusResolved {
+			return nil
+		}
+
+		var firing int64
+		if err := tx.Model(&models.IncidentAlert{}).
+			Where("incident_id = ? AND status = ?", incident.IncidentID, models.AlertFiring).
+			Count(&firing).Error; err != nil {
+			return fmt.Errorf("failed to count firing alerts: %w", err)
+		}
+
+		name := link.AlertName
+		if name == "" {
+			name = "alert"
+		}
+		note := fmt.Sprintf("Upstream %s %s (%s) resolved at %s", link.Source, name, link.Fingerprint, endsAt.UTC().Format(time.RFC3339))
+
+		resolution = &AlertResolution{IncidentID: incident.IncidentID}
+		switch {
+		case firing > 0:
+			note += fmt.Sprintf("; %d linked alert(s) still firing", firing)
+		case !r.flags.Enabled(FlagAutoClose):
+			note += "; auto-close disabled by feature flag, left open"
+		case r.shouldAutoResolve(incident):
+			incident.SetStatus(models.StatusResolved, link.Source)
+			resolution.Resolved = true
+			note += "; incident auto-resolved"
+		default:
+			note += "; auto-resolve disabled, left open"
+		}
+
+		if incident.Notes != "" {
+			incident.Notes += "\n" + note
+		} else {
+			incident.Notes = note
+		}
+		if err := tx.Save(&incident).Error; err != nil {
+			return fmt.Errorf("failed to update incident: %w", err)
+		}
+
+		log.Printf("Incident %s: %s", incident.IncidentID, note)
+		return nil
+	})
+	return resolution, err
+}
+
+// shouldAutoResolve applies the incident's rule override or the default
+func (r *AlertResolver) shouldAutoResolve(incident models.Incident) bool {
+	if override := r.engine.RuleAutoResolve(incident.TriggeredByRule); override != nil {
+		return *override
+	}
+	return r.autoResolve.Load()
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// apiAccessExportPage is how many records an export reads at a time
+const apiAccessExportPage = 500
+
+// APIAccessQuery selects API access records; zero fields don't filter
+type APIAccessQuery struct {
+	Actor      string
+	Method     string
+	Route      string
+	Result     string
+	ResourceID string // matches any of a record's resource IDs
+	Status     int
+	From       time.Time
+	To         time.Time
+	After      uint // only records with a higher ID, for paging
+	Limit      int
+}
+
+// APIAccessLog records every API request so use of the platform itself can
+// be audited. Records are written through the batch writer, off the request
+// path.
+type APIAccessLog struct {
+	db     *gorm.DB
+	reads  *gorm.DB
+	writer *database.BatchWriter
+}
+
+// NewAPIAccessLog creates the API access log. Listings and exports are read
+// from reads.
+func NewAPIAccessLog(db, reads *gorm.DB, writer *database.BatchWriter) *APIAccessLog {
+	return &APIAccessLog{db: db, reads: reads, writer: writer}
+}
+
+// Record queues a request's record
+func (l *APIAccessLog) Record(access *models.APIAccess) {
+	l.writer.WriteAsync(access)
+}
+
+// List returns the records q selects, oldest first
+func (l *APIAccessLog) List(q APIAccessQuery) ([]models.APIAccess, error) {
+	query := l.reads.Order("id ASC")
+	if q.Actor != "" {
+		query = query.Where("actor = ?", q.Actor)
+	}
+	if q.Method != "" {
+		query = query.Where("method = ?", q.Method)
+	}
+	if q.Route != "" {
+		query = query.Where("route = ?", q.Route)
+	}
+	if q.Result != "" {
+		query = query.Where("result = ?", q.Result)
+	}
+	if q.ResourceID != "" {
+		// resource_ids is a JSON object; match the ID as one of its values
+		quoted, _ := json.Marshal(q.ResourceID)
+		query = query.Where("resource_ids LIKE ?", "%:"+string(quoted)+"%")
+	}
+	if q.Status != 0 {
+		query = query.Where("status = ?", q.Status)
+	}
+	if !q.From.IsZero() {
+		query = query.Where("timestamp >= ?", q.From)
+	}
+	if !q.To.IsZero() {
+		query = query.Where("timestamp < ?", q.To)
+	}
+	if q.After > 0 {
+		query = query.Where("id > ?", q.After)
+	}
+	if q.Limit > 0 {
+		query = query.Limit(q.Limit)
+	}
+
+	records := []models.APIAccess{}
+	if err := query.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch API access log: %w", err)
+	}
+	return records, nil
+}
+
+// Export passes every record q selects to fn in order, a page at a time;
+// q.Limit and q.After are ignored
+func (l *APIAccessLog) Export(q APIAccessQuery, fn func(models.APIAccess) error) error {
+	q.After, q.Limit = 0, apiAccessExportPage
+	for {
+		records, err := l.List(q)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+		if len(records) < apiAccessExportPage {
+			return nil
+		}
+		q.After = records[len(records)-1].ID
+	}
+}
+
+// Prune deletes records older than retention
+func (l *APIAccessLog) Prune(retention time.Duration) error {
+	result := l.db.Where("timestamp < ?", time.Now().UTC().Add(-retention)).Delete(&models.APIAccess{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to prune API access log: %w", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+var (
+	// ErrApprovalNotFound is returned for an unknown approval
+	ErrApprovalNotFound = errors.New("approval not found")
+	// ErrApprovalDecided is returned when approving or rejecting an approval
+	// that was already decided
+	ErrApprovalDecided = errors.New("approval already decided")
+	// ErrContainmentPause is returned when approving an action the
+	// containment pause holds back
+	ErrContainmentPause = errors.New("containment pause in effect")
+	// ErrSelfApproval is returned when the principal that started a run
+	// approves one of its actions
+	ErrSelfApproval = errors.New("the principal that started the run may not approve its actions")
+	// ErrAlreadyDecided is returned when a responder decides the same
+	// approval twice
+	ErrAlreadyDecided = errors.New("approval already decided by this principal")
+)
+
+// ApprovalGates hold playbook actions back until enough responders approve
+// them. Actions and playbooks can require approvals in the execution
+// policy, and during a change freeze every auto-remediation action is
+// gated, whether or not its playbook asks for approval.
+type ApprovalGates struct {
+	db      *gorm.DB
+	actions *ActionRegistry
+	policy  *ExecutionPolicy
+}
+
+// ApprovalRequirement is what gates a playbook step's action
+type ApprovalRequirement struct {
+	Approvals int
+	Reasons   []string
+	// Freeze is the change freeze in effect, if it gates the action
+	Freeze *models.ChangeFreeze
+}
+
+// NewApprovalGates creates approval gates executing approved actions through
+// actions
+func NewApprovalGates(db *gorm.DB, actions *ActionRegistry) *ApprovalGates {
+	return &ApprovalGates{db: db, actions: actions}
+}
+
+// SetPolicy provides the approvals the execution policy requires of actions
+// and playbooks
+func (g *ApprovalGates) SetPolicy(policy *ExecutionPolicy) {
+	g.policy = policy
+}
+
+// Requirement returns the approvals a playbook step running the action
+// needs now; zero Approvals runs it ungated
+func (g *ApprovalGates) Requirement(playbookID, actionType string) ApprovalRequirement {
+	var req ApprovalRequirement
+	if g == nil {
+		return req
+	}
+	remediation := g.actions.Remediation(actionType)
+	if approvals := g.policy.RequiredApprovals(playbookID, actionType, remediation); approvals > 0 {
+		req.Approvals = approvals
+		req.Reasons = append(req.Reasons, fmt.Sprintf("execution policy requires %d approvals", approvals))
+	}
+	if remediation {
+		freeze, err := ActiveChangeFreeze(g.db, time.Now().UTC())
+		if err != nil {
+			log.Printf("Failed to check change freezes: %v", err)
+		} else if freeze != nil {
+			req.Freeze = freeze
+			req.Reasons = append(req.Reasons, "change freeze: "+freeze.Name)
+			if req.Approvals < 1 {
+				req.Approvals = 1
+			}
+		}
+	}
+	return req
+}
+
+// Hold records a pending approval for an action of the playbook step in
+// ctx. heldActionID is the action log recording the hold.
+func (g *ApprovalGates) Hold(ctx context.Context, actionType string, params map[string]interface{}, heldActionID string, req ApprovalRequirement) (*models.ActionApproval, error) {
+	paramsJSON, _ := json.Marshal(params)
+	approval := &models.ActionApproval{
+		ActionType:        actionType,
+		Parameters:        string(paramsJSON),
+		HeldActionID:      heldActionID,
+		Reason:            strings.Join(req.Reasons, "; "),
+		RequiredApprovals: req.Approvals,
+	}
+	if req.Freeze != nil {
+		approval.FreezeID = &req.Freeze.FreezeID
+	}
+	if step, ok := StepRunFrom(ctx); ok {
+		approval.PlaybookID = step.PlaybookID
+		approval.RunID = step.RunID
+		approval.StepID = step.StepID
+		approval.Environment = step.Environment
+		if step.IncidentID != "" {
+			approval.IncidentID = &step.IncidentID
+		}
+		if step.Scope.RuleID != "" {
+			approval.RuleID = &step.Scope.RuleID
+		}
+		if step.Scope.Tenant != "" {
+			approval.Tenant = &step.Scope.Tenant
+		}
+		if step.TriggeredBy != "" {
+			approval.RequestedBy = &step.TriggeredBy
+		}
+	}
+	if incidentID := getStringParam(params, "incident_id", ""); incidentID != "" {
+		approval.IncidentID = &incidentID
+	}
+	if err := g.db.Create(approval).Error; err != nil {
+		return nil, fmt.Errorf("failed to record approval: %w", err)
+	}
+	log.Printf("Action %s for step %s awaiting %d approval(s) %s (%s)", actionType, approval.StepID, approval.RequiredApprovals, approval.ApprovalID, approval.Reason)
+	return approval, nil
+}
+
+// Get returns an approval with its decisions
+func (g *ApprovalGates) Get(approvalID string) (*models.ActionApproval, error) {
+	var approval models.ActionApproval
+	if err := g.db.Preload("Decisions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).First(&approval, "approval_id = ?", approvalID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrApprovalNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch approval: %w", err)
+	}
+	return &approval, nil
+}
+
+// Approve records a responder's approval. The approval that brings it to
+// RequiredApprovals executes the held action as part of its playbook step.
+// The returned approval carries the execution's error, if any; err reports
+// only why the approval couldn't be recorded.
+func (g *ApprovalGates) Approve(ctx context.Context, approvalID, by, note string) (*models.ActionApproval, error) {
+	approval, err := g.Get(approvalID)
+	if err != nil {
+		return nil, err
+	}
+	if approval.Status != models.ApprovalPending {
+		return approval, ErrApprovalDecided
+	}
+	if approval.RequestedBy != nil && *approval.RequestedBy == by {
+		return approval, ErrSelfApproval
+	}
+	approvals := 0
+	for _, d := range approval.Decisions {
+		if d.Actor == by {
+			return approval, ErrAlreadyDecided
+		}
+		if d.Decision == models.DecisionApprove {
+			approvals++
+		}
+	}
+	final := approvals+1 >= approval.RequiredApprovals
+	if final && g.actions.Contained(approval.ActionType) {
+		return approval, ErrContainmentPause
+	}
+	if err := g.recordDecision(ctx, approval, by, models.DecisionApprove, note); err != nil {
+		return approval, err
+	}
+	// Recount, so two responders approving at once can't both see
+	// themselves as the one short of final
+	var count int64
+	if err := g.db.Model(&models.ApprovalDecision{}).
+		Where("approval_id = ? AND decision = ?", approval.ApprovalID, models.DecisionApprove).
+		Count(&count).Error; err != nil {
+		return approval, fmt.Errorf("failed to count approvals: %w", err)
+	}
+	if count < int64(approval.RequiredApprovals) {
+		log.Printf("Approval %s approved by %s (%d of %d)", approval.ApprovalID, by, count, approval.RequiredApprovals)
+		return approval, nil
+	}
+	if err := g.decide(ctx, approval, models.ApprovalApproved, by, note); err != nil {
+		if errors.Is(err, ErrApprovalDecided) {
+			// A concurrent final approval executed the action
+			return g.Get(approval.ApprovalID)
+		}
+		return approval, err
+	}
+
+	var params map[string]interface{}
+	if err := json.Unmarshal([]byte(approval.Parameters), &params); err != nil {
+		return approval, fmt.Errorf("failed to decode held parameters: %w", err)
+	}
+	step := StepRun{PlaybookID: approval.PlaybookID, RunID: approval.RunID, StepID: approval.StepID, Environment: approval.Environment}
+	if approval.IncidentID != nil {
+		step.IncidentID = *approval.IncidentID
+	}
+	if approval.RuleID != nil {
+		step.Scope.RuleID = *approval.RuleID
+	}
+	if approval.Tenant != nil {
+		step.Scope.Tenant = *approval.Tenant
+	}
+	if approval.RequestedBy != nil {
+		step.TriggeredBy = *approval.RequestedBy
+	}
+	if approval.RunID != "" {
+		ctx = WithStepRun(ctx, step)
+	}
+
+	_, actionID, execErr := g.actions.ExecuteLogged(ctx, approval.ActionType, params)
+	updates := map[string]interface{}{}
+	if actionID != "" {
+		approval.ActionID = &actionID
+		updates["action_id"] = actionID
+	}
+	if execErr != nil {
+		errMsg := execErr.Error()
+		approval.Error = &errMsg
+		updates["error"] = errMsg
+	}
+	if len(updates) > 0 {
+		if err := g.db.WithContext(ctx).Model(approval).Omit(clause.Associations).Updates(updates).Error; err != nil {
+			log.Printf("Failed to record execution of approval %s: %v", approval.ApprovalID, err)
+		}
+	}
+	return approval, nil
+}
+
+// Reject discards a held action and records who rejected it; one rejection
+// decides the approval
+func (g *ApprovalGates) Reject(ctx context.Context, approvalID, by, note string) (*models.ActionApproval, error) {
+	approval, err := g.Get(approvalID)
+	if err != nil {
+		return nil, err
+	}
+	if approval.Status != models.ApprovalPending {
+		return approval, ErrApprovalDecided
+	}
+	for _, d := range approval.Decisions {
+		if d.Actor == by {
+			return approval, ErrAlreadyDecided
+		}
+	}
+	if err := g.recordDecision(ctx, approval, by, models.DecisionReject, note); err != nil {
+		return approval, err
+	}
+	if err := g.decide(ctx, approval, models.ApprovalRejected, by, note); err != nil {
+		return approval, err
+	}
+	return approval, nil
+}
+
+// recordDecision adds a responder's decision to an approval. Each responder
+// decides an approval once, enforced by a unique index, so two requests
+// from the same principal can't count as two approvals.
+func (g *ApprovalGates) recordDecision(ctx context.Context, approval *models.ActionApproval, by, decision, note string) error {
+	d := models.ApprovalDecision{ApprovalID: approval.ApprovalID, Actor: by, Decision: decision, Note: note}
+	if err := g.db.WithContext(ctx).Create(&d).Error; err != nil {
+		var existing int64
+		g.db.Model(&models.ApprovalDecision{}).Where("approval_id = ? AND actor = ?", approval.ApprovalID, by).Count(&existing)
+		if existing > 0 {
+			return ErrAlreadyDecided
+		}
+		return fmt.Errorf("failed to record decision: %w", err)
+	}
+	approval.Decisions = append(approval.Decisions, d)
+	return nil
+}
+
+// decide moves a pending approval to status. The update is conditional on
+// the approval still being pending, so of two concurrent decisions only
+// one takes effect.
+func (g *ApprovalGates) decide(ctx context.Context, approval *models.ActionApproval, status models.ApprovalStatus, by, note string) error {
+	now := time.Now().UTC()
+	result := g.db.WithContext(ctx).Model(&models.ActionApproval{}).
+		Where("approval_id = ? AND status = ?", approval.ApprovalID, models.ApprovalPending).
+		Updates(map[string]interface{}{"status": status, "decided_by": by, "decided_at": now, "note": note})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update approval: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrApprovalDecided
+	}
+	if approval.HeldActionID != "" {
+		g.db.WithContext(ctx).Model(&models.ActionLog{}).Where("action_id = ?", approval.HeldActionID).
+			Update("notes", fmt.Sprintf("%s by %s (approval %s)", status, by, approval.ApprovalID))
+	}
+	approval.Status = status
+	approval.DecidedBy = &by
+	approval.DecidedAt = &now
+	approval.Note = note
+	return nil
+}
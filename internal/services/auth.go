@@ -0,0 +1,82 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role is an API caller's access level
+type Role string
+
+const (
+	RoleViewer    Role = "viewer"
+	RoleResponder Role = "responder"
+	RoleAdmin     Role = "admin"
+)
+
+// Rank orders roles by privilege; unknown roles rank below viewer
+func (r Role) Rank() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleResponder:
+		return 2
+	case RoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// ParseRole validates a role name
+func ParseRole(s string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(s)))
+	if role.Rank() == 0 {
+		return "", fmt.Errorf("unknown role %q: expected viewer, responder or admin", s)
+	}
+	return role, nil
+}
+
+// Principal is the authenticated caller of an API request
+type Principal struct {
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+}
+
+// CredentialError is a credential the API rejects, as opposed to a failure
+// checking it
+type CredentialError struct {
+	Message string
+}
+
+func (e *CredentialError) Error() string {
+	return e.Message
+}
+
+// APIKeys maps API keys to the principal they authenticate
+type APIKeys map[string]Principal
+
+// ParseAPIKeys parses a key list such as
+// "alice:responder:k3y1,dashboard:viewer:k3y2" (name:role:key)
+func ParseAPIKeys(spec string) (APIKeys, error) {
+	keys := make(APIKeys)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("invalid API key entry for %q: expected name:role:key", parts[0])
+		}
+		role, err := ParseRole(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid API key entry for %q: %w", parts[0], err)
+		}
+		if _, dup := keys[parts[2]]; dup {
+			return nil, fmt.Errorf("duplicate API key for %q", parts[0])
+		}
+		keys[parts[2]] = Principal{Name: parts[0], Role: role}
+	}
+	return keys, nil
+}
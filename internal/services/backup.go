@@ -0,0 +1,596 @@
+package services
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// ErrBackupNotFound is returned for a backup name with no archive
+var ErrBackupNotFound = errors.New("backup not found")
+
+// ErrInvalidBackup is returned when an archive fails verification
+var ErrInvalidBackup = errors.New("backup failed verification")
+
+// backupName matches the archives the manager writes; anything else is
+// refused so names from requests can't address other files
+var backupName = regexp.MustCompile(`^backup-\d{8}T\d{6}Z\.tar\.gz$`)
+
+// Entries in a backup archive
+const (
+	backupManifest = "manifest.json"
+	backupDatabase = "database.db"
+	backupContent  = "content/"
+	backupTenants  = "tenants/"
+)
+
+// BackupConfig locates what a backup captures and where archives go
+type BackupConfig struct {
+	DatabasePath string
+	// TenantDatabases are the tenant databases' paths, by tenant name
+	TenantDatabases map[string]string
+	// ContentDirs are directories captured by name, e.g. "rules"
+	ContentDirs map[string]string
+	// Dir keeps the archives; the newest Retain are kept (0 keeps all)
+	Dir    string
+	Retain int
+	// Store, when set, receives a copy of every archive
+	Store ObjectStore
+}
+
+// BackupManifest describes an archive's contents
+type BackupManifest struct {
+	Name      string       `json:"name"`
+	CreatedAt time.Time    `json:"created_at"`
+	Files     []BackupFile `json:"files"`
+}
+
+// BackupFile is one file in an archive with its checksum
+type BackupFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// BackupInfo describes a stored archive
+type BackupInfo struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	Size      int64     `json:"size"`
+	Uploaded  bool      `json:"uploaded,omitempty"`
+}
+
+// BackupManager takes point-in-time snapshots of the database and content
+// directories as compressed archives, verifies them and restores them
+type BackupManager struct {
+	db  *gorm.DB
+	cfg BackupConfig
+	mu  sync.Mutex // one snapshot at a time
+}
+
+// NewBackupManager creates a backup manager reading the database through db
+func NewBackupManager(db *gorm.DB, cfg BackupConfig) *BackupManager {
+	return &BackupManager{db: db, cfg: cfg}
+}
+
+// OpenBackupDatabase opens a database for backing up without migrating it,
+// for use outside the server
+func OpenBackupDatabase(path string) (*gorm.DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("database %s: %w", path, err)
+	}
+	return gorm.Open(sqlite.Open(path), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+}
+
+// Create snapshots the database with VACUUM INTO, which reads one consistent
+// state even while events are being written, archives it with the content
+// directories, uploads the archive when a store is configured and prunes old
+// archives
+func (m *BackupManager) Create() (*BackupInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(m.cfg.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	now := time.Now().UTC()
+	name := "backup-" + now.Format("20060102T150405Z") + ".tar.gz"
+	final := filepath.Join(m.cfg.Dir, name)
+	if _, err := os.Stat(final); err == nil {
+		return nil, fmt.Errorf("backup %s already exists", name)
+	}
+
+	work, err := os.MkdirTemp(m.cfg.Dir, ".snapshot-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	defer os.RemoveAll(work)
+
+	snapshot := filepath.Join(work, backupDatabase)
+	if err := m.db.Exec("VACUUM INTO ?", snapshot).Error; err != nil {
+		return nil, fmt.Errorf("failed to snapshot database: %w", err)
+	}
+
+	files := []archiveFile{{name: backupDatabase, path: snapshot}}
+	for _, tenant := range tenantNames(m.cfg.TenantDatabases) {
+		tenantSnapshot := filepath.Join(work, tenant+".db")
+		if err := snapshotDatabase(m.cfg.TenantDatabases[tenant], tenantSnapshot); err != nil {
+			return nil, fmt.Errorf("failed to snapshot tenant %s: %w", tenant, err)
+		}
+		files = append(files, archiveFile{name: backupTenants + tenant + ".db", path: tenantSnapshot})
+	}
+	names := make([]string, 0, len(m.cfg.ContentDirs))
+	for dirName := range m.cfg.ContentDirs {
+		names = append(names, dirName)
+	}
+	sort.Strings(names)
+	for _, dirName := range names {
+		dirFiles, err := contentFiles(dirName, m.cfg.ContentDirs[dirName])
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, dirFiles...)
+	}
+
+	tmp := filepath.Join(work, name)
+	if err := writeBackupArchive(tmp, name, now, files); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, final); err != nil {
+		return nil, fmt.Errorf("failed to store backup: %w", err)
+	}
+	info, err := os.Stat(final)
+	if err != nil {
+		return nil, err
+	}
+	result := &BackupInfo{Name: name, CreatedAt: now, Size: info.Size()}
+
+	if m.cfg.Store != nil {
+		data, err := os.ReadFile(final)
+		if err != nil {
+			return nil, err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		err = m.cfg.Store.Put(ctx, name, data)
+		cancel()
+		if err != nil {
+			// The local archive is still good; report the upload failure
+			return result, fmt.Errorf("backup %s written but upload failed: %w", name, err)
+		}
+		result.Uploaded = true
+	}
+
+	log.Printf("Created backup %s (%d bytes)", name, result.Size)
+	m.prune()
+	return result, nil
+}
+
+// snapshotDatabase copies the database at path into dest with VACUUM INTO
+func snapshotDatabase(path, dest string) error {
+	db, err := OpenBackupDatabase(path)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+	return db.Exec("VACUUM INTO ?", dest).Error
+}
+
+// tenantNames returns the tenants of a backup configuration in order
+func tenantNames(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// archiveFile is a file to archive under name
+type archiveFile struct {
+	name string
+	path string
+}
+
+// contentFiles lists the regular files under a content directory, as
+// content/<dirName>/<relative path>. A missing directory is captured empty.
+func contentFiles(dirName, dir string) ([]archiveFile, error) {
+	var files []archiveFile
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && p == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, archiveFile{name: backupContent + dirName + "/" + filepath.ToSlash(rel), path: p})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+// writeBackupArchive writes the manifest and files to a gzipped tarball
+func writeBackupArchive(dest, name string, createdAt time.Time, files []archiveFile) error {
+	manifest := BackupManifest{Name: name, CreatedAt: createdAt}
+	for _, f := range files {
+		sum, size, err := fileChecksum(f.path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f.path, err)
+		}
+		manifest.Files = append(manifest.Files, BackupFile{Path: f.name, Size: size, SHA256: sum})
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	header := &tar.Header{Name: backupManifest, Mode: 0644, Size: int64(len(manifestJSON)), ModTime: createdAt}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifestJSON); err != nil {
+		return err
+	}
+	for i, f := range files {
+		header := &tar.Header{Name: f.name, Mode: 0644, Size: manifest.Files[i].Size, ModTime: createdAt}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		in, err := os.Open(f.path)
+		if err != nil {
+			return err
+		}
+		_, err = io.CopyN(tw, in, header.Size)
+		in.Close()
+		if err != nil {
+			return fmt.Errorf("failed to archive %s: %w", f.path, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+func fileChecksum(p string) (string, int64, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// prune removes the oldest local archives beyond the retention count
+func (m *BackupManager) prune() {
+	if m.cfg.Retain <= 0 {
+		return
+	}
+	backups, err := m.List()
+	if err != nil {
+		log.Printf("Error listing backups to prune: %v", err)
+		return
+	}
+	for _, backup := range backups[min(len(backups), m.cfg.Retain):] {
+		if err := os.Remove(filepath.Join(m.cfg.Dir, backup.Name)); err != nil {
+			log.Printf("Error pruning backup %s: %v", backup.Name, err)
+			continue
+		}
+		log.Printf("Pruned backup %s", backup.Name)
+	}
+}
+
+// List returns the local archives, newest first
+func (m *BackupManager) List() ([]BackupInfo, error) {
+	entries, err := os.ReadDir(m.cfg.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []BackupInfo{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	backups := []BackupInfo{}
+	for _, entry := range entries {
+		if !backupName.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		createdAt, _ := time.Parse("20060102T150405Z", strings.TrimSuffix(strings.TrimPrefix(entry.Name(), "backup-"), ".tar.gz"))
+		backups = append(backups, BackupInfo{Name: entry.Name(), CreatedAt: createdAt, Size: info.Size()})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Name > backups[j].Name })
+	return backups, nil
+}
+
+// Path returns the local archive for a backup name
+func (m *BackupManager) Path(name string) (string, error) {
+	if !backupName.MatchString(name) {
+		return "", ErrBackupNotFound
+	}
+	p := filepath.Join(m.cfg.Dir, name)
+	if _, err := os.Stat(p); err != nil {
+		return "", ErrBackupNotFound
+	}
+	return p, nil
+}
+
+// Fetch downloads an archive from the store into the backup directory,
+// unless it is already there, and returns its path
+func (m *BackupManager) Fetch(name string) (string, error) {
+	if p, err := m.Path(name); err == nil {
+		return p, nil
+	}
+	if m.cfg.Store == nil || !backupName.MatchString(name) {
+		return "", ErrBackupNotFound
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	data, err := m.cfg.Store.Get(ctx, name)
+	if errors.Is(err, ErrObjectNotFound) {
+		return "", ErrBackupNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	if err := os.MkdirAll(m.cfg.Dir, 0755); err != nil {
+		return "", err
+	}
+	p := filepath.Join(m.cfg.Dir, name)
+	if err := os.WriteFile(p, data, 0644); err != nil {
+		return "", err
+	}
+	return p, nil
+}
+
+// VerifyBackup checks an archive: every file matches its manifest checksum
+// and the database passes SQLite's integrity check
+func VerifyBackup(archive string) (*BackupManifest, error) {
+	work, err := os.MkdirTemp("", "backup-verify-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(work)
+	return extractBackup(archive, work)
+}
+
+// extractBackup unpacks an archive into dir and verifies it
+func extractBackup(archive, dir string) (*BackupManifest, error) {
+	in, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	tr := tar.NewReader(gz)
+
+	var manifest *BackupManifest
+	sums := make(map[string]string)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+		if header.Name == backupManifest {
+			manifest = &BackupManifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, fmt.Errorf("%w: manifest: %v", ErrInvalidBackup, err)
+			}
+			continue
+		}
+		clean := path.Clean(header.Name)
+		if header.Typeflag != tar.TypeReg || clean != header.Name || strings.HasPrefix(clean, "../") || path.IsAbs(clean) {
+			return nil, fmt.Errorf("%w: unexpected entry %q", ErrInvalidBackup, header.Name)
+		}
+		dest := filepath.Join(dir, filepath.FromSlash(clean))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return nil, err
+		}
+		out, err := os.Create(dest)
+		if err != nil {
+			return nil, err
+		}
+		h := sha256.New()
+		_, err = io.Copy(io.MultiWriter(out, h), tr)
+		out.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBackup, header.Name, err)
+		}
+		sums[clean] = hex.EncodeToString(h.Sum(nil))
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("%w: no manifest", ErrInvalidBackup)
+	}
+	for _, f := range manifest.Files {
+		sum, ok := sums[f.Path]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is missing", ErrInvalidBackup, f.Path)
+		}
+		if sum != f.SHA256 {
+			return nil, fmt.Errorf("%w: %s checksum mismatch", ErrInvalidBackup, f.Path)
+		}
+		delete(sums, f.Path)
+	}
+	for extra := range sums {
+		return nil, fmt.Errorf("%w: %s is not in the manifest", ErrInvalidBackup, extra)
+	}
+
+	if err := checkDatabase(filepath.Join(dir, backupDatabase)); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	for _, f := range manifest.Files {
+		if strings.HasPrefix(f.Path, backupTenants) {
+			if err := checkDatabase(filepath.Join(dir, filepath.FromSlash(f.Path))); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBackup, f.Path, err)
+			}
+		}
+	}
+	return manifest, nil
+}
+
+// checkDatabase runs SQLite's integrity check on a snapshot and confirms it
+// holds the events table
+func checkDatabase(p string) error {
+	db, err := OpenBackupDatabase(p)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	var result string
+	if err := db.Raw("PRAGMA integrity_check").Scan(&result).Error; err != nil {
+		return fmt.Errorf("integrity check failed: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("integrity check failed: %s", result)
+	}
+	if !db.Migrator().HasTable("events") {
+		return fmt.Errorf("database has no events table")
+	}
+	return nil
+}
+
+// RestoreBackup verifies an archive and then replaces the database, the
+// tenant databases it holds and the content directories with its contents.
+// The service must be stopped. The replaced databases and directories are
+// kept beside the originals with a .pre-restore suffix.
+func RestoreBackup(archive, databasePath string, tenantDatabases, contentDirs map[string]string) (*BackupManifest, error) {
+	// Unpacked next to the database so the final renames stay on one
+	// filesystem
+	if err := os.MkdirAll(filepath.Dir(databasePath), 0755); err != nil {
+		return nil, err
+	}
+	work, err := os.MkdirTemp(filepath.Dir(databasePath), ".restore-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(work)
+
+	manifest, err := extractBackup(archive, work)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := restoreDatabase(filepath.Join(work, backupDatabase), databasePath); err != nil {
+		return nil, fmt.Errorf("failed to restore database: %w", err)
+	}
+	for _, tenant := range tenantNames(tenantDatabases) {
+		restored := filepath.Join(work, "tenants", tenant+".db")
+		if _, err := os.Stat(restored); errors.Is(err, fs.ErrNotExist) {
+			// Taken before the tenant had a database
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(tenantDatabases[tenant]), 0755); err != nil {
+			return nil, err
+		}
+		if err := restoreDatabase(restored, tenantDatabases[tenant]); err != nil {
+			return nil, fmt.Errorf("failed to restore tenant %s: %w", tenant, err)
+		}
+	}
+
+	for dirName, dir := range contentDirs {
+		restored := filepath.Join(work, "content", dirName)
+		if _, err := os.Stat(restored); errors.Is(err, fs.ErrNotExist) {
+			if err := os.MkdirAll(restored, 0755); err != nil {
+				return nil, err
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+			return nil, err
+		}
+		if err := replaceWith(dir, restored); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", dirName, err)
+		}
+	}
+	return manifest, nil
+}
+
+// restoreDatabase moves a snapshot into place as the database at path,
+// setting the database and its WAL files aside
+func restoreDatabase(snapshot, path string) error {
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if err := replaceWith(path+suffix, ""); err != nil {
+			return err
+		}
+	}
+	return os.Rename(snapshot, path)
+}
+
+// replaceWith moves target aside to target.pre-restore, dropping an older
+// copy, and moves replacement into its place when one is given. A missing
+// target is fine.
+func replaceWith(target, replacement string) error {
+	aside := target + ".pre-restore"
+	if err := os.RemoveAll(aside); err != nil {
+		return err
+	}
+	if err := os.Rename(target, aside); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if replacement == "" {
+		return nil
+	}
+	return os.Rename(replacement, target)
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// blastRadiusLookback is how long before an incident's creation events from
+// downstream services count as correlated
+const blastRadiusLookback = time.Hour
+
+// Limits on the lists in a blast radius
+const (
+	maxBlastRadiusEvents    = 100
+	maxBlastRadiusIncidents = 50
+)
+
+// DownstreamService is a service that depends, directly or through other
+// services, on one an incident impacts
+type DownstreamService struct {
+	Name      string `json:"name"`
+	OwnerTeam string `json:"owner_team"`
+	Tier      int    `json:"tier"`
+	// Depth is the number of dependency hops from an impacted service; Path
+	// runs from that service to this one
+	Depth       int        `json:"depth"`
+	Path        []string   `json:"path"`
+	EventCount  int64      `json:"event_count"`
+	LastEventAt *time.Time `json:"last_event_at"`
+}
+
+// BlastRadius is the likely reach of an incident through the service
+// dependency graph
+type BlastRadius struct {
+	IncidentID  string    `json:"incident_id"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// Since is the start of the window for correlated events
+	Since      time.Time           `json:"since"`
+	Impacted   []string            `json:"impacted"`
+	Downstream []DownstreamService `json:"downstream"`
+	// Events are the most recent correlated events from downstream services
+	Events []models.EventSummary `json:"events"`
+	// Incidents are the other open incidents on downstream services
+	Incidents []models.Incident `json:"incidents"`
+}
+
+// BlastRadius returns the services downstream of those an incident impacts,
+// nearest first, with the events they have reported since shortly before
+// the incident was raised. It is computed from the incident's current
+// services on every call. It returns gorm.ErrRecordNotFound for an unknown
+// incident.
+func (c *ServiceCatalog) BlastRadius(incidentID string) (*BlastRadius, error) {
+	var incident models.Incident
+	if err := c.db.First(&incident, "incident_id = ?", incidentID).Error; err != nil {
+		return nil, err
+	}
+	radius := &BlastRadius{
+		IncidentID:  incident.IncidentID,
+		GeneratedAt: time.Now().UTC(),
+		Since:       incident.CreatedAt.Add(-blastRadiusLookback),
+		Impacted:    decodeStringList(incident.Services),
+		Downstream:  []DownstreamService{},
+		Events:      []models.EventSummary{},
+		Incidents:   []models.Incident{},
+	}
+	if len(radius.Impacted) == 0 {
+		return radius, nil
+	}
+
+	var catalog []models.Service
+	if err := c.db.Find(&catalog).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch services: %w", err)
+	}
+	byName := make(map[string]models.Service, len(catalog))
+	dependents := make(map[string][]string)
+	for _, service := range catalog {
+		byName[service.Name] = service
+		for _, dependency := range service.Dependencies {
+			dependents[dependency] = append(dependents[dependency], service.Name)
+		}
+	}
+
+	// Breadth-first over reverse dependency edges, so each service is
+	// reached by its shortest path
+	paths := make(map[string][]string)
+	queue := make([]string, 0, len(radius.Impacted))
+	for _, name := range radius.Impacted {
+		paths[name] = []string{name}
+		queue = append(queue, name)
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		next := dependents[name]
+		sort.Strings(next)
+		for _, dependent := range next {
+			if _, seen := paths[dependent]; seen {
+				continue
+			}
+			path := append(append([]string{}, paths[name]...), dependent)
+			paths[dependent] = path
+			queue = append(queue, dependent)
+			service := byName[dependent]
+			radius.Downstream = append(radius.Downstream, DownstreamService{
+				Name:      dependent,
+				OwnerTeam: service.OwnerTeam,
+				Tier:      service.Tier,
+				Depth:     len(path) - 1,
+				Path:      path,
+			})
+		}
+	}
+	if len(radius.Downstream) == 0 {
+		return radius, nil
+	}
+
+	names := make([]string, len(radius.Downstream))
+	for i, service := range radius.Downstream {
+		names[i] = service.Name
+	}
+	var counts []struct {
+		ServiceName string
+		Count       int64
+	}
+	if err := c.db.Model(&models.Event{}).Select("service_name, COUNT(*) AS count").
+		Where("service_name IN ? AND timestamp >= ?", names, radius.Since).
+		Group("service_name").Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count events: %w", err)
+	}
+	eventCounts := make(map[string]int64, len(counts))
+	for _, count := range counts {
+		eventCounts[count.ServiceName] = count.Count
+	}
+	for i := range radius.Downstream {
+		service := &radius.Downstream[i]
+		if service.EventCount = eventCounts[service.Name]; service.EventCount == 0 {
+			continue
+		}
+		var last []time.Time
+		if err := c.db.Model(&models.Event{}).Where("service_name = ?", service.Name).
+			Order("timestamp DESC").Limit(1).Pluck("timestamp", &last).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch events: %w", err)
+		}
+		if len(last) > 0 {
+			service.LastEventAt = &last[0]
+		}
+	}
+
+	if err := c.db.Model(&models.Event{}).Select(models.EventSummaryColumns).
+		Where("service_name IN ? AND timestamp >= ?", names, radius.Since).
+		Order("timestamp DESC").Limit(maxBlastRadiusEvents).
+		Scan(&radius.Events).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch events: %w", err)
+	}
+
+	if err := c.db.Where("incident_id <> ? AND status <> ? AND exercise = ?", incident.IncidentID, models.StatusResolved, incident.Exercise).
+		Where("EXISTS (SELECT 1 FROM json_each(incidents.services) WHERE json_each.value IN ?)", names).
+		Order("created_at DESC").Limit(maxBlastRadiusIncidents).
+		Find(&radius.Incidents).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch incidents: %w", err)
+	}
+	return radius, nil
+}
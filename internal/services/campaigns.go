@@ -0,0 +1,408 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// maxCampaignTimelineIncidents caps the incidents merged into a campaign's
+// timeline; the most recent are kept
+const maxCampaignTimelineIncidents = 50
+
+// ErrCampaignNotFound is returned for an unknown campaign ID
+var ErrCampaignNotFound = errors.New("campaign not found")
+
+// ErrCampaignIncidentNotFound is returned when a campaign operation names an
+// unknown incident, or one not in the campaign
+var ErrCampaignIncidentNotFound = errors.New("incident not found")
+
+// campaignStatusOrder ranks incident statuses from least to most advanced;
+// a campaign has the status of its least advanced incident
+var campaignStatusOrder = []models.IncidentStatus{
+	models.StatusOpen, models.StatusInvestigating, models.StatusContained, models.StatusResolved,
+}
+
+// CampaignManager groups incidents into campaigns, automatically as they
+// are created and by hand through the API
+type CampaignManager struct {
+	db        *gorm.DB
+	window    time.Duration
+	ruleBurst int
+}
+
+// NewCampaignManager creates a campaign manager. Incidents created within
+// window of each other are grouped when they share a source IP or user, or
+// when ruleBurst incidents come from one rule. A zero window disables
+// automatic grouping.
+func NewCampaignManager(db *gorm.DB, window time.Duration, ruleBurst int) *CampaignManager {
+	return &CampaignManager{db: db, window: window, ruleBurst: ruleBurst}
+}
+
+// CampaignSummary is a campaign with the aggregate state of its incidents
+type CampaignSummary struct {
+	models.Campaign
+	Status        string               `json:"status"`
+	Severity      models.SeverityLevel `json:"severity"`
+	IncidentCount int                  `json:"incident_count"`
+	FirstSeen     *time.Time           `json:"first_seen"`
+	LastSeen      *time.Time           `json:"last_seen"`
+}
+
+// CampaignTimelineEntry is a timeline entry from one of a campaign's incidents
+type CampaignTimelineEntry struct {
+	TimelineEntry
+	IncidentID string `json:"incident_id"`
+	Incident   string `json:"incident"`
+}
+
+// CampaignDetail is a campaign with its incidents and combined timeline
+type CampaignDetail struct {
+	CampaignSummary
+	Incidents []models.Incident       `json:"incidents"`
+	Timeline  []CampaignTimelineEntry `json:"timeline"`
+}
+
+// campaignKey is a value incidents can share to be grouped
+type campaignKey struct {
+	kind  string // source_ip, user or rule
+	value string
+}
+
+func (k campaignKey) String() string { return k.kind + ":" + k.value }
+
+// campaignKeys returns the grouping keys of a new incident, IOCs first
+func campaignKeys(incident *models.Incident, normalized map[string]interface{}) []campaignKey {
+	var keys []campaignKey
+	for _, field := range []struct{ kind, a, b string }{
+		{"source_ip", "source_ip", "src_ip"},
+		{"user", "username", "user"},
+	} {
+		value := normalized[field.a]
+		if value == nil {
+			value = normalized[field.b]
+		}
+		if s, ok := value.(string); ok && s != "" {
+			keys = append(keys, campaignKey{field.kind, s})
+		}
+	}
+	if incident.TriggeredByRule != "" {
+		keys = append(keys, campaignKey{"rule", incident.TriggeredByRule})
+	}
+	return keys
+}
+
+// Correlate groups a newly created incident: into an active campaign with
+// a matching key, or into a new campaign with the ungrouped recent incidents
+// that share a key with it
+func (m *CampaignManager) Correlate(tx *gorm.DB, incident *models.Incident, normalized map[string]interface{}) error {
+	if m == nil || m.window <= 0 {
+		return nil
+	}
+	keys := campaignKeys(incident, normalized)
+	if len(keys) == 0 {
+		return nil
+	}
+	cutoff := time.Now().UTC().Add(-m.window)
+
+	groupKeys := make([]string, len(keys))
+	for i, key := range keys {
+		groupKeys[i] = key.String()
+	}
+	var campaign models.Campaign
+	err := tx.Where("group_key IN ? AND exercise = ? AND updated_at >= ?", groupKeys, incident.Exercise, cutoff).
+		Order("updated_at DESC").First(&campaign).Error
+	if err == nil {
+		return m.attach(tx, &campaign, []string{incident.IncidentID})
+	}
+	if err != gorm.ErrRecordNotFound {
+		return fmt.Errorf("failed to look up campaign: %w", err)
+	}
+
+	for _, key := range keys {
+		related, err := m.relatedIncidents(tx, incident, key, cutoff)
+		if err != nil {
+			return err
+		}
+		threshold := 2
+		if key.kind == "rule" {
+			threshold = m.ruleBurst
+		}
+		if len(related)+1 < threshold || len(related) == 0 {
+			continue
+		}
+
+		campaign := &models.Campaign{
+			Title:       fmt.Sprintf("Campaign: %s %s", strings.ReplaceAll(key.kind, "_", " "), key.value),
+			Description: fmt.Sprintf("Grouped automatically: incidents sharing %s %s within %s", key.kind, key.value, m.window),
+			GroupKey:    key.String(),
+			Auto:        true,
+			Exercise:    incident.Exercise,
+		}
+		if err := tx.Create(campaign).Error; err != nil {
+			return fmt.Errorf("failed to create campaign: %w", err)
+		}
+		log.Printf("Created campaign %s for %s with %d incidents", campaign.CampaignID, key, len(related)+1)
+		return m.attach(tx, campaign, append(related, incident.IncidentID))
+	}
+	return nil
+}
+
+// relatedIncidents returns ungrouped incidents created since cutoff that
+// share key with incident. Source IPs and users are matched on the
+// incidents' related events.
+func (m *CampaignManager) relatedIncidents(tx *gorm.DB, incident *models.Incident, key campaignKey, cutoff time.Time) ([]string, error) {
+	var ids []string
+	var err error
+	switch key.kind {
+	case "rule":
+		err = tx.Model(&models.Incident{}).
+			Where("triggered_by_rule = ? AND created_at >= ? AND campaign_id IS NULL AND incident_id <> ? AND exercise = ?",
+				key.value, cutoff, incident.IncidentID, incident.Exercise).
+			Pluck("incident_id", &ids).Error
+	default:
+		column := "src_ip"
+		if key.kind == "user" {
+			column = "user_name"
+		}
+		err = tx.Raw(`SELECT DISTINCT i.incident_id FROM incidents i, json_each(i.related_events) je
+			JOIN events e ON e.event_id = je.value
+			WHERE e.`+column+` = ? AND i.created_at >= ? AND i.campaign_id IS NULL AND i.incident_id <> ? AND i.exercise = ?`,
+			key.value, cutoff, incident.IncidentID, incident.Exercise).
+			Scan(&ids).Error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find incidents sharing %s: %w", key, err)
+	}
+	return ids, nil
+}
+
+// attach moves incidents into a campaign and marks it updated
+func (m *CampaignManager) attach(tx *gorm.DB, campaign *models.Campaign, incidentIDs []string) error {
+	if err := tx.Model(&models.Incident{}).Where("incident_id IN ?", incidentIDs).
+		Update("campaign_id", campaign.CampaignID).Error; err != nil {
+		return fmt.Errorf("failed to add incidents to campaign: %w", err)
+	}
+	if err := tx.Model(campaign).Update("updated_at", time.Now().UTC()).Error; err != nil {
+		return fmt.Errorf("failed to update campaign: %w", err)
+	}
+	return nil
+}
+
+// Create creates a campaign by hand from existing incidents
+func (m *CampaignManager) Create(title, description string, incidentIDs []string) (*models.Campaign, error) {
+	campaign := &models.Campaign{Title: title, Description: description}
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := m.checkIncidents(tx, incidentIDs); err != nil {
+			return err
+		}
+		if err := tx.Create(campaign).Error; err != nil {
+			return fmt.Errorf("failed to create campaign: %w", err)
+		}
+		if len(incidentIDs) == 0 {
+			return nil
+		}
+		return m.attach(tx, campaign, incidentIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return campaign, nil
+}
+
+// AddIncidents moves incidents into a campaign, out of any other
+func (m *CampaignManager) AddIncidents(campaignID string, incidentIDs []string) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		campaign, err := m.find(tx, campaignID)
+		if err != nil {
+			return err
+		}
+		if err := m.checkIncidents(tx, incidentIDs); err != nil {
+			return err
+		}
+		return m.attach(tx, campaign, incidentIDs)
+	})
+}
+
+// RemoveIncident takes an incident out of a campaign
+func (m *CampaignManager) RemoveIncident(campaignID, incidentID string) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		campaign, err := m.find(tx, campaignID)
+		if err != nil {
+			return err
+		}
+		result := tx.Model(&models.Incident{}).
+			Where("incident_id = ? AND campaign_id = ?", incidentID, campaignID).
+			Update("campaign_id", nil)
+		if result.Error != nil {
+			return fmt.Errorf("failed to remove incident from campaign: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w in campaign: %s", ErrCampaignIncidentNotFound, incidentID)
+		}
+		return tx.Model(campaign).Update("updated_at", time.Now().UTC()).Error
+	})
+}
+
+// Update changes a campaign's title or description
+func (m *CampaignManager) Update(campaignID string, title, description *string) (*models.Campaign, error) {
+	campaign, err := m.find(m.db, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if title != nil {
+		campaign.Title = *title
+	}
+	if description != nil {
+		campaign.Description = *description
+	}
+	if err := m.db.Save(campaign).Error; err != nil {
+		return nil, fmt.Errorf("failed to update campaign: %w", err)
+	}
+	return campaign, nil
+}
+
+// List returns campaigns, most recently updated first, optionally only those
+// with the given aggregate status
+func (m *CampaignManager) List(status string) ([]CampaignSummary, error) {
+	var campaigns []models.Campaign
+	if err := m.db.Order("updated_at DESC").Find(&campaigns).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch campaigns: %w", err)
+	}
+	summaries, err := m.summarize(campaigns)
+	if err != nil || status == "" {
+		return summaries, err
+	}
+	filtered := []CampaignSummary{}
+	for _, summary := range summaries {
+		if summary.Status == status {
+			filtered = append(filtered, summary)
+		}
+	}
+	return filtered, nil
+}
+
+// Get returns a campaign with its incidents and their merged timeline
+func (m *CampaignManager) Get(campaignID string) (*CampaignDetail, error) {
+	campaign, err := m.find(m.db, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := m.summarize([]models.Campaign{*campaign})
+	if err != nil {
+		return nil, err
+	}
+	detail := &CampaignDetail{CampaignSummary: summaries[0], Timeline: []CampaignTimelineEntry{}}
+	if err := m.db.Where("campaign_id = ?", campaignID).Order("created_at ASC").Find(&detail.Incidents).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch campaign incidents: %w", err)
+	}
+
+	incidents := detail.Incidents
+	if len(incidents) > maxCampaignTimelineIncidents {
+		incidents = incidents[len(incidents)-maxCampaignTimelineIncidents:]
+	}
+	for _, incident := range incidents {
+		report, err := BuildIncidentReport(m.db, incident.IncidentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build timeline for incident %s: %w", incident.IncidentID, err)
+		}
+		for _, entry := range report.Timeline {
+			detail.Timeline = append(detail.Timeline, CampaignTimelineEntry{
+				TimelineEntry: entry,
+				IncidentID:    incident.IncidentID,
+				Incident:      incident.Title,
+			})
+		}
+	}
+	sort.SliceStable(detail.Timeline, func(i, j int) bool {
+		return detail.Timeline[i].Time.Before(detail.Timeline[j].Time)
+	})
+	return detail, nil
+}
+
+// find loads a campaign, returning ErrCampaignNotFound if it doesn't exist
+func (m *CampaignManager) find(tx *gorm.DB, campaignID string) (*models.Campaign, error) {
+	var campaign models.Campaign
+	if err := tx.First(&campaign, "campaign_id = ?", campaignID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrCampaignNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch campaign: %w", err)
+	}
+	return &campaign, nil
+}
+
+// checkIncidents verifies that every incident exists
+func (m *CampaignManager) checkIncidents(tx *gorm.DB, incidentIDs []string) error {
+	if len(incidentIDs) == 0 {
+		return nil
+	}
+	var found int64
+	if err := tx.Model(&models.Incident{}).Where("incident_id IN ?", incidentIDs).Count(&found).Error; err != nil {
+		return fmt.Errorf("failed to look up incidents: %w", err)
+	}
+	unique := make(map[string]bool, len(incidentIDs))
+	for _, id := range incidentIDs {
+		unique[id] = true
+	}
+	if int(found) != len(unique) {
+		return fmt.Errorf("%w: one of %s", ErrCampaignIncidentNotFound, strings.Join(incidentIDs, ", "))
+	}
+	return nil
+}
+
+// summarize derives each campaign's status, severity and extent from its
+// incidents
+func (m *CampaignManager) summarize(campaigns []models.Campaign) ([]CampaignSummary, error) {
+	summaries := make([]CampaignSummary, len(campaigns))
+	if len(campaigns) == 0 {
+		return summaries, nil
+	}
+	index := make(map[string]*CampaignSummary, len(campaigns))
+	ids := make([]string, len(campaigns))
+	for i, campaign := range campaigns {
+		summaries[i] = CampaignSummary{Campaign: campaign, Status: "empty"}
+		index[campaign.CampaignID] = &summaries[i]
+		ids[i] = campaign.CampaignID
+	}
+
+	var incidents []models.Incident
+	if err := m.db.Select("incident_id", "campaign_id", "status", "severity", "created_at", "updated_at").
+		Where("campaign_id IN ?", ids).Find(&incidents).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch campaign incidents: %w", err)
+	}
+	statusRank := make(map[models.IncidentStatus]int, len(campaignStatusOrder))
+	for i, status := range campaignStatusOrder {
+		statusRank[status] = i
+	}
+	lowestStatus := make(map[string]int)
+	for _, incident := range incidents {
+		summary := index[*incident.CampaignID]
+		summary.IncidentCount++
+		if summary.Severity == "" || incident.Severity.Rank() > summary.Severity.Rank() {
+			summary.Severity = incident.Severity
+		}
+		created, updated := incident.CreatedAt, incident.UpdatedAt
+		if summary.FirstSeen == nil || created.Before(*summary.FirstSeen) {
+			summary.FirstSeen = &created
+		}
+		if summary.LastSeen == nil || updated.After(*summary.LastSeen) {
+			summary.LastSeen = &updated
+		}
+		rank := statusRank[incident.Status] // unknown statuses count as open
+		if current, seen := lowestStatus[*incident.CampaignID]; !seen || rank < current {
+			lowestStatus[*incident.CampaignID] = rank
+		}
+	}
+	for id, rank := range lowestStatus {
+		index[id].Status = string(campaignStatusOrder[rank])
+	}
+	return summaries, nil
+}
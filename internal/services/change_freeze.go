@@ -0,0 +1,51 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// ActiveChangeFreeze returns the change freeze in effect at a time, or nil.
+// Of overlapping freezes the one that started first is returned.
+func ActiveChangeFreeze(db *gorm.DB, at time.Time) (*models.ChangeFreeze, error) {
+	var freezes []models.ChangeFreeze
+	if err := db.Where("starts_at <= ? AND ends_at > ?", at, at).
+		Order("starts_at ASC").Limit(1).Find(&freezes).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch change freezes: %w", err)
+	}
+	if len(freezes) == 0 {
+		return nil, nil
+	}
+	return &freezes[0], nil
+}
+
+// freezeNotice is the prefix notifications sent during a change freeze
+// carry
+func freezeNotice(freeze *models.ChangeFreeze) string {
+	return fmt.Sprintf("[CHANGE FREEZE: %s until %s] ", freeze.Name, freeze.EndsAt.UTC().Format(time.RFC3339))
+}
+
+// withFreezeNotice returns notification parameters whose message calls out
+// the change freeze in effect, if any. params is not modified.
+func withFreezeNotice(db *gorm.DB, params map[string]interface{}) map[string]interface{} {
+	freeze, err := ActiveChangeFreeze(db, time.Now().UTC())
+	if err != nil || freeze == nil {
+		return params
+	}
+	message := getStringParam(params, "message", "")
+	if strings.HasPrefix(message, "[CHANGE FREEZE") {
+		return params
+	}
+	noticed := make(map[string]interface{}, len(params)+1)
+	for k, v := range params {
+		noticed[k] = v
+	}
+	noticed["message"] = strings.TrimSpace(freezeNotice(freeze) + message)
+	noticed["change_freeze"] = freeze.Name
+	return noticed
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// coldStorageBatch bounds the payloads moved per query
+const coldStorageBatch = 100
+
+// ColdStorage moves large raw event payloads out of the database into an
+// object store, leaving a key in their place, and fetches them back when an
+// event is read
+type ColdStorage struct {
+	db        *gorm.DB
+	store     ObjectStore
+	threshold int
+
+	// cursor is the highest events rowid already considered; payloads never
+	// change, so each pass only looks at rows added since the last
+	mu     sync.Mutex
+	cursor int64
+}
+
+// NewColdStorage offloads raw payloads larger than threshold bytes to store.
+// A nil ColdStorage keeps every payload in the database.
+func NewColdStorage(db *gorm.DB, store ObjectStore, threshold int) *ColdStorage {
+	return &ColdStorage{db: db, store: store, threshold: threshold}
+}
+
+// rawDataKey names the object holding an event's raw payload
+func rawDataKey(eventID string, timestamp time.Time) string {
+	return fmt.Sprintf("raw/%s/%s.json", timestamp.UTC().Format("2006/01/02"), eventID)
+}
+
+// Offload moves the raw payloads of events stored since the last pass that
+// exceed the threshold. A payload is uploaded before its row is cleared, so
+// a failure leaves it in the database to be retried on the next pass.
+func (c *ColdStorage) Offload() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var last int64
+	if err := c.db.Raw("SELECT COALESCE(MAX(rowid), 0) FROM events").Scan(&last).Error; err != nil {
+		return fmt.Errorf("failed to read events: %w", err)
+	}
+	if last < c.cursor {
+		c.cursor = 0 // the database was replaced
+	}
+
+	moved := 0
+	for c.cursor < last {
+		var rows []struct {
+			RowID     int64
+			EventID   string
+			Timestamp time.Time
+			RawData   string
+		}
+		err := c.db.Model(&models.Event{}).
+			Select("rowid AS row_id, event_id, timestamp, raw_data").
+			Where("rowid > ? AND rowid <= ? AND raw_data_ref IS NULL AND length(raw_data) > ?", c.cursor, last, c.threshold).
+			Order("rowid").Limit(coldStorageBatch).Scan(&rows).Error
+		if err != nil {
+			return fmt.Errorf("failed to find payloads to offload: %w", err)
+		}
+		if len(rows) == 0 {
+			c.cursor = last
+			break
+		}
+
+		for _, row := range rows {
+			// Objects hold the payload as sent, even when the database
+			// keeps it compressed
+			rawData, err := models.DecodePayload(row.RawData)
+			if err != nil {
+				return fmt.Errorf("failed to read payload of event %s: %w", row.EventID, err)
+			}
+			key := rawDataKey(row.EventID, row.Timestamp)
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			err = c.store.Put(ctx, key, []byte(rawData))
+			cancel()
+			if err != nil {
+				return fmt.Errorf("failed to offload payload of event %s: %w", row.EventID, err)
+			}
+			err = c.db.Model(&models.Event{}).Where("event_id = ? AND raw_data_ref IS NULL", row.EventID).
+				Updates(map[string]interface{}{"raw_data": "", "raw_data_ref": key}).Error
+			if err != nil {
+				return fmt.Errorf("failed to record offloaded payload of event %s: %w", row.EventID, err)
+			}
+			c.cursor = row.RowID
+			moved++
+		}
+		if len(rows) < coldStorageBatch {
+			c.cursor = last
+		}
+	}
+	if moved > 0 {
+		log.Printf("Moved %d raw event payloads to cold storage", moved)
+	}
+	return nil
+}
+
+// LoadRawData fetches an event's raw payload back from cold storage, if it
+// was moved there
+func (c *ColdStorage) LoadRawData(ctx context.Context, event *models.Event) error {
+	if event.RawDataRef == nil || *event.RawDataRef == "" {
+		return nil
+	}
+	if c == nil {
+		return fmt.Errorf("raw payload is in cold storage, which isn't configured")
+	}
+	data, err := c.store.Get(ctx, *event.RawDataRef)
+	if err != nil {
+		return fmt.Errorf("failed to fetch raw payload %s: %w", *event.RawDataRef, err)
+	}
+	event.RawData = string(data)
+	return nil
+}
+
+// ErasePayload applies erase to an offloaded payload, storing it back if it
+// changed, and reports whether it did
+func (c *ColdStorage) ErasePayload(ctx context.Context, key string, erase func(string) string) (bool, error) {
+	if c == nil {
+		return false, fmt.Errorf("raw payload is in cold storage, which isn't configured")
+	}
+	data, err := c.store.Get(ctx, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch raw payload %s: %w", key, err)
+	}
+	erased := erase(string(data))
+	if erased == string(data) {
+		return false, nil
+	}
+	if err := c.store.Put(ctx, key, []byte(erased)); err != nil {
+		return false, fmt.Errorf("failed to rewrite raw payload %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// DeletePayloads removes offloaded payloads whose events are gone or no
+// longer refer to them
+func (c *ColdStorage) DeletePayloads(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if c == nil {
+		return fmt.Errorf("raw payloads are in cold storage, which isn't configured")
+	}
+	for _, key := range keys {
+		if err := c.store.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete raw payload %s: %w", key, err)
+		}
+	}
+	return nil
+}
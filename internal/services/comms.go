@@ -0,0 +1,365 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// Comms template audiences
+const (
+	AudienceInternal  = "internal"
+	AudienceExecutive = "executive"
+	AudienceCustomer  = "customer"
+)
+
+var (
+	// ErrCommsTemplateNotFound is returned for an unknown comms template
+	ErrCommsTemplateNotFound = errors.New("comms template not found")
+	// ErrCommsIncidentNotFound is returned for an update about an unknown
+	// incident
+	ErrCommsIncidentNotFound = errors.New("incident not found")
+	// ErrNoUpdateTargets is returned when an update has nowhere to go
+	ErrNoUpdateTargets = errors.New("no targets for the update")
+	// ErrUpdateNotDelivered is returned when no target received an update
+	ErrUpdateNotDelivered = errors.New("update could not be delivered to any target")
+)
+
+// CommsTemplatesFile is the YAML format of a comms templates file
+type CommsTemplatesFile struct {
+	Templates []struct {
+		ID       string   `yaml:"id"`
+		Name     string   `yaml:"name"`
+		Audience string   `yaml:"audience"`
+		Targets  []string `yaml:"targets"`
+		Subject  string   `yaml:"subject"`
+		Body     string   `yaml:"body"`
+	} `yaml:"templates"`
+}
+
+// CommsTemplate renders a stakeholder update from incident fields
+type CommsTemplate struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Audience string   `json:"audience"`
+	Targets  []string `json:"targets"` // default notify targets
+	Subject  string   `json:"subject"`
+	Body     string   `json:"body"`
+
+	subject *template.Template
+	body    *template.Template
+}
+
+// CommsData is what comms templates render: the incident with its JSON
+// list columns decoded, the sender's message and how long it has run
+type CommsData struct {
+	Incident *models.Incident
+	Services []string
+	Tags     []string
+	// Roles maps each incident role to its holders, comma-separated
+	Roles    map[string]string
+	Message  string
+	Duration string // since the incident opened, rounded to the minute
+	Now      time.Time
+}
+
+// RenderedUpdate is a comms template rendered for an incident
+type RenderedUpdate struct {
+	Template string `json:"template"`
+	Audience string `json:"audience"`
+	Subject  string `json:"subject"`
+	Body     string `json:"body"`
+}
+
+// Comms renders stakeholder updates from comms templates, delivers them to
+// notify targets and records them on the incident
+type Comms struct {
+	db        *gorm.DB
+	router    *NotificationRouter
+	templates map[string]*CommsTemplate
+}
+
+// NewComms creates a comms service delivering through router
+func NewComms(db *gorm.DB, router *NotificationRouter) *Comms {
+	return &Comms{db: db, router: router, templates: make(map[string]*CommsTemplate)}
+}
+
+// LoadTemplates loads the comms templates of all YAML files in the
+// directory. A template that doesn't parse, or fails to render a sample
+// incident, is skipped with a warning.
+func (c *Comms) LoadTemplates(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return fmt.Errorf("failed to glob comms templates: %w", err)
+	}
+	files2, err := filepath.Glob(filepath.Join(dir, "*.yml"))
+	if err != nil {
+		return fmt.Errorf("failed to glob comms templates: %w", err)
+	}
+	files = append(files, files2...)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Printf("Warning: failed to read comms templates file %s: %v", file, err)
+			continue
+		}
+		var templatesFile CommsTemplatesFile
+		if err := yaml.Unmarshal(data, &templatesFile); err != nil {
+			log.Printf("Warning: failed to parse comms templates file %s: %v", file, err)
+			continue
+		}
+		for _, spec := range templatesFile.Templates {
+			t := &CommsTemplate{
+				ID:       spec.ID,
+				Name:     spec.Name,
+				Audience: spec.Audience,
+				Targets:  spec.Targets,
+				Subject:  spec.Subject,
+				Body:     spec.Body,
+			}
+			if err := t.compile(); err != nil {
+				log.Printf("Warning: skipping comms template %q in %s: %v", spec.ID, file, err)
+				continue
+			}
+			c.templates[t.ID] = t
+			log.Printf("Loaded comms template: %s (%s)", t.ID, t.Name)
+		}
+	}
+	return nil
+}
+
+// compile validates and parses the template, rendering a sample incident so
+// references to unknown fields fail at load rather than mid-incident
+func (t *CommsTemplate) compile() error {
+	if t.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	switch t.Audience {
+	case AudienceInternal, AudienceExecutive, AudienceCustomer:
+	default:
+		return fmt.Errorf("audience must be %s, %s or %s", AudienceInternal, AudienceExecutive, AudienceCustomer)
+	}
+	if strings.TrimSpace(t.Body) == "" {
+		return fmt.Errorf("body is required")
+	}
+	var err error
+	if t.subject, err = template.New(t.ID + ".subject").Funcs(reportFuncs).Option("missingkey=error").Parse(t.Subject); err != nil {
+		return fmt.Errorf("invalid subject: %w", err)
+	}
+	if t.body, err = template.New(t.ID + ".body").Funcs(reportFuncs).Option("missingkey=error").Parse(t.Body); err != nil {
+		return fmt.Errorf("invalid body: %w", err)
+	}
+	sample := &models.Incident{Title: "Sample", Status: models.StatusOpen, Severity: models.SeverityCritical, CreatedAt: time.Now().UTC()}
+	_, err = t.render(commsData(sample, nil, "", time.Now().UTC()))
+	return err
+}
+
+func (t *CommsTemplate) render(data CommsData) (RenderedUpdate, error) {
+	var subject, body bytes.Buffer
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return RenderedUpdate{}, fmt.Errorf("failed to render subject: %w", err)
+	}
+	if err := t.body.Execute(&body, data); err != nil {
+		return RenderedUpdate{}, fmt.Errorf("failed to render body: %w", err)
+	}
+	return RenderedUpdate{
+		Template: t.ID,
+		Audience: t.Audience,
+		Subject:  strings.TrimSpace(subject.String()),
+		Body:     strings.TrimSpace(body.String()),
+	}, nil
+}
+
+func commsData(incident *models.Incident, roles []models.IncidentRoleAssignment, message string, now time.Time) CommsData {
+	// Every role is present, so templates can name roles nobody holds
+	holders := make(map[string]string, len(models.IncidentRoles))
+	for _, role := range models.IncidentRoles {
+		holders[role] = ""
+	}
+	for _, a := range roles {
+		if holders[a.Role] != "" {
+			holders[a.Role] += ", "
+		}
+		holders[a.Role] += a.Assignee
+	}
+	return CommsData{
+		Incident: incident,
+		Services: decodeStringList(incident.Services),
+		Tags:     decodeStringList(incident.Tags),
+		Roles:    holders,
+		Message:  message,
+		Duration: formatIdle(now.Sub(incident.CreatedAt)),
+		Now:      now,
+	}
+}
+
+// Templates lists the loaded comms templates by ID
+func (c *Comms) Templates() []*CommsTemplate {
+	templates := make([]*CommsTemplate, 0, len(c.templates))
+	for _, t := range c.templates {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].ID < templates[j].ID })
+	return templates
+}
+
+// Render renders a comms template for an incident without sending it
+func (c *Comms) Render(incidentID, templateID, message string) (*models.Incident, RenderedUpdate, error) {
+	t, ok := c.templates[templateID]
+	if !ok {
+		return nil, RenderedUpdate{}, fmt.Errorf("%w: %s", ErrCommsTemplateNotFound, templateID)
+	}
+	var incident models.Incident
+	if err := c.db.First(&incident, "incident_id = ?", incidentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, RenderedUpdate{}, ErrCommsIncidentNotFound
+		}
+		return nil, RenderedUpdate{}, fmt.Errorf("failed to fetch incident: %w", err)
+	}
+	roles, err := currentRoles(c.db, incident.IncidentID)
+	if err != nil {
+		return nil, RenderedUpdate{}, err
+	}
+	rendered, err := t.render(commsData(&incident, roles, message, time.Now().UTC()))
+	return &incident, rendered, err
+}
+
+// Send renders a comms template for an incident and delivers it to targets,
+// or to the template's targets when none are given. The update is recorded
+// when at least one target received it, which restarts the incident's
+// update cadence.
+func (c *Comms) Send(ctx context.Context, incidentID, templateID, message string, targets []string, by string) (*models.StakeholderUpdate, error) {
+	incident, rendered, err := c.Render(incidentID, templateID, message)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		targets = c.templates[templateID].Targets
+	}
+	if len(targets) == 0 {
+		return nil, ErrNoUpdateTargets
+	}
+
+	text := rendered.Body
+	if rendered.Subject != "" {
+		text = rendered.Subject + "\n\n" + rendered.Body
+	}
+	n := Notification{
+		IncidentID: incident.IncidentID,
+		Severity:   string(incident.Severity),
+		Category:   incident.Category,
+		Team:       incident.Team,
+		Tags:       decodeStringList(incident.Tags),
+		Message:    text,
+		RequestID:  RequestIDFrom(ctx),
+	}
+	update := &models.StakeholderUpdate{
+		IncidentID: incident.IncidentID,
+		Template:   rendered.Template,
+		Audience:   rendered.Audience,
+		Subject:    rendered.Subject,
+		Body:       rendered.Body,
+		SentBy:     by,
+		Targets:    []string{},
+	}
+	for _, target := range targets {
+		if c.router.SendDirect(n, target) {
+			update.Targets = append(update.Targets, target)
+		} else {
+			update.Failed = append(update.Failed, target)
+		}
+	}
+	if len(update.Targets) == 0 {
+		return update, fmt.Errorf("%w: %s", ErrUpdateNotDelivered, strings.Join(update.Failed, ", "))
+	}
+
+	err = c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(update).Error; err != nil {
+			return fmt.Errorf("failed to record update: %w", err)
+		}
+		if err := tx.Model(incident).Updates(map[string]interface{}{
+			"last_stakeholder_update_at": update.CreatedAt,
+			"update_reminded_at":         nil,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update incident: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return update, err
+	}
+	log.Printf("Stakeholder update %s (%s) for incident %s sent to %s by %s",
+		update.UpdateID, update.Template, incident.IncidentID, strings.Join(update.Targets, ", "), by)
+	return update, nil
+}
+
+// SendUpdateAction sends a stakeholder update from a comms template
+type SendUpdateAction struct {
+	comms *Comms
+}
+
+// NewSendUpdateAction creates a send_update action
+func NewSendUpdateAction(comms *Comms) *SendUpdateAction {
+	return &SendUpdateAction{comms: comms}
+}
+
+func (a *SendUpdateAction) Execute(params map[string]interface{}) (interface{}, error) {
+	return a.ExecuteContext(context.Background(), params)
+}
+
+func (a *SendUpdateAction) ExecuteContext(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	incidentID := getStringParam(params, "incident_id", "")
+	if incidentID == "" {
+		return nil, fmt.Errorf("incident_id parameter is required")
+	}
+	templateID := getStringParam(params, "template", "")
+	if templateID == "" {
+		return nil, fmt.Errorf("template parameter is required")
+	}
+	update, err := a.comms.Send(ctx, incidentID, templateID, getStringParam(params, "message", ""),
+		getListParam(params, "targets"), "playbook")
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"update_id": update.UpdateID,
+		"subject":   update.Subject,
+		"targets":   update.Targets,
+		"failed":    update.Failed,
+	}, nil
+}
+
+func (a *SendUpdateAction) Describe() ActionDescriptor {
+	return ActionDescriptor{
+		Name:        "send_update",
+		Description: "Render a comms template for an incident and send it to stakeholders",
+		Parameters: []ActionParameter{
+			{Name: "incident_id", Type: "string", Required: true, Description: "Incident the update is about"},
+			{Name: "template", Type: "string", Required: true, Description: "Comms template ID, e.g. internal_update, exec_update or customer_notice"},
+			{Name: "message", Type: "string", Description: "Latest status, available to the template as {{ .Message }}"},
+			{Name: "targets", Type: "any", Description: "Notify targets, as a comma-separated list or an array; defaults to the template's"},
+		},
+		Outputs: []ActionOutput{
+			{Name: "update_id", Type: "string", Required: true, Description: "Recorded stakeholder update"},
+			{Name: "subject", Type: "string", Description: "Rendered subject"},
+			{Name: "targets", Type: "array", Items: "string", Required: true, Description: "Targets that received the update"},
+			{Name: "failed", Type: "array", Items: "string", Description: "Targets that could not be reached"},
+		},
+		SideEffect:   SideEffectExternal,
+		Notification: true,
+	}
+}
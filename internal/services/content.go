@@ -0,0 +1,642 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidContent is returned when applying a bundle that fails validation
+var ErrInvalidContent = errors.New("content bundle is invalid")
+
+// ErrStalePlan is returned when the live content changed after a bundle was
+// planned
+var ErrStalePlan = errors.New("live content changed since the plan was made")
+
+// contentID restricts rule and playbook IDs to names that are safe as file
+// names
+var contentID = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ContentBundle is a desired set of rules, playbooks and watchlists, each a
+// YAML document in the format of its directory. Content missing from the
+// bundle is removed on apply.
+type ContentBundle struct {
+	Rules      []string `json:"rules"`
+	Playbooks  []string `json:"playbooks"`
+	Watchlists []string `json:"watchlists"`
+}
+
+// ContentDirs are the directories content is loaded from
+type ContentDirs struct {
+	Rules      string
+	Playbooks  string
+	Watchlists string
+}
+
+// Content change actions
+const (
+	ContentAdd    = "add"
+	ContentChange = "change"
+	ContentRemove = "remove"
+)
+
+// FieldChange is one changed value in a rule or playbook, addressed by its
+// YAML path (e.g. "rule.conditions[1].threshold"). Before is null for added
+// fields and After for removed ones.
+type FieldChange struct {
+	Path   string      `json:"path"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// ContentItemChange is an added, changed or removed rule or playbook
+type ContentItemChange struct {
+	ID     string        `json:"id"`
+	Action string        `json:"action"`
+	File   string        `json:"file"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// ContentPlan is the difference between a bundle and the live content
+type ContentPlan struct {
+	// PlanID identifies the live content and bundle the plan was made from;
+	// applying with it fails if either has changed
+	PlanID     string              `json:"plan_id"`
+	Valid      bool                `json:"valid"`
+	Errors     []string            `json:"errors,omitempty"`
+	Rules      []ContentItemChange `json:"rules"`
+	Playbooks  []ContentItemChange `json:"playbooks"`
+	Watchlists []ContentItemChange `json:"watchlists"`
+	Summary    map[string]int      `json:"summary"`
+}
+
+// contentItem is a parsed rule or playbook document
+type contentItem struct {
+	ID   string
+	File string
+	Raw  string
+	Doc  interface{}
+}
+
+// ContentManager plans and applies changes to the rules, playbooks and
+// watchlists directories, reloading the detection engine and orchestrator on
+// apply
+type ContentManager struct {
+	engine       *DetectionEngine
+	orchestrator *Orchestrator
+	actions      *ActionRegistry
+	dirs         ContentDirs
+
+	mu sync.Mutex
+}
+
+// NewContentManager creates a content manager
+func NewContentManager(engine *DetectionEngine, orchestrator *Orchestrator, actions *ActionRegistry, dirs ContentDirs) *ContentManager {
+	return &ContentManager{
+		engine:       engine,
+		orchestrator: orchestrator,
+		actions:      actions,
+		dirs:         dirs,
+	}
+}
+
+// Plan diffs a bundle against the live content without changing anything
+func (m *ContentManager) Plan(bundle ContentBundle) (*ContentPlan, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	plan, _, err := m.plan(bundle)
+	return plan, err
+}
+
+// Apply writes a bundle to the content directories and reloads them. Nothing is written unless the whole bundle is valid, and the bundle
+// applies as a whole: if writing any file or reloading fails, every file is
+// restored and reloaded. With a planID, the bundle is only applied if the
+// plan it came from is still current.
+func (m *ContentManager) Apply(bundle ContentBundle, planID, actor string) (*ContentPlan, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	plan, err := m.apply(bundle, planID)
+	if err != nil {
+		return plan, err
+	}
+
+	log.Printf("Content plan %s applied by %s: %d added, %d changed, %d removed",
+		plan.PlanID, actor, plan.Summary[ContentAdd], plan.Summary[ContentChange], plan.Summary[ContentRemove])
+	return plan, nil
+}
+
+// Modify applies a bundle computed from the live content by change, holding
+// the lock so nothing can change in between
+func (m *ContentManager) Modify(change func(live ContentBundle) (ContentBundle, error)) (*ContentPlan, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	live, err := m.liveBundle()
+	if err != nil {
+		return nil, err
+	}
+	bundle, err := change(live)
+	if err != nil {
+		return nil, err
+	}
+	return m.apply(bundle, "")
+}
+
+// apply is Apply for a caller holding the lock
+func (m *ContentManager) apply(bundle ContentBundle, planID string) (*ContentPlan, error) {
+	plan, state, err := m.plan(bundle)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.Valid {
+		return plan, ErrInvalidContent
+	}
+	if planID != "" && planID != plan.PlanID {
+		return plan, ErrStalePlan
+	}
+
+	// Stage every directory before changing any, so rules never go live
+	// without the playbooks and watchlists they were validated against
+	txn := &contentTxn{}
+	for _, dir := range []struct {
+		path    string
+		changes []ContentItemChange
+		input   contentDiffInput
+	}{
+		{m.dirs.Rules, plan.Rules, state.rules},
+		{m.dirs.Playbooks, plan.Playbooks, state.playbooks},
+		{m.dirs.Watchlists, plan.Watchlists, state.watchlists},
+	} {
+		if err := txn.stage(dir.path, dir.changes, dir.input); err != nil {
+			txn.rollback()
+			return plan, err
+		}
+	}
+	if err := txn.commit(); err != nil {
+		txn.rollback()
+		return plan, err
+	}
+	if err := m.reload(); err != nil {
+		// Put the files back and reload them, so what's loaded matches
+		// what's on disk again
+		txn.rollback()
+		if restoreErr := m.reload(); restoreErr != nil {
+			log.Printf("Failed to reload content after rolling back: %v", restoreErr)
+		}
+		return plan, err
+	}
+	txn.finish()
+	return plan, nil
+}
+
+// contentState is the live and desired content a plan was made from
+type contentState struct {
+	rules      contentDiffInput
+	playbooks  contentDiffInput
+	watchlists contentDiffInput
+}
+
+type contentDiffInput struct {
+	live    map[string]contentItem
+	desired map[string]contentItem
+}
+
+// liveBundle returns the live content as a bundle
+func (m *ContentManager) liveBundle() (ContentBundle, error) {
+	var bundle ContentBundle
+	for _, kind := range []struct {
+		dir, name string
+		docs      *[]string
+	}{
+		{m.dirs.Rules, "rule", &bundle.Rules},
+		{m.dirs.Playbooks, "playbook", &bundle.Playbooks},
+		{m.dirs.Watchlists, "watchlist", &bundle.Watchlists},
+	} {
+		items, err := readContentDir(kind.dir, kind.name)
+		if err != nil {
+			return bundle, err
+		}
+		ids := make([]string, 0, len(items))
+		for id := range items {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			*kind.docs = append(*kind.docs, items[id].Raw)
+		}
+	}
+	return bundle, nil
+}
+
+func (m *ContentManager) plan(bundle ContentBundle) (*ContentPlan, *contentState, error) {
+	liveRules, err := readContentDir(m.dirs.Rules, "rule")
+	if err != nil {
+		return nil, nil, err
+	}
+	livePlaybooks, err := readContentDir(m.dirs.Playbooks, "playbook")
+	if err != nil {
+		return nil, nil, err
+	}
+	liveWatchlists, err := readContentDir(m.dirs.Watchlists, "watchlist")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var problems []string
+	desiredRules := parseBundleItems(bundle.Rules, "rule", &problems)
+	desiredPlaybooks := parseBundleItems(bundle.Playbooks, "playbook", &problems)
+	desiredWatchlists := parseBundleItems(bundle.Watchlists, "watchlist", &problems)
+	problems = append(problems, m.validateRules(bundle.Rules, desiredPlaybooks, desiredWatchlists)...)
+	problems = append(problems, m.validatePlaybooks(bundle.Playbooks)...)
+
+	state := &contentState{
+		rules:      contentDiffInput{live: liveRules, desired: desiredRules},
+		playbooks:  contentDiffInput{live: livePlaybooks, desired: desiredPlaybooks},
+		watchlists: contentDiffInput{live: liveWatchlists, desired: desiredWatchlists},
+	}
+	plan := &ContentPlan{
+		PlanID:     planFingerprint(state),
+		Valid:      len(problems) == 0,
+		Errors:     problems,
+		Rules:      diffContent(liveRules, desiredRules, m.dirs.Rules),
+		Playbooks:  diffContent(livePlaybooks, desiredPlaybooks, m.dirs.Playbooks),
+		Watchlists: diffContent(liveWatchlists, desiredWatchlists, m.dirs.Watchlists),
+		Summary:    map[string]int{ContentAdd: 0, ContentChange: 0, ContentRemove: 0},
+	}
+	for _, changes := range [][]ContentItemChange{plan.Rules, plan.Playbooks, plan.Watchlists} {
+		for _, change := range changes {
+			plan.Summary[change.Action]++
+		}
+	}
+	return plan, state, nil
+}
+
+func (m *ContentManager) validateRules(docs []string, playbooks, watchlists map[string]contentItem) []string {
+	var problems []string
+	for i, doc := range docs {
+		var rule Rule
+		if err := yaml.Unmarshal([]byte(doc), &rule); err != nil {
+			continue // reported by parseBundleItems
+		}
+		label := fmt.Sprintf("rule %d (%s)", i+1, rule.Rule.ID)
+		if len(rule.Rule.Conditions) == 0 {
+			problems = append(problems, label+": no conditions")
+		}
+		if err := m.engine.deriveRuleSeverity(&rule); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", label, err))
+		}
+		if err := compileRule(&rule); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", label, err))
+		}
+		for _, cond := range rule.Rule.Conditions {
+			if cond.Operator == "in_watchlist" {
+				if _, ok := watchlists[cond.Watchlist]; !ok {
+					problems = append(problems, fmt.Sprintf("%s: watchlist %q is not in the bundle", label, cond.Watchlist))
+				}
+			}
+		}
+		for _, action := range rule.Rule.Actions {
+			if action.Type == "execute_playbook" {
+				if _, ok := playbooks[action.Playbook]; !ok {
+					problems = append(problems, fmt.Sprintf("%s: playbook %q is not in the bundle", label, action.Playbook))
+				}
+			}
+		}
+	}
+	return problems
+}
+
+func (m *ContentManager) validatePlaybooks(docs []string) []string {
+	var problems []string
+	for i, doc := range docs {
+		var playbook Playbook
+		if err := yaml.Unmarshal([]byte(doc), &playbook); err != nil {
+			continue // reported by parseBundleItems
+		}
+		label := fmt.Sprintf("playbook %d (%s)", i+1, playbook.Playbook.ID)
+		if len(playbook.Playbook.Steps) == 0 {
+			problems = append(problems, label+": no steps")
+		}
+		stepIDs := make(map[string]bool)
+		for j, step := range playbook.Playbook.Steps {
+			if step.ID == "" {
+				problems = append(problems, fmt.Sprintf("%s: step %d has no id", label, j+1))
+			} else if stepIDs[step.ID] {
+				problems = append(problems, fmt.Sprintf("%s: duplicate step id %q", label, step.ID))
+			}
+			stepIDs[step.ID] = true
+			switch {
+			case step.Manual && (step.Action != "" || step.Transform != nil),
+				step.Transform != nil && step.Action != "":
+				problems = append(problems, fmt.Sprintf("%s: step %q must have only one of action, transform or manual", label, step.ID))
+			case step.Transform != nil:
+				if _, err := compileTransform(step.Transform); err != nil {
+					problems = append(problems, fmt.Sprintf("%s: step %q has an invalid transform: %v", label, step.ID, err))
+				}
+			case !step.Manual && !m.actions.Has(step.Action):
+				problems = append(problems, fmt.Sprintf("%s: step %q uses unknown action %q", label, step.ID, step.Action))
+			}
+			if err := validateOutputSchema(step.Outputs); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: step %q: %v", label, step.ID, err))
+			}
+		}
+	}
+	return problems
+}
+
+// contentWrite is one file an apply creates, replaces or removes
+type contentWrite struct {
+	dest   string
+	tmp    string // staged new content; empty for a removal
+	backup string // hard link to the previous content; empty for a new file
+	done   bool
+}
+
+// contentTxn applies file changes across all content directories as a
+// unit: everything is staged first, then committed, and a failure at any
+// point, including the reload afterwards, puts every file back
+type contentTxn struct {
+	writes []*contentWrite
+}
+
+// stage writes the added and changed files of one directory next to their
+// destinations and links a backup of each file to be replaced or removed.
+// Nothing live changes.
+func (t *contentTxn) stage(dir string, changes []ContentItemChange, input contentDiffInput) error {
+	for _, change := range changes {
+		w := &contentWrite{dest: change.File}
+		t.writes = append(t.writes, w)
+		if change.Action != ContentAdd {
+			w.backup = filepath.Join(filepath.Dir(change.File), fmt.Sprintf(".content-%s.bak", filepath.Base(change.File)))
+			os.Remove(w.backup)
+			if err := os.Link(change.File, w.backup); err != nil {
+				if os.IsNotExist(err) {
+					w.backup = ""
+				} else {
+					return fmt.Errorf("failed to back up %s: %w", change.File, err)
+				}
+			}
+		}
+		if change.Action == ContentRemove {
+			continue
+		}
+
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+		tmp, err := os.CreateTemp(dir, ".content-*.tmp")
+		if err != nil {
+			return fmt.Errorf("failed to stage %s: %w", change.File, err)
+		}
+		w.tmp = tmp.Name()
+		_, err = tmp.WriteString(input.desired[change.ID].Raw)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to stage %s: %w", change.File, err)
+		}
+	}
+	return nil
+}
+
+// commit renames the staged files into place and removes deleted ones
+func (t *contentTxn) commit() error {
+	for _, w := range t.writes {
+		if w.tmp != "" {
+			if err := os.Rename(w.tmp, w.dest); err != nil {
+				return fmt.Errorf("failed to write %s: %w", w.dest, err)
+			}
+		} else if err := os.Remove(w.dest); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", w.dest, err)
+		}
+		w.done = true
+	}
+	return nil
+}
+
+// rollback restores every committed file from its backup, removing the new
+// ones, and discards what was staged
+func (t *contentTxn) rollback() {
+	for i := len(t.writes) - 1; i >= 0; i-- {
+		w := t.writes[i]
+		switch {
+		case !w.done:
+			if w.tmp != "" {
+				os.Remove(w.tmp)
+			}
+			if w.backup != "" {
+				os.Remove(w.backup)
+			}
+		case w.backup != "":
+			if err := os.Rename(w.backup, w.dest); err != nil {
+				log.Printf("Failed to restore %s from %s: %v", w.dest, w.backup, err)
+			}
+		default:
+			if err := os.Remove(w.dest); err != nil && !os.IsNotExist(err) {
+				log.Printf("Failed to remove %s: %v", w.dest, err)
+			}
+		}
+	}
+}
+
+// finish drops the backups of a committed apply
+func (t *contentTxn) finish() {
+	for _, w := range t.writes {
+		if w.backup != "" {
+			os.Remove(w.backup)
+		}
+	}
+}
+
+func (m *ContentManager) reload() error {
+	if err := m.orchestrator.LoadPlaybooks(m.dirs.Playbooks); err != nil {
+		return err
+	}
+	if err := m.engine.LoadWatchlists(m.dirs.Watchlists); err != nil {
+		return err
+	}
+	return m.engine.LoadRules(m.dirs.Rules)
+}
+
+// readContentDir reads the rules or playbooks in a directory by ID
+func readContentDir(dir, kind string) (map[string]contentItem, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to glob %ss: %w", kind, err)
+	}
+	files2, err := filepath.Glob(filepath.Join(dir, "*.yml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to glob %ss: %w", kind, err)
+	}
+	files = append(files, files2...)
+
+	items := make(map[string]contentItem, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		item, err := parseContentItem(string(data), kind)
+		if err != nil {
+			// Unloadable files aren't live content
+			continue
+		}
+		item.File = file
+		items[item.ID] = item
+	}
+	return items, nil
+}
+
+// parseBundleItems parses a bundle's documents, reporting problems
+func parseBundleItems(docs []string, kind string, problems *[]string) map[string]contentItem {
+	items := make(map[string]contentItem, len(docs))
+	for i, doc := range docs {
+		item, err := parseContentItem(doc, kind)
+		if err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s %d: %v", kind, i+1, err))
+			continue
+		}
+		if !contentID.MatchString(item.ID) {
+			*problems = append(*problems, fmt.Sprintf("%s %d: invalid id %q", kind, i+1, item.ID))
+			continue
+		}
+		if _, dup := items[item.ID]; dup {
+			*problems = append(*problems, fmt.Sprintf("%s %d: duplicate id %q", kind, i+1, item.ID))
+			continue
+		}
+		items[item.ID] = item
+	}
+	return items
+}
+
+// parseContentItem parses a rule or playbook document, whose ID is at
+// <kind>.id
+func parseContentItem(raw, kind string) (contentItem, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+		return contentItem{}, fmt.Errorf("invalid YAML: %w", err)
+	}
+	body, ok := doc[kind].(map[string]interface{})
+	if !ok {
+		return contentItem{}, fmt.Errorf("missing top-level %q key", kind)
+	}
+	id, _ := body["id"].(string)
+	if id == "" {
+		return contentItem{}, fmt.Errorf("missing %s.id", kind)
+	}
+	return contentItem{ID: id, Raw: raw, Doc: doc}, nil
+}
+
+// diffContent lists added, changed and removed items ordered by ID. New
+// items are written to <dir>/<id>.yaml; changed ones keep their file.
+func diffContent(live, desired map[string]contentItem, dir string) []ContentItemChange {
+	changes := []ContentItemChange{}
+	for id, want := range desired {
+		have, ok := live[id]
+		if !ok {
+			changes = append(changes, ContentItemChange{
+				ID: id, Action: ContentAdd, File: filepath.Join(dir, id+".yaml"),
+				Fields: diffValues("", nil, want.Doc),
+			})
+			continue
+		}
+		if fields := diffValues("", have.Doc, want.Doc); len(fields) > 0 {
+			changes = append(changes, ContentItemChange{ID: id, Action: ContentChange, File: have.File, Fields: fields})
+		}
+	}
+	for id, have := range live {
+		if _, ok := desired[id]; !ok {
+			changes = append(changes, ContentItemChange{ID: id, Action: ContentRemove, File: have.File})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].ID < changes[j].ID })
+	return changes
+}
+
+// diffValues compares two decoded YAML values, descending into maps and
+// lists so each change is reported at the deepest differing path
+func diffValues(path string, before, after interface{}) []FieldChange {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if (beforeIsMap || before == nil) && (afterIsMap || after == nil) && (beforeIsMap || afterIsMap) {
+		keys := make(map[string]bool)
+		for k := range beforeMap {
+			keys[k] = true
+		}
+		for k := range afterMap {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		var changes []FieldChange
+		for _, k := range sorted {
+			changes = append(changes, diffValues(joinPath(path, k), beforeMap[k], afterMap[k])...)
+		}
+		return changes
+	}
+
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if beforeIsList && afterIsList {
+		var changes []FieldChange
+		for i := 0; i < len(beforeList) || i < len(afterList); i++ {
+			var b, a interface{}
+			if i < len(beforeList) {
+				b = beforeList[i]
+			}
+			if i < len(afterList) {
+				a = afterList[i]
+			}
+			changes = append(changes, diffValues(fmt.Sprintf("%s[%d]", path, i), b, a)...)
+		}
+		return changes
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+	return []FieldChange{{Path: path, Before: before, After: after}}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// planFingerprint hashes the live and desired content, in ID order
+func planFingerprint(state *contentState) string {
+	h := sha256.New()
+	for _, part := range []map[string]contentItem{
+		state.rules.live, state.rules.desired, state.playbooks.live, state.playbooks.desired,
+		state.watchlists.live, state.watchlists.desired,
+	} {
+		ids := make([]string, 0, len(part))
+		for id := range part {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			fmt.Fprintf(h, "%s\x00%s\x00%s\x00", id, part[id].File, part[id].Raw)
+		}
+		h.Write([]byte{0xff})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,926 @@
+package services
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// Content pack errors
+var (
+	ErrPackNotFound   = errors.New("content pack not found")
+	ErrPackConflict   = errors.New("content pack conflicts with existing content")
+	ErrPackDependency = errors.New("content pack dependency error")
+	ErrPackUntrusted  = errors.New("content pack registry can't be trusted")
+)
+
+// Content pack sources
+const (
+	PackSourceLocal    = "local"
+	PackSourceRegistry = "registry"
+)
+
+// maxPackSize bounds a downloaded pack archive
+const maxPackSize = 20 << 20
+
+// PackManifest is a pack's pack.yaml. A pack directory (or the root of a
+// registry archive) holds pack.yaml and rules/, playbooks/ and watchlists/
+// directories of YAML files.
+type PackManifest struct {
+	Pack struct {
+		Name        string `yaml:"name" json:"name"`
+		Version     string `yaml:"version" json:"version"`
+		Description string `yaml:"description" json:"description"`
+		// Requires maps pack names to version constraints such as ">=1.2.0"
+		Requires map[string]string `yaml:"requires" json:"requires,omitempty"`
+	} `yaml:"pack"`
+}
+
+// PackInfo describes an available pack version
+type PackInfo struct {
+	Name        string            `json:"name"`
+	Version     string            `json:"version"`
+	Description string            `json:"description"`
+	Requires    map[string]string `json:"requires,omitempty"`
+	Source      string            `json:"source"`
+	Installed   string            `json:"installed,omitempty"` // installed version, if any
+}
+
+// registryIndex is a registry's index.json, signed by index.json.sig: the
+// base64 Ed25519 signature of its bytes under the registry's key
+type registryIndex struct {
+	Packs []struct {
+		Name        string            `json:"name"`
+		Version     string            `json:"version"`
+		Description string            `json:"description"`
+		Requires    map[string]string `json:"requires"`
+		URL         string            `json:"url"`    // archive, relative to the registry
+		SHA256      string            `json:"sha256"` // of the archive; required
+	} `json:"packs"`
+}
+
+// loadedPack is a fetched pack and its content
+type loadedPack struct {
+	Manifest PackManifest
+	Source   string
+	Checksum string
+	Bundle   ContentBundle
+}
+
+// PackInstallRequest selects a pack to install
+type PackInstallRequest struct {
+	Name string `json:"name" binding:"required"`
+	// Version is a version constraint; the newest matching version is used
+	Version string `json:"version"`
+	// Source is "local" or "registry"; by default the local directory is
+	// tried first
+	Source string `json:"source"`
+	// Force replaces content with the same IDs that the pack doesn't own
+	Force bool `json:"force"`
+}
+
+// PackInstallResult reports an install
+type PackInstallResult struct {
+	Installed []models.ContentPack `json:"installed"` // the pack and any dependencies, in install order
+	Plan      *ContentPlan         `json:"plan"`
+}
+
+// ContentPackManager installs content packs from a local directory or a
+// registry URL into the content directories through the ContentManager,
+// tracking installed versions and dependencies
+type ContentPackManager struct {
+	db          *gorm.DB
+	content     *ContentManager
+	dir         string
+	registryURL string
+	registryKey ed25519.PublicKey
+	client      *http.Client
+}
+
+// NewContentPackManager creates a content pack manager. A registry must be
+// served over HTTPS and come with the base64 Ed25519 public key its index
+// is signed with, since packs install playbooks that run scripts.
+func NewContentPackManager(db *gorm.DB, content *ContentManager, dir, registryURL, registryKey string) (*ContentPackManager, error) {
+	p := &ContentPackManager{
+		db:          db,
+		content:     content,
+		dir:         dir,
+		registryURL: strings.TrimSuffix(registryURL, "/"),
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}
+	if p.registryURL == "" {
+		return p, nil
+	}
+	if err := requireHTTPS(p.registryURL); err != nil {
+		return nil, fmt.Errorf("invalid registry URL: %w", err)
+	}
+	if registryKey == "" {
+		return nil, errors.New("a registry needs the key its index is signed with")
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(registryKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid registry key: expected a base64 Ed25519 public key")
+	}
+	p.registryKey = ed25519.PublicKey(key)
+	return p, nil
+}
+
+// requireHTTPS rejects URLs that aren't absolute https ones
+func requireHTTPS(target string) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%s is not an https URL", target)
+	}
+	return nil
+}
+
+// Installed lists installed packs ordered by name
+func (p *ContentPackManager) Installed() ([]models.ContentPack, error) {
+	packs := []models.ContentPack{}
+	if err := p.db.Order("name ASC").Find(&packs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch content packs: %w", err)
+	}
+	return packs, nil
+}
+
+// Available lists the packs in the local directory and registry
+func (p *ContentPackManager) Available(ctx context.Context) ([]PackInfo, error) {
+	installed, err := p.installedByName()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := []PackInfo{}
+	local, err := p.localPacks()
+	if err != nil {
+		return nil, err
+	}
+	for _, manifest := range local {
+		infos = append(infos, PackInfo{
+			Name:        manifest.Pack.Name,
+			Version:     manifest.Pack.Version,
+			Description: manifest.Pack.Description,
+			Requires:    manifest.Pack.Requires,
+			Source:      PackSourceLocal,
+		})
+	}
+	if p.registryURL != "" {
+		index, err := p.fetchIndex(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range index.Packs {
+			infos = append(infos, PackInfo{
+				Name:        entry.Name,
+				Version:     entry.Version,
+				Description: entry.Description,
+				Requires:    entry.Requires,
+				Source:      PackSourceRegistry,
+			})
+		}
+	}
+
+	for i := range infos {
+		if pack, ok := installed[infos[i].Name]; ok {
+			infos[i].Installed = pack.Version
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Name != infos[j].Name {
+			return infos[i].Name < infos[j].Name
+		}
+		return compareVersions(infos[i].Version, infos[j].Version) > 0
+	})
+	return infos, nil
+}
+
+// Install installs or upgrades a pack along with any required packs that
+// aren't installed at a matching version, in one content apply
+func (p *ContentPackManager) Install(ctx context.Context, req PackInstallRequest, actor string) (*PackInstallResult, error) {
+	if req.Source != "" && req.Source != PackSourceLocal && req.Source != PackSourceRegistry {
+		return nil, fmt.Errorf("invalid source %q: expected %s or %s", req.Source, PackSourceLocal, PackSourceRegistry)
+	}
+	installed, err := p.installedByName()
+	if err != nil {
+		return nil, err
+	}
+
+	var order []*loadedPack
+	visiting := make(map[string]bool)
+	var resolve func(name, constraint string, root bool) error
+	resolve = func(name, constraint string, root bool) error {
+		if visiting[name] {
+			return fmt.Errorf("%w: dependency cycle through %s", ErrPackDependency, name)
+		}
+		for _, pack := range order {
+			if pack.Manifest.Pack.Name == name {
+				if !versionSatisfies(pack.Manifest.Pack.Version, constraint) {
+					return fmt.Errorf("%w: %s is required but %s was selected", ErrPackDependency, packRef(name, constraint), pack.Manifest.Pack.Version)
+				}
+				return nil
+			}
+		}
+		if current, ok := installed[name]; ok && !root && versionSatisfies(current.Version, constraint) {
+			return nil
+		}
+
+		pack, err := p.fetch(ctx, name, constraint, req.Source)
+		if err != nil {
+			if !root && errors.Is(err, ErrPackNotFound) {
+				return fmt.Errorf("%w: required pack %s: %v", ErrPackDependency, packRef(name, constraint), err)
+			}
+			return err
+		}
+		deps := make([]string, 0, len(pack.Manifest.Pack.Requires))
+		for dep := range pack.Manifest.Pack.Requires {
+			deps = append(deps, dep)
+		}
+		sort.Strings(deps)
+		visiting[name] = true
+		for _, dep := range deps {
+			if err := resolve(dep, pack.Manifest.Pack.Requires[dep], false); err != nil {
+				return err
+			}
+		}
+		visiting[name] = false
+		order = append(order, pack)
+		return nil
+	}
+	if err := resolve(req.Name, req.Version, true); err != nil {
+		return nil, err
+	}
+
+	// Packs already installed that depend on one being upgraded must still
+	// be satisfied
+	reinstalled := make(map[string]bool, len(order))
+	for _, pack := range order {
+		reinstalled[pack.Manifest.Pack.Name] = true
+	}
+	for _, pack := range order {
+		for _, other := range installed {
+			if reinstalled[other.Name] {
+				continue
+			}
+			if constraint, ok := other.Requires[pack.Manifest.Pack.Name]; ok && !versionSatisfies(pack.Manifest.Pack.Version, constraint) {
+				return nil, fmt.Errorf("%w: installed pack %s requires %s", ErrPackDependency, other.Name, packRef(pack.Manifest.Pack.Name, constraint))
+			}
+		}
+	}
+
+	plan, err := p.content.Modify(func(live ContentBundle) (ContentBundle, error) {
+		return mergePacks(live, order, installed, req.Force)
+	})
+	if err != nil {
+		if plan != nil && errors.Is(err, ErrInvalidContent) {
+			return &PackInstallResult{Plan: plan}, err
+		}
+		return nil, err
+	}
+
+	result := &PackInstallResult{Plan: plan}
+	err = p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, pack := range order {
+			record := models.ContentPack{
+				Name:        pack.Manifest.Pack.Name,
+				Version:     pack.Manifest.Pack.Version,
+				Description: pack.Manifest.Pack.Description,
+				InstalledBy: actor,
+				Source:      pack.Source,
+				Checksum:    pack.Checksum,
+				Requires:    pack.Manifest.Pack.Requires,
+				Rules:       bundleIDs(pack.Bundle.Rules, "rule"),
+				Playbooks:   bundleIDs(pack.Bundle.Playbooks, "playbook"),
+				Watchlists:  bundleIDs(pack.Bundle.Watchlists, "watchlist"),
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "name"}},
+				DoUpdates: clause.AssignmentColumns([]string{
+					"version", "description", "installed_by", "source", "checksum",
+					"requires", "rules", "playbooks", "watchlists", "updated_at",
+				}),
+			}).Create(&record).Error; err != nil {
+				return fmt.Errorf("failed to record content pack %s: %w", record.Name, err)
+			}
+			result.Installed = append(result.Installed, record)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pack := range result.Installed {
+		log.Printf("Content pack %s %s installed by %s from %s", pack.Name, pack.Version, actor, pack.Source)
+	}
+	return result, nil
+}
+
+// Uninstall removes a pack's content, unless another installed pack
+// requires it
+func (p *ContentPackManager) Uninstall(ctx context.Context, name, actor string) (*ContentPlan, error) {
+	installed, err := p.installedByName()
+	if err != nil {
+		return nil, err
+	}
+	pack, ok := installed[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not installed", ErrPackNotFound, name)
+	}
+	for _, other := range installed {
+		if _, ok := other.Requires[name]; ok {
+			return nil, fmt.Errorf("%w: installed pack %s requires %s", ErrPackDependency, other.Name, name)
+		}
+	}
+
+	plan, err := p.content.Modify(func(live ContentBundle) (ContentBundle, error) {
+		return ContentBundle{
+			Rules:      withoutIDs(live.Rules, "rule", pack.Rules),
+			Playbooks:  withoutIDs(live.Playbooks, "playbook", pack.Playbooks),
+			Watchlists: withoutIDs(live.Watchlists, "watchlist", pack.Watchlists),
+		}, nil
+	})
+	if err != nil {
+		return plan, err
+	}
+	if err := p.db.WithContext(ctx).Delete(&models.ContentPack{}, "name = ?", name).Error; err != nil {
+		return plan, fmt.Errorf("failed to remove content pack record: %w", err)
+	}
+
+	log.Printf("Content pack %s %s uninstalled by %s", name, pack.Version, actor)
+	return plan, nil
+}
+
+func (p *ContentPackManager) installedByName() (map[string]models.ContentPack, error) {
+	packs, err := p.Installed()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]models.ContentPack, len(packs))
+	for _, pack := range packs {
+		byName[pack.Name] = pack
+	}
+	return byName, nil
+}
+
+// mergePacks replaces the content owned by the installed versions of packs
+// with their new content. Content with the same ID owned by another pack,
+// or by no pack, is a conflict unless forced.
+func mergePacks(live ContentBundle, packs []*loadedPack, installed map[string]models.ContentPack, force bool) (ContentBundle, error) {
+	owners := make(map[string]string)
+	for _, pack := range installed {
+		for _, ids := range []struct {
+			kind string
+			ids  []string
+		}{{"rule", pack.Rules}, {"playbook", pack.Playbooks}, {"watchlist", pack.Watchlists}} {
+			for _, id := range ids.ids {
+				owners[ids.kind+":"+id] = pack.Name
+			}
+		}
+	}
+
+	merged := live
+	for _, pack := range packs {
+		name := pack.Manifest.Pack.Name
+		previous := installed[name]
+		merged.Rules = withoutIDs(merged.Rules, "rule", previous.Rules)
+		merged.Playbooks = withoutIDs(merged.Playbooks, "playbook", previous.Playbooks)
+		merged.Watchlists = withoutIDs(merged.Watchlists, "watchlist", previous.Watchlists)
+
+		for _, kind := range []struct {
+			name   string
+			docs   []string
+			target *[]string
+		}{
+			{"rule", pack.Bundle.Rules, &merged.Rules},
+			{"playbook", pack.Bundle.Playbooks, &merged.Playbooks},
+			{"watchlist", pack.Bundle.Watchlists, &merged.Watchlists},
+		} {
+			ids := bundleIDs(kind.docs, kind.name)
+			existing := make(map[string]bool)
+			for _, id := range bundleIDs(*kind.target, kind.name) {
+				existing[id] = true
+			}
+			for _, id := range ids {
+				if !existing[id] {
+					continue
+				}
+				if !force {
+					owner := owners[kind.name+":"+id]
+					if owner == "" {
+						owner = "no pack"
+					}
+					return ContentBundle{}, fmt.Errorf("%w: %s %s from %s is already defined (owned by %s)", ErrPackConflict, kind.name, id, name, owner)
+				}
+			}
+			*kind.target = append(withoutIDs(*kind.target, kind.name, ids), kind.docs...)
+		}
+	}
+	return merged, nil
+}
+
+// bundleIDs returns the IDs of a bundle's documents, skipping unparseable
+// ones (which fail validation on apply)
+func bundleIDs(docs []string, kind string) []string {
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		if item, err := parseContentItem(doc, kind); err == nil {
+			ids = append(ids, item.ID)
+		}
+	}
+	return ids
+}
+
+// withoutIDs drops the documents with the given IDs
+func withoutIDs(docs []string, kind string, ids []string) []string {
+	drop := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		drop[id] = true
+	}
+	kept := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		if item, err := parseContentItem(doc, kind); err == nil && drop[item.ID] {
+			continue
+		}
+		kept = append(kept, doc)
+	}
+	return kept
+}
+
+// fetch loads the newest version of a pack matching constraint
+func (p *ContentPackManager) fetch(ctx context.Context, name, constraint, source string) (*loadedPack, error) {
+	if source != PackSourceRegistry {
+		pack, err := p.fetchLocal(name, constraint)
+		if err == nil || source == PackSourceLocal || !errors.Is(err, ErrPackNotFound) {
+			return pack, err
+		}
+	}
+	if p.registryURL == "" {
+		return nil, fmt.Errorf("%w: %s is not in %s and no registry is configured", ErrPackNotFound, packRef(name, constraint), p.dir)
+	}
+	return p.fetchRegistry(ctx, name, constraint)
+}
+
+// localPacks reads the manifests of the pack directories under dir by path
+func (p *ContentPackManager) localPacks() (map[string]PackManifest, error) {
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read content packs directory: %w", err)
+	}
+
+	manifests := make(map[string]PackManifest)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(p.dir, entry.Name())
+		data, err := os.ReadFile(filepath.Join(dir, "pack.yaml"))
+		if err != nil {
+			continue
+		}
+		manifest, err := parsePackManifest(data)
+		if err != nil {
+			log.Printf("Warning: skipping content pack %s: %v", dir, err)
+			continue
+		}
+		manifests[dir] = manifest
+	}
+	return manifests, nil
+}
+
+func (p *ContentPackManager) fetchLocal(name, constraint string) (*loadedPack, error) {
+	manifests, err := p.localPacks()
+	if err != nil {
+		return nil, err
+	}
+	var bestDir string
+	var best PackManifest
+	for dir, manifest := range manifests {
+		if manifest.Pack.Name != name || !versionSatisfies(manifest.Pack.Version, constraint) {
+			continue
+		}
+		if bestDir == "" || compareVersions(manifest.Pack.Version, best.Pack.Version) > 0 {
+			bestDir, best = dir, manifest
+		}
+	}
+	if bestDir == "" {
+		return nil, fmt.Errorf("%w: %s", ErrPackNotFound, packRef(name, constraint))
+	}
+
+	files := make(map[string][]byte)
+	err = filepath.WalkDir(bestDir, func(file string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(bestDir, file)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content pack %s: %w", bestDir, err)
+	}
+
+	pack, err := packFromFiles(files)
+	if err != nil {
+		return nil, fmt.Errorf("content pack %s: %w", bestDir, err)
+	}
+	pack.Source = bestDir
+	pack.Checksum = filesChecksum(files)
+	return pack, nil
+}
+
+func (p *ContentPackManager) fetchIndex(ctx context.Context) (*registryIndex, error) {
+	body, err := p.download(ctx, p.registryURL+"/index.json", 1<<20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch registry index: %w", err)
+	}
+	signature, err := p.download(ctx, p.registryURL+"/index.json.sig", 1<<10)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch registry index signature: %w", err)
+	}
+	// base64 tools may wrap the signature
+	sig, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(signature)), ""))
+	if err != nil || !ed25519.Verify(p.registryKey, body, sig) {
+		return nil, fmt.Errorf("%w: index signature doesn't verify", ErrPackUntrusted)
+	}
+	var index registryIndex
+	if err := json.Unmarshal(body, &index); err != nil {
+		return nil, fmt.Errorf("invalid registry index: %w", err)
+	}
+	return &index, nil
+}
+
+func (p *ContentPackManager) fetchRegistry(ctx context.Context, name, constraint string) (*loadedPack, error) {
+	index, err := p.fetchIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	best := -1
+	for i, entry := range index.Packs {
+		if entry.Name != name || !versionSatisfies(entry.Version, constraint) {
+			continue
+		}
+		if best < 0 || compareVersions(entry.Version, index.Packs[best].Version) > 0 {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil, fmt.Errorf("%w: %s is not in the registry", ErrPackNotFound, packRef(name, constraint))
+	}
+	entry := index.Packs[best]
+	if entry.SHA256 == "" {
+		return nil, fmt.Errorf("%w: %s %s has no sha256", ErrPackUntrusted, name, entry.Version)
+	}
+
+	base, err := url.Parse(p.registryURL + "/")
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry URL: %w", err)
+	}
+	ref, err := url.Parse(entry.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive URL for %s %s: %w", name, entry.Version, err)
+	}
+	archiveURL := base.ResolveReference(ref).String()
+	if err := requireHTTPS(archiveURL); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPackUntrusted, err)
+	}
+	archive, err := p.download(ctx, archiveURL, maxPackSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s %s: %w", name, entry.Version, err)
+	}
+	sum := sha256.Sum256(archive)
+	checksum := hex.EncodeToString(sum[:])
+	if !strings.EqualFold(entry.SHA256, checksum) {
+		return nil, fmt.Errorf("%w: checksum mismatch for %s %s", ErrPackUntrusted, name, entry.Version)
+	}
+
+	files, err := untarPack(archive)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive for %s %s: %w", name, entry.Version, err)
+	}
+	pack, err := packFromFiles(files)
+	if err != nil {
+		return nil, fmt.Errorf("content pack %s %s: %w", name, entry.Version, err)
+	}
+	if pack.Manifest.Pack.Name != name || pack.Manifest.Pack.Version != entry.Version {
+		return nil, fmt.Errorf("archive for %s %s contains %s %s", name, entry.Version, pack.Manifest.Pack.Name, pack.Manifest.Pack.Version)
+	}
+	pack.Source = archiveURL
+	pack.Checksum = checksum
+	return pack, nil
+}
+
+func (p *ContentPackManager) download(ctx context.Context, target string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("response exceeds %d bytes", limit)
+	}
+	return data, nil
+}
+
+// untarPack reads the files of a .tar.gz pack archive. A single top-level
+// directory holding pack.yaml is stripped.
+func untarPack(archive []byte) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	reader := tar.NewReader(gz)
+	var total int64
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		total += header.Size
+		if total > maxPackSize {
+			return nil, fmt.Errorf("archive contents exceed %d bytes", maxPackSize)
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		files[path.Clean(strings.TrimPrefix(header.Name, "./"))] = data
+	}
+
+	if _, ok := files["pack.yaml"]; !ok {
+		stripped := make(map[string][]byte, len(files))
+		for name, data := range files {
+			_, rest, ok := strings.Cut(name, "/")
+			if !ok {
+				return files, nil
+			}
+			stripped[rest] = data
+		}
+		return stripped, nil
+	}
+	return files, nil
+}
+
+func parsePackManifest(data []byte) (PackManifest, error) {
+	var manifest PackManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("invalid pack.yaml: %w", err)
+	}
+	if !contentID.MatchString(manifest.Pack.Name) {
+		return manifest, fmt.Errorf("invalid pack name %q", manifest.Pack.Name)
+	}
+	if _, ok := parseVersion(manifest.Pack.Version); !ok {
+		return manifest, fmt.Errorf("invalid pack version %q", manifest.Pack.Version)
+	}
+	for dep, constraint := range manifest.Pack.Requires {
+		if _, err := parseConstraint(constraint); err != nil {
+			return manifest, fmt.Errorf("invalid constraint for %s: %w", dep, err)
+		}
+	}
+	return manifest, nil
+}
+
+// packFromFiles builds a pack from its manifest and content directories
+func packFromFiles(files map[string][]byte) (*loadedPack, error) {
+	data, ok := files["pack.yaml"]
+	if !ok {
+		return nil, fmt.Errorf("missing pack.yaml")
+	}
+	manifest, err := parsePackManifest(data)
+	if err != nil {
+		return nil, err
+	}
+
+	pack := &loadedPack{Manifest: manifest}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		dir, file := path.Split(name)
+		if ext := path.Ext(file); ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		switch dir {
+		case "rules/":
+			pack.Bundle.Rules = append(pack.Bundle.Rules, string(files[name]))
+		case "playbooks/":
+			pack.Bundle.Playbooks = append(pack.Bundle.Playbooks, string(files[name]))
+		case "watchlists/":
+			pack.Bundle.Watchlists = append(pack.Bundle.Watchlists, string(files[name]))
+		}
+	}
+	return pack, nil
+}
+
+func filesChecksum(files map[string][]byte) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%s\x00%d\x00", name, len(files[name]))
+		h.Write(files[name])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// packRef names a pack and, when given, its version constraint
+func packRef(name, constraint string) string {
+	if constraint == "" {
+		return name
+	}
+	return name + " " + constraint
+}
+
+// parseVersion parses a "1.2.3" version (a leading "v" and missing minor or
+// patch parts are allowed); anything after "-" is a pre-release
+func parseVersion(v string) ([4]string, bool) {
+	var parts [4]string
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	v, parts[3], _ = strings.Cut(v, "-")
+	nums := strings.Split(v, ".")
+	if len(nums) == 0 || len(nums) > 3 {
+		return parts, false
+	}
+	for i := 0; i < 3; i++ {
+		parts[i] = "0"
+		if i < len(nums) {
+			if _, err := strconv.Atoi(nums[i]); err != nil {
+				return parts, false
+			}
+			parts[i] = nums[i]
+		}
+	}
+	return parts, true
+}
+
+// compareVersions orders versions numerically; a pre-release sorts before
+// its release. Unparseable versions sort first.
+func compareVersions(a, b string) int {
+	pa, okA := parseVersion(a)
+	pb, okB := parseVersion(b)
+	switch {
+	case !okA && !okB:
+		return strings.Compare(a, b)
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+	for i := 0; i < 3; i++ {
+		na, _ := strconv.Atoi(pa[i])
+		nb, _ := strconv.Atoi(pb[i])
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case pa[3] == pb[3]:
+		return 0
+	case pa[3] == "":
+		return 1
+	case pb[3] == "":
+		return -1
+	}
+	return strings.Compare(pa[3], pb[3])
+}
+
+// versionBound is one comparison of a version constraint
+type versionBound struct {
+	op      string
+	version string
+}
+
+// parseConstraint parses a comma-separated constraint such as
+// ">=1.2.0,<2.0.0", "^1.2.0" (same major), "~1.2.0" (same minor), "1.2.0"
+// (exact) or "" / "*" (any)
+func parseConstraint(constraint string) ([]versionBound, error) {
+	var bounds []versionBound
+	for _, part := range strings.Split(constraint, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" || part == "*" {
+			continue
+		}
+		op := ""
+		for _, candidate := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				break
+			}
+		}
+		version := strings.TrimSpace(strings.TrimPrefix(part, op))
+		parsed, ok := parseVersion(version)
+		if !ok {
+			return nil, fmt.Errorf("invalid version constraint %q", part)
+		}
+		major, _ := strconv.Atoi(parsed[0])
+		minor, _ := strconv.Atoi(parsed[1])
+		switch op {
+		case "^":
+			upper := fmt.Sprintf("%d.0.0", major+1)
+			if major == 0 {
+				upper = fmt.Sprintf("0.%d.0", minor+1)
+			}
+			bounds = append(bounds, versionBound{">=", version}, versionBound{"<", upper})
+		case "~":
+			bounds = append(bounds, versionBound{">=", version}, versionBound{"<", fmt.Sprintf("%d.%d.0", major, minor+1)})
+		case "":
+			bounds = append(bounds, versionBound{"=", version})
+		default:
+			bounds = append(bounds, versionBound{op, version})
+		}
+	}
+	return bounds, nil
+}
+
+// versionSatisfies reports whether version meets constraint
+func versionSatisfies(version, constraint string) bool {
+	bounds, err := parseConstraint(constraint)
+	if err != nil {
+		return false
+	}
+	for _, bound := range bounds {
+		cmp := compareVersions(version, bound.version)
+		ok := false
+		switch bound.op {
+		case "=":
+			ok = cmp == 0
+		case ">=":
+			ok = cmp >= 0
+		case ">":
+			ok = cmp > 0
+		case "<=":
+			ok = cmp <= 0
+		case "<":
+			ok = cmp < 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// Count conditions match when enough earlier events, within timewindow
+// seconds of the event, are related to it:
+//
+//   - filter selects the events counted, as field: value (or field: [values])
+//     pairs; without it, events of the same event_type are counted
+//   - group_by lists fields whose values the counted events must share with
+//     the event; a legacy condition's field is its only group_by field
+//   - distinct_field (or the legacy count_field) makes count_distinct count
+//     distinct values of that field instead of events
+//
+// The event itself is counted. If the event lacks a group_by field the
+// condition doesn't match.
+
+// countFieldPattern restricts count condition fields to plain dotted names;
+// they become JSON paths in queries
+var countFieldPattern = regexp.MustCompile(`^[A-Za-z0-9_]+(\.[A-Za-z0-9_]+)*$`)
+
+// eventColumns are the fields read from event columns rather than the
+// normalized payload
+var eventColumns = map[string]bool{"event_type": true, "source": true, "severity": true}
+
+// isCountOperator reports whether op is a time-windowed count operator
+func isCountOperator(op string) bool {
+	return op == "count" || op == "count_distinct"
+}
+
+// groupBy returns the fields counted events must share with the event
+func (c Condition) groupBy() []string {
+	if len(c.GroupBy) > 0 {
+		return c.GroupBy
+	}
+	if c.Field != "" {
+		return []string{c.Field}
+	}
+	return nil
+}
+
+// distinctField returns the field count_distinct counts values of
+func (c Condition) distinctField() string {
+	if c.DistinctField != "" {
+		return c.DistinctField
+	}
+	return c.CountField
+}
+
+// validateCountCondition checks a count condition when its rule is loaded
+func validateCountCondition(c Condition) error {
+	if c.Threshold <= 0 {
+		return fmt.Errorf("%s condition needs a positive threshold", c.Operator)
+	}
+	if c.TimeWindow <= 0 {
+		return fmt.Errorf("%s condition needs a positive timewindow", c.Operator)
+	}
+	if c.Operator == "count_distinct" && c.distinctField() == "" {
+		return fmt.Errorf("count_distinct condition needs a distinct_field")
+	}
+
+	fields := append([]string{}, c.groupBy()...)
+	if c.Operator == "count_distinct" {
+		fields = append(fields, c.distinctField())
+	}
+	for field := range c.Filter {
+		fields = append(fields, field)
+	}
+	for _, field := range fields {
+		if !countFieldPattern.MatchString(field) {
+			return fmt.Errorf("invalid field name %q in %s condition", field, c.Operator)
+		}
+	}
+	for field, value := range c.Filter {
+		if _, ok := filterValues(value); !ok {
+			return fmt.Errorf("filter on %s must be a value or a list of values", field)
+		}
+	}
+	return nil
+}
+
+// filterValues returns the values a filter entry accepts
+func filterValues(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if !isScalar(item) {
+				return nil, false
+			}
+		}
+		return v, true
+	default:
+		if !isScalar(v) {
+			return nil, false
+		}
+		return []interface{}{v}, true
+	}
+}
+
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case string, int, int64, float64, bool:
+		return true
+	default:
+		return false
+	}
+}
+
+// eventField reads a condition field from an event's columns or its
+// normalized payload
+func eventField(event *models.Event, normalized map[string]interface{}, field string) interface{} {
+	switch field {
+	case "event_type":
+		return event.EventType
+	case "source":
+		return event.Source
+	case "severity":
+		return string(event.Severity)
+	default:
+		return getNestedField(normalized, field)
+	}
+}
+
+// countFieldExpr returns the SQL expression for a field on the events table;
+// payload fields are read from their hot column or with json_extract and a
+// bound path
+func countFieldExpr(field string) (string, []interface{}) {
+	if eventColumns[field] {
+		return field, nil
+	}
+	expr, args, _ := database.FieldExpr(field)
+	return expr, args
+}
+
+// countQuery builds the parameterized query for a count condition. ok is
+// false when the event can't be grouped.
+func countQuery(event *models.Event, normalized map[string]interface{}, cond Condition) (sql string, args []interface{}, ok bool) {
+	scope, scopeArgs, ok := countScope(event, normalized, cond)
+	if !ok {
+		return "", nil, false
+	}
+	windowStart, windowEnd := countWindow(event, cond)
+
+	selectExpr := "COUNT(*)"
+	if cond.Operator == "count_distinct" {
+		expr, exprArgs := countFieldExpr(cond.distinctField())
+		selectExpr = "COUNT(DISTINCT " + expr + ")"
+		args = exprArgs
+	}
+	args = append(append(args, windowStart, windowEnd), scopeArgs...)
+
+	return "SELECT " + selectExpr + " FROM events WHERE timestamp >= ? AND timestamp <= ? AND " + scope, args, true
+}
+
+// countWindow returns the time range a count condition counts events in
+func countWindow(event *models.Event, cond Condition) (start, end time.Time) {
+	end = event.Timestamp.UTC()
+	return end.Add(-time.Duration(cond.TimeWindow) * time.Second), end
+}
+
+// countScope builds the WHERE clause, apart from the time window, selecting
+// the events a count condition counts for the event. ok is false when the
+// event can't be grouped.
+func countScope(event *models.Event, normalized map[string]interface{}, cond Condition) (sql string, args []interface{}, ok bool) {
+	var where []string
+	if len(cond.Filter) == 0 {
+		where = append(where, "event_type = ?")
+		args = append(args, event.EventType)
+	}
+	// Sorted so equivalent conditions produce the same statement
+	filterFields := make([]string, 0, len(cond.Filter))
+	for field := range cond.Filter {
+		filterFields = append(filterFields, field)
+	}
+	sort.Strings(filterFields)
+	for _, field := range filterFields {
+		values, _ := filterValues(cond.Filter[field])
+		expr, exprArgs := countFieldExpr(field)
+		where = append(where, expr+" IN ?")
+		args = append(append(args, exprArgs...), values)
+	}
+
+	for _, field := range cond.groupBy() {
+		value := eventField(event, normalized, field)
+		if !isScalar(value) {
+			return "", nil, false
+		}
+		expr, exprArgs := countFieldExpr(field)
+		where = append(where, expr+" = ?")
+		args = append(append(args, exprArgs...), value)
+	}
+
+	return strings.Join(where, " AND "), args, true
+}
+
+// countedBy reports whether other is an event the condition counts for the
+// event, comparing values as the equals operator does
+func countedBy(event *models.Event, normalized map[string]interface{}, other *models.Event, otherNormalized map[string]interface{}, cond Condition) bool {
+	if len(cond.Filter) == 0 && other.EventType != event.EventType {
+		return false
+	}
+	for field, value := range cond.Filter {
+		values, _ := filterValues(value)
+		actual := fmt.Sprintf("%v", eventField(other, otherNormalized, field))
+		found := false
+		for _, v := range values {
+			if fmt.Sprintf("%v", v) == actual {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, field := range cond.groupBy() {
+		value := eventField(other, otherNormalized, field)
+		if !isScalar(value) || fmt.Sprintf("%v", value) != fmt.Sprintf("%v", eventField(event, normalized, field)) {
+			return false
+		}
+	}
+	return true
+}
+
+// groupable reports whether the event carries every group_by field
+func groupable(event *models.Event, normalized map[string]interface{}, cond Condition) bool {
+	for _, field := range cond.groupBy() {
+		if !isScalar(eventField(event, normalized, field)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/config"
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// countTestNow is when the events under test happen
+var countTestNow = time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+// bruteForce counts failed logins for the same user
+var bruteForce = Condition{
+	Operator:   "count",
+	Threshold:  5,
+	TimeWindow: 300,
+	Filter:     map[string]interface{}{"event_type": "auth_failure"},
+	GroupBy:    []string{"username"},
+}
+
+// spray counts distinct users failing to log in from the same address
+var spray = Condition{
+	Operator:      "count_distinct",
+	Threshold:     5,
+	TimeWindow:    300,
+	Filter:        map[string]interface{}{"event_type": "auth_failure"},
+	GroupBy:       []string{"source_ip"},
+	DistinctField: "username",
+}
+
+func countTestEvent(eventType string, ago time.Duration, normalized map[string]interface{}) *models.Event {
+	data, _ := json.Marshal(normalized)
+	return &models.Event{
+		Timestamp:  countTestNow.Add(-ago),
+		Source:     "sshd",
+		EventType:  eventType,
+		Severity:   models.SeverityLevel("info"),
+		Normalized: string(data),
+	}
+}
+
+func openCountTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(&config.Config{DatabaseURL: filepath.Join(t.TempDir(), "count.db"), DatabaseBusyTimeout: 5000})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close(db) })
+	return db
+}
+
+func TestValidateCountCondition(t *testing.T) {
+	tests := []struct {
+		name    string
+		cond    Condition
+		wantErr bool
+	}{
+		{"brute force", bruteForce, false},
+		{"spray", spray, false},
+		{"legacy field", Condition{Operator: "count", Threshold: 3, TimeWindow: 60, Field: "source_ip"}, false},
+		{"dotted field", Condition{Operator: "count", Threshold: 3, TimeWindow: 60, GroupBy: []string{"user.name"}}, false},
+		{"no threshold", Condition{Operator: "count", TimeWindow: 60, GroupBy: []string{"username"}}, true},
+		{"no window", Condition{Operator: "count", Threshold: 3, GroupBy: []string{"username"}}, true},
+		{"distinct without field", Condition{Operator: "count_distinct", Threshold: 3, TimeWindow: 60}, true},
+		{"injected group_by", Condition{Operator: "count", Threshold: 3, TimeWindow: 60, GroupBy: []string{"username') OR 1=1 --"}}, true},
+		{"injected distinct_field", Condition{Operator: "count_distinct", Threshold: 3, TimeWindow: 60, DistinctField: "username); DROP TABLE events; --"}, true},
+		{"injected filter field", Condition{Operator: "count", Threshold: 3, TimeWindow: 60, Filter: map[string]interface{}{"event_type = event_type OR 1": "x"}}, true},
+		{"path traversal field", Condition{Operator: "count", Threshold: 3, TimeWindow: 60, GroupBy: []string{"$.username"}}, true},
+		{"empty field segment", Condition{Operator: "count", Threshold: 3, TimeWindow: 60, GroupBy: []string{"user..name"}}, true},
+		{"object filter value", Condition{Operator: "count", Threshold: 3, TimeWindow: 60, Filter: map[string]interface{}{"username": map[string]interface{}{"a": 1}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCountCondition(tt.cond)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateCountCondition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCountQuery(t *testing.T) {
+	db := openCountTestDB(t)
+
+	var history []*models.Event
+	// Brute force: alice fails six times from rotating addresses, bob twice
+	for i := 0; i < 6; i++ {
+		history = append(history, countTestEvent("auth_failure", time.Duration(i)*time.Minute/2,
+			map[string]interface{}{"username": "alice", "source_ip": "198.51.100." + string(rune('1'+i))}))
+	}
+	for i := 0; i < 2; i++ {
+		history = append(history, countTestEvent("auth_failure", time.Minute, map[string]interface{}{"username": "bob", "source_ip": "192.0.2.9"}))
+	}
+	// Successes and failures outside the window don't count
+	history = append(history,
+		countTestEvent("auth_success", time.Minute, map[string]interface{}{"username": "alice", "source_ip": "198.51.100.1"}),
+		countTestEvent("auth_failure", 10*time.Minute, map[string]interface{}{"username": "alice", "source_ip": "198.51.100.1"}),
+	)
+	// Spray: one address tries eight users, some more than once
+	for i, user := range []string{"u1", "u2", "u3", "u4", "u5", "u6", "u7", "u8", "u1", "u2"} {
+		history = append(history, countTestEvent("auth_failure", time.Duration(i)*10*time.Second,
+			map[string]interface{}{"username": user, "source_ip": "203.0.113.5"}))
+	}
+	if err := db.Create(&history).Error; err != nil {
+		t.Fatalf("failed to store events: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		cond       Condition
+		normalized map[string]interface{}
+		eventType  string
+		want       int64
+		wantOK     bool
+	}{
+		{"brute force on alice", bruteForce, map[string]interface{}{"username": "alice", "source_ip": "198.51.100.1"}, "auth_failure", 6, true},
+		{"brute force on bob", bruteForce, map[string]interface{}{"username": "bob", "source_ip": "192.0.2.9"}, "auth_failure", 2, true},
+		{"spray from one address", spray, map[string]interface{}{"username": "u1", "source_ip": "203.0.113.5"}, "auth_failure", 8, true},
+		{"spray from another address", spray, map[string]interface{}{"username": "u1", "source_ip": "192.0.2.9"}, "auth_failure", 1, true},
+		{"unfiltered count by event type", Condition{Operator: "count", Threshold: 1, TimeWindow: 300, GroupBy: []string{"username"}},
+			map[string]interface{}{"username": "alice"}, "auth_success", 1, true},
+		{"event without group_by field", bruteForce, map[string]interface{}{"source_ip": "198.51.100.1"}, "auth_failure", 0, false},
+		{"object group_by value", bruteForce, map[string]interface{}{"username": map[string]interface{}{"first": "alice"}}, "auth_failure", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := countTestEvent(tt.eventType, 0, tt.normalized)
+			sql, args, ok := countQuery(event, tt.normalized, tt.cond)
+			if ok != tt.wantOK {
+				t.Fatalf("countQuery() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			var got int64
+			if err := db.Raw(sql, args...).Scan(&got).Error; err != nil {
+				t.Fatalf("count query failed: %v\n%s", err, sql)
+			}
+			if got != tt.want {
+				t.Errorf("count = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountQueryBindsFieldNames(t *testing.T) {
+	cond := Condition{Operator: "count_distinct", Threshold: 2, TimeWindow: 60, GroupBy: []string{"user.name"}, DistinctField: "host"}
+	normalized := map[string]interface{}{"user": map[string]interface{}{"name": "alice"}, "host": "web-1"}
+	sql, args, ok := countQuery(countTestEvent("auth_failure", 0, normalized), normalized, cond)
+	if !ok {
+		t.Fatal("countQuery() ok = false")
+	}
+	want := "SELECT COUNT(DISTINCT json_extract(normalized, ?)) FROM events WHERE timestamp >= ? AND timestamp <= ? AND event_type = ? AND json_extract(normalized, ?) = ?"
+	if sql != want {
+		t.Errorf("sql = %q, want %q", sql, want)
+	}
+	if len(args) != 6 || args[0] != "$.host" || args[4] != "$.user.name" || args[5] != "alice" {
+		t.Errorf("args = %v", args)
+	}
+}
+
+func TestCountedBy(t *testing.T) {
+	failure := func(user, ip string) (*models.Event, map[string]interface{}) {
+		normalized := map[string]interface{}{"username": user, "source_ip": ip}
+		return countTestEvent("auth_failure", 0, normalized), normalized
+	}
+	success := func(user, ip string) (*models.Event, map[string]interface{}) {
+		normalized := map[string]interface{}{"username": user, "source_ip": ip}
+		return countTestEvent("auth_success", 0, normalized), normalized
+	}
+
+	tests := []struct {
+		name  string
+		cond  Condition
+		event func() (*models.Event, map[string]interface{})
+		other func() (*models.Event, map[string]interface{})
+		want  bool
+	}{
+		{"brute force: same user, other address", bruteForce,
+			func() (*models.Event, map[string]interface{}) { return failure("alice", "198.51.100.1") },
+			func() (*models.Event, map[string]interface{}) { return failure("alice", "198.51.100.2") }, true},
+		{"brute force: other user", bruteForce,
+			func() (*models.Event, map[string]interface{}) { return failure("alice", "198.51.100.1") },
+			func() (*models.Event, map[string]interface{}) { return failure("bob", "198.51.100.1") }, false},
+		{"brute force: success isn't counted", bruteForce,
+			func() (*models.Event, map[string]interface{}) { return failure("alice", "198.51.100.1") },
+			func() (*models.Event, map[string]interface{}) { return success("alice", "198.51.100.1") }, false},
+		{"spray: same address, other user", spray,
+			func() (*models.Event, map[string]interface{}) { return failure("u1", "203.0.113.5") },
+			func() (*models.Event, map[string]interface{}) { return failure("u2", "203.0.113.5") }, true},
+		{"spray: other address", spray,
+			func() (*models.Event, map[string]interface{}) { return failure("u1", "203.0.113.5") },
+			func() (*models.Event, map[string]interface{}) { return failure("u2", "203.0.113.6") }, false},
+		{"other lacks group_by field", bruteForce,
+			func() (*models.Event, map[string]interface{}) { return failure("alice", "198.51.100.1") },
+			func() (*models.Event, map[string]interface{}) {
+				return countTestEvent("auth_failure", 0, map[string]interface{}{"source_ip": "198.51.100.1"}), map[string]interface{}{"source_ip": "198.51.100.1"}
+			}, false},
+		{"no filter: same event type only", Condition{Operator: "count", Threshold: 2, TimeWindow: 60, GroupBy: []string{"username"}},
+			func() (*models.Event, map[string]interface{}) { return failure("alice", "198.51.100.1") },
+			func() (*models.Event, map[string]interface{}) { return success("alice", "198.51.100.1") }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, normalized := tt.event()
+			other, otherNormalized := tt.other()
+			if got := countedBy(event, normalized, other, otherNormalized, tt.cond); got != tt.want {
+				t.Errorf("countedBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -8,12 +9,15 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"gopkg.in/yaml.v3"
 	"gorm.io/gorm"
 
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
 	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/store"
 )
 
 // Rule represents a detection rule loaded from YAML
@@ -24,7 +28,20 @@ type Rule struct {
 		Description string   `yaml:"description"`
 		Category    string   `yaml:"category"`
 		Severity    string   `yaml:"severity"`
+		Impact      int      `yaml:"impact"`  // with urgency, derives severity from the severity matrix
+		Urgency     int      `yaml:"urgency"` // 1 up, see SEVERITY_MATRIX
 		Enabled     bool     `yaml:"enabled"`
+		Runbook     string   `yaml:"runbook"` // runbook slug linked to created incidents
+		Team        string   `yaml:"team"`    // owning team, used by notification routes
+		Tags        []string `yaml:"tags"`
+		// AutoResolve overrides ALERT_AUTO_RESOLVE for incidents from this rule
+		AutoResolve *bool `yaml:"auto_resolve"`
+		// CorrelationKey names the normalized field that groups matches into one
+		// open incident; defaults to the field of the rule's count condition
+		CorrelationKey string `yaml:"correlation_key"`
+		// RiskScore is the risk a match adds to the entities in the event;
+		// defaults to points for the rule's severity, 0 adds none
+		RiskScore *float64 `yaml:"risk_score"`
 		Conditions  []Condition `yaml:"conditions"`
 		Actions     []RuleAction `yaml:"actions"`
 	} `yaml:"rule"`
@@ -40,6 +57,32 @@ type Condition struct {
 	Threshold  int         `yaml:"threshold"`
 	TimeWindow int         `yaml:"timewindow"`
 	CountField string      `yaml:"count_field"`
+	Watchlist  string      `yaml:"watchlist"` // for in_watchlist
+
+	// Count conditions (see count_conditions.go)
+	GroupBy       []string               `yaml:"group_by"`
+	Filter        map[string]interface{} `yaml:"filter"`
+	DistinctField string                 `yaml:"distinct_field"`
+
+	// Seen-value conditions (see seen_values.go)
+	LearningPeriod int `yaml:"learning_period"`
+
+	// Time conditions (see time_conditions.go): the timezone the event time
+	// is read in, and time_window's days and HH:MM bounds
+	Timezone string   `yaml:"timezone"`
+	Days     []string `yaml:"days"`
+	Start    string   `yaml:"start"`
+	End      string   `yaml:"end"`
+
+	// seenScope names the values a seen-value condition tracks
+	seenScope string
+
+	// location and window are the compiled time condition settings
+	location *time.Location
+	window   *hoursWindow
+
+	// compiled is the Pattern compiled once at load time
+	compiled *regexp.Regexp
 }
 
 // RuleAction represents an action to take when a rule matches
@@ -55,16 +98,189 @@ type RuleAction struct {
 
 // DetectionEngine handles rule evaluation and detection
 type DetectionEngine struct {
-	db    *gorm.DB
-	rules []Rule
+	db     *gorm.DB
+	events store.EventStore
+	locks  *LockManager
+	outbox *Outbox
+
+	mu         sync.RWMutex
+	rules      []Rule
+	index      *ruleIndex
+	watchlists map[string]map[string]bool
+
+	perf       *PerfRecorder
+	campaigns  *CampaignManager
+	travel     *TravelDetector
+	risk       *RiskScorer
+	catalog    *ServiceCatalog
+	hours      *BusinessHours
+	enricher   *EnrichmentPipeline
+	severities *SeverityMatrix
+
+	// tracing stores how each event was evaluated (see detection_trace.go)
+	tracing bool
+
+	// tenants hold the events, and their traces, of tenants with a
+	// database of their own
+	tenants *database.Tenants
+
+	// Prioritized, micro-batched evaluation (see detection_queue.go and
+	// detection_batch.go)
+	queue         *detectionQueue
+	workers       int
+	batchSize     int
+	batchInterval time.Duration
+}
+
+// Watchlist is a named list of values (IPs, domains, users, ...) that rule
+// conditions can test fields against, loaded from YAML
+type Watchlist struct {
+	Watchlist struct {
+		ID          string   `yaml:"id"`
+		Name        string   `yaml:"name"`
+		Description string   `yaml:"description"`
+		Entries     []string `yaml:"entries"`
+	} `yaml:"watchlist"`
 }
 
-// NewDetectionEngine creates a new detection engine
-func NewDetectionEngine(db *gorm.DB) *DetectionEngine {
+// NewDetectionEngine creates a new detection engine. Events are marked
+// processed in events; db serves the rest of detection's queries.
+func NewDetectionEngine(db *gorm.DB, events store.EventStore, locks *LockManager, outbox *Outbox) *DetectionEngine {
 	return &DetectionEngine{
-		db:    db,
-		rules: []Rule{},
+		db:         db,
+		events:     events,
+		locks:      locks,
+		outbox:     outbox,
+		rules:      []Rule{},
+		index:      buildRuleIndex(nil),
+		watchlists: make(map[string]map[string]bool),
+		perf:       NewPerfRecorder(),
+		hours:      defaultBusinessHours(),
+	}
+}
+
+// SetTenants counts and marks the events of tenants with a database of their
+// own in that database
+func (de *DetectionEngine) SetTenants(tenants *database.Tenants) {
+	de.tenants = tenants
+}
+
+// eventDB returns the database holding an event
+func (de *DetectionEngine) eventDB(event *models.Event) *gorm.DB {
+	if db := de.tenants.Lookup(event.Tenant); db != nil {
+		return db
+	}
+	return de.db
+}
+
+// eventsFor returns the store holding an event
+func (de *DetectionEngine) eventsFor(event *models.Event) store.EventStore {
+	if db := de.tenants.Lookup(event.Tenant); db != nil {
+		return store.NewGormEvents(db, nil)
+	}
+	return de.events
+}
+
+// SetCampaignManager groups newly created incidents into campaigns
+func (de *DetectionEngine) SetCampaignManager(campaigns *CampaignManager) {
+	de.campaigns = campaigns
+}
+
+// SetEnrichmentPipeline enriches events before they are matched; the
+// enriched fields are stored with the event
+func (de *DetectionEngine) SetEnrichmentPipeline(pipeline *EnrichmentPipeline) {
+	de.enricher = pipeline
+}
+
+// SetTravelDetector checks login events for impossible travel
+func (de *DetectionEngine) SetTravelDetector(travel *TravelDetector) {
+	de.travel = travel
+}
+
+// SetRiskScorer adds the risk of rule matches to the entities involved
+func (de *DetectionEngine) SetRiskScorer(risk *RiskScorer) {
+	de.risk = risk
+}
+
+// SetSeverityMatrix derives the severity of rules that set impact and
+// urgency; call it before loading rules
+func (de *DetectionEngine) SetSeverityMatrix(matrix *SeverityMatrix) {
+	de.severities = matrix
+}
+
+// deriveRuleSeverity sets the severity of a rule with impact and urgency
+// from the severity matrix
+func (de *DetectionEngine) deriveRuleSeverity(rule *Rule) error {
+	severity, err := deriveSeverity(de.severities, rule.Rule.Impact, rule.Rule.Urgency, models.SeverityLevel(rule.Rule.Severity))
+	if err != nil {
+		return err
 	}
+	rule.Rule.Severity = string(severity)
+	return nil
+}
+
+// SetServiceCatalog applies the impact of the services events name to the
+// incidents they raise
+func (de *DetectionEngine) SetServiceCatalog(catalog *ServiceCatalog) {
+	de.catalog = catalog
+}
+
+// LoadWatchlists loads all YAML watchlists from the specified directory
+func (de *DetectionEngine) LoadWatchlists(watchlistsDir string) error {
+	files, err := filepath.Glob(filepath.Join(watchlistsDir, "*.yaml"))
+	if err != nil {
+		return fmt.Errorf("failed to glob watchlists: %w", err)
+	}
+
+	files2, err := filepath.Glob(filepath.Join(watchlistsDir, "*.yml"))
+	if err != nil {
+		return fmt.Errorf("failed to glob watchlists: %w", err)
+	}
+	files = append(files, files2...)
+
+	watchlists := make(map[string]map[string]bool)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Printf("Warning: failed to read watchlist file %s: %v", file, err)
+			continue
+		}
+
+		var watchlist Watchlist
+		if err := yaml.Unmarshal(data, &watchlist); err != nil {
+			log.Printf("Warning: failed to parse watchlist file %s: %v", file, err)
+			continue
+		}
+
+		entries := make(map[string]bool, len(watchlist.Watchlist.Entries))
+		for _, entry := range watchlist.Watchlist.Entries {
+			entries[strings.ToLower(strings.TrimSpace(entry))] = true
+		}
+		watchlists[watchlist.Watchlist.ID] = entries
+		log.Printf("Loaded watchlist: %s (%d entries)", watchlist.Watchlist.ID, len(entries))
+	}
+
+	de.mu.Lock()
+	de.watchlists = watchlists
+	de.mu.Unlock()
+
+	log.Printf("Loaded %d watchlists", len(watchlists))
+	return nil
+}
+
+// inWatchlist reports whether a value is on a watchlist, ignoring case
+func (de *DetectionEngine) inWatchlist(name string, value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	de.mu.RLock()
+	entries, ok := de.watchlists[name]
+	de.mu.RUnlock()
+	if !ok {
+		log.Printf("Unknown watchlist: %s", name)
+		return false
+	}
+	return entries[strings.ToLower(fmt.Sprintf("%v", value))]
 }
 
 // LoadRules loads all YAML rules from the specified directory
@@ -80,7 +296,7 @@ func (de *DetectionEngine) LoadRules(rulesDir string) error {
 	}
 	files = append(files, files2...)
 
-	de.rules = []Rule{}
+	rules := []Rule{}
 	for _, file := range files {
 		data, err := os.ReadFile(file)
 		if err != nil {
@@ -95,46 +311,270 @@ func (de *DetectionEngine) LoadRules(rulesDir string) error {
 		}
 
 		if rule.Rule.Enabled {
-			de.rules = append(de.rules, rule)
-			log.Printf("Loaded rule: %s (%s)", rule.Rule.ID, rule.Rule.Name)
+			rules = append(rules, rule)
 		}
 	}
 
-	log.Printf("Loaded %d enabled rules", len(de.rules))
+	loaded := de.SetRules(rules)
+	log.Printf("Loaded %d enabled rules", loaded)
 	return nil
 }
 
+// RuleCount returns the number of active rules
+func (de *DetectionEngine) RuleCount() int {
+	de.mu.RLock()
+	defer de.mu.RUnlock()
+	return len(de.rules)
+}
+
+// SetRules compiles and indexes the given rules and swaps them in as the
+// active rule set. Rules that fail to compile are skipped. Returns the number
+// of rules installed.
+func (de *DetectionEngine) SetRules(rules []Rule) int {
+	compiled := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		if err := de.deriveRuleSeverity(&rule); err != nil {
+			log.Printf("Warning: skipping rule %s: %v", rule.Rule.ID, err)
+			continue
+		}
+		if err := compileRule(&rule); err != nil {
+			log.Printf("Warning: skipping rule %s: %v", rule.Rule.ID, err)
+			continue
+		}
+		compiled = append(compiled, rule)
+		log.Printf("Loaded rule: %s (%s)", rule.Rule.ID, rule.Rule.Name)
+	}
+
+	index := buildRuleIndex(compiled)
+
+	de.mu.Lock()
+	de.rules = compiled
+	de.index = index
+	de.mu.Unlock()
+
+	return len(compiled)
+}
+
+// compileRule precompiles regex and time conditions so evaluation never
+// recompiles them, and checks count and seen-value conditions
+func compileRule(rule *Rule) error {
+	conditions := make([]Condition, len(rule.Rule.Conditions))
+	copy(conditions, rule.Rule.Conditions)
+
+	for i := range conditions {
+		if isCountOperator(conditions[i].Operator) {
+			if err := validateCountCondition(conditions[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		if isSeenOperator(conditions[i].Operator) {
+			if err := validateSeenCondition(conditions[i]); err != nil {
+				return err
+			}
+			conditions[i].seenScope = seenScope(rule.Rule.ID, conditions[i])
+			continue
+		}
+		if isTimeOperator(conditions[i].Operator) {
+			if err := compileTimeCondition(&conditions[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		if conditions[i].Operator != "regex" {
+			continue
+		}
+		re, err := regexp.Compile(conditions[i].Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid regex %q on field %s: %w", conditions[i].Pattern, conditions[i].Field, err)
+		}
+		conditions[i].compiled = re
+	}
+
+	rule.Rule.Conditions = conditions
+	return nil
+}
+
+// countEvaluator decides the conditions that depend on other events:
+// time-windowed counts and seen values. Live detection queries the events and
+// seen_values tables; simulations use the replayed events. Besides the
+// outcome it reports the count compared: matching events or distinct values
+// in the window, or how often a seen value has been seen; -1 when there is
+// none, as for an event lacking the condition's fields.
+type countEvaluator func(event *models.Event, normalized map[string]interface{}, cond Condition) (bool, int64)
+
+// MatchingRules returns the rules whose conditions an event satisfies,
+// evaluating only rules indexed as plausible for the event
+func (de *DetectionEngine) MatchingRules(event *models.Event, normalized map[string]interface{}) []Rule {
+	return de.matchingRules(event, normalized, de.evaluateCountCondition, nil, nil)
+}
+
+// Perf returns the recorder timing live detection on this instance
+func (de *DetectionEngine) Perf() *PerfRecorder {
+	return de.perf
+}
+
+// matchingRules evaluates the candidate rules for an event, timing each rule
+// and its expensive conditions when perf is non-nil and recording their
+// outcomes when trace is non-nil
+func (de *DetectionEngine) matchingRules(event *models.Event, normalized map[string]interface{}, count countEvaluator, perf *PerfRecorder, trace *EventTrace) []Rule {
+	de.mu.RLock()
+	rules, index := de.rules, de.index
+	de.mu.RUnlock()
+
+	var matched []Rule
+	candidates := index.candidates(event.EventType, event.Source)
+	for _, i := range candidates {
+		var ruleTrace *RuleTrace
+		if trace != nil {
+			ruleTrace = &RuleTrace{RuleID: rules[i].Rule.ID, Name: rules[i].Rule.Name, Conditions: []ConditionTrace{}}
+			trace.Rules = append(trace.Rules, ruleTrace)
+		}
+		start := time.Now()
+		ok := de.matchesRule(event, normalized, rules[i], count, perf, ruleTrace)
+		perf.recordRule(rules[i].Rule.ID, time.Since(start), ok)
+		if ok {
+			matched = append(matched, rules[i])
+		}
+		if ruleTrace != nil {
+			ruleTrace.Matched = ok
+		}
+	}
+
+	if trace != nil && len(candidates) < len(rules) {
+		evaluated := make(map[int]bool, len(candidates))
+		for _, i := range candidates {
+			evaluated[i] = true
+		}
+		for i, rule := range rules {
+			if !evaluated[i] {
+				trace.NotEvaluated = append(trace.NotEvaluated, rule.Rule.ID)
+			}
+		}
+	}
+	return matched
+}
+
 // EvaluateEvent evaluates an event against all loaded rules
 func (de *DetectionEngine) EvaluateEvent(event *models.Event) error {
-	log.Printf("Evaluating event %s against %d rules", event.EventID, len(de.rules))
+	normalized, err := de.prepareEvent(event)
+	if err != nil {
+		de.traceFailure(event, err)
+		return err
+	}
+	de.processEvent(event, normalized, de.evaluateCountCondition, 0)
+	return nil
+}
+
+// prepareEvent parses and enriches an event ahead of matching
+func (de *DetectionEngine) prepareEvent(event *models.Event) (map[string]interface{}, error) {
+	log.Printf("Evaluating event %s%s", event.EventID, requestTag(event.RequestID))
+	start := time.Now()
+	if !event.CreatedAt.IsZero() {
+		de.perf.recordStage(StageQueued, start.Sub(event.CreatedAt))
+	}
 
 	// Parse normalized data
 	var normalized map[string]any
 	if err := json.Unmarshal([]byte(event.Normalized), &normalized); err != nil {
-		return fmt.Errorf("failed to parse normalized data: %w", err)
+		return nil, fmt.Errorf("failed to parse normalized data: %w", err)
 	}
+	if de.enricher.Apply(event, normalized) {
+		if enriched, err := json.Marshal(normalized); err == nil {
+			event.Normalized = string(enriched)
+		}
+	}
+	if de.enricher != nil {
+		de.perf.recordStage(StageEnrichment, time.Since(start))
+	}
+	de.travel.Observe(event, normalized)
+	return normalized, nil
+}
 
-	for _, rule := range de.rules {
-		if de.matchesRule(event, normalized, rule) {
-			log.Printf("Event %s matched rule %s", event.EventID, rule.Rule.ID)
-			if err := de.executeRuleActions(event, rule); err != nil {
-				log.Printf("Error executing rule actions: %v", err)
+// processEvent matches a prepared event, runs the actions of the rules it
+// matched and marks it processed. shared is its part of matching work done
+// for a whole batch, added to its matching time.
+func (de *DetectionEngine) processEvent(event *models.Event, normalized map[string]interface{}, count countEvaluator, shared time.Duration) {
+	start := time.Now()
+	trace := de.newTrace(event)
+	matched := de.matchingRules(event, normalized, count, de.perf, trace)
+	matchedAt := time.Now()
+	de.perf.recordStage(StageMatching, matchedAt.Sub(start)+shared)
+
+	for _, rule := range matched {
+		log.Printf("Event %s matched rule %s%s", event.EventID, rule.Rule.ID, requestTag(event.RequestID))
+		ruleTrace := trace.rule(rule.Rule.ID)
+		suppression, err := FindSuppression(de.db, event, rule.Rule.Tags)
+		if err != nil {
+			log.Printf("Error checking suppressions: %v", err)
+		} else if suppression != nil {
+			log.Printf("Rule %s match for event %s suppressed by %s (%s)", rule.Rule.ID, event.EventID, suppression.Name, suppression.SuppressionID)
+			if ruleTrace != nil {
+				ruleTrace.SuppressedBy = suppression.SuppressionID
+			}
+			if err := RecordSuppressed(de.db, suppression.SuppressionID); err != nil {
+				log.Printf("Error recording suppression: %v", err)
+			}
+			continue
+		}
+		de.risk.Record(event, normalized, rule)
+		if err := de.executeRuleActions(event, normalized, rule, ruleTrace); err != nil {
+			log.Printf("Error executing rule actions: %v", err)
+			if ruleTrace != nil {
+				ruleTrace.Error = err.Error()
 			}
 		}
 	}
 
 	// Mark event as processed
 	now := time.Now()
+	de.perf.recordStage(StageActions, now.Sub(matchedAt))
+	if !event.CreatedAt.IsZero() {
+		de.perf.recordStage(StageTotal, now.Sub(event.CreatedAt))
+	}
 	event.ProcessedAt = &now
-	de.db.Save(event)
+	var record *models.DetectionTrace
+	if de.tracing {
+		record = trace.record()
+	}
+	if err := de.eventsFor(event).MarkProcessed(context.Background(), event, record); err != nil {
+		log.Printf("Error marking event %s processed: %v", event.EventID, err)
+	}
+}
 
-	return nil
+// traceFailure records the trace of an event detection couldn't evaluate
+func (de *DetectionEngine) traceFailure(event *models.Event, cause error) {
+	if !de.tracing {
+		return
+	}
+	trace := de.newTrace(event)
+	trace.Error = cause.Error()
+	if err := de.eventDB(event).Create(trace.record()).Error; err != nil {
+		log.Printf("Error recording detection trace for event %s: %v", event.EventID, err)
+	}
 }
 
-// matchesRule checks if an event matches a rule's conditions
-func (de *DetectionEngine) matchesRule(event *models.Event, normalized map[string]interface{}, rule Rule) bool {
-	for _, condition := range rule.Rule.Conditions {
-		if !de.evaluateCondition(event, normalized, condition) {
+// matchesRule checks if an event matches a rule's conditions, recording
+// each condition's outcome in trace when it is non-nil
+func (de *DetectionEngine) matchesRule(event *models.Event, normalized map[string]interface{}, rule Rule, count countEvaluator, perf *PerfRecorder, trace *RuleTrace) bool {
+	for i, condition := range rule.Rule.Conditions {
+		timed := perf != nil && timedOperators[condition.Operator]
+		var start time.Time
+		if timed {
+			start = time.Now()
+		}
+		var ok bool
+		if trace != nil {
+			outcome := de.traceCondition(event, normalized, i, condition, count)
+			trace.Conditions = append(trace.Conditions, outcome)
+			ok = outcome.Passed
+		} else {
+			ok = de.evaluateCondition(event, normalized, condition, count)
+		}
+		if timed {
+			perf.recordCondition(rule.Rule.ID, i, condition, time.Since(start))
+		}
+		if !ok {
 			return false
 		}
 	}
@@ -142,20 +582,8 @@ func (de *DetectionEngine) matchesRule(event *models.Event, normalized map[strin
 }
 
 // evaluateCondition evaluates a single condition
-func (de *DetectionEngine) evaluateCondition(event *models.Event, normalized map[string]interface{}, cond Condition) bool {
-	// Get the field value
-	var fieldValue interface{}
-	switch cond.Field {
-	case "event_type":
-		fieldValue = event.EventType
-	case "source":
-		fieldValue = event.Source
-	case "severity":
-		fieldValue = string(event.Severity)
-	default:
-		// Try to get from normalized data
-		fieldValue = getNestedField(normalized, cond.Field)
-	}
+func (de *DetectionEngine) evaluateCondition(event *models.Event, normalized map[string]interface{}, cond Condition, count countEvaluator) bool {
+	fieldValue := eventField(event, normalized, cond.Field)
 
 	switch cond.Operator {
 	case "equals":
@@ -171,25 +599,29 @@ func (de *DetectionEngine) evaluateCondition(event *models.Event, normalized map
 		return false
 
 	case "greater_than":
-		// Simple numeric comparison
-		if num, ok := fieldValue.(float64); ok {
-			if threshold, ok := cond.Value.(float64); ok {
-				return num > threshold
-			}
+		// Simple numeric comparison; YAML integers decode as int
+		num, ok := toFloat(fieldValue)
+		if !ok {
+			return false
 		}
-		return false
+		threshold, ok := toFloat(cond.Value)
+		return ok && num > threshold
 
 	case "regex":
-		strValue := fmt.Sprintf("%v", fieldValue)
-		matched, err := regexp.MatchString(cond.Pattern, strValue)
-		if err != nil {
-			log.Printf("Regex error: %v", err)
+		if cond.compiled == nil {
 			return false
 		}
-		return matched
+		return cond.compiled.MatchString(fmt.Sprintf("%v", fieldValue))
 
-	case "count", "count_distinct":
-		return de.evaluateCountCondition(event, cond)
+	case "in_watchlist":
+		return de.inWatchlist(cond.Watchlist, fieldValue)
+
+	case "count", "count_distinct", "first_seen", "rare":
+		ok, _ := count(event, normalized, cond)
+		return ok
+
+	case "business_hours", "outside_business_hours", "weekend", "time_window":
+		return de.evaluateTimeCondition(event, cond)
 
 	default:
 		log.Printf("Unknown operator: %s", cond.Operator)
@@ -197,51 +629,150 @@ func (de *DetectionEngine) evaluateCondition(event *models.Event, normalized map
 	}
 }
 
-// evaluateCountCondition evaluates time-windowed count conditions
-func (de *DetectionEngine) evaluateCountCondition(event *models.Event, cond Condition) bool {
-	// Calculate time window
-	windowStart := time.Now().Add(-time.Duration(cond.TimeWindow) * time.Second)
+// evaluateCountCondition evaluates time-windowed count conditions against
+// stored events, and seen-value conditions against the seen_values table
+func (de *DetectionEngine) evaluateCountCondition(event *models.Event, normalized map[string]interface{}, cond Condition) (bool, int64) {
+	if isSeenOperator(cond.Operator) {
+		return de.evaluateSeenCondition(event, normalized, cond)
+	}
+	sql, args, ok := countQuery(event, normalized, cond)
+	if !ok {
+		return false, -1
+	}
 
-	// Query events in the time window
 	var count int64
-	query := de.db.Model(&models.Event{}).
-		Where("timestamp >= ?", windowStart).
-		Where(cond.Field+" = ?", event.EventType)
-
-	if cond.Operator == "count_distinct" && cond.CountField != "" {
-		query = query.Distinct(cond.CountField)
+	if err := de.eventDB(event).Raw(sql, args...).Scan(&count).Error; err != nil {
+		log.Printf("Error evaluating %s condition: %v", cond.Operator, err)
+		return false, -1
 	}
 
-	query.Count(&count)
-
-	return int(count) >= cond.Threshold
+	return int(count) >= cond.Threshold, count
 }
 
-// executeRuleActions executes the actions specified by a rule
-func (de *DetectionEngine) executeRuleActions(event *models.Event, rule Rule) error {
+// executeRuleActions applies a rule's actions in one transaction: the
+// incident is created (or the event attached to an open one) and any
+// notifications and playbook runs are queued in the outbox alongside it.
+// What was done is recorded in trace when it is non-nil.
+func (de *DetectionEngine) executeRuleActions(event *models.Event, normalized map[string]interface{}, rule Rule, trace *RuleTrace) error {
+	createsIncident := false
 	for _, action := range rule.Rule.Actions {
-		switch action.Type {
-		case "create_incident":
-			if err := de.createIncident(event, rule, action); err != nil {
-				log.Printf("Failed to create incident: %v", err)
-			}
+		if action.Type == "create_incident" {
+			createsIncident = true
+		}
+	}
 
-		case "execute_playbook":
-			log.Printf("Triggering playbook: %s for event %s", action.Playbook, event.EventID)
-			// Playbook execution will be handled by orchestrator
+	correlationKey := de.correlationKey(normalized, rule)
+	if createsIncident && correlationKey != "" {
+		lockName := "incident:" + correlationKey
+		token, err := de.locks.Lock(lockName, 30*time.Second, 10*time.Second)
+		if err != nil {
+			return fmt.Errorf("failed to lock %s: %w", lockName, err)
+		}
+		defer de.locks.Unlock(lockName, token)
+	}
 
-		case "notify":
-			de.sendNotification(event, rule, action)
+	return de.db.Transaction(func(tx *gorm.DB) error {
+		var incident *models.Incident
+		if createsIncident {
+			var created bool
+			var err error
+			incident, created, err = de.createIncident(tx, event, rule, correlationKey, isExercise(normalized))
+			if err != nil {
+				return err
+			}
+			if err := linkIncidentAlert(tx, incident, normalized); err != nil {
+				return err
+			}
+			if err := de.catalog.ApplyImpact(tx, incident, EventServices(normalized)); err != nil {
+				return err
+			}
+			if trace != nil {
+				trace.IncidentID, trace.IncidentCreated = incident.IncidentID, created
+			}
+			if !created {
+				// Responses already ran for the open incident
+				return nil
+			}
+			if err := de.explainMatch(tx, incident, event, normalized, rule, trace); err != nil {
+				log.Printf("Error recording detection for incident %s: %v", incident.IncidentID, err)
+			}
+			if err := de.campaigns.Correlate(tx, incident, normalized); err != nil {
+				return err
+			}
+		}
 
-		default:
-			log.Printf("Unknown action type: %s", action.Type)
+		for _, action := range rule.Rule.Actions {
+			switch action.Type {
+			case "create_incident":
+				// Handled above
+
+			case "execute_playbook":
+				inputs := make(map[string]interface{}, len(normalized)+1)
+				for k, v := range normalized {
+					inputs[k] = v
+				}
+				if incident != nil {
+					inputs["incident_id"] = incident.IncidentID
+				}
+				log.Printf("Queueing playbook: %s for event %s%s", action.Playbook, event.EventID, requestTag(event.RequestID))
+				// Quotas count against the event's tenant, or its source
+				// outside any tenant
+				tenant := event.Tenant
+				if tenant == "" {
+					tenant = event.Source
+				}
+				payload := map[string]interface{}{
+					"playbook_id": action.Playbook,
+					"inputs":      inputs,
+					"trigger":     PlaybookTriggerRule,
+					"event_id":    event.EventID,
+					"rule_id":     rule.Rule.ID,
+					"tenant":      tenant,
+					"exercise":    isExercise(normalized),
+				}
+				if event.RequestID != nil {
+					payload[RequestIDField] = *event.RequestID
+				}
+				if err := de.outbox.Enqueue(tx, TopicExecutePlaybook, payload); err != nil {
+					return err
+				}
+				trace.addAction("execute_playbook:" + action.Playbook)
+
+			case "notify":
+				params := de.notificationParams(event, rule, action, incident)
+				if isExercise(normalized) {
+					params["message"] = "[EXERCISE] " + params["message"].(string)
+				}
+				if err := de.outbox.Enqueue(tx, TopicNotify, params); err != nil {
+					return err
+				}
+				trace.addAction(fmt.Sprintf("notify:%v", params["channel"]))
+
+			default:
+				log.Printf("Unknown action type: %s", action.Type)
+			}
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
-// createIncident creates an incident from a rule match
-func (de *DetectionEngine) createIncident(event *models.Event, rule Rule, action RuleAction) error {
+// createIncident creates an incident from a rule match, or attaches the event
+// to an existing open incident with the same correlation key. The caller must
+// hold the correlation key's lock. Reports whether a new incident was created.
+func (de *DetectionEngine) createIncident(tx *gorm.DB, event *models.Event, rule Rule, correlationKey string, exercise bool) (*models.Incident, bool, error) {
+	if correlationKey != "" {
+		var existing models.Incident
+		err := tx.Where("correlation_key = ? AND status <> ?", correlationKey, models.StatusResolved).
+			Order("created_at DESC").
+			First(&existing).Error
+		if err == nil {
+			return &existing, false, de.attachEvent(tx, &existing, event)
+		}
+		if err != gorm.ErrRecordNotFound {
+			return nil, false, fmt.Errorf("failed to look up open incident: %w", err)
+		}
+	}
+
 	severity := models.SeverityMedium
 	switch strings.ToLower(rule.Rule.Severity) {
 	case "critical":
@@ -257,35 +788,161 @@ func (de *DetectionEngine) createIncident(event *models.Event, rule Rule, action
 	incident := &models.Incident{
 		Status:          models.StatusOpen,
 		Severity:        severity,
+		Impact:          rule.Rule.Impact,
+		Urgency:         rule.Rule.Urgency,
 		Category:        rule.Rule.Category,
 		Title:           rule.Rule.Name,
 		Description:     fmt.Sprintf("%s\nTriggered by event: %s", rule.Rule.Description, event.EventID),
 		TriggeredByRule: rule.Rule.ID,
 		RelatedEvents:   fmt.Sprintf("[\"%s\"]", event.EventID),
+		CorrelationKey:  correlationKey,
+		Exercise:        exercise,
+		Team:            rule.Rule.Team,
+		RequestID:       event.RequestID,
+	}
+	if len(rule.Rule.Tags) > 0 {
+		tagsJSON, _ := json.Marshal(rule.Rule.Tags)
+		incident.Tags = string(tagsJSON)
+	}
+	if exercise {
+		incident.Title = "[EXERCISE] " + incident.Title
 	}
 
-	if err := de.db.Create(incident).Error; err != nil {
-		return fmt.Errorf("failed to create incident: %w", err)
+	if rule.Rule.Runbook != "" {
+		var runbook models.Runbook
+		if err := tx.First(&runbook, "slug = ?", rule.Rule.Runbook).Error; err != nil {
+			log.Printf("Warning: runbook %s for rule %s not found: %v", rule.Rule.Runbook, rule.Rule.ID, err)
+		} else {
+			incident.RunbookID = &runbook.RunbookID
+		}
+	}
+
+	if err := tx.Create(incident).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to create incident: %w", err)
+	}
+
+	log.Printf("Created incident %s for rule %s%s", incident.IncidentID, rule.Rule.ID, requestTag(event.RequestID))
+	return incident, true, nil
+}
+
+// correlationKey builds the dedup key for a rule match, or "" if the rule has
+// no grouping field or the event doesn't carry it
+func (de *DetectionEngine) correlationKey(normalized map[string]interface{}, rule Rule) string {
+	var fields []string
+	if rule.Rule.CorrelationKey != "" {
+		fields = []string{rule.Rule.CorrelationKey}
+	} else {
+		for _, cond := range rule.Rule.Conditions {
+			if isCountOperator(cond.Operator) {
+				fields = cond.groupBy()
+				break
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		value := getNestedField(normalized, field)
+		if value == nil {
+			return ""
+		}
+		parts[i] = fmt.Sprintf("%s=%v", field, value)
 	}
 
-	log.Printf("Created incident %s for rule %s", incident.IncidentID, rule.Rule.ID)
+	key := rule.Rule.ID + ":" + strings.Join(parts, ",")
+	if isExercise(normalized) {
+		// Exercises never absorb real events, or vice versa
+		key = "exercise:" + key
+	}
+	return key
+}
+
+// RuleAutoResolve returns a rule's auto_resolve setting, or nil when the
+// rule doesn't set one (or isn't loaded)
+func (de *DetectionEngine) RuleAutoResolve(ruleID string) *bool {
+	de.mu.RLock()
+	defer de.mu.RUnlock()
+
+	for _, rule := range de.rules {
+		if rule.Rule.ID == ruleID {
+			return rule.Rule.AutoResolve
+		}
+	}
+	return nil
+}
+
+// attachEvent appends an event to an existing incident's related events
+func (de *DetectionEngine) attachEvent(tx *gorm.DB, incident *models.Incident, event *models.Event) error {
+	var related []string
+	if incident.RelatedEvents != "" {
+		if err := json.Unmarshal([]byte(incident.RelatedEvents), &related); err != nil {
+			return fmt.Errorf("failed to parse related events: %w", err)
+		}
+	}
+	related = append(related, event.EventID)
+
+	relatedJSON, err := json.Marshal(related)
+	if err != nil {
+		return fmt.Errorf("failed to marshal related events: %w", err)
+	}
+
+	if err := tx.Model(incident).Update("related_events", string(relatedJSON)).Error; err != nil {
+		return fmt.Errorf("failed to update incident: %w", err)
+	}
+
+	log.Printf("Attached event %s to open incident %s", event.EventID, incident.IncidentID)
 	return nil
 }
 
-// sendNotification sends a notification
-func (de *DetectionEngine) sendNotification(event *models.Event, rule Rule, action RuleAction) {
+// notificationParams builds notify parameters for a rule match, including
+// the attributes notification routes match on
+func (de *DetectionEngine) notificationParams(event *models.Event, rule Rule, action RuleAction, incident *models.Incident) map[string]interface{} {
 	message := action.Message
 	if message == "" {
 		message = fmt.Sprintf("Rule '%s' triggered by event %s", rule.Rule.Name, event.EventID)
 	}
 
-	// For MVP, just log the notification
 	channel := action.Channel
 	if channel == "" && len(action.Channels) > 0 {
 		channel = action.Channels[0]
 	}
 
-	log.Printf("[NOTIFICATION] [%s] %s", channel, message)
+	params := map[string]interface{}{
+		"channel":  channel,
+		"message":  message,
+		"severity": rule.Rule.Severity,
+		"category": rule.Rule.Category,
+		"team":     rule.Rule.Team,
+		"tags":     rule.Rule.Tags,
+	}
+	if incident != nil {
+		params["incident_id"] = incident.IncidentID
+		params["severity"] = string(incident.Severity)
+		if incident.Team != "" {
+			params["team"] = incident.Team
+		}
+	}
+	if event.RequestID != nil {
+		params[RequestIDField] = *event.RequestID
+	}
+	return params
+}
+
+// toFloat converts JSON and YAML numbers to float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
 }
 
 // getNestedField retrieves a nested field from a map using dot notation
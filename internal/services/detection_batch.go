@@ -0,0 +1,265 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// detectionQueueSize bounds the events waiting for a worker at each
+// priority
+const detectionQueueSize = 10000
+
+// detectionQueueWait is how long a submitter waits for room in a full
+// queue before leaving the event to the stuck-event sweeper
+const detectionQueueWait = 2 * time.Second
+
+// collectBatch appends waiting events to the batch until it is full or the
+// linger interval has passed
+func (de *DetectionEngine) collectBatch(batch *[]*models.Event) {
+	var linger <-chan time.Time
+	if de.batchInterval > 0 {
+		timer := time.NewTimer(de.batchInterval)
+		defer timer.Stop()
+		linger = timer.C
+	}
+
+	for len(*batch) < de.batchSize && linger != nil {
+		select {
+		case <-de.queue.ready:
+			*batch = append(*batch, de.queue.take(de.batchSize-len(*batch))...)
+		case <-linger:
+			return
+		}
+	}
+}
+
+// preparedEvent is a batched event with its parsed, enriched payload
+type preparedEvent struct {
+	event      *models.Event
+	normalized map[string]interface{}
+}
+
+// EvaluateBatch evaluates stored events against all loaded rules, in order.
+// Each event is matched and acted on as by EvaluateEvent, but the count
+// conditions of the whole batch are computed up front.
+func (de *DetectionEngine) EvaluateBatch(events []*models.Event) {
+	prepared := make([]preparedEvent, 0, len(events))
+	for _, event := range events {
+		normalized, err := de.prepareEvent(event)
+		if err != nil {
+			log.Printf("Error evaluating event %s: %v", event.EventID, err)
+			de.traceFailure(event, err)
+			continue
+		}
+		prepared = append(prepared, preparedEvent{event: event, normalized: normalized})
+	}
+	if len(prepared) == 0 {
+		return
+	}
+
+	start := time.Now()
+	counts := de.batchCounts(prepared)
+	shared := time.Since(start) / time.Duration(len(prepared))
+
+	for _, p := range prepared {
+		de.processEvent(p.event, p.normalized, counts.evaluate, shared)
+	}
+}
+
+// countKey identifies one event's count for a count condition scope
+type countKey struct {
+	event  *models.Event
+	scope  string
+	window int
+}
+
+// countRequest is an event's count that a batch needs
+type countRequest struct {
+	key        countKey
+	event      *models.Event
+	normalized map[string]interface{}
+	start, end time.Time
+}
+
+// countScan is the count requests sharing a scope: the same condition
+// operator, filter and group values
+type countScan struct {
+	cond      Condition
+	scope     string
+	scopeArgs []interface{}
+	requests  []countRequest
+}
+
+// windowCounts holds the counts computed for a batch
+type windowCounts struct {
+	de     *DetectionEngine
+	counts map[countKey]int64
+}
+
+// scopeKey identifies a count condition scope for an event; conditions with
+// equal keys count the same events, apart from their windows. Events of
+// different tenants never share a scope, since their databases differ.
+func scopeKey(event *models.Event, normalized map[string]interface{}, cond Condition) (key, scope string, args []interface{}, ok bool) {
+	scope, args, ok = countScope(event, normalized, cond)
+	if !ok {
+		return "", "", nil, false
+	}
+	key = event.Tenant + "|" + cond.Operator + "|" + scope + "|" + fmt.Sprint(args...)
+	if cond.Operator == "count_distinct" {
+		key += "|" + cond.distinctField()
+	}
+	return key, scope, args, true
+}
+
+// batchCounts finds the count conditions the batch's events reach, with a
+// matching pass that assumes every count and seen-value condition holds, and
+// computes them per scope
+func (de *DetectionEngine) batchCounts(prepared []preparedEvent) *windowCounts {
+	scans := make(map[string]*countScan)
+	var order []string
+	planned := make(map[countKey]bool)
+	plan := func(event *models.Event, normalized map[string]interface{}, cond Condition) (bool, int64) {
+		if isSeenOperator(cond.Operator) {
+			return true, -1
+		}
+		key, scope, args, ok := scopeKey(event, normalized, cond)
+		if !ok {
+			return false, -1
+		}
+		request := countKey{event: event, scope: key, window: cond.TimeWindow}
+		if planned[request] {
+			return true, -1
+		}
+		planned[request] = true
+		scan := scans[key]
+		if scan == nil {
+			scan = &countScan{cond: cond, scope: scope, scopeArgs: args}
+			scans[key] = scan
+			order = append(order, key)
+		}
+		start, end := countWindow(event, cond)
+		scan.requests = append(scan.requests, countRequest{key: request, event: event, normalized: normalized, start: start, end: end})
+		return true, -1
+	}
+	for _, p := range prepared {
+		de.matchingRules(p.event, p.normalized, plan, nil, nil)
+	}
+
+	counts := &windowCounts{de: de, counts: make(map[countKey]int64, len(planned))}
+	for _, key := range order {
+		if err := de.computeCounts(scans[key], counts.counts); err != nil {
+			log.Printf("Error evaluating %s condition: %v", scans[key].cond.Operator, err)
+		}
+	}
+	return counts
+}
+
+// computeCounts computes the counts of a scope's requests. A single request
+// is counted by the database as EvaluateEvent would; several are counted from
+// one scan of the events across all their windows.
+func (de *DetectionEngine) computeCounts(scan *countScan, counts map[countKey]int64) error {
+	if len(scan.requests) == 1 {
+		request := scan.requests[0]
+		sql, args, _ := countQuery(request.event, request.normalized, scan.cond)
+		var count int64
+		if err := de.eventDB(request.event).Raw(sql, args...).Scan(&count).Error; err != nil {
+			return err
+		}
+		counts[request.key] = count
+		return nil
+	}
+
+	start, end := scan.requests[0].start, scan.requests[0].end
+	for _, request := range scan.requests[1:] {
+		if request.start.Before(start) {
+			start = request.start
+		}
+		if request.end.After(end) {
+			end = request.end
+		}
+	}
+
+	selectExpr := "timestamp"
+	var args []interface{}
+	distinct := scan.cond.Operator == "count_distinct"
+	if distinct {
+		expr, exprArgs := countFieldExpr(scan.cond.distinctField())
+		selectExpr += ", " + expr
+		args = exprArgs
+	}
+	args = append(append(args, start, end), scan.scopeArgs...)
+	rows, err := de.eventDB(scan.requests[0].event).Raw("SELECT "+selectExpr+" FROM events WHERE timestamp >= ? AND timestamp <= ? AND "+scan.scope, args...).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type countedEvent struct {
+		at    time.Time
+		value string
+	}
+	var events []countedEvent
+	for rows.Next() {
+		var counted countedEvent
+		var value interface{}
+		if distinct {
+			err = rows.Scan(&counted.at, &value)
+		} else {
+			err = rows.Scan(&counted.at)
+		}
+		if err != nil {
+			return err
+		}
+		if distinct {
+			if value == nil {
+				continue // COUNT(DISTINCT) skips NULLs
+			}
+			if b, ok := value.([]byte); ok {
+				value = string(b)
+			}
+			counted.value = fmt.Sprintf("%T:%v", value, value)
+		}
+		events = append(events, counted)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, request := range scan.requests {
+		var count int64
+		values := make(map[string]bool)
+		for _, counted := range events {
+			if counted.at.Before(request.start) || counted.at.After(request.end) {
+				continue
+			}
+			if !distinct {
+				count++
+			} else if !values[counted.value] {
+				values[counted.value] = true
+				count++
+			}
+		}
+		counts[request.key] = count
+	}
+	return nil
+}
+
+// evaluate decides count conditions from the batch's counts, falling back
+// to a query for any it lacks, and seen-value conditions as usual
+func (w *windowCounts) evaluate(event *models.Event, normalized map[string]interface{}, cond Condition) (bool, int64) {
+	if isSeenOperator(cond.Operator) {
+		return w.de.evaluateSeenCondition(event, normalized, cond)
+	}
+	key, _, _, ok := scopeKey(event, normalized, cond)
+	if !ok {
+		return false, -1
+	}
+	count, found := w.counts[countKey{event: event, scope: key, window: cond.TimeWindow}]
+	if !found {
+		return w.de.evaluateCountCondition(event, normalized, cond)
+	}
+	return int(count) >= cond.Threshold, count
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// maxExplainedEvents bounds the event IDs listed for a count window
+const maxExplainedEvents = 100
+
+// ErrNoDetection is returned for an incident no rule match created
+var ErrNoDetection = errors.New("incident was not created by a rule match")
+
+// MatchExplanation is why a rule created an incident
+type MatchExplanation struct {
+	IncidentID     string    `json:"incident_id"`
+	RuleID         string    `json:"rule_id"`
+	RuleName       string    `json:"rule_name"`
+	EventID        string    `json:"event_id"`
+	MatchedAt      time.Time `json:"matched_at"`
+	CorrelationKey string    `json:"correlation_key,omitempty"`
+	// Conditions are the rule's conditions, all passed, with the values
+	// and counts observed
+	Conditions []ConditionTrace `json:"conditions"`
+	// Windows are the events each count condition counted
+	Windows []MatchWindow `json:"windows,omitempty"`
+	// ContributingEvents are the triggering event and those its count
+	// conditions counted, oldest first
+	ContributingEvents []string `json:"contributing_events"`
+}
+
+// MatchWindow is the window a count condition counted events in
+type MatchWindow struct {
+	Condition     int                    `json:"condition"`
+	Operator      string                 `json:"operator"`
+	GroupBy       map[string]interface{} `json:"group_by,omitempty"`
+	DistinctField string                 `json:"distinct_field,omitempty"`
+	Start         time.Time              `json:"start"`
+	End           time.Time              `json:"end"`
+	Count         int64                  `json:"count"`
+	Threshold     int                    `json:"threshold"`
+	// EventIDs are the events in the window, oldest first; Truncated when
+	// there were more than are listed
+	EventIDs  []string `json:"event_ids"`
+	Truncated bool     `json:"truncated,omitempty"`
+}
+
+// explainMatch stores the explanation of the rule match that created an
+// incident, from the rule's trace for the triggering event
+func (de *DetectionEngine) explainMatch(tx *gorm.DB, incident *models.Incident, event *models.Event, normalized map[string]interface{}, rule Rule, trace *RuleTrace) error {
+	explanation := MatchExplanation{
+		IncidentID:         incident.IncidentID,
+		RuleID:             rule.Rule.ID,
+		RuleName:           rule.Rule.Name,
+		EventID:            event.EventID,
+		MatchedAt:          time.Now().UTC(),
+		CorrelationKey:     incident.CorrelationKey,
+		Conditions:         []ConditionTrace{},
+		ContributingEvents: []string{},
+	}
+	if trace != nil {
+		explanation.Conditions = trace.Conditions
+	}
+
+	contributing := make(map[string]bool)
+	for i, cond := range rule.Rule.Conditions {
+		if !isCountOperator(cond.Operator) {
+			continue
+		}
+		window, err := countedEvents(tx, event, normalized, cond)
+		if err != nil {
+			return err
+		}
+		if window == nil {
+			continue
+		}
+		window.Condition = i
+		if trace != nil && i < len(trace.Conditions) && trace.Conditions[i].Count != nil {
+			window.Count = *trace.Conditions[i].Count
+		}
+		explanation.Windows = append(explanation.Windows, *window)
+		for _, id := range window.EventIDs {
+			if !contributing[id] {
+				contributing[id] = true
+				explanation.ContributingEvents = append(explanation.ContributingEvents, id)
+			}
+		}
+	}
+	if !contributing[event.EventID] {
+		explanation.ContributingEvents = append(explanation.ContributingEvents, event.EventID)
+	}
+
+	encoded, err := json.Marshal(explanation)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.IncidentDetection{
+		IncidentID:  incident.IncidentID,
+		RuleID:      rule.Rule.ID,
+		EventID:     event.EventID,
+		Explanation: string(encoded),
+	}).Error
+}
+
+// countedEvents lists the events a count condition counted for the event,
+// or returns nil when the event can't be grouped
+func countedEvents(db *gorm.DB, event *models.Event, normalized map[string]interface{}, cond Condition) (*MatchWindow, error) {
+	scope, scopeArgs, ok := countScope(event, normalized, cond)
+	if !ok {
+		return nil, nil
+	}
+	start, end := countWindow(event, cond)
+	window := &MatchWindow{
+		Operator:  cond.Operator,
+		Start:     start,
+		End:       end,
+		Threshold: cond.Threshold,
+		EventIDs:  []string{},
+	}
+	if cond.Operator == "count_distinct" {
+		window.DistinctField = cond.distinctField()
+	}
+	if fields := cond.groupBy(); len(fields) > 0 {
+		window.GroupBy = make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			window.GroupBy[field] = eventField(event, normalized, field)
+		}
+	}
+
+	args := append([]interface{}{start, end}, scopeArgs...)
+	args = append(args, maxExplainedEvents+1)
+	err := db.Raw("SELECT event_id FROM events WHERE timestamp >= ? AND timestamp <= ? AND "+scope+
+		" ORDER BY timestamp, rowid LIMIT ?", args...).Scan(&window.EventIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list counted events: %w", err)
+	}
+	if len(window.EventIDs) > maxExplainedEvents {
+		window.EventIDs = window.EventIDs[:maxExplainedEvents]
+		window.Truncated = true
+	}
+	return window, nil
+}
+
+// IncidentExplanation returns the explanation of the rule match that
+// created an incident
+func IncidentExplanation(db *gorm.DB, incidentID string) (*MatchExplanation, error) {
+	var detection models.IncidentDetection
+	err := db.First(&detection, "incident_id = ?", incidentID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		var count int64
+		if err := db.Model(&models.Incident{}).Where("incident_id = ?", incidentID).Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch incident: %w", err)
+		}
+		if count == 0 {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, ErrNoDetection
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch detection: %w", err)
+	}
+
+	var explanation MatchExplanation
+	if err := json.Unmarshal([]byte(detection.Explanation), &explanation); err != nil {
+		return nil, fmt.Errorf("failed to parse detection: %w", err)
+	}
+	return &explanation, nil
+}
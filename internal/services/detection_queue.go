@@ -0,0 +1,297 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// Detection priorities, highest first. Workers take events from the highest
+// priority queue that has any, so a critical event submitted behind a
+// backlog of info events is evaluated next.
+const (
+	PriorityCritical = iota // critical and high events
+	PriorityNormal          // medium events
+	PriorityLow             // low, info and unrated events
+	priorityLevels
+)
+
+// priorityNames name the priorities in queue stats
+var priorityNames = [priorityLevels]string{"critical", "normal", "low"}
+
+// eventPriority returns the detection priority of an event's severity
+func eventPriority(event *models.Event) int {
+	switch rank := event.Severity.Rank(); {
+	case rank >= models.SeverityHigh.Rank():
+		return PriorityCritical
+	case rank == models.SeverityMedium.Rank():
+		return PriorityNormal
+	}
+	return PriorityLow
+}
+
+// queuedEvent is an event waiting for a detection worker
+type queuedEvent struct {
+	event    *models.Event
+	queuedAt time.Time
+}
+
+// detectionQueue holds submitted events by priority, each level FIFO and
+// bounded by detectionQueueSize
+type detectionQueue struct {
+	mu     sync.Mutex
+	levels [priorityLevels][]queuedEvent
+	// ready holds a signal while events are waiting, and room one when a
+	// worker has taken events, for submitters waiting on a full queue
+	ready chan struct{}
+	room  chan struct{}
+	// maxWait is how long an event may wait before it is taken ahead of
+	// higher priorities; 0 leaves lower priorities waiting while higher
+	// ones have events
+	maxWait time.Duration
+
+	evaluated [priorityLevels]int64
+	promoted  [priorityLevels]int64
+	overflow  [priorityLevels]int64
+}
+
+// DetectionQueueStats describes the detection queues
+type DetectionQueueStats struct {
+	Enabled  bool `json:"enabled"`
+	Workers  int  `json:"workers"`
+	Capacity int  `json:"capacity"` // per priority
+	// MaxWaitMS is how long an event may wait before it is taken ahead of
+	// higher priorities
+	MaxWaitMS  int64                `json:"max_wait_ms"`
+	Priorities []PriorityQueueStats `json:"priorities"`
+}
+
+// PriorityQueueStats describes the queue of one detection priority
+type PriorityQueueStats struct {
+	Priority string `json:"priority"`
+	Depth    int    `json:"depth"`
+	// OldestWaitMS is how long the oldest waiting event has been queued
+	OldestWaitMS float64 `json:"oldest_wait_ms"`
+	// Evaluated counts events taken by workers, Promoted those of them
+	// taken ahead of higher priorities for having waited too long and
+	// Overflow those left for the stuck-event sweeper because the queue
+	// stayed full
+	Evaluated int64 `json:"evaluated"`
+	Promoted  int64 `json:"promoted"`
+	Overflow  int64 `json:"overflow"`
+}
+
+func newDetectionQueue(maxWait time.Duration) *detectionQueue {
+	return &detectionQueue{ready: make(chan struct{}, 1), room: make(chan struct{}, 1), maxWait: maxWait}
+}
+
+// push queues an event, waiting up to wait for room when its priority's
+// queue is full, and returns false if it stayed full
+func (q *detectionQueue) push(event *models.Event, wait time.Duration) bool {
+	priority := eventPriority(event)
+	var deadline <-chan time.Time
+	for {
+		q.mu.Lock()
+		if len(q.levels[priority]) < detectionQueueSize {
+			q.levels[priority] = append(q.levels[priority], queuedEvent{event: event, queuedAt: time.Now()})
+			more := len(q.levels[priority]) < detectionQueueSize
+			q.mu.Unlock()
+			q.signal()
+			if more {
+				// Pass the room on to the next waiting submitter
+				notify(q.room)
+			}
+			return true
+		}
+		q.mu.Unlock()
+
+		if deadline == nil {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+		select {
+		case <-q.room:
+		case <-deadline:
+			q.mu.Lock()
+			q.overflow[priority]++
+			q.mu.Unlock()
+			return false
+		}
+	}
+}
+
+// signal wakes a waiting worker
+func (q *detectionQueue) signal() {
+	notify(q.ready)
+}
+
+// notify leaves a signal on ch unless one is already waiting
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// take removes up to max events, in the order they should be evaluated, and
+// leaves a signal for the next worker when events remain
+func (q *detectionQueue) take(max int) []*models.Event {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	var events []*models.Event
+	for len(events) < max {
+		priority := q.next(now)
+		if priority < 0 {
+			break
+		}
+		level := q.levels[priority]
+		events = append(events, level[0].event)
+		level[0] = queuedEvent{}
+		q.levels[priority] = level[1:]
+		q.evaluated[priority]++
+	}
+	for _, level := range q.levels {
+		if len(level) > 0 {
+			q.signal()
+			break
+		}
+	}
+	if len(events) > 0 {
+		notify(q.room)
+	}
+	return events
+}
+
+// next returns the priority to take an event from, or -1 when none are
+// waiting. Events that have waited longer than maxWait go first, oldest
+// first, so a steady stream of critical events can't starve the rest;
+// otherwise the highest priority with events is taken.
+func (q *detectionQueue) next(now time.Time) int {
+	highest, overdue := -1, -1
+	for priority, level := range q.levels {
+		if len(level) == 0 {
+			continue
+		}
+		if highest < 0 {
+			highest = priority
+		}
+		if q.maxWait > 0 && now.Sub(level[0].queuedAt) >= q.maxWait &&
+			(overdue < 0 || level[0].queuedAt.Before(q.levels[overdue][0].queuedAt)) {
+			overdue = priority
+		}
+	}
+	if overdue >= 0 && overdue != highest {
+		q.promoted[overdue]++
+		return overdue
+	}
+	return highest
+}
+
+// stats reports the depth and throughput of each priority
+func (q *detectionQueue) stats() []PriorityQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	stats := make([]PriorityQueueStats, priorityLevels)
+	for priority, level := range q.levels {
+		stats[priority] = PriorityQueueStats{
+			Priority:  priorityNames[priority],
+			Depth:     len(level),
+			Evaluated: q.evaluated[priority],
+			Promoted:  q.promoted[priority],
+			Overflow:  q.overflow[priority],
+		}
+		if len(level) > 0 {
+			stats[priority].OldestWaitMS = ms(now.Sub(level[0].queuedAt))
+		}
+	}
+	return stats
+}
+
+// StartWorkers evaluates submitted events on workers goroutines, taking them
+// from per-severity queues highest priority first (see detectionQueue.next).
+// Each worker evaluates a batch at a time, of up to batchSize events: the
+// events waiting, lingering up to batchInterval for more when the batch
+// isn't full. Count conditions of a batch share their window queries: events
+// with the same filter and group values are counted from one scan instead of
+// one query each. A batchSize of 1 or less evaluates events one at a time.
+// With no workers every event is evaluated on its own as soon as it's
+// submitted.
+func (de *DetectionEngine) StartWorkers(workers, batchSize int, batchInterval, maxWait time.Duration) {
+	if workers <= 0 || de.queue != nil {
+		return
+	}
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	de.workers = workers
+	de.batchSize = batchSize
+	de.batchInterval = batchInterval
+	de.queue = newDetectionQueue(maxWait)
+	for i := 0; i < workers; i++ {
+		go de.runWorker()
+	}
+}
+
+// Submit queues stored events for asynchronous detection. When an event's
+// queue is full the submitter waits for room, which slows ingestion down to
+// what detection keeps up with. Events still not queued after
+// detectionQueueWait stay unprocessed in the database, for the stuck-event
+// sweeper to requeue once the backlog clears, and are returned.
+func (de *DetectionEngine) Submit(events ...*models.Event) []*models.Event {
+	var left []*models.Event
+	wait := detectionQueueWait
+	for _, event := range events {
+		if de.queue == nil {
+			go de.EvaluateEvent(event)
+			continue
+		}
+		if !de.queue.push(event, wait) {
+			left = append(left, event)
+			// The rest of a batch would only wait in turn
+			wait = 0
+		}
+	}
+	if len(left) > 0 {
+		log.Printf("Detection queue full: %d events left for reprocessing", len(left))
+	}
+	return left
+}
+
+// QueueStats reports the detection queues' depths
+func (de *DetectionEngine) QueueStats() DetectionQueueStats {
+	if de.queue == nil {
+		return DetectionQueueStats{}
+	}
+	return DetectionQueueStats{
+		Enabled:    true,
+		Workers:    de.workers,
+		Capacity:   detectionQueueSize,
+		MaxWaitMS:  de.queue.maxWait.Milliseconds(),
+		Priorities: de.queue.stats(),
+	}
+}
+
+// runWorker evaluates queued events a batch at a time
+func (de *DetectionEngine) runWorker() {
+	for range de.queue.ready {
+		batch := de.queue.take(de.batchSize)
+		if len(batch) == 0 {
+			continue
+		}
+		if de.batchSize <= 1 {
+			if err := de.EvaluateEvent(batch[0]); err != nil {
+				log.Printf("Error evaluating event %s: %v", batch[0].EventID, err)
+			}
+			continue
+		}
+		de.collectBatch(&batch)
+		de.EvaluateBatch(batch)
+	}
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// ErrEventNotFound is returned for an event that doesn't exist
+var ErrEventNotFound = errors.New("event not found")
+
+// EventTrace is how detection evaluated an event
+type EventTrace struct {
+	EventID     string    `json:"event_id"`
+	EvaluatedAt time.Time `json:"evaluated_at"`
+	// Reprocess is the event's reprocess count when it was evaluated; 0 for
+	// its first evaluation
+	Reprocess int `json:"reprocess,omitempty"`
+	// Rules are the rules the event was matched against, in order
+	Rules []*RuleTrace `json:"rules"`
+	// NotEvaluated are the rules skipped because their event_type or source
+	// condition rules the event out
+	NotEvaluated []string `json:"not_evaluated,omitempty"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// RuleTrace is the outcome of one rule for an event. Conditions are
+// evaluated in order and stop at the first that fails, so a rule that
+// didn't match ends with its failing condition.
+type RuleTrace struct {
+	RuleID     string           `json:"rule_id"`
+	Name       string           `json:"name"`
+	Matched    bool             `json:"matched"`
+	Conditions []ConditionTrace `json:"conditions"`
+
+	// What a match led to
+	SuppressedBy    string   `json:"suppressed_by,omitempty"`
+	IncidentID      string   `json:"incident_id,omitempty"`
+	IncidentCreated bool     `json:"incident_created,omitempty"`
+	Actions         []string `json:"actions,omitempty"`
+	Error           string   `json:"error,omitempty"`
+}
+
+// ConditionTrace is the outcome of one rule condition
+type ConditionTrace struct {
+	Index    int    `json:"index"`
+	Field    string `json:"field,omitempty"`
+	Operator string `json:"operator"`
+	Passed   bool   `json:"passed"`
+	// Value is the event's value of the field, or its time for time
+	// conditions
+	Value interface{} `json:"value,omitempty"`
+	// Count is the count a count or seen-value condition compared
+	Count  *int64 `json:"count,omitempty"`
+	Detail string `json:"detail"`
+}
+
+// SetTracing turns storing a trace of each evaluated event on or off.
+// Evaluations are traced either way, to explain the incidents they create.
+func (de *DetectionEngine) SetTracing(enabled bool) {
+	de.tracing = enabled
+}
+
+// newTrace starts the trace of an event's evaluation
+func (de *DetectionEngine) newTrace(event *models.Event) *EventTrace {
+	return &EventTrace{
+		EventID:     event.EventID,
+		EvaluatedAt: time.Now().UTC(),
+		Reprocess:   event.ReprocessCount,
+		Rules:       []*RuleTrace{},
+	}
+}
+
+// rule returns the trace of a rule evaluated for the event
+func (t *EventTrace) rule(ruleID string) *RuleTrace {
+	if t == nil {
+		return nil
+	}
+	for _, rule := range t.Rules {
+		if rule.RuleID == ruleID {
+			return rule
+		}
+	}
+	return nil
+}
+
+// addAction records an action a rule's match queued
+func (t *RuleTrace) addAction(action string) {
+	if t != nil {
+		t.Actions = append(t.Actions, action)
+	}
+}
+
+// record returns the trace to store
+func (t *EventTrace) record() *models.DetectionTrace {
+	encoded, _ := json.Marshal(t)
+	return &models.DetectionTrace{EventID: t.EventID, EvaluatedAt: t.EvaluatedAt, Trace: string(encoded)}
+}
+
+// traceCondition evaluates a condition as evaluateCondition does, and
+// explains the outcome
+func (de *DetectionEngine) traceCondition(event *models.Event, normalized map[string]interface{}, index int, cond Condition, count countEvaluator) ConditionTrace {
+	outcome := ConditionTrace{Index: index, Field: cond.Field, Operator: cond.Operator}
+
+	switch {
+	case isCountOperator(cond.Operator) || isSeenOperator(cond.Operator):
+		passed, n := count(event, normalized, cond)
+		outcome.Passed = passed
+		if n >= 0 {
+			outcome.Count = &n
+		}
+		outcome.Detail = countDetail(cond, passed, n)
+
+	case isTimeOperator(cond.Operator):
+		outcome.Passed = de.evaluateTimeCondition(event, cond)
+		loc := cond.location
+		if loc == nil {
+			loc = de.hours.Location()
+		}
+		outcome.Value = event.Timestamp.In(loc).Format(time.RFC3339)
+		outcome.Detail = timeDetail(cond, outcome.Passed)
+
+	default:
+		outcome.Passed = de.evaluateCondition(event, normalized, cond, count)
+		outcome.Value = eventField(event, normalized, cond.Field)
+		outcome.Detail = fieldDetail(cond, outcome.Value, outcome.Passed)
+	}
+	return outcome
+}
+
+// fieldDetail explains the outcome of a condition on a field's value
+func fieldDetail(cond Condition, value interface{}, passed bool) string {
+	if value == nil {
+		return fmt.Sprintf("event has no %s", cond.Field)
+	}
+	shown := describeValue(value)
+
+	switch cond.Operator {
+	case "equals":
+		if passed {
+			return fmt.Sprintf("%s is %s", cond.Field, shown)
+		}
+		return fmt.Sprintf("%s is %s, not %s", cond.Field, shown, describeValue(cond.Value))
+	case "in":
+		if passed {
+			return fmt.Sprintf("%s %s is in %v", cond.Field, shown, cond.Values)
+		}
+		return fmt.Sprintf("%s %s is not in %v", cond.Field, shown, cond.Values)
+	case "greater_than":
+		if _, ok := toFloat(value); !ok {
+			return fmt.Sprintf("%s %s is not a number", cond.Field, shown)
+		}
+		if passed {
+			return fmt.Sprintf("%s %s is greater than %v", cond.Field, shown, cond.Value)
+		}
+		return fmt.Sprintf("%s %s is not greater than %v", cond.Field, shown, cond.Value)
+	case "regex":
+		if passed {
+			return fmt.Sprintf("%s %s matches /%s/", cond.Field, shown, cond.Pattern)
+		}
+		return fmt.Sprintf("%s %s doesn't match /%s/", cond.Field, shown, cond.Pattern)
+	case "in_watchlist":
+		if passed {
+			return fmt.Sprintf("%s %s is on watchlist %s", cond.Field, shown, cond.Watchlist)
+		}
+		return fmt.Sprintf("%s %s is not on watchlist %s", cond.Field, shown, cond.Watchlist)
+	}
+	return fmt.Sprintf("unknown operator %s", cond.Operator)
+}
+
+// countDetail explains the outcome of a count or seen-value condition
+func countDetail(cond Condition, passed bool, n int64) string {
+	if n < 0 {
+		if passed {
+			return "matched"
+		}
+		return "event lacks the condition's fields, or the lookup failed"
+	}
+
+	switch cond.Operator {
+	case "count":
+		return fmt.Sprintf("%d matching events within %ds, threshold %d", n, cond.TimeWindow, cond.Threshold)
+	case "count_distinct":
+		return fmt.Sprintf("%d distinct %s within %ds, threshold %d", n, cond.distinctField(), cond.TimeWindow, cond.Threshold)
+	}
+
+	before := n - 1 // the count includes this event
+	switch {
+	case cond.Operator == "first_seen" && passed:
+		return fmt.Sprintf("%s not seen before", cond.Field)
+	case cond.Operator == "rare" && passed:
+		return fmt.Sprintf("%s seen %d times before, below threshold %d", cond.Field, before, cond.Threshold)
+	case cond.LearningPeriod > 0 && (before == 0 || (cond.Operator == "rare" && before < int64(cond.Threshold))):
+		return "condition is still in its learning period"
+	case cond.Operator == "rare":
+		return fmt.Sprintf("%s seen %d times before, threshold %d", cond.Field, before, cond.Threshold)
+	}
+	return fmt.Sprintf("%s seen %d times before", cond.Field, before)
+}
+
+// timeDetail explains the outcome of a time condition
+func timeDetail(cond Condition, passed bool) string {
+	switch cond.Operator {
+	case "business_hours":
+		if passed {
+			return "event time is within business hours"
+		}
+		return "event time is outside business hours"
+	case "outside_business_hours":
+		if passed {
+			return "event time is outside business hours"
+		}
+		return "event time is within business hours"
+	case "weekend":
+		if passed {
+			return "event time is on a weekend"
+		}
+		return "event time is on a working day"
+	}
+	if passed {
+		return "event time is within the window"
+	}
+	return "event time is outside the window"
+}
+
+// describeValue formats a value for a detail, quoting strings
+func describeValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// EventTraces returns the recorded evaluations of an event, newest first
+func EventTraces(db *gorm.DB, eventID string) ([]EventTrace, error) {
+	var count int64
+	if err := db.Model(&models.Event{}).Where("event_id = ?", eventID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch event: %w", err)
+	}
+	if count == 0 {
+		return nil, ErrEventNotFound
+	}
+
+	var records []models.DetectionTrace
+	err := db.Where("event_id = ?", eventID).Order("evaluated_at DESC, id DESC").Find(&records).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch detection traces: %w", err)
+	}
+	traces := make([]EventTrace, 0, len(records))
+	for _, record := range records {
+		var trace EventTrace
+		if err := json.Unmarshal([]byte(record.Trace), &trace); err != nil {
+			return nil, fmt.Errorf("failed to parse detection trace %d: %w", record.ID, err)
+		}
+		traces = append(traces, trace)
+	}
+	return traces, nil
+}
+
+// PruneTraces deletes traces of evaluations older than retention, in the
+// shared database and every tenant's
+func (de *DetectionEngine) PruneTraces(retention time.Duration) error {
+	dbs := []*gorm.DB{de.db}
+	for _, tenant := range de.tenants.Names() {
+		dbs = append(dbs, de.tenants.Lookup(tenant))
+	}
+	cutoff := time.Now().UTC().Add(-retention)
+	for _, db := range dbs {
+		if err := db.Where("evaluated_at < ?", cutoff).Delete(&models.DetectionTrace{}).Error; err != nil {
+			return fmt.Errorf("failed to prune detection traces: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,408 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// defaultEnrichmentTimeout bounds a stage that doesn't set its own timeout
+const defaultEnrichmentTimeout = 2 * time.Second
+
+// Enricher is one stage of the enrichment pipeline. Enrich returns the
+// normalized fields to add to an event; it must not modify normalized,
+// which may still be read after the stage has timed out.
+type Enricher interface {
+	Enrich(ctx context.Context, event *models.Event, normalized map[string]interface{}) (map[string]interface{}, error)
+}
+
+// EnrichmentStageSpec configures a pipeline stage in the enrichment file
+type EnrichmentStageSpec struct {
+	Name string `yaml:"name" json:"name"`
+	// Type is geoip, asset, threat_intel, user or http
+	Type string `yaml:"type" json:"type"`
+	// Field is the normalized field the stage looks up and Target the field
+	// it writes; both default per type
+	Field  string `yaml:"field" json:"field"`
+	Target string `yaml:"target" json:"target"`
+	// File holds the lookup table of asset and user stages
+	File string `yaml:"file" json:"file,omitempty"`
+	// HTTP stages call URL, a template with {{ event.* }} placeholders, with
+	// an optional JSON body template, and copy the response paths in Fields
+	// onto the event (or the whole response to Target without Fields)
+	URL     string            `yaml:"url" json:"url,omitempty"`
+	Method  string            `yaml:"method" json:"method,omitempty"`
+	Headers map[string]string `yaml:"headers" json:"-"`
+	Body    interface{}       `yaml:"body" json:"body,omitempty"`
+	Fields  map[string]string `yaml:"fields" json:"fields,omitempty"`
+	// EventTypes limits the stage to some event types
+	EventTypes []string `yaml:"event_types" json:"event_types,omitempty"`
+	// Timeout is a Go duration; the stage's result is dropped past it
+	Timeout string `yaml:"timeout" json:"timeout,omitempty"`
+	// Overwrite lets the stage replace fields the event already has
+	Overwrite bool  `yaml:"overwrite" json:"overwrite"`
+	Enabled   *bool `yaml:"enabled" json:"enabled"`
+}
+
+// EnrichmentFile is the YAML layout of the enrichment file
+type EnrichmentFile struct {
+	Stages []EnrichmentStageSpec `yaml:"stages"`
+}
+
+// enrichmentDefaults are the default field and target of each stage type
+var enrichmentDefaults = map[string][2]string{
+	"geoip":        {"source_ip", "geo"},
+	"asset":        {"host", "asset"},
+	"threat_intel": {"source_ip", "threat_intel"},
+	"user":         {"username", "user_profile"},
+}
+
+type enrichmentStage struct {
+	spec       EnrichmentStageSpec
+	enricher   Enricher
+	timeout    time.Duration
+	eventTypes map[string]bool
+}
+
+// EnrichmentPipeline runs ordered enrichment stages on each event between
+// ingest and detection. A stage that fails, panics or times out is logged
+// and skipped; the event continues through the remaining stages with the
+// fields added so far.
+type EnrichmentPipeline struct {
+	stages []enrichmentStage
+	perf   *PerfRecorder
+}
+
+// LoadEnrichmentPipeline reads the enrichment file and builds its enabled
+// stages. Without the file no enrichment runs.
+func LoadEnrichmentPipeline(path string, geo *GeoIPDatabase, detection *DetectionEngine) (*EnrichmentPipeline, error) {
+	pipeline := &EnrichmentPipeline{perf: detection.Perf()}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return pipeline, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read enrichment file: %w", err)
+	}
+	var file EnrichmentFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse enrichment file: %w", err)
+	}
+
+	names := make(map[string]bool)
+	for i, spec := range file.Stages {
+		if spec.Name == "" {
+			spec.Name = spec.Type
+		}
+		if names[spec.Name] {
+			return nil, fmt.Errorf("stage %d: duplicate name %q", i+1, spec.Name)
+		}
+		names[spec.Name] = true
+		if spec.Enabled != nil && !*spec.Enabled {
+			continue
+		}
+		stage, err := newEnrichmentStage(spec, geo, detection)
+		if err != nil {
+			return nil, fmt.Errorf("stage %s: %w", spec.Name, err)
+		}
+		pipeline.stages = append(pipeline.stages, stage)
+	}
+	return pipeline, nil
+}
+
+// newEnrichmentStage builds a stage from its spec
+func newEnrichmentStage(spec EnrichmentStageSpec, geo *GeoIPDatabase, detection *DetectionEngine) (enrichmentStage, error) {
+	stage := enrichmentStage{timeout: defaultEnrichmentTimeout}
+	if defaults, ok := enrichmentDefaults[spec.Type]; ok {
+		if spec.Field == "" {
+			spec.Field = defaults[0]
+		}
+		if spec.Target == "" {
+			spec.Target = defaults[1]
+		}
+	}
+	if spec.Timeout != "" {
+		timeout, err := time.ParseDuration(spec.Timeout)
+		if err != nil || timeout <= 0 {
+			return stage, fmt.Errorf("invalid timeout %q", spec.Timeout)
+		}
+		stage.timeout = timeout
+	}
+	if len(spec.EventTypes) > 0 {
+		stage.eventTypes = make(map[string]bool, len(spec.EventTypes))
+		for _, eventType := range spec.EventTypes {
+			stage.eventTypes[eventType] = true
+		}
+	}
+
+	var err error
+	switch spec.Type {
+	case "geoip":
+		stage.enricher = &geoIPEnricher{geo: geo, field: spec.Field, target: spec.Target}
+	case "threat_intel":
+		stage.enricher = &threatIntelEnricher{detection: detection, field: spec.Field, target: spec.Target}
+	case "asset":
+		stage.enricher, err = loadAssetEnricher(spec)
+	case "user":
+		stage.enricher, err = loadUserEnricher(spec)
+	case "http":
+		stage.enricher, err = newHTTPEnricher(spec)
+	default:
+		return stage, fmt.Errorf("unknown type %q", spec.Type)
+	}
+	if err != nil {
+		return stage, err
+	}
+	stage.spec = spec
+	return stage, nil
+}
+
+// Stages returns the specs of the enabled stages, in order
+func (p *EnrichmentPipeline) Stages() []EnrichmentStageSpec {
+	specs := []EnrichmentStageSpec{}
+	if p == nil {
+		return specs
+	}
+	for _, stage := range p.stages {
+		specs = append(specs, stage.spec)
+	}
+	return specs
+}
+
+// Apply runs the pipeline on an event's normalized fields, adding the
+// fields each stage returns, and reports whether any were added
+func (p *EnrichmentPipeline) Apply(event *models.Event, normalized map[string]interface{}) bool {
+	if p == nil {
+		return false
+	}
+	changed := false
+	for _, stage := range p.stages {
+		if stage.eventTypes != nil && !stage.eventTypes[event.EventType] {
+			continue
+		}
+		start := time.Now()
+		fields, err := stage.run(event, normalized)
+		p.perf.recordEnrichment(stage.spec.Name, time.Since(start), err)
+		if err != nil {
+			log.Printf("Enrichment stage %s failed for event %s: %v", stage.spec.Name, event.EventID, err)
+			continue
+		}
+		for key, value := range fields {
+			if _, exists := normalized[key]; exists && !stage.spec.Overwrite {
+				continue
+			}
+			normalized[key] = value
+			changed = true
+		}
+	}
+	return changed
+}
+
+// run calls the stage's enricher with its timeout on a copy of the fields,
+// so a stage left running past its timeout can't race later stages
+func (s enrichmentStage) run(event *models.Event, normalized map[string]interface{}) (map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	type result struct {
+		fields map[string]interface{}
+		err    error
+	}
+	done := make(chan result, 1)
+	view := maps.Clone(normalized)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{err: fmt.Errorf("panic: %v", r)}
+			}
+		}()
+		fields, err := s.enricher.Enrich(ctx, event, view)
+		done <- result{fields: fields, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.fields, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out after %s", s.timeout)
+	}
+}
+
+// enrichmentKey returns the string a stage looks up, or "" when the event
+// lacks the field
+func enrichmentKey(event *models.Event, normalized map[string]interface{}, field string) string {
+	value := eventField(event, normalized, field)
+	if value == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprintf("%v", value))
+}
+
+// geoIPEnricher locates an address in the GeoIP database
+type geoIPEnricher struct {
+	geo           *GeoIPDatabase
+	field, target string
+}
+
+func (e *geoIPEnricher) Enrich(ctx context.Context, event *models.Event, normalized map[string]interface{}) (map[string]interface{}, error) {
+	ip := enrichmentKey(event, normalized, e.field)
+	if ip == "" {
+		return nil, nil
+	}
+	location, ok := e.geo.Lookup(ip)
+	if !ok {
+		return nil, nil
+	}
+	return map[string]interface{}{e.target: map[string]interface{}{
+		"latitude":  location.Latitude,
+		"longitude": location.Longitude,
+		"country":   location.Country,
+		"city":      location.City,
+	}}, nil
+}
+
+// threatIntelEnricher lists the watchlists a value is on
+type threatIntelEnricher struct {
+	detection     *DetectionEngine
+	field, target string
+}
+
+func (e *threatIntelEnricher) Enrich(ctx context.Context, event *models.Event, normalized map[string]interface{}) (map[string]interface{}, error) {
+	value := enrichmentKey(event, normalized, e.field)
+	if value == "" {
+		return nil, nil
+	}
+	watchlists := e.detection.WatchlistsContaining(value)
+	if len(watchlists) == 0 {
+		return nil, nil
+	}
+	return map[string]interface{}{e.target: map[string]interface{}{"watchlists": watchlists}}, nil
+}
+
+// lookupEnricher adds the record a value maps to in a lookup table
+type lookupEnricher struct {
+	records       map[string]map[string]interface{}
+	normalize     func(string) string
+	field, target string
+}
+
+func (e *lookupEnricher) Enrich(ctx context.Context, event *models.Event, normalized map[string]interface{}) (map[string]interface{}, error) {
+	key := enrichmentKey(event, normalized, e.field)
+	if key == "" {
+		return nil, nil
+	}
+	record, ok := e.records[e.normalize(key)]
+	if !ok {
+		return nil, nil
+	}
+	return map[string]interface{}{e.target: record}, nil
+}
+
+// loadLookupRecords reads a YAML file holding a list of records under
+// listKey, each record converted to JSON-compatible values
+func loadLookupRecords(path, listKey string) ([]map[string]interface{}, error) {
+	if path == "" {
+		return nil, fmt.Errorf("file is required")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var file map[string][]map[string]interface{}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	// Round-trip through JSON so records look like normalized event fields
+	encoded, err := json.Marshal(file[listKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var records []map[string]interface{}
+	if err := json.Unmarshal(encoded, &records); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return records, nil
+}
+
+// recordStrings returns a record's string field, or the strings in a list
+// field
+func recordStrings(record map[string]interface{}, key string) []string {
+	switch value := record[key].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		var out []string
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// loadAssetEnricher indexes an asset inventory by each asset's name,
+// hostnames and ips, ignoring case
+func loadAssetEnricher(spec EnrichmentStageSpec) (Enricher, error) {
+	assets, err := loadLookupRecords(spec.File, "assets")
+	if err != nil {
+		return nil, err
+	}
+	e := &lookupEnricher{records: make(map[string]map[string]interface{}), normalize: strings.ToLower, field: spec.Field, target: spec.Target}
+	for i, asset := range assets {
+		if name, _ := asset["name"].(string); name == "" {
+			return nil, fmt.Errorf("asset %d has no name", i+1)
+		}
+		for _, key := range []string{"name", "hostnames", "ips"} {
+			for _, value := range recordStrings(asset, key) {
+				e.records[strings.ToLower(value)] = asset
+			}
+		}
+	}
+	return e, nil
+}
+
+// loadUserEnricher indexes a user directory by each user's username and
+// aliases. Account names are resolved without their domain, so DOMAIN\jdoe
+// and jdoe@example.com both find jdoe.
+func loadUserEnricher(spec EnrichmentStageSpec) (Enricher, error) {
+	users, err := loadLookupRecords(spec.File, "users")
+	if err != nil {
+		return nil, err
+	}
+	e := &lookupEnricher{records: make(map[string]map[string]interface{}), normalize: accountName, field: spec.Field, target: spec.Target}
+	for i, user := range users {
+		if username, _ := user["username"].(string); username == "" {
+			return nil, fmt.Errorf("user %d has no username", i+1)
+		}
+		for _, key := range []string{"username", "aliases"} {
+			for _, value := range recordStrings(user, key) {
+				e.records[accountName(value)] = user
+			}
+		}
+	}
+	return e, nil
+}
+
+// accountName strips a Windows domain prefix or email domain from an
+// account and lowercases it
+func accountName(account string) string {
+	account = strings.ToLower(strings.TrimSpace(account))
+	if i := strings.LastIndex(account, `\`); i >= 0 {
+		account = account[i+1:]
+	}
+	if i := strings.Index(account, "@"); i >= 0 {
+		account = account[:i]
+	}
+	return account
+}
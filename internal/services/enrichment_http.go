@@ -0,0 +1,199 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// maxEnrichmentResponse bounds the response body an HTTP stage reads
+const maxEnrichmentResponse = 1 << 20
+
+// httpEnricher calls an external API for each event and maps fields of its
+// JSON response onto the event
+type httpEnricher struct {
+	client  *http.Client
+	method  string
+	url     []urlPart
+	headers map[string]string
+	body    interface{} // compiled transform template, nil for no body
+	fields  map[string]string
+	target  string
+}
+
+// urlPart is a literal piece of a URL template, or an expression whose
+// value is escaped into it
+type urlPart struct {
+	literal string
+	expr    *expression
+}
+
+// newHTTPEnricher compiles an http stage's URL, body and field mapping.
+// Header values may refer to environment variables as $NAME or ${NAME}.
+func newHTTPEnricher(spec EnrichmentStageSpec) (Enricher, error) {
+	if spec.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	if len(spec.Fields) == 0 && spec.Target == "" {
+		return nil, fmt.Errorf("fields or target is required")
+	}
+	e := &httpEnricher{
+		client:  &http.Client{},
+		method:  strings.ToUpper(spec.Method),
+		headers: make(map[string]string, len(spec.Headers)),
+		fields:  spec.Fields,
+		target:  spec.Target,
+	}
+	if e.method == "" {
+		e.method = http.MethodGet
+		if spec.Body != nil {
+			e.method = http.MethodPost
+		}
+	}
+
+	var err error
+	if e.url, err = compileURLTemplate(spec.URL); err != nil {
+		return nil, fmt.Errorf("url: %w", err)
+	}
+	if spec.Body != nil {
+		if e.body, err = compileTransform(plainValue(spec.Body)); err != nil {
+			return nil, fmt.Errorf("body: %w", err)
+		}
+	}
+	for name, value := range spec.Headers {
+		e.headers[name] = os.ExpandEnv(value)
+	}
+	for field, path := range spec.Fields {
+		if field == "" || path == "" {
+			return nil, fmt.Errorf("fields: empty field or response path")
+		}
+	}
+	return e, nil
+}
+
+// compileURLTemplate splits a URL on its {{ expression }} placeholders
+func compileURLTemplate(template string) ([]urlPart, error) {
+	var parts []urlPart
+	rest := template
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated {{ in %q", template)
+		}
+		end += start
+		if start > 0 {
+			parts = append(parts, urlPart{literal: rest[:start]})
+		}
+		expr, err := compileExpression(strings.TrimSpace(rest[start+2 : end]))
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, urlPart{expr: expr})
+		rest = rest[end+2:]
+	}
+	if rest != "" {
+		parts = append(parts, urlPart{literal: rest})
+	}
+	return parts, nil
+}
+
+// enrichmentVars exposes an event to templates as {{ event.* }}, in the
+// same shape as in playbooks
+func enrichmentVars(event *models.Event, normalized map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"event": map[string]interface{}{
+			"event_id":   event.EventID,
+			"timestamp":  event.Timestamp.UTC().Format(time.RFC3339),
+			"source":     event.Source,
+			"event_type": event.EventType,
+			"severity":   string(event.Severity),
+			"normalized": plainValue(normalized),
+		},
+	}
+}
+
+func (e *httpEnricher) Enrich(ctx context.Context, event *models.Event, normalized map[string]interface{}) (map[string]interface{}, error) {
+	vars := enrichmentVars(event, normalized)
+
+	var target strings.Builder
+	for _, part := range e.url {
+		if part.expr == nil {
+			target.WriteString(part.literal)
+			continue
+		}
+		value, err := part.expr.evaluate(vars)
+		if err != nil {
+			return nil, fmt.Errorf("url: %w", err)
+		}
+		if value == nil {
+			return nil, nil // the event lacks a field the URL needs
+		}
+		target.WriteString(url.PathEscape(fmt.Sprintf("%v", value)))
+	}
+
+	var body io.Reader
+	if e.body != nil {
+		value, err := evaluateTransform(e.body, vars)
+		if err != nil {
+			return nil, fmt.Errorf("body: %w", err)
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("body: %w", err)
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, e.method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range e.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil // nothing known about the value
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("request returned %s", resp.Status)
+	}
+
+	var result interface{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxEnrichmentResponse)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(e.fields) == 0 {
+		return map[string]interface{}{e.target: result}, nil
+	}
+	response, _ := result.(map[string]interface{})
+	fields := make(map[string]interface{}, len(e.fields))
+	for field, path := range e.fields {
+		if value := getNestedField(response, path); value != nil {
+			fields[field] = value
+		}
+	}
+	return fields, nil
+}
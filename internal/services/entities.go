@@ -0,0 +1,180 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// Limits on the lists in an entity profile
+const (
+	maxProfileEvents    = 50
+	maxProfileIncidents = 50
+	maxProfileRelated   = 50
+)
+
+// ErrUnknownEntityType is returned for entity types other than user, host
+// and ip
+var ErrUnknownEntityType = errors.New("entity type must be user, host or ip")
+
+// entityColumns are the indexed event columns holding each entity type
+var entityColumns = map[string]string{
+	"user": "user_name",
+	"host": "host_name",
+	"ip":   "src_ip",
+}
+
+// EntityProfile is everything known about a user, host or IP address
+type EntityProfile struct {
+	EntityType string     `json:"entity_type"`
+	Value      string     `json:"value"`
+	FirstSeen  *time.Time `json:"first_seen"`
+	LastSeen   *time.Time `json:"last_seen"`
+	EventCount int64      `json:"event_count"`
+	// EventTypes counts the entity's events by type
+	EventTypes   map[string]int64      `json:"event_types"`
+	RecentEvents []models.EventSummary `json:"recent_events"`
+	Incidents    []models.Incident     `json:"incidents"`
+	// Related are the other entities seen in the same events
+	Related    map[string][]string    `json:"related"`
+	Watchlists []string               `json:"watchlists"`
+	Risk       *EntityRiskScore       `json:"risk"`
+	Enrichment map[string]interface{} `json:"enrichment"`
+}
+
+// EntityProfiler assembles entity profiles
+type EntityProfiler struct {
+	db        *gorm.DB
+	detection *DetectionEngine
+	risk      *RiskScorer
+	geo       *GeoIPDatabase
+}
+
+// NewEntityProfiler creates an entity profiler
+func NewEntityProfiler(db *gorm.DB, detection *DetectionEngine, risk *RiskScorer, geo *GeoIPDatabase) *EntityProfiler {
+	return &EntityProfiler{db: db, detection: detection, risk: risk, geo: geo}
+}
+
+// Profile returns the profile of an entity. Entities never seen in an event
+// get an empty profile rather than an error.
+func (p *EntityProfiler) Profile(entityType, value string) (*EntityProfile, error) {
+	column, ok := entityColumns[entityType]
+	if !ok {
+		return nil, ErrUnknownEntityType
+	}
+	profile := &EntityProfile{
+		EntityType:   entityType,
+		Value:        value,
+		EventTypes:   map[string]int64{},
+		RecentEvents: []models.EventSummary{},
+		Incidents:    []models.Incident{},
+		Related:      map[string][]string{},
+		Watchlists:   p.detection.WatchlistsContaining(value),
+		Enrichment:   map[string]interface{}{},
+	}
+
+	var counts []struct {
+		EventType string
+		Count     int64
+	}
+	if err := p.db.Model(&models.Event{}).Select("event_type, COUNT(*) AS count").
+		Where(column+" = ?", value).Group("event_type").Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count events: %w", err)
+	}
+	for _, c := range counts {
+		profile.EventTypes[c.EventType] = c.Count
+		profile.EventCount += c.Count
+	}
+
+	if profile.EventCount > 0 {
+		if err := p.db.Model(&models.Event{}).Select(models.EventSummaryColumns).
+			Where(column+" = ?", value).Order("timestamp DESC").Limit(maxProfileEvents).
+			Scan(&profile.RecentEvents).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch events: %w", err)
+		}
+		var first models.EventSummary
+		if err := p.db.Model(&models.Event{}).Select(models.EventSummaryColumns).
+			Where(column+" = ?", value).Order("timestamp ASC").Limit(1).Scan(&first).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch events: %w", err)
+		}
+		firstSeen, lastSeen := first.Timestamp, profile.RecentEvents[0].Timestamp
+		profile.FirstSeen, profile.LastSeen = &firstSeen, &lastSeen
+
+		if err := p.db.Raw(`SELECT DISTINCT i.* FROM incidents i, json_each(i.related_events) je
+			JOIN events e ON e.event_id = je.value
+			WHERE e.`+column+` = ? ORDER BY i.created_at DESC LIMIT ?`, value, maxProfileIncidents).
+			Scan(&profile.Incidents).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch incidents: %w", err)
+		}
+
+		for relatedType, relatedColumn := range entityColumns {
+			if relatedType == entityType {
+				continue
+			}
+			related := []string{}
+			if err := p.db.Model(&models.Event{}).Distinct(relatedColumn).
+				Where(column+" = ? AND "+relatedColumn+" IS NOT NULL", value).
+				Limit(maxProfileRelated).Pluck(relatedColumn, &related).Error; err != nil {
+				return nil, fmt.Errorf("failed to fetch related entities: %w", err)
+			}
+			sort.Strings(related)
+			profile.Related[relatedType] = related
+		}
+	}
+
+	risk, err := p.risk.Get(entityType, value)
+	switch {
+	case err == nil:
+		profile.Risk = &risk.EntityRiskScore
+	case err != gorm.ErrRecordNotFound:
+		return nil, err
+	}
+
+	if err := p.enrich(profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// enrich adds what lookups beyond the event store know about the entity:
+// the GeoIP location of an address and where a user last logged in from
+func (p *EntityProfiler) enrich(profile *EntityProfile) error {
+	switch profile.EntityType {
+	case "ip":
+		if location, ok := p.geo.Lookup(profile.Value); ok {
+			profile.Enrichment["geo"] = location
+		}
+	case "user":
+		var login models.LoginLocation
+		err := p.db.First(&login, "username = ? AND exercise = ?", profile.Value, false).Error
+		switch {
+		case err == nil:
+			profile.Enrichment["last_login"] = login
+		case err != gorm.ErrRecordNotFound:
+			return fmt.Errorf("failed to fetch last login: %w", err)
+		}
+	}
+	return nil
+}
+
+// WatchlistsContaining returns the IDs of the watchlists a value is on,
+// ignoring case
+func (de *DetectionEngine) WatchlistsContaining(value string) []string {
+	value = strings.ToLower(value)
+	de.mu.RLock()
+	defer de.mu.RUnlock()
+	names := []string{}
+	for name, entries := range de.watchlists {
+		if entries[value] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// ErasedValue replaces an erased identifier
+const ErasedValue = "[ERASED]"
+
+// minErasureLength keeps an erasure from matching half the database
+const minErasureLength = 4
+
+// erasureBatch is how many rows an erasure rewrites at a time
+const erasureBatch = 200
+
+// ErrErasureTooShort is returned for an identifier too short to erase safely
+var ErrErasureTooShort = fmt.Errorf("identifier must be at least %d characters", minErasureLength)
+
+// erasureColumns are the free-text columns an erasure rewrites, per table
+var erasureColumns = []struct {
+	model   interface{}
+	table   string
+	key     string
+	columns []string
+}{
+	{&models.Incident{}, "incidents", "incident_id", []string{"title", "description", "notes", "tags", "correlation_key"}},
+	{&models.IncidentDetection{}, "incident_detections", "incident_id", []string{"explanation"}},
+	{&models.IncidentComment{}, "incident_comments", "comment_id", []string{"body"}},
+	{&models.IncidentTask{}, "incident_tasks", "task_id", []string{"title"}},
+	{&models.IncidentHandoff{}, "incident_handoffs", "handoff_id", []string{"current_state", "pending_actions", "next_steps"}},
+	{&models.StakeholderUpdate{}, "stakeholder_updates", "update_id", []string{"body"}},
+	{&models.ActionLog{}, "action_logs", "action_id", []string{"parameters", "result", "error", "notes"}},
+	{&models.PlaybookRun{}, "playbook_runs", "run_id", []string{"inputs", "error"}},
+	{&models.DetectionTrace{}, "detection_traces", "id", []string{"trace"}},
+	// A campaign's group key names what its incidents share, such as
+	// "user:alice"; redacting it also stops new incidents joining on it
+	{&models.Campaign{}, "campaigns", "campaign_id", []string{"title", "description", "group_key"}},
+	{&models.NotificationDelivery{}, "notification_deliveries", "delivery_id", []string{"target", "message", "error"}},
+	{&models.OutboxMessage{}, "outbox_messages", "message_id", []string{"payload", "last_error"}},
+}
+
+// ErasureResult counts the rows an erasure rewrote and deleted, per table,
+// summed over the shared and tenant databases
+type ErasureResult struct {
+	Updated map[string]int64 `json:"updated"`
+	Deleted map[string]int64 `json:"deleted"`
+	// ColdStorageObjects are raw payloads in cold storage that were rewritten
+	ColdStorageObjects int64 `json:"cold_storage_objects"`
+}
+
+// EraseIdentifier redacts an identifier (an email, username, IP address...)
+// wherever it appears in stored events, incidents and their records, matched
+// case-insensitively, along with its masked forms when PII was scrubbed
+// before storage. Raw payloads moved to cold storage are fetched, rewritten
+// and stored back. Per-entity state keyed by it (risk scores, login
+// locations, first-seen values) is deleted. The shared database and every
+// tenant database are covered.
+func EraseIdentifier(ctx context.Context, db *gorm.DB, tenants *database.Tenants, cold *ColdStorage, pii *PIIScrubber, identifier string) (*ErasureResult, error) {
+	identifier = strings.TrimSpace(identifier)
+	if len(identifier) < minErasureLength {
+		return nil, ErrErasureTooShort
+	}
+	forms := append([]string{identifier}, pii.Masks(identifier)...)
+	quoted := make([]string, len(forms))
+	for i, form := range forms {
+		quoted[i] = regexp.QuoteMeta(form)
+	}
+	pattern := regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+	erase := func(text string) string { return pattern.ReplaceAllLiteralString(text, ErasedValue) }
+
+	result := &ErasureResult{Updated: make(map[string]int64), Deleted: make(map[string]int64)}
+	if err := eraseDatabase(ctx, db, cold, identifier, forms, erase, result); err != nil {
+		return nil, err
+	}
+	for _, tenant := range tenants.Names() {
+		if err := eraseDatabase(ctx, tenants.Lookup(tenant), cold, identifier, forms, erase, result); err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", tenant, err)
+		}
+	}
+	return result, nil
+}
+
+// eraseDatabase erases an identifier from one database, adding to result
+func eraseDatabase(ctx context.Context, db *gorm.DB, cold *ColdStorage, identifier string, forms []string, erase func(string) string, result *ErasureResult) error {
+	db = db.WithContext(ctx)
+
+	updated, err := eraseEvents(db, forms, erase)
+	if err != nil {
+		return err
+	}
+	result.Updated["events"] += updated
+	objects, err := eraseColdPayloads(ctx, db, cold, erase)
+	if err != nil {
+		return err
+	}
+	result.ColdStorageObjects += objects
+
+	for _, spec := range erasureColumns {
+		var conditions []string
+		var args []interface{}
+		for _, column := range spec.columns {
+			for _, form := range forms {
+				conditions = append(conditions, column+" LIKE ?")
+				args = append(args, "%"+form+"%")
+			}
+		}
+		var rows []map[string]interface{}
+		err := db.Model(spec.model).Select(append([]string{spec.key}, spec.columns...)).
+			Where(strings.Join(conditions, " OR "), args...).Find(&rows).Error
+		if err != nil {
+			return fmt.Errorf("failed to search %s: %w", spec.table, err)
+		}
+		for _, row := range rows {
+			changes := make(map[string]interface{})
+			for _, column := range spec.columns {
+				var text string
+				switch v := row[column].(type) {
+				case string:
+					text = v
+				case []byte:
+					text = string(v)
+				default:
+					continue
+				}
+				if erased := erase(text); erased != text {
+					changes[column] = erased
+				}
+			}
+			if len(changes) == 0 {
+				continue
+			}
+			if err := db.Model(spec.model).Where(spec.key+" = ?", row[spec.key]).Updates(changes).Error; err != nil {
+				return fmt.Errorf("failed to erase from %s: %w", spec.table, err)
+			}
+			result.Updated[spec.table]++
+		}
+	}
+
+	deletes := []struct {
+		model interface{}
+		table string
+		where string
+	}{
+		{&models.EntityRisk{}, "entity_risks", "LOWER(entity_value) = LOWER(?)"},
+		{&models.RiskContribution{}, "risk_contributions", "LOWER(entity_value) = LOWER(?)"},
+		{&models.LoginLocation{}, "login_locations", "LOWER(username) = LOWER(?)"},
+		{&models.SeenValue{}, "seen_values", "LOWER(entity) = LOWER(?) OR LOWER(value) = LOWER(?)"},
+	}
+	for _, spec := range deletes {
+		args := make([]interface{}, strings.Count(spec.where, "?"))
+		for i := range args {
+			args[i] = identifier
+		}
+		deleted := db.Where(spec.where, args...).Delete(spec.model)
+		if deleted.Error != nil {
+			return fmt.Errorf("failed to erase from %s: %w", spec.table, deleted.Error)
+		}
+		result.Deleted[spec.table] += deleted.RowsAffected
+	}
+	return nil
+}
+
+// eraseEvents rewrites the payloads of events mentioning any of forms.
+// Compressed payloads can't be searched in SQL, so they are all read.
+func eraseEvents(db *gorm.DB, forms []string, erase func(string) string) (updated int64, err error) {
+	var conditions []string
+	var args []interface{}
+	for _, form := range forms {
+		conditions = append(conditions, "normalized LIKE ?", "raw_data LIKE ?")
+		args = append(args, "%"+form+"%", "%"+form+"%")
+	}
+	conditions = append(conditions, "hex(substr(CAST(raw_data AS BLOB), 1, 1)) = '00'")
+	query := db.Where(strings.Join(conditions, " OR "), args...)
+
+	var events []models.Event
+	err = query.FindInBatches(&events, erasureBatch, func(tx *gorm.DB, _ int) error {
+		for i := range events {
+			event := &events[i]
+			raw, normalized := erase(event.RawData), erase(event.Normalized)
+			if raw == event.RawData && normalized == event.Normalized {
+				continue
+			}
+			event.RawData, event.Normalized = raw, normalized
+			if err := db.Model(event).Select("raw_data", "normalized").Updates(event).Error; err != nil {
+				return err
+			}
+			updated++
+		}
+		return nil
+	}).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, fmt.Errorf("failed to erase from events: %w", err)
+	}
+	return updated, nil
+}
+
+// eraseColdPayloads rewrites the offloaded raw payloads of a database's
+// events. Objects can't be searched in place, so each one is fetched.
+func eraseColdPayloads(ctx context.Context, db *gorm.DB, cold *ColdStorage, erase func(string) string) (rewritten int64, err error) {
+	var events []models.Event
+	err = db.Select("event_id", "raw_data_ref").Where("raw_data_ref IS NOT NULL AND raw_data_ref <> ''").
+		FindInBatches(&events, erasureBatch, func(tx *gorm.DB, _ int) error {
+			for _, event := range events {
+				changed, err := cold.ErasePayload(ctx, *event.RawDataRef, erase)
+				if err != nil {
+					return fmt.Errorf("event %s: %w", event.EventID, err)
+				}
+				if changed {
+					rewritten++
+				}
+			}
+			return nil
+		}).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, fmt.Errorf("failed to erase from cold storage: %w", err)
+	}
+	return rewritten, nil
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// purgeBatch is how many events a purge deletes per statement, and so per
+// audit log entry
+const purgeBatch = 500
+
+// TombstoneNormalized replaces the payload of a purged event an incident
+// still refers to
+const TombstoneNormalized = `{"purged":true}`
+
+// ErrInvalidPurgeFilter is returned for a filter that could select every
+// event or can't select any
+var ErrInvalidPurgeFilter = errors.New("invalid purge filter")
+
+// PurgeFilter selects events to delete, such as test data or a poisoned
+// ingest. At least one of the criteria besides the time range is required.
+type PurgeFilter struct {
+	From      *time.Time `json:"from"`
+	To        *time.Time `json:"to"`
+	Source    string     `json:"source"`
+	EventType string     `json:"event_type"`
+	RequestID string     `json:"request_id"`
+	EventIDs  []string   `json:"event_ids"`
+	// Detach deletes events incidents refer to as well, removing them from
+	// the incidents' related events; otherwise they are tombstoned
+	Detach bool `json:"detach"`
+	// DryRun counts the events without changing anything
+	DryRun bool `json:"dry_run"`
+}
+
+// PurgeResult counts what a purge did
+type PurgeResult struct {
+	Matched    int64 `json:"matched"`
+	Deleted    int64 `json:"deleted"`
+	Tombstoned int64 `json:"tombstoned"`
+	// ColdStorageDeleted are raw payloads removed from cold storage
+	ColdStorageDeleted int64 `json:"cold_storage_deleted"`
+	// Incidents are the incidents whose related events were detached
+	Incidents []string `json:"incidents,omitempty"`
+	DryRun    bool     `json:"dry_run,omitempty"`
+}
+
+// PurgeEvents deletes the events a filter selects from eventsDB, with the
+// detection traces recorded for them. Incidents live in db, the shared
+// database, which is eventsDB unless the events belong to a tenant.
+//
+// An event an incident refers to is kept as a tombstone, its payloads
+// cleared and its metadata left, so the incident's timeline still resolves;
+// with Detach it is removed from the incident's related events and deleted.
+// Playbook runs keep their record but lose the reference. Raw payloads in
+// cold storage are deleted once their events are deleted or tombstoned.
+// Deletes run through the audited tables, so the audit log records each
+// batch with the IDs it removed and the context's actor.
+func PurgeEvents(ctx context.Context, db, eventsDB *gorm.DB, cold *ColdStorage, filter PurgeFilter) (*PurgeResult, error) {
+	if filter.Source == "" && filter.EventType == "" && filter.RequestID == "" && len(filter.EventIDs) == 0 {
+		return nil, fmt.Errorf("%w: one of source, event_type, request_id or event_ids is required", ErrInvalidPurgeFilter)
+	}
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return nil, fmt.Errorf("%w: to is before from", ErrInvalidPurgeFilter)
+	}
+	db, eventsDB = db.WithContext(ctx), eventsDB.WithContext(ctx)
+
+	query := eventsDB.Model(&models.Event{})
+	if len(filter.EventIDs) > 0 {
+		query = query.Where("event_id IN ?", filter.EventIDs)
+	}
+	if filter.From != nil {
+		query = query.Where("timestamp >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("timestamp < ?", *filter.To)
+	}
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+	if filter.EventType != "" {
+		query = query.Where("event_type = ?", filter.EventType)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	// Tombstones already carry nothing to purge
+	query = query.Where("normalized <> ?", TombstoneNormalized)
+
+	var ids []string
+	if err := query.Order("timestamp").Pluck("event_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to find events to purge: %w", err)
+	}
+	result := &PurgeResult{Matched: int64(len(ids)), DryRun: filter.DryRun}
+	if filter.DryRun || len(ids) == 0 {
+		return result, nil
+	}
+
+	detached := make(map[string]bool)
+	for start := 0; start < len(ids); start += purgeBatch {
+		batch := ids[start:min(start+purgeBatch, len(ids))]
+		referenced, err := referencingIncidents(db, batch)
+		if err != nil {
+			return nil, err
+		}
+		// The keys are read first, since tombstoning clears them
+		var coldKeys []string
+		err = eventsDB.Model(&models.Event{}).Where("event_id IN ? AND raw_data_ref IS NOT NULL AND raw_data_ref <> ''", batch).
+			Pluck("raw_data_ref", &coldKeys).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to find cold storage payloads: %w", err)
+		}
+
+		remove := batch
+		if len(referenced) > 0 && filter.Detach {
+			if err := detachEvents(db, referenced, batch); err != nil {
+				return nil, err
+			}
+			for _, incident := range referenced {
+				detached[incident.IncidentID] = true
+			}
+		} else if len(referenced) > 0 {
+			kept := referencedEvents(referenced, batch)
+			keptIDs := make([]string, 0, len(kept))
+			for id := range kept {
+				keptIDs = append(keptIDs, id)
+			}
+			tombstoned := eventsDB.Model(&models.Event{}).Where("event_id IN ?", keptIDs).
+				Updates(map[string]interface{}{"raw_data": "", "raw_data_ref": nil, "normalized": TombstoneNormalized})
+			if tombstoned.Error != nil {
+				return nil, fmt.Errorf("failed to tombstone events: %w", tombstoned.Error)
+			}
+			result.Tombstoned += tombstoned.RowsAffected
+			remove = nil
+			for _, id := range batch {
+				if !kept[id] {
+					remove = append(remove, id)
+				}
+			}
+		}
+		if len(remove) > 0 {
+			err = eventsDB.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Where("event_id IN ?", remove).Delete(&models.DetectionTrace{}).Error; err != nil {
+					return err
+				}
+				deleted := tx.Where("event_id IN ?", remove).Delete(&models.Event{})
+				result.Deleted += deleted.RowsAffected
+				return deleted.Error
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to delete events: %w", err)
+			}
+			err = db.Model(&models.PlaybookRun{}).Where("event_id IN ?", remove).Update("event_id", nil).Error
+			if err != nil {
+				return nil, fmt.Errorf("failed to detach playbook runs: %w", err)
+			}
+		}
+
+		// Payloads go after their rows, so a failure leaves an orphaned
+		// object rather than an event pointing at nothing
+		if err := cold.DeletePayloads(ctx, coldKeys); err != nil {
+			return nil, err
+		}
+		result.ColdStorageDeleted += int64(len(coldKeys))
+	}
+
+	for id := range detached {
+		result.Incidents = append(result.Incidents, id)
+	}
+	sort.Strings(result.Incidents)
+	return result, nil
+}
+
+// referencingIncidents returns the incidents whose related events include
+// any of ids
+func referencingIncidents(db *gorm.DB, ids []string) ([]models.Incident, error) {
+	var incidents []models.Incident
+	err := db.Select("incident_id", "related_events").
+		Where("json_valid(related_events) AND EXISTS (SELECT 1 FROM json_each(incidents.related_events) WHERE json_each.value IN ?)", ids).
+		Find(&incidents).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find incidents referring to events: %w", err)
+	}
+	return incidents, nil
+}
+
+// referencedEvents returns which of ids the incidents refer to
+func referencedEvents(incidents []models.Incident, ids []string) map[string]bool {
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	referenced := make(map[string]bool)
+	for _, incident := range incidents {
+		for _, id := range decodeStringList(incident.RelatedEvents) {
+			if wanted[id] {
+				referenced[id] = true
+			}
+		}
+	}
+	return referenced
+}
+
+// detachEvents removes ids from the incidents' related events
+func detachEvents(db *gorm.DB, incidents []models.Incident, ids []string) error {
+	removed := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		removed[id] = true
+	}
+	for _, incident := range incidents {
+		var kept []string
+		for _, id := range decodeStringList(incident.RelatedEvents) {
+			if !removed[id] {
+				kept = append(kept, id)
+			}
+		}
+		related, err := json.Marshal(append([]string{}, kept...))
+		if err != nil {
+			return err
+		}
+		err = db.Model(&models.Incident{IncidentID: incident.IncidentID}).
+			Updates(map[string]interface{}{"related_events": string(related)}).Error
+		if err != nil {
+			return fmt.Errorf("failed to detach events from incident %s: %w", incident.IncidentID, err)
+		}
+	}
+	return nil
+}
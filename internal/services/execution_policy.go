@@ -0,0 +1,217 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// ExecutionPermission is the role needed to run a playbook or action, plus
+// principals granted it regardless of role
+type ExecutionPermission struct {
+	Role   string   `yaml:"role"`
+	Grants []string `yaml:"grants"`
+	// Approvals is how many distinct responders, other than the one who
+	// started the run, must approve the action, or the playbook's
+	// auto-remediation steps, before it executes; 0 runs them ungated
+	Approvals int `yaml:"approvals"`
+}
+
+// ExecutionPolicyFile is the YAML layout of the execution policy
+type ExecutionPolicyFile struct {
+	DefaultRole string                         `yaml:"default_role"`
+	Playbooks   map[string]ExecutionPermission `yaml:"playbooks"`
+	Actions     map[string]ExecutionPermission `yaml:"actions"`
+}
+
+// permission is a validated ExecutionPermission
+type permission struct {
+	role      Role
+	grants    map[string]bool
+	approvals int
+}
+
+// allows reports whether the principal meets the permission
+func (p permission) allows(principal Principal) bool {
+	return principal.Role.Rank() >= p.role.Rank() || p.grants[principal.Name]
+}
+
+// PermissionDeniedError explains why a principal may not run a playbook
+type PermissionDeniedError struct {
+	Principal Principal
+	Resource  string // "playbook:<id>" or "action:<type>"
+	Required  Role
+}
+
+func (e *PermissionDeniedError) Error() string {
+	return fmt.Sprintf("%s (%s) may not execute %s: requires role %s or an explicit grant",
+		e.Principal.Name, e.Principal.Role, e.Resource, e.Required)
+}
+
+// ExecutionPolicy decides who may run playbooks by hand. Runs started by
+// detection rules execute as the system and are not checked.
+type ExecutionPolicy struct {
+	db          *gorm.DB
+	defaultRole Role
+	playbooks   map[string]permission
+	actions     map[string]permission
+}
+
+// LoadExecutionPolicy reads an execution policy file. Without the file every
+// playbook and action needs the responder role.
+func LoadExecutionPolicy(db *gorm.DB, path string) (*ExecutionPolicy, error) {
+	policy := &ExecutionPolicy{
+		db:          db,
+		defaultRole: RoleResponder,
+		playbooks:   make(map[string]permission),
+		actions:     make(map[string]permission),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return policy, nil
+		}
+		return nil, fmt.Errorf("failed to read execution policy: %w", err)
+	}
+
+	var file ExecutionPolicyFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse execution policy: %w", err)
+	}
+
+	if file.DefaultRole != "" {
+		if policy.defaultRole, err = ParseRole(file.DefaultRole); err != nil {
+			return nil, fmt.Errorf("invalid execution policy default_role: %w", err)
+		}
+	}
+	for id, spec := range file.Playbooks {
+		if policy.playbooks[id], err = policy.parsePermission(spec); err != nil {
+			return nil, fmt.Errorf("invalid execution policy for playbook %s: %w", id, err)
+		}
+	}
+	for name, spec := range file.Actions {
+		if policy.actions[name], err = policy.parsePermission(spec); err != nil {
+			return nil, fmt.Errorf("invalid execution policy for action %s: %w", name, err)
+		}
+	}
+	return policy, nil
+}
+
+func (p *ExecutionPolicy) parsePermission(spec ExecutionPermission) (permission, error) {
+	if spec.Approvals < 0 {
+		return permission{}, fmt.Errorf("approvals must not be negative")
+	}
+	perm := permission{role: p.defaultRole, grants: make(map[string]bool, len(spec.Grants)), approvals: spec.Approvals}
+	if spec.Role != "" {
+		role, err := ParseRole(spec.Role)
+		if err != nil {
+			return perm, err
+		}
+		perm.role = role
+	}
+	for _, name := range spec.Grants {
+		perm.grants[name] = true
+	}
+	return perm, nil
+}
+
+// AuthorizePlaybook checks that the principal may run the playbook and every
+// action its automated steps use. Denials are logged and recorded as a
+// permission_denied action log entry.
+func (p *ExecutionPolicy) AuthorizePlaybook(principal Principal, playbook Playbook) error {
+	id := playbook.Playbook.ID
+	if err := p.check(principal, "playbook:"+id, p.playbookPermission(id)); err != nil {
+		p.recordDenial(id, err)
+		return err
+	}
+
+	seen := make(map[string]bool)
+	var actions []string
+	for _, step := range playbook.Playbook.Steps {
+		if step.Manual || step.Action == "" || seen[step.Action] {
+			continue
+		}
+		seen[step.Action] = true
+		actions = append(actions, step.Action)
+	}
+	sort.Strings(actions)
+
+	for _, action := range actions {
+		if err := p.check(principal, "action:"+action, p.actionPermission(action)); err != nil {
+			p.recordDenial(id, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// RequiredApprovals returns how many approvals a playbook step running the
+// action needs before it executes: the action's own requirement, or the
+// playbook's when the action is auto-remediation, whichever is higher
+func (p *ExecutionPolicy) RequiredApprovals(playbookID, actionType string, remediation bool) int {
+	if p == nil {
+		return 0
+	}
+	approvals := p.actionPermission(actionType).approvals
+	if remediation {
+		if playbook := p.playbookPermission(playbookID).approvals; playbook > approvals {
+			approvals = playbook
+		}
+	}
+	return approvals
+}
+
+func (p *ExecutionPolicy) playbookPermission(id string) permission {
+	if perm, ok := p.playbooks[id]; ok {
+		return perm
+	}
+	return permission{role: p.defaultRole}
+}
+
+func (p *ExecutionPolicy) actionPermission(name string) permission {
+	if perm, ok := p.actions[name]; ok {
+		return perm
+	}
+	return permission{role: p.defaultRole}
+}
+
+func (p *ExecutionPolicy) check(principal Principal, resource string, perm permission) *PermissionDeniedError {
+	if perm.allows(principal) {
+		return nil
+	}
+	return &PermissionDeniedError{Principal: principal, Resource: resource, Required: perm.role}
+}
+
+// recordDenial logs a refused execution
+func (p *ExecutionPolicy) recordDenial(playbookID string, denied *PermissionDeniedError) {
+	log.Printf("Permission denied: %v", denied)
+
+	params, _ := json.Marshal(map[string]interface{}{
+		"principal": denied.Principal.Name,
+		"role":      denied.Principal.Role,
+		"resource":  denied.Resource,
+		"required":  denied.Required,
+	})
+	message := denied.Error()
+	completed := time.Now().UTC()
+	entry := &models.ActionLog{
+		ActionType:  "permission_denied",
+		Status:      models.ActionFailed,
+		PlaybookID:  &playbookID,
+		Parameters:  string(params),
+		Error:       &message,
+		CompletedAt: &completed,
+	}
+	if err := p.db.Create(entry).Error; err != nil {
+		log.Printf("Failed to record permission denial: %v", err)
+	}
+}
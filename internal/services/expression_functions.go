@@ -0,0 +1,789 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exprFunction is a builtin expression function. Functions with lambda set
+// take a lambda as their last argument.
+type exprFunction struct {
+	minArgs, maxArgs int // maxArgs -1 is variadic
+	call             func(args []interface{}) (interface{}, error)
+	lambda           bool
+	callLambda       func(e *exprEnv, args []interface{}, fn *lambdaNode) (interface{}, error)
+}
+
+func (f exprFunction) arity() string {
+	switch {
+	case f.maxArgs < 0:
+		return fmt.Sprintf("at least %d", f.minArgs)
+	case f.minArgs == f.maxArgs:
+		return strconv.Itoa(f.minArgs)
+	default:
+		return fmt.Sprintf("%d to %d", f.minArgs, f.maxArgs)
+	}
+}
+
+// expressionFunctions are the functions expressions may call
+var expressionFunctions map[string]exprFunction
+
+func init() {
+	expressionFunctions = map[string]exprFunction{
+		// Collections
+		"len":     {minArgs: 1, maxArgs: 1, call: exprLen},
+		"keys":    {minArgs: 1, maxArgs: 1, call: exprKeys},
+		"values":  {minArgs: 1, maxArgs: 1, call: exprValues},
+		"has":     {minArgs: 2, maxArgs: 2, call: exprHas},
+		"first":   {minArgs: 1, maxArgs: 1, call: func(a []interface{}) (interface{}, error) { return exprIndexList(a[0], 0) }},
+		"last":    {minArgs: 1, maxArgs: 1, call: func(a []interface{}) (interface{}, error) { return exprIndexList(a[0], -1) }},
+		"slice":   {minArgs: 2, maxArgs: 3, call: exprSlice},
+		"unique":  {minArgs: 1, maxArgs: 1, call: exprUnique},
+		"sort":    {minArgs: 1, maxArgs: 1, call: exprSort},
+		"reverse": {minArgs: 1, maxArgs: 1, call: exprReverse},
+		"flatten": {minArgs: 1, maxArgs: 1, call: exprFlatten},
+		"sum":     {minArgs: 1, maxArgs: 1, call: exprSum},
+		"avg":     {minArgs: 1, maxArgs: 1, call: exprAvg},
+		"min":     {minArgs: 1, maxArgs: -1, call: func(a []interface{}) (interface{}, error) { return exprExtreme(a, -1) }},
+		"max":     {minArgs: 1, maxArgs: -1, call: func(a []interface{}) (interface{}, error) { return exprExtreme(a, 1) }},
+		"range":   {minArgs: 1, maxArgs: 2, call: exprRange},
+		"merge":   {minArgs: 1, maxArgs: -1, call: exprMerge},
+
+		// Lambdas
+		"filter":  {minArgs: 2, maxArgs: 2, lambda: true, callLambda: exprFilter},
+		"map":     {minArgs: 2, maxArgs: 2, lambda: true, callLambda: exprMap},
+		"any":     {minArgs: 2, maxArgs: 2, lambda: true, callLambda: exprAny},
+		"all":     {minArgs: 2, maxArgs: 2, lambda: true, callLambda: exprAll},
+		"count":   {minArgs: 2, maxArgs: 2, lambda: true, callLambda: exprCount},
+		"find":    {minArgs: 2, maxArgs: 2, lambda: true, callLambda: exprFind},
+		"groupBy": {minArgs: 2, maxArgs: 2, lambda: true, callLambda: exprGroupBy},
+		"sortBy":  {minArgs: 2, maxArgs: 2, lambda: true, callLambda: exprSortBy},
+
+		// Strings
+		"upper":      {minArgs: 1, maxArgs: 1, call: exprStringFunc(strings.ToUpper)},
+		"lower":      {minArgs: 1, maxArgs: 1, call: exprStringFunc(strings.ToLower)},
+		"trim":       {minArgs: 1, maxArgs: 1, call: exprStringFunc(strings.TrimSpace)},
+		"split":      {minArgs: 2, maxArgs: 2, call: exprSplit},
+		"join":       {minArgs: 1, maxArgs: 2, call: exprJoin},
+		"replace":    {minArgs: 3, maxArgs: 3, call: exprReplace},
+		"contains":   {minArgs: 2, maxArgs: 2, call: func(a []interface{}) (interface{}, error) { return exprContains(a[0], a[1]) }},
+		"startsWith": {minArgs: 2, maxArgs: 2, call: exprStringPredicate(strings.HasPrefix)},
+		"endsWith":   {minArgs: 2, maxArgs: 2, call: exprStringPredicate(strings.HasSuffix)},
+
+		// Conversion
+		"string":   {minArgs: 1, maxArgs: 1, call: func(a []interface{}) (interface{}, error) { return exprString(a[0]), nil }},
+		"number":   {minArgs: 1, maxArgs: 1, call: exprNumber},
+		"int":      {minArgs: 1, maxArgs: 1, call: exprInt},
+		"round":    {minArgs: 1, maxArgs: 2, call: exprRound},
+		"abs":      {minArgs: 1, maxArgs: 1, call: exprAbs},
+		"toJSON":   {minArgs: 1, maxArgs: 1, call: exprToJSON},
+		"fromJSON": {minArgs: 1, maxArgs: 1, call: exprFromJSON},
+		"default":  {minArgs: 2, maxArgs: 2, call: exprDefault},
+		"now":      {minArgs: 0, maxArgs: 0, call: func([]interface{}) (interface{}, error) { return time.Now().UTC().Format(time.RFC3339), nil }},
+	}
+}
+
+// plainValue converts a value to the JSON-like values expressions operate
+// on: numbers become float64, errors their message, and other Go types are
+// round-tripped through JSON
+func plainValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, bool, string, float64:
+		return v
+	case error:
+		return v.Error()
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = plainValue(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = plainValue(item)
+		}
+		return out
+	}
+	if f, ok := toFloat(value); ok {
+		return f
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return string(data)
+	}
+	return out
+}
+
+// exprType names a value's type for error messages
+func exprType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// exprString formats a value as a string; whole numbers have no decimals
+// and lists and objects are JSON
+func exprString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case []interface{}, map[string]interface{}:
+		data, _ := json.Marshal(t)
+		return string(data)
+	default:
+		return fmt.Sprintf("%v", t)
+	}
+}
+
+// exprEqual compares values structurally
+func exprEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// exprCompare orders two numbers or two strings
+func exprCompare(op string, left, right interface{}) (interface{}, error) {
+	var c int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return nil, fmt.Errorf("can't compare number with %s", exprType(right))
+		}
+		c = cmpFloat(l, r)
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("can't compare string with %s", exprType(right))
+		}
+		c = strings.Compare(l, r)
+	default:
+		return nil, fmt.Errorf("can't compare %s", exprType(left))
+	}
+	switch op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// exprContains reports whether a list holds an element, an object a key or
+// a string a substring
+func exprContains(container, item interface{}) (interface{}, error) {
+	switch c := container.(type) {
+	case nil:
+		return false, nil
+	case []interface{}:
+		for _, v := range c {
+			if exprEqual(v, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]interface{}:
+		_, ok := c[exprString(item)]
+		return ok, nil
+	case string:
+		return strings.Contains(c, exprString(item)), nil
+	}
+	return nil, fmt.Errorf("can't search %s", exprType(container))
+}
+
+func exprList(v interface{}) ([]interface{}, error) {
+	switch l := v.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		return l, nil
+	}
+	return nil, fmt.Errorf("expected a list, got %s", exprType(v))
+}
+
+func exprNumbers(v interface{}) ([]float64, error) {
+	list, err := exprList(v)
+	if err != nil {
+		return nil, err
+	}
+	nums := make([]float64, len(list))
+	for i, item := range list {
+		n, ok := item.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected numbers, got %s", exprType(item))
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
+
+func exprLen(a []interface{}) (interface{}, error) {
+	switch v := a[0].(type) {
+	case nil:
+		return 0.0, nil
+	case string:
+		return float64(len(v)), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	}
+	return nil, fmt.Errorf("can't take the length of %s", exprType(a[0]))
+}
+
+func exprKeys(a []interface{}) (interface{}, error) {
+	m, ok := a[0].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an object, got %s", exprType(a[0]))
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]interface{}, len(keys))
+	for i, k := range keys {
+		out[i] = k
+	}
+	return out, nil
+}
+
+func exprValues(a []interface{}) (interface{}, error) {
+	keys, err := exprKeys(a)
+	if err != nil {
+		return nil, err
+	}
+	m := a[0].(map[string]interface{})
+	out := make([]interface{}, 0, len(m))
+	for _, k := range keys.([]interface{}) {
+		out = append(out, m[k.(string)])
+	}
+	return out, nil
+}
+
+func exprHas(a []interface{}) (interface{}, error) {
+	m, ok := a[0].(map[string]interface{})
+	if !ok {
+		return false, nil
+	}
+	_, found := m[exprString(a[1])]
+	return found, nil
+}
+
+func exprIndexList(v interface{}, i int) (interface{}, error) {
+	list, err := exprList(v)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	if i < 0 {
+		i += len(list)
+	}
+	return list[i], nil
+}
+
+func exprSlice(a []interface{}) (interface{}, error) {
+	bound := func(v interface{}, n int) (int, error) {
+		f, ok := v.(float64)
+		if !ok {
+			return 0, fmt.Errorf("slice bounds must be numbers")
+		}
+		i := int(f)
+		if i < 0 {
+			i += n
+		}
+		return max(0, min(i, n)), nil
+	}
+	var n int
+	switch v := a[0].(type) {
+	case string:
+		n = len(v)
+	case []interface{}:
+		n = len(v)
+	case nil:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("can't slice %s", exprType(a[0]))
+	}
+	start, err := bound(a[1], n)
+	if err != nil {
+		return nil, err
+	}
+	end := n
+	if len(a) == 3 {
+		if end, err = bound(a[2], n); err != nil {
+			return nil, err
+		}
+	}
+	end = max(start, end)
+	if s, ok := a[0].(string); ok {
+		return s[start:end], nil
+	}
+	return append([]interface{}{}, a[0].([]interface{})[start:end]...), nil
+}
+
+func exprUnique(a []interface{}) (interface{}, error) {
+	list, err := exprList(a[0])
+	if err != nil {
+		return nil, err
+	}
+	out := []interface{}{}
+	seen := make(map[string]bool)
+	for _, item := range list {
+		key := exprType(item) + ":" + exprString(item)
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func exprSort(a []interface{}) (interface{}, error) {
+	list, err := exprList(a[0])
+	if err != nil {
+		return nil, err
+	}
+	out := append([]interface{}{}, list...)
+	return out, sortValues(out, func(i int) interface{} { return out[i] })
+}
+
+// sortValues sorts list in place by the keys, which must be all numbers or
+// all strings
+func sortValues(list []interface{}, key func(i int) interface{}) error {
+	keys := make([]interface{}, len(list))
+	for i := range list {
+		keys[i] = key(i)
+	}
+	var sortErr error
+	idx := make([]int, len(list))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		less, err := exprCompare("<", keys[idx[i]], keys[idx[j]])
+		if err != nil {
+			sortErr = err
+			return false
+		}
+		return less.(bool)
+	})
+	if sortErr != nil {
+		return sortErr
+	}
+	sorted := make([]interface{}, len(list))
+	for i, j := range idx {
+		sorted[i] = list[j]
+	}
+	copy(list, sorted)
+	return nil
+}
+
+func exprReverse(a []interface{}) (interface{}, error) {
+	list, err := exprList(a[0])
+	if err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, len(list))
+	for i, item := range list {
+		out[len(list)-1-i] = item
+	}
+	return out, nil
+}
+
+func exprFlatten(a []interface{}) (interface{}, error) {
+	list, err := exprList(a[0])
+	if err != nil {
+		return nil, err
+	}
+	out := []interface{}{}
+	for _, item := range list {
+		if inner, ok := item.([]interface{}); ok {
+			out = append(out, inner...)
+		} else {
+			out = append(out, item)
+		}
+		if len(out) > expressionMaxItems {
+			return nil, fmt.Errorf("list longer than %d items", expressionMaxItems)
+		}
+	}
+	return out, nil
+}
+
+func exprSum(a []interface{}) (interface{}, error) {
+	nums, err := exprNumbers(a[0])
+	if err != nil {
+		return nil, err
+	}
+	total := 0.0
+	for _, n := range nums {
+		total += n
+	}
+	return total, nil
+}
+
+func exprAvg(a []interface{}) (interface{}, error) {
+	nums, err := exprNumbers(a[0])
+	if err != nil || len(nums) == 0 {
+		return nil, err
+	}
+	total, _ := exprSum(a)
+	return total.(float64) / float64(len(nums)), nil
+}
+
+// exprExtreme returns the smallest (sign -1) or largest (sign 1) of a list
+// or of its arguments
+func exprExtreme(a []interface{}, sign int) (interface{}, error) {
+	values := a
+	if len(a) == 1 {
+		list, err := exprList(a[0])
+		if err != nil {
+			return nil, err
+		}
+		values = list
+	}
+	var best interface{}
+	for _, v := range values {
+		if best == nil {
+			best = v
+			continue
+		}
+		greater, err := exprCompare(">", v, best)
+		if err != nil {
+			return nil, err
+		}
+		if greater.(bool) == (sign > 0) && !exprEqual(v, best) {
+			best = v
+		}
+	}
+	return best, nil
+}
+
+func exprRange(a []interface{}) (interface{}, error) {
+	start, end := 0.0, 0.0
+	var ok bool
+	if len(a) == 1 {
+		end, ok = a[0].(float64)
+	} else {
+		start, ok = a[0].(float64)
+		if ok {
+			end, ok = a[1].(float64)
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("range bounds must be numbers")
+	}
+	if end-start > expressionMaxItems {
+		return nil, fmt.Errorf("list longer than %d items", expressionMaxItems)
+	}
+	out := []interface{}{}
+	for i := start; i < end; i++ {
+		out = append(out, i)
+	}
+	return out, nil
+}
+
+func exprMerge(a []interface{}) (interface{}, error) {
+	out := map[string]interface{}{}
+	for _, arg := range a {
+		if arg == nil {
+			continue
+		}
+		m, ok := arg.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected objects, got %s", exprType(arg))
+		}
+		for k, v := range m {
+			out[k] = v
+		}
+	}
+	return out, nil
+}
+
+// eachItem calls fn with each element of a list, charging the budget
+func eachItem(e *exprEnv, v interface{}, fn func(item interface{}) (bool, error)) error {
+	list, err := exprList(v)
+	if err != nil {
+		return err
+	}
+	for _, item := range list {
+		if err := e.spend(1); err != nil {
+			return err
+		}
+		more, err := fn(item)
+		if err != nil || !more {
+			return err
+		}
+	}
+	return nil
+}
+
+func exprFilter(e *exprEnv, args []interface{}, fn *lambdaNode) (interface{}, error) {
+	out := []interface{}{}
+	err := eachItem(e, args[0], func(item interface{}) (bool, error) {
+		keep, err := fn.call(e, item)
+		if isTruthy(keep) {
+			out = append(out, item)
+		}
+		return true, err
+	})
+	return out, err
+}
+
+func exprMap(e *exprEnv, args []interface{}, fn *lambdaNode) (interface{}, error) {
+	out := []interface{}{}
+	err := eachItem(e, args[0], func(item interface{}) (bool, error) {
+		value, err := fn.call(e, item)
+		out = append(out, value)
+		return true, err
+	})
+	return out, err
+}
+
+func exprAny(e *exprEnv, args []interface{}, fn *lambdaNode) (interface{}, error) {
+	found := false
+	err := eachItem(e, args[0], func(item interface{}) (bool, error) {
+		value, err := fn.call(e, item)
+		found = isTruthy(value)
+		return !found, err
+	})
+	return found, err
+}
+
+func exprAll(e *exprEnv, args []interface{}, fn *lambdaNode) (interface{}, error) {
+	all := true
+	err := eachItem(e, args[0], func(item interface{}) (bool, error) {
+		value, err := fn.call(e, item)
+		all = isTruthy(value)
+		return all, err
+	})
+	return all, err
+}
+
+func exprCount(e *exprEnv, args []interface{}, fn *lambdaNode) (interface{}, error) {
+	n := 0.0
+	err := eachItem(e, args[0], func(item interface{}) (bool, error) {
+		value, err := fn.call(e, item)
+		if isTruthy(value) {
+			n++
+		}
+		return true, err
+	})
+	return n, err
+}
+
+func exprFind(e *exprEnv, args []interface{}, fn *lambdaNode) (interface{}, error) {
+	var found interface{}
+	err := eachItem(e, args[0], func(item interface{}) (bool, error) {
+		value, err := fn.call(e, item)
+		if isTruthy(value) {
+			found = item
+			return false, err
+		}
+		return true, err
+	})
+	return found, err
+}
+
+func exprGroupBy(e *exprEnv, args []interface{}, fn *lambdaNode) (interface{}, error) {
+	out := map[string]interface{}{}
+	err := eachItem(e, args[0], func(item interface{}) (bool, error) {
+		key, err := fn.call(e, item)
+		k := exprString(key)
+		group, _ := out[k].([]interface{})
+		out[k] = append(group, item)
+		return true, err
+	})
+	return out, err
+}
+
+func exprSortBy(e *exprEnv, args []interface{}, fn *lambdaNode) (interface{}, error) {
+	list, err := exprList(args[0])
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]interface{}, len(list))
+	for i, item := range list {
+		if err := e.spend(1); err != nil {
+			return nil, err
+		}
+		if keys[i], err = fn.call(e, item); err != nil {
+			return nil, err
+		}
+	}
+	out := append([]interface{}{}, list...)
+	return out, sortValues(out, func(i int) interface{} { return keys[i] })
+}
+
+func exprStringFunc(fn func(string) string) func([]interface{}) (interface{}, error) {
+	return func(a []interface{}) (interface{}, error) {
+		return fn(exprString(a[0])), nil
+	}
+}
+
+func exprStringPredicate(fn func(s, affix string) bool) func([]interface{}) (interface{}, error) {
+	return func(a []interface{}) (interface{}, error) {
+		return fn(exprString(a[0]), exprString(a[1])), nil
+	}
+}
+
+func exprSplit(a []interface{}) (interface{}, error) {
+	parts := strings.Split(exprString(a[0]), exprString(a[1]))
+	if len(parts) > expressionMaxItems {
+		return nil, fmt.Errorf("list longer than %d items", expressionMaxItems)
+	}
+	out := make([]interface{}, len(parts))
+	for i, p := range parts {
+		out[i] = p
+	}
+	return out, nil
+}
+
+func exprJoin(a []interface{}) (interface{}, error) {
+	list, err := exprList(a[0])
+	if err != nil {
+		return nil, err
+	}
+	sep := ","
+	if len(a) == 2 {
+		sep = exprString(a[1])
+	}
+	// Size the result before building it: a long separator over many
+	// items would otherwise allocate far past the cap first
+	parts := make([]string, len(list))
+	size := 0
+	for i, item := range list {
+		parts[i] = exprString(item)
+		size += len(parts[i])
+	}
+	if len(parts) > 1 {
+		size += len(sep) * (len(parts) - 1)
+	}
+	if size > expressionMaxString {
+		return nil, fmt.Errorf("string longer than %d bytes", expressionMaxString)
+	}
+	return strings.Join(parts, sep), nil
+}
+
+func exprReplace(a []interface{}) (interface{}, error) {
+	s, old, replacement := exprString(a[0]), exprString(a[1]), exprString(a[2])
+	// As with join, check the result's length before allocating it
+	if grow := len(replacement) - len(old); grow > 0 {
+		if n := strings.Count(s, old); n > (expressionMaxString-len(s))/grow {
+			return nil, fmt.Errorf("string longer than %d bytes", expressionMaxString)
+		}
+	}
+	return strings.ReplaceAll(s, old, replacement), nil
+}
+
+func exprNumber(a []interface{}) (interface{}, error) {
+	switch v := a[0].(type) {
+	case float64:
+		return v, nil
+	case bool:
+		if v {
+			return 1.0, nil
+		}
+		return 0.0, nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", v)
+		}
+		return f, nil
+	}
+	return nil, fmt.Errorf("can't convert %s to a number", exprType(a[0]))
+}
+
+func exprInt(a []interface{}) (interface{}, error) {
+	n, err := exprNumber(a)
+	if err != nil {
+		return nil, err
+	}
+	return math.Trunc(n.(float64)), nil
+}
+
+func exprRound(a []interface{}) (interface{}, error) {
+	n, ok := a[0].(float64)
+	if !ok {
+		return nil, fmt.Errorf("expected a number, got %s", exprType(a[0]))
+	}
+	places := 0.0
+	if len(a) == 2 {
+		if places, ok = a[1].(float64); !ok {
+			return nil, fmt.Errorf("places must be a number")
+		}
+	}
+	scale := math.Pow(10, places)
+	return math.Round(n*scale) / scale, nil
+}
+
+func exprAbs(a []interface{}) (interface{}, error) {
+	n, ok := a[0].(float64)
+	if !ok {
+		return nil, fmt.Errorf("expected a number, got %s", exprType(a[0]))
+	}
+	return math.Abs(n), nil
+}
+
+func exprToJSON(a []interface{}) (interface{}, error) {
+	data, err := json.Marshal(a[0])
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func exprFromJSON(a []interface{}) (interface{}, error) {
+	var out interface{}
+	if err := json.Unmarshal([]byte(exprString(a[0])), &out); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return out, nil
+}
+
+func exprDefault(a []interface{}) (interface{}, error) {
+	if a[0] == nil || a[0] == "" {
+		return a[1], nil
+	}
+	return a[0], nil
+}
@@ -0,0 +1,737 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expressions are a small, side-effect-free language for reshaping data in
+// playbooks: literals (numbers, 'strings', true/false/null, [lists] and
+// {key: value} objects), variable paths (steps.lookup.output.hosts[0].ip),
+// arithmetic, comparisons, && || !, cond ? a : b, `in`, and the functions in
+// expressionFunctions, some of which take lambdas (h => h.risk > 70).
+// Evaluation is bounded by expressionBudget operations and
+// expressionMaxItems elements per collection, so a transform can't hang or
+// exhaust memory.
+//
+// The interpreter is our own rather than expr or goja: neither is in the
+// module graph, and both would need the budget and size limits bolted on from
+// outside, where an engine's built-ins can still allocate before we see the
+// result. Here every node and function is ours to meter.
+
+// expressionBudget is the number of operations one evaluation may perform
+const expressionBudget = 200000
+
+// expressionMaxItems bounds the size of collections built
+const expressionMaxItems = 10000
+
+// expressionMaxString bounds the length of strings built
+const expressionMaxString = 1 << 20
+
+// exprNode is a parsed expression
+type exprNode interface {
+	eval(e *exprEnv) (interface{}, error)
+}
+
+// expression is a compiled expression
+type expression struct {
+	source string
+	root   exprNode
+}
+
+// compileExpression parses an expression
+func compileExpression(source string) (*expression, error) {
+	tokens, err := tokenizeExpression(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseExpression()
+	if err == nil && p.peek().kind != tokEOF {
+		err = fmt.Errorf("unexpected %q", p.peek().text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+	return &expression{source: source, root: root}, nil
+}
+
+// evaluate runs the expression against variables, which must hold only
+// JSON-like values (see plainValue)
+func (x *expression) evaluate(vars map[string]interface{}) (interface{}, error) {
+	env := &exprEnv{vars: vars, budget: expressionBudget}
+	value, err := x.root.eval(env)
+	if err != nil {
+		return nil, fmt.Errorf("expression %q: %w", x.source, err)
+	}
+	return value, nil
+}
+
+// exprEnv holds variables in scope and the remaining budget
+type exprEnv struct {
+	vars   map[string]interface{}
+	parent *exprEnv
+	budget int
+	root   *exprEnv
+}
+
+func (e *exprEnv) top() *exprEnv {
+	if e.root != nil {
+		return e.root
+	}
+	return e
+}
+
+// spend charges n operations against the evaluation budget
+func (e *exprEnv) spend(n int) error {
+	top := e.top()
+	top.budget -= n
+	if top.budget < 0 {
+		return fmt.Errorf("evaluation budget of %d operations exceeded", expressionBudget)
+	}
+	return nil
+}
+
+func (e *exprEnv) lookup(name string) (interface{}, bool) {
+	for env := e; env != nil; env = env.parent {
+		if value, ok := env.vars[name]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// with returns a child scope binding name to value
+func (e *exprEnv) with(name string, value interface{}) *exprEnv {
+	return &exprEnv{vars: map[string]interface{}{name: value}, parent: e, root: e.top()}
+}
+
+// Tokens
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type exprToken struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+// exprOperators are matched longest first
+var exprOperators = []string{"=>", "==", "!=", "<=", ">=", "&&", "||", "?.",
+	"+", "-", "*", "/", "%", "<", ">", "!", "?", ":", ".", ",", "(", ")", "[", "]", "{", "}"}
+
+func tokenizeExpression(s string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9') {
+				i++
+			}
+			if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+				i++
+				if i < len(s) && (s[i] == '+' || s[i] == '-') {
+					i++
+				}
+				for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+					i++
+				}
+			}
+			num, err := strconv.ParseFloat(s[start:i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("bad number %q at %d", s[start:i], start)
+			}
+			tokens = append(tokens, exprToken{kind: tokNumber, text: s[start:i], num: num, pos: start})
+		case c == '\'' || c == '"':
+			start := i
+			var b strings.Builder
+			i++
+			for ; i < len(s) && s[i] != c; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+					switch s[i] {
+					case 'n':
+						b.WriteByte('\n')
+					case 't':
+						b.WriteByte('\t')
+					default:
+						b.WriteByte(s[i])
+					}
+					continue
+				}
+				b.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, fmt.Errorf("unterminated string at %d", start)
+			}
+			i++
+			tokens = append(tokens, exprToken{kind: tokString, text: b.String(), pos: start})
+		case c == '_' || unicode.IsLetter(rune(c)):
+			start := i
+			for i < len(s) && isIdentByte(s[i]) {
+				i++
+			}
+			// Member names may contain hyphens (steps.step-1.output), so
+			// subtraction after a member needs spaces
+			if n := len(tokens); n > 0 && tokens[n-1].kind == tokOp && (tokens[n-1].text == "." || tokens[n-1].text == "?.") {
+				for i+1 < len(s) && s[i] == '-' && isIdentByte(s[i+1]) {
+					i++
+					for i < len(s) && isIdentByte(s[i]) {
+						i++
+					}
+				}
+			}
+			tokens = append(tokens, exprToken{kind: tokIdent, text: s[start:i], pos: start})
+		default:
+			matched := false
+			for _, op := range exprOperators {
+				if strings.HasPrefix(s[i:], op) {
+					tokens = append(tokens, exprToken{kind: tokOp, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+			}
+		}
+	}
+	return append(tokens, exprToken{kind: tokEOF, pos: len(s)}), nil
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// Parser
+
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+func (p *exprParser) peek() exprToken { return p.tokens[p.pos] }
+
+func (p *exprParser) next() exprToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *exprParser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op || t.kind == tokIdent && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expect(op string) error {
+	if !p.accept(op) {
+		t := p.peek()
+		if t.kind == tokEOF {
+			return fmt.Errorf("expected %q at end", op)
+		}
+		return fmt.Errorf("expected %q at %d, found %q", op, t.pos, t.text)
+	}
+	return nil
+}
+
+func (p *exprParser) parseExpression() (exprNode, error) {
+	// A lambda: name => body
+	if t := p.peek(); t.kind == tokIdent && p.tokens[p.pos+1].text == "=>" {
+		p.pos += 2
+		body, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		return &lambdaNode{param: t.text, body: body}, nil
+	}
+	return p.parseTernary()
+}
+
+func (p *exprParser) parseTernary() (exprNode, error) {
+	cond, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("?") {
+		return cond, nil
+	}
+	then, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	return &ternaryNode{cond: cond, then: then, otherwise: otherwise}, nil
+}
+
+// binaryLevels lists binary operators from lowest to highest precedence
+var binaryLevels = [][]string{
+	{"||", "or"},
+	{"&&", "and"},
+	{"==", "!="},
+	{"<", "<=", ">", ">=", "in"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *exprParser) parseBinary(level int) (exprNode, error) {
+	if level == len(binaryLevels) {
+		return p.parseUnary()
+	}
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		op := ""
+		for _, candidate := range binaryLevels[level] {
+			if (t.kind == tokOp || t.kind == tokIdent) && t.text == candidate {
+				op = candidate
+			}
+		}
+		if op == "" {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "or":
+			op = "||"
+		case "and":
+			op = "&&"
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.accept("!") || p.accept("not") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	if p.accept("-") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{op: "-", left: &literalNode{value: 0.0}, right: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *exprParser) parsePostfix() (exprNode, error) {
+	node, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept(".") || p.accept("?."):
+			t := p.next()
+			if t.kind != tokIdent {
+				return nil, fmt.Errorf("expected a name after '.' at %d", t.pos)
+			}
+			node = &indexNode{target: node, index: &literalNode{value: t.text}}
+		case p.accept("["):
+			index, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			node = &indexNode{target: node, index: index}
+		default:
+			return node, nil
+		}
+	}
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		return &literalNode{value: t.num}, nil
+	case tokString:
+		return &literalNode{value: t.text}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null", "nil":
+			return &literalNode{value: nil}, nil
+		}
+		if p.accept("(") {
+			fn, ok := expressionFunctions[t.text]
+			if !ok {
+				return nil, fmt.Errorf("unknown function %s at %d", t.text, t.pos)
+			}
+			var args []exprNode
+			for !p.accept(")") {
+				if len(args) > 0 {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+				arg, err := p.parseExpression()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+			}
+			if len(args) < fn.minArgs || fn.maxArgs >= 0 && len(args) > fn.maxArgs {
+				return nil, fmt.Errorf("%s takes %s arguments, got %d", t.text, fn.arity(), len(args))
+			}
+			return &callNode{name: t.text, fn: fn, args: args}, nil
+		}
+		return &variableNode{name: t.text}, nil
+	case tokOp:
+		switch t.text {
+		case "(":
+			node, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			return node, p.expect(")")
+		case "[":
+			list := &listNode{}
+			for !p.accept("]") {
+				if len(list.items) > 0 {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+					if p.accept("]") {
+						break
+					}
+				}
+				item, err := p.parseExpression()
+				if err != nil {
+					return nil, err
+				}
+				list.items = append(list.items, item)
+			}
+			return list, nil
+		case "{":
+			object := &objectNode{}
+			for !p.accept("}") {
+				if len(object.keys) > 0 {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+					if p.accept("}") {
+						break
+					}
+				}
+				key := p.next()
+				if key.kind != tokIdent && key.kind != tokString {
+					return nil, fmt.Errorf("expected an object key at %d", key.pos)
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				value, err := p.parseExpression()
+				if err != nil {
+					return nil, err
+				}
+				object.keys = append(object.keys, key.text)
+				object.values = append(object.values, value)
+			}
+			return object, nil
+		}
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+}
+
+// Nodes
+
+type literalNode struct{ value interface{} }
+
+func (n *literalNode) eval(e *exprEnv) (interface{}, error) {
+	return n.value, e.spend(1)
+}
+
+type variableNode struct{ name string }
+
+func (n *variableNode) eval(e *exprEnv) (interface{}, error) {
+	if err := e.spend(1); err != nil {
+		return nil, err
+	}
+	value, ok := e.lookup(n.name)
+	if !ok {
+		return nil, fmt.Errorf("unknown variable %s", n.name)
+	}
+	return value, nil
+}
+
+type indexNode struct{ target, index exprNode }
+
+// eval reads a key or element; missing keys, out-of-range indexes and
+// indexing null give null
+func (n *indexNode) eval(e *exprEnv) (interface{}, error) {
+	target, err := n.target.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	index, err := n.index.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	switch t := target.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		return t[exprString(index)], nil
+	case []interface{}:
+		i, ok := index.(float64)
+		if !ok {
+			return nil, fmt.Errorf("list index must be a number, got %s", exprType(index))
+		}
+		if i < 0 {
+			i += float64(len(t))
+		}
+		if i < 0 || int(i) >= len(t) {
+			return nil, nil
+		}
+		return t[int(i)], nil
+	case string:
+		i, ok := index.(float64)
+		if !ok || i < 0 || int(i) >= len(t) {
+			return nil, nil
+		}
+		return string(t[int(i)]), nil
+	default:
+		return nil, fmt.Errorf("can't index %s", exprType(target))
+	}
+}
+
+type listNode struct{ items []exprNode }
+
+func (n *listNode) eval(e *exprEnv) (interface{}, error) {
+	list := make([]interface{}, 0, len(n.items))
+	for _, item := range n.items {
+		value, err := item.eval(e)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+type objectNode struct {
+	keys   []string
+	values []exprNode
+}
+
+func (n *objectNode) eval(e *exprEnv) (interface{}, error) {
+	object := make(map[string]interface{}, len(n.keys))
+	for i, key := range n.keys {
+		value, err := n.values[i].eval(e)
+		if err != nil {
+			return nil, err
+		}
+		object[key] = value
+	}
+	return object, nil
+}
+
+type notNode struct{ operand exprNode }
+
+func (n *notNode) eval(e *exprEnv) (interface{}, error) {
+	value, err := n.operand.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	return !isTruthy(value), nil
+}
+
+type ternaryNode struct{ cond, then, otherwise exprNode }
+
+func (n *ternaryNode) eval(e *exprEnv) (interface{}, error) {
+	cond, err := n.cond.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	if isTruthy(cond) {
+		return n.then.eval(e)
+	}
+	return n.otherwise.eval(e)
+}
+
+type lambdaNode struct {
+	param string
+	body  exprNode
+}
+
+func (n *lambdaNode) eval(e *exprEnv) (interface{}, error) {
+	return nil, fmt.Errorf("a lambda (%s => ...) can only be passed to a function", n.param)
+}
+
+// call evaluates the lambda's body with its parameter bound to value
+func (n *lambdaNode) call(e *exprEnv, value interface{}) (interface{}, error) {
+	return n.body.eval(e.with(n.param, value))
+}
+
+type binaryNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n *binaryNode) eval(e *exprEnv) (interface{}, error) {
+	left, err := n.left.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	// Short-circuit logic returns the deciding operand, so || doubles as a
+	// default: a.b || 'unknown'
+	switch n.op {
+	case "&&":
+		if !isTruthy(left) {
+			return left, nil
+		}
+		return n.right.eval(e)
+	case "||":
+		if isTruthy(left) {
+			return left, nil
+		}
+		return n.right.eval(e)
+	}
+
+	right, err := n.right.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.spend(1); err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return exprEqual(left, right), nil
+	case "!=":
+		return !exprEqual(left, right), nil
+	case "in":
+		return exprContains(right, left)
+	case "<", "<=", ">", ">=":
+		return exprCompare(n.op, left, right)
+	case "+":
+		switch l := left.(type) {
+		case string:
+			s := l + exprString(right)
+			if len(s) > expressionMaxString {
+				return nil, fmt.Errorf("string longer than %d bytes", expressionMaxString)
+			}
+			return s, nil
+		case []interface{}:
+			r, ok := right.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("can't add %s to a list", exprType(right))
+			}
+			if len(l)+len(r) > expressionMaxItems {
+				return nil, fmt.Errorf("list longer than %d items", expressionMaxItems)
+			}
+			return append(append([]interface{}{}, l...), r...), nil
+		}
+	}
+
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("%s needs numbers, got %s and %s", n.op, exprType(left), exprType(right))
+	}
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	case "%":
+		if int64(r) == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return float64(int64(l) % int64(r)), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", n.op)
+}
+
+type callNode struct {
+	name string
+	fn   exprFunction
+	args []exprNode
+}
+
+func (n *callNode) eval(e *exprEnv) (interface{}, error) {
+	if err := e.spend(1); err != nil {
+		return nil, err
+	}
+	args := make([]interface{}, len(n.args))
+	var lambda *lambdaNode
+	for i, arg := range n.args {
+		if l, ok := arg.(*lambdaNode); ok {
+			if !n.fn.lambda || i != len(n.args)-1 {
+				return nil, fmt.Errorf("%s doesn't take a lambda", n.name)
+			}
+			lambda = l
+			continue
+		}
+		value, err := arg.eval(e)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	if n.fn.lambda {
+		if lambda == nil {
+			return nil, fmt.Errorf("%s needs a lambda such as x => x.field as its last argument", n.name)
+		}
+		return n.fn.callLambda(e, args[:len(args)-1], lambda)
+	}
+	value, err := n.fn.call(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return value, nil
+}
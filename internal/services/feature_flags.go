@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// Feature flags gating subsystems that act without a human in the loop
+const (
+	// FlagAutoRemediation lets detection rules run playbooks
+	FlagAutoRemediation = "auto_remediation"
+	// FlagAutoClose lets the stale policy and resolved upstream alerts
+	// resolve incidents
+	FlagAutoClose = "auto_close"
+	// FlagAITriage gates automated triage; no subsystem reads it yet
+	FlagAITriage = "ai_triage"
+	// FlagContainmentPause turns playbook actions with external side
+	// effects, other than notifications, into dry runs
+	FlagContainmentPause = "containment_pause"
+)
+
+// featureFlagDefaults are the built-in values of the known flags, used when
+// neither FEATURE_FLAGS nor an override sets one
+var featureFlagDefaults = map[string]bool{
+	FlagAutoRemediation:  true,
+	FlagAutoClose:        true,
+	FlagAITriage:         false,
+	FlagContainmentPause: false,
+}
+
+// FeatureFlagState is a flag's effective value and where it came from
+type FeatureFlagState struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	// Source is "default", "config" or "override"
+	Source    string     `json:"source"`
+	Reason    string     `json:"reason,omitempty"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// UnknownFeatureFlagError is returned for a flag name that isn't defined
+type UnknownFeatureFlagError struct {
+	Name string
+}
+
+func (e *UnknownFeatureFlagError) Error() string {
+	return fmt.Sprintf("unknown feature flag %q", e.Name)
+}
+
+// ParseFeatureFlags parses a flag list such as
+// "auto_remediation=false,ai_triage=true"
+func ParseFeatureFlags(spec string) (map[string]bool, error) {
+	flags := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid feature flag %q: expected name=true|false", entry)
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, known := featureFlagDefaults[name]; !known {
+			return nil, &UnknownFeatureFlagError{Name: name}
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid feature flag %q: expected name=true|false", entry)
+		}
+		flags[name] = enabled
+	}
+	return flags, nil
+}
+
+// FeatureFlags resolves flags from, in order of precedence, a runtime
+// override stored in the database, the FEATURE_FLAGS config and the
+// built-in default. Overrides are read on every check so a toggle takes
+// effect immediately on all instances.
+type FeatureFlags struct {
+	db *gorm.DB
+
+	mu     sync.RWMutex
+	config map[string]bool
+}
+
+// NewFeatureFlags creates a flag set with the configured values
+func NewFeatureFlags(db *gorm.DB, config map[string]bool) *FeatureFlags {
+	f := &FeatureFlags{db: db}
+	f.SetConfig(config)
+	return f
+}
+
+// SetConfig replaces the configured values
+func (f *FeatureFlags) SetConfig(config map[string]bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.config = config
+}
+
+// Enabled reports whether a flag is on. A nil flag set, or a failed
+// override lookup, falls back to the configured or built-in value.
+func (f *FeatureFlags) Enabled(name string) bool {
+	if f == nil {
+		return featureFlagDefaults[name]
+	}
+
+	var override models.FeatureFlag
+	err := f.db.Where("name = ?", name).Limit(1).Find(&override).Error
+	if err != nil {
+		log.Printf("Failed to read feature flag %s, using configured value: %v", name, err)
+	} else if override.Name != "" {
+		return override.Enabled
+	}
+	return f.configured(name).Enabled
+}
+
+// configured is a flag's value without overrides
+func (f *FeatureFlags) configured(name string) FeatureFlagState {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if enabled, ok := f.config[name]; ok {
+		return FeatureFlagState{Name: name, Enabled: enabled, Source: "config"}
+	}
+	return FeatureFlagState{Name: name, Enabled: featureFlagDefaults[name], Source: "default"}
+}
+
+// List returns every known flag's effective state, sorted by name
+func (f *FeatureFlags) List() ([]FeatureFlagState, error) {
+	var overrides []models.FeatureFlag
+	if err := f.db.Find(&overrides).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch feature flags: %w", err)
+	}
+	byName := make(map[string]models.FeatureFlag, len(overrides))
+	for _, o := range overrides {
+		byName[o.Name] = o
+	}
+
+	states := make([]FeatureFlagState, 0, len(featureFlagDefaults))
+	for name := range featureFlagDefaults {
+		if o, ok := byName[name]; ok {
+			states = append(states, overrideState(o))
+			continue
+		}
+		states = append(states, f.configured(name))
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states, nil
+}
+
+// Set stores an override for a flag
+func (f *FeatureFlags) Set(ctx context.Context, name string, enabled bool, actor, reason string) (*FeatureFlagState, error) {
+	if _, known := featureFlagDefaults[name]; !known {
+		return nil, &UnknownFeatureFlagError{Name: name}
+	}
+
+	override := models.FeatureFlag{Name: name, Enabled: enabled, Reason: reason, UpdatedBy: actor}
+	if err := f.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "reason", "updated_by", "updated_at"}),
+	}).Create(&override).Error; err != nil {
+		return nil, fmt.Errorf("failed to save feature flag: %w", err)
+	}
+
+	log.Printf("Feature flag %s set to %t by %s: %s", name, enabled, actor, reason)
+	state := overrideState(override)
+	return &state, nil
+}
+
+// Clear removes a flag's override, returning it to its configured value
+func (f *FeatureFlags) Clear(ctx context.Context, name, actor string) (*FeatureFlagState, error) {
+	if _, known := featureFlagDefaults[name]; !known {
+		return nil, &UnknownFeatureFlagError{Name: name}
+	}
+
+	if err := f.db.WithContext(ctx).Delete(&models.FeatureFlag{Name: name}).Error; err != nil {
+		return nil, fmt.Errorf("failed to clear feature flag: %w", err)
+	}
+
+	log.Printf("Feature flag %s override cleared by %s", name, actor)
+	state := f.configured(name)
+	return &state, nil
+}
+
+func overrideState(o models.FeatureFlag) FeatureFlagState {
+	updatedAt := o.UpdatedAt
+	return FeatureFlagState{
+		Name:      o.Name,
+		Enabled:   o.Enabled,
+		Source:    "override",
+		Reason:    o.Reason,
+		UpdatedBy: o.UpdatedBy,
+		UpdatedAt: &updatedAt,
+	}
+}
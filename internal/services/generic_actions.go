@@ -2,12 +2,13 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
-	"os/exec"
+	"os"
 	"strings"
 	"time"
 
@@ -95,12 +96,40 @@ func (a *HTTPRequestAction) Execute(params map[string]interface{}) (interface{},
 	}, nil
 }
 
+func (a *HTTPRequestAction) Describe() ActionDescriptor {
+	return ActionDescriptor{
+		Name:        "http_request",
+		Description: "Make an HTTP request to any API",
+		Parameters: []ActionParameter{
+			{Name: "url", Type: "string", Required: true, Description: "Request URL"},
+			{Name: "method", Type: "string", Default: "GET", Description: "HTTP method"},
+			{Name: "headers", Type: "object", Description: "Request headers"},
+			{Name: "body", Type: "any", Description: "Request body, sent as JSON"},
+			{Name: "timeout", Type: "integer", Default: 30, Description: "Timeout in seconds"},
+		},
+		Outputs: []ActionOutput{
+			{Name: "status_code", Type: "integer", Required: true, Description: "HTTP status code"},
+			{Name: "success", Type: "boolean", Required: true, Description: "Whether the status was 2xx"},
+			{Name: "headers", Type: "object", Description: "Response headers"},
+			{Name: "body", Type: "any", Description: "Response body, parsed when JSON"},
+		},
+		SideEffect:     SideEffectExternal,
+		ConcurrencyKey: "url",
+	}
+}
+
 // ShellScriptAction executes arbitrary shell scripts/commands
 type ShellScriptAction struct {
 	db *gorm.DB
 }
 
 func (a *ShellScriptAction) Execute(params map[string]interface{}) (interface{}, error) {
+	return a.ExecuteContext(context.Background(), params)
+}
+
+// ExecuteContext runs the script with the playbook context in IR_*
+// environment variables
+func (a *ShellScriptAction) ExecuteContext(ctx context.Context, params map[string]interface{}) (interface{}, error) {
 	script := getStringParam(params, "script", "")
 	shell := getStringParam(params, "shell", "/bin/bash")
 	timeout := getIntParam(params, "timeout", 300)
@@ -113,45 +142,39 @@ func (a *ShellScriptAction) Execute(params map[string]interface{}) (interface{},
 	log.Printf("[ACTION] [SHELL] Executing script (timeout: %ds)", timeout)
 	log.Printf("[ACTION] [SHELL] Script: %s", script)
 
-	// Create command
-	cmd := exec.Command(shell, "-c", script)
-	if workdir != "" {
-		cmd.Dir = workdir
+	env := stepRunEnv(ctx)
+	for name, value := range envParam(params) {
+		env[name] = value
 	}
 
-	// Set timeout
-	done := make(chan error, 1)
-	go func() {
-		done <- cmd.Run()
-	}()
-
-	// Capture output
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	// Wait with timeout
-	select {
-	case err := <-done:
-		exitCode := 0
-		if err != nil {
-			if exitErr, ok := err.(*exec.ExitError); ok {
-				exitCode = exitErr.ExitCode()
-			} else {
-				return nil, fmt.Errorf("script execution failed: %w", err)
-			}
-		}
-
-		return map[string]interface{}{
-			"exit_code": exitCode,
-			"stdout":    stdout.String(),
-			"stderr":    stderr.String(),
-			"success":   exitCode == 0,
-		}, nil
+	result, err := runScript(ctx, scriptCommand{
+		Path:        shell,
+		Args:        []string{"-c", script},
+		Dir:         workdir,
+		Env:         env,
+		Timeout:     time.Duration(timeout) * time.Second,
+		OutputLimit: getIntParam(params, "max_output_bytes", defaultScriptOutputLimit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("script execution failed: %w", err)
+	}
+	return result.toMap(), nil
+}
 
-	case <-time.After(time.Duration(timeout) * time.Second):
-		cmd.Process.Kill()
-		return nil, fmt.Errorf("script execution timed out after %d seconds", timeout)
+func (a *ShellScriptAction) Describe() ActionDescriptor {
+	return ActionDescriptor{
+		Name:        "shell_script",
+		Description: "Run a shell script on the server",
+		Parameters: []ActionParameter{
+			{Name: "script", Type: "string", Required: true, Description: "Script to run"},
+			{Name: "shell", Type: "string", Default: "/bin/bash", Description: "Shell binary"},
+			{Name: "timeout", Type: "integer", Default: 300, Description: "Timeout in seconds"},
+			{Name: "workdir", Type: "string", Description: "Working directory"},
+			{Name: "env", Type: "object", Description: "Extra environment variables"},
+			{Name: "max_output_bytes", Type: "integer", Default: defaultScriptOutputLimit, Description: "Bytes of stdout and stderr kept"},
+		},
+		Outputs:    scriptOutputs,
+		SideEffect: SideEffectExternal,
 	}
 }
 
@@ -225,46 +248,136 @@ func (a *WebhookAction) Execute(params map[string]interface{}) (interface{}, err
 	}, nil
 }
 
+func (a *WebhookAction) Describe() ActionDescriptor {
+	return ActionDescriptor{
+		Name:        "webhook",
+		Description: "Send a JSON payload to a webhook URL",
+		Parameters: []ActionParameter{
+			{Name: "url", Type: "string", Required: true, Description: "Webhook URL"},
+			{Name: "payload", Type: "any", Description: "Payload to send"},
+			{Name: "method", Type: "string", Default: "POST", Description: "HTTP method"},
+			{Name: "headers", Type: "object", Description: "Request headers"},
+		},
+		Outputs: []ActionOutput{
+			{Name: "status_code", Type: "integer", Required: true, Description: "HTTP status code"},
+			{Name: "success", Type: "boolean", Required: true, Description: "Whether the status was 2xx"},
+			{Name: "response", Type: "string", Description: "Response body"},
+		},
+		SideEffect:     SideEffectExternal,
+		ConcurrencyKey: "url",
+	}
+}
+
 // PythonScriptAction executes Python scripts (useful for complex integrations)
 type PythonScriptAction struct {
-	db *gorm.DB
+	venvs *VirtualenvCache
+}
+
+// NewPythonScriptAction creates a Python action that installs requirements
+// into virtualenvs cached by venvs
+func NewPythonScriptAction(venvs *VirtualenvCache) *PythonScriptAction {
+	return &PythonScriptAction{venvs: venvs}
 }
 
 func (a *PythonScriptAction) Execute(params map[string]interface{}) (interface{}, error) {
+	return a.ExecuteContext(context.Background(), params)
+}
+
+// ExecuteContext runs a script file or inline code, passing the playbook
+// context as JSON on stdin and in IR_* environment variables
+func (a *PythonScriptAction) ExecuteContext(ctx context.Context, params map[string]interface{}) (interface{}, error) {
 	script := getStringParam(params, "script", "")
+	code := getStringParam(params, "code", "")
 	pythonPath := getStringParam(params, "python", "python3")
-	args := params["args"]
+	timeout := getIntParam(params, "timeout", 300)
 
-	if script == "" {
-		return nil, fmt.Errorf("script parameter is required")
+	if (script == "") == (code == "") {
+		return nil, fmt.Errorf("exactly one of script or code is required")
 	}
 
-	// Build command
-	cmdArgs := []string{script}
-	if args != nil {
-		if argList, ok := args.([]interface{}); ok {
-			for _, arg := range argList {
-				cmdArgs = append(cmdArgs, fmt.Sprintf("%v", arg))
-			}
+	requirements := stringListParam(params["requirements"])
+	if len(requirements) > 0 {
+		installTimeout := time.Duration(getIntParam(params, "install_timeout", 600)) * time.Second
+		venvPython, err := a.venvs.Python(ctx, pythonPath, requirements, installTimeout)
+		if err != nil {
+			return nil, err
+		}
+		pythonPath = venvPython
+	}
+
+	if code != "" {
+		file, err := os.CreateTemp("", "ir-script-*.py")
+		if err != nil {
+			return nil, fmt.Errorf("failed to write inline script: %w", err)
+		}
+		defer os.Remove(file.Name())
+		_, err = file.WriteString(code)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
 		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to write inline script: %w", err)
+		}
+		script = file.Name()
 	}
 
+	// Build command
+	cmdArgs := append([]string{script}, stringListParam(params["args"])...)
+
 	log.Printf("[ACTION] [PYTHON] Executing: %s %s", pythonPath, strings.Join(cmdArgs, " "))
 
-	// Execute Python script
-	cmd := exec.Command(pythonPath, cmdArgs...)
-	output, err := cmd.CombinedOutput()
+	stdin, err := stepRunPayload(ctx, params["data"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode script input: %w", err)
+	}
+	env := stepRunEnv(ctx)
+	for name, value := range envParam(params) {
+		env[name] = value
+	}
 
-	exitCode := 0
+	result, err := runScript(ctx, scriptCommand{
+		Path:        pythonPath,
+		Args:        cmdArgs,
+		Dir:         getStringParam(params, "workdir", ""),
+		Env:         env,
+		Stdin:       stdin,
+		Timeout:     time.Duration(timeout) * time.Second,
+		OutputLimit: getIntParam(params, "max_output_bytes", defaultScriptOutputLimit),
+	})
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			exitCode = exitErr.ExitCode()
-		}
+		return nil, fmt.Errorf("script execution failed: %w", err)
+	}
+	return result.toMap(), nil
+}
+
+func (a *PythonScriptAction) Describe() ActionDescriptor {
+	return ActionDescriptor{
+		Name:        "python_script",
+		Description: "Run a Python script on the server",
+		Parameters: []ActionParameter{
+			{Name: "script", Type: "string", Description: "Path to the script; one of script or code is required"},
+			{Name: "code", Type: "string", Description: "Inline script body"},
+			{Name: "python", Type: "string", Default: "python3", Description: "Python interpreter"},
+			{Name: "args", Type: "array", Description: "Script arguments"},
+			{Name: "requirements", Type: "array", Description: "pip requirements installed into a cached virtualenv"},
+			{Name: "data", Type: "any", Description: "JSON passed to the script on stdin with the playbook context"},
+			{Name: "env", Type: "object", Description: "Extra environment variables"},
+			{Name: "workdir", Type: "string", Description: "Working directory"},
+			{Name: "timeout", Type: "integer", Default: 300, Description: "Timeout in seconds"},
+			{Name: "install_timeout", Type: "integer", Default: 600, Description: "Timeout in seconds for creating the virtualenv"},
+			{Name: "max_output_bytes", Type: "integer", Default: defaultScriptOutputLimit, Description: "Bytes of stdout and stderr kept"},
+		},
+		Outputs:    scriptOutputs,
+		SideEffect: SideEffectExternal,
 	}
+}
 
-	return map[string]interface{}{
-		"exit_code": exitCode,
-		"output":    string(output),
-		"success":   exitCode == 0,
-	}, nil
+// stringListParam reads a list parameter as strings
+func stringListParam(value interface{}) []string {
+	list, _ := value.([]interface{})
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		out = append(out, fmt.Sprintf("%v", item))
+	}
+	return out
 }
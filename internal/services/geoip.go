@@ -0,0 +1,141 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GeoLocation is where an IP address is located
+type GeoLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Country   string  `json:"country,omitempty"`
+	City      string  `json:"city,omitempty"`
+}
+
+// GeoIPDatabase maps IP addresses to locations. It is loaded from CSV files
+// with a header row naming the columns: network (a CIDR), latitude and
+// longitude, and optionally country (or country_iso_code) and city (or
+// city_name). Networks must not overlap. MaxMind's GeoLite2 City "Blocks" CSV
+// files load as they are.
+type GeoIPDatabase struct {
+	networks []geoNetwork // sorted by first address
+}
+
+type geoNetwork struct {
+	prefix   netip.Prefix
+	location GeoLocation
+}
+
+// LoadGeoIPDatabase loads and merges the given CSV files
+func LoadGeoIPDatabase(paths ...string) (*GeoIPDatabase, error) {
+	db := &GeoIPDatabase{}
+	for _, path := range paths {
+		if err := db.load(path); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	sort.Slice(db.networks, func(i, j int) bool {
+		return db.networks[i].prefix.Addr().Less(db.networks[j].prefix.Addr())
+	})
+	return db, nil
+}
+
+func (db *GeoIPDatabase) load(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	column := func(names ...string) int {
+		for _, name := range names {
+			if i, ok := columns[name]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+	network, lat, lon := column("network"), column("latitude"), column("longitude")
+	if network < 0 || lat < 0 || lon < 0 {
+		return fmt.Errorf("header needs network, latitude and longitude columns")
+	}
+	country, city := column("country", "country_iso_code"), column("city", "city_name")
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if record[lat] == "" || record[lon] == "" {
+			continue // networks known only by country
+		}
+		prefix, err := netip.ParsePrefix(record[network])
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		location := GeoLocation{}
+		if location.Latitude, err = strconv.ParseFloat(record[lat], 64); err != nil {
+			return fmt.Errorf("line %d: invalid latitude: %w", line, err)
+		}
+		if location.Longitude, err = strconv.ParseFloat(record[lon], 64); err != nil {
+			return fmt.Errorf("line %d: invalid longitude: %w", line, err)
+		}
+		if country >= 0 {
+			location.Country = record[country]
+		}
+		if city >= 0 {
+			location.City = record[city]
+		}
+		db.networks = append(db.networks, geoNetwork{prefix: prefix.Masked(), location: location})
+	}
+}
+
+// Len returns the number of networks loaded
+func (db *GeoIPDatabase) Len() int {
+	if db == nil {
+		return 0
+	}
+	return len(db.networks)
+}
+
+// Lookup returns the location of the network holding ip
+func (db *GeoIPDatabase) Lookup(ip string) (GeoLocation, bool) {
+	if db == nil {
+		return GeoLocation{}, false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return GeoLocation{}, false
+	}
+	addr = addr.Unmap()
+
+	// Networks don't overlap, so only the last one starting at or before
+	// addr can hold it
+	i := sort.Search(len(db.networks), func(i int) bool {
+		return addr.Less(db.networks[i].prefix.Addr())
+	})
+	if i > 0 && db.networks[i-1].prefix.Contains(addr) {
+		return db.networks[i-1].location, true
+	}
+	return GeoLocation{}, false
+}
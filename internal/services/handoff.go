@@ -0,0 +1,411 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+var (
+	// ErrHandoffIncidentNotFound is returned for a handoff of an unknown
+	// incident
+	ErrHandoffIncidentNotFound = errors.New("incident not found")
+	// ErrHandoffNoRecipient is returned when handing roles over without
+	// naming the incoming responder
+	ErrHandoffNoRecipient = errors.New("roles can only be handed to a named responder (to)")
+)
+
+// handoffLookback bounds the timeline a handoff is drafted from when the
+// incident has had no earlier handoff
+const handoffLookback = 12 * time.Hour
+
+// maxHandoffTimeline caps the timeline entries a draft is built from
+const maxHandoffTimeline = 100
+
+// draftedByTimeline marks handoffs drafted by the built-in summary
+const draftedByTimeline = "timeline"
+
+// HandoffRequest is an end-of-shift summary. Draft fills the fields left
+// empty from the incident's activity since the last handoff.
+type HandoffRequest struct {
+	To             string   `json:"to"`
+	CurrentState   string   `json:"current_state"`
+	PendingActions string   `json:"pending_actions"`
+	NextSteps      string   `json:"next_steps"`
+	Roles          []string `json:"roles"` // roles the sender hands to To
+	Draft          bool     `json:"draft"`
+}
+
+// HandoffDraft is a drafted end-of-shift summary
+type HandoffDraft struct {
+	CurrentState   string `json:"current_state"`
+	PendingActions string `json:"pending_actions"`
+	NextSteps      string `json:"next_steps"`
+	DraftedBy      string `json:"drafted_by"`
+}
+
+// HandoffContext is what a handoff is drafted from
+type HandoffContext struct {
+	Incident  *models.Incident
+	Since     time.Time
+	Timeline  []TimelineEntry
+	Tasks     []models.IncidentTask // open and in progress
+	Approvals []models.ActionApproval
+	Runs      []models.PlaybookRun // running
+	Roles     []models.IncidentRoleAssignment
+}
+
+// HandoffDrafter drafts a handoff summary, e.g. with an LLM
+type HandoffDrafter interface {
+	Draft(ctx context.Context, hc *HandoffContext) (*HandoffDraft, error)
+}
+
+// Handoffs record shift handoffs on incidents and notify the incoming
+// responder
+type Handoffs struct {
+	db      *gorm.DB
+	outbox  *Outbox
+	roles   *IncidentRoles
+	drafter HandoffDrafter
+}
+
+// NewHandoffs creates the handoff service. drafter may be nil, in which case
+// drafts are summarized from the timeline without an LLM.
+func NewHandoffs(db *gorm.DB, outbox *Outbox, roles *IncidentRoles, drafter HandoffDrafter) *Handoffs {
+	return &Handoffs{db: db, outbox: outbox, roles: roles, drafter: drafter}
+}
+
+// List returns an incident's handoffs, newest first
+func (h *Handoffs) List(incidentID string) ([]models.IncidentHandoff, error) {
+	handoffs := []models.IncidentHandoff{}
+	if err := h.db.Where("incident_id = ?", incidentID).Order("created_at DESC").Find(&handoffs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch handoffs: %w", err)
+	}
+	return handoffs, nil
+}
+
+// Draft drafts a handoff from the incident's activity since its last
+// handoff. The configured drafter is tried first; when it is unset or fails
+// the summary is built from the timeline.
+func (h *Handoffs) Draft(ctx context.Context, incidentID string) (*HandoffDraft, error) {
+	hc, err := h.context(incidentID)
+	if err != nil {
+		return nil, err
+	}
+	if h.drafter != nil {
+		draft, err := h.drafter.Draft(ctx, hc)
+		if err == nil {
+			return draft, nil
+		}
+		log.Printf("Handoff draft for incident %s failed, summarizing the timeline instead: %v", incidentID, err)
+	}
+	return summarizeHandoff(hc), nil
+}
+
+// Create records a handoff from one responder, drafting the fields left
+// empty when req.Draft is set. Roles listed in req.Roles are handed to
+// req.To, who watches the incident and is notified of the handoff; without
+// To the incident's notification routes are.
+func (h *Handoffs) Create(ctx context.Context, incidentID, from string, req HandoffRequest) (*models.IncidentHandoff, error) {
+	if len(req.Roles) > 0 && req.To == "" {
+		return nil, ErrHandoffNoRecipient
+	}
+	for _, role := range req.Roles {
+		if !models.ValidIncidentRole(role) {
+			return nil, fmt.Errorf("%w %q: expected one of %s", ErrUnknownRole, role, strings.Join(models.IncidentRoles, ", "))
+		}
+	}
+
+	handoff := &models.IncidentHandoff{
+		IncidentID:     incidentID,
+		From:           from,
+		To:             req.To,
+		CurrentState:   strings.TrimSpace(req.CurrentState),
+		PendingActions: strings.TrimSpace(req.PendingActions),
+		NextSteps:      strings.TrimSpace(req.NextSteps),
+		Roles:          req.Roles,
+	}
+	if req.Draft && (handoff.CurrentState == "" || handoff.PendingActions == "" || handoff.NextSteps == "") {
+		draft, err := h.Draft(ctx, incidentID)
+		if err != nil {
+			return nil, err
+		}
+		handoff.DraftedBy = draft.DraftedBy
+		if handoff.CurrentState == "" {
+			handoff.CurrentState = draft.CurrentState
+		}
+		if handoff.PendingActions == "" {
+			handoff.PendingActions = draft.PendingActions
+		}
+		if handoff.NextSteps == "" {
+			handoff.NextSteps = draft.NextSteps
+		}
+	}
+
+	err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var incident models.Incident
+		if err := tx.First(&incident, "incident_id = ?", incidentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrHandoffIncidentNotFound
+			}
+			return fmt.Errorf("failed to fetch incident: %w", err)
+		}
+		if err := tx.Create(handoff).Error; err != nil {
+			return fmt.Errorf("failed to record handoff: %w", err)
+		}
+		if handoff.To != "" {
+			if err := WatchIncident(tx, incidentID, handoff.To, models.WatchHandoff); err != nil {
+				return err
+			}
+		}
+		return h.outbox.Enqueue(tx, TopicNotifyUser, map[string]interface{}{
+			"incident_id": incident.IncidentID,
+			"user":        handoff.To,
+			"severity":    string(incident.Severity),
+			"category":    incident.Category,
+			"team":        incident.Team,
+			"tags":        decodeStringList(incident.Tags),
+			"message":     handoffMessage(&incident, handoff),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Roles are handed over after the handoff is recorded, each in its own
+	// transaction, so watchers hear about every change
+	for _, role := range handoff.Roles {
+		if _, err := h.roles.Assign(ctx, incidentID, role, handoff.To, from, "shift handoff "+handoff.HandoffID); err != nil {
+			return handoff, fmt.Errorf("handoff recorded, but handing over %s failed: %w", role, err)
+		}
+	}
+	log.Printf("Handoff %s of incident %s from %s to %s", handoff.HandoffID, incidentID, from, orDash(handoff.To))
+	return handoff, nil
+}
+
+// handoffMessage is the notification sent to the incoming responder
+func handoffMessage(incident *models.Incident, handoff *models.IncidentHandoff) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Shift handoff for [%s] %s from %s", incident.Severity, incident.Title, handoff.From)
+	if handoff.To != "" {
+		fmt.Fprintf(&b, " to %s", handoff.To)
+	}
+	if len(handoff.Roles) > 0 {
+		fmt.Fprintf(&b, " (now %s)", strings.Join(handoff.Roles, ", "))
+	}
+	for _, section := range []struct{ title, text string }{
+		{"Current state", handoff.CurrentState},
+		{"Pending actions", handoff.PendingActions},
+		{"Next steps", handoff.NextSteps},
+	} {
+		if section.text != "" {
+			fmt.Fprintf(&b, "\n\n%s:\n%s", section.title, section.text)
+		}
+	}
+	return b.String()
+}
+
+// context gathers what a handoff of the incident is drafted from
+func (h *Handoffs) context(incidentID string) (*HandoffContext, error) {
+	report, err := BuildIncidentReport(h.db, incidentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrHandoffIncidentNotFound
+		}
+		return nil, err
+	}
+	hc := &HandoffContext{Incident: &report.Incident, Since: time.Now().UTC().Add(-handoffLookback)}
+	var last models.IncidentHandoff
+	if err := h.db.Where("incident_id = ?", incidentID).Order("created_at DESC").Limit(1).Find(&last).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch handoffs: %w", err)
+	}
+	if last.HandoffID != "" && last.CreatedAt.After(hc.Since) {
+		hc.Since = last.CreatedAt
+	}
+	for _, entry := range report.Timeline {
+		if !entry.Time.Before(hc.Since) {
+			hc.Timeline = append(hc.Timeline, entry)
+		}
+	}
+	if len(hc.Timeline) > maxHandoffTimeline {
+		hc.Timeline = hc.Timeline[len(hc.Timeline)-maxHandoffTimeline:]
+	}
+	for _, task := range report.Tasks {
+		if task.Status == models.TaskOpen || task.Status == models.TaskInProgress {
+			hc.Tasks = append(hc.Tasks, task)
+		}
+	}
+	for _, run := range report.Runs {
+		if run.Status == models.RunRunning {
+			hc.Runs = append(hc.Runs, run)
+		}
+	}
+	if err := h.db.Where("incident_id = ? AND status = ?", incidentID, models.ApprovalPending).
+		Order("created_at ASC").Find(&hc.Approvals).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch approvals: %w", err)
+	}
+	if hc.Roles, err = currentRoles(h.db, incidentID); err != nil {
+		return nil, err
+	}
+	return hc, nil
+}
+
+// summarizeHandoff builds a handoff from the incident's state, open work and
+// recent timeline without an LLM
+func summarizeHandoff(hc *HandoffContext) *HandoffDraft {
+	incident := hc.Incident
+	now := time.Now().UTC()
+
+	var state strings.Builder
+	fmt.Fprintf(&state, "%s incident is %s, open for %s.", incident.Severity, incident.Status, formatIdle(now.Sub(incident.CreatedAt)))
+	for _, a := range hc.Roles {
+		fmt.Fprintf(&state, "\n%s: %s", a.Role, a.Assignee)
+	}
+	fmt.Fprintf(&state, "\n%d timeline entries since %s", len(hc.Timeline), hc.Since.Format(time.RFC3339))
+	recent := hc.Timeline
+	if len(recent) > 5 {
+		recent = recent[len(recent)-5:]
+	}
+	if len(recent) > 0 {
+		state.WriteString(", most recently:")
+		for _, entry := range recent {
+			fmt.Fprintf(&state, "\n- %s %s", entry.Time.Format("15:04"), entry.Summary)
+		}
+	} else {
+		state.WriteString(".")
+	}
+
+	var pending, next []string
+	for _, task := range hc.Tasks {
+		line := task.Title
+		if task.Assignee != nil {
+			line += " (" + *task.Assignee + ")"
+		}
+		if task.DueAt != nil {
+			line += ", due " + task.DueAt.UTC().Format(time.RFC3339)
+		}
+		if task.Status == models.TaskInProgress {
+			pending = append(pending, "- In progress: "+line)
+		} else {
+			next = append(next, "- "+line)
+		}
+	}
+	for _, approval := range hc.Approvals {
+		pending = append(pending, fmt.Sprintf("- Awaiting approval: %s in playbook %s (%s)", approval.ActionType, orDash(approval.PlaybookID), approval.Reason))
+	}
+	for _, run := range hc.Runs {
+		pending = append(pending, fmt.Sprintf("- Playbook %s running since %s", run.PlaybookID, run.StartedAt.UTC().Format(time.RFC3339)))
+	}
+	if len(pending) == 0 {
+		pending = append(pending, "None.")
+	}
+	if len(next) == 0 {
+		next = append(next, "No open tasks.")
+	}
+	return &HandoffDraft{
+		CurrentState:   state.String(),
+		PendingActions: strings.Join(pending, "\n"),
+		NextSteps:      strings.Join(next, "\n"),
+		DraftedBy:      draftedByTimeline,
+	}
+}
+
+// LLMHandoffDrafter drafts handoffs with the Anthropic Messages API from the
+// built-in summary and the timeline since the last handoff
+type LLMHandoffDrafter struct {
+	apiKey string
+	model  string
+	url    string
+	client *http.Client
+}
+
+// NewLLMHandoffDrafter creates an LLM drafter calling the Messages API at url
+func NewLLMHandoffDrafter(apiKey, model, url string) *LLMHandoffDrafter {
+	return &LLMHandoffDrafter{apiKey: apiKey, model: model, url: url, client: &http.Client{Timeout: 60 * time.Second}}
+}
+
+const handoffPrompt = `You are helping an incident responder hand an ongoing incident over to the next shift.
+From the incident details below, write a concise handoff. Respond with only a JSON object with the string fields
+"current_state" (what is happening and what is known), "pending_actions" (work in flight the incoming responder must
+track) and "next_steps" (what they should do next, as a short bulleted list). Do not invent facts.
+
+`
+
+func (d *LLMHandoffDrafter) Draft(ctx context.Context, hc *HandoffContext) (*HandoffDraft, error) {
+	summary := summarizeHandoff(hc)
+	var prompt strings.Builder
+	prompt.WriteString(handoffPrompt)
+	fmt.Fprintf(&prompt, "Incident: %s\nSeverity: %s\nStatus: %s\nTeam: %s\n", hc.Incident.Title, hc.Incident.Severity, hc.Incident.Status, orDash(hc.Incident.Team))
+	if hc.Incident.Description != "" {
+		fmt.Fprintf(&prompt, "Description: %s\n", hc.Incident.Description)
+	}
+	fmt.Fprintf(&prompt, "\nCurrent state:\n%s\n\nPending:\n%s\n\nOpen tasks:\n%s\n\nTimeline since %s:\n",
+		summary.CurrentState, summary.PendingActions, summary.NextSteps, hc.Since.Format(time.RFC3339))
+	for _, entry := range hc.Timeline {
+		fmt.Fprintf(&prompt, "- %s [%s] %s\n", entry.Time.UTC().Format(time.RFC3339), entry.Kind, entry.Summary)
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"model":      d.model,
+		"max_tokens": 1024,
+		"messages":   []map[string]string{{"role": "user", "content": prompt.String()}},
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", d.apiKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("LLM request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("LLM returned HTTP %d: %s", resp.StatusCode, truncate(string(respBody), 200))
+	}
+
+	var message struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(respBody, &message); err != nil {
+		return nil, fmt.Errorf("failed to decode LLM response: %w", err)
+	}
+	var text strings.Builder
+	for _, block := range message.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	// Tolerate prose or a code fence around the JSON object
+	out := text.String()
+	start, end := strings.Index(out, "{"), strings.LastIndex(out, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("LLM response has no JSON object")
+	}
+	var draft HandoffDraft
+	if err := json.Unmarshal([]byte(out[start:end+1]), &draft); err != nil {
+		return nil, fmt.Errorf("failed to decode LLM draft: %w", err)
+	}
+	if draft.CurrentState == "" && draft.PendingActions == "" && draft.NextSteps == "" {
+		return nil, fmt.Errorf("LLM draft is empty")
+	}
+	draft.DraftedBy = d.model
+	return &draft, nil
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// TravelDetectorSource is the source of events raised by the impossible
+// travel detector
+const TravelDetectorSource = "impossible-travel"
+
+// EventImpossibleTravel is the event type the detector raises
+const EventImpossibleTravel = "impossible_travel"
+
+// earthRadiusKm is the mean radius of the Earth
+const earthRadiusKm = 6371.0
+
+// TravelDetectorConfig holds the impossible travel thresholds
+type TravelDetectorConfig struct {
+	// MaxSpeed is the fastest plausible travel between logins, in km/h
+	MaxSpeed float64
+	// MinDistance ignores moves shorter than this many km, which GeoIP
+	// can't place reliably
+	MinDistance float64
+	// EventTypes are the login event types tracked
+	EventTypes []string
+}
+
+// TravelDetector tracks where each user logs in from and raises an
+// impossible_travel event when two consecutive logins are further apart
+// than the user could have travelled in the time between them. A login's
+// location is read from its geo.latitude and geo.longitude fields (plus
+// geo.country and geo.city) when present, or else looked up for its
+// source_ip in the GeoIP database. Logins that can't be located are skipped.
+type TravelDetector struct {
+	db         *gorm.DB
+	geo        *GeoIPDatabase
+	ingestor   *Ingestor
+	cfg        TravelDetectorConfig
+	eventTypes map[string]bool
+}
+
+// NewTravelDetector creates an impossible travel detector that raises its
+// events through ingestor
+func NewTravelDetector(db *gorm.DB, geo *GeoIPDatabase, ingestor *Ingestor, cfg TravelDetectorConfig) *TravelDetector {
+	eventTypes := make(map[string]bool, len(cfg.EventTypes))
+	for _, eventType := range cfg.EventTypes {
+		eventTypes[eventType] = true
+	}
+	return &TravelDetector{db: db, geo: geo, ingestor: ingestor, cfg: cfg, eventTypes: eventTypes}
+}
+
+// SplitList splits a comma-separated setting, dropping empty entries
+func SplitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Observe records a login's location and checks it against the user's
+// previous login
+func (d *TravelDetector) Observe(event *models.Event, normalized map[string]interface{}) {
+	if d == nil || !d.eventTypes[event.EventType] {
+		return
+	}
+	username, _ := eventField(event, normalized, "username").(string)
+	if username == "" {
+		return
+	}
+	sourceIP, _ := eventField(event, normalized, "source_ip").(string)
+	location, ok := d.locate(normalized, sourceIP)
+	if !ok {
+		return
+	}
+
+	current := models.LoginLocation{
+		Username:  username,
+		Exercise:  isExercise(normalized),
+		SourceIP:  sourceIP,
+		Latitude:  location.Latitude,
+		Longitude: location.Longitude,
+		Country:   location.Country,
+		City:      location.City,
+		SeenAt:    event.Timestamp.UTC(),
+		EventID:   event.EventID,
+	}
+
+	var previous models.LoginLocation
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.First(&previous, "username = ? AND exercise = ?", username, current.Exercise).Error
+		if err == gorm.ErrRecordNotFound {
+			previous = models.LoginLocation{}
+		} else if err != nil {
+			return err
+		}
+		if !previous.SeenAt.IsZero() && current.SeenAt.Before(previous.SeenAt) {
+			// Late arrival: compare it, but keep the newer login
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&current).Error
+	})
+	if err != nil {
+		log.Printf("Impossible travel: failed to record login for %s: %v", username, err)
+		return
+	}
+	if previous.SeenAt.IsZero() || previous.SourceIP == sourceIP {
+		return
+	}
+
+	distance := haversineKm(previous.Latitude, previous.Longitude, current.Latitude, current.Longitude)
+	if distance < d.cfg.MinDistance {
+		return
+	}
+	elapsed := current.SeenAt.Sub(previous.SeenAt)
+	if elapsed < 0 {
+		elapsed = -elapsed
+	}
+	speed := math.Inf(1)
+	if elapsed > 0 {
+		speed = distance / elapsed.Hours()
+	}
+	if speed <= d.cfg.MaxSpeed {
+		return
+	}
+	d.raise(event, normalized, previous, current, distance, elapsed, speed)
+}
+
+// locate returns a login's location from its geo fields or the GeoIP database
+func (d *TravelDetector) locate(normalized map[string]interface{}, sourceIP string) (GeoLocation, bool) {
+	lat, latOK := toFloat(getNestedField(normalized, "geo.latitude"))
+	lon, lonOK := toFloat(getNestedField(normalized, "geo.longitude"))
+	if latOK && lonOK {
+		location := GeoLocation{Latitude: lat, Longitude: lon}
+		location.Country, _ = getNestedField(normalized, "geo.country").(string)
+		location.City, _ = getNestedField(normalized, "geo.city").(string)
+		return location, true
+	}
+	if sourceIP == "" {
+		return GeoLocation{}, false
+	}
+	return d.geo.Lookup(sourceIP)
+}
+
+// raise ingests an impossible_travel event for a pair of logins
+func (d *TravelDetector) raise(event *models.Event, normalized map[string]interface{}, previous, current models.LoginLocation, distance float64, elapsed time.Duration, speed float64) {
+	data := map[string]interface{}{
+		"username":           current.Username,
+		"source_ip":          current.SourceIP,
+		"country":            current.Country,
+		"city":               current.City,
+		"previous_source_ip": previous.SourceIP,
+		"previous_country":   previous.Country,
+		"previous_city":      previous.City,
+		"previous_login_at":  previous.SeenAt.Format(time.RFC3339),
+		"distance_km":        math.Round(distance),
+		"elapsed_minutes":    math.Round(elapsed.Minutes()),
+		"login_event_id":     event.EventID,
+		"previous_event_id":  previous.EventID,
+	}
+	if !math.IsInf(speed, 0) {
+		data["speed_kmh"] = math.Round(speed)
+	}
+	if isExercise(normalized) {
+		data[ExerciseField] = true
+	}
+	normalizedJSON, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Impossible travel: failed to encode event: %v", err)
+		return
+	}
+
+	travel := &models.Event{
+		Timestamp:  current.SeenAt,
+		Source:     TravelDetectorSource,
+		EventType:  EventImpossibleTravel,
+		Severity:   models.SeverityHigh,
+		RequestID:  event.RequestID,
+		Normalized: string(normalizedJSON),
+	}
+	if err := d.ingestor.Ingest(travel); err != nil {
+		log.Printf("Impossible travel: failed to ingest event for %s: %v", current.Username, err)
+		return
+	}
+	log.Printf("Impossible travel: %s moved %.0f km (%s to %s) in %s, raised event %s",
+		current.Username, distance, describeLocation(previous), describeLocation(current), elapsed.Round(time.Minute), travel.EventID)
+}
+
+// describeLocation names a login's location for logs
+func describeLocation(l models.LoginLocation) string {
+	switch {
+	case l.City != "" && l.Country != "":
+		return l.City + ", " + l.Country
+	case l.Country != "":
+		return l.Country
+	default:
+		return fmt.Sprintf("%.2f,%.2f", l.Latitude, l.Longitude)
+	}
+}
+
+// haversineKm returns the great-circle distance between two points in km
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// recordIncidentAction links a step a run took to the run's incident, with
+// a one-line summary for the incident timeline. Runs without an incident
+// record nothing.
+func (o *Orchestrator) recordIncidentAction(ctx context.Context, step PlaybookStep, actionID string, err error) {
+	run, ok := StepRunFrom(ctx)
+	if !ok || run.IncidentID == "" {
+		return
+	}
+
+	actionType := step.Action
+	switch {
+	case step.Manual:
+		actionType = "manual"
+	case step.Transform != nil:
+		actionType = "transform"
+	}
+	name := step.Name
+	if name == "" {
+		name = step.ID
+	}
+
+	entry := &models.IncidentAction{
+		IncidentID: run.IncidentID,
+		RunID:      run.RunID,
+		PlaybookID: run.PlaybookID,
+		StepID:     step.ID,
+		ActionType: actionType,
+		Status:     string(models.ActionCompleted),
+		Summary:    fmt.Sprintf("%s (%s) completed", name, actionType),
+	}
+	if actionID != "" {
+		entry.ActionID = &actionID
+	}
+	if err != nil {
+		entry.Status = string(models.ActionFailed)
+		entry.Summary = fmt.Sprintf("%s (%s) failed: %v", name, actionType, err)
+	}
+
+	if err := o.db.Create(entry).Error; err != nil {
+		log.Printf("Failed to record step %s on incident %s: %v", step.ID, run.IncidentID, err)
+	}
+}
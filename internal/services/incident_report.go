@@ -0,0 +1,407 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// maxReportEvents caps the related events listed in a report
+const maxReportEvents = 500
+
+// TimelineEntry is one dated line in an incident report timeline
+type TimelineEntry struct {
+	Time    time.Time `json:"time"`
+	Kind    string    `json:"kind"`
+	Summary string    `json:"summary"`
+}
+
+// IncidentReport gathers everything known about an incident for export
+type IncidentReport struct {
+	GeneratedAt time.Time                `json:"generated_at"`
+	Incident    models.Incident          `json:"incident"`
+	Tags        []string                 `json:"tags"`
+	Timeline    []TimelineEntry          `json:"timeline"`
+	Events      []models.EventSummary    `json:"events"`
+	Actions     []models.ActionLog       `json:"actions"`
+	Runs        []models.PlaybookRun     `json:"playbook_runs"`
+	Tasks       []models.IncidentTask    `json:"tasks"`
+	Comments    []models.IncidentComment `json:"comments"`
+	Alerts      []models.IncidentAlert   `json:"alerts"`
+	Runbook     *models.Runbook          `json:"runbook"`
+
+	// pii masks personal data in the rendered report
+	pii *PIIScrubber
+}
+
+// MaskPII masks personal data in the report's Markdown, HTML and PDF
+// renderings
+func (r *IncidentReport) MaskPII(pii *PIIScrubber) {
+	r.pii = pii
+}
+
+// BuildIncidentReport loads an incident and its related records. It returns
+// gorm.ErrRecordNotFound when the incident doesn't exist.
+func BuildIncidentReport(db *gorm.DB, incidentID string) (*IncidentReport, error) {
+	report := &IncidentReport{GeneratedAt: time.Now().UTC()}
+	if err := db.First(&report.Incident, "incident_id = ?", incidentID).Error; err != nil {
+		return nil, err
+	}
+	incident := &report.Incident
+	report.Tags = decodeStringList(incident.Tags)
+
+	eventIDs := decodeStringList(incident.RelatedEvents)
+	if len(eventIDs) > maxReportEvents {
+		eventIDs = eventIDs[:maxReportEvents]
+	}
+	if len(eventIDs) > 0 {
+		if err := db.Model(&models.Event{}).Select(models.EventSummaryColumns).
+			Where("event_id IN ?", eventIDs).Order("timestamp ASC").Scan(&report.Events).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch events: %w", err)
+		}
+	}
+
+	queries := []struct {
+		dest  interface{}
+		order string
+		what  string
+	}{
+		{&report.Actions, "created_at ASC", "actions"},
+		{&report.Runs, "started_at ASC", "playbook runs"},
+		{&report.Tasks, "created_at ASC", "tasks"},
+		{&report.Comments, "created_at ASC", "comments"},
+		{&report.Alerts, "created_at ASC", "alerts"},
+		{&report.Incident.ActionsTaken, "created_at ASC", "actions taken"},
+	}
+	for _, q := range queries {
+		if err := db.Where("incident_id = ?", incidentID).Order(q.order).Find(q.dest).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", q.what, err)
+		}
+	}
+
+	if incident.RunbookID != nil {
+		var runbook models.Runbook
+		if err := db.First(&runbook, "runbook_id = ?", *incident.RunbookID).Error; err == nil {
+			report.Runbook = &runbook
+		}
+	}
+
+	report.Timeline = report.buildTimeline()
+	return report, nil
+}
+
+// buildTimeline merges the incident's records into one dated list
+func (r *IncidentReport) buildTimeline() []TimelineEntry {
+	incident := r.Incident
+	timeline := []TimelineEntry{{
+		Time:    incident.CreatedAt,
+		Kind:    "incident",
+		Summary: fmt.Sprintf("Incident opened by rule %s (%s)", orDash(incident.TriggeredByRule), incident.Severity),
+	}}
+	add := func(t time.Time, kind, format string, args ...interface{}) {
+		timeline = append(timeline, TimelineEntry{Time: t, Kind: kind, Summary: fmt.Sprintf(format, args...)})
+	}
+
+	for _, e := range r.Events {
+		add(e.Timestamp, "event", "%s event from %s (%s)", e.EventType, e.Source, e.Severity)
+	}
+	// Actions run by playbook steps appear once, as the step's summary
+	stepActions := make(map[string]bool)
+	for _, taken := range incident.ActionsTaken {
+		add(taken.CreatedAt, "step", "Playbook %s: %s", taken.PlaybookID, taken.Summary)
+		if taken.ActionID != nil {
+			stepActions[*taken.ActionID] = true
+		}
+	}
+	for _, a := range r.Actions {
+		if a.ActionType == ActionTypeReopen {
+			add(a.CreatedAt, "incident", "%s", a.Notes)
+			continue
+		}
+		if !stepActions[a.ActionID] {
+			add(a.CreatedAt, "action", "Action %s %s", a.ActionType, a.Status)
+		}
+	}
+	for _, run := range r.Runs {
+		add(run.StartedAt, "playbook", "Playbook %s started", run.PlaybookID)
+		if run.CompletedAt != nil {
+			add(*run.CompletedAt, "playbook", "Playbook %s %s", run.PlaybookID, run.Status)
+		}
+	}
+	for _, t := range r.Tasks {
+		add(t.CreatedAt, "task", "Task added: %s", t.Title)
+		if t.CompletedAt != nil {
+			add(*t.CompletedAt, "task", "Task %s: %s", t.Status, t.Title)
+		}
+	}
+	for _, c := range r.Comments {
+		add(c.CreatedAt, "comment", "%s: %s", c.Author, firstLine(c.Body))
+	}
+	for _, a := range r.Alerts {
+		add(a.CreatedAt, "alert", "Linked upstream %s alert %s (%s)", a.Source, orDash(a.AlertName), a.Fingerprint)
+		if a.ResolvedAt != nil {
+			add(*a.ResolvedAt, "alert", "Upstream %s alert %s resolved", a.Source, orDash(a.AlertName))
+		}
+	}
+	if incident.AcknowledgedAt != nil {
+		add(*incident.AcknowledgedAt, "incident", "Acknowledged by %s", derefString(incident.AcknowledgedBy))
+	}
+	if incident.ResolvedAt != nil {
+		add(*incident.ResolvedAt, "incident", "Resolved by %s", derefString(incident.ResolvedBy))
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Time.Before(timeline[j].Time)
+	})
+	return timeline
+}
+
+// RenderMarkdown renders the report as Markdown
+func (r *IncidentReport) RenderMarkdown() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := markdownReportTemplate.Execute(&buf, r); err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+	return r.scrub(buf.Bytes()), nil
+}
+
+// RenderHTML renders the report as a standalone HTML page
+func (r *IncidentReport) RenderHTML() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlReportTemplate.Execute(&buf, r); err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+	return r.scrub(buf.Bytes()), nil
+}
+
+// RenderPDF renders the Markdown report as a plain-text PDF
+func (r *IncidentReport) RenderPDF() ([]byte, error) {
+	markdown, err := r.RenderMarkdown()
+	if err != nil {
+		return nil, err
+	}
+	return textPDF(string(markdown)), nil
+}
+
+func (r *IncidentReport) scrub(rendered []byte) []byte {
+	if !r.pii.OnExport() {
+		return rendered
+	}
+	return []byte(r.pii.ScrubText(string(rendered)))
+}
+
+var reportFuncs = map[string]interface{}{
+	"ts": func(t time.Time) string {
+		return t.UTC().Format("2006-01-02 15:04:05 UTC")
+	},
+	"tsp": func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.UTC().Format("2006-01-02 15:04:05 UTC")
+	},
+	"deref": derefString,
+	"dash":  orDash,
+	"join":  strings.Join,
+	"cell": func(s string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(s, "|", "\\|"), "\n", " ")
+	},
+}
+
+var markdownReportTemplate = template.Must(template.New("report.md").Funcs(reportFuncs).Parse(`# Incident Report: {{ .Incident.Title }}
+
+_Generated {{ ts .GeneratedAt }}_
+
+## Summary
+
+| Field | Value |
+|-------|-------|
+| Incident ID | {{ .Incident.IncidentID }} |
+| Status | {{ .Incident.Status }} |
+| Severity | {{ .Incident.Severity }} |
+| Category | {{ dash .Incident.Category }} |
+| Rule | {{ dash .Incident.TriggeredByRule }} |
+| Team | {{ cell (dash .Incident.Team) }} |
+| Assigned to | {{ cell (deref .Incident.AssignedTo) }} |
+| Tags | {{ if .Tags }}{{ cell (join .Tags ", ") }}{{ else }}-{{ end }} |
+| Opened | {{ ts .Incident.CreatedAt }} |
+| Acknowledged | {{ tsp .Incident.AcknowledgedAt }}{{ if .Incident.AcknowledgedBy }} by {{ cell (deref .Incident.AcknowledgedBy) }}{{ end }} |
+| Last updated | {{ ts .Incident.UpdatedAt }} |
+{{ if .Incident.Exercise }}
+> This incident was raised by an exercise scenario.
+{{ end }}
+{{ if .Incident.Description }}{{ .Incident.Description }}
+{{ end }}
+## Timeline
+
+| Time | Kind | Entry |
+|------|------|-------|
+{{ range .Timeline }}| {{ ts .Time }} | {{ .Kind }} | {{ cell .Summary }} |
+{{ end }}
+## Actions Taken
+{{ if .Actions }}
+| Time | Action | Status | Notes |
+|------|--------|--------|-------|
+{{ range .Actions }}| {{ ts .CreatedAt }} | {{ .ActionType }} | {{ .Status }} | {{ cell .Notes }}{{ if .Error }} {{ cell (deref .Error) }}{{ end }} |
+{{ end }}{{ else }}
+None.
+{{ end }}{{ if .Runs }}
+### Playbook Runs
+
+| Started | Playbook | Status | Completed |
+|---------|----------|--------|-----------|
+{{ range .Runs }}| {{ ts .StartedAt }} | {{ .PlaybookID }} | {{ .Status }} | {{ tsp .CompletedAt }} |
+{{ end }}{{ end }}{{ if .Tasks }}
+### Tasks
+
+| Task | Assignee | Status | Completed |
+|------|----------|--------|-----------|
+{{ range .Tasks }}| {{ cell .Title }} | {{ cell (deref .Assignee) }} | {{ .Status }} | {{ tsp .CompletedAt }} |
+{{ end }}{{ end }}
+## Artifacts
+
+### Related Events
+{{ if .Events }}
+| Time | Event ID | Type | Source | Severity |
+|------|----------|------|--------|----------|
+{{ range .Events }}| {{ ts .Timestamp }} | {{ .EventID }} | {{ .EventType }} | {{ .Source }} | {{ .Severity }} |
+{{ end }}{{ else }}
+None.
+{{ end }}{{ if .Alerts }}
+### Upstream Alerts
+
+| Source | Alert | Fingerprint | Status | Resolved |
+|--------|-------|-------------|--------|----------|
+{{ range .Alerts }}| {{ .Source }} | {{ cell (dash .AlertName) }} | {{ cell .Fingerprint }} | {{ .Status }} | {{ tsp .ResolvedAt }} |
+{{ end }}{{ end }}{{ if .Runbook }}
+### Runbook
+
+{{ .Runbook.Title }}{{ if .Runbook.URL }} ({{ .Runbook.URL }}){{ end }}
+{{ end }}{{ if .Comments }}
+## Comments
+{{ range .Comments }}
+**{{ .Author }}** - {{ ts .CreatedAt }}
+
+{{ .Body }}
+{{ end }}{{ end }}
+## Resolution
+
+Status: **{{ .Incident.Status }}**{{ if eq .Incident.Status "resolved" }} (as of {{ ts .Incident.UpdatedAt }}){{ end }}
+{{ if .Incident.Notes }}
+` + "```" + `
+{{ .Incident.Notes }}
+` + "```" + `
+{{ end }}`))
+
+var htmlReportTemplate = htmltemplate.Must(htmltemplate.New("report.html").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Incident Report: {{ .Incident.Title }}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 960px; margin: 2rem auto; padding: 0 1rem; color: #1f2328; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1rem; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; vertical-align: top; font-size: 0.9rem; }
+th { background: #f6f8fa; }
+pre { background: #f6f8fa; padding: 0.75rem; white-space: pre-wrap; }
+.muted { color: #656d76; }
+</style>
+</head>
+<body>
+<h1>Incident Report: {{ .Incident.Title }}</h1>
+<p class="muted">Generated {{ ts .GeneratedAt }}</p>
+
+<h2>Summary</h2>
+<table>
+<tr><th>Incident ID</th><td>{{ .Incident.IncidentID }}</td></tr>
+<tr><th>Status</th><td>{{ .Incident.Status }}</td></tr>
+<tr><th>Severity</th><td>{{ .Incident.Severity }}</td></tr>
+<tr><th>Category</th><td>{{ dash .Incident.Category }}</td></tr>
+<tr><th>Rule</th><td>{{ dash .Incident.TriggeredByRule }}</td></tr>
+<tr><th>Team</th><td>{{ dash .Incident.Team }}</td></tr>
+<tr><th>Assigned to</th><td>{{ deref .Incident.AssignedTo }}</td></tr>
+<tr><th>Tags</th><td>{{ if .Tags }}{{ join .Tags ", " }}{{ else }}-{{ end }}</td></tr>
+<tr><th>Opened</th><td>{{ ts .Incident.CreatedAt }}</td></tr>
+<tr><th>Acknowledged</th><td>{{ tsp .Incident.AcknowledgedAt }}{{ if .Incident.AcknowledgedBy }} by {{ deref .Incident.AcknowledgedBy }}{{ end }}</td></tr>
+<tr><th>Last updated</th><td>{{ ts .Incident.UpdatedAt }}</td></tr>
+</table>
+{{ if .Incident.Exercise }}<p><strong>This incident was raised by an exercise scenario.</strong></p>{{ end }}
+{{ if .Incident.Description }}<p>{{ .Incident.Description }}</p>{{ end }}
+
+<h2>Timeline</h2>
+<table>
+<tr><th>Time</th><th>Kind</th><th>Entry</th></tr>
+{{ range .Timeline }}<tr><td>{{ ts .Time }}</td><td>{{ .Kind }}</td><td>{{ .Summary }}</td></tr>
+{{ end }}</table>
+
+<h2>Actions Taken</h2>
+{{ if .Actions }}<table>
+<tr><th>Time</th><th>Action</th><th>Status</th><th>Notes</th></tr>
+{{ range .Actions }}<tr><td>{{ ts .CreatedAt }}</td><td>{{ .ActionType }}</td><td>{{ .Status }}</td><td>{{ .Notes }}{{ if .Error }} {{ deref .Error }}{{ end }}</td></tr>
+{{ end }}</table>{{ else }}<p>None.</p>{{ end }}
+{{ if .Runs }}<h3>Playbook Runs</h3>
+<table>
+<tr><th>Started</th><th>Playbook</th><th>Status</th><th>Completed</th></tr>
+{{ range .Runs }}<tr><td>{{ ts .StartedAt }}</td><td>{{ .PlaybookID }}</td><td>{{ .Status }}</td><td>{{ tsp .CompletedAt }}</td></tr>
+{{ end }}</table>{{ end }}
+{{ if .Tasks }}<h3>Tasks</h3>
+<table>
+<tr><th>Task</th><th>Assignee</th><th>Status</th><th>Completed</th></tr>
+{{ range .Tasks }}<tr><td>{{ .Title }}</td><td>{{ deref .Assignee }}</td><td>{{ .Status }}</td><td>{{ tsp .CompletedAt }}</td></tr>
+{{ end }}</table>{{ end }}
+
+<h2>Artifacts</h2>
+<h3>Related Events</h3>
+{{ if .Events }}<table>
+<tr><th>Time</th><th>Event ID</th><th>Type</th><th>Source</th><th>Severity</th></tr>
+{{ range .Events }}<tr><td>{{ ts .Timestamp }}</td><td>{{ .EventID }}</td><td>{{ .EventType }}</td><td>{{ .Source }}</td><td>{{ .Severity }}</td></tr>
+{{ end }}</table>{{ else }}<p>None.</p>{{ end }}
+{{ if .Alerts }}<h3>Upstream Alerts</h3>
+<table>
+<tr><th>Source</th><th>Alert</th><th>Fingerprint</th><th>Status</th><th>Resolved</th></tr>
+{{ range .Alerts }}<tr><td>{{ .Source }}</td><td>{{ dash .AlertName }}</td><td>{{ .Fingerprint }}</td><td>{{ .Status }}</td><td>{{ tsp .ResolvedAt }}</td></tr>
+{{ end }}</table>{{ end }}
+{{ if .Runbook }}<h3>Runbook</h3>
+<p>{{ if .Runbook.URL }}<a href="{{ .Runbook.URL }}">{{ .Runbook.Title }}</a>{{ else }}{{ .Runbook.Title }}{{ end }}</p>{{ end }}
+{{ if .Comments }}<h2>Comments</h2>
+{{ range .Comments }}<p><strong>{{ .Author }}</strong> <span class="muted">{{ ts .CreatedAt }}</span></p>
+<pre>{{ .Body }}</pre>
+{{ end }}{{ end }}
+<h2>Resolution</h2>
+<p>Status: <strong>{{ .Incident.Status }}</strong>{{ if eq .Incident.Status "resolved" }} (as of {{ ts .Incident.UpdatedAt }}){{ end }}</p>
+{{ if .Incident.Notes }}<pre>{{ .Incident.Notes }}</pre>{{ end }}
+</body>
+</html>
+`))
+
+// orDash returns "-" for empty strings
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// derefString returns "-" for nil or empty string pointers
+func derefString(s *string) string {
+	if s == nil {
+		return "-"
+	}
+	return orDash(*s)
+}
+
+// firstLine returns the first line of s, marking truncation
+func firstLine(s string) string {
+	if idx := strings.IndexByte(s, '\n'); idx != -1 {
+		return s[:idx] + " ..."
+	}
+	return s
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+var (
+	// ErrUnknownRole is returned for a role that isn't an incident role
+	ErrUnknownRole = errors.New("unknown incident role")
+	// ErrRoleIncidentNotFound is returned for a role on an unknown incident
+	ErrRoleIncidentNotFound = errors.New("incident not found")
+	// ErrRoleNotHeld is returned when unassigning a role nobody holds
+	ErrRoleNotHeld = errors.New("role is not assigned")
+)
+
+// roleTargetPrefix marks notification targets resolved to the holders of an
+// incident role, e.g. "role:commander"
+const roleTargetPrefix = "role:"
+
+// IncidentRoles assigns and rotates responders' roles on incidents. The
+// commander, comms lead and scribe are held by one responder at a time;
+// assigning one hands it over. An incident can have several SMEs.
+type IncidentRoles struct {
+	db     *gorm.DB
+	outbox *Outbox
+}
+
+// NewIncidentRoles creates the incident role service; role changes notify
+// the incident's watchers through outbox
+func NewIncidentRoles(db *gorm.DB, outbox *Outbox) *IncidentRoles {
+	return &IncidentRoles{db: db, outbox: outbox}
+}
+
+// Current returns an incident's current role assignments, in role order
+func (r *IncidentRoles) Current(incidentID string) ([]models.IncidentRoleAssignment, error) {
+	return currentRoles(r.db, incidentID)
+}
+
+// History returns every assignment of an incident's roles, oldest first
+func (r *IncidentRoles) History(incidentID string) ([]models.IncidentRoleAssignment, error) {
+	assignments := []models.IncidentRoleAssignment{}
+	if err := r.db.Where("incident_id = ?", incidentID).Order("started_at ASC").Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch roles: %w", err)
+	}
+	return assignments, nil
+}
+
+// Assign gives assignee a role on an incident. A single-holder role is
+// handed over, ending the previous holder's assignment; assigning the
+// current holder again changes nothing. The assignee watches the incident
+// from then on.
+func (r *IncidentRoles) Assign(ctx context.Context, incidentID, role, assignee, by, note string) (*models.IncidentRoleAssignment, error) {
+	if !models.ValidIncidentRole(role) {
+		return nil, fmt.Errorf("%w %q: expected one of %s", ErrUnknownRole, role, strings.Join(models.IncidentRoles, ", "))
+	}
+	var assignment *models.IncidentRoleAssignment
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var incident models.Incident
+		if err := tx.First(&incident, "incident_id = ?", incidentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRoleIncidentNotFound
+			}
+			return fmt.Errorf("failed to fetch incident: %w", err)
+		}
+
+		var holders []models.IncidentRoleAssignment
+		if err := tx.Where("incident_id = ? AND role = ? AND ended_at IS NULL", incidentID, role).
+			Find(&holders).Error; err != nil {
+			return fmt.Errorf("failed to fetch role holders: %w", err)
+		}
+		var previous []string
+		for i := range holders {
+			if holders[i].Assignee == assignee {
+				assignment = &holders[i]
+				return nil
+			}
+			if role != models.RoleSME {
+				if err := endAssignment(tx, &holders[i], by); err != nil {
+					return err
+				}
+				previous = append(previous, holders[i].Assignee)
+			}
+		}
+
+		assignment = &models.IncidentRoleAssignment{
+			IncidentID: incidentID,
+			Role:       role,
+			Assignee:   assignee,
+			AssignedBy: by,
+			Note:       note,
+		}
+		if err := tx.Create(assignment).Error; err != nil {
+			return fmt.Errorf("failed to assign role: %w", err)
+		}
+		if err := WatchIncident(tx, incidentID, assignee, models.WatchRole); err != nil {
+			return err
+		}
+		change := fmt.Sprintf("%s is now %s", assignee, role)
+		if len(previous) > 0 {
+			change += " (was " + strings.Join(previous, ", ") + ")"
+		}
+		log.Printf("Incident %s: %s, assigned by %s", incidentID, change, by)
+		return NotifyWatchers(tx, r.outbox, &incident, by, change+", assigned by "+by)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// Unassign ends a role's assignment: assignee's, or with no assignee every
+// current holder's
+func (r *IncidentRoles) Unassign(ctx context.Context, incidentID, role, assignee, by string) error {
+	if !models.ValidIncidentRole(role) {
+		return fmt.Errorf("%w %q: expected one of %s", ErrUnknownRole, role, strings.Join(models.IncidentRoles, ", "))
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var incident models.Incident
+		if err := tx.First(&incident, "incident_id = ?", incidentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRoleIncidentNotFound
+			}
+			return fmt.Errorf("failed to fetch incident: %w", err)
+		}
+		query := tx.Where("incident_id = ? AND role = ? AND ended_at IS NULL", incidentID, role)
+		if assignee != "" {
+			query = query.Where("assignee = ?", assignee)
+		}
+		var holders []models.IncidentRoleAssignment
+		if err := query.Find(&holders).Error; err != nil {
+			return fmt.Errorf("failed to fetch role holders: %w", err)
+		}
+		if len(holders) == 0 {
+			return ErrRoleNotHeld
+		}
+		var ended []string
+		for i := range holders {
+			if err := endAssignment(tx, &holders[i], by); err != nil {
+				return err
+			}
+			ended = append(ended, holders[i].Assignee)
+		}
+		change := fmt.Sprintf("%s no longer %s", strings.Join(ended, ", "), role)
+		log.Printf("Incident %s: %s, unassigned by %s", incidentID, change, by)
+		return NotifyWatchers(tx, r.outbox, &incident, by, change+", unassigned by "+by)
+	})
+}
+
+func endAssignment(tx *gorm.DB, assignment *models.IncidentRoleAssignment, by string) error {
+	now := time.Now().UTC()
+	assignment.EndedAt = &now
+	assignment.EndedBy = &by
+	if err := tx.Model(assignment).Updates(map[string]interface{}{"ended_at": now, "ended_by": by}).Error; err != nil {
+		return fmt.Errorf("failed to end role assignment: %w", err)
+	}
+	return nil
+}
+
+// currentRoles returns an incident's current role assignments, in role order
+func currentRoles(db *gorm.DB, incidentID string) ([]models.IncidentRoleAssignment, error) {
+	var assignments []models.IncidentRoleAssignment
+	if err := db.Where("incident_id = ? AND ended_at IS NULL", incidentID).
+		Order("started_at ASC").Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch roles: %w", err)
+	}
+	ordered := make([]models.IncidentRoleAssignment, 0, len(assignments))
+	for _, role := range models.IncidentRoles {
+		for _, a := range assignments {
+			if a.Role == role {
+				ordered = append(ordered, a)
+			}
+		}
+	}
+	return ordered, nil
+}
+
+// roleHolders returns the current holders of a role on an incident
+func roleHolders(db *gorm.DB, incidentID, role string) ([]string, error) {
+	var holders []string
+	if err := db.Model(&models.IncidentRoleAssignment{}).
+		Where("incident_id = ? AND role = ? AND ended_at IS NULL", incidentID, role).
+		Order("started_at ASC").Pluck("assignee", &holders).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch role holders: %w", err)
+	}
+	return holders, nil
+}
+
+// incidentCommander returns the incident's commander, falling back to its
+// assignee when no commander is assigned
+func incidentCommander(db *gorm.DB, incident *models.Incident) string {
+	holders, err := roleHolders(db, incident.IncidentID, models.RoleCommander)
+	if err != nil {
+		log.Printf("Failed to look up commander of incident %s: %v", incident.IncidentID, err)
+	}
+	if len(holders) > 0 {
+		return holders[0]
+	}
+	if incident.AssignedTo != nil {
+		return *incident.AssignedTo
+	}
+	return ""
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/store"
+)
+
+// Ingestor stores incoming events and hands them to the detection engine. It
+// is shared by every ingest transport (REST, gRPC).
+type Ingestor struct {
+	events          store.EventStore
+	detectionEngine *DetectionEngine
+	// Tolerances for client-supplied occurred_at; 0 disables the check
+	maxFutureSkew time.Duration
+	maxPastSkew   time.Duration
+	// async queues fast-ack events (see ingest_async.go)
+	async *asyncQueue
+	// pii masks personal data in payloads before storage
+	pii *PIIScrubber
+	// tenants store the events of tenants with a database of their own
+	tenants *database.Tenants
+}
+
+// NewIngestor creates an ingestor storing events in events
+func NewIngestor(events store.EventStore, detectionEngine *DetectionEngine) *Ingestor {
+	return &Ingestor{
+		events:          events,
+		detectionEngine: detectionEngine,
+	}
+}
+
+// SetClockSkew sets how far a client-supplied occurred_at may lie ahead of
+// or behind the time an event is received before it is flagged and the
+// receive time used instead; 0 disables either check
+func (i *Ingestor) SetClockSkew(maxFuture, maxPast time.Duration) {
+	i.maxFutureSkew = maxFuture
+	i.maxPastSkew = maxPast
+}
+
+// SetPIIScrubber masks personal data in event payloads before they are
+// stored, when the PII policy applies on store
+func (i *Ingestor) SetPIIScrubber(pii *PIIScrubber) {
+	i.pii = pii
+}
+
+// SetTenants stores events stamped with a tenant in that tenant's database
+func (i *Ingestor) SetTenants(tenants *database.Tenants) {
+	i.tenants = tenants
+}
+
+// eventsFor returns the store for a tenant's events
+func (i *Ingestor) eventsFor(tenant string) store.EventStore {
+	if writer := i.tenants.Writer(tenant); writer != nil {
+		return store.NewGormEvents(i.tenants.Lookup(tenant), writer)
+	}
+	return i.events
+}
+
+// prepare readies an event for storage: it settles its timestamps and masks
+// personal data
+func (i *Ingestor) prepare(event *models.Event, now time.Time) {
+	i.stampTimes(event, now)
+	if i.pii.OnStore() {
+		i.pii.ScrubEvent(event)
+	}
+}
+
+// stampTimes records when an event was received and settles the timestamp
+// detection uses: its occurred_at when plausible, otherwise the receive time
+// with the event flagged. Events without occurred_at keep any timestamp
+// their producer set.
+func (i *Ingestor) stampTimes(event *models.Event, now time.Time) {
+	if event.ReceivedAt == nil {
+		event.ReceivedAt = &now
+	}
+	received := *event.ReceivedAt
+	if event.OccurredAt == nil {
+		if event.Timestamp.IsZero() {
+			event.Timestamp = received
+		}
+		return
+	}
+
+	occurred := event.OccurredAt.UTC()
+	event.OccurredAt = &occurred
+	switch skew := occurred.Sub(received); {
+	case i.maxFutureSkew > 0 && skew > i.maxFutureSkew:
+		event.ClockSkew = models.ClockSkewFuture
+		event.Timestamp = received
+	case i.maxPastSkew > 0 && -skew > i.maxPastSkew:
+		event.ClockSkew = models.ClockSkewPast
+		event.Timestamp = received
+	default:
+		event.Timestamp = occurred
+	}
+}
+
+// Ingest persists an event and triggers asynchronous detection
+func (i *Ingestor) Ingest(event *models.Event) error {
+	i.prepare(event, time.Now().UTC())
+	if err := i.eventsFor(event.Tenant).Save(context.Background(), event); err != nil {
+		return err
+	}
+
+	i.detectionEngine.Submit(event)
+	return nil
+}
+
+// IngestBatch persists several events in the shared store, or their
+// tenants' databases, and triggers detection for each once they are committed
+func (i *Ingestor) IngestBatch(events []*models.Event) error {
+	now := time.Now().UTC()
+	var tenants []string
+	byTenant := make(map[string][]*models.Event)
+	for _, event := range events {
+		i.prepare(event, now)
+		if _, ok := byTenant[event.Tenant]; !ok {
+			tenants = append(tenants, event.Tenant)
+		}
+		byTenant[event.Tenant] = append(byTenant[event.Tenant], event)
+	}
+
+	for _, tenant := range tenants {
+		group := byTenant[tenant]
+		if err := i.eventsFor(tenant).Save(context.Background(), group...); err != nil {
+			return err
+		}
+		i.detectionEngine.Submit(group...)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// ErrIngestQueueFull is returned when the fast-ack queue can't take events
+var ErrIngestQueueFull = errors.New("ingest queue is full")
+
+// maxAsyncWriteBatch bounds the events the fast-ack worker writes at once
+const maxAsyncWriteBatch = 500
+
+// asyncQueue holds fast-ack events waiting to be stored
+type asyncQueue struct {
+	events chan *models.Event
+	// sendMu serializes producers so a batch is queued whole or not at all
+	sendMu sync.Mutex
+	closed bool
+	done   chan struct{}
+	failed atomic.Int64
+}
+
+// AsyncQueueStats describes the fast-ack queue
+type AsyncQueueStats struct {
+	Enabled  bool  `json:"enabled"`
+	Queued   int   `json:"queued"`
+	Capacity int   `json:"capacity"`
+	Failed   int64 `json:"failed"`
+}
+
+// StartAsync enables fast-ack ingestion with a queue of up to size events
+// and starts the worker that stores and evaluates them
+func (i *Ingestor) StartAsync(size int) {
+	if size <= 0 || i.async != nil {
+		return
+	}
+	i.async = &asyncQueue{
+		events: make(chan *models.Event, size),
+		done:   make(chan struct{}),
+	}
+	go i.runAsync()
+}
+
+// Enqueue accepts events for fast-ack ingestion: they get their IDs and
+// receive times now and are stored and evaluated in the background, so the
+// caller never waits on the database. The batch is refused whole with
+// ErrIngestQueueFull when the queue lacks room for it. Without StartAsync
+// events are ingested synchronously.
+func (i *Ingestor) Enqueue(events []*models.Event) error {
+	if i.async == nil {
+		return i.IngestBatch(events)
+	}
+
+	now := time.Now().UTC()
+	for _, event := range events {
+		if event.EventID == "" {
+			event.EventID = uuid.New().String()
+		}
+		i.prepare(event, now)
+	}
+
+	q := i.async
+	q.sendMu.Lock()
+	defer q.sendMu.Unlock()
+	if q.closed || cap(q.events)-len(q.events) < len(events) {
+		return ErrIngestQueueFull
+	}
+	for _, event := range events {
+		q.events <- event
+	}
+	return nil
+}
+
+// AsyncStats reports the fast-ack queue's depth and failed writes
+func (i *Ingestor) AsyncStats() AsyncQueueStats {
+	if i.async == nil {
+		return AsyncQueueStats{}
+	}
+	return AsyncQueueStats{
+		Enabled:  true,
+		Queued:   len(i.async.events),
+		Capacity: cap(i.async.events),
+		Failed:   i.async.failed.Load(),
+	}
+}
+
+// Close stops accepting fast-ack events and waits until those queued are
+// stored. It must be called before the batch writer is closed.
+func (i *Ingestor) Close() {
+	if i.async == nil {
+		return
+	}
+	i.async.sendMu.Lock()
+	if !i.async.closed {
+		i.async.closed = true
+		close(i.async.events)
+	}
+	i.async.sendMu.Unlock()
+	<-i.async.done
+}
+
+// runAsync writes queued events in batches of whatever has accumulated.
+// Events that fail to store are logged and dropped; their clients were
+// already told they were accepted.
+func (i *Ingestor) runAsync() {
+	q := i.async
+	defer close(q.done)
+
+	batch := make([]*models.Event, 0, maxAsyncWriteBatch)
+	for event := range q.events {
+		batch = append(batch[:0], event)
+	drain:
+		for len(batch) < maxAsyncWriteBatch {
+			select {
+			case next, ok := <-q.events:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, next)
+			default:
+				break drain
+			}
+		}
+
+		if err := i.IngestBatch(append([]*models.Event(nil), batch...)); err != nil {
+			q.failed.Add(int64(len(batch)))
+			log.Printf("Fast-ack ingest: failed to store %d events: %v", len(batch), err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+	"github.com/gixxerblade/incident-response-mvp/internal/store"
+)
+
+// waitProcessed polls the store until detection has marked an event
+// processed
+func waitProcessed(t *testing.T, events *store.MemoryEvents, eventID string) *models.Event {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		event, err := events.Get(context.Background(), eventID)
+		if err == nil && event.ProcessedAt != nil {
+			return event
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("event %s was not marked processed", eventID)
+	return nil
+}
+
+func newTestIngestor() (*Ingestor, *store.MemoryEvents) {
+	events := store.NewMemoryEvents()
+	return NewIngestor(events, NewDetectionEngine(nil, events, nil, nil)), events
+}
+
+func TestIngestMarksEventsProcessed(t *testing.T) {
+	ingestor, events := newTestIngestor()
+	event := &models.Event{Source: "sshd", EventType: "auth_failure", Severity: "info", Normalized: `{"username":"alice"}`}
+	if err := ingestor.Ingest(event); err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	if event.EventID == "" || event.ReceivedAt == nil || event.Timestamp.IsZero() {
+		t.Fatalf("ingested event not stamped: id %q, received %v, timestamp %v", event.EventID, event.ReceivedAt, event.Timestamp)
+	}
+
+	stored := waitProcessed(t, events, event.EventID)
+	if stored.ProcessedAt.Before(*stored.ReceivedAt) {
+		t.Errorf("processed_at %v before received_at %v", stored.ProcessedAt, stored.ReceivedAt)
+	}
+	if len(events.Traces(event.EventID)) != 0 {
+		t.Error("trace recorded with tracing off")
+	}
+}
+
+func TestIngestBatch(t *testing.T) {
+	ingestor, events := newTestIngestor()
+	batch := make([]*models.Event, 5)
+	for i := range batch {
+		batch[i] = &models.Event{Source: "sshd", EventType: "auth_failure", Severity: "info", Normalized: `{}`}
+	}
+	if err := ingestor.IngestBatch(batch); err != nil {
+		t.Fatalf("IngestBatch() error = %v", err)
+	}
+	if events.Len() != len(batch) {
+		t.Fatalf("stored %d events, want %d", events.Len(), len(batch))
+	}
+	for _, event := range batch {
+		waitProcessed(t, events, event.EventID)
+	}
+}
+
+func TestIngestClockSkew(t *testing.T) {
+	ingestor, events := newTestIngestor()
+	ingestor.SetClockSkew(time.Minute, time.Hour)
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name     string
+		occurred time.Time
+		wantSkew models.ClockSkew
+		useOwn   bool
+	}{
+		{"plausible", now.Add(-10 * time.Minute), "", true},
+		{"future", now.Add(10 * time.Minute), models.ClockSkewFuture, false},
+		{"past", now.Add(-2 * time.Hour), models.ClockSkewPast, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			occurred := tt.occurred
+			event := &models.Event{Source: "sshd", EventType: "auth_failure", Severity: "info", Normalized: `{}`, OccurredAt: &occurred}
+			if err := ingestor.Ingest(event); err != nil {
+				t.Fatalf("Ingest() error = %v", err)
+			}
+			stored := waitProcessed(t, events, event.EventID)
+			if stored.ClockSkew != tt.wantSkew {
+				t.Errorf("clock_skew = %q, want %q", stored.ClockSkew, tt.wantSkew)
+			}
+			if got := stored.Timestamp.Equal(occurred); got != tt.useOwn {
+				t.Errorf("timestamp %v uses occurred_at = %v, want %v", stored.Timestamp, got, tt.useOwn)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// IPAllowlist restricts the source addresses that may reach a group of
+// endpoints. An empty allowlist allows every address.
+type IPAllowlist struct {
+	mu   sync.RWMutex
+	nets []*net.IPNet
+}
+
+// ParseIPNets parses a comma-separated list of IP addresses and CIDR ranges,
+// such as "10.0.0.0/8,192.0.2.7"
+func ParseIPNets(spec string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, entry := range SplitList(spec) {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", entry)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			entry = fmt.Sprintf("%s/%d", entry, bits)
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR range %q", entry)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// NewIPAllowlist creates an allowlist of nets
+func NewIPAllowlist(nets []*net.IPNet) *IPAllowlist {
+	return &IPAllowlist{nets: nets}
+}
+
+// Set replaces the allowed nets
+func (l *IPAllowlist) Set(nets []*net.IPNet) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.nets = nets
+}
+
+// Allows reports whether a source address may pass. Unparseable addresses
+// only pass an empty allowlist.
+func (l *IPAllowlist) Allows(addr string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if len(l.nets) == 0 {
+		return true
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range l.nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// LeaderElector holds a database lease so only one instance runs background
+// jobs while every instance keeps serving the API and ingest
+type LeaderElector struct {
+	db         *gorm.DB
+	name       string
+	instanceID string
+	ttl        time.Duration
+
+	leader atomic.Bool
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewLeaderElector creates a leader elector for the named lease
+func NewLeaderElector(db *gorm.DB, name, instanceID string, ttl time.Duration) *LeaderElector {
+	return &LeaderElector{
+		db:         db,
+		name:       name,
+		instanceID: instanceID,
+		ttl:        ttl,
+		stop:       make(chan struct{}),
+	}
+}
+
+// Start begins acquiring and renewing the lease at a third of its TTL
+func (le *LeaderElector) Start() {
+	le.tryAcquire()
+
+	le.wg.Add(1)
+	go func() {
+		defer le.wg.Done()
+		ticker := time.NewTicker(le.ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				le.tryAcquire()
+			case <-le.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts renewal and releases the lease if held, letting another
+// instance take over without waiting for expiry
+func (le *LeaderElector) Stop() {
+	close(le.stop)
+	le.wg.Wait()
+
+	if le.leader.Load() {
+		le.db.Where("name = ? AND holder = ?", le.name, le.instanceID).Delete(&models.Lease{})
+		le.leader.Store(false)
+	}
+}
+
+// IsLeader reports whether this instance currently holds the lease
+func (le *LeaderElector) IsLeader() bool {
+	return le.leader.Load()
+}
+
+// InstanceID returns this instance's lease holder identity
+func (le *LeaderElector) InstanceID() string {
+	return le.instanceID
+}
+
+// tryAcquire renews the lease if held or takes it over if expired
+func (le *LeaderElector) tryAcquire() {
+	now := time.Now().UTC()
+	expiresAt := now.Add(le.ttl)
+
+	// Create the lease row on first use; a no-op if it already exists
+	le.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Lease{
+		Name:      le.name,
+		Holder:    le.instanceID,
+		ExpiresAt: expiresAt,
+	})
+
+	result := le.db.Model(&models.Lease{}).
+		Where("name = ? AND (holder = ? OR expires_at < ?)", le.name, le.instanceID, now).
+		Updates(map[string]interface{}{"holder": le.instanceID, "expires_at": expiresAt})
+
+	isLeader := result.Error == nil && result.RowsAffected > 0
+	if result.Error != nil {
+		log.Printf("Leader election: failed to renew lease %s: %v", le.name, result.Error)
+	}
+
+	if was := le.leader.Swap(isLeader); was != isLeader {
+		if isLeader {
+			log.Printf("Leader election: %s acquired lease %s", le.instanceID, le.name)
+		} else {
+			log.Printf("Leader election: %s lost lease %s", le.instanceID, le.name)
+		}
+	}
+}
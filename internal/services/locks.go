@@ -0,0 +1,71 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// ErrLockTimeout is returned when a lock could not be acquired in time
+var ErrLockTimeout = errors.New("timed out waiting for lock")
+
+// LockManager provides short-lived named locks backed by the leases table, so
+// they hold across goroutines and across instances sharing a database
+type LockManager struct {
+	db *gorm.DB
+}
+
+// NewLockManager creates a new lock manager
+func NewLockManager(db *gorm.DB) *LockManager {
+	return &LockManager{db: db}
+}
+
+// TryLock attempts to take the named lock for ttl without waiting. On success
+// it returns a token that must be passed to Unlock.
+func (lm *LockManager) TryLock(name string, ttl time.Duration) (string, bool) {
+	token := uuid.New().String()
+	now := time.Now().UTC()
+
+	created := lm.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Lease{
+		Name:      name,
+		Holder:    token,
+		ExpiresAt: now.Add(ttl),
+	})
+	if created.Error == nil && created.RowsAffected > 0 {
+		return token, true
+	}
+
+	// Take over a lock whose holder crashed without releasing it
+	taken := lm.db.Model(&models.Lease{}).
+		Where("name = ? AND expires_at < ?", name, now).
+		Updates(map[string]interface{}{"holder": token, "expires_at": now.Add(ttl)})
+	if taken.Error == nil && taken.RowsAffected > 0 {
+		return token, true
+	}
+
+	return "", false
+}
+
+// Lock waits up to timeout for the named lock
+func (lm *LockManager) Lock(name string, ttl, timeout time.Duration) (string, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		if token, ok := lm.TryLock(name, ttl); ok {
+			return token, nil
+		}
+		if time.Now().After(deadline) {
+			return "", ErrLockTimeout
+		}
+		time.Sleep(25 * time.Millisecond)
+	}
+}
+
+// Unlock releases the named lock if it is still held by token
+func (lm *LockManager) Unlock(name, token string) {
+	lm.db.Where("name = ? AND holder = ?", name, token).Delete(&models.Lease{})
+}
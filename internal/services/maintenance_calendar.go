@@ -0,0 +1,649 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+const (
+	// calendarHorizon is how far ahead recurring maintenance is expanded
+	calendarHorizon = 30 * 24 * time.Hour
+	// maxCalendarSize bounds a fetched calendar feed
+	maxCalendarSize = 10 << 20
+)
+
+// MaintenanceCalendar is an iCal feed of maintenance windows. Google
+// Calendar publishes one as the calendar's "secret address in iCal format".
+type MaintenanceCalendar struct {
+	Name string
+	URL  string
+}
+
+// ParseMaintenanceCalendars parses a calendar list such as
+// "prod=https://example.com/maint.ics,net=webcal://example.com/net.ics"
+func ParseMaintenanceCalendars(spec string) ([]MaintenanceCalendar, error) {
+	var calendars []MaintenanceCalendar
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, url, ok := strings.Cut(entry, "=")
+		name, url = strings.TrimSpace(name), strings.TrimSpace(url)
+		if !ok || name == "" || url == "" {
+			return nil, fmt.Errorf("invalid maintenance calendar %q: expected name=url", entry)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate maintenance calendar %q", name)
+		}
+		seen[name] = true
+		if strings.HasPrefix(url, "webcal://") {
+			url = "https://" + strings.TrimPrefix(url, "webcal://")
+		}
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			return nil, fmt.Errorf("invalid maintenance calendar %q: unsupported URL scheme", entry)
+		}
+		calendars = append(calendars, MaintenanceCalendar{Name: name, URL: url})
+	}
+	return calendars, nil
+}
+
+// CalendarSync imports maintenance windows from iCal feeds as suppressions.
+// Each event's sources and tags come from "sources:" and "tags:" lines in
+// its description, plus its categories as tags; events with neither are
+// skipped. Windows removed or cancelled in the calendar are deleted unless
+// they have already ended.
+type CalendarSync struct {
+	db        *gorm.DB
+	client    *http.Client
+	calendars []MaintenanceCalendar
+}
+
+// NewCalendarSync creates a calendar sync
+func NewCalendarSync(db *gorm.DB, calendars []MaintenanceCalendar) *CalendarSync {
+	return &CalendarSync{
+		db:        db,
+		client:    &http.Client{Timeout: 30 * time.Second},
+		calendars: calendars,
+	}
+}
+
+// CalendarSyncResult summarizes the import of one calendar
+type CalendarSyncResult struct {
+	Calendar string `json:"calendar"`
+	Imported int    `json:"imported"`
+	Removed  int64  `json:"removed"`
+	Skipped  int    `json:"skipped"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Sync imports every calendar; intended to run as a leader-only scheduled job
+func (s *CalendarSync) Sync() error {
+	var errs []error
+	for _, result := range s.SyncAll(context.Background()) {
+		if result.Error != "" {
+			errs = append(errs, fmt.Errorf("calendar %s: %s", result.Calendar, result.Error))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SyncAll imports every calendar, continuing past failures
+func (s *CalendarSync) SyncAll(ctx context.Context) []CalendarSyncResult {
+	results := make([]CalendarSyncResult, 0, len(s.calendars))
+	for _, calendar := range s.calendars {
+		result, err := s.syncCalendar(ctx, calendar)
+		if err != nil {
+			log.Printf("Maintenance calendar %s: sync failed: %v", calendar.Name, err)
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (s *CalendarSync) syncCalendar(ctx context.Context, calendar MaintenanceCalendar) (CalendarSyncResult, error) {
+	result := CalendarSyncResult{Calendar: calendar.Name}
+	body, err := s.fetch(ctx, calendar.URL)
+	if err != nil {
+		return result, err
+	}
+
+	now := time.Now().UTC()
+	windows, skipped, err := parseMaintenanceWindows(body, now, now.Add(calendarHorizon))
+	if err != nil {
+		return result, err
+	}
+	result.Skipped = skipped
+
+	origin := "calendar:" + calendar.Name
+	externalIDs := make([]string, 0, len(windows))
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, w := range windows {
+			suppression := &models.Suppression{
+				Name:       w.Summary,
+				Reason:     "Scheduled maintenance (" + calendar.Name + " calendar)",
+				StartsAt:   w.Start,
+				EndsAt:     w.End,
+				Sources:    w.Sources,
+				Tags:       w.Tags,
+				Origin:     origin,
+				ExternalID: w.ExternalID,
+				CreatedBy:  origin,
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "origin"}, {Name: "external_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"name", "starts_at", "ends_at", "sources", "tags", "updated_at"}),
+			}).Create(suppression).Error; err != nil {
+				return fmt.Errorf("failed to save suppression: %w", err)
+			}
+			externalIDs = append(externalIDs, w.ExternalID)
+		}
+
+		query := tx.Where("origin = ? AND ends_at > ?", origin, now)
+		if len(externalIDs) > 0 {
+			query = query.Where("external_id NOT IN ?", externalIDs)
+		}
+		removed := query.Delete(&models.Suppression{})
+		if removed.Error != nil {
+			return fmt.Errorf("failed to remove suppressions: %w", removed.Error)
+		}
+		result.Removed = removed.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Imported = len(windows)
+	log.Printf("Maintenance calendar %s: %d windows imported, %d removed, %d skipped",
+		calendar.Name, result.Imported, result.Removed, result.Skipped)
+	return result, nil
+}
+
+func (s *CalendarSync) fetch(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid calendar URL: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch calendar: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch calendar: HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCalendarSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read calendar: %w", err)
+	}
+	return string(body), nil
+}
+
+// maintenanceWindow is one occurrence of a calendar event
+type maintenanceWindow struct {
+	ExternalID string
+	Summary    string
+	Start      time.Time
+	End        time.Time
+	Sources    []string
+	Tags       []string
+}
+
+// icalProperty is a content line: NAME;PARAM=VALUE:value
+type icalProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// icalEvent is a parsed VEVENT
+type icalEvent struct {
+	UID          string
+	Summary      string
+	Description  string
+	Categories   []string
+	Status       string
+	Start        time.Time
+	End          time.Time
+	RRule        string
+	ExDates      map[int64]bool
+	RecurrenceID *time.Time
+}
+
+// parseMaintenanceWindows returns the windows of an iCal calendar that
+// haven't ended by from, expanding recurring events up to until. It reports
+// how many events were skipped for having no sources or tags.
+func parseMaintenanceWindows(body string, from, until time.Time) ([]maintenanceWindow, int, error) {
+	events, err := parseICalEvents(body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Modified occurrences of a recurring event replace the occurrence
+	// at their RECURRENCE-ID
+	overrides := make(map[string]map[int64]bool)
+	for _, event := range events {
+		if event.RecurrenceID != nil {
+			if overrides[event.UID] == nil {
+				overrides[event.UID] = make(map[int64]bool)
+			}
+			overrides[event.UID][event.RecurrenceID.Unix()] = true
+		}
+	}
+
+	var windows []maintenanceWindow
+	skipped := 0
+	for _, event := range events {
+		if strings.EqualFold(event.Status, "CANCELLED") {
+			continue
+		}
+		sources, tags := maintenanceScope(event)
+		if len(sources) == 0 && len(tags) == 0 {
+			log.Printf("Skipping maintenance event %q (%s): no sources or tags", event.Summary, event.UID)
+			skipped++
+			continue
+		}
+
+		var starts []time.Time
+		switch {
+		case event.RecurrenceID != nil:
+			starts = []time.Time{event.Start}
+		case event.RRule != "":
+			starts, err = expandRRule(event.RRule, event.Start, until)
+			if err != nil {
+				log.Printf("Maintenance event %q (%s): %v; importing its first occurrence only", event.Summary, event.UID, err)
+				starts = []time.Time{event.Start}
+			}
+		default:
+			starts = []time.Time{event.Start}
+		}
+
+		duration := event.End.Sub(event.Start)
+		for _, start := range starts {
+			if event.ExDates[start.Unix()] {
+				continue
+			}
+			if event.RecurrenceID == nil && event.RRule != "" && overrides[event.UID][start.Unix()] {
+				continue
+			}
+			end := start.Add(duration)
+			if !end.After(from) {
+				continue
+			}
+
+			externalID := event.UID
+			if event.RRule != "" || event.RecurrenceID != nil {
+				occurrence := start
+				if event.RecurrenceID != nil {
+					occurrence = *event.RecurrenceID
+				}
+				externalID += "/" + occurrence.UTC().Format(time.RFC3339)
+			}
+			windows = append(windows, maintenanceWindow{
+				ExternalID: externalID,
+				Summary:    event.Summary,
+				Start:      start.UTC(),
+				End:        end.UTC(),
+				Sources:    sources,
+				Tags:       tags,
+			})
+		}
+	}
+	return windows, skipped, nil
+}
+
+var scopeLine = regexp.MustCompile(`(?i)^\s*(sources?|tags?)\s*:\s*(.*)$`)
+
+// maintenanceScope reads an event's sources and tags from its description
+// and categories
+func maintenanceScope(event icalEvent) (sources, tags []string) {
+	for _, line := range strings.Split(event.Description, "\n") {
+		m := scopeLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		values := splitList(m[2])
+		if strings.HasPrefix(strings.ToLower(m[1]), "source") {
+			sources = append(sources, values...)
+		} else {
+			tags = append(tags, values...)
+		}
+	}
+	tags = append(tags, event.Categories...)
+	return sources, tags
+}
+
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// parseICalEvents parses the VEVENTs of an iCal (RFC 5545) document
+func parseICalEvents(body string) ([]icalEvent, error) {
+	// Unfold continuation lines
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	body = strings.ReplaceAll(body, "\n ", "")
+	body = strings.ReplaceAll(body, "\n\t", "")
+
+	var events []icalEvent
+	var current *icalEvent
+	var rawStart, rawEnd, rawDuration *icalProperty
+	sawCalendar := false
+	for _, line := range strings.Split(body, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		prop := parseICalLine(line)
+		switch {
+		case prop.Name == "BEGIN" && strings.EqualFold(prop.Value, "VCALENDAR"):
+			sawCalendar = true
+		case prop.Name == "BEGIN" && strings.EqualFold(prop.Value, "VEVENT"):
+			current = &icalEvent{ExDates: make(map[int64]bool)}
+			rawStart, rawEnd, rawDuration = nil, nil, nil
+		case prop.Name == "END" && strings.EqualFold(prop.Value, "VEVENT") && current != nil:
+			if err := finishICalEvent(current, rawStart, rawEnd, rawDuration); err != nil {
+				log.Printf("Skipping calendar event %q (%s): %v", current.Summary, current.UID, err)
+			} else {
+				events = append(events, *current)
+			}
+			current = nil
+		case current == nil:
+			// Calendar-level property or another component
+		case prop.Name == "UID":
+			current.UID = prop.Value
+		case prop.Name == "SUMMARY":
+			current.Summary = unescapeICalText(prop.Value)
+		case prop.Name == "DESCRIPTION":
+			current.Description = unescapeICalText(prop.Value)
+		case prop.Name == "CATEGORIES":
+			current.Categories = append(current.Categories, splitList(unescapeICalText(prop.Value))...)
+		case prop.Name == "STATUS":
+			current.Status = prop.Value
+		case prop.Name == "RRULE":
+			current.RRule = prop.Value
+		case prop.Name == "DTSTART":
+			p := prop
+			rawStart = &p
+		case prop.Name == "DTEND":
+			p := prop
+			rawEnd = &p
+		case prop.Name == "DURATION":
+			p := prop
+			rawDuration = &p
+		case prop.Name == "EXDATE":
+			for _, v := range strings.Split(prop.Value, ",") {
+				if t, _, err := parseICalTime(icalProperty{Params: prop.Params, Value: v}); err == nil {
+					current.ExDates[t.Unix()] = true
+				}
+			}
+		case prop.Name == "RECURRENCE-ID":
+			if t, _, err := parseICalTime(prop); err == nil {
+				current.RecurrenceID = &t
+			}
+		}
+	}
+	if !sawCalendar {
+		return nil, fmt.Errorf("not an iCal calendar")
+	}
+	return events, nil
+}
+
+func finishICalEvent(event *icalEvent, rawStart, rawEnd, rawDuration *icalProperty) error {
+	if event.UID == "" {
+		return fmt.Errorf("missing UID")
+	}
+	if rawStart == nil {
+		return fmt.Errorf("missing DTSTART")
+	}
+	start, allDay, err := parseICalTime(*rawStart)
+	if err != nil {
+		return err
+	}
+	event.Start = start
+
+	switch {
+	case rawEnd != nil:
+		if event.End, _, err = parseICalTime(*rawEnd); err != nil {
+			return err
+		}
+	case rawDuration != nil:
+		d, err := parseICalDuration(rawDuration.Value)
+		if err != nil {
+			return err
+		}
+		event.End = start.Add(d)
+	case allDay:
+		event.End = start.AddDate(0, 0, 1)
+	default:
+		event.End = start
+	}
+	if !event.End.After(event.Start) {
+		return fmt.Errorf("window has no duration")
+	}
+	return nil
+}
+
+// parseICalLine splits a content line into name, parameters and value
+func parseICalLine(line string) icalProperty {
+	prop := icalProperty{Params: make(map[string]string)}
+	inQuotes := false
+	split := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		} else if r == ':' && !inQuotes {
+			split = i
+			break
+		}
+	}
+	head := line
+	if split >= 0 {
+		head, prop.Value = line[:split], line[split+1:]
+	}
+	parts := strings.Split(head, ";")
+	prop.Name = strings.ToUpper(strings.TrimSpace(parts[0]))
+	for _, param := range parts[1:] {
+		if k, v, ok := strings.Cut(param, "="); ok {
+			prop.Params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return prop
+}
+
+func unescapeICalText(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
+
+// parseICalTime parses a DATE or DATE-TIME value, in its TZID when given.
+// Reports whether the value was a date (an all-day event).
+func parseICalTime(prop icalProperty) (time.Time, bool, error) {
+	value := strings.TrimSpace(prop.Value)
+	loc := time.UTC
+	if tzid := prop.Params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		} else {
+			log.Printf("Unknown calendar time zone %q, using UTC", tzid)
+		}
+	}
+
+	if prop.Params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date %q", value)
+		}
+		return t, true, nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date-time %q", value)
+		}
+		return t, false, nil
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date-time %q", value)
+	}
+	return t, false, nil
+}
+
+var icalDuration = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseICalDuration parses a DURATION value such as "PT2H30M" or "P1D"
+func parseICalDuration(value string) (time.Duration, error) {
+	m := icalDuration.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil || m[1] == "-" {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+2] == "" {
+			continue
+		}
+		n, _ := strconv.Atoi(m[i+2])
+		d += time.Duration(n) * unit
+	}
+	return d, nil
+}
+
+var icalWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// expandRRule lists the occurrence starts of a recurring event up to until.
+// DAILY, WEEKLY (with BYDAY) and MONTHLY rules with INTERVAL, COUNT and
+// UNTIL are supported.
+func expandRRule(rrule string, start, until time.Time) ([]time.Time, error) {
+	parts := make(map[string]string)
+	for _, part := range strings.Split(rrule, ";") {
+		if k, v, ok := strings.Cut(part, "="); ok {
+			parts[strings.ToUpper(k)] = strings.ToUpper(v)
+		}
+	}
+
+	interval := 1
+	if v, ok := parts["INTERVAL"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid RRULE INTERVAL %q", v)
+		}
+		interval = n
+	}
+	count := -1
+	if v, ok := parts["COUNT"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid RRULE COUNT %q", v)
+		}
+		count = n
+	}
+	if v, ok := parts["UNTIL"]; ok {
+		t, _, err := parseICalTime(icalProperty{Params: map[string]string{}, Value: v})
+		if err != nil {
+			return nil, fmt.Errorf("invalid RRULE UNTIL %q", v)
+		}
+		if t.Before(until) {
+			until = t
+		}
+	}
+	for _, unsupported := range []string{"BYMONTHDAY", "BYSETPOS", "BYMONTH", "BYHOUR", "BYWEEKNO", "BYYEARDAY"} {
+		if _, ok := parts[unsupported]; ok {
+			return nil, fmt.Errorf("unsupported RRULE part %s", unsupported)
+		}
+	}
+
+	var days []time.Weekday
+	if v, ok := parts["BYDAY"]; ok {
+		if parts["FREQ"] != "WEEKLY" {
+			return nil, fmt.Errorf("BYDAY is only supported for WEEKLY rules")
+		}
+		for _, day := range strings.Split(v, ",") {
+			wd, ok := icalWeekdays[day]
+			if !ok {
+				return nil, fmt.Errorf("unsupported RRULE BYDAY %q", day)
+			}
+			days = append(days, wd)
+		}
+		sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+	}
+
+	var starts []time.Time
+	add := func(t time.Time) bool {
+		if t.After(until) || count == 0 {
+			return false
+		}
+		if !t.Before(start) {
+			starts = append(starts, t)
+			if count > 0 {
+				count--
+			}
+		}
+		return true
+	}
+
+	switch parts["FREQ"] {
+	case "DAILY":
+		for t := start; add(t); t = t.AddDate(0, 0, interval) {
+		}
+	case "WEEKLY":
+		if len(days) == 0 {
+			for t := start; add(t); t = t.AddDate(0, 0, 7*interval) {
+			}
+			break
+		}
+		weekStart := start.AddDate(0, 0, -int(start.Weekday()))
+		for week := weekStart; ; week = week.AddDate(0, 0, 7*interval) {
+			more := true
+			for _, day := range days {
+				if more = add(week.AddDate(0, 0, int(day))); !more {
+					break
+				}
+			}
+			if !more {
+				break
+			}
+		}
+	case "MONTHLY":
+		for i := 0; ; i += interval {
+			t := start.AddDate(0, i, 0)
+			if t.Day() != start.Day() {
+				// No such day this month
+				if t.After(until) {
+					break
+				}
+				continue
+			}
+			if !add(t) {
+				break
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported RRULE FREQ %q", parts["FREQ"])
+	}
+	return starts, nil
+}
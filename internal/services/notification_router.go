@@ -0,0 +1,614 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// defaultNotifyChannel is used when no route matches and the rule names no channel
+const defaultNotifyChannel = "console"
+
+// NotificationRoutesFile is the YAML format of a routes file
+type NotificationRoutesFile struct {
+	Routes []struct {
+		ID               string   `yaml:"id"`
+		Name             string   `yaml:"name"`
+		Priority         *int     `yaml:"priority"`
+		Disabled         bool     `yaml:"disabled"`
+		Stop             bool     `yaml:"stop"`
+		Severities       []string `yaml:"severities"`
+		Categories       []string `yaml:"categories"`
+		Tags             []string `yaml:"tags"`
+		Teams            []string `yaml:"teams"`
+		Targets          []string `yaml:"targets"`
+		Fallbacks        []string `yaml:"fallbacks"`
+		QuietStart       string   `yaml:"quiet_start"`
+		QuietEnd         string   `yaml:"quiet_end"`
+		QuietTimezone    string   `yaml:"quiet_timezone"`
+		QuietMinSeverity string   `yaml:"quiet_min_severity"`
+		DedupWindow      int      `yaml:"dedup_window"`
+		DigestSeverities []string `yaml:"digest_severities"`
+		DigestInterval   string   `yaml:"digest_interval"`
+	} `yaml:"routes"`
+}
+
+// Notification is a notify request with the attributes routes match on
+type Notification struct {
+	IncidentID string
+	Severity   string
+	Category   string
+	Tags       []string
+	Team       string
+	Message    string
+	Channel    string // rule's channel, used when no route matches
+	RequestID  string // request that raised it, recorded on delivery action logs
+
+	// digestID marks summary deliveries sent by SendDigests
+	digestID string
+}
+
+// NotificationRouter delivers notifications to the targets of matching routes
+type NotificationRouter struct {
+	db      *gorm.DB
+	actions *ActionRegistry
+}
+
+// NewNotificationRouter creates a new notification router
+func NewNotificationRouter(db *gorm.DB, actions *ActionRegistry) *NotificationRouter {
+	return &NotificationRouter{
+		db:      db,
+		actions: actions,
+	}
+}
+
+// LoadRoutes upserts routes from all YAML files in the directory, so file
+// routes replace API edits to the same route ID on restart
+func (r *NotificationRouter) LoadRoutes(routesDir string) error {
+	files, err := filepath.Glob(filepath.Join(routesDir, "*.yaml"))
+	if err != nil {
+		return fmt.Errorf("failed to glob notification routes: %w", err)
+	}
+
+	files2, err := filepath.Glob(filepath.Join(routesDir, "*.yml"))
+	if err != nil {
+		return fmt.Errorf("failed to glob notification routes: %w", err)
+	}
+	files = append(files, files2...)
+
+	loaded := 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Printf("Warning: failed to read routes file %s: %v", file, err)
+			continue
+		}
+
+		var routesFile NotificationRoutesFile
+		if err := yaml.Unmarshal(data, &routesFile); err != nil {
+			log.Printf("Warning: failed to parse routes file %s: %v", file, err)
+			continue
+		}
+
+		for _, spec := range routesFile.Routes {
+			route := models.NotificationRoute{
+				RouteID:          spec.ID,
+				Name:             spec.Name,
+				Priority:         100,
+				Disabled:         spec.Disabled,
+				Stop:             spec.Stop,
+				Severities:       spec.Severities,
+				Categories:       spec.Categories,
+				Tags:             spec.Tags,
+				Teams:            spec.Teams,
+				Targets:          spec.Targets,
+				Fallbacks:        spec.Fallbacks,
+				QuietStart:       spec.QuietStart,
+				QuietEnd:         spec.QuietEnd,
+				QuietTimezone:    spec.QuietTimezone,
+				QuietMinSeverity: models.SeverityLevel(spec.QuietMinSeverity),
+				DedupWindow:      spec.DedupWindow,
+				DigestSeverities: spec.DigestSeverities,
+				DigestInterval:   spec.DigestInterval,
+			}
+			if spec.Priority != nil {
+				route.Priority = *spec.Priority
+			}
+
+			if err := ValidateNotificationRoute(&route); err != nil {
+				log.Printf("Warning: invalid route %q in %s: %v", spec.ID, file, err)
+				continue
+			}
+			if err := r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&route).Error; err != nil {
+				log.Printf("Warning: failed to save route %s: %v", spec.ID, err)
+				continue
+			}
+			loaded++
+		}
+	}
+
+	log.Printf("Loaded %d notification routes", loaded)
+	return nil
+}
+
+// ValidateNotificationRoute checks a route's ID, targets, severities and quiet hours
+func ValidateNotificationRoute(route *models.NotificationRoute) error {
+	if route.RouteID == "" {
+		return fmt.Errorf("route id is required")
+	}
+	if len(route.Targets) == 0 {
+		return fmt.Errorf("at least one target is required")
+	}
+	for _, severity := range route.Severities {
+		if models.SeverityLevel(severity).Rank() < 0 {
+			return fmt.Errorf("unknown severity %q", severity)
+		}
+	}
+	if route.QuietMinSeverity != "" && route.QuietMinSeverity.Rank() < 0 {
+		return fmt.Errorf("unknown quiet_min_severity %q", route.QuietMinSeverity)
+	}
+	if route.DedupWindow < 0 {
+		return fmt.Errorf("dedup_window must not be negative")
+	}
+	for _, severity := range route.DigestSeverities {
+		if models.SeverityLevel(severity).Rank() < 0 {
+			return fmt.Errorf("unknown digest severity %q", severity)
+		}
+	}
+	if len(route.DigestSeverities) > 0 {
+		if _, err := parseDigestInterval(route.DigestInterval); err != nil {
+			return err
+		}
+	}
+
+	if (route.QuietStart == "") != (route.QuietEnd == "") {
+		return fmt.Errorf("quiet_start and quiet_end must be set together")
+	}
+	if route.QuietStart != "" {
+		if _, err := parseClock(route.QuietStart); err != nil {
+			return err
+		}
+		if _, err := parseClock(route.QuietEnd); err != nil {
+			return err
+		}
+		if _, err := time.LoadLocation(route.QuietTimezone); err != nil {
+			return fmt.Errorf("invalid quiet_timezone %q: %w", route.QuietTimezone, err)
+		}
+	}
+	return nil
+}
+
+// NotificationFromParams reads a notification from notify parameters as
+// queued by detection
+func NotificationFromParams(params map[string]interface{}) Notification {
+	n := Notification{
+		IncidentID: getStringParam(params, "incident_id", ""),
+		Severity:   getStringParam(params, "severity", ""),
+		Category:   getStringParam(params, "category", ""),
+		Team:       getStringParam(params, "team", ""),
+		Message:    getStringParam(params, "message", "Notification"),
+		Channel:    getStringParam(params, "channel", ""),
+		RequestID:  getStringParam(params, RequestIDField, ""),
+	}
+	switch tags := params["tags"].(type) {
+	case []string:
+		n.Tags = tags
+	case []interface{}:
+		for _, tag := range tags {
+			n.Tags = append(n.Tags, fmt.Sprintf("%v", tag))
+		}
+	}
+	return n
+}
+
+// Dispatch delivers a notification to every matching route. Without a
+// matching route it goes to the rule's channel. It fails only when nothing
+// could be delivered, so the outbox retries.
+func (r *NotificationRouter) Dispatch(n Notification) error {
+	var routes []models.NotificationRoute
+	if err := r.db.Where("disabled = ?", false).Order("priority ASC, route_id ASC").Find(&routes).Error; err != nil {
+		return fmt.Errorf("failed to load notification routes: %w", err)
+	}
+
+	var matched []models.NotificationRoute
+	for _, route := range routes {
+		if routeMatches(route, n) {
+			matched = append(matched, route)
+			if route.Stop {
+				break
+			}
+		}
+	}
+
+	if len(matched) == 0 {
+		channel := n.Channel
+		if channel == "" {
+			channel = defaultNotifyChannel
+		}
+		if r.deliver(nil, n, channel, false, "") {
+			return nil
+		}
+		return fmt.Errorf("failed to deliver notification to %s", channel)
+	}
+
+	now := time.Now().UTC()
+	attempted, delivered := 0, 0
+	for i := range matched {
+		route := &matched[i]
+		dedupKey := notificationDedupKey(route.RouteID, n)
+
+		if route.DedupWindow > 0 {
+			var count int64
+			r.db.Model(&models.NotificationDelivery{}).
+				Where("dedup_key = ? AND status IN ? AND created_at > ?", dedupKey,
+					[]models.DeliveryStatus{models.DeliverySent, models.DeliveryDigestPending, models.DeliveryDigested},
+					now.Add(-time.Duration(route.DedupWindow)*time.Second)).
+				Count(&count)
+			if count > 0 {
+				r.record(route, n, "", false, models.DeliveryDuplicate, dedupKey, nil)
+				continue
+			}
+		}
+
+		// Low-severity notifications wait for the route's next digest
+		if containsFold(route.DigestSeverities, n.Severity) {
+			r.record(route, n, "", false, models.DeliveryDigestPending, dedupKey, nil)
+			continue
+		}
+
+		if inQuietHours(route, now) && !bypassesQuietHours(route, n) {
+			r.record(route, n, "", false, models.DeliveryQuietHours, dedupKey, nil)
+			continue
+		}
+
+		attempted++
+		sent := false
+		for _, target := range route.Targets {
+			if r.deliver(route, n, target, false, dedupKey) {
+				sent = true
+			}
+		}
+		if !sent {
+			for _, target := range route.Fallbacks {
+				if r.deliver(route, n, target, true, dedupKey) {
+					sent = true
+				}
+			}
+		}
+		if sent {
+			delivered++
+		}
+	}
+
+	if attempted > 0 && delivered == 0 {
+		return fmt.Errorf("notification could not be delivered on any route")
+	}
+	return nil
+}
+
+// SendDirect sends a notification to one target, bypassing routes, and
+// records the delivery. Reports whether it was sent.
+func (r *NotificationRouter) SendDirect(n Notification, target string) bool {
+	return r.deliver(nil, n, target, false, "")
+}
+
+// deliver sends to one target through the notify action and records the outcome
+func (r *NotificationRouter) deliver(route *models.NotificationRoute, n Notification, target string, fallback bool, dedupKey string) bool {
+	if role, ok := strings.CutPrefix(target, roleTargetPrefix); ok {
+		return r.deliverToRole(route, n, role, fallback, dedupKey)
+	}
+	action, params := targetAction(target, n)
+	_, err := r.actions.ExecuteContext(WithRequestID(context.Background(), n.RequestID), action, params)
+	if err != nil {
+		log.Printf("Notification to %s failed: %v", target, err)
+		r.record(route, n, target, fallback, models.DeliveryFailed, dedupKey, err)
+		return false
+	}
+	r.record(route, n, target, fallback, models.DeliverySent, dedupKey, nil)
+	return true
+}
+
+// deliverToRole sends to the notification target of each current holder of
+// an incident role. It fails, so route fallbacks apply, when no holder has a
+// target set.
+func (r *NotificationRouter) deliverToRole(route *models.NotificationRoute, n Notification, role string, fallback bool, dedupKey string) bool {
+	target := roleTargetPrefix + role
+	if n.IncidentID == "" {
+		r.record(route, n, target, fallback, models.DeliveryFailed, dedupKey, fmt.Errorf("role targets need an incident"))
+		return false
+	}
+	holders, err := roleHolders(r.db, n.IncidentID, role)
+	if err != nil {
+		r.record(route, n, target, fallback, models.DeliveryFailed, dedupKey, err)
+		return false
+	}
+	var prefs []models.UserPreference
+	if len(holders) > 0 {
+		if err := r.db.Where("user IN ? AND notify_target <> ''", holders).Find(&prefs).Error; err != nil {
+			r.record(route, n, target, fallback, models.DeliveryFailed, dedupKey, err)
+			return false
+		}
+	}
+	if len(prefs) == 0 {
+		r.record(route, n, target, fallback, models.DeliveryFailed, dedupKey,
+			fmt.Errorf("no %s of incident %s has a notification target", role, n.IncidentID))
+		return false
+	}
+	sent := false
+	for _, pref := range prefs {
+		// A holder's own target can't name a role, which could loop
+		if strings.HasPrefix(pref.NotifyTarget, roleTargetPrefix) {
+			continue
+		}
+		if r.deliver(route, n, pref.NotifyTarget, fallback, dedupKey) {
+			sent = true
+		}
+	}
+	return sent
+}
+
+// targetAction maps a route target to the action that delivers it:
+// "sms:<number>" texts, "voice:<number>" phones, anything else is a notify channel
+func targetAction(target string, n Notification) (string, map[string]interface{}) {
+	params := map[string]interface{}{
+		"message": n.Message,
+	}
+	if n.IncidentID != "" {
+		params["incident_id"] = n.IncidentID
+	}
+
+	switch {
+	case strings.HasPrefix(target, "sms:"):
+		params["to"] = strings.TrimPrefix(target, "sms:")
+		return "sms_notify", params
+	case strings.HasPrefix(target, "voice:"):
+		params["to"] = strings.TrimPrefix(target, "voice:")
+		return "voice_call", params
+	default:
+		params["channel"] = target
+		return "notify", params
+	}
+}
+
+// record stores a delivery outcome
+func (r *NotificationRouter) record(route *models.NotificationRoute, n Notification, target string, fallback bool, status models.DeliveryStatus, dedupKey string, err error) {
+	delivery := &models.NotificationDelivery{
+		Target:   target,
+		Fallback: fallback,
+		Status:   status,
+		Message:  n.Message,
+		DedupKey: dedupKey,
+	}
+	if route != nil {
+		delivery.RouteID = &route.RouteID
+	}
+	if n.IncidentID != "" {
+		incidentID := n.IncidentID
+		delivery.IncidentID = &incidentID
+	}
+	if err != nil {
+		errMsg := err.Error()
+		delivery.Error = &errMsg
+	}
+	if n.digestID != "" {
+		delivery.DigestID = &n.digestID
+	}
+
+	if err := r.db.Create(delivery).Error; err != nil {
+		log.Printf("Failed to record notification delivery: %v", err)
+	}
+}
+
+// maxDigestLines caps how many notifications a digest lists individually
+const maxDigestLines = 50
+
+// SendDigests sends one summary per target for each route whose oldest
+// pending digest notification is at least a digest interval old. Digests
+// are held during the route's quiet hours. Intended to run as a leader-only
+// scheduled job.
+func (r *NotificationRouter) SendDigests() error {
+	var routes []models.NotificationRoute
+	if err := r.db.Where("disabled = ?", false).Order("priority ASC, route_id ASC").Find(&routes).Error; err != nil {
+		return fmt.Errorf("failed to load notification routes: %w", err)
+	}
+
+	now := time.Now().UTC()
+	var failed []string
+	for i := range routes {
+		route := &routes[i]
+		if len(route.DigestSeverities) == 0 {
+			continue
+		}
+		interval, err := parseDigestInterval(route.DigestInterval)
+		if err != nil {
+			continue
+		}
+
+		var pending []models.NotificationDelivery
+		if err := r.db.Where("route_id = ? AND status = ?", route.RouteID, models.DeliveryDigestPending).
+			Order("created_at ASC").Find(&pending).Error; err != nil {
+			return fmt.Errorf("failed to load pending digest notifications: %w", err)
+		}
+		if len(pending) == 0 || now.Sub(pending[0].CreatedAt) < interval || inQuietHours(route, now) {
+			continue
+		}
+
+		digest := Notification{
+			Message:  digestMessage(route, pending),
+			digestID: uuid.New().String(),
+		}
+		sent := false
+		for _, target := range route.Targets {
+			if r.deliver(route, digest, target, false, "") {
+				sent = true
+			}
+		}
+		if !sent {
+			for _, target := range route.Fallbacks {
+				if r.deliver(route, digest, target, true, "") {
+					sent = true
+				}
+			}
+		}
+		if !sent {
+			failed = append(failed, route.RouteID)
+			continue
+		}
+
+		ids := make([]string, 0, len(pending))
+		for _, delivery := range pending {
+			ids = append(ids, delivery.DeliveryID)
+		}
+		if err := r.db.Model(&models.NotificationDelivery{}).Where("delivery_id IN ?", ids).
+			Updates(map[string]interface{}{"status": models.DeliveryDigested, "digest_id": digest.digestID}).Error; err != nil {
+			return fmt.Errorf("failed to mark digest notifications: %w", err)
+		}
+		log.Printf("Sent digest %s for route %s with %d notifications", digest.digestID, route.RouteID, len(pending))
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to deliver digests for routes: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// digestMessage summarizes pending notifications for a route
+func digestMessage(route *models.NotificationRoute, pending []models.NotificationDelivery) string {
+	name := route.Name
+	if name == "" {
+		name = route.RouteID
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Digest for %s: %d notifications since %s", name, len(pending), pending[0].CreatedAt.UTC().Format(time.RFC3339))
+	for i, delivery := range pending {
+		if i == maxDigestLines {
+			fmt.Fprintf(&b, "\n... and %d more", len(pending)-maxDigestLines)
+			break
+		}
+		fmt.Fprintf(&b, "\n- %s", delivery.Message)
+		if delivery.IncidentID != nil {
+			fmt.Fprintf(&b, " (incident %s)", *delivery.IncidentID)
+		}
+	}
+	return b.String()
+}
+
+// parseDigestInterval accepts "hourly", "daily" or a Go duration of at least a minute
+func parseDigestInterval(s string) (time.Duration, error) {
+	switch s {
+	case "hourly":
+		return time.Hour, nil
+	case "daily":
+		return 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < time.Minute {
+		return 0, fmt.Errorf("invalid digest_interval %q: expected hourly, daily or a duration of at least 1m", s)
+	}
+	return d, nil
+}
+
+// routeMatches reports whether every non-empty match list of the route
+// contains the notification's attribute
+func routeMatches(route models.NotificationRoute, n Notification) bool {
+	if len(route.Severities) > 0 && !containsFold(route.Severities, n.Severity) {
+		return false
+	}
+	if len(route.Categories) > 0 && !containsFold(route.Categories, n.Category) {
+		return false
+	}
+	if len(route.Teams) > 0 && !containsFold(route.Teams, n.Team) {
+		return false
+	}
+	if len(route.Tags) > 0 {
+		found := false
+		for _, tag := range n.Tags {
+			if containsFold(route.Tags, tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold reports whether values contains s, ignoring case
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// notificationDedupKey identifies repeats of a notification on a route
+func notificationDedupKey(routeID string, n Notification) string {
+	if n.IncidentID != "" {
+		return routeID + ":" + n.IncidentID
+	}
+	sum := sha256.Sum256([]byte(n.Message))
+	return routeID + ":" + hex.EncodeToString(sum[:8])
+}
+
+// inQuietHours reports whether now falls within the route's quiet hours;
+// windows may wrap past midnight
+func inQuietHours(route *models.NotificationRoute, now time.Time) bool {
+	if route.QuietStart == "" {
+		return false
+	}
+	start, err := parseClock(route.QuietStart)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(route.QuietEnd)
+	if err != nil {
+		return false
+	}
+	loc, err := time.LoadLocation(route.QuietTimezone)
+	if err != nil {
+		return false
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// bypassesQuietHours reports whether the notification is severe enough to
+// be sent during quiet hours
+func bypassesQuietHours(route *models.NotificationRoute, n Notification) bool {
+	if route.QuietMinSeverity == "" {
+		return false
+	}
+	return models.SeverityLevel(n.Severity).Rank() >= route.QuietMinSeverity.Rank()
+}
+
+// parseClock parses "HH:MM" into minutes after midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
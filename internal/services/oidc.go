@@ -0,0 +1,577 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+var (
+	// ErrOIDCLogin is returned when a sign-in can't be completed: a bad or
+	// expired login state, a failed code exchange or an invalid ID token
+	ErrOIDCLogin = errors.New("sign-in failed")
+	// ErrOIDCNoRole is returned when a user's groups map to no role and
+	// there is no default role
+	ErrOIDCNoRole = errors.New("no role is granted to this user's groups")
+)
+
+const (
+	// oidcLoginTimeout is how long a sign-in may take at the identity
+	// provider
+	oidcLoginTimeout = 10 * time.Minute
+	// oidcClockSkew is the leeway given to ID token expiry
+	oidcClockSkew = time.Minute
+)
+
+// OIDCConfig configures sign-in through an OpenID Connect provider
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// GroupsClaim and UsernameClaim name the ID token claims holding the
+	// user's groups and principal name
+	GroupsClaim   string
+	UsernameClaim string
+	// GroupRoles maps identity provider groups to roles; a user gets the
+	// highest role of their groups, or DefaultRole (none when empty)
+	GroupRoles  map[string]Role
+	DefaultRole Role
+}
+
+// ParseGroupRoles parses a group to role list such as
+// "ir-admins=admin,sec-oncall=responder"
+func ParseGroupRoles(spec string) (map[string]Role, error) {
+	roles := make(map[string]Role)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		group, name, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(group) == "" {
+			return nil, fmt.Errorf("invalid group role %q: expected group=role", entry)
+		}
+		role, err := ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid group role %q: %w", entry, err)
+		}
+		roles[strings.TrimSpace(group)] = role
+	}
+	return roles, nil
+}
+
+// OIDCIdentity is a user as their ID token describes them
+type OIDCIdentity struct {
+	Issuer   string   `json:"issuer"`
+	Subject  string   `json:"subject"`
+	Username string   `json:"username"`
+	Email    string   `json:"email"`
+	Name     string   `json:"name"`
+	Groups   []string `json:"groups"`
+	Role     Role     `json:"role"`
+}
+
+// OIDCLoginState carries a sign-in across the identity provider round trip,
+// in a signed cookie
+type OIDCLoginState struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"` // PKCE code verifier
+	Redirect string `json:"redirect"`
+	Expires  int64  `json:"expires"`
+}
+
+// oidcDiscovery is the part of the provider's discovery document used
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDC signs users in with the authorization code flow (with PKCE) and
+// provisions them on first login. Discovery and signing keys are fetched
+// from the issuer when first needed.
+type OIDC struct {
+	db       *gorm.DB
+	cfg      OIDCConfig
+	client   *http.Client
+	stateKey []byte
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]crypto.PublicKey
+}
+
+// NewOIDC creates an OIDC sign-in provider
+func NewOIDC(db *gorm.DB, cfg OIDCConfig) *OIDC {
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = "email"
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "profile", "email"}
+	}
+	// Login states are signed with a key every instance derives from the
+	// client secret; a public client's key is per process, so its sign-ins
+	// must complete on the instance that started them
+	stateKey := make([]byte, 32)
+	if cfg.ClientSecret != "" {
+		sum := sha256.Sum256([]byte("oidc-login-state:" + cfg.ClientSecret))
+		stateKey = sum[:]
+	} else {
+		_, _ = rand.Read(stateKey)
+	}
+	return &OIDC{db: db, cfg: cfg, client: &http.Client{Timeout: 15 * time.Second}, stateKey: stateKey}
+}
+
+// NewLoginState starts a sign-in that returns to redirect
+func (o *OIDC) NewLoginState(redirect string) OIDCLoginState {
+	return OIDCLoginState{
+		State:    randomToken(),
+		Nonce:    randomToken(),
+		Verifier: randomToken(),
+		Redirect: redirect,
+		Expires:  time.Now().Add(oidcLoginTimeout).Unix(),
+	}
+}
+
+// SealLoginState encodes and signs a login state for a cookie
+func (o *OIDC) SealLoginState(state OIDCLoginState) string {
+	payload, _ := json.Marshal(state)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + o.sign(encoded)
+}
+
+// OpenLoginState verifies a sealed login state against the state the
+// provider returned
+func (o *OIDC) OpenLoginState(sealed, state string) (*OIDCLoginState, error) {
+	encoded, signature, ok := strings.Cut(sealed, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(o.sign(encoded))) {
+		return nil, fmt.Errorf("%w: invalid login state", ErrOIDCLogin)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid login state", ErrOIDCLogin)
+	}
+	var login OIDCLoginState
+	if err := json.Unmarshal(payload, &login); err != nil {
+		return nil, fmt.Errorf("%w: invalid login state", ErrOIDCLogin)
+	}
+	if time.Now().Unix() > login.Expires {
+		return nil, fmt.Errorf("%w: sign-in took too long, start again", ErrOIDCLogin)
+	}
+	if login.State == "" || !hmac.Equal([]byte(login.State), []byte(state)) {
+		return nil, fmt.Errorf("%w: state mismatch", ErrOIDCLogin)
+	}
+	return &login, nil
+}
+
+// sign MACs a login state
+func (o *OIDC) sign(value string) string {
+	mac := hmac.New(sha256.New, o.stateKey)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// AuthURL returns the provider URL that starts a sign-in
+func (o *OIDC) AuthURL(ctx context.Context, login OIDCLoginState) (string, error) {
+	discovery, err := o.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	challenge := sha256.Sum256([]byte(login.Verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {o.cfg.ClientID},
+		"redirect_uri":          {o.cfg.RedirectURL},
+		"scope":                 {strings.Join(o.cfg.Scopes, " ")},
+		"state":                 {login.State},
+		"nonce":                 {login.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems an authorization code and returns the verified identity
+// of the user who signed in
+func (o *OIDC) Exchange(ctx context.Context, code string, login *OIDCLoginState) (*OIDCIdentity, error) {
+	discovery, err := o.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {o.cfg.RedirectURL},
+		"code_verifier": {login.Verifier},
+	}
+	if o.cfg.ClientSecret == "" {
+		form.Set("client_id", o.cfg.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if o.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(o.cfg.ClientID), url.QueryEscape(o.cfg.ClientSecret))
+	}
+	data, err := doRequest(o.client, req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: token request: %v", ErrOIDCLogin, err)
+	}
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(data, &token); err != nil || token.IDToken == "" {
+		return nil, fmt.Errorf("%w: token response has no id_token", ErrOIDCLogin)
+	}
+
+	claims, err := o.verifyIDToken(ctx, token.IDToken, discovery)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLogin, err)
+	}
+	if nonce, _ := claims["nonce"].(string); nonce != login.Nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrOIDCLogin)
+	}
+	return o.identity(claims)
+}
+
+// identity reads a user and their role from verified ID token claims
+func (o *OIDC) identity(claims map[string]interface{}) (*OIDCIdentity, error) {
+	identity := &OIDCIdentity{}
+	identity.Issuer, _ = claims["iss"].(string)
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	identity.Name, _ = claims["name"].(string)
+	identity.Username, _ = claims[o.cfg.UsernameClaim].(string)
+	if identity.Username == "" {
+		identity.Username = identity.Subject
+	}
+	if identity.Subject == "" {
+		return nil, fmt.Errorf("%w: ID token has no subject", ErrOIDCLogin)
+	}
+	switch groups := claims[o.cfg.GroupsClaim].(type) {
+	case []interface{}:
+		for _, group := range groups {
+			if name, ok := group.(string); ok {
+				identity.Groups = append(identity.Groups, name)
+			}
+		}
+	case string:
+		identity.Groups = SplitList(groups)
+	}
+
+	identity.Role = o.cfg.DefaultRole
+	for _, group := range identity.Groups {
+		if role, ok := o.cfg.GroupRoles[group]; ok && role.Rank() > identity.Role.Rank() {
+			identity.Role = role
+		}
+	}
+	if identity.Role == "" {
+		return nil, ErrOIDCNoRole
+	}
+	return identity, nil
+}
+
+// Provision creates the user on their first login, or refreshes their
+// details and role from the identity provider
+func (o *OIDC) Provision(ctx context.Context, identity *OIDCIdentity) (*models.User, error) {
+	groups, _ := json.Marshal(identity.Groups)
+	now := time.Now().UTC()
+	var user models.User
+	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.First(&user, "issuer = ? AND subject = ?", identity.Issuer, identity.Subject).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to fetch user: %w", err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			var taken int64
+			if err := tx.Model(&models.User{}).Where("name = ?", identity.Username).Count(&taken).Error; err != nil {
+				return fmt.Errorf("failed to fetch user: %w", err)
+			}
+			if taken > 0 {
+				return fmt.Errorf("%w: user name %q belongs to another identity", ErrOIDCLogin, identity.Username)
+			}
+			user = models.User{Name: identity.Username, Issuer: identity.Issuer, Subject: identity.Subject}
+			log.Printf("Provisioning user %s (%s) from %s", identity.Username, identity.Role, identity.Issuer)
+		}
+		user.Email = identity.Email
+		user.DisplayName = identity.Name
+		user.Role = string(identity.Role)
+		user.Groups = string(groups)
+		user.LastLoginAt = &now
+		if err := tx.Save(&user).Error; err != nil {
+			return fmt.Errorf("failed to save user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// discover fetches the provider's discovery document, once
+func (o *OIDC) discover(ctx context.Context) (*oidcDiscovery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.discovery != nil {
+		return o.discovery, nil
+	}
+	endpoint := strings.TrimSuffix(o.cfg.IssuerURL, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery request: %w", err)
+	}
+	data, err := doRequest(o.client, req)
+	if err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	var discovery oidcDiscovery
+	if err := json.Unmarshal(data, &discovery); err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("OIDC discovery document is missing endpoints")
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(o.cfg.IssuerURL, "/") {
+		return nil, fmt.Errorf("OIDC discovery issuer %q doesn't match %q", discovery.Issuer, o.cfg.IssuerURL)
+	}
+	o.discovery = &discovery
+	return o.discovery, nil
+}
+
+// verifyIDToken checks an ID token's signature, issuer, audience and expiry
+// and returns its claims
+func (o *OIDC) verifyIDToken(ctx context.Context, raw string, discovery *oidcDiscovery) (map[string]interface{}, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed ID token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed ID token signature")
+	}
+	key, err := o.signingKey(ctx, discovery, header.Kid, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyJWTSignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		// The provider may have rotated the key under the same ID
+		if key, err = o.signingKey(ctx, discovery, header.Kid, true); err != nil {
+			return nil, err
+		}
+		if err := verifyJWTSignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+			return nil, err
+		}
+	}
+
+	var claims map[string]interface{}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if iss, _ := claims["iss"].(string); iss != discovery.Issuer {
+		return nil, fmt.Errorf("ID token issuer %q is not %q", iss, discovery.Issuer)
+	}
+	audienceOK := false
+	switch aud := claims["aud"].(type) {
+	case string:
+		audienceOK = aud == o.cfg.ClientID
+	case []interface{}:
+		for _, a := range aud {
+			if a == o.cfg.ClientID {
+				audienceOK = true
+			}
+		}
+	}
+	if !audienceOK {
+		return nil, fmt.Errorf("ID token is not for this client")
+	}
+	exp, _ := claims["exp"].(float64)
+	if time.Now().Add(-oidcClockSkew).After(time.Unix(int64(exp), 0)) {
+		return nil, fmt.Errorf("ID token has expired")
+	}
+	return claims, nil
+}
+
+// signingKey returns the provider key with kid, refetching the key set when
+// it's unknown or refresh is set so rotated keys are picked up
+func (o *OIDC) signingKey(ctx context.Context, discovery *oidcDiscovery, kid string, refresh bool) (crypto.PublicKey, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if key, ok := o.keys[kid]; ok && !refresh {
+		return key, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discovery.JWKSURI, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key set request: %w", err)
+	}
+	data, err := doRequest(o.client, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, err
+	}
+	o.keys = keys
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// parseJWKS reads the RSA and EC keys of a JSON Web Key Set by key ID
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid signing key set: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		switch jwk.Kty {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+			e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+			if errN != nil || errE != nil {
+				continue
+			}
+			keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch jwk.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			default:
+				continue
+			}
+			x, errX := base64.RawURLEncoding.DecodeString(jwk.X)
+			y, errY := base64.RawURLEncoding.DecodeString(jwk.Y)
+			if errX != nil || errY != nil {
+				continue
+			}
+			keys[jwk.Kid] = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		}
+	}
+	return keys, nil
+}
+
+// verifyJWTSignature checks a JWS signature over signed with the RS* or
+// ES* algorithm alg
+func verifyJWTSignature(alg string, key crypto.PublicKey, signed string, signature []byte) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported ID token algorithm %q", alg)
+	}
+	var h hash.Hash
+	var hashID crypto.Hash
+	switch alg[2:] {
+	case "256":
+		h, hashID = sha256.New(), crypto.SHA256
+	case "384":
+		h, hashID = sha512.New384(), crypto.SHA384
+	case "512":
+		h, hashID = sha512.New(), crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported ID token algorithm %q", alg)
+	}
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("ID token algorithm %q doesn't match its RSA key", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(k, hashID, digest, signature); err != nil {
+			return fmt.Errorf("invalid ID token signature")
+		}
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") || len(signature)%2 != 0 {
+			return fmt.Errorf("ID token algorithm %q doesn't match its EC key", alg)
+		}
+		half := len(signature) / 2
+		r := new(big.Int).SetBytes(signature[:half])
+		s := new(big.Int).SetBytes(signature[half:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return fmt.Errorf("invalid ID token signature")
+		}
+	default:
+		return fmt.Errorf("unsupported signing key")
+	}
+	return nil
+}
+
+// decodeJWTPart decodes a base64url JSON segment of a JWT
+func decodeJWTPart(segment string, out interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("malformed ID token")
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("malformed ID token")
+	}
+	return nil
+}
+
+// randomToken returns 32 random bytes, base64url encoded
+func randomToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
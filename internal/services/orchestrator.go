@@ -1,14 +1,22 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
 )
 
 // Playbook represents a response playbook loaded from YAML
@@ -20,6 +28,11 @@ type Playbook struct {
 		Version     string          `yaml:"version"`
 		Inputs      []PlaybookInput `yaml:"inputs"`
 		Steps       []PlaybookStep  `yaml:"steps"`
+
+		// Variables are available to steps as {{ vars.name }}; the profile
+		// in Environments selected for a run overrides them
+		Variables    map[string]interface{}            `yaml:"variables"`
+		Environments map[string]map[string]interface{} `yaml:"environments"`
 	} `yaml:"playbook"`
 }
 
@@ -37,24 +50,123 @@ type PlaybookStep struct {
 	Parameters map[string]interface{} `yaml:"parameters"`
 	OnFailure  string                 `yaml:"on_failure"`
 	Condition  string                 `yaml:"condition"`
+
+	// Manual steps become IncidentTasks for a human instead of running an action
+	Manual   bool   `yaml:"manual"`
+	Assignee string `yaml:"assignee"`
+	DueIn    string `yaml:"due_in"` // Go duration, e.g. "30m"
+
+	// Transform steps evaluate an expression, or an object of expressions,
+	// in-process instead of running an action; see compileTransform
+	Transform interface{} `yaml:"transform"`
+
+	// Outputs declares fields of the step's output beyond those in its
+	// action's schema, such as the JSON a script prints
+	Outputs []ActionOutput `yaml:"outputs"`
+
+	// SampleOutput stands in for the action's result in dry runs
+	SampleOutput map[string]interface{} `yaml:"sample_output"`
 }
 
 // Orchestrator handles playbook execution
 type Orchestrator struct {
-	db        *gorm.DB
+	db      *gorm.DB
+	actions *ActionRegistry
+	locks   *LockManager
+
+	mu        sync.RWMutex
 	playbooks map[string]Playbook
-	actions   *ActionRegistry
+
+	// defaultEnvironment is the profile used by runs that don't select one
+	defaultEnvironment string
+
+	quotas *ExecutionQuotas
+	gates  *ApprovalGates
+
+	// running tracks runs started in the background
+	running sync.WaitGroup
 }
 
 // NewOrchestrator creates a new orchestrator
-func NewOrchestrator(db *gorm.DB, actions *ActionRegistry) *Orchestrator {
+func NewOrchestrator(db *gorm.DB, actions *ActionRegistry, locks *LockManager) *Orchestrator {
 	return &Orchestrator{
 		db:        db,
 		playbooks: make(map[string]Playbook),
 		actions:   actions,
+		locks:     locks,
 	}
 }
 
+// SetDefaultEnvironment sets the environment profile used by runs that don't
+// select one, such as rule-triggered runs
+func (o *Orchestrator) SetDefaultEnvironment(name string) {
+	o.mu.Lock()
+	o.defaultEnvironment = name
+	o.mu.Unlock()
+}
+
+// SetQuotas limits the runs each rule and tenant may start per hour
+func (o *Orchestrator) SetQuotas(quotas *ExecutionQuotas) {
+	o.quotas = quotas
+}
+
+// SetApprovalGates holds actions for approval where the execution policy
+// requires it, and auto-remediation actions during change freezes
+func (o *Orchestrator) SetApprovalGates(gates *ApprovalGates) {
+	o.gates = gates
+}
+
+// runEnvironment returns the environment a run in ctx uses
+func (o *Orchestrator) runEnvironment(ctx context.Context) string {
+	if name := PlaybookEnvironmentFrom(ctx); name != "" {
+		return name
+	}
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.defaultEnvironment
+}
+
+// ValidateEnvironment checks that a playbook defines an environment profile.
+// Playbooks without profiles accept any environment.
+func (o *Orchestrator) ValidateEnvironment(playbookID, environment string) error {
+	playbook, ok := o.GetPlaybook(playbookID)
+	if !ok {
+		return fmt.Errorf("playbook not found: %s", playbookID)
+	}
+	if environment == "" || len(playbook.Playbook.Environments) == 0 {
+		return nil
+	}
+	if _, ok := playbook.Playbook.Environments[environment]; !ok {
+		names := make([]string, 0, len(playbook.Playbook.Environments))
+		for name := range playbook.Playbook.Environments {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("playbook %s has no environment %q (have %s)", playbookID, environment, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// playbookVariables returns a playbook's variables with the environment's
+// profile applied, each interpolated against the run's inputs
+func (o *Orchestrator) playbookVariables(playbook Playbook, environment string, inputs map[string]interface{}) map[string]interface{} {
+	vars := make(map[string]interface{}, len(playbook.Playbook.Variables))
+	for name, value := range playbook.Playbook.Variables {
+		vars[name] = value
+	}
+	for name, value := range playbook.Playbook.Environments[environment] {
+		vars[name] = value
+	}
+	return o.interpolateParameters(vars, map[string]interface{}{"inputs": inputs})
+}
+
+// ErrPlaybookRunInProgress is returned when the same playbook is already
+// running against the same target
+var ErrPlaybookRunInProgress = errors.New("playbook run already in progress for target")
+
+// playbookLockTTL bounds how long a crashed run can block its target
+const playbookLockTTL = time.Hour
+
 // LoadPlaybooks loads all YAML playbooks from the specified directory
 func (o *Orchestrator) LoadPlaybooks(playbooksDir string) error {
 	files, err := filepath.Glob(filepath.Join(playbooksDir, "*.yaml"))
@@ -68,6 +180,7 @@ func (o *Orchestrator) LoadPlaybooks(playbooksDir string) error {
 	}
 	files = append(files, files2...)
 
+	playbooks := make(map[string]Playbook)
 	for _, file := range files {
 		data, err := os.ReadFile(file)
 		if err != nil {
@@ -81,24 +194,119 @@ func (o *Orchestrator) LoadPlaybooks(playbooksDir string) error {
 			continue
 		}
 
-		o.playbooks[playbook.Playbook.ID] = playbook
+		playbooks[playbook.Playbook.ID] = playbook
 		log.Printf("Loaded playbook: %s (%s)", playbook.Playbook.ID, playbook.Playbook.Name)
 	}
 
-	log.Printf("Loaded %d playbooks", len(o.playbooks))
+	o.SetPlaybooks(playbooks)
+	log.Printf("Loaded %d playbooks", len(playbooks))
 	return nil
 }
 
+// SetPlaybooks swaps in playbooks as the loaded set. Runs already in
+// progress finish with the version they started with.
+func (o *Orchestrator) SetPlaybooks(playbooks map[string]Playbook) {
+	o.mu.Lock()
+	o.playbooks = playbooks
+	o.mu.Unlock()
+}
+
 // ExecutePlaybook executes a playbook with the given inputs
 func (o *Orchestrator) ExecutePlaybook(playbookID string, inputs map[string]interface{}) error {
-	playbook, ok := o.playbooks[playbookID]
+	return o.ExecutePlaybookContext(context.Background(), playbookID, inputs)
+}
+
+// ExecutePlaybookContext executes a playbook, recording the context's
+// request ID on the run and its action logs
+func (o *Orchestrator) ExecutePlaybookContext(ctx context.Context, playbookID string, inputs map[string]interface{}) error {
+	execute, err := o.beginRun(ctx, playbookID, inputs)
+	if err != nil {
+		return err
+	}
+	return execute()
+}
+
+// StartPlaybookContext starts a playbook run and executes its steps in the
+// background. An error means the run didn't start: the playbook isn't
+// loaded, its inputs are invalid, another run holds its target or its quota
+// is used up. How the steps went is recorded on the run.
+func (o *Orchestrator) StartPlaybookContext(ctx context.Context, playbookID string, inputs map[string]interface{}) error {
+	execute, err := o.beginRun(ctx, playbookID, inputs)
+	if err != nil {
+		return err
+	}
+	o.running.Add(1)
+	go func() {
+		defer o.running.Done()
+		if err := execute(); err != nil {
+			log.Printf("Playbook %s failed: %v", playbookID, err)
+		}
+	}()
+	return nil
+}
+
+// Wait blocks until runs started in the background have finished
+func (o *Orchestrator) Wait() {
+	o.running.Wait()
+}
+
+// beginRun checks that a run can start, takes its target's lock and records
+// it, returning a function that executes its steps, records the outcome and
+// releases the lock
+func (o *Orchestrator) beginRun(ctx context.Context, playbookID string, inputs map[string]interface{}) (func() error, error) {
+	playbook, ok := o.GetPlaybook(playbookID)
 	if !ok {
-		return fmt.Errorf("playbook not found: %s", playbookID)
+		return nil, fmt.Errorf("playbook not found: %s", playbookID)
 	}
 
-	log.Printf("Executing playbook: %s (%s)", playbookID, playbook.Playbook.Name)
+	log.Printf("Executing playbook: %s (%s)%s", playbookID, playbook.Playbook.Name, requestTag(requestIDPtr(ctx)))
+
+	if err := validatePlaybookInputs(playbook, inputs); err != nil {
+		return nil, err
+	}
 
-	// Validate required inputs
+	// Prevent overlapping runs of this playbook against the same target
+	lockName := playbookLockName(playbookID, inputs)
+	token, ok := o.locks.TryLock(lockName, playbookLockTTL)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPlaybookRunInProgress, lockName)
+	}
+
+	environment := o.runEnvironment(ctx)
+	scope := ExecutionScopeFrom(ctx)
+	if err := o.quotas.CheckRun(scope); err != nil {
+		// The refusal is recorded as a rejected run
+		run := o.startRun(ctx, playbookID, environment, inputs)
+		run.Status = models.RunRejected
+		o.finishRun(run, err)
+		o.locks.Unlock(lockName, token)
+		o.quotas.Raise(err, map[string]interface{}{"playbook_id": playbookID})
+		return nil, err
+	}
+	run := o.startRun(ctx, playbookID, environment, inputs)
+	stepRun := StepRun{PlaybookID: playbookID, RunID: run.RunID, Environment: environment, EventID: TriggerEventFrom(ctx), Inputs: inputs, Scope: scope, TriggeredBy: TriggeredByFrom(ctx)}
+	if run.IncidentID != nil {
+		stepRun.IncidentID = *run.IncidentID
+	}
+	return func() error {
+		defer o.locks.Unlock(lockName, token)
+		err := o.executeSteps(WithStepRun(ctx, stepRun), playbookID, playbook, inputs, nil)
+		o.finishRun(run, err)
+		return err
+	}, nil
+}
+
+// ValidateInputs checks a playbook's required inputs without running it
+func (o *Orchestrator) ValidateInputs(playbookID string, inputs map[string]interface{}) error {
+	playbook, ok := o.GetPlaybook(playbookID)
+	if !ok {
+		return fmt.Errorf("playbook not found: %s", playbookID)
+	}
+	return validatePlaybookInputs(playbook, inputs)
+}
+
+// validatePlaybookInputs checks that all required inputs are present
+func validatePlaybookInputs(playbook Playbook, inputs map[string]interface{}) error {
 	for _, input := range playbook.Playbook.Inputs {
 		if input.Required {
 			if _, ok := inputs[input.Name]; !ok {
@@ -106,20 +314,140 @@ func (o *Orchestrator) ExecutePlaybook(playbookID string, inputs map[string]inte
 			}
 		}
 	}
+	return nil
+}
 
+// executeSteps runs a playbook's steps sequentially. With a non-nil preview
+// no action or task is created: each step's outcome is appended to the
+// preview and its sample output stands in for the result.
+func (o *Orchestrator) executeSteps(ctx context.Context, playbookID string, playbook Playbook, inputs map[string]interface{}, preview *PlaybookPreview) error {
 	// Execution context holds inputs and step outputs
 	context := make(map[string]interface{})
 	context["inputs"] = inputs
+	environment := o.runEnvironment(ctx)
+	context["environment"] = environment
+	vars := o.playbookVariables(playbook, environment, inputs)
+	context["vars"] = vars
+	if preview != nil {
+		preview.Variables = vars
+	}
+	incidentID, _ := inputs["incident_id"].(string)
+	o.injectRunContext(context, incidentID, TriggerEventFrom(ctx))
 
 	// Execute steps sequentially
 	for _, step := range playbook.Playbook.Steps {
+		if step.Condition != "" {
+			run, err := o.evaluateStepCondition(step.Condition, context)
+			if preview != nil {
+				preview.recordCondition(step, run, err)
+			}
+			if err != nil {
+				log.Printf("Step %s condition failed: %v", step.ID, err)
+				if step.OnFailure == "abort" || step.OnFailure == "" {
+					return fmt.Errorf("step %s failed: %w", step.ID, err)
+				}
+				o.recordStepResult(context, step.ID, nil, err)
+				continue
+			}
+			if !run {
+				log.Printf("Skipping step %s: condition not met", step.ID)
+				o.recordStepResult(context, step.ID, nil, nil)
+				continue
+			}
+		}
+
 		log.Printf("Executing step: %s - %s", step.ID, step.Name)
 
+		if step.Manual {
+			var task map[string]interface{}
+			var err error
+			if preview != nil {
+				task = o.previewManualTask(step, context)
+				preview.recordStep(step, nil, task, nil)
+			} else {
+				task, err = o.createManualTask(playbookID, step, context)
+				o.recordIncidentAction(ctx, step, "", err)
+			}
+			if err != nil {
+				log.Printf("Failed to create task for manual step %s: %v", step.ID, err)
+				if step.OnFailure == "abort" || step.OnFailure == "" {
+					return fmt.Errorf("step %s failed: %w", step.ID, err)
+				}
+			}
+			o.recordStepResult(context, step.ID, task, err)
+			continue
+		}
+
+		if step.Transform != nil {
+			// Transforms are pure, so dry runs evaluate them for real
+			output, err := o.runTransform(step, context)
+			if err == nil {
+				output, err = o.normalizeStepOutput(step, output)
+			}
+			if preview != nil {
+				preview.recordStep(step, nil, output, err)
+			} else {
+				o.recordIncidentAction(ctx, step, "", err)
+			}
+			if err != nil {
+				log.Printf("Step %s failed: %v", step.ID, err)
+				if step.OnFailure == "abort" || step.OnFailure == "" {
+					return fmt.Errorf("step %s failed: %w", step.ID, err)
+				}
+			}
+			o.recordStepResult(context, step.ID, output, err)
+			continue
+		}
+
 		// Interpolate variables in parameters
 		interpolatedParams := o.interpolateParameters(step.Parameters, context)
 
-		// Execute the action
-		result, err := o.actions.Execute(step.Action, interpolatedParams)
+		// Execute the action, or substitute its sample output in a dry run
+		var result interface{}
+		var err error
+		if preview != nil {
+			result, err = o.sampleStepOutput(step, context)
+			// Only a step's own sample output is expected to match its schema
+			if err == nil && step.SampleOutput != nil {
+				result, err = o.normalizeStepOutput(step, result)
+			}
+			preview.recordStep(step, interpolatedParams, result, err)
+		} else {
+			stepCtx := ctx
+			if stepRun, ok := StepRunFrom(ctx); ok {
+				stepRun.StepID = step.ID
+				stepCtx = WithStepRun(ctx, stepRun)
+			}
+			var actionID string
+			if o.actions.Contained(step.Action) {
+				// Held back by the containment pause: the step's sample
+				// output stands in, as in a dry run
+				log.Printf("Containment pause: not executing %s for step %s", step.Action, step.ID)
+				result, err = o.heldStepOutput(step, context)
+				actionID = o.actions.LogHeld(stepCtx, step.Action, models.ActionDryRun, interpolatedParams, result, "Not executed: containment pause")
+			} else if ExerciseFrom(ctx) && o.actions.Remediation(step.Action) {
+				// An exercise rehearses the response without touching
+				// real systems: remediation is logged as a dry run
+				log.Printf("Exercise: not executing %s for step %s", step.Action, step.ID)
+				result, err = o.heldStepOutput(step, context)
+				actionID = o.actions.LogHeld(stepCtx, step.Action, models.ActionDryRun, interpolatedParams, result, "Not executed: exercise")
+			} else if gate := o.gates.Requirement(playbookID, step.Action); gate.Approvals > 0 {
+				// Gated by the execution policy or a change freeze: the
+				// action runs once approved, and the step's sample output
+				// stands in meanwhile
+				result, err = o.heldStepOutput(step, context)
+				actionID = o.actions.LogHeld(stepCtx, step.Action, models.ActionAwaitingApproval, interpolatedParams, result, "Awaiting approval: "+strings.Join(gate.Reasons, "; "))
+				if _, holdErr := o.gates.Hold(stepCtx, step.Action, interpolatedParams, actionID, gate); holdErr != nil && err == nil {
+					err = holdErr
+				}
+			} else {
+				result, actionID, err = o.actions.ExecuteLogged(stepCtx, step.Action, interpolatedParams)
+				if err == nil {
+					result, err = o.normalizeStepOutput(step, result)
+				}
+			}
+			o.recordIncidentAction(stepCtx, step, actionID, err)
+		}
 		if err != nil {
 			log.Printf("Step %s failed: %v", step.ID, err)
 
@@ -132,13 +460,7 @@ func (o *Orchestrator) ExecutePlaybook(playbookID string, inputs map[string]inte
 		}
 
 		// Store step result in context
-		if context["steps"] == nil {
-			context["steps"] = make(map[string]interface{})
-		}
-		context["steps"].(map[string]interface{})[step.ID] = map[string]interface{}{
-			"output": result,
-			"error":  err,
-		}
+		o.recordStepResult(context, step.ID, result, err)
 
 		log.Printf("Step %s completed", step.ID)
 	}
@@ -147,6 +469,153 @@ func (o *Orchestrator) ExecutePlaybook(playbookID string, inputs map[string]inte
 	return nil
 }
 
+// heldStepOutput returns the output standing in for a step whose action was
+// held back: its sample output, as in a dry run
+func (o *Orchestrator) heldStepOutput(step PlaybookStep, context map[string]interface{}) (interface{}, error) {
+	result, err := o.sampleStepOutput(step, context)
+	if err == nil && step.SampleOutput != nil {
+		result, err = o.normalizeStepOutput(step, result)
+	}
+	return result, err
+}
+
+// startRun records the beginning of a playbook run
+func (o *Orchestrator) startRun(ctx context.Context, playbookID, environment string, inputs map[string]interface{}) *models.PlaybookRun {
+	inputsJSON, _ := json.Marshal(inputs)
+	run := &models.PlaybookRun{
+		PlaybookID:  playbookID,
+		Status:      models.RunRunning,
+		Environment: environment,
+		Inputs:      string(inputsJSON),
+		RequestID:   requestIDPtr(ctx),
+	}
+	if eventID := TriggerEventFrom(ctx); eventID != "" {
+		run.EventID = &eventID
+	}
+	if incidentID, ok := inputs["incident_id"].(string); ok && incidentID != "" {
+		run.IncidentID = &incidentID
+	}
+	scope := ExecutionScopeFrom(ctx)
+	if scope.RuleID != "" {
+		run.RuleID = &scope.RuleID
+	}
+	if scope.Tenant != "" {
+		run.Tenant = &scope.Tenant
+	}
+	if principal := TriggeredByFrom(ctx); principal != "" {
+		run.TriggeredBy = &principal
+	}
+	run.Exercise = ExerciseFrom(ctx)
+
+	if err := o.db.Create(run).Error; err != nil {
+		log.Printf("Failed to record playbook run for %s: %v", playbookID, err)
+	}
+	return run
+}
+
+// finishRun records a playbook run's outcome
+func (o *Orchestrator) finishRun(run *models.PlaybookRun, err error) {
+	now := time.Now().UTC()
+	run.CompletedAt = &now
+	if err != nil {
+		if run.Status != models.RunRejected {
+			run.Status = models.RunFailed
+		}
+		errMsg := err.Error()
+		run.Error = &errMsg
+	} else {
+		run.Status = models.RunCompleted
+	}
+
+	if err := o.db.Save(run).Error; err != nil {
+		log.Printf("Failed to update playbook run %s: %v", run.RunID, err)
+	}
+}
+
+// playbookLockName identifies a playbook run's target: the incident when one
+// is given, otherwise all inputs
+func playbookLockName(playbookID string, inputs map[string]interface{}) string {
+	if incidentID, ok := inputs["incident_id"]; ok {
+		return fmt.Sprintf("playbook:%s:incident=%v", playbookID, incidentID)
+	}
+
+	keys := make([]string, 0, len(inputs))
+	for k := range inputs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, inputs[k]))
+	}
+	return fmt.Sprintf("playbook:%s:%s", playbookID, strings.Join(parts, ","))
+}
+
+// recordStepResult stores a step's output and error in the execution context
+func (o *Orchestrator) recordStepResult(context map[string]interface{}, stepID string, output interface{}, err error) {
+	if context["steps"] == nil {
+		context["steps"] = make(map[string]interface{})
+	}
+	context["steps"].(map[string]interface{})[stepID] = map[string]interface{}{
+		"output": output,
+		"error":  err,
+	}
+}
+
+// createManualTask records a manual playbook step as a task on the run's incident
+func (o *Orchestrator) createManualTask(playbookID string, step PlaybookStep, context map[string]interface{}) (map[string]interface{}, error) {
+	incidentID := fmt.Sprintf("%v", o.resolveVariable("inputs.incident_id", context))
+	if incidentID == "" || incidentID == "inputs.incident_id" || incidentID == "<nil>" {
+		return nil, fmt.Errorf("manual step requires an incident_id input")
+	}
+	var incidents int64
+	if err := o.db.Model(&models.Incident{}).Where("incident_id = ?", incidentID).Count(&incidents).Error; err != nil {
+		return nil, fmt.Errorf("failed to look up incident %s: %w", incidentID, err)
+	}
+	if incidents == 0 {
+		return nil, fmt.Errorf("incident %s not found", incidentID)
+	}
+
+	title := step.Name
+	if title == "" {
+		title = step.ID
+	}
+
+	stepID := step.ID
+	task := &models.IncidentTask{
+		IncidentID: incidentID,
+		Title:      o.interpolateString(title, context),
+		Status:     models.TaskOpen,
+		PlaybookID: &playbookID,
+		StepID:     &stepID,
+	}
+
+	if step.Assignee != "" {
+		assignee := o.interpolateString(step.Assignee, context)
+		task.Assignee = &assignee
+	}
+
+	if step.DueIn != "" {
+		dueIn, err := time.ParseDuration(step.DueIn)
+		if err != nil {
+			return nil, fmt.Errorf("invalid due_in %q: %w", step.DueIn, err)
+		}
+		dueAt := time.Now().UTC().Add(dueIn)
+		task.DueAt = &dueAt
+	}
+
+	if err := o.db.Create(task).Error; err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+
+	log.Printf("Created task %s for manual step %s on incident %s", task.TaskID, step.ID, incidentID)
+	return map[string]interface{}{
+		"task_id": task.TaskID,
+		"status":  string(task.Status),
+	}, nil
+}
+
 // interpolateParameters replaces template variables in parameters
 func (o *Orchestrator) interpolateParameters(params map[string]interface{}, context map[string]interface{}) map[string]interface{} {
 	result := make(map[string]interface{})
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// Outbox topics
+const (
+	TopicNotify          = "notify"
+	TopicExecutePlaybook = "execute_playbook"
+)
+
+// PlaybookTriggerRule is the trigger of execute_playbook messages queued by
+// detection rules rather than a person
+const PlaybookTriggerRule = "rule"
+
+// OutboxHandler performs the side effect for one outbox message
+type OutboxHandler func(payload map[string]interface{}) error
+
+// Outbox records side effects transactionally and dispatches them after commit
+// with retries, so a rolled-back change never notifies and a committed one
+// always eventually does
+type Outbox struct {
+	db          *gorm.DB
+	handlers    map[string]OutboxHandler
+	maxAttempts int
+	batchSize   int
+}
+
+// NewOutbox creates a new outbox
+func NewOutbox(db *gorm.DB, maxAttempts int) *Outbox {
+	return &Outbox{
+		db:          db,
+		handlers:    make(map[string]OutboxHandler),
+		maxAttempts: maxAttempts,
+		batchSize:   50,
+	}
+}
+
+// RegisterHandler sets the handler for a topic
+func (o *Outbox) RegisterHandler(topic string, handler OutboxHandler) {
+	o.handlers[topic] = handler
+}
+
+// Enqueue records a message using tx, so it commits or rolls back with the caller's changes
+func (o *Outbox) Enqueue(tx *gorm.DB, topic string, payload map[string]interface{}) error {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox payload: %w", err)
+	}
+
+	message := &models.OutboxMessage{
+		Topic:   topic,
+		Payload: string(payloadJSON),
+	}
+	if err := tx.Create(message).Error; err != nil {
+		return fmt.Errorf("failed to enqueue outbox message: %w", err)
+	}
+	return nil
+}
+
+// Dispatch delivers due pending messages; intended to run as a leader-only
+// scheduled job so each message is dispatched by one instance
+func (o *Outbox) Dispatch() error {
+	var messages []models.OutboxMessage
+	if err := o.db.
+		Where("status = ? AND next_attempt_at <= ?", models.OutboxPending, time.Now().UTC()).
+		Order("created_at ASC").
+		Limit(o.batchSize).
+		Find(&messages).Error; err != nil {
+		return fmt.Errorf("failed to fetch outbox messages: %w", err)
+	}
+
+	for i := range messages {
+		o.deliver(&messages[i])
+	}
+	return nil
+}
+
+// Backlog returns the number of pending messages and when the oldest was
+// queued (nil when none are pending)
+func (o *Outbox) Backlog(ctx context.Context) (int64, *time.Time, error) {
+	var pending int64
+	query := o.db.WithContext(ctx).Model(&models.OutboxMessage{}).Where("status = ?", models.OutboxPending)
+	if err := query.Count(&pending).Error; err != nil {
+		return 0, nil, fmt.Errorf("failed to count outbox messages: %w", err)
+	}
+	if pending == 0 {
+		return 0, nil, nil
+	}
+
+	var oldest models.OutboxMessage
+	if err := o.db.WithContext(ctx).Where("status = ?", models.OutboxPending).
+		Order("created_at ASC").Limit(1).Find(&oldest).Error; err != nil {
+		return 0, nil, fmt.Errorf("failed to fetch oldest outbox message: %w", err)
+	}
+	return pending, &oldest.CreatedAt, nil
+}
+
+// deliver runs one message's handler and records the outcome
+func (o *Outbox) deliver(message *models.OutboxMessage) {
+	message.Attempts++
+
+	err := o.handle(message)
+	if err == nil {
+		now := time.Now().UTC()
+		message.Status = models.OutboxDispatched
+		message.DispatchedAt = &now
+		message.LastError = nil
+	} else {
+		errMsg := err.Error()
+		message.LastError = &errMsg
+		if message.Attempts >= o.maxAttempts {
+			message.Status = models.OutboxFailed
+			log.Printf("Outbox: message %s (%s) failed permanently after %d attempts: %v", message.MessageID, message.Topic, message.Attempts, err)
+		} else {
+			message.NextAttemptAt = time.Now().UTC().Add(outboxBackoff(message.Attempts))
+			log.Printf("Outbox: message %s (%s) attempt %d failed, retrying at %s: %v", message.MessageID, message.Topic, message.Attempts, message.NextAttemptAt.Format(time.RFC3339), err)
+		}
+	}
+
+	if err := o.db.Save(message).Error; err != nil {
+		log.Printf("Outbox: failed to update message %s: %v", message.MessageID, err)
+	}
+}
+
+// handle decodes the payload and invokes the topic's handler
+func (o *Outbox) handle(message *models.OutboxMessage) error {
+	handler, ok := o.handlers[message.Topic]
+	if !ok {
+		return fmt.Errorf("no handler for topic %s", message.Topic)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(message.Payload), &payload); err != nil {
+		return fmt.Errorf("failed to parse payload: %w", err)
+	}
+	return handler(payload)
+}
+
+// outboxBackoff grows quadratically from 5s, capped at 10 minutes
+func outboxBackoff(attempts int) time.Duration {
+	backoff := time.Duration(attempts*attempts) * 5 * time.Second
+	if backoff > 10*time.Minute {
+		return 10 * time.Minute
+	}
+	return backoff
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// outputTypes are the types an ActionOutput may declare
+var outputTypes = map[string]bool{
+	"string": true, "integer": true, "number": true, "boolean": true,
+	"object": true, "array": true, "any": true,
+}
+
+// validateOutputSchema reports problems in a declared output schema
+func validateOutputSchema(schema []ActionOutput) error {
+	for _, field := range schema {
+		if field.Name == "" {
+			return fmt.Errorf("output has no name")
+		}
+		if !outputTypes[field.Type] {
+			return fmt.Errorf("output %s has unknown type %q", field.Name, field.Type)
+		}
+		if field.Items != "" && (field.Type != "array" || !outputTypes[field.Items]) {
+			return fmt.Errorf("output %s has invalid items type %q", field.Name, field.Items)
+		}
+	}
+	return nil
+}
+
+// mergeOutputSchemas returns base with fields redeclared in override replaced
+// and new ones appended
+func mergeOutputSchemas(base, override []ActionOutput) []ActionOutput {
+	merged := append([]ActionOutput{}, base...)
+	for _, field := range override {
+		replaced := false
+		for i := range merged {
+			if merged[i].Name == field.Name {
+				merged[i] = field
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged = append(merged, field)
+		}
+	}
+	return merged
+}
+
+// normalizeOutput checks a result against a schema and returns it as plain
+// values (see plainValue) with each declared field converted to its type:
+// numeric strings become numbers, "true" a boolean, a scalar a one-element
+// array, and missing fields their default. A missing optional array is
+// empty. Fields the schema doesn't mention are kept as they are.
+func normalizeOutput(result interface{}, schema []ActionOutput) (interface{}, error) {
+	if len(schema) == 0 {
+		return result, nil
+	}
+	out, ok := plainValue(result).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("output is %s, not an object", exprType(plainValue(result)))
+	}
+
+	for _, field := range schema {
+		path := strings.Split(field.Name, ".")
+		parent := out
+		for _, part := range path[:len(path)-1] {
+			next, ok := parent[part].(map[string]interface{})
+			if !ok {
+				parent = nil
+				break
+			}
+			parent = next
+		}
+		name := path[len(path)-1]
+
+		var value interface{}
+		if parent != nil {
+			value = parent[name]
+		}
+		if value == nil {
+			switch {
+			case field.Default != nil:
+				value = plainValue(field.Default)
+			case field.Required:
+				return nil, fmt.Errorf("output %s is missing", field.Name)
+			case field.Type == "array" && parent != nil:
+				parent[name] = []interface{}{}
+				continue
+			default:
+				continue
+			}
+			if parent == nil {
+				continue
+			}
+		}
+
+		converted, err := convertOutputValue(value, field.Type, field.Items)
+		if err != nil {
+			return nil, fmt.Errorf("output %s: %w", field.Name, err)
+		}
+		parent[name] = converted
+	}
+	return out, nil
+}
+
+// convertOutputValue converts a plain value to a declared type
+func convertOutputValue(value interface{}, typ, items string) (interface{}, error) {
+	switch typ {
+	case "string":
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case float64, bool:
+			return exprString(v), nil
+		}
+	case "integer":
+		switch v := value.(type) {
+		case float64:
+			if v == math.Trunc(v) {
+				return int(v), nil
+			}
+		case string:
+			if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+				return n, nil
+			}
+		}
+	case "number":
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return f, nil
+			}
+		}
+	case "boolean":
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return b, nil
+			}
+		}
+	case "object":
+		if v, ok := value.(map[string]interface{}); ok {
+			return v, nil
+		}
+	case "array":
+		list, ok := value.([]interface{})
+		if !ok {
+			if _, isObject := value.(map[string]interface{}); isObject {
+				break
+			}
+			list = []interface{}{value}
+		}
+		if items == "" || items == "any" {
+			return list, nil
+		}
+		out := make([]interface{}, len(list))
+		for i, item := range list {
+			converted, err := convertOutputValue(item, items, "")
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			out[i] = converted
+		}
+		return out, nil
+	case "any", "":
+		return value, nil
+	}
+	return nil, fmt.Errorf("expected %s, got %s", typ, exprType(value))
+}
+
+// normalizeStepOutput applies the action's output schema, extended by the
+// step's own outputs, to a step result
+func (o *Orchestrator) normalizeStepOutput(step PlaybookStep, result interface{}) (interface{}, error) {
+	var schema []ActionOutput
+	if step.Action != "" {
+		if desc, ok := o.actions.Descriptor(step.Action); ok {
+			schema = desc.Outputs
+		}
+	}
+	schema = mergeOutputSchemas(schema, step.Outputs)
+	normalized, err := normalizeOutput(result, schema)
+	if err != nil {
+		return result, err
+	}
+	return normalized, nil
+}
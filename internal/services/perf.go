@@ -0,0 +1,343 @@
+package services
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// perfWindowSize is the number of recent samples kept per rule, condition and
+// pipeline stage; percentiles are computed over this window
+const perfWindowSize = 256
+
+// Pipeline stages timed for each evaluated event
+const (
+	StageQueued     = "queued"     // stored to evaluation start
+	StageEnrichment = "enrichment" // enrichment pipeline
+	StageMatching   = "matching"   // rule matching
+	StageActions    = "actions"    // incident creation and outbox writes for matches
+	StageTotal      = "total"      // stored to processed
+)
+
+// perfBuckets are the upper bounds of the pipeline latency histograms
+var perfBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// timedOperators are the condition operators timed individually: regexes and
+// the count and seen-value lookups that hit the database
+var timedOperators = map[string]bool{"regex": true, "count": true, "count_distinct": true, "first_seen": true, "rare": true}
+
+// latencyWindow keeps the most recent samples of a measurement
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+	count   int64
+	total   time.Duration
+	max     time.Duration
+}
+
+func (w *latencyWindow) add(d time.Duration) {
+	if len(w.samples) < perfWindowSize {
+		w.samples = append(w.samples, d)
+	} else {
+		w.samples[w.next] = d
+		w.next = (w.next + 1) % perfWindowSize
+	}
+	w.count++
+	w.total += d
+	if d > w.max {
+		w.max = d
+	}
+}
+
+// LatencySummary describes a measurement; percentiles cover the recent
+// window, the count, mean and max everything since the last reset
+type LatencySummary struct {
+	Count  int64   `json:"count"`
+	MeanMs float64 `json:"mean_ms"`
+	P50Ms  float64 `json:"p50_ms"`
+	P95Ms  float64 `json:"p95_ms"`
+	P99Ms  float64 `json:"p99_ms"`
+	MaxMs  float64 `json:"max_ms"`
+}
+
+func (w *latencyWindow) summary() (LatencySummary, []time.Duration) {
+	sorted := append([]time.Duration(nil), w.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	s := LatencySummary{Count: w.count, MaxMs: ms(w.max)}
+	if w.count > 0 {
+		s.MeanMs = ms(w.total / time.Duration(w.count))
+	}
+	if len(sorted) > 0 {
+		s.P50Ms = ms(sorted[int(float64(len(sorted)-1)*0.50)])
+		s.P95Ms = ms(sorted[int(float64(len(sorted)-1)*0.95)])
+		s.P99Ms = ms(sorted[int(float64(len(sorted)-1)*0.99)])
+	}
+	return s, sorted
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// RulePerf is the evaluation time of one rule
+type RulePerf struct {
+	RuleID  string `json:"rule_id"`
+	Matches int64  `json:"matches"`
+	LatencySummary
+}
+
+// ConditionPerf is the evaluation time of one timed condition
+type ConditionPerf struct {
+	RuleID   string `json:"rule_id"`
+	Index    int    `json:"index"`
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	LatencySummary
+}
+
+// EnrichmentPerf is the run time of one enrichment stage and how often it
+// failed or timed out
+type EnrichmentPerf struct {
+	Stage  string `json:"stage"`
+	Errors int64  `json:"errors"`
+	LatencySummary
+}
+
+// HistogramBucket counts recent samples above the previous bucket's bound and
+// at or below LeMs; the last bucket has no upper bound
+type HistogramBucket struct {
+	LeMs  *float64 `json:"le_ms"`
+	Count int      `json:"count"`
+}
+
+// StagePerf is the latency of one pipeline stage
+type StagePerf struct {
+	LatencySummary
+	Histogram []HistogramBucket `json:"histogram"`
+}
+
+// PerfReport is the response body of GET /api/v1/admin/perf
+type PerfReport struct {
+	Since          time.Time            `json:"since"`
+	WindowSize     int                  `json:"window_size"`
+	Rules          []RulePerf           `json:"rules"`
+	SlowConditions []ConditionPerf      `json:"slow_conditions"`
+	Pipeline       map[string]StagePerf `json:"pipeline"`
+	Enrichment     []EnrichmentPerf     `json:"enrichment"`
+	// Queue is the depth of the detection queues, which the recorder leaves
+	// to the engine to fill in
+	Queue *DetectionQueueStats `json:"queue,omitempty"`
+}
+
+type conditionKey struct {
+	ruleID string
+	index  int
+}
+
+type conditionStats struct {
+	field    string
+	operator string
+	window   latencyWindow
+}
+
+type enrichmentStats struct {
+	errors int64
+	window latencyWindow
+}
+
+type ruleStats struct {
+	matches int64
+	window  latencyWindow
+}
+
+// PerfRecorder collects detection timings in memory for this instance. A nil
+// recorder records nothing, which is how simulations and benchmarks skip it.
+type PerfRecorder struct {
+	mu         sync.Mutex
+	since      time.Time
+	rules      map[string]*ruleStats
+	conditions map[conditionKey]*conditionStats
+	stages     map[string]*latencyWindow
+	enrichment map[string]*enrichmentStats
+}
+
+// NewPerfRecorder creates an empty recorder
+func NewPerfRecorder() *PerfRecorder {
+	p := &PerfRecorder{}
+	p.Reset()
+	return p
+}
+
+// Reset discards all samples
+func (p *PerfRecorder) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.since = time.Now().UTC()
+	p.rules = make(map[string]*ruleStats)
+	p.conditions = make(map[conditionKey]*conditionStats)
+	p.stages = make(map[string]*latencyWindow)
+	p.enrichment = make(map[string]*enrichmentStats)
+}
+
+// recordRule records one evaluation of a rule
+func (p *PerfRecorder) recordRule(ruleID string, d time.Duration, matched bool) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats, ok := p.rules[ruleID]
+	if !ok {
+		stats = &ruleStats{}
+		p.rules[ruleID] = stats
+	}
+	stats.window.add(d)
+	if matched {
+		stats.matches++
+	}
+}
+
+// recordCondition records one evaluation of a rule's condition
+func (p *PerfRecorder) recordCondition(ruleID string, index int, cond Condition, d time.Duration) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := conditionKey{ruleID: ruleID, index: index}
+	stats, ok := p.conditions[key]
+	if !ok {
+		stats = &conditionStats{field: cond.Field, operator: cond.Operator}
+		p.conditions[key] = stats
+	}
+	stats.window.add(d)
+}
+
+// recordStage records the latency of a pipeline stage
+func (p *PerfRecorder) recordStage(stage string, d time.Duration) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	window, ok := p.stages[stage]
+	if !ok {
+		window = &latencyWindow{}
+		p.stages[stage] = window
+	}
+	window.add(d)
+}
+
+// recordEnrichment records one run of an enrichment stage
+func (p *PerfRecorder) recordEnrichment(stage string, d time.Duration, err error) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats, ok := p.enrichment[stage]
+	if !ok {
+		stats = &enrichmentStats{}
+		p.enrichment[stage] = stats
+	}
+	stats.window.add(d)
+	if err != nil {
+		stats.errors++
+	}
+}
+
+// Report summarizes the recorded timings, listing the limit slowest rules
+// and conditions by p95
+func (p *PerfRecorder) Report(limit int) PerfReport {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	report := PerfReport{
+		Since:          p.since,
+		WindowSize:     perfWindowSize,
+		Rules:          []RulePerf{},
+		SlowConditions: []ConditionPerf{},
+		Pipeline:       make(map[string]StagePerf),
+		Enrichment:     []EnrichmentPerf{},
+	}
+
+	for id, stats := range p.rules {
+		summary, _ := stats.window.summary()
+		report.Rules = append(report.Rules, RulePerf{RuleID: id, Matches: stats.matches, LatencySummary: summary})
+	}
+	sort.Slice(report.Rules, func(i, j int) bool {
+		if report.Rules[i].P95Ms != report.Rules[j].P95Ms {
+			return report.Rules[i].P95Ms > report.Rules[j].P95Ms
+		}
+		return report.Rules[i].RuleID < report.Rules[j].RuleID
+	})
+	if limit > 0 && len(report.Rules) > limit {
+		report.Rules = report.Rules[:limit]
+	}
+
+	for key, stats := range p.conditions {
+		summary, _ := stats.window.summary()
+		report.SlowConditions = append(report.SlowConditions, ConditionPerf{
+			RuleID:         key.ruleID,
+			Index:          key.index,
+			Field:          stats.field,
+			Operator:       stats.operator,
+			LatencySummary: summary,
+		})
+	}
+	sort.Slice(report.SlowConditions, func(i, j int) bool {
+		a, b := report.SlowConditions[i], report.SlowConditions[j]
+		if a.P95Ms != b.P95Ms {
+			return a.P95Ms > b.P95Ms
+		}
+		if a.RuleID != b.RuleID {
+			return a.RuleID < b.RuleID
+		}
+		return a.Index < b.Index
+	})
+	if limit > 0 && len(report.SlowConditions) > limit {
+		report.SlowConditions = report.SlowConditions[:limit]
+	}
+
+	for stage, window := range p.stages {
+		summary, sorted := window.summary()
+		report.Pipeline[stage] = StagePerf{LatencySummary: summary, Histogram: histogram(sorted)}
+	}
+
+	for stage, stats := range p.enrichment {
+		summary, _ := stats.window.summary()
+		report.Enrichment = append(report.Enrichment, EnrichmentPerf{Stage: stage, Errors: stats.errors, LatencySummary: summary})
+	}
+	sort.Slice(report.Enrichment, func(i, j int) bool {
+		return report.Enrichment[i].Stage < report.Enrichment[j].Stage
+	})
+	return report
+}
+
+// histogram buckets sorted samples by perfBuckets
+func histogram(sorted []time.Duration) []HistogramBucket {
+	buckets := make([]HistogramBucket, len(perfBuckets)+1)
+	i := 0
+	for b, bound := range perfBuckets {
+		le := ms(bound)
+		buckets[b].LeMs = &le
+		for i < len(sorted) && sorted[i] <= bound {
+			buckets[b].Count++
+			i++
+		}
+	}
+	buckets[len(perfBuckets)].Count = len(sorted) - i
+	return buckets
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// Where PII is masked
+const (
+	PIIApplyOff    = "off"
+	PIIApplyStore  = "store"  // event payloads before they are stored, and exports
+	PIIApplyExport = "export" // incident reports only
+)
+
+// PII masking styles
+const (
+	PIIMaskRedact = "redact" // [EMAIL]
+	PIIMaskHash   = "hash"   // [EMAIL:<keyed hash>], so equal values still correlate
+)
+
+// PIIPolicyFile is the YAML layout of the PII policy
+type PIIPolicyFile struct {
+	Apply     string   `yaml:"apply"`
+	Mask      string   `yaml:"mask"`
+	Detectors []string `yaml:"detectors"`
+	// Patterns are extra detectors: name -> regular expression
+	Patterns map[string]string `yaml:"patterns"`
+	// KeepFields are JSON keys whose values are never masked
+	KeepFields []string `yaml:"keep_fields"`
+}
+
+// piiDetector finds one kind of PII
+type piiDetector struct {
+	name    string
+	pattern *regexp.Regexp
+	valid   func(match string) bool // rejects look-alikes; nil accepts every match
+}
+
+// builtinPIIDetectors are the detectors a policy can name
+var builtinPIIDetectors = map[string]piiDetector{
+	"email": {
+		name:    "email",
+		pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`),
+	},
+	"credit_card": {
+		name:    "credit_card",
+		pattern: regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`),
+		valid:   luhnValid,
+	},
+	// US social security numbers and UK national insurance numbers
+	"national_id": {
+		name:    "national_id",
+		pattern: regexp.MustCompile(`\b(?:\d{3}-\d{2}-\d{4}|[A-CEGHJ-PR-TW-Z][A-CEGHJ-NPR-TW-Z] ?\d{2} ?\d{2} ?\d{2} ?[A-D])\b`),
+		valid:   nationalIDValid,
+	},
+}
+
+// PIIScrubber masks personal data matched by the PII policy's detectors
+type PIIScrubber struct {
+	apply     string
+	mask      string
+	hashKey   []byte
+	detectors []piiDetector
+	keep      map[string]bool
+}
+
+// LoadPIIPolicy reads a PII policy file. A missing file turns scrubbing off.
+// hashKey keys the hashes of mask: hash.
+func LoadPIIPolicy(path, hashKey string) (*PIIScrubber, error) {
+	p := &PIIScrubber{apply: PIIApplyOff, mask: PIIMaskRedact, hashKey: []byte(hashKey), keep: make(map[string]bool)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return p, nil
+		}
+		return nil, fmt.Errorf("failed to read PII policy: %w", err)
+	}
+
+	var file PIIPolicyFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse PII policy: %w", err)
+	}
+	switch file.Apply {
+	case "":
+	case PIIApplyOff, PIIApplyStore, PIIApplyExport:
+		p.apply = file.Apply
+	default:
+		return nil, fmt.Errorf("invalid PII policy: apply must be store, export or off")
+	}
+	switch file.Mask {
+	case "":
+	case PIIMaskRedact, PIIMaskHash:
+		p.mask = file.Mask
+	default:
+		return nil, fmt.Errorf("invalid PII policy: mask must be redact or hash")
+	}
+	if p.mask == PIIMaskHash && hashKey == "" && p.apply != PIIApplyOff {
+		return nil, fmt.Errorf("invalid PII policy: mask: hash requires PII_HASH_KEY")
+	}
+	for _, name := range file.Detectors {
+		detector, ok := builtinPIIDetectors[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid PII policy: unknown detector %q", name)
+		}
+		p.detectors = append(p.detectors, detector)
+	}
+	for name, expr := range file.Patterns {
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PII policy: pattern %q: %w", name, err)
+		}
+		p.detectors = append(p.detectors, piiDetector{name: name, pattern: pattern})
+	}
+	for _, field := range file.KeepFields {
+		p.keep[field] = true
+	}
+	return p, nil
+}
+
+// OnStore reports whether event payloads are masked before storage
+func (p *PIIScrubber) OnStore() bool {
+	return p != nil && p.apply == PIIApplyStore && len(p.detectors) > 0
+}
+
+// OnExport reports whether exports are masked
+func (p *PIIScrubber) OnExport() bool {
+	return p != nil && p.apply != PIIApplyOff && len(p.detectors) > 0
+}
+
+// ScrubEvent masks PII in an event's raw and normalized payloads
+func (p *PIIScrubber) ScrubEvent(event *models.Event) {
+	if !models.IsEncodedPayload(event.RawData) {
+		event.RawData = p.ScrubJSON(event.RawData)
+	}
+	event.Normalized = p.ScrubJSON(event.Normalized)
+}
+
+// ScrubJSON masks PII in the string values of a JSON document, leaving keep
+// fields alone. Text that isn't JSON is scrubbed as a whole. The document is
+// only re-encoded when something was masked.
+func (p *PIIScrubber) ScrubJSON(text string) string {
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return p.ScrubText(text)
+	}
+	scrubbed, changed := p.scrubValue(value)
+	if !changed {
+		return text
+	}
+	encoded, err := json.Marshal(scrubbed)
+	if err != nil {
+		return p.ScrubText(text)
+	}
+	return string(encoded)
+}
+
+// ScrubObject masks PII in the JSON form of v, returning it decoded
+func (p *PIIScrubber) ScrubObject(v interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(encoded, &value); err != nil {
+		return nil, err
+	}
+	return p.ScrubValue(value), nil
+}
+
+// ScrubValue masks PII in the strings of a decoded JSON value
+func (p *PIIScrubber) ScrubValue(value interface{}) interface{} {
+	scrubbed, _ := p.scrubValue(value)
+	return scrubbed
+}
+
+func (p *PIIScrubber) scrubValue(value interface{}) (interface{}, bool) {
+	changed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range v {
+			if p.keep[key] {
+				continue
+			}
+			scrubbed, innerChanged := p.scrubValue(inner)
+			if innerChanged {
+				v[key], changed = scrubbed, true
+			}
+		}
+	case []interface{}:
+		for i, inner := range v {
+			scrubbed, innerChanged := p.scrubValue(inner)
+			if innerChanged {
+				v[i], changed = scrubbed, true
+			}
+		}
+	case string:
+		scrubbed := p.ScrubText(v)
+		return scrubbed, scrubbed != v
+	}
+	return value, changed
+}
+
+// ScrubText masks every PII match in text
+func (p *PIIScrubber) ScrubText(text string) string {
+	if p == nil {
+		return text
+	}
+	for _, detector := range p.detectors {
+		text = detector.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if detector.valid != nil && !detector.valid(match) {
+				return match
+			}
+			return p.maskValue(detector.name, match)
+		})
+	}
+	return text
+}
+
+// Masks returns the hashed forms an identifier is stored as when it was
+// scrubbed before storage, so an erasure can find those too. Redacted forms
+// are the same for every value and name no one.
+func (p *PIIScrubber) Masks(identifier string) []string {
+	if !p.OnStore() || p.mask != PIIMaskHash {
+		return nil
+	}
+	var masks []string
+	for _, detector := range p.detectors {
+		if loc := detector.pattern.FindStringIndex(identifier); loc != nil && loc[0] == 0 && loc[1] == len(identifier) {
+			masks = append(masks, p.maskValue(detector.name, identifier))
+		}
+	}
+	return masks
+}
+
+// maskValue returns the replacement for a match of a detector
+func (p *PIIScrubber) maskValue(name, match string) string {
+	label := strings.ToUpper(name)
+	if p.mask != PIIMaskHash {
+		return "[" + label + "]"
+	}
+	// Equal values hash alike however they were written
+	normalized := strings.ToLower(match)
+	if name == "credit_card" || name == "national_id" {
+		normalized = strings.NewReplacer(" ", "", "-", "").Replace(normalized)
+	}
+	mac := hmac.New(sha256.New, p.hashKey)
+	mac.Write([]byte(normalized))
+	return "[" + label + ":" + hex.EncodeToString(mac.Sum(nil))[:16] + "]"
+}
+
+// luhnValid reports whether a card number candidate passes the Luhn check
+func luhnValid(match string) bool {
+	sum, double, digits := 0, false, 0
+	for i := len(match) - 1; i >= 0; i-- {
+		c := match[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
+
+// nationalIDValid rejects SSN look-alikes with an area, group or serial
+// number that is never issued
+func nationalIDValid(match string) bool {
+	if len(match) != 11 || match[3] != '-' {
+		return true
+	}
+	area, group, serial := match[:3], match[4:6], match[7:]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
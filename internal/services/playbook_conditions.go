@@ -0,0 +1,222 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// conditionOperators are checked in order so two-character operators win
+var conditionOperators = []string{"==", "!=", ">=", "<=", ">", "<"}
+
+// evaluateStepCondition evaluates a step condition such as
+// "{{ steps.step-8.output.confidence > 0.8 || steps.step-10.error }}".
+// It supports ||, &&, comparisons, ! negation, quoted strings, numbers,
+// booleans and variable paths; a bare operand is tested for truthiness.
+func (o *Orchestrator) evaluateStepCondition(condition string, context map[string]interface{}) (bool, error) {
+	expr := strings.TrimSpace(condition)
+	if strings.HasPrefix(expr, "{{") && strings.HasSuffix(expr, "}}") {
+		expr = strings.TrimSpace(expr[2 : len(expr)-2])
+	}
+	if expr == "" {
+		return true, nil
+	}
+
+	for _, alternative := range splitOutsideQuotes(expr, "||") {
+		matched := true
+		for _, term := range splitOutsideQuotes(alternative, "&&") {
+			ok, err := o.evaluateConditionTerm(strings.TrimSpace(term), context)
+			if err != nil {
+				return false, fmt.Errorf("invalid condition %q: %w", condition, err)
+			}
+			if !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// evaluateConditionTerm evaluates a single comparison or operand
+func (o *Orchestrator) evaluateConditionTerm(term string, context map[string]interface{}) (bool, error) {
+	if term == "" {
+		return false, fmt.Errorf("empty expression")
+	}
+
+	for _, op := range conditionOperators {
+		idx := indexOutsideQuotes(term, op)
+		if idx == -1 {
+			continue
+		}
+		left, err := o.conditionOperand(term[:idx], context)
+		if err != nil {
+			return false, err
+		}
+		right, err := o.conditionOperand(term[idx+len(op):], context)
+		if err != nil {
+			return false, err
+		}
+		return compareOperands(left, right, op), nil
+	}
+
+	if strings.HasPrefix(term, "!") {
+		ok, err := o.evaluateConditionTerm(strings.TrimSpace(term[1:]), context)
+		return !ok, err
+	}
+
+	value, err := o.conditionOperand(term, context)
+	if err != nil {
+		return false, err
+	}
+	return isTruthy(value), nil
+}
+
+// conditionOperand parses a literal or resolves a variable path
+func (o *Orchestrator) conditionOperand(raw string, context map[string]interface{}) (interface{}, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return nil, fmt.Errorf("missing operand")
+	}
+
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1], nil
+	}
+
+	switch s {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null", "nil", "none":
+		return nil, nil
+	}
+
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+
+	// resolveVariable returns the path itself when it runs off the context
+	value := o.resolveVariable(s, context)
+	if path, ok := value.(string); ok && path == s {
+		return nil, nil
+	}
+	return value, nil
+}
+
+// compareOperands compares numerically when both sides are numbers, and as
+// strings otherwise
+func compareOperands(left, right interface{}, op string) bool {
+	lf, lok := conditionNumber(left)
+	rf, rok := conditionNumber(right)
+	if lok && rok {
+		switch op {
+		case "==":
+			return lf == rf
+		case "!=":
+			return lf != rf
+		case ">":
+			return lf > rf
+		case "<":
+			return lf < rf
+		case ">=":
+			return lf >= rf
+		case "<=":
+			return lf <= rf
+		}
+	}
+
+	if op == "==" || op == "!=" {
+		equal := (left == nil && right == nil) ||
+			(left != nil && right != nil && fmt.Sprintf("%v", left) == fmt.Sprintf("%v", right))
+		return equal == (op == "==")
+	}
+
+	// Ordering against a missing value is never true
+	if left == nil || right == nil {
+		return false
+	}
+	ls, rs := fmt.Sprintf("%v", left), fmt.Sprintf("%v", right)
+	switch op {
+	case ">":
+		return ls > rs
+	case "<":
+		return ls < rs
+	case ">=":
+		return ls >= rs
+	case "<=":
+		return ls <= rs
+	}
+	return false
+}
+
+// conditionNumber converts numbers and numeric strings (e.g. string inputs)
+// to float64
+func conditionNumber(v interface{}) (float64, bool) {
+	if s, ok := v.(string); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return f, err == nil
+	}
+	return toFloat(v)
+}
+
+// isTruthy reports whether a resolved value counts as true
+func isTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case error:
+		return v != nil
+	case string:
+		return v != "" && v != "false" && v != "0"
+	case map[string]interface{}:
+		return len(v) > 0
+	case []interface{}:
+		return len(v) > 0
+	}
+	if f, ok := toFloat(value); ok {
+		return f != 0
+	}
+	return true
+}
+
+// splitOutsideQuotes splits s on sep, ignoring separators inside quotes
+func splitOutsideQuotes(s, sep string) []string {
+	var parts []string
+	for {
+		idx := indexOutsideQuotes(s, sep)
+		if idx == -1 {
+			return append(parts, s)
+		}
+		parts = append(parts, s[:idx])
+		s = s[idx+len(sep):]
+	}
+}
+
+// indexOutsideQuotes returns the first index of sub in s that is not inside
+// a quoted string, or -1
+func indexOutsideQuotes(s, sub string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '\'' || c == '"' {
+			quote = c
+			continue
+		}
+		if strings.HasPrefix(s[i:], sub) {
+			return i
+		}
+	}
+	return -1
+}
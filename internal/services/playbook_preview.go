@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Step statuses reported by a dry run
+const (
+	StepWouldRun = "would_run"
+	StepSkipped  = "skipped"
+	StepFailed   = "failed"
+)
+
+// PlaybookPreview is the outcome of a dry run: what each step would do
+// given the inputs, without executing any action
+type PlaybookPreview struct {
+	PlaybookID  string                 `json:"playbook_id"`
+	Name        string                 `json:"name"`
+	Environment string                 `json:"environment,omitempty"`
+	Inputs      map[string]interface{} `json:"inputs"`
+	Variables   map[string]interface{} `json:"variables,omitempty"`
+	Steps       []StepPreview          `json:"steps"`
+	Completed   bool                   `json:"completed"`
+	Error       string                 `json:"error,omitempty"`
+}
+
+// StepPreview describes a single step of a dry run
+type StepPreview struct {
+	ID           string                 `json:"id"`
+	Name         string                 `json:"name"`
+	Action       string                 `json:"action,omitempty"`
+	Manual       bool                   `json:"manual,omitempty"`
+	Condition    string                 `json:"condition,omitempty"`
+	ConditionMet *bool                  `json:"condition_met,omitempty"`
+	Status       string                 `json:"status"`
+	Parameters   map[string]interface{} `json:"parameters,omitempty"`
+	Output       interface{}            `json:"output,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+// PreviewPlaybook walks a playbook with the given inputs, interpolating
+// parameters and evaluating conditions, but substitutes each action with its
+// step's sample_output. Nothing is locked, recorded or executed. An empty
+// environment uses the default profile.
+func (o *Orchestrator) PreviewPlaybook(playbookID, environment string, inputs map[string]interface{}) (*PlaybookPreview, error) {
+	playbook, ok := o.GetPlaybook(playbookID)
+	if !ok {
+		return nil, fmt.Errorf("playbook not found: %s", playbookID)
+	}
+
+	if err := validatePlaybookInputs(playbook, inputs); err != nil {
+		return nil, err
+	}
+
+	preview := &PlaybookPreview{
+		PlaybookID: playbookID,
+		Name:       playbook.Playbook.Name,
+		Inputs:     inputs,
+		Steps:      make([]StepPreview, 0, len(playbook.Playbook.Steps)),
+	}
+
+	ctx := WithPlaybookEnvironment(context.Background(), environment)
+	preview.Environment = o.runEnvironment(ctx)
+	if err := o.executeSteps(ctx, playbookID, playbook, inputs, preview); err != nil {
+		preview.Error = err.Error()
+	} else {
+		preview.Completed = true
+	}
+	return preview, nil
+}
+
+// GetPlaybook returns a loaded playbook by ID
+func (o *Orchestrator) GetPlaybook(playbookID string) (Playbook, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	playbook, ok := o.playbooks[playbookID]
+	return playbook, ok
+}
+
+// ListPlaybooks returns all loaded playbooks ordered by ID
+func (o *Orchestrator) ListPlaybooks() []Playbook {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	playbooks := make([]Playbook, 0, len(o.playbooks))
+	for _, playbook := range o.playbooks {
+		playbooks = append(playbooks, playbook)
+	}
+	sort.Slice(playbooks, func(i, j int) bool {
+		return playbooks[i].Playbook.ID < playbooks[j].Playbook.ID
+	})
+	return playbooks
+}
+
+// sampleStepOutput is the no-op stand-in for a step's action in a dry run
+func (o *Orchestrator) sampleStepOutput(step PlaybookStep, context map[string]interface{}) (interface{}, error) {
+	if !o.actions.Has(step.Action) {
+		return nil, fmt.Errorf("unknown action type: %s", step.Action)
+	}
+
+	if step.SampleOutput != nil {
+		return o.interpolateParameters(step.SampleOutput, context), nil
+	}
+	return map[string]interface{}{
+		"dry_run": true,
+		"action":  step.Action,
+	}, nil
+}
+
+// previewManualTask describes the task a manual step would create
+func (o *Orchestrator) previewManualTask(step PlaybookStep, context map[string]interface{}) map[string]interface{} {
+	title := step.Name
+	if title == "" {
+		title = step.ID
+	}
+
+	task := map[string]interface{}{
+		"dry_run": true,
+		"title":   o.interpolateString(title, context),
+		"status":  "open",
+	}
+	if step.Assignee != "" {
+		task["assignee"] = o.interpolateString(step.Assignee, context)
+	}
+	if step.DueIn != "" {
+		task["due_in"] = step.DueIn
+	}
+	return task
+}
+
+// recordCondition adds a step's condition outcome to the preview; steps
+// that will run are completed by recordStep
+func (p *PlaybookPreview) recordCondition(step PlaybookStep, met bool, err error) {
+	entry := newStepPreview(step)
+	entry.ConditionMet = &met
+	switch {
+	case err != nil:
+		entry.Status = StepFailed
+		entry.Error = err.Error()
+	case !met:
+		entry.Status = StepSkipped
+	}
+	p.Steps = append(p.Steps, entry)
+}
+
+// recordStep adds a step's interpolated parameters and stub output to the preview
+func (p *PlaybookPreview) recordStep(step PlaybookStep, params map[string]interface{}, output interface{}, err error) {
+	if n := len(p.Steps); n == 0 || p.Steps[n-1].ID != step.ID || p.Steps[n-1].Status != "" {
+		p.Steps = append(p.Steps, newStepPreview(step))
+	}
+
+	entry := &p.Steps[len(p.Steps)-1]
+	entry.Status = StepWouldRun
+	entry.Parameters = params
+	entry.Output = output
+	if err != nil {
+		entry.Status = StepFailed
+		entry.Error = err.Error()
+	}
+}
+
+func newStepPreview(step PlaybookStep) StepPreview {
+	entry := StepPreview{
+		ID:        step.ID,
+		Name:      step.Name,
+		Manual:    step.Manual,
+		Condition: step.Condition,
+	}
+	if !step.Manual {
+		entry.Action = step.Action
+	}
+	return entry
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// venvReadyMarker is written once a virtualenv's requirements are installed;
+// directories without it are incomplete and rebuilt
+const venvReadyMarker = ".ir-ready"
+
+// venvInstallEnv are server environment variables pip may need (proxies,
+// package indexes) passed to installs but not to scripts
+var venvInstallEnv = []string{
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy",
+	"PIP_INDEX_URL", "PIP_EXTRA_INDEX_URL", "PIP_TRUSTED_HOST", "PIP_CERT",
+}
+
+// requirementPattern matches a PEP 508 requirement: a project name, then
+// optional extras, version specifiers, URL and environment markers. An entry
+// starting with "-" would be taken by pip as an option such as --index-url.
+var requirementPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._-]*[A-Za-z0-9])?\s*(\[[A-Za-z0-9._,\s-]*\])?\s*([<>=!~;@(].*)?$`)
+
+// VirtualenvCache creates Python virtualenvs per distinct interpreter and
+// requirement set under a directory and reuses them across runs
+type VirtualenvCache struct {
+	dir string
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// NewVirtualenvCache creates a cache rooted at dir
+func NewVirtualenvCache(dir string) *VirtualenvCache {
+	return &VirtualenvCache{dir: dir, locks: make(map[string]*sync.Mutex)}
+}
+
+// normalizeRequirements trims, sorts and deduplicates requirements, dropping
+// empty ones and rejecting any that aren't PEP 508 requirements
+func normalizeRequirements(requirements []string) ([]string, error) {
+	seen := make(map[string]bool, len(requirements))
+	reqs := make([]string, 0, len(requirements))
+	for _, req := range requirements {
+		req = strings.TrimSpace(req)
+		if req == "" || seen[req] {
+			continue
+		}
+		if !requirementPattern.MatchString(req) {
+			return nil, fmt.Errorf("invalid requirement %q: expected a PEP 508 requirement such as requests==2.32.3", req)
+		}
+		seen[req] = true
+		reqs = append(reqs, req)
+	}
+	sort.Strings(reqs)
+	return reqs, nil
+}
+
+// venvKey identifies a virtualenv by interpreter and normalized requirements
+func venvKey(python string, requirements []string) string {
+	sum := sha256.Sum256([]byte(python + "\n" + strings.Join(requirements, "\n")))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// Python returns the interpreter of a virtualenv with the requirements
+// installed, creating it on first use. Concurrent callers for the same
+// environment wait for one install.
+func (c *VirtualenvCache) Python(ctx context.Context, python string, requirements []string, timeout time.Duration) (string, error) {
+	requirements, err := normalizeRequirements(requirements)
+	if err != nil {
+		return "", err
+	}
+	key := venvKey(python, requirements)
+	dir, err := filepath.Abs(filepath.Join(c.dir, key))
+	if err != nil {
+		return "", err
+	}
+	interpreter := filepath.Join(dir, "bin", "python")
+
+	lock := c.lock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if _, err := os.Stat(filepath.Join(dir, venvReadyMarker)); err == nil {
+		return interpreter, nil
+	}
+
+	log.Printf("[ACTION] [PYTHON] Creating virtualenv %s for %s", key, strings.Join(requirements, " "))
+	if err := os.RemoveAll(dir); err != nil {
+		return "", fmt.Errorf("failed to clear virtualenv %s: %w", key, err)
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create virtualenv directory: %w", err)
+	}
+
+	env := map[string]string{}
+	for _, name := range venvInstallEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			env[name] = value
+		}
+	}
+	steps := []scriptCommand{
+		{Path: python, Args: []string{"-m", "venv", dir}},
+		// "--" ends pip's options, so no requirement is read as one
+		{Path: interpreter, Args: append([]string{"-m", "pip", "install", "--disable-pip-version-check", "--no-input", "--"}, requirements...)},
+	}
+	if len(requirements) == 0 {
+		steps = steps[:1]
+	}
+	for _, step := range steps {
+		step.Env = env
+		step.Timeout = timeout
+		result, err := runScript(ctx, step)
+		if err == nil && !result.Success {
+			err = fmt.Errorf("exit code %d: %s", result.ExitCode, strings.TrimSpace(lastLines(result.Stderr, 5)))
+		}
+		if err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("failed to create virtualenv %s: %w", key, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, venvReadyMarker), []byte(strings.Join(requirements, "\n")+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("failed to mark virtualenv %s ready: %w", key, err)
+	}
+	return interpreter, nil
+}
+
+// lock returns the mutex serializing work on one virtualenv
+func (c *VirtualenvCache) lock(key string) *sync.Mutex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	lock, ok := c.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		c.locks[key] = lock
+	}
+	return lock
+}
+
+// lastLines returns the last n lines of s
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// quotaWindow is the period execution quotas are counted over
+const quotaWindow = time.Hour
+
+// Quota names besides action types
+const (
+	// QuotaRuns limits playbook runs
+	QuotaRuns = "runs"
+	// QuotaActions limits actions with external side effects
+	QuotaActions = "actions"
+)
+
+// SelfEventQuotaExceeded is the self-monitoring event raised when an
+// execution quota is hit
+const SelfEventQuotaExceeded = "self_quota_exceeded"
+
+// ErrQuotaExceeded is returned for a playbook run or action over its quota
+var ErrQuotaExceeded = errors.New("execution quota exceeded")
+
+// ExecutionScope is what a playbook run's executions count against: the
+// rule that queued it and its tenant. The tenant is the tenant database of
+// the event it matched or the caller that started it by hand, falling back
+// to the event's source or the caller's API key outside any tenant.
+type ExecutionScope struct {
+	RuleID string
+	Tenant string
+}
+
+// QuotaExceededError explains which quota refused an execution
+type QuotaExceededError struct {
+	Quota string // QuotaRuns, QuotaActions or an action type
+	Scope string // "rule <id>" or "tenant <name>"
+	Limit int
+	// RetryAfter is when the oldest counted execution leaves the window
+	RetryAfter time.Duration
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s: %d %s per hour for %s", ErrQuotaExceeded, e.Limit, e.Quota, e.Scope)
+}
+
+func (e *QuotaExceededError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// ExecutionQuotas limit how many playbook runs and actions each rule and
+// each tenant may execute per hour, so a feedback loop between a rule and
+// its own playbook's effects can't run thousands of block_ip actions. Runs
+// and actions are counted from playbook_runs and action_logs, so limits hold
+// across restarts and leader changes.
+type ExecutionQuotas struct {
+	db       *gorm.DB
+	limits   map[string]int
+	actions  *ActionRegistry
+	ingestor *Ingestor
+
+	mu     sync.Mutex
+	raised map[string]time.Time
+}
+
+// QuotaUsage is one quota's use by a rule or tenant over the last hour
+type QuotaUsage struct {
+	Quota string `json:"quota"`
+	Scope string `json:"scope"`
+	Used  int64  `json:"used"`
+	Limit int    `json:"limit"`
+}
+
+// ParseExecutionQuotas parses "runs=100,actions=500,block_ip=50"
+func ParseExecutionQuotas(spec string) (map[string]int, error) {
+	return parseLimits(spec, "execution quota")
+}
+
+// NewExecutionQuotas creates quotas from per-hour limits; executions
+// without a limit aren't restricted. Hitting a quota raises a
+// self-monitoring event through ingestor, when set.
+func NewExecutionQuotas(db *gorm.DB, limits map[string]int, actions *ActionRegistry, ingestor *Ingestor) *ExecutionQuotas {
+	return &ExecutionQuotas{
+		db:       db,
+		limits:   limits,
+		actions:  actions,
+		ingestor: ingestor,
+		raised:   make(map[string]time.Time),
+	}
+}
+
+// externalActions lists the registered actions with external side effects
+func (q *ExecutionQuotas) externalActions() []string {
+	var external []string
+	for _, desc := range q.actions.Catalog() {
+		if desc.SideEffect == SideEffectExternal {
+			external = append(external, desc.Name)
+		}
+	}
+	return external
+}
+
+// actionLogs selects the action logs of the runs in a scope, limited to an
+// action type or, for QuotaActions, to actions with external side effects
+func (q *ExecutionQuotas) actionLogs(s scopePart, quota string) *gorm.DB {
+	runs := q.db.Model(&models.PlaybookRun{}).Select("run_id").Where(s.column+" = ?", s.value)
+	query := q.db.Model(&models.ActionLog{}).Where("run_id IN (?) AND status NOT IN ?", runs,
+		[]models.ActionStatus{models.ActionDryRun, models.ActionAwaitingApproval})
+	if quota == QuotaActions {
+		return query.Where("action_type IN ?", q.externalActions())
+	}
+	return query.Where("action_type = ?", quota)
+}
+
+// CheckRun returns a QuotaExceededError when the scope has used up its
+// playbook runs
+func (q *ExecutionQuotas) CheckRun(scope ExecutionScope) error {
+	if q == nil {
+		return nil
+	}
+	limit, ok := q.limits[QuotaRuns]
+	if !ok {
+		return nil
+	}
+	for _, s := range scope.parts() {
+		query := q.db.Model(&models.PlaybookRun{}).Where(s.column+" = ? AND status <> ?", s.value, models.RunRejected)
+		if err := q.check(query, "started_at", QuotaRuns, s.name, limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckAction returns a QuotaExceededError when the scope has used up its
+// runs of the action type, or its actions with external side effects
+func (q *ExecutionQuotas) CheckAction(scope ExecutionScope, actionType string, effect SideEffect) error {
+	if q == nil {
+		return nil
+	}
+	for _, s := range scope.parts() {
+		if limit, ok := q.limits[actionType]; ok {
+			if err := q.check(q.actionLogs(s, actionType), "created_at", actionType, s.name, limit); err != nil {
+				return err
+			}
+		}
+		if limit, ok := q.limits[QuotaActions]; ok && effect == SideEffectExternal {
+			if err := q.check(q.actionLogs(s, QuotaActions), "created_at", QuotaActions, s.name, limit); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// check counts the executions query selects within the window
+func (q *ExecutionQuotas) check(query *gorm.DB, timeColumn, quota, scope string, limit int) error {
+	since := time.Now().UTC().Add(-quotaWindow)
+	var count int64
+	if err := query.Session(&gorm.Session{}).Where(timeColumn+" >= ?", since).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count %s for %s: %w", quota, scope, err)
+	}
+	if count < int64(limit) {
+		return nil
+	}
+
+	var oldest []time.Time
+	err := query.Session(&gorm.Session{}).Where(timeColumn+" >= ?", since).
+		Order(timeColumn).Limit(1).Pluck(timeColumn, &oldest).Error
+	var retryAfter time.Duration
+	if err == nil && len(oldest) > 0 {
+		retryAfter = time.Until(oldest[0].Add(quotaWindow))
+	}
+	if retryAfter < time.Second {
+		retryAfter = time.Second
+	}
+	return &QuotaExceededError{Quota: quota, Scope: scope, Limit: limit, RetryAfter: retryAfter}
+}
+
+// Usage reports every limited quota's use over the last hour by the rules
+// and tenants that have run playbooks in it
+func (q *ExecutionQuotas) Usage() ([]QuotaUsage, error) {
+	usage := []QuotaUsage{}
+	if q == nil || len(q.limits) == 0 {
+		return usage, nil
+	}
+	since := time.Now().UTC().Add(-quotaWindow)
+
+	quotas := make([]string, 0, len(q.limits))
+	for quota := range q.limits {
+		quotas = append(quotas, quota)
+	}
+	sort.Strings(quotas)
+
+	for _, column := range []string{"rule_id", "tenant"} {
+		var counts []struct {
+			Value string
+			Count int64
+		}
+		if err := q.db.Model(&models.PlaybookRun{}).Select(column+" AS value, COUNT(*) AS count").
+			Where(column+" IS NOT NULL AND started_at >= ? AND status <> ?", since, models.RunRejected).
+			Group(column).Order(column).Scan(&counts).Error; err != nil {
+			return nil, fmt.Errorf("failed to count playbook runs: %w", err)
+		}
+		for _, c := range counts {
+			scope := scopePart{column: column, value: c.Value}.withName()
+			for _, quota := range quotas {
+				used := c.Count
+				if quota != QuotaRuns {
+					if err := q.actionLogs(scope, quota).Where("created_at >= ?", since).Count(&used).Error; err != nil {
+						return nil, fmt.Errorf("failed to count actions: %w", err)
+					}
+				}
+				usage = append(usage, QuotaUsage{Quota: quota, Scope: scope.name, Used: used, Limit: q.limits[quota]})
+			}
+		}
+	}
+	return usage, nil
+}
+
+// Raise reports a quota hit as a self-monitoring event, at most once per
+// quota and scope per window, so the hit opens an incident without adding
+// an event per refused execution
+func (q *ExecutionQuotas) Raise(err error, data map[string]interface{}) {
+	var exceeded *QuotaExceededError
+	if q == nil || q.ingestor == nil || !errors.As(err, &exceeded) {
+		return
+	}
+	key := exceeded.Quota + "|" + exceeded.Scope
+	now := time.Now().UTC()
+	q.mu.Lock()
+	if last, ok := q.raised[key]; ok && now.Sub(last) < quotaWindow {
+		q.mu.Unlock()
+		return
+	}
+	q.raised[key] = now
+	q.mu.Unlock()
+
+	log.Printf("Execution quota hit: %v", exceeded)
+	event := map[string]interface{}{
+		"quota": exceeded.Quota,
+		"scope": exceeded.Scope,
+		"limit": exceeded.Limit,
+	}
+	for k, v := range data {
+		event[k] = v
+	}
+	emitSelfEvent(q.ingestor, SelfEventQuotaExceeded, models.SeverityHigh, event)
+}
+
+// scopePart is one dimension of an execution scope quotas are counted by
+type scopePart struct {
+	column string
+	value  string
+	name   string
+}
+
+func (s scopePart) withName() scopePart {
+	if s.column == "rule_id" {
+		s.name = "rule " + s.value
+	} else {
+		s.name = "tenant " + s.value
+	}
+	return s
+}
+
+// parts returns the scope's rule and tenant, where set
+func (s ExecutionScope) parts() []scopePart {
+	var parts []scopePart
+	if s.RuleID != "" {
+		parts = append(parts, scopePart{column: "rule_id", value: s.RuleID}.withName())
+	}
+	if s.Tenant != "" {
+		parts = append(parts, scopePart{column: "tenant", value: s.Tenant}.withName())
+	}
+	return parts
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RedactedValue replaces hidden fields in API responses
+const RedactedValue = "[REDACTED]"
+
+// RedactionPolicyFile is the YAML layout of the redaction policy
+type RedactionPolicyFile struct {
+	Roles map[string][]string `yaml:"roles"`
+}
+
+// RedactionPolicy lists, per role, the JSON fields hidden from responses
+type RedactionPolicy struct {
+	fields map[Role]map[string]bool
+}
+
+// LoadRedactionPolicy reads a redaction policy file. A missing file yields
+// an empty policy that hides nothing.
+func LoadRedactionPolicy(path string) (*RedactionPolicy, error) {
+	policy := &RedactionPolicy{fields: make(map[Role]map[string]bool)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return policy, nil
+		}
+		return nil, fmt.Errorf("failed to read redaction policy: %w", err)
+	}
+
+	var file RedactionPolicyFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse redaction policy: %w", err)
+	}
+
+	for name, fields := range file.Roles {
+		role, err := ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction policy: %w", err)
+		}
+		set := make(map[string]bool, len(fields))
+		for _, field := range fields {
+			set[field] = true
+		}
+		policy.fields[role] = set
+	}
+	return policy, nil
+}
+
+// Hides reports whether the role has any fields redacted
+func (p *RedactionPolicy) Hides(role Role) bool {
+	return len(p.fields[role]) > 0
+}
+
+// HidesField reports whether the role has field redacted
+func (p *RedactionPolicy) HidesField(role Role, field string) bool {
+	return p.fields[role][field]
+}
+
+// Redact replaces the role's hidden fields, matched by key at any depth, in
+// a decoded JSON value
+func (p *RedactionPolicy) Redact(role Role, value interface{}) interface{} {
+	fields := p.fields[role]
+	if len(fields) == 0 {
+		return value
+	}
+	return redactValue(fields, value)
+}
+
+func redactValue(fields map[string]bool, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range v {
+			if fields[key] {
+				if inner != nil {
+					v[key] = RedactedValue
+				}
+				continue
+			}
+			v[key] = redactValue(fields, inner)
+		}
+	case []interface{}:
+		for i, inner := range v {
+			v[i] = redactValue(fields, inner)
+		}
+	}
+	return value
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// ActionTypeReopen is the action log type recording an incident reopen
+const ActionTypeReopen = "incident_reopen"
+
+// ErrIncidentNotResolved is returned when reopening an incident that isn't
+// resolved
+var ErrIncidentNotResolved = errors.New("incident is not resolved")
+
+// ErrInvalidReopenStatus is returned when reopening an incident to resolved
+var ErrInvalidReopenStatus = errors.New("cannot reopen an incident to resolved")
+
+// ReopenIncident moves a resolved incident back to status (open when empty)
+// on behalf of by, counting the reopen and recording the reason and the
+// previous resolution in the incident's action log. The move must be allowed
+// by the incident's workflow.
+func ReopenIncident(db *gorm.DB, workflows *Workflows, incidentID, by, reason string, status models.IncidentStatus) (*models.Incident, error) {
+	if status == "" {
+		status = models.StatusOpen
+	}
+	if status == models.StatusResolved {
+		return nil, ErrInvalidReopenStatus
+	}
+
+	var incident models.Incident
+	if err := db.First(&incident, "incident_id = ?", incidentID).Error; err != nil {
+		return nil, err
+	}
+	if incident.Status != models.StatusResolved {
+		return nil, ErrIncidentNotResolved
+	}
+
+	params, _ := json.Marshal(map[string]interface{}{
+		"reason":        reason,
+		"status":        status,
+		"resolved_at":   incident.ResolvedAt,
+		"resolved_by":   incident.ResolvedBy,
+		"reopened_by":   by,
+		"resolution":    incident.Resolution,
+		"reopen_number": incident.ReopenCount + 1,
+	})
+	incident.SetStatus(status, by)
+	if err := workflows.CheckTransition(&incident, models.StatusResolved); err != nil {
+		return nil, err
+	}
+	if err := db.Save(&incident).Error; err != nil {
+		return nil, fmt.Errorf("failed to reopen incident: %w", err)
+	}
+
+	completed := time.Now().UTC()
+	entry := &models.ActionLog{
+		ActionType:  ActionTypeReopen,
+		Status:      models.ActionCompleted,
+		IncidentID:  &incident.IncidentID,
+		Parameters:  string(params),
+		CompletedAt: &completed,
+		Notes:       fmt.Sprintf("Reopened by %s: %s", by, reason),
+	}
+	if err := db.Create(entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to record reopen: %w", err)
+	}
+	return &incident, nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// maxReprocessBatch bounds the events requeued at once
+const maxReprocessBatch = 10000
+
+// ErrInvalidReprocessFilter is returned for a filter that can't select events
+var ErrInvalidReprocessFilter = errors.New("invalid reprocess filter")
+
+// ReprocessFilter selects events to run detection on again. Without
+// EventIDs or IncludeProcessed only events never marked processed are
+// selected, and only once they are older than the grace period, so events
+// still queued for detection aren't evaluated twice.
+type ReprocessFilter struct {
+	From     *time.Time `json:"from"`
+	To       *time.Time `json:"to"`
+	Source   string     `json:"source"`
+	EventIDs []string   `json:"event_ids"`
+	// IncludeProcessed also selects events detection already finished
+	IncludeProcessed bool `json:"include_processed"`
+	Limit            int  `json:"limit"`
+	// Tenant selects from a tenant's database instead of the shared one
+	Tenant string `json:"tenant"`
+}
+
+// Reprocessor requeues events whose detection never finished, for example
+// because the process stopped while they were queued
+type Reprocessor struct {
+	db        *gorm.DB
+	tenants   *database.Tenants
+	detection *DetectionEngine
+	// grace is how long an event may stay unprocessed before it's stuck
+	grace time.Duration
+	// maxAttempts bounds the sweeper's requeues of one event, so an event
+	// that crashes detection doesn't do so forever
+	maxAttempts int
+}
+
+// NewReprocessor creates a reprocessor that considers events unprocessed
+// for longer than grace to be stuck, in the shared database and each
+// tenant's
+func NewReprocessor(db *gorm.DB, tenants *database.Tenants, detection *DetectionEngine, grace time.Duration, maxAttempts int) *Reprocessor {
+	return &Reprocessor{db: db, tenants: tenants, detection: detection, grace: grace, maxAttempts: maxAttempts}
+}
+
+// Sweep requeues stuck events that haven't used up their attempts
+func (r *Reprocessor) Sweep() error {
+	for _, tenant := range append([]string{""}, r.tenants.Names()...) {
+		db := r.db
+		if tenant != "" {
+			db = r.tenants.Lookup(tenant)
+		}
+		query := db.Model(&models.Event{}).
+			Where("processed_at IS NULL AND created_at < ?", time.Now().Add(-r.grace))
+		if r.maxAttempts > 0 {
+			query = query.Where("reprocess_count < ?", r.maxAttempts)
+		}
+		queued, err := r.requeue(db, tenant, query, maxReprocessBatch)
+		if err != nil {
+			return err
+		}
+		if queued > 0 && tenant != "" {
+			log.Printf("Requeued %d unprocessed events of tenant %s for detection", queued, tenant)
+		} else if queued > 0 {
+			log.Printf("Requeued %d unprocessed events for detection", queued)
+		}
+	}
+	return nil
+}
+
+// Reprocess requeues the events a filter selects, oldest first, and returns
+// how many were queued
+func (r *Reprocessor) Reprocess(filter ReprocessFilter) (int, error) {
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return 0, fmt.Errorf("%w: to is before from", ErrInvalidReprocessFilter)
+	}
+	if filter.Limit < 0 || filter.Limit > maxReprocessBatch {
+		return 0, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidReprocessFilter, maxReprocessBatch)
+	}
+	limit := filter.Limit
+	if limit == 0 {
+		limit = 1000
+	}
+	db := r.db
+	if filter.Tenant != "" {
+		if db = r.tenants.Lookup(filter.Tenant); db == nil {
+			return 0, fmt.Errorf("%w: unknown tenant %s", ErrInvalidReprocessFilter, filter.Tenant)
+		}
+	}
+
+	query := db.Model(&models.Event{})
+	if len(filter.EventIDs) > 0 {
+		query = query.Where("event_id IN ?", filter.EventIDs)
+	} else if !filter.IncludeProcessed {
+		query = query.Where("processed_at IS NULL AND created_at < ?", time.Now().Add(-r.grace))
+	}
+	if filter.From != nil {
+		query = query.Where("timestamp >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("timestamp < ?", *filter.To)
+	}
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+	return r.requeue(db, filter.Tenant, query, limit)
+}
+
+// requeue counts an attempt for up to limit of the selected events of a
+// database and submits them for detection. The attempt is stored first, so
+// an event that crashes the process is still counted.
+func (r *Reprocessor) requeue(db *gorm.DB, tenant string, query *gorm.DB, limit int) (int, error) {
+	var events []*models.Event
+	if err := query.Order("created_at").Limit(limit).Find(&events).Error; err != nil {
+		return 0, fmt.Errorf("failed to find events to reprocess: %w", err)
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, len(events))
+	for i, event := range events {
+		ids[i] = event.EventID
+	}
+	err := db.Model(&models.Event{}).Where("event_id IN ?", ids).
+		UpdateColumn("reprocess_count", gorm.Expr("reprocess_count + 1")).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to record reprocessing: %w", err)
+	}
+
+	for _, event := range events {
+		event.ReprocessCount++
+		event.Tenant = tenant
+		// Leave the queue and total latency histograms to live events
+		event.CreatedAt = time.Time{}
+	}
+	left := r.detection.Submit(events...)
+	if len(left) > 0 {
+		// Events the full queue turned away weren't attempted
+		ids = ids[:0]
+		for _, event := range left {
+			ids = append(ids, event.EventID)
+		}
+		err := db.Model(&models.Event{}).Where("event_id IN ?", ids).
+			UpdateColumn("reprocess_count", gorm.Expr("reprocess_count - 1")).Error
+		if err != nil {
+			log.Printf("Failed to restore reprocessing attempts: %v", err)
+		}
+	}
+	return len(events) - len(left), nil
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDField is the payload and input key carrying a request ID through
+// queued work
+const RequestIDField = "request_id"
+
+// requestIDPattern bounds client-supplied request IDs to safe log-friendly
+// values
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// requestIDKey is the context key holding the request ID
+type requestIDKey struct{}
+
+// NormalizeRequestID returns id when it is an acceptable request ID, or a
+// new one otherwise
+func NormalizeRequestID(id string) string {
+	if requestIDPattern.MatchString(id) {
+		return id
+	}
+	return uuid.New().String()
+}
+
+// WithRequestID returns a context carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFrom returns the context's request ID, or ""
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestIDPtr returns the context's request ID for an optional column
+func requestIDPtr(ctx context.Context) *string {
+	if id := RequestIDFrom(ctx); id != "" {
+		return &id
+	}
+	return nil
+}
+
+// requestTag formats a request ID for log lines, or "" without one
+func requestTag(id *string) string {
+	if id == nil || *id == "" {
+		return ""
+	}
+	return " [request_id=" + *id + "]"
+}
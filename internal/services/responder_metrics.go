@@ -0,0 +1,193 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// responderGroupColumns are the incident columns responder metrics can be
+// broken down by
+var responderGroupColumns = map[string]string{
+	"assignee": "assigned_to",
+	"team":     "team",
+	"resolver": "resolved_by",
+}
+
+// responderIntervals are the period lengths a breakdown can be bucketed into
+var responderIntervals = map[string]time.Duration{
+	"day":  24 * time.Hour,
+	"week": 7 * 24 * time.Hour,
+}
+
+// UnassignedGroup is the group key of incidents with no assignee, team or
+// resolver
+const UnassignedGroup = "(none)"
+
+// ErrInvalidResponderGroup is returned for a group_by other than assignee,
+// team or resolver
+var ErrInvalidResponderGroup = errors.New("group_by must be assignee, team or resolver")
+
+// ErrInvalidResponderInterval is returned for an interval other than day or
+// week
+var ErrInvalidResponderInterval = errors.New("interval must be day or week")
+
+// ResponderMetricsQuery selects the incidents and breakdown of a responder
+// metrics report
+type ResponderMetricsQuery struct {
+	GroupBy  string
+	From     time.Time
+	To       time.Time
+	Interval string
+}
+
+// ResponderMetrics is a responder workload report over a time range
+type ResponderMetrics struct {
+	GroupBy  string                 `json:"group_by"`
+	From     time.Time              `json:"from"`
+	To       time.Time              `json:"to"`
+	Interval string                 `json:"interval,omitempty"`
+	Groups   []ResponderGroupMetric `json:"groups"`
+}
+
+// ResponderGroupMetric is the workload of one assignee, team or resolver.
+// Active counts incidents open at any point in the range; Handled those
+// resolved within it. Reopened counts active incidents resolved and then
+// reopened at least once, Reopens the total number of reopenings, and
+// ReopenRate is Reopened over the active incidents resolved at least once.
+type ResponderGroupMetric struct {
+	Key                     string   `json:"key"`
+	Active                  int      `json:"active"`
+	Handled                 int      `json:"handled"`
+	StillOpen               int      `json:"still_open"`
+	Reopened                int      `json:"reopened"`
+	Reopens                 int      `json:"reopens"`
+	ReopenRate              float64  `json:"reopen_rate"`
+	MedianResolutionSeconds *float64 `json:"median_resolution_seconds"`
+	// Periods splits Handled and the median by ?interval=, oldest first
+	Periods []ResponderPeriodMetric `json:"periods,omitempty"`
+}
+
+// ResponderPeriodMetric is a group's resolutions within one interval
+type ResponderPeriodMetric struct {
+	Start                   time.Time `json:"start"`
+	Handled                 int       `json:"handled"`
+	MedianResolutionSeconds *float64  `json:"median_resolution_seconds"`
+}
+
+// responderIncident is the slice of an incident responder metrics need
+type responderIncident struct {
+	Key         string
+	Status      models.IncidentStatus
+	CreatedAt   time.Time
+	ResolvedAt  *time.Time
+	ReopenCount int
+}
+
+// ComputeResponderMetrics reports incidents handled, median time to resolve
+// and reopenings per assignee, team or resolver between q.From and q.To.
+// Resolution time runs from creation to the last resolution, so it includes
+// any time spent reopened. Exercise incidents are left out.
+func ComputeResponderMetrics(db *gorm.DB, q ResponderMetricsQuery) (*ResponderMetrics, error) {
+	column, ok := responderGroupColumns[q.GroupBy]
+	if !ok {
+		return nil, ErrInvalidResponderGroup
+	}
+	var interval time.Duration
+	if q.Interval != "" {
+		if interval, ok = responderIntervals[q.Interval]; !ok {
+			return nil, ErrInvalidResponderInterval
+		}
+	}
+
+	var incidents []responderIncident
+	if err := db.Model(&models.Incident{}).
+		Select("COALESCE(NULLIF("+column+", ''), ?) AS key, status, created_at, resolved_at, reopen_count", UnassignedGroup).
+		Where("exercise = ? AND created_at < ?", false, q.To).
+		Where("status <> ? OR resolved_at >= ?", models.StatusResolved, q.From).
+		Scan(&incidents).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch incidents: %w", err)
+	}
+
+	type accumulator struct {
+		metric      ResponderGroupMetric
+		everClosed  int
+		resolutions []float64
+		periods     map[int][]float64
+	}
+	groups := map[string]*accumulator{}
+	for _, incident := range incidents {
+		acc, ok := groups[incident.Key]
+		if !ok {
+			acc = &accumulator{metric: ResponderGroupMetric{Key: incident.Key}, periods: map[int][]float64{}}
+			groups[incident.Key] = acc
+		}
+		acc.metric.Active++
+		resolved := incident.Status == models.StatusResolved && incident.ResolvedAt != nil
+		if incident.ReopenCount > 0 {
+			acc.metric.Reopened++
+			acc.metric.Reopens += incident.ReopenCount
+		}
+		if incident.ReopenCount > 0 || resolved {
+			acc.everClosed++
+		}
+
+		if !resolved || !incident.ResolvedAt.Before(q.To) {
+			acc.metric.StillOpen++
+			continue
+		}
+		seconds := incident.ResolvedAt.Sub(incident.CreatedAt).Seconds()
+		acc.metric.Handled++
+		acc.resolutions = append(acc.resolutions, seconds)
+		if interval > 0 {
+			period := int(incident.ResolvedAt.Sub(q.From) / interval)
+			acc.periods[period] = append(acc.periods[period], seconds)
+		}
+	}
+
+	report := &ResponderMetrics{GroupBy: q.GroupBy, From: q.From, To: q.To, Interval: q.Interval, Groups: []ResponderGroupMetric{}}
+	for _, acc := range groups {
+		acc.metric.MedianResolutionSeconds = median(acc.resolutions)
+		if acc.everClosed > 0 {
+			acc.metric.ReopenRate = float64(acc.metric.Reopened) / float64(acc.everClosed)
+		}
+		if interval > 0 {
+			acc.metric.Periods = []ResponderPeriodMetric{}
+			for start, i := q.From, 0; start.Before(q.To); start, i = start.Add(interval), i+1 {
+				acc.metric.Periods = append(acc.metric.Periods, ResponderPeriodMetric{
+					Start:                   start,
+					Handled:                 len(acc.periods[i]),
+					MedianResolutionSeconds: median(acc.periods[i]),
+				})
+			}
+		}
+		report.Groups = append(report.Groups, acc.metric)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		if report.Groups[i].Handled != report.Groups[j].Handled {
+			return report.Groups[i].Handled > report.Groups[j].Handled
+		}
+		return report.Groups[i].Key < report.Groups[j].Key
+	})
+	return report, nil
+}
+
+// median returns the median of values, or nil when there are none
+func median(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	m := sorted[mid]
+	if len(sorted)%2 == 0 {
+		m = (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return &m
+}
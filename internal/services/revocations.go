@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// Revocation errors
+var (
+	ErrAlreadyRevoked = errors.New("credential already revoked")
+	ErrAPIKeyNotFound = errors.New("API key not found")
+)
+
+// APIKeyStatus is a configured API key as admins see it; the key itself is
+// never shown
+type APIKeyStatus struct {
+	Name        string                    `json:"name"`
+	Role        Role                      `json:"role"`
+	Fingerprint string                    `json:"fingerprint"` // start of the key's SHA-256
+	Revoked     *models.RevokedCredential `json:"revoked"`
+}
+
+// Revocations is the server-side credential denylist. Revoked API keys stay
+// rejected until they are replaced in API_KEYS; revoking a session token also
+// ends its session.
+type Revocations struct {
+	db       *gorm.DB
+	sessions *Sessions
+}
+
+// NewRevocations creates the credential denylist
+func NewRevocations(db *gorm.DB, sessions *Sessions) *Revocations {
+	return &Revocations{db: db, sessions: sessions}
+}
+
+// Find returns the denylist entry for a credential, or nil when it isn't
+// revoked
+func (r *Revocations) Find(token string) (*models.RevokedCredential, error) {
+	var revoked models.RevokedCredential
+	if err := r.db.First(&revoked, "token_hash = ?", hashCredential(token)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to check revocations: %w", err)
+	}
+	return &revoked, nil
+}
+
+// List returns the denylist, newest first
+func (r *Revocations) List() ([]models.RevokedCredential, error) {
+	var revoked []models.RevokedCredential
+	if err := r.db.Order("created_at DESC").Find(&revoked).Error; err != nil {
+		return nil, fmt.Errorf("failed to list revocations: %w", err)
+	}
+	return revoked, nil
+}
+
+// APIKeys reports each configured API key and whether it is revoked, by name
+func (r *Revocations) APIKeys(keys APIKeys) ([]APIKeyStatus, error) {
+	statuses := make([]APIKeyStatus, 0, len(keys))
+	for key, principal := range keys {
+		revoked, err := r.Find(key)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, APIKeyStatus{
+			Name:        principal.Name,
+			Role:        principal.Role,
+			Fingerprint: hashCredential(key)[:12],
+			Revoked:     revoked,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Name != statuses[j].Name {
+			return statuses[i].Name < statuses[j].Name
+		}
+		return statuses[i].Fingerprint < statuses[j].Fingerprint
+	})
+	return statuses, nil
+}
+
+// RevokeAPIKey denylists every key configured for name that isn't revoked
+// yet
+func (r *Revocations) RevokeAPIKey(ctx context.Context, keys APIKeys, name, reason, by string) ([]models.RevokedCredential, error) {
+	found := false
+	var revoked []models.RevokedCredential
+	for key, principal := range keys {
+		if principal.Name != name {
+			continue
+		}
+		found = true
+		entry, err := r.revoke(ctx, key, models.CredentialAPIKey, name, reason, by)
+		if errors.Is(err, ErrAlreadyRevoked) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		revoked = append(revoked, *entry)
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrAPIKeyNotFound, name)
+	}
+	if len(revoked) == 0 {
+		return nil, fmt.Errorf("%w: every key of %s", ErrAlreadyRevoked, name)
+	}
+	return revoked, nil
+}
+
+// RevokeToken denylists a credential by value, such as one found in a leak.
+// A session token's session ends too.
+func (r *Revocations) RevokeToken(ctx context.Context, keys APIKeys, token, reason, by string) (*models.RevokedCredential, error) {
+	if principal, ok := keys[token]; ok {
+		return r.revoke(ctx, token, models.CredentialAPIKey, principal.Name, reason, by)
+	}
+	name := ""
+	if IsSessionToken(token) {
+		if session, err := r.sessions.Lookup(token); err == nil {
+			name = session.User
+		}
+		if err := r.sessions.Revoke(ctx, token); err != nil && !errors.Is(err, ErrSessionNotFound) {
+			return nil, err
+		}
+	}
+	return r.revoke(ctx, token, models.CredentialToken, name, reason, by)
+}
+
+func (r *Revocations) revoke(ctx context.Context, token, kind, name, reason, by string) (*models.RevokedCredential, error) {
+	existing, err := r.Find(token)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrAlreadyRevoked
+	}
+	revoked := &models.RevokedCredential{
+		TokenHash: hashCredential(token),
+		Kind:      kind,
+		Name:      name,
+		Reason:    reason,
+		RevokedBy: by,
+	}
+	if err := r.db.WithContext(ctx).Create(revoked).Error; err != nil {
+		return nil, fmt.Errorf("failed to revoke credential: %w", err)
+	}
+	return revoked, nil
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// RiskScoringSource is the source of events raised by risk scoring
+const RiskScoringSource = "risk-scoring"
+
+// EventRiskThresholdExceeded is the event type raised when an entity's risk
+// score crosses the threshold
+const EventRiskThresholdExceeded = "risk_threshold_exceeded"
+
+// maxRiskContributions caps the contributions returned for an entity
+const maxRiskContributions = 100
+
+// riskEntityFields are the entity types scored and the normalized fields
+// naming them, in order of preference
+var riskEntityFields = []struct {
+	entityType string
+	fields     []string
+}{
+	{"user", []string{"username", "user"}},
+	{"host", []string{"host", "hostname"}},
+	{"ip", []string{"source_ip", "src_ip"}},
+}
+
+// severityRiskPoints are the points a match adds for rules without a
+// risk_score
+var severityRiskPoints = map[models.SeverityLevel]float64{
+	models.SeverityInfo:     1,
+	models.SeverityLow:      5,
+	models.SeverityMedium:   10,
+	models.SeverityHigh:     25,
+	models.SeverityCritical: 50,
+}
+
+// RiskEntityType reports whether t is a scored entity type
+func RiskEntityType(t string) bool {
+	for _, entity := range riskEntityFields {
+		if entity.entityType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// RiskScorer accumulates risk scores for the users, hosts and IP addresses
+// named in events that match rules. Each match adds the rule's risk_score,
+// or points for its severity, to every entity in the event. Scores halve
+// every half-life. When an entity's score reaches the threshold, a
+// risk_threshold_exceeded event is ingested for rules to act on; it is
+// raised again only after the score has decayed below the threshold.
+// Exercise events and risk events themselves add no risk.
+type RiskScorer struct {
+	db        *gorm.DB
+	ingestor  *Ingestor
+	halfLife  time.Duration
+	threshold float64
+}
+
+// NewRiskScorer creates a risk scorer. A zero half-life keeps scores
+// forever; a zero threshold raises no events.
+func NewRiskScorer(db *gorm.DB, ingestor *Ingestor, halfLife time.Duration, threshold float64) *RiskScorer {
+	return &RiskScorer{db: db, ingestor: ingestor, halfLife: halfLife, threshold: threshold}
+}
+
+// EntityRiskScore is an entity's risk with its score decayed to now
+type EntityRiskScore struct {
+	models.EntityRisk
+	CurrentScore float64 `json:"current_score"`
+	Threshold    float64 `json:"threshold"`
+}
+
+// EntityRiskDetail is an entity's risk and the matches that contributed to it
+type EntityRiskDetail struct {
+	EntityRiskScore
+	Contributions []models.RiskContribution `json:"contributions"`
+}
+
+// decay returns a score as of now
+func (r *RiskScorer) decay(score float64, scoredAt, now time.Time) float64 {
+	if r.halfLife <= 0 || scoredAt.IsZero() || !now.After(scoredAt) {
+		return score
+	}
+	return score * math.Pow(0.5, now.Sub(scoredAt).Seconds()/r.halfLife.Seconds())
+}
+
+// rulePoints returns the risk a match of rule adds
+func rulePoints(rule Rule) float64 {
+	if rule.Rule.RiskScore != nil {
+		return *rule.Rule.RiskScore
+	}
+	return severityRiskPoints[models.SeverityLevel(rule.Rule.Severity)]
+}
+
+// Record adds the risk of a rule match to the entities in the event
+func (r *RiskScorer) Record(event *models.Event, normalized map[string]interface{}, rule Rule) {
+	if r == nil || event.Source == RiskScoringSource || isExercise(normalized) {
+		return
+	}
+	points := rulePoints(rule)
+	if points <= 0 {
+		return
+	}
+
+	for _, entity := range riskEntityFields {
+		var value string
+		for _, field := range entity.fields {
+			if v, ok := normalized[field].(string); ok && v != "" {
+				value = v
+				break
+			}
+		}
+		if value == "" {
+			continue
+		}
+		score, crossed, err := r.add(entity.entityType, value, points, rule.Rule.ID, event.EventID)
+		if err != nil {
+			log.Printf("Risk scoring: failed to score %s %s: %v", entity.entityType, value, err)
+			continue
+		}
+		if crossed {
+			r.raise(entity.entityType, value, score, rule, event)
+		}
+	}
+}
+
+// add adds points to an entity's score, reporting the new score and whether
+// it crossed the threshold
+func (r *RiskScorer) add(entityType, value string, points float64, ruleID, eventID string) (float64, bool, error) {
+	now := time.Now().UTC()
+	var score float64
+	crossed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var risk models.EntityRisk
+		err := tx.First(&risk, "entity_type = ? AND entity_value = ?", entityType, value).Error
+		if err == gorm.ErrRecordNotFound {
+			risk = models.EntityRisk{EntityType: entityType, EntityValue: value}
+		} else if err != nil {
+			return err
+		}
+
+		current := r.decay(risk.Score, risk.ScoredAt, now)
+		if risk.ExceededAt != nil && current < r.threshold {
+			risk.ExceededAt = nil
+		}
+		score = current + points
+		risk.Score = score
+		risk.ScoredAt = now
+		risk.LastRuleID = ruleID
+		if r.threshold > 0 && score >= r.threshold && risk.ExceededAt == nil {
+			risk.ExceededAt = &now
+			crossed = true
+		}
+		if err := tx.Save(&risk).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.RiskContribution{
+			EntityType:  entityType,
+			EntityValue: value,
+			RuleID:      ruleID,
+			EventID:     eventID,
+			Points:      points,
+		}).Error
+	})
+	return score, crossed, err
+}
+
+// raise ingests a risk_threshold_exceeded event for an entity
+func (r *RiskScorer) raise(entityType, value string, score float64, rule Rule, event *models.Event) {
+	normalized, err := json.Marshal(map[string]interface{}{
+		"entity":         entityType + ":" + value,
+		"entity_type":    entityType,
+		"entity_value":   value,
+		"risk_score":     math.Round(score*10) / 10,
+		"risk_threshold": r.threshold,
+		"rule_id":        rule.Rule.ID,
+		"event_id":       event.EventID,
+	})
+	if err != nil {
+		log.Printf("Risk scoring: failed to encode event: %v", err)
+		return
+	}
+
+	risk := &models.Event{
+		Timestamp:  time.Now().UTC(),
+		Source:     RiskScoringSource,
+		EventType:  EventRiskThresholdExceeded,
+		Severity:   models.SeverityHigh,
+		RequestID:  event.RequestID,
+		Normalized: string(normalized),
+	}
+	if err := r.ingestor.Ingest(risk); err != nil {
+		log.Printf("Risk scoring: failed to ingest event for %s %s: %v", entityType, value, err)
+		return
+	}
+	log.Printf("Risk scoring: %s %s reached %.1f (threshold %.0f) after rule %s, raised event %s",
+		entityType, value, score, r.threshold, rule.Rule.ID, risk.EventID)
+}
+
+// score returns an entity's risk decayed to now
+func (r *RiskScorer) score(risk models.EntityRisk, now time.Time) EntityRiskScore {
+	current := math.Round(r.decay(risk.Score, risk.ScoredAt, now)*10) / 10
+	return EntityRiskScore{EntityRisk: risk, CurrentScore: current, Threshold: r.threshold}
+}
+
+// List returns the riskiest entities, optionally of one type, with a current
+// score of at least minScore
+func (r *RiskScorer) List(entityType string, minScore float64, limit int) ([]EntityRiskScore, error) {
+	query := r.db.Model(&models.EntityRisk{})
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	var risks []models.EntityRisk
+	if err := query.Find(&risks).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch entity risk: %w", err)
+	}
+
+	now := time.Now().UTC()
+	scores := []EntityRiskScore{}
+	for _, risk := range risks {
+		if score := r.score(risk, now); score.CurrentScore >= minScore && score.CurrentScore > 0 {
+			scores = append(scores, score)
+		}
+	}
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].CurrentScore > scores[j].CurrentScore
+	})
+	if limit > 0 && len(scores) > limit {
+		scores = scores[:limit]
+	}
+	return scores, nil
+}
+
+// Get returns an entity's risk and its most recent contributions
+func (r *RiskScorer) Get(entityType, value string) (*EntityRiskDetail, error) {
+	var risk models.EntityRisk
+	if err := r.db.First(&risk, "entity_type = ? AND entity_value = ?", entityType, value).Error; err != nil {
+		return nil, err
+	}
+	detail := &EntityRiskDetail{EntityRiskScore: r.score(risk, time.Now().UTC())}
+	if err := r.db.Where("entity_type = ? AND entity_value = ?", entityType, value).
+		Order("created_at DESC").Limit(maxRiskContributions).Find(&detail.Contributions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch risk contributions: %w", err)
+	}
+	return detail, nil
+}
+
+// Reset clears an entity's score, e.g. once its activity has been explained
+func (r *RiskScorer) Reset(entityType, value string) error {
+	result := r.db.Model(&models.EntityRisk{}).
+		Where("entity_type = ? AND entity_value = ?", entityType, value).
+		Updates(map[string]interface{}{"score": 0, "scored_at": time.Now().UTC(), "exceeded_at": nil})
+	if result.Error != nil {
+		return fmt.Errorf("failed to reset entity risk: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
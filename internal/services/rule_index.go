@@ -0,0 +1,76 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ruleIndex maps event attributes to the positions of rules that could match
+// them, so an event is only evaluated against plausible rules
+type ruleIndex struct {
+	byEventType map[string][]int
+	bySource    map[string][]int
+	unindexed   []int
+}
+
+// buildRuleIndex indexes each rule by the values its event_type condition
+// accepts, falling back to its source condition, or else marks it unindexed
+func buildRuleIndex(rules []Rule) *ruleIndex {
+	idx := &ruleIndex{
+		byEventType: make(map[string][]int),
+		bySource:    make(map[string][]int),
+	}
+
+	for i, rule := range rules {
+		if values, ok := indexableValues(rule, "event_type"); ok {
+			for _, v := range values {
+				idx.byEventType[v] = append(idx.byEventType[v], i)
+			}
+			continue
+		}
+		if values, ok := indexableValues(rule, "source"); ok {
+			for _, v := range values {
+				idx.bySource[v] = append(idx.bySource[v], i)
+			}
+			continue
+		}
+		idx.unindexed = append(idx.unindexed, i)
+	}
+
+	return idx
+}
+
+// candidates returns the positions of rules to evaluate for an event, in rule order
+func (idx *ruleIndex) candidates(eventType, source string) []int {
+	byType := idx.byEventType[eventType]
+	bySource := idx.bySource[source]
+	if len(bySource) == 0 && len(idx.unindexed) == 0 {
+		return byType
+	}
+
+	merged := make([]int, 0, len(byType)+len(bySource)+len(idx.unindexed))
+	merged = append(merged, byType...)
+	merged = append(merged, bySource...)
+	merged = append(merged, idx.unindexed...)
+	sort.Ints(merged)
+	return merged
+}
+
+// indexableValues returns the exact values a rule requires for field, if the
+// rule constrains that field with an equals or in condition
+func indexableValues(rule Rule, field string) ([]string, bool) {
+	for _, cond := range rule.Rule.Conditions {
+		if cond.Field != field {
+			continue
+		}
+		switch cond.Operator {
+		case "equals":
+			return []string{fmt.Sprintf("%v", cond.Value)}, true
+		case "in":
+			if len(cond.Values) > 0 {
+				return cond.Values, true
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// maxContextEvents caps the related events an incident context summarizes;
+// the most recent are kept
+const maxContextEvents = 100
+
+// incidentContext returns an incident as a playbook run sees it in
+// {{ incident.* }}: its fields, tags, related event IDs and the artifacts
+// (addresses, users, upstream alerts) seen across its events
+func (o *Orchestrator) incidentContext(incidentID string) (map[string]interface{}, error) {
+	var incident models.Incident
+	if err := o.db.First(&incident, "incident_id = ?", incidentID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load incident %s: %w", incidentID, err)
+	}
+
+	eventIDs := decodeStringList(incident.RelatedEvents)
+	recent := eventIDs
+	if len(recent) > maxContextEvents {
+		recent = recent[len(recent)-maxContextEvents:]
+	}
+	var events []models.EventSummary
+	if len(recent) > 0 {
+		if err := o.db.Model(&models.Event{}).Select(models.EventSummaryColumns).
+			Where("event_id IN ?", recent).Order("timestamp ASC").Scan(&events).Error; err != nil {
+			return nil, fmt.Errorf("failed to load events for incident %s: %w", incidentID, err)
+		}
+	}
+	var alerts []models.IncidentAlert
+	if err := o.db.Where("incident_id = ?", incidentID).Order("created_at ASC").Find(&alerts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load alerts for incident %s: %w", incidentID, err)
+	}
+
+	sourceIPs := make(map[string]bool)
+	users := make(map[string]bool)
+	eventTypes := make(map[string]bool)
+	for _, event := range events {
+		if event.SrcIP != nil && *event.SrcIP != "" {
+			sourceIPs[*event.SrcIP] = true
+		}
+		if event.User != nil && *event.User != "" {
+			users[*event.User] = true
+		}
+		eventTypes[event.EventType] = true
+	}
+	alertList := make([]interface{}, 0, len(alerts))
+	for _, alert := range alerts {
+		alertList = append(alertList, map[string]interface{}{
+			"source":      alert.Source,
+			"alert_name":  alert.AlertName,
+			"fingerprint": alert.Fingerprint,
+			"status":      alert.Status,
+		})
+	}
+
+	related := make([]interface{}, len(eventIDs))
+	for i, id := range eventIDs {
+		related[i] = id
+	}
+	tags := decodeStringList(incident.Tags)
+	tagList := make([]interface{}, len(tags))
+	for i, tag := range tags {
+		tagList[i] = tag
+	}
+
+	return map[string]interface{}{
+		"incident_id":       incident.IncidentID,
+		"title":             incident.Title,
+		"description":       incident.Description,
+		"status":            string(incident.Status),
+		"severity":          string(incident.Severity),
+		"category":          incident.Category,
+		"team":              incident.Team,
+		"tags":              tagList,
+		"assigned_to":       derefString(incident.AssignedTo),
+		"triggered_by_rule": incident.TriggeredByRule,
+		"correlation_key":   incident.CorrelationKey,
+		"exercise":          incident.Exercise,
+		"created_at":        incident.CreatedAt.UTC().Format(time.RFC3339),
+		"related_events":    related,
+		"artifacts": map[string]interface{}{
+			"source_ips":  sortedKeys(sourceIPs),
+			"users":       sortedKeys(users),
+			"event_types": sortedKeys(eventTypes),
+			"alerts":      alertList,
+		},
+	}, nil
+}
+
+// eventContext returns the event that triggered a run as {{ event.* }},
+// with its normalized fields
+func (o *Orchestrator) eventContext(eventID string) (map[string]interface{}, error) {
+	var event models.Event
+	if err := o.db.First(&event, "event_id = ?", eventID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load event %s: %w", eventID, err)
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal([]byte(event.Normalized), &normalized); err != nil {
+		normalized = map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"event_id":   event.EventID,
+		"timestamp":  event.Timestamp.UTC().Format(time.RFC3339),
+		"source":     event.Source,
+		"event_type": event.EventType,
+		"severity":   string(event.Severity),
+		"normalized": normalized,
+	}, nil
+}
+
+// injectRunContext adds {{ incident.* }} for runs against an incident and
+// {{ event.* }} for runs triggered by an event. Either is left out, with a
+// warning, if it can't be loaded.
+func (o *Orchestrator) injectRunContext(runContext map[string]interface{}, incidentID, eventID string) {
+	if incidentID != "" {
+		if incident, err := o.incidentContext(incidentID); err != nil {
+			log.Printf("Warning: %v", err)
+		} else {
+			runContext["incident"] = incident
+		}
+	}
+	if eventID != "" {
+		if event, err := o.eventContext(eventID); err != nil {
+			log.Printf("Warning: %v", err)
+		} else {
+			runContext["event"] = event
+		}
+	}
+}
+
+// sortedKeys returns a set's members in order
+func sortedKeys(set map[string]bool) []interface{} {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	out := make([]interface{}, len(keys))
+	for i, key := range keys {
+		out[i] = key
+	}
+	return out
+}
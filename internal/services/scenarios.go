@@ -0,0 +1,327 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// ExerciseField marks synthetic events in their normalized data. Incidents
+// raised from them are flagged as exercises and never correlate with real ones.
+const ExerciseField = "exercise"
+
+// maxScenarioDuration bounds the total delays a scenario may script
+const maxScenarioDuration = time.Hour
+
+// Scenario is a scripted sequence of synthetic events loaded from YAML
+type Scenario struct {
+	Scenario struct {
+		ID          string         `yaml:"id" json:"id"`
+		Name        string         `yaml:"name" json:"name"`
+		Description string         `yaml:"description" json:"description"`
+		Schedule    string         `yaml:"schedule" json:"schedule,omitempty"` // Go duration for recurring runs
+		Steps       []ScenarioStep `yaml:"steps" json:"steps"`
+	} `yaml:"scenario"`
+}
+
+// ScenarioStep injects one event, optionally repeated. String values in
+// normalized may use {{run_id}} and {{random_ip}} placeholders, resolved once
+// per run.
+type ScenarioStep struct {
+	Delay      string                 `yaml:"delay" json:"delay,omitempty"` // wait before the step
+	EventType  string                 `yaml:"event_type" json:"event_type"`
+	Source     string                 `yaml:"source" json:"source"`
+	Severity   string                 `yaml:"severity" json:"severity"`
+	Repeat     int                    `yaml:"repeat" json:"repeat,omitempty"`
+	Interval   string                 `yaml:"interval" json:"interval,omitempty"` // wait between repeats
+	Normalized map[string]interface{} `yaml:"normalized" json:"normalized"`
+	// Vary lists values cycled across repeats, e.g. destination_port: [22, 23, 80]
+	Vary map[string][]interface{} `yaml:"vary" json:"vary,omitempty"`
+}
+
+// ScenarioEngine injects exercise events through the normal ingest path
+type ScenarioEngine struct {
+	db       *gorm.DB
+	ingestor *Ingestor
+
+	mu        sync.RWMutex
+	scenarios map[string]Scenario
+}
+
+// NewScenarioEngine creates a new scenario engine
+func NewScenarioEngine(db *gorm.DB, ingestor *Ingestor) *ScenarioEngine {
+	return &ScenarioEngine{
+		db:        db,
+		ingestor:  ingestor,
+		scenarios: make(map[string]Scenario),
+	}
+}
+
+// LoadScenarios loads all YAML scenarios from the specified directory
+func (se *ScenarioEngine) LoadScenarios(scenariosDir string) error {
+	files, err := filepath.Glob(filepath.Join(scenariosDir, "*.yaml"))
+	if err != nil {
+		return fmt.Errorf("failed to glob scenarios: %w", err)
+	}
+
+	files2, err := filepath.Glob(filepath.Join(scenariosDir, "*.yml"))
+	if err != nil {
+		return fmt.Errorf("failed to glob scenarios: %w", err)
+	}
+	files = append(files, files2...)
+
+	scenarios := make(map[string]Scenario)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Printf("Warning: failed to read scenario file %s: %v", file, err)
+			continue
+		}
+
+		var scenario Scenario
+		if err := yaml.Unmarshal(data, &scenario); err != nil {
+			log.Printf("Warning: failed to parse scenario file %s: %v", file, err)
+			continue
+		}
+		if err := validateScenario(scenario); err != nil {
+			log.Printf("Warning: skipping scenario %s: %v", file, err)
+			continue
+		}
+
+		scenarios[scenario.Scenario.ID] = scenario
+		log.Printf("Loaded scenario: %s (%s)", scenario.Scenario.ID, scenario.Scenario.Name)
+	}
+
+	se.mu.Lock()
+	se.scenarios = scenarios
+	se.mu.Unlock()
+
+	log.Printf("Loaded %d scenarios", len(scenarios))
+	return nil
+}
+
+func validateScenario(scenario Scenario) error {
+	s := scenario.Scenario
+	if s.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("at least one step is required")
+	}
+	if s.Schedule != "" {
+		if _, err := time.ParseDuration(s.Schedule); err != nil {
+			return fmt.Errorf("invalid schedule: %w", err)
+		}
+	}
+
+	var total time.Duration
+	for i, step := range s.Steps {
+		if step.EventType == "" {
+			return fmt.Errorf("step %d: event_type is required", i+1)
+		}
+		delay, err := parseOptionalDuration(step.Delay)
+		if err != nil {
+			return fmt.Errorf("step %d: invalid delay: %w", i+1, err)
+		}
+		interval, err := parseOptionalDuration(step.Interval)
+		if err != nil {
+			return fmt.Errorf("step %d: invalid interval: %w", i+1, err)
+		}
+		total += delay + interval*time.Duration(maxInt(step.Repeat, 1)-1)
+	}
+	if total > maxScenarioDuration {
+		return fmt.Errorf("scripted delays total %s, more than %s", total, maxScenarioDuration)
+	}
+	return nil
+}
+
+// List returns the loaded scenarios sorted by ID
+func (se *ScenarioEngine) List() []Scenario {
+	se.mu.RLock()
+	defer se.mu.RUnlock()
+
+	list := make([]Scenario, 0, len(se.scenarios))
+	for _, scenario := range se.scenarios {
+		list = append(list, scenario)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Scenario.ID < list[j].Scenario.ID
+	})
+	return list
+}
+
+// Get returns a scenario by ID
+func (se *ScenarioEngine) Get(id string) (Scenario, bool) {
+	se.mu.RLock()
+	defer se.mu.RUnlock()
+	scenario, ok := se.scenarios[id]
+	return scenario, ok
+}
+
+// Start records a run and injects the scenario's events in the background
+func (se *ScenarioEngine) Start(id, trigger string) (*models.ExerciseRun, error) {
+	scenario, ok := se.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("scenario not found: %s", id)
+	}
+
+	run := &models.ExerciseRun{ScenarioID: id, Trigger: trigger}
+	if err := se.db.Create(run).Error; err != nil {
+		return nil, fmt.Errorf("failed to record exercise run: %w", err)
+	}
+
+	go se.execute(scenario, run)
+	return run, nil
+}
+
+// RunScheduled injects a scenario synchronously; used by scheduled jobs
+func (se *ScenarioEngine) RunScheduled(id string) error {
+	scenario, ok := se.Get(id)
+	if !ok {
+		return fmt.Errorf("scenario not found: %s", id)
+	}
+
+	run := &models.ExerciseRun{ScenarioID: id, Trigger: "schedule"}
+	if err := se.db.Create(run).Error; err != nil {
+		return fmt.Errorf("failed to record exercise run: %w", err)
+	}
+	return se.execute(scenario, run)
+}
+
+// Schedules returns the scenarios with a recurring schedule and their interval
+func (se *ScenarioEngine) Schedules() map[string]time.Duration {
+	schedules := make(map[string]time.Duration)
+	for _, scenario := range se.List() {
+		if scenario.Scenario.Schedule == "" {
+			continue
+		}
+		interval, _ := time.ParseDuration(scenario.Scenario.Schedule)
+		schedules[scenario.Scenario.ID] = interval
+	}
+	return schedules
+}
+
+// execute injects each step's events and records the outcome on the run
+func (se *ScenarioEngine) execute(scenario Scenario, run *models.ExerciseRun) error {
+	log.Printf("Starting exercise %s (scenario %s)", run.RunID, scenario.Scenario.ID)
+
+	vars := map[string]string{
+		"{{run_id}}":    run.RunID,
+		"{{random_ip}}": fmt.Sprintf("203.0.113.%d", 1+rand.Intn(254)),
+	}
+
+	var runErr error
+	for _, step := range scenario.Scenario.Steps {
+		delay, _ := parseOptionalDuration(step.Delay)
+		interval, _ := parseOptionalDuration(step.Interval)
+		time.Sleep(delay)
+
+		for i := 0; i < maxInt(step.Repeat, 1); i++ {
+			if i > 0 {
+				time.Sleep(interval)
+			}
+			event, err := scenarioEvent(step, i, run, vars)
+			if err == nil {
+				err = se.ingestor.Ingest(event)
+			}
+			if err != nil {
+				runErr = fmt.Errorf("failed to inject %s event: %w", step.EventType, err)
+				break
+			}
+			run.EventsInjected++
+		}
+		if runErr != nil {
+			break
+		}
+	}
+
+	now := time.Now().UTC()
+	run.CompletedAt = &now
+	run.Status = models.RunCompleted
+	if runErr != nil {
+		run.Status = models.RunFailed
+		errMsg := runErr.Error()
+		run.Error = &errMsg
+	}
+	if err := se.db.Save(run).Error; err != nil {
+		log.Printf("Failed to update exercise run %s: %v", run.RunID, err)
+	}
+
+	log.Printf("Exercise %s finished: %d events injected", run.RunID, run.EventsInjected)
+	return runErr
+}
+
+// scenarioEvent builds the i-th event of a step, tagged as an exercise
+func scenarioEvent(step ScenarioStep, i int, run *models.ExerciseRun, vars map[string]string) (*models.Event, error) {
+	normalized := make(map[string]interface{}, len(step.Normalized)+len(step.Vary)+2)
+	for k, v := range step.Normalized {
+		if s, ok := v.(string); ok {
+			for placeholder, value := range vars {
+				s = strings.ReplaceAll(s, placeholder, value)
+			}
+			v = s
+		}
+		normalized[k] = v
+	}
+	for k, values := range step.Vary {
+		if len(values) > 0 {
+			normalized[k] = values[i%len(values)]
+		}
+	}
+	normalized[ExerciseField] = true
+	normalized["exercise_run_id"] = run.RunID
+
+	normalizedJSON, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	source := step.Source
+	if source == "" {
+		source = "exercise"
+	}
+	severity := step.Severity
+	if severity == "" {
+		severity = "info"
+	}
+
+	return &models.Event{
+		Timestamp:  time.Now().UTC(),
+		Source:     source,
+		EventType:  step.EventType,
+		Severity:   models.SeverityLevel(severity),
+		Normalized: string(normalizedJSON),
+	}, nil
+}
+
+// isExercise reports whether normalized event data is tagged as an exercise
+func isExercise(normalized map[string]interface{}) bool {
+	v, _ := normalized[ExerciseField].(bool)
+	return v
+}
+
+func parseOptionalDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(s)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
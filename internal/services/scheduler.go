@@ -0,0 +1,85 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Scheduler runs periodic background jobs (pruners, sweepers, feed pullers)
+// on the elected leader only
+type Scheduler struct {
+	elector *LeaderElector
+	jobs    []scheduledJob
+
+	stop    chan struct{}
+	wg      sync.WaitGroup
+	started atomic.Bool
+	stopped atomic.Bool
+}
+
+type scheduledJob struct {
+	name     string
+	interval time.Duration
+	run      func() error
+}
+
+// NewScheduler creates a new scheduler gated on the given leader elector
+func NewScheduler(elector *LeaderElector) *Scheduler {
+	return &Scheduler{
+		elector: elector,
+		stop:    make(chan struct{}),
+	}
+}
+
+// Register adds a job; it must be called before Start
+func (s *Scheduler) Register(name string, interval time.Duration, run func() error) {
+	s.jobs = append(s.jobs, scheduledJob{name: name, interval: interval, run: run})
+	log.Printf("Registered background job: %s (every %s)", name, interval)
+}
+
+// Start launches a ticker per job; ticks on non-leader instances are skipped
+func (s *Scheduler) Start() {
+	s.started.Store(true)
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go func(job scheduledJob) {
+			defer s.wg.Done()
+			ticker := time.NewTicker(job.interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					if !s.elector.IsLeader() {
+						continue
+					}
+					if err := job.run(); err != nil {
+						log.Printf("Background job %s failed: %v", job.name, err)
+					}
+				case <-s.stop:
+					return
+				}
+			}
+		}(job)
+	}
+}
+
+// Stop signals all jobs to exit and waits for in-flight runs to finish
+func (s *Scheduler) Stop() {
+	s.stopped.Store(true)
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// Started reports whether the job tickers are running, whether or not this
+// instance is the leader
+func (s *Scheduler) Started() bool {
+	return s.started.Load() && !s.stopped.Load()
+}
+
+// Running reports whether the scheduler is active on this instance
+func (s *Scheduler) Running() bool {
+	return s.elector.IsLeader()
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultScriptOutputLimit caps the stdout and stderr kept from a script
+const defaultScriptOutputLimit = 64 * 1024
+
+// scriptKillGrace is how long a killed script's pipes may stay open (held
+// by a background grandchild, say) before the runner stops waiting for them
+const scriptKillGrace = 5 * time.Second
+
+// scriptBaseEnv are the server environment variables scripts inherit; the
+// rest (API keys, tokens) are withheld
+var scriptBaseEnv = []string{"PATH", "HOME", "LANG", "LC_ALL", "TZ", "TMPDIR", "USER"}
+
+// envNameUnsafe matches characters not allowed in generated variable names
+var envNameUnsafe = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// scriptCommand describes a script to run
+type scriptCommand struct {
+	Path        string
+	Args        []string
+	Dir         string
+	Env         map[string]string
+	Stdin       []byte
+	Timeout     time.Duration
+	OutputLimit int
+}
+
+// stepRunPayload returns the playbook context as a JSON document for a
+// script's stdin, with the action's data
+func stepRunPayload(ctx context.Context, data interface{}) ([]byte, error) {
+	payload := map[string]interface{}{"data": data}
+	if id := RequestIDFrom(ctx); id != "" {
+		payload["request_id"] = id
+	}
+	if step, ok := StepRunFrom(ctx); ok {
+		payload["playbook_id"] = step.PlaybookID
+		payload["run_id"] = step.RunID
+		payload["step_id"] = step.StepID
+		payload["incident_id"] = step.IncidentID
+		payload["environment"] = step.Environment
+		payload["event_id"] = step.EventID
+		payload["inputs"] = step.Inputs
+	}
+	return json.Marshal(payload)
+}
+
+// scriptOutputs is the output schema of actions returning a scriptResult
+var scriptOutputs = []ActionOutput{
+	{Name: "exit_code", Type: "integer", Required: true, Description: "Exit code"},
+	{Name: "success", Type: "boolean", Required: true, Description: "Whether the exit code was 0"},
+	{Name: "stdout", Type: "string", Description: "Standard output, up to max_output_bytes"},
+	{Name: "stderr", Type: "string", Description: "Standard error, up to max_output_bytes"},
+	{Name: "stdout_truncated", Type: "boolean", Description: "Whether stdout was cut off"},
+	{Name: "stderr_truncated", Type: "boolean", Description: "Whether stderr was cut off"},
+	{Name: "duration_ms", Type: "integer", Description: "Run time in milliseconds"},
+	{Name: "json", Type: "any", Description: "Stdout parsed as JSON, when it is a JSON document"},
+}
+
+// scriptResult is the structured outcome of a script run
+type scriptResult struct {
+	ExitCode        int    `json:"exit_code"`
+	Success         bool   `json:"success"`
+	Stdout          string `json:"stdout"`
+	Stderr          string `json:"stderr"`
+	StdoutTruncated bool   `json:"stdout_truncated"`
+	StderrTruncated bool   `json:"stderr_truncated"`
+	DurationMs      int64  `json:"duration_ms"`
+	// JSON is stdout parsed as JSON, when stdout or its last line is a JSON
+	// document
+	JSON interface{} `json:"json,omitempty"`
+}
+
+// toMap returns the result as a step output
+func (r scriptResult) toMap() map[string]interface{} {
+	out := map[string]interface{}{
+		"exit_code":        r.ExitCode,
+		"success":          r.Success,
+		"stdout":           r.Stdout,
+		"stderr":           r.Stderr,
+		"stdout_truncated": r.StdoutTruncated,
+		"stderr_truncated": r.StderrTruncated,
+		"duration_ms":      r.DurationMs,
+	}
+	if r.JSON != nil {
+		out["json"] = r.JSON
+	}
+	return out
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the
+// rest, so a chatty script can't exhaust memory
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.buf.Len(); remaining < len(p) {
+		b.truncated = true
+		if remaining > 0 {
+			b.buf.Write(p[:remaining])
+		}
+		// Report a full write so the copy keeps draining the pipe
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// runScript runs a command in its own process group, killing the whole group
+// on timeout or cancellation. A non-zero exit is a result, not an error.
+func runScript(ctx context.Context, command scriptCommand) (scriptResult, error) {
+	if command.OutputLimit <= 0 {
+		command.OutputLimit = defaultScriptOutputLimit
+	}
+	if command.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, command.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, command.Path, command.Args...)
+	cmd.Dir = command.Dir
+	cmd.Env = scriptEnv(command.Env)
+	if command.Stdin != nil {
+		cmd.Stdin = bytes.NewReader(command.Stdin)
+	}
+	stdout := &limitedBuffer{limit: command.OutputLimit}
+	stderr := &limitedBuffer{limit: command.OutputLimit}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = scriptKillGrace
+	setProcessGroup(cmd)
+
+	start := time.Now()
+	err := cmd.Run()
+	result := scriptResult{
+		Stdout:          stdout.buf.String(),
+		Stderr:          stderr.buf.String(),
+		StdoutTruncated: stdout.truncated,
+		StderrTruncated: stderr.truncated,
+		DurationMs:      time.Since(start).Milliseconds(),
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		if errors.Is(ctxErr, context.DeadlineExceeded) {
+			return result, fmt.Errorf("script timed out after %s", command.Timeout)
+		}
+		return result, fmt.Errorf("script cancelled: %w", ctxErr)
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	default:
+		return result, fmt.Errorf("failed to run script: %w", err)
+	}
+	result.Success = result.ExitCode == 0
+
+	if !result.StdoutTruncated {
+		result.JSON = parseJSONOutput(result.Stdout)
+	}
+	return result, nil
+}
+
+// parseJSONOutput parses stdout as a JSON document, or failing that its last
+// non-empty line as an object or array, so scripts can log before printing
+// their result
+func parseJSONOutput(stdout string) interface{} {
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(stdout), &parsed); err == nil {
+		return parsed
+	}
+	trimmed := strings.TrimRight(stdout, "\r\n")
+	last := strings.TrimSpace(trimmed[strings.LastIndex(trimmed, "\n")+1:])
+	if !strings.HasPrefix(last, "{") && !strings.HasPrefix(last, "[") {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(last), &parsed); err == nil {
+		return parsed
+	}
+	return nil
+}
+
+// scriptEnv builds a script's environment from the inherited base variables
+// and extra, which takes precedence
+func scriptEnv(extra map[string]string) []string {
+	env := []string{}
+	for _, name := range scriptBaseEnv {
+		if _, overridden := extra[name]; overridden {
+			continue
+		}
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+
+	names := make([]string, 0, len(extra))
+	for name := range extra {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, name+"="+extra[name])
+	}
+	return env
+}
+
+// stepRunEnv returns the playbook context as environment variables: IR_*
+// identifiers and environment, IR_INPUTS as JSON and each scalar input as IR_INPUT_<NAME>
+func stepRunEnv(ctx context.Context) map[string]string {
+	env := map[string]string{}
+	if id := RequestIDFrom(ctx); id != "" {
+		env["IR_REQUEST_ID"] = id
+	}
+
+	step, ok := StepRunFrom(ctx)
+	if !ok {
+		return env
+	}
+	env["IR_PLAYBOOK_ID"] = step.PlaybookID
+	env["IR_RUN_ID"] = step.RunID
+	env["IR_STEP_ID"] = step.StepID
+	if step.IncidentID != "" {
+		env["IR_INCIDENT_ID"] = step.IncidentID
+	}
+	if step.Environment != "" {
+		env["IR_ENVIRONMENT"] = step.Environment
+	}
+	if step.EventID != "" {
+		env["IR_EVENT_ID"] = step.EventID
+	}
+
+	if inputs, err := json.Marshal(step.Inputs); err == nil {
+		env["IR_INPUTS"] = string(inputs)
+	}
+	for name, value := range step.Inputs {
+		switch value.(type) {
+		case string, bool, int, int64, float64:
+			key := "IR_INPUT_" + envNameUnsafe.ReplaceAllString(strings.ToUpper(name), "_")
+			env[key] = fmt.Sprintf("%v", value)
+		}
+	}
+	return env
+}
+
+// envParam reads an action's env parameter as variables
+func envParam(params map[string]interface{}) map[string]string {
+	env := map[string]string{}
+	values, _ := params["env"].(map[string]interface{})
+	for name, value := range values {
+		env[name] = fmt.Sprintf("%v", value)
+	}
+	return env
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package services
+
+import "os/exec"
+
+// setProcessGroup is a no-op without Unix process groups; cancellation kills
+// only the script's own process
+func setProcessGroup(cmd *exec.Cmd) {}
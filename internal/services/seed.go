@@ -0,0 +1,538 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// seedBatch is how many rows a seed inserts per statement
+const seedBatch = 500
+
+// SeedProfile sets how much sample data a seed generates
+type SeedProfile struct {
+	Events    int
+	Incidents int
+	Runs      int
+	Rules     int
+	Playbooks int
+	// Days is how far back from now event times are spread
+	Days int
+	// Attacks is how many scripted attack chains (brute force, login,
+	// malware, exfiltration) are added, each with its incidents and runs
+	Attacks int
+}
+
+// SeedProfiles are the profiles the seed command offers
+var SeedProfiles = map[string]SeedProfile{
+	"small":             {Events: 500, Incidents: 10, Runs: 10, Rules: 3, Playbooks: 1, Days: 7},
+	"large":             {Events: 200000, Incidents: 2000, Runs: 1000, Rules: 50, Playbooks: 10, Days: 30},
+	"attack-simulation": {Events: 2000, Incidents: 5, Runs: 5, Days: 1, Attacks: 5},
+}
+
+// SeedProfileNames returns the profile names, sorted
+func SeedProfileNames() []string {
+	names := make([]string, 0, len(SeedProfiles))
+	for name := range SeedProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SeedResult counts what a seed created
+type SeedResult struct {
+	Events    int
+	Incidents int
+	Runs      int
+	Actions   int
+	Rules     int
+	Playbooks int
+}
+
+// seedHosts, seedUsers and seedAnalysts are the names sample data draws on
+var (
+	seedHosts     = []string{"web-01", "web-02", "db-01", "build-03", "laptop-ana", "laptop-raj", "laptop-kim", "vpn-gw"}
+	seedUsers     = []string{"alice", "bob", "carol", "dave", "erin", "frank", "svc-backup", "admin"}
+	seedAnalysts  = []string{"analyst-1", "analyst-2", "analyst-3"}
+	seedSources   = map[string]string{"authentication_failed": "auth", "authentication_success": "auth", "process_execution": "edr", "network_connection": "firewall", "file_access": "edr"}
+	seedProcesses = []string{"chrome.exe", "outlook.exe", "powershell.exe", "svchost.exe", "python3", "sshd", "curl"}
+)
+
+// seedCategories are incident categories with the rule each is attributed to
+var seedCategories = []struct{ category, rule, title string }{
+	{"authentication", "auth-001", "Brute Force Login Detection"},
+	{"malware", "mal-001", "Suspicious Process Execution"},
+	{"exfiltration", "exfil-001", "Large Outbound Data Transfer"},
+	{"network", "net-001", "Port Scan Detected"},
+	{"ransomware", "rans-001", "Mass File Encryption"},
+}
+
+// Seeder generates sample events, incidents, playbook runs, rules and
+// playbooks for demos, load tests and reproducing issue reports. Its
+// random source is seeded, so a seed value always yields the same IDs and
+// content; times are relative to when it runs.
+type Seeder struct {
+	db  *gorm.DB
+	rng *rand.Rand
+	now time.Time
+	// RulesDir and PlaybooksDir receive the generated rules and playbooks
+	// as seed-*.yaml files; empty skips them
+	RulesDir     string
+	PlaybooksDir string
+
+	events []seedEvent // stored events, to link incidents to
+	result SeedResult
+}
+
+// seedEvent is what a seeder keeps of an event it stored
+type seedEvent struct {
+	id, source string
+	at         time.Time
+}
+
+// NewSeeder creates a seeder writing to db, its output determined by seed
+func NewSeeder(db *gorm.DB, seed int64) *Seeder {
+	return &Seeder{db: db, rng: rand.New(rand.NewSource(seed)), now: time.Now().UTC()}
+}
+
+// Seed generates a profile's data. Events are stored already processed, so
+// detection and the reprocess sweeper leave them alone; the incidents and
+// runs are written as detection and playbooks would have left them.
+func (s *Seeder) Seed(ctx context.Context, profile SeedProfile) (*SeedResult, error) {
+	s.db = s.db.WithContext(ctx)
+	if err := s.writeContent(profile); err != nil {
+		return nil, err
+	}
+	if err := s.seedEvents(profile); err != nil {
+		return nil, err
+	}
+	for i := 0; i < profile.Attacks; i++ {
+		if err := s.seedAttack(profile); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.seedIncidents(profile); err != nil {
+		return nil, err
+	}
+	return &s.result, nil
+}
+
+// id returns a UUID drawn from the seeded source
+func (s *Seeder) id() string {
+	return uuid.Must(uuid.NewRandomFromReader(s.rng)).String()
+}
+
+// pick returns a random element of items
+func pick[T any](rng *rand.Rand, items []T) T {
+	return items[rng.Intn(len(items))]
+}
+
+// timeAgo returns a random time within the profile's range
+func (s *Seeder) timeAgo(profile SeedProfile) time.Time {
+	span := time.Duration(max(profile.Days, 1)) * 24 * time.Hour
+	return s.now.Add(-time.Duration(s.rng.Int63n(int64(span))))
+}
+
+// ip returns a random address in a private range, or a public-looking one
+func (s *Seeder) ip(external bool) string {
+	if external {
+		return fmt.Sprintf("%d.%d.%d.%d", 23+s.rng.Intn(180), s.rng.Intn(256), s.rng.Intn(256), 1+s.rng.Intn(254))
+	}
+	return fmt.Sprintf("10.%d.%d.%d", s.rng.Intn(4), s.rng.Intn(256), 1+s.rng.Intn(254))
+}
+
+// event builds an event of a type with plausible normalized fields
+func (s *Seeder) event(eventType string, at time.Time, fields map[string]interface{}) *models.Event {
+	normalized := map[string]interface{}{
+		"event_type": eventType,
+		"host":       pick(s.rng, seedHosts),
+		"username":   pick(s.rng, seedUsers),
+	}
+	switch eventType {
+	case "authentication_failed", "authentication_success":
+		normalized["source_ip"] = s.ip(s.rng.Intn(4) == 0)
+		normalized["auth_method"] = pick(s.rng, []string{"password", "ssh_key", "sso"})
+	case "process_execution":
+		normalized["process_name"] = pick(s.rng, seedProcesses)
+		normalized["parent_process"] = pick(s.rng, []string{"explorer.exe", "cmd.exe", "bash", "systemd"})
+	case "network_connection":
+		normalized["source_ip"] = s.ip(false)
+		normalized["destination_ip"] = s.ip(true)
+		normalized["destination_port"] = pick(s.rng, []int{22, 53, 80, 443, 3389, 8080})
+		normalized["bytes_out"] = s.rng.Intn(200000)
+	case "file_access":
+		normalized["file_path"] = fmt.Sprintf("/srv/share/%s/report-%d.docx", pick(s.rng, seedUsers), s.rng.Intn(1000))
+		normalized["operation"] = pick(s.rng, []string{"read", "write", "rename"})
+	}
+	for k, v := range fields {
+		normalized[k] = v
+	}
+
+	severity := models.SeverityLevel("info")
+	if eventType == "authentication_failed" || eventType == "process_execution" && s.rng.Intn(10) == 0 {
+		severity = "low"
+	}
+	encoded, _ := json.Marshal(normalized)
+	received := at.Add(time.Duration(s.rng.Intn(2000)) * time.Millisecond)
+	processed := received.Add(time.Duration(5+s.rng.Intn(50)) * time.Millisecond)
+	return &models.Event{
+		EventID:     s.id(),
+		Timestamp:   at,
+		OccurredAt:  &at,
+		ReceivedAt:  &received,
+		Source:      seedSources[eventType],
+		EventType:   eventType,
+		Severity:    severity,
+		RawData:     string(encoded),
+		Normalized:  string(encoded),
+		CreatedAt:   received,
+		ProcessedAt: &processed,
+	}
+}
+
+// store inserts events in batches and keeps them for linking
+func (s *Seeder) store(events []*models.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := s.db.CreateInBatches(events, seedBatch).Error; err != nil {
+		return fmt.Errorf("failed to store events: %w", err)
+	}
+	for _, event := range events {
+		s.events = append(s.events, seedEvent{id: event.EventID, source: event.Source, at: event.Timestamp})
+	}
+	s.result.Events += len(events)
+	return nil
+}
+
+// seedEvents generates the background event volume, mostly routine logins,
+// processes and connections
+func (s *Seeder) seedEvents(profile SeedProfile) error {
+	types := []string{
+		"authentication_success", "authentication_success", "authentication_failed",
+		"process_execution", "process_execution", "network_connection", "network_connection", "file_access",
+	}
+	batch := make([]*models.Event, 0, seedBatch)
+	for i := 0; i < profile.Events; i++ {
+		batch = append(batch, s.event(pick(s.rng, types), s.timeAgo(profile), nil))
+		if len(batch) == seedBatch {
+			if err := s.store(batch); err != nil {
+				return err
+			}
+			batch = make([]*models.Event, 0, seedBatch)
+		}
+	}
+	return s.store(batch)
+}
+
+// seedAttack scripts one intrusion: a burst of failed logins from an
+// outside address, a successful login, a suspicious process on the host
+// and a large outbound transfer, with an incident and response run for each
+// detected stage
+func (s *Seeder) seedAttack(profile SeedProfile) error {
+	attacker, host, user := s.ip(true), pick(s.rng, seedHosts), pick(s.rng, seedUsers)
+	start := s.timeAgo(profile)
+	at := func(offset time.Duration) time.Time { return start.Add(offset) }
+
+	var bruteForce []*models.Event
+	for i := 0; i < 20+s.rng.Intn(30); i++ {
+		bruteForce = append(bruteForce, s.event("authentication_failed", at(time.Duration(i)*2*time.Second),
+			map[string]interface{}{"source_ip": attacker, "host": host, "username": user}))
+	}
+	login := s.event("authentication_success", at(2*time.Minute), map[string]interface{}{"source_ip": attacker, "host": host, "username": user})
+	malware := s.event("process_execution", at(6*time.Minute), map[string]interface{}{
+		"host": host, "username": user,
+		"process_name": fmt.Sprintf("%x.exe", s.rng.Uint32()), "parent_process": "cmd.exe",
+	})
+	exfil := s.event("network_connection", at(25*time.Minute), map[string]interface{}{
+		"host": host, "username": user, "destination_ip": attacker, "destination_port": 443,
+		"bytes_out": 500000000 + s.rng.Intn(500000000),
+	})
+	chain := append(bruteForce, login, malware, exfil)
+	if err := s.store(chain); err != nil {
+		return err
+	}
+
+	stages := []struct {
+		category int
+		severity models.SeverityLevel
+		events   []*models.Event
+		playbook string
+	}{
+		{0, "high", chain[:len(bruteForce)+1], "brute-force-response"},
+		{1, "critical", []*models.Event{malware}, ""},
+		{2, "critical", []*models.Event{exfil}, ""},
+	}
+	for _, stage := range stages {
+		c := seedCategories[stage.category]
+		trigger := stage.events[len(stage.events)-1]
+		incident := &models.Incident{
+			IncidentID:      s.id(),
+			CreatedAt:       trigger.Timestamp.Add(time.Second),
+			Status:          models.StatusInvestigating,
+			Severity:        stage.severity,
+			Category:        c.category,
+			Title:           c.title,
+			Description:     fmt.Sprintf("%s on %s (%s from %s)", c.title, host, user, attacker),
+			TriggeredByRule: c.rule,
+			CorrelationKey:  c.rule + ":" + attacker,
+			Tags:            `["attack-simulation"]`,
+		}
+		ids := make([]string, len(stage.events))
+		for i, event := range stage.events {
+			ids[i] = event.EventID
+		}
+		incident.RelatedEvents = encodeIDList(ids)
+		incident.UpdatedAt = incident.CreatedAt
+		if err := s.db.Create(incident).Error; err != nil {
+			return fmt.Errorf("failed to store incident: %w", err)
+		}
+		s.result.Incidents++
+		if stage.playbook != "" {
+			ref := seedEvent{id: trigger.EventID, source: trigger.Source}
+			if err := s.seedRun(incident, stage.playbook, ref, map[string]interface{}{"source_ip": attacker}, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// seedIncidents generates incidents across categories, statuses and
+// assignees, each linked to a few stored events, and playbook runs for them
+func (s *Seeder) seedIncidents(profile SeedProfile) error {
+	if len(s.events) == 0 && profile.Incidents > 0 {
+		return fmt.Errorf("incidents need events to link to")
+	}
+	statuses := []models.IncidentStatus{models.StatusOpen, models.StatusInvestigating, models.StatusContained, models.StatusResolved, models.StatusResolved}
+	severities := []models.SeverityLevel{"low", "medium", "medium", "high", "critical"}
+	playbooks := []string{"brute-force-response", "port-scan-response"}
+	for i := 0; i < profile.Playbooks; i++ {
+		playbooks = append(playbooks, fmt.Sprintf("seed-playbook-%02d", i+1))
+	}
+
+	incidents := make([]*models.Incident, 0, profile.Incidents)
+	for i := 0; i < profile.Incidents; i++ {
+		c := pick(s.rng, seedCategories)
+		related := make([]string, 1+s.rng.Intn(5))
+		first := pick(s.rng, s.events)
+		related[0] = first.id
+		for j := 1; j < len(related); j++ {
+			related[j] = pick(s.rng, s.events).id
+		}
+		created := first.at.Add(time.Duration(1+s.rng.Intn(60)) * time.Second)
+		incident := &models.Incident{
+			IncidentID:      s.id(),
+			CreatedAt:       created,
+			UpdatedAt:       created,
+			Status:          pick(s.rng, statuses),
+			Severity:        pick(s.rng, severities),
+			Category:        c.category,
+			Title:           c.title,
+			Description:     fmt.Sprintf("%s on %s", c.title, pick(s.rng, seedHosts)),
+			TriggeredByRule: c.rule,
+			CorrelationKey:  fmt.Sprintf("%s:seed-%d", c.rule, i),
+			RelatedEvents:   encodeIDList(related),
+			Tags:            fmt.Sprintf(`[%q]`, pick(s.rng, []string{"seed", "phishing", "vpn", "prod", "endpoint"})),
+		}
+		if s.rng.Intn(3) > 0 {
+			analyst := pick(s.rng, seedAnalysts)
+			incident.AssignedTo = &analyst
+		}
+		if incident.Status == models.StatusResolved {
+			resolved := created.Add(time.Duration(10+s.rng.Intn(2880)) * time.Minute)
+			if resolved.After(s.now) {
+				resolved = s.now
+			}
+			by := pick(s.rng, seedAnalysts)
+			incident.ResolvedAt, incident.ResolvedBy = &resolved, &by
+			incident.Resolution = pick(s.rng, []string{"true_positive", "false_positive", "benign"})
+			incident.UpdatedAt = resolved
+		}
+		incidents = append(incidents, incident)
+	}
+	if len(incidents) > 0 {
+		if err := s.db.CreateInBatches(incidents, seedBatch).Error; err != nil {
+			return fmt.Errorf("failed to store incidents: %w", err)
+		}
+		s.result.Incidents += len(incidents)
+	}
+
+	for i := 0; i < profile.Runs && len(incidents) > 0; i++ {
+		incident := pick(s.rng, incidents)
+		trigger := pick(s.rng, s.events)
+		if err := s.seedRun(incident, pick(s.rng, playbooks), trigger, nil, s.rng.Intn(8) > 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedRun records a playbook run for an incident with its action log
+func (s *Seeder) seedRun(incident *models.Incident, playbookID string, trigger seedEvent, inputs map[string]interface{}, succeeded bool) error {
+	if inputs == nil {
+		inputs = map[string]interface{}{}
+	}
+	inputs["incident_id"] = incident.IncidentID
+	encoded, _ := json.Marshal(inputs)
+	started := incident.CreatedAt.Add(time.Duration(1+s.rng.Intn(10)) * time.Second)
+	completed := started.Add(time.Duration(200+s.rng.Intn(5000)) * time.Millisecond)
+	run := &models.PlaybookRun{
+		RunID:       s.id(),
+		StartedAt:   started,
+		CompletedAt: &completed,
+		PlaybookID:  playbookID,
+		IncidentID:  &incident.IncidentID,
+		Status:      models.RunCompleted,
+		EventID:     &trigger.id,
+		RuleID:      &incident.TriggeredByRule,
+		Tenant:      &trigger.source,
+		Inputs:      string(encoded),
+	}
+	if !succeeded {
+		run.Status = models.RunFailed
+		message := "step-2: action timed out"
+		run.Error = &message
+	}
+	if err := s.db.Create(run).Error; err != nil {
+		return fmt.Errorf("failed to store playbook run: %w", err)
+	}
+	s.result.Runs++
+
+	steps := []string{"block_ip", "log_action", "update_incident", "notify"}
+	actions := make([]*models.ActionLog, 0, len(steps))
+	at := started
+	for i, actionType := range steps {
+		stepID := fmt.Sprintf("step-%d", i+1)
+		took := 20 + s.rng.Intn(800)
+		done := at.Add(time.Duration(took) * time.Millisecond)
+		action := &models.ActionLog{
+			ActionID:      s.id(),
+			CreatedAt:     at,
+			CompletedAt:   &done,
+			ActionType:    actionType,
+			Status:        models.ActionCompleted,
+			IncidentID:    &incident.IncidentID,
+			PlaybookID:    &run.PlaybookID,
+			StepID:        &stepID,
+			RunID:         &run.RunID,
+			Parameters:    string(encoded),
+			ExecutionTime: took,
+		}
+		if !succeeded && i == 1 {
+			action.Status = models.ActionFailed
+			action.Error = run.Error
+			actions = append(actions, action)
+			break
+		}
+		actions = append(actions, action)
+		at = done
+	}
+	if err := s.db.Create(actions).Error; err != nil {
+		return fmt.Errorf("failed to store action log: %w", err)
+	}
+	s.result.Actions += len(actions)
+	return nil
+}
+
+// encodeIDList encodes event IDs as an incident's related events
+func encodeIDList(ids []string) string {
+	encoded, _ := json.Marshal(ids)
+	return string(encoded)
+}
+
+// writeContent writes the profile's generated rules and playbooks. Each
+// rule matches one of the sample event types; generated playbooks only log
+// and notify, so running them has no side effects.
+func (s *Seeder) writeContent(profile SeedProfile) error {
+	eventTypes := []string{"authentication_failed", "process_execution", "network_connection", "file_access"}
+	fields := map[string]string{"authentication_failed": "username", "process_execution": "host", "network_connection": "destination_ip", "file_access": "username"}
+	if s.RulesDir != "" {
+		for i := 0; i < profile.Rules; i++ {
+			eventType := pick(s.rng, eventTypes)
+			rule := fmt.Sprintf(`rule:
+  id: seed-rule-%03d
+  name: "Seed Rule %03d"
+  description: "Generated by the seed command: repeated %s events"
+  category: seed
+  severity: %s
+  enabled: true
+  tags: [seed]
+
+  conditions:
+    - field: event_type
+      operator: equals
+      value: %q
+    - operator: count
+      group_by: [%s]
+      threshold: %d
+      timewindow: %d
+
+  actions:
+    - type: create_incident
+      priority: medium
+`, i+1, i+1, eventType, pick(s.rng, []string{"low", "medium", "high"}), eventType, fields[eventType], 20+s.rng.Intn(80), 60*(1+s.rng.Intn(10)))
+			if profile.Playbooks > 0 {
+				rule += fmt.Sprintf("    - type: execute_playbook\n      playbook: \"seed-playbook-%02d\"\n", 1+s.rng.Intn(profile.Playbooks))
+			}
+			if err := writeSeedFile(s.RulesDir, fmt.Sprintf("seed-rule-%03d.yaml", i+1), rule); err != nil {
+				return err
+			}
+			s.result.Rules++
+		}
+	}
+	if s.PlaybooksDir != "" {
+		for i := 0; i < profile.Playbooks; i++ {
+			playbook := fmt.Sprintf(`playbook:
+  id: seed-playbook-%02d
+  name: "Seed Playbook %02d"
+  description: "Generated by the seed command; logs and notifies only"
+  version: "1.0"
+
+  inputs:
+    - name: incident_id
+      required: true
+
+  steps:
+    - id: step-1
+      name: "Log Incident"
+      action: log_action
+      parameters:
+        message: "Seed playbook %02d ran for {{ inputs.incident_id }}"
+        level: "info"
+
+    - id: step-2
+      name: "Notify"
+      action: notify
+      parameters:
+        channel: "console"
+        message: "{{ incident.title }} ({{ incident.severity }})"
+`, i+1, i+1, i+1)
+			if err := writeSeedFile(s.PlaybooksDir, fmt.Sprintf("seed-playbook-%02d.yaml", i+1), playbook); err != nil {
+				return err
+			}
+			s.result.Playbooks++
+		}
+	}
+	return nil
+}
+
+// writeSeedFile writes a generated content file, replacing one from an
+// earlier seed
+func writeSeedFile(dir, name, content string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// Seen-value conditions track the values of field per entity, the values of
+// the condition's group_by fields, in the seen_values table:
+//
+//   - first_seen matches the first time a value is seen for an entity, e.g.
+//     the first login of a user from a country
+//   - rare matches while a value has been seen fewer than threshold times
+//     before for the entity
+//
+// Without group_by, values are tracked across all events. With timewindow
+// (seconds), a value not seen for that long is forgotten and counts as new
+// again. learning_period (seconds) records values without matching until the
+// condition has been observing that long, so a new rule doesn't flag every
+// value it hasn't met yet. Events lacking the field or a group_by field don't
+// match and aren't recorded.
+
+// isSeenOperator reports whether op is a seen-value operator
+func isSeenOperator(op string) bool {
+	return op == "first_seen" || op == "rare"
+}
+
+// validateSeenCondition checks a seen-value condition when its rule is loaded
+func validateSeenCondition(c Condition) error {
+	if c.Field == "" {
+		return fmt.Errorf("%s condition needs a field", c.Operator)
+	}
+	if c.Operator == "rare" && c.Threshold <= 0 {
+		return fmt.Errorf("rare condition needs a positive threshold")
+	}
+	if c.TimeWindow < 0 || c.LearningPeriod < 0 {
+		return fmt.Errorf("%s condition has a negative timewindow or learning_period", c.Operator)
+	}
+	for _, field := range append([]string{c.Field}, c.GroupBy...) {
+		if !countFieldPattern.MatchString(field) {
+			return fmt.Errorf("invalid field name %q in %s condition", field, c.Operator)
+		}
+	}
+	return nil
+}
+
+// seenScope names the values a condition of a rule tracks; conditions on the
+// same field with different group_by fields are tracked separately
+func seenScope(ruleID string, c Condition) string {
+	scope := ruleID + ":" + c.Field
+	if len(c.GroupBy) > 0 {
+		scope += "/" + strings.Join(c.GroupBy, ",")
+	}
+	return scope
+}
+
+// seenKey returns the entity and value an event contributes to a seen-value
+// condition. ok is false when the event lacks one of the fields.
+func seenKey(event *models.Event, normalized map[string]interface{}, cond Condition) (entity, value string, ok bool) {
+	parts := make([]string, len(cond.GroupBy))
+	for i, field := range cond.GroupBy {
+		v := eventField(event, normalized, field)
+		if !isScalar(v) {
+			return "", "", false
+		}
+		parts[i] = fmt.Sprintf("%s=%v", field, v)
+	}
+	v := eventField(event, normalized, cond.Field)
+	if !isScalar(v) {
+		return "", "", false
+	}
+	return strings.Join(parts, ","), fmt.Sprintf("%v", v), true
+}
+
+// observeSeenValue decides a seen-value condition for a value observed at
+// at, given the value's record (nil if it was never seen) and when the
+// condition started observing (nil if it hasn't), and returns the updated
+// record
+func observeSeenValue(cond Condition, record *models.SeenValue, scopeStart *time.Time, at time.Time) (bool, models.SeenValue) {
+	var updated models.SeenValue
+	var seen int64
+	if record != nil {
+		updated = *record
+		forgotten := cond.TimeWindow > 0 && at.Sub(record.LastSeen) > time.Duration(cond.TimeWindow)*time.Second
+		if !forgotten {
+			seen = record.Count
+		}
+	}
+
+	matched := seen == 0
+	if cond.Operator == "rare" {
+		matched = seen < int64(cond.Threshold)
+	}
+	if matched && cond.LearningPeriod > 0 &&
+		(scopeStart == nil || at.Sub(*scopeStart) < time.Duration(cond.LearningPeriod)*time.Second) {
+		matched = false
+	}
+
+	if seen == 0 {
+		updated.FirstSeen = at
+		updated.LastSeen = at
+		updated.Count = 1
+	} else {
+		updated.Count++
+		if at.After(updated.LastSeen) {
+			updated.LastSeen = at
+		}
+	}
+	return matched, updated
+}
+
+// evaluateSeenCondition records an event's value for a seen-value condition
+// and reports whether it is new (first_seen) or rare, and how often the value
+// has now been seen
+func (de *DetectionEngine) evaluateSeenCondition(event *models.Event, normalized map[string]interface{}, cond Condition) (bool, int64) {
+	entity, value, ok := seenKey(event, normalized, cond)
+	if !ok {
+		return false, -1
+	}
+	at := event.Timestamp.UTC()
+
+	matched := false
+	seen := int64(-1)
+	err := de.db.Transaction(func(tx *gorm.DB) error {
+		var record *models.SeenValue
+		var existing models.SeenValue
+		err := tx.Where("scope = ? AND entity = ? AND value = ?", cond.seenScope, entity, value).First(&existing).Error
+		switch {
+		case err == nil:
+			record = &existing
+		case err != gorm.ErrRecordNotFound:
+			return err
+		}
+
+		var scopeStart *time.Time
+		if cond.LearningPeriod > 0 {
+			var earliest models.SeenValue
+			err := tx.Where("scope = ?", cond.seenScope).Order("first_seen ASC").First(&earliest).Error
+			switch {
+			case err == nil:
+				scopeStart = &earliest.FirstSeen
+			case err != gorm.ErrRecordNotFound:
+				return err
+			}
+		}
+
+		var updated models.SeenValue
+		matched, updated = observeSeenValue(cond, record, scopeStart, at)
+		seen = updated.Count
+		if record != nil {
+			return tx.Save(&updated).Error
+		}
+		updated.Scope, updated.Entity, updated.Value = cond.seenScope, entity, value
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&updated)
+		if result.Error == nil && result.RowsAffected == 0 {
+			// Recorded concurrently by another event: not new after all
+			matched, seen = false, -1
+			return tx.Model(&models.SeenValue{}).
+				Where("scope = ? AND entity = ? AND value = ?", cond.seenScope, entity, value).
+				Update("count", gorm.Expr("count + 1")).Error
+		}
+		return result.Error
+	})
+	if err != nil {
+		log.Printf("Error evaluating %s condition: %v", cond.Operator, err)
+		return false, -1
+	}
+	return matched, seen
+}
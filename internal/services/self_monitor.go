@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// SelfMonitorSource is the event source reserved for the service's own
+// health events; external events may not use it
+const SelfMonitorSource = "incident-response"
+
+// Self-monitoring event types
+const (
+	SelfEventActionFailures  = "self_action_failures"
+	SelfEventQueueBacklog    = "self_queue_backlog"
+	SelfEventDatabaseErrors  = "self_database_errors"
+	SelfEventPlaybookFailure = "self_playbook_failure"
+)
+
+// selfMonitorWindow is the period action failure rates are measured over
+const selfMonitorWindow = 5 * time.Minute
+
+// SelfMonitorConfig holds the thresholds that turn internal failures into
+// events
+type SelfMonitorConfig struct {
+	// ActionFailureRate is the failed share of actions finished in the
+	// window that raises an event, once at least ActionFailureMin failed
+	ActionFailureRate float64
+	ActionFailureMin  int
+	// QueueBacklog and QueueMaxAge raise an event when that many outbox
+	// messages are pending or the oldest has waited that long
+	QueueBacklog int64
+	QueueMaxAge  time.Duration
+	// Cooldown is the minimum time between events for the same condition
+	Cooldown time.Duration
+}
+
+// SelfMonitor emits the service's own failures as events into its ingest
+// pipeline under SelfMonitorSource, so rules and playbooks can alert on the
+// responder's health. Shared conditions (action failures, queue backlog,
+// playbook failures) are checked by the leader; database errors are counted
+// and reported by every instance.
+type SelfMonitor struct {
+	db       *gorm.DB
+	ingestor *Ingestor
+	outbox   *Outbox
+	cfg      SelfMonitorConfig
+
+	mu                sync.Mutex
+	lastEmitted       map[string]time.Time
+	lastPlaybookCheck time.Time
+
+	dbErrors    atomic.Int64
+	lastDBError atomic.Value // string
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewSelfMonitor creates a self monitor
+func NewSelfMonitor(db *gorm.DB, ingestor *Ingestor, outbox *Outbox, cfg SelfMonitorConfig) *SelfMonitor {
+	return &SelfMonitor{
+		db:                db,
+		ingestor:          ingestor,
+		outbox:            outbox,
+		cfg:               cfg,
+		lastEmitted:       make(map[string]time.Time),
+		lastPlaybookCheck: time.Now().UTC(),
+		stop:              make(chan struct{}),
+	}
+}
+
+// RecordDatabaseError counts a failed database operation. It only updates
+// counters, so it is safe to call from the database layer itself.
+func (m *SelfMonitor) RecordDatabaseError(err error) {
+	m.dbErrors.Add(1)
+	m.lastDBError.Store(err.Error())
+}
+
+// Start reports this instance's database errors every interval
+func (m *SelfMonitor) Start(interval time.Duration) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				m.CheckDatabase()
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the database error reporter
+func (m *SelfMonitor) Stop() {
+	close(m.stop)
+	m.wg.Wait()
+}
+
+// CheckDatabase emits an event when database operations failed since the
+// last check
+func (m *SelfMonitor) CheckDatabase() {
+	count := m.dbErrors.Swap(0)
+	if count == 0 {
+		return
+	}
+	lastError, _ := m.lastDBError.Load().(string)
+	m.emit(SelfEventDatabaseErrors, models.SeverityHigh, SelfEventDatabaseErrors, map[string]interface{}{
+		"error_count": count,
+		"last_error":  lastError,
+	})
+}
+
+// Check evaluates the shared conditions; intended to run as a leader-only
+// scheduled job
+func (m *SelfMonitor) Check() error {
+	if err := m.checkActionFailures(); err != nil {
+		return err
+	}
+	if err := m.checkQueue(); err != nil {
+		return err
+	}
+	return m.checkPlaybookRuns()
+}
+
+func (m *SelfMonitor) checkActionFailures() error {
+	since := time.Now().UTC().Add(-selfMonitorWindow)
+	var counts []struct {
+		Status models.ActionStatus
+		Count  int
+	}
+	if err := m.db.Model(&models.ActionLog{}).
+		Select("status, COUNT(*) AS count").
+		Where("completed_at >= ? AND status IN ?", since, []models.ActionStatus{models.ActionCompleted, models.ActionFailed}).
+		Group("status").Scan(&counts).Error; err != nil {
+		return fmt.Errorf("failed to count action results: %w", err)
+	}
+
+	var failed, total int
+	for _, c := range counts {
+		total += c.Count
+		if c.Status == models.ActionFailed {
+			failed = c.Count
+		}
+	}
+	if failed < m.cfg.ActionFailureMin || total == 0 {
+		return nil
+	}
+	rate := float64(failed) / float64(total)
+	if rate < m.cfg.ActionFailureRate {
+		return nil
+	}
+
+	m.emit(SelfEventActionFailures, models.SeverityHigh, SelfEventActionFailures, map[string]interface{}{
+		"failed":         failed,
+		"total":          total,
+		"failure_rate":   rate,
+		"window_seconds": int(selfMonitorWindow.Seconds()),
+	})
+	return nil
+}
+
+func (m *SelfMonitor) checkQueue() error {
+	pending, oldest, err := m.outbox.Backlog(context.Background())
+	if err != nil {
+		return err
+	}
+	if oldest == nil {
+		return nil
+	}
+	age := time.Since(*oldest)
+	if pending < m.cfg.QueueBacklog && age < m.cfg.QueueMaxAge {
+		return nil
+	}
+
+	m.emit(SelfEventQueueBacklog, models.SeverityHigh, SelfEventQueueBacklog, map[string]interface{}{
+		"pending":            pending,
+		"oldest_age_seconds": int(age.Seconds()),
+	})
+	return nil
+}
+
+func (m *SelfMonitor) checkPlaybookRuns() error {
+	m.mu.Lock()
+	since := m.lastPlaybookCheck
+	m.mu.Unlock()
+	now := time.Now().UTC()
+
+	var runs []models.PlaybookRun
+	if err := m.db.Where("status = ? AND completed_at > ? AND completed_at <= ?", models.RunFailed, since, now).
+		Order("completed_at ASC").Find(&runs).Error; err != nil {
+		return fmt.Errorf("failed to fetch failed playbook runs: %w", err)
+	}
+
+	m.mu.Lock()
+	m.lastPlaybookCheck = now
+	m.mu.Unlock()
+
+	// One event per playbook, carrying its latest failure
+	latest := make(map[string]models.PlaybookRun)
+	failures := make(map[string]int)
+	for _, run := range runs {
+		latest[run.PlaybookID] = run
+		failures[run.PlaybookID]++
+	}
+	for playbookID, run := range latest {
+		data := map[string]interface{}{
+			"playbook_id": playbookID,
+			"run_id":      run.RunID,
+			"failures":    failures[playbookID],
+		}
+		if run.Error != nil {
+			data["error"] = *run.Error
+		}
+		if run.IncidentID != nil {
+			data["incident_id"] = *run.IncidentID
+		}
+		m.emit(SelfEventPlaybookFailure, models.SeverityMedium, SelfEventPlaybookFailure+":"+playbookID, data)
+	}
+	return nil
+}
+
+// emit ingests a self-monitoring event unless the same condition was
+// reported within the cooldown, which also stops a failing self-monitoring
+// playbook from feeding on its own failures
+func (m *SelfMonitor) emit(eventType string, severity models.SeverityLevel, key string, data map[string]interface{}) {
+	now := time.Now().UTC()
+	m.mu.Lock()
+	if last, ok := m.lastEmitted[key]; ok && now.Sub(last) < m.cfg.Cooldown {
+		m.mu.Unlock()
+		return
+	}
+	m.lastEmitted[key] = now
+	m.mu.Unlock()
+
+	emitSelfEvent(m.ingestor, eventType, severity, data)
+}
+
+// emitSelfEvent ingests an event about the service itself under
+// SelfMonitorSource
+func emitSelfEvent(ingestor *Ingestor, eventType string, severity models.SeverityLevel, data map[string]interface{}) {
+	now := time.Now().UTC()
+	normalized := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		normalized[k] = v
+	}
+	normalized["check"] = eventType
+	normalizedJSON, err := json.Marshal(normalized)
+	if err != nil {
+		log.Printf("Self-monitoring: failed to encode %s event: %v", eventType, err)
+		return
+	}
+
+	event := &models.Event{
+		Timestamp:  now,
+		Source:     SelfMonitorSource,
+		EventType:  eventType,
+		Severity:   severity,
+		Normalized: string(normalizedJSON),
+	}
+	if err := ingestor.Ingest(event); err != nil {
+		log.Printf("Self-monitoring: failed to ingest %s event: %v", eventType, err)
+		return
+	}
+	log.Printf("Self-monitoring: emitted %s event %s", eventType, event.EventID)
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// ErrServiceNotFound is returned for an unknown service name
+var ErrServiceNotFound = errors.New("service not found")
+
+// ErrServiceExists is returned when creating a service whose name is taken
+var ErrServiceExists = errors.New("service already exists")
+
+// ErrServiceInUse is returned when deleting a service others depend on
+var ErrServiceInUse = errors.New("other services depend on this service")
+
+// ErrInvalidService is returned for a service with an invalid tier or
+// dependencies
+var ErrInvalidService = errors.New("invalid service")
+
+// ServiceTierDefaults are what impacting a service of a tier implies for an
+// incident: a minimum severity and SLA targets from its creation
+type ServiceTierDefaults struct {
+	MinSeverity models.SeverityLevel
+	Acknowledge time.Duration
+	Resolve     time.Duration
+}
+
+// ServiceTiers maps each tier, 1 being the most critical, to its defaults
+var ServiceTiers = map[int]ServiceTierDefaults{
+	1: {models.SeverityHigh, 15 * time.Minute, 4 * time.Hour},
+	2: {models.SeverityMedium, time.Hour, 24 * time.Hour},
+	3: {models.SeverityLow, 4 * time.Hour, 3 * 24 * time.Hour},
+	4: {models.SeverityInfo, 24 * time.Hour, 7 * 24 * time.Hour},
+}
+
+// EventServices returns the service names an event's normalized fields
+// refer to: a "service" string and/or a "services" list
+func EventServices(normalized map[string]interface{}) []string {
+	var names []string
+	if name, ok := normalized["service"].(string); ok && name != "" {
+		names = append(names, name)
+	}
+	if list, ok := normalized["services"].([]interface{}); ok {
+		for _, item := range list {
+			if name, ok := item.(string); ok && name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// ServiceCatalog stores services and applies their impact to incidents
+type ServiceCatalog struct {
+	db        *gorm.DB
+	calendars *SLACalendars
+}
+
+// NewServiceCatalog creates a service catalog
+func NewServiceCatalog(db *gorm.DB) *ServiceCatalog {
+	return &ServiceCatalog{db: db}
+}
+
+// SetSLACalendars counts resolve-by deadlines in the business time of the
+// incident's team calendar
+func (c *ServiceCatalog) SetSLACalendars(calendars *SLACalendars) {
+	c.calendars = calendars
+}
+
+// List returns every service, by name
+func (c *ServiceCatalog) List() ([]models.Service, error) {
+	services := []models.Service{}
+	if err := c.db.Order("name").Find(&services).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch services: %w", err)
+	}
+	return services, nil
+}
+
+// Get returns a service by name
+func (c *ServiceCatalog) Get(name string) (*models.Service, error) {
+	var service models.Service
+	if err := c.db.First(&service, "name = ?", name).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrServiceNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch service: %w", err)
+	}
+	return &service, nil
+}
+
+// Create adds a service to the catalog
+func (c *ServiceCatalog) Create(service *models.Service) error {
+	if service.Tier == 0 {
+		service.Tier = 3
+	}
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Service{}).Where("name = ?", service.Name).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check service: %w", err)
+		}
+		if count > 0 {
+			return ErrServiceExists
+		}
+		if err := c.validate(tx, service); err != nil {
+			return err
+		}
+		if err := tx.Create(service).Error; err != nil {
+			return fmt.Errorf("failed to create service: %w", err)
+		}
+		return nil
+	})
+}
+
+// Update saves changes to a service loaded with Get
+func (c *ServiceCatalog) Update(service *models.Service) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		if err := c.validate(tx, service); err != nil {
+			return err
+		}
+		if err := tx.Save(service).Error; err != nil {
+			return fmt.Errorf("failed to update service: %w", err)
+		}
+		return nil
+	})
+}
+
+// Delete removes a service no other service depends on
+func (c *ServiceCatalog) Delete(name string) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		var dependents int64
+		if err := tx.Model(&models.Service{}).
+			Where("EXISTS (SELECT 1 FROM json_each(services.dependencies) WHERE json_each.value = ?)", name).
+			Count(&dependents).Error; err != nil {
+			return fmt.Errorf("failed to check dependents: %w", err)
+		}
+		if dependents > 0 {
+			return ErrServiceInUse
+		}
+		result := tx.Where("name = ?", name).Delete(&models.Service{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete service: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrServiceNotFound
+		}
+		return nil
+	})
+}
+
+// validate checks a service's tier and that its dependencies are other
+// catalog services
+func (c *ServiceCatalog) validate(tx *gorm.DB, service *models.Service) error {
+	if _, ok := ServiceTiers[service.Tier]; !ok {
+		return fmt.Errorf("%w: tier must be between 1 and 4", ErrInvalidService)
+	}
+	if service.Dependencies == nil {
+		service.Dependencies = []string{}
+	}
+	for _, dependency := range service.Dependencies {
+		if dependency == service.Name {
+			return fmt.Errorf("%w: a service cannot depend on itself", ErrInvalidService)
+		}
+	}
+	if len(service.Dependencies) == 0 {
+		return nil
+	}
+	var known []string
+	if err := tx.Model(&models.Service{}).Where("name IN ?", service.Dependencies).
+		Pluck("name", &known).Error; err != nil {
+		return fmt.Errorf("failed to check dependencies: %w", err)
+	}
+	if len(known) < len(service.Dependencies) {
+		knownSet := make(map[string]bool, len(known))
+		for _, name := range known {
+			knownSet[name] = true
+		}
+		var unknown []string
+		for _, dependency := range service.Dependencies {
+			if !knownSet[dependency] {
+				unknown = append(unknown, dependency)
+			}
+		}
+		if len(unknown) > 0 {
+			return fmt.Errorf("%w: unknown dependencies %s", ErrInvalidService, strings.Join(unknown, ", "))
+		}
+	}
+	return nil
+}
+
+// ApplyImpact records services as impacted by an incident and applies the
+// defaults of the most critical one known to the catalog: its owner team
+// when the incident has none, its tier's minimum severity, and SLA deadlines
+// counted from the incident's creation, the resolve-by one in business time
+// (see SLACalendars). Severity is only raised and
+// deadlines only brought forward.
+func (c *ServiceCatalog) ApplyImpact(tx *gorm.DB, incident *models.Incident, names []string) error {
+	if c == nil || len(names) == 0 {
+		return nil
+	}
+
+	impacted := make(map[string]bool)
+	for _, name := range decodeStringList(incident.Services) {
+		impacted[name] = true
+	}
+	for _, name := range names {
+		impacted[name] = true
+	}
+	merged := make([]string, 0, len(impacted))
+	for name := range impacted {
+		merged = append(merged, name)
+	}
+	sort.Strings(merged)
+	encoded, _ := json.Marshal(merged)
+	updates := map[string]interface{}{"services": string(encoded)}
+	incident.Services = string(encoded)
+
+	var services []models.Service
+	if err := tx.Where("name IN ?", merged).Order("tier, name").Find(&services).Error; err != nil {
+		return fmt.Errorf("failed to fetch services: %w", err)
+	}
+	if len(services) > 0 {
+		critical := services[0]
+		defaults := ServiceTiers[critical.Tier]
+		if incident.Team == "" {
+			for _, service := range services {
+				if service.OwnerTeam != "" {
+					incident.Team = service.OwnerTeam
+					updates["team"] = incident.Team
+					break
+				}
+			}
+		}
+		if defaults.MinSeverity.Rank() > incident.Severity.Rank() {
+			incident.Severity = defaults.MinSeverity
+			updates["severity"] = incident.Severity
+		}
+		created := incident.CreatedAt
+		if created.IsZero() {
+			created = time.Now().UTC()
+		}
+		if due := created.Add(defaults.Acknowledge); incident.AcknowledgeBy == nil || due.Before(*incident.AcknowledgeBy) {
+			incident.AcknowledgeBy = &due
+			updates["acknowledge_by"] = due
+		}
+		if due := c.calendars.ResolveBy(incident, created, defaults.Resolve); incident.ResolveBy == nil || due.Before(*incident.ResolveBy) {
+			incident.ResolveBy = &due
+			updates["resolve_by"] = due
+		}
+	}
+
+	if err := tx.Model(incident).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to record impacted services: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// sessionTokenPrefix marks session tokens, so they're told apart from API
+// keys in logs and bearer headers
+const sessionTokenPrefix = "irs_"
+
+// sessionTouchInterval is how stale a session's last_seen_at may get before
+// a request updates it
+const sessionTouchInterval = time.Minute
+
+// ErrSessionNotFound is returned for an unknown, expired or revoked session
+var ErrSessionNotFound = errors.New("session not found")
+
+// Sessions issues and resolves login sessions. A session lasts lifetime from
+// sign-in, and ends early after idle without requests when idle is set.
+type Sessions struct {
+	db       *gorm.DB
+	lifetime time.Duration
+	idle     time.Duration
+}
+
+// NewSessions creates the session store
+func NewSessions(db *gorm.DB, lifetime, idle time.Duration) *Sessions {
+	return &Sessions{db: db, lifetime: lifetime, idle: idle}
+}
+
+// IsSessionToken reports whether a credential is a session token rather
+// than an API key
+func IsSessionToken(token string) bool {
+	return strings.HasPrefix(token, sessionTokenPrefix)
+}
+
+// Create signs principal in, returning the session and its token. The token
+// is only ever returned here.
+func (s *Sessions) Create(ctx context.Context, principal Principal, provider string) (string, *models.Session, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("failed to generate session token: %w", err)
+	}
+	token := sessionTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	now := time.Now().UTC()
+	session := &models.Session{
+		TokenHash:  hashCredential(token),
+		User:       principal.Name,
+		Role:       string(principal.Role),
+		Provider:   provider,
+		ExpiresAt:  now.Add(s.lifetime),
+		LastSeenAt: now,
+	}
+	if err := s.db.WithContext(ctx).Create(session).Error; err != nil {
+		return "", nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return token, session, nil
+}
+
+// Lookup resolves a session token to its live session
+func (s *Sessions) Lookup(token string) (*models.Session, error) {
+	var session models.Session
+	if err := s.db.First(&session, "token_hash = ?", hashCredential(token)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch session: %w", err)
+	}
+	now := time.Now().UTC()
+	if !s.live(&session, now) {
+		return nil, ErrSessionNotFound
+	}
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval {
+		if err := s.db.Model(&session).UpdateColumn("last_seen_at", now).Error; err != nil {
+			return nil, fmt.Errorf("failed to update session: %w", err)
+		}
+	}
+	return &session, nil
+}
+
+// SessionPrincipal returns the principal a session signs in
+func SessionPrincipal(session *models.Session) Principal {
+	return Principal{Name: session.User, Role: Role(session.Role)}
+}
+
+// List returns the live sessions of user, or of everyone when user is
+// empty, most recently used first
+func (s *Sessions) List(user string) ([]models.Session, error) {
+	query := s.db.Order("last_seen_at DESC")
+	if user != "" {
+		query = query.Where("user = ?", user)
+	}
+	var sessions []models.Session
+	if err := query.Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	now := time.Now().UTC()
+	live := sessions[:0]
+	for _, session := range sessions {
+		if s.live(&session, now) {
+			live = append(live, session)
+		}
+	}
+	return live, nil
+}
+
+// RevokeID ends a session by ID. With user set, only that user's session is
+// ended.
+func (s *Sessions) RevokeID(ctx context.Context, sessionID, user string) (*models.Session, error) {
+	var session models.Session
+	query := s.db.WithContext(ctx).Where("session_id = ?", sessionID)
+	if user != "" {
+		query = query.Where("user = ?", user)
+	}
+	if err := query.First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch session: %w", err)
+	}
+	if err := s.db.WithContext(ctx).Delete(&session).Error; err != nil {
+		return nil, fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return &session, nil
+}
+
+// RevokeUser ends every session of user, returning how many there were
+func (s *Sessions) RevokeUser(ctx context.Context, user string) (int64, error) {
+	result := s.db.WithContext(ctx).Where("user = ?", user).Delete(&models.Session{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// Revoke ends the session a token belongs to
+func (s *Sessions) Revoke(ctx context.Context, token string) error {
+	result := s.db.WithContext(ctx).Where("token_hash = ?", hashCredential(token)).Delete(&models.Session{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke session: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// Prune deletes expired sessions
+func (s *Sessions) Prune() error {
+	now := time.Now().UTC()
+	query := s.db.Where("expires_at <= ?", now)
+	if s.idle > 0 {
+		query = query.Or("last_seen_at <= ?", now.Add(-s.idle))
+	}
+	if err := query.Delete(&models.Session{}).Error; err != nil {
+		return fmt.Errorf("failed to prune sessions: %w", err)
+	}
+	return nil
+}
+
+// live reports whether a session is neither expired nor idle
+func (s *Sessions) live(session *models.Session, now time.Time) bool {
+	if !now.Before(session.ExpiresAt) {
+		return false
+	}
+	return s.idle == 0 || now.Sub(session.LastSeenAt) < s.idle
+}
+
+// hashCredential returns the hex SHA-256 a session token or API key is
+// stored under
+func hashCredential(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+var (
+	// ErrNoSeverityMatrix is returned when impact and urgency are given but
+	// no severity matrix is configured
+	ErrNoSeverityMatrix = errors.New("no severity matrix is configured")
+	// ErrInvalidSeverityScore is returned for an impact or urgency outside
+	// the matrix
+	ErrInvalidSeverityScore = errors.New("invalid impact or urgency")
+)
+
+// DefaultSeverityMatrix is the severity matrix used when none is configured:
+// impact 1-3 by urgency 1-3, higher scores being more severe
+const DefaultSeverityMatrix = "low,low,medium;low,medium,high;medium,high,critical"
+
+// SeverityMatrix derives an incident's severity from impact and urgency
+// scores. Row i holds the severities for impact i+1, column j those for
+// urgency j+1.
+type SeverityMatrix struct {
+	rows [][]models.SeverityLevel
+}
+
+// ParseSeverityMatrix parses a matrix such as
+// "low,low,medium;low,medium,high;medium,high,critical": one
+// semicolon-separated row per impact score from 1 up, each listing the
+// severity for urgency 1 up. Every row must have the same length. An empty
+// spec returns nil, leaving severity as given.
+func ParseSeverityMatrix(spec string) (*SeverityMatrix, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	matrix := &SeverityMatrix{}
+	for i, row := range strings.Split(spec, ";") {
+		var levels []models.SeverityLevel
+		for _, cell := range strings.Split(row, ",") {
+			level := models.SeverityLevel(strings.ToLower(strings.TrimSpace(cell)))
+			if level.Rank() < 0 {
+				return nil, fmt.Errorf("invalid severity matrix: impact %d has unknown severity %q", i+1, strings.TrimSpace(cell))
+			}
+			levels = append(levels, level)
+		}
+		if len(matrix.rows) > 0 && len(levels) != len(matrix.rows[0]) {
+			return nil, fmt.Errorf("invalid severity matrix: impact %d has %d urgencies, expected %d", i+1, len(levels), len(matrix.rows[0]))
+		}
+		matrix.rows = append(matrix.rows, levels)
+	}
+	return matrix, nil
+}
+
+// Derive returns the severity for an impact and urgency score
+func (m *SeverityMatrix) Derive(impact, urgency int) (models.SeverityLevel, error) {
+	if m == nil {
+		return "", ErrNoSeverityMatrix
+	}
+	if impact < 1 || impact > len(m.rows) {
+		return "", fmt.Errorf("%w: impact %d, expected 1-%d", ErrInvalidSeverityScore, impact, len(m.rows))
+	}
+	if urgency < 1 || urgency > len(m.rows[0]) {
+		return "", fmt.Errorf("%w: urgency %d, expected 1-%d", ErrInvalidSeverityScore, urgency, len(m.rows[0]))
+	}
+	return m.rows[impact-1][urgency-1], nil
+}
+
+// Rows returns the matrix, one row of severities per impact score
+func (m *SeverityMatrix) Rows() [][]models.SeverityLevel {
+	if m == nil {
+		return [][]models.SeverityLevel{}
+	}
+	return m.rows
+}
+
+// deriveSeverity returns the severity for impact and urgency when both are
+// set, or fallback when neither is
+func deriveSeverity(matrix *SeverityMatrix, impact, urgency int, fallback models.SeverityLevel) (models.SeverityLevel, error) {
+	if impact == 0 && urgency == 0 {
+		return fallback, nil
+	}
+	if impact == 0 || urgency == 0 {
+		return "", fmt.Errorf("%w: impact and urgency must be given together", ErrInvalidSeverityScore)
+	}
+	return matrix.Derive(impact, urgency)
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// maxSimulatedEventIDs caps the event IDs kept per simulated incident
+const maxSimulatedEventIDs = 100
+
+// SimulationReport describes what the rule set would have done for a set of
+// replayed events
+type SimulationReport struct {
+	EventsReplayed int                 `json:"events_replayed"`
+	RuleMatches    int                 `json:"rule_matches"`
+	MatchesByRule  map[string]int      `json:"matches_by_rule"`
+	Incidents      []SimulatedIncident `json:"incidents"`
+	// Actions from rules that don't create an incident
+	Actions []SimulatedAction `json:"actions"`
+}
+
+// SimulatedIncident is an incident the rule set would have opened
+type SimulatedIncident struct {
+	RuleID         string            `json:"rule_id"`
+	Title          string            `json:"title"`
+	Severity       string            `json:"severity"`
+	CorrelationKey string            `json:"correlation_key,omitempty"`
+	FirstSeen      time.Time         `json:"first_seen"`
+	LastSeen       time.Time         `json:"last_seen"`
+	EventCount     int               `json:"event_count"`
+	EventIDs       []string          `json:"event_ids"`
+	Actions        []SimulatedAction `json:"actions"`
+}
+
+// SimulatedAction is a side effect that would have been queued
+type SimulatedAction struct {
+	Type      string    `json:"type"`
+	RuleID    string    `json:"rule_id"`
+	EventID   string    `json:"event_id"`
+	Timestamp time.Time `json:"timestamp"`
+	Playbook  string    `json:"playbook,omitempty"`
+	Channel   string    `json:"channel,omitempty"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// Simulator replays events through the detection engine's current rules in
+// isolation: count conditions are evaluated over the replayed events rather
+// than the live table, incidents are deduplicated in memory, and actions are
+// recorded instead of executed
+type Simulator struct {
+	engine *DetectionEngine
+}
+
+// NewSimulator creates a simulator bound to a detection engine's rule set
+func NewSimulator(engine *DetectionEngine) *Simulator {
+	return &Simulator{engine: engine}
+}
+
+// Replay evaluates events in timestamp order and returns the report
+func (s *Simulator) Replay(events []*models.Event) (*SimulationReport, error) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+
+	report := &SimulationReport{
+		MatchesByRule: make(map[string]int),
+		Incidents:     []SimulatedIncident{},
+		Actions:       []SimulatedAction{},
+	}
+	window := &replayWindow{}
+	open := make(map[string]int) // correlation key -> index in report.Incidents
+
+	for _, event := range events {
+		var normalized map[string]interface{}
+		if err := json.Unmarshal([]byte(event.Normalized), &normalized); err != nil {
+			return nil, fmt.Errorf("event %s: failed to parse normalized data: %w", event.EventID, err)
+		}
+
+		// The event counts towards its own window, as it would once stored
+		window.add(event, normalized)
+		report.EventsReplayed++
+
+		for _, rule := range s.engine.matchingRules(event, normalized, window.count, nil, nil) {
+			report.RuleMatches++
+			report.MatchesByRule[rule.Rule.ID]++
+			s.apply(report, open, event, normalized, rule)
+		}
+	}
+	return report, nil
+}
+
+// apply records what executeRuleActions would have done for a match
+func (s *Simulator) apply(report *SimulationReport, open map[string]int, event *models.Event, normalized map[string]interface{}, rule Rule) {
+	createsIncident := false
+	for _, action := range rule.Rule.Actions {
+		if action.Type == "create_incident" {
+			createsIncident = true
+		}
+	}
+
+	var incident *SimulatedIncident
+	if createsIncident {
+		key := s.engine.correlationKey(normalized, rule)
+		if i, ok := open[key]; ok && key != "" {
+			// Attached to an open incident: no new side effects
+			existing := &report.Incidents[i]
+			existing.EventCount++
+			existing.LastSeen = event.Timestamp
+			if len(existing.EventIDs) < maxSimulatedEventIDs {
+				existing.EventIDs = append(existing.EventIDs, event.EventID)
+			}
+			return
+		}
+
+		report.Incidents = append(report.Incidents, SimulatedIncident{
+			RuleID:         rule.Rule.ID,
+			Title:          rule.Rule.Name,
+			Severity:       rule.Rule.Severity,
+			CorrelationKey: key,
+			FirstSeen:      event.Timestamp,
+			LastSeen:       event.Timestamp,
+			EventCount:     1,
+			EventIDs:       []string{event.EventID},
+			Actions:        []SimulatedAction{},
+		})
+		incident = &report.Incidents[len(report.Incidents)-1]
+		if key != "" {
+			open[key] = len(report.Incidents) - 1
+		}
+	}
+
+	for _, action := range rule.Rule.Actions {
+		simulated := SimulatedAction{
+			Type:      action.Type,
+			RuleID:    rule.Rule.ID,
+			EventID:   event.EventID,
+			Timestamp: event.Timestamp,
+		}
+		switch action.Type {
+		case "create_incident":
+			continue
+		case "execute_playbook":
+			simulated.Playbook = action.Playbook
+		case "notify":
+			params := s.engine.notificationParams(event, rule, action, nil)
+			simulated.Channel, _ = params["channel"].(string)
+			simulated.Message, _ = params["message"].(string)
+		}
+
+		if incident != nil {
+			incident.Actions = append(incident.Actions, simulated)
+		} else {
+			report.Actions = append(report.Actions, simulated)
+		}
+	}
+}
+
+// ActionCount returns the number of actions the report would have queued
+func (r *SimulationReport) ActionCount() int {
+	n := len(r.Actions)
+	for _, incident := range r.Incidents {
+		n += len(incident.Actions)
+	}
+	return n
+}
+
+// replayWindow holds replayed events for count conditions, and the values
+// seen-value conditions have recorded during the replay
+type replayWindow struct {
+	events []replayedEvent
+	seen   map[string]*models.SeenValue
+	// seenSince is when each seen-value scope first recorded a value
+	seenSince map[string]time.Time
+}
+
+type replayedEvent struct {
+	event      *models.Event
+	normalized map[string]interface{}
+}
+
+func (w *replayWindow) add(event *models.Event, normalized map[string]interface{}) {
+	w.events = append(w.events, replayedEvent{event: event, normalized: normalized})
+}
+
+// count evaluates a count condition over the replayed events it counts
+// within the time window ending at the event
+func (w *replayWindow) count(event *models.Event, normalized map[string]interface{}, cond Condition) (bool, int64) {
+	if isSeenOperator(cond.Operator) {
+		return w.observe(event, normalized, cond)
+	}
+	if !groupable(event, normalized, cond) {
+		return false, -1
+	}
+	windowStart := event.Timestamp.Add(-time.Duration(cond.TimeWindow) * time.Second)
+
+	matched := 0
+	distinct := make(map[string]struct{})
+	for i := len(w.events) - 1; i >= 0; i-- {
+		e := w.events[i]
+		if e.event.Timestamp.Before(windowStart) {
+			// Events are replayed in order, so everything earlier is outside too
+			break
+		}
+		if e.event.Timestamp.After(event.Timestamp) || !countedBy(event, normalized, e.event, e.normalized, cond) {
+			continue
+		}
+
+		if cond.Operator == "count_distinct" {
+			if value := eventField(e.event, e.normalized, cond.distinctField()); value != nil {
+				distinct[fmt.Sprintf("%v", value)] = struct{}{}
+			}
+		} else {
+			matched++
+		}
+	}
+
+	if cond.Operator == "count_distinct" {
+		return len(distinct) >= cond.Threshold, int64(len(distinct))
+	}
+	return matched >= cond.Threshold, int64(matched)
+}
+
+// observe evaluates a seen-value condition against the values recorded
+// during the replay, which starts with none
+func (w *replayWindow) observe(event *models.Event, normalized map[string]interface{}, cond Condition) (bool, int64) {
+	entity, value, ok := seenKey(event, normalized, cond)
+	if !ok {
+		return false, -1
+	}
+	if w.seen == nil {
+		w.seen = make(map[string]*models.SeenValue)
+		w.seenSince = make(map[string]time.Time)
+	}
+
+	key := cond.seenScope + "\x00" + entity + "\x00" + value
+	var scopeStart *time.Time
+	if since, ok := w.seenSince[cond.seenScope]; ok {
+		scopeStart = &since
+	}
+	matched, updated := observeSeenValue(cond, w.seen[key], scopeStart, event.Timestamp)
+	w.seen[key] = &updated
+	if scopeStart == nil {
+		w.seenSince[cond.seenScope] = event.Timestamp
+	}
+	return matched, updated.Count
+}
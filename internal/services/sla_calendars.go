@@ -0,0 +1,288 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// maxCalendarDays bounds how far business time is counted, so a calendar
+// whose holidays leave no working hours can't loop forever
+const maxCalendarDays = 3 * 366
+
+// SLACalendarSpec is a business calendar: weekly working hours in a
+// timezone, as in BUSINESS_HOURS, and holiday dates (YYYY-MM-DD)
+type SLACalendarSpec struct {
+	Hours    string   `yaml:"hours" json:"hours"`
+	Timezone string   `yaml:"timezone" json:"timezone"`
+	Holidays []string `yaml:"holidays" json:"holidays"`
+}
+
+// SLACalendarsFile is the YAML layout of the SLA calendars file
+type SLACalendarsFile struct {
+	Default *SLACalendarSpec           `yaml:"default" json:"default"`
+	Teams   map[string]SLACalendarSpec `yaml:"teams" json:"teams"`
+}
+
+// businessCalendar is a compiled SLACalendarSpec
+type businessCalendar struct {
+	name     string
+	hours    *BusinessHours
+	holidays map[string]bool
+}
+
+// SLACalendars are the business calendars SLA clocks run on. An incident
+// uses its team's calendar, or the default one; resolve-by clocks of
+// non-critical incidents only run during its working hours outside
+// holidays. Critical incidents, and incidents without a calendar, run
+// around the clock.
+type SLACalendars struct {
+	file  SLACalendarsFile
+	def   *businessCalendar
+	teams map[string]*businessCalendar
+}
+
+// LoadSLACalendars reads an SLA calendars file. Without the file every SLA
+// clock runs around the clock.
+func LoadSLACalendars(path string) (*SLACalendars, error) {
+	s := &SLACalendars{teams: make(map[string]*businessCalendar)}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read SLA calendars: %w", err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, &s.file); err != nil {
+			return nil, fmt.Errorf("failed to parse SLA calendars: %w", err)
+		}
+	}
+	if s.file.Default != nil {
+		if s.def, err = compileCalendar("default", *s.file.Default); err != nil {
+			return nil, err
+		}
+	}
+	for team, spec := range s.file.Teams {
+		calendar, err := compileCalendar(team, spec)
+		if err != nil {
+			return nil, err
+		}
+		s.teams[team] = calendar
+	}
+	if s.file.Teams == nil {
+		s.file.Teams = map[string]SLACalendarSpec{}
+	}
+	return s, nil
+}
+
+func compileCalendar(name string, spec SLACalendarSpec) (*businessCalendar, error) {
+	hours, err := ParseBusinessHours(spec.Hours, spec.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SLA calendar %q: %w", name, err)
+	}
+	calendar := &businessCalendar{name: name, hours: hours, holidays: make(map[string]bool)}
+	for _, date := range spec.Holidays {
+		day, err := time.Parse("2006-01-02", strings.TrimSpace(date))
+		if err != nil {
+			return nil, fmt.Errorf("invalid SLA calendar %q: holiday %q is not a YYYY-MM-DD date", name, date)
+		}
+		calendar.holidays[day.Format("2006-01-02")] = true
+	}
+	return calendar, nil
+}
+
+// File returns the calendars as loaded
+func (s *SLACalendars) File() SLACalendarsFile {
+	return s.file
+}
+
+// calendarFor returns the calendar an incident's resolve-by clock runs on,
+// or nil when it runs around the clock
+func (s *SLACalendars) calendarFor(incident *models.Incident) *businessCalendar {
+	if s == nil || incident.Severity == models.SeverityCritical {
+		return nil
+	}
+	if calendar, ok := s.teams[incident.Team]; ok {
+		return calendar
+	}
+	return s.def
+}
+
+// ResolveBy returns the deadline target after an incident's creation,
+// counting only its calendar's working time
+func (s *SLACalendars) ResolveBy(incident *models.Incident, created time.Time, target time.Duration) time.Time {
+	calendar := s.calendarFor(incident)
+	if calendar == nil {
+		return created.Add(target)
+	}
+	return calendar.add(created, target)
+}
+
+// SLAStatus is how far an incident's resolve-by clock has run
+type SLAStatus struct {
+	IncidentID     string     `json:"incident_id"`
+	Severity       string     `json:"severity"`
+	Team           string     `json:"team"`
+	Calendar       string     `json:"calendar"` // empty when the clock runs around the clock
+	AcknowledgeBy  *time.Time `json:"acknowledge_by"`
+	ResolveBy      *time.Time `json:"resolve_by"`
+	Running        bool       `json:"running"` // the clock is counting now
+	ElapsedSeconds int        `json:"elapsed_seconds"`
+	PausedSeconds  int        `json:"paused_seconds"`
+	// RemainingSeconds is the clock time left until resolve_by, negative
+	// once past it; nil without a deadline
+	RemainingSeconds *int `json:"remaining_seconds"`
+	Breached         bool `json:"breached"`
+}
+
+// Status reports an incident's SLA clock: the time counted and paused since
+// its creation, up to its resolution or now
+func (s *SLACalendars) Status(incident *models.Incident, now time.Time) SLAStatus {
+	status := SLAStatus{
+		IncidentID:    incident.IncidentID,
+		Severity:      string(incident.Severity),
+		Team:          incident.Team,
+		AcknowledgeBy: incident.AcknowledgeBy,
+		ResolveBy:     incident.ResolveBy,
+	}
+	end := now
+	resolved := incident.Status == models.StatusResolved && incident.ResolvedAt != nil
+	if resolved {
+		end = *incident.ResolvedAt
+	}
+	if end.Before(incident.CreatedAt) {
+		end = incident.CreatedAt
+	}
+	wall := end.Sub(incident.CreatedAt)
+
+	calendar := s.calendarFor(incident)
+	elapsed := wall
+	status.Running = !resolved
+	if calendar != nil {
+		status.Calendar = calendar.name
+		elapsed = calendar.between(incident.CreatedAt, end)
+		status.Running = !resolved && calendar.working(now)
+	}
+	status.ElapsedSeconds = int(elapsed.Seconds())
+	status.PausedSeconds = int((wall - elapsed).Seconds())
+
+	if incident.ResolveBy != nil {
+		var remaining time.Duration
+		switch {
+		case !end.Before(*incident.ResolveBy):
+			remaining = -end.Sub(*incident.ResolveBy)
+		case calendar != nil:
+			remaining = calendar.between(end, *incident.ResolveBy)
+		default:
+			remaining = incident.ResolveBy.Sub(end)
+		}
+		seconds := int(remaining.Seconds())
+		status.RemainingSeconds = &seconds
+		status.Breached = end.After(*incident.ResolveBy)
+	}
+	return status
+}
+
+// working reports whether t is in working hours on a working day
+func (c *businessCalendar) working(t time.Time) bool {
+	local := t.In(c.hours.Location())
+	return c.hours.Contains(local, nil) && !c.holidays[local.Format("2006-01-02")]
+}
+
+// add returns the time d of working time after start
+func (c *businessCalendar) add(start time.Time, d time.Duration) time.Time {
+	remaining := d
+	day := c.startOfDay(start)
+	for i := 0; i < maxCalendarDays; i++ {
+		for _, span := range c.spans(day) {
+			from, to := span[0], span[1]
+			if !to.After(start) {
+				continue
+			}
+			if from.Before(start) {
+				from = start
+			}
+			if length := to.Sub(from); length < remaining {
+				remaining -= length
+				continue
+			}
+			return from.Add(remaining).UTC()
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return start.Add(d)
+}
+
+// between returns the working time from from to to
+func (c *businessCalendar) between(from, to time.Time) time.Duration {
+	var total time.Duration
+	day := c.startOfDay(from)
+	for i := 0; i < maxCalendarDays && day.Before(to); i++ {
+		for _, span := range c.spans(day) {
+			start, end := span[0], span[1]
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if end.After(start) {
+				total += end.Sub(start)
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return total
+}
+
+// startOfDay returns local midnight of t's day
+func (c *businessCalendar) startOfDay(t time.Time) time.Time {
+	local := t.In(c.hours.Location())
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+}
+
+// spans returns the merged working periods on day (a local midnight), in
+// order; none on holidays. Windows crossing midnight count on both dates.
+func (c *businessCalendar) spans(day time.Time) [][2]time.Time {
+	if c.holidays[day.Format("2006-01-02")] {
+		return nil
+	}
+	at := func(minute int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), 0, minute, 0, 0, day.Location())
+	}
+	today := day.Weekday()
+	yesterday := (today + 6) % 7
+	var spans [][2]time.Time
+	for _, window := range c.hours.windows {
+		switch {
+		case window.start < window.end:
+			if window.days[today] {
+				spans = append(spans, [2]time.Time{at(window.start), at(window.end)})
+			}
+		default:
+			if window.days[today] {
+				spans = append(spans, [2]time.Time{at(window.start), at(24 * 60)})
+			}
+			if window.days[yesterday] && window.end > 0 {
+				spans = append(spans, [2]time.Time{at(0), at(window.end)})
+			}
+		}
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0].Before(spans[j][0]) })
+	var merged [][2]time.Time
+	for _, span := range spans {
+		if n := len(merged); n > 0 && !span[0].After(merged[n-1][1]) {
+			if span[1].After(merged[n-1][1]) {
+				merged[n-1][1] = span[1]
+			}
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
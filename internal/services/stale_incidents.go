@@ -0,0 +1,265 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// Stale policy actions
+const (
+	StaleActionResolve = "resolve"
+	StaleActionFlag    = "flag"
+)
+
+// StalePolicyActor is recorded as the resolver of incidents the stale policy
+// auto-resolves
+const StalePolicyActor = "stale-policy"
+
+// ParseStaleThresholds parses a per-severity idle period list such as
+// "info=72h,low=168h"; severities not listed are never auto-closed
+func ParseStaleThresholds(spec string) (map[models.SeverityLevel]time.Duration, error) {
+	return parseSeverityDurations(spec, "stale threshold")
+}
+
+// parseSeverityDurations parses a "severity=duration,..." list; kind names
+// the entries in errors
+func parseSeverityDurations(spec, kind string) (map[models.SeverityLevel]time.Duration, error) {
+	thresholds := make(map[models.SeverityLevel]time.Duration)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		severity, period, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid %s %q: expected severity=duration", kind, entry)
+		}
+		level := models.SeverityLevel(strings.ToLower(strings.TrimSpace(severity)))
+		if level.Rank() < 0 {
+			return nil, fmt.Errorf("invalid %s %q: unknown severity", kind, entry)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(period))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid %s %q: expected a positive duration", kind, entry)
+		}
+		thresholds[level] = d
+	}
+	return thresholds, nil
+}
+
+// StalePolicy auto-resolves or flags incidents that have seen no new events
+// or responder activity for their severity's threshold. A warning
+// notification goes out a grace period before, and each step is recorded in
+// the incident's action log.
+type StalePolicy struct {
+	db         *gorm.DB
+	outbox     *Outbox
+	flags      *FeatureFlags
+	thresholds map[models.SeverityLevel]time.Duration
+	grace      time.Duration
+	action     string
+}
+
+// NewStalePolicy creates a stale incident policy
+func NewStalePolicy(db *gorm.DB, outbox *Outbox, flags *FeatureFlags, thresholds map[models.SeverityLevel]time.Duration, grace time.Duration, action string) (*StalePolicy, error) {
+	if action != StaleActionResolve && action != StaleActionFlag {
+		return nil, fmt.Errorf("invalid stale incident action %q: expected %s or %s", action, StaleActionResolve, StaleActionFlag)
+	}
+	if grace < 0 {
+		return nil, fmt.Errorf("stale incident grace period must not be negative")
+	}
+	return &StalePolicy{
+		db:         db,
+		outbox:     outbox,
+		flags:      flags,
+		thresholds: thresholds,
+		grace:      grace,
+		action:     action,
+	}, nil
+}
+
+// Enabled reports whether any severity has a threshold
+func (p *StalePolicy) Enabled() bool {
+	return len(p.thresholds) > 0
+}
+
+// Run applies the policy to every unresolved incident with a threshold
+func (p *StalePolicy) Run() error {
+	now := time.Now().UTC()
+	for severity, threshold := range p.thresholds {
+		var incidents []models.Incident
+		if err := p.db.Where("severity = ? AND status <> ?", severity, models.StatusResolved).
+			Find(&incidents).Error; err != nil {
+			return fmt.Errorf("failed to fetch incidents: %w", err)
+		}
+
+		for i := range incidents {
+			if err := p.apply(&incidents[i], threshold, now); err != nil {
+				log.Printf("Stale policy failed for incident %s: %v", incidents[i].IncidentID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// apply moves one incident through warn -> resolve/flag, or clears a
+// pending warning or flag when activity has resumed
+func (p *StalePolicy) apply(incident *models.Incident, threshold time.Duration, now time.Time) error {
+	lastActivity, err := p.lastActivity(incident)
+	if err != nil {
+		return err
+	}
+	idle := now.Sub(lastActivity)
+
+	warnAfter := threshold - p.grace
+	if warnAfter < 0 {
+		warnAfter = 0
+	}
+
+	switch {
+	case incident.StaleFlaggedAt != nil:
+		if lastActivity.After(*incident.StaleFlaggedAt) {
+			return p.transition(incident, "stale_cleared", idle, threshold,
+				map[string]interface{}{"stale_warned_at": nil, "stale_flagged_at": nil},
+				"Activity resumed; stale flag cleared", false)
+		}
+
+	case incident.StaleWarnedAt != nil && lastActivity.After(*incident.StaleWarnedAt):
+		return p.transition(incident, "stale_cleared", idle, threshold,
+			map[string]interface{}{"stale_warned_at": nil},
+			"Activity resumed; stale auto-close cancelled", false)
+
+	case incident.StaleWarnedAt == nil && idle >= warnAfter:
+		verb := "auto-resolved"
+		if p.action == StaleActionFlag {
+			verb = "flagged as stale"
+		}
+		message := fmt.Sprintf("Incident '%s' has had no activity for %s and will be %s in %s unless it is updated",
+			incident.Title, formatIdle(idle), verb, formatIdle(maxDuration(threshold-idle, p.grace)))
+		return p.transition(incident, "stale_warning", idle, threshold,
+			map[string]interface{}{"stale_warned_at": now}, message, true)
+
+	case incident.StaleWarnedAt != nil && idle >= threshold && now.Sub(*incident.StaleWarnedAt) >= p.grace:
+		if p.action == StaleActionFlag {
+			return p.transition(incident, "stale_flag", idle, threshold,
+				map[string]interface{}{"stale_flagged_at": now},
+				fmt.Sprintf("Incident flagged as stale after %s without activity", formatIdle(idle)), true)
+		}
+		// The warning stays pending, so the incident closes on the first run
+		// after the flag is turned back on
+		if !p.flags.Enabled(FlagAutoClose) {
+			log.Printf("Stale policy: auto-close disabled by feature flag, leaving incident %s open", incident.IncidentID)
+			return nil
+		}
+		return p.transition(incident, "stale_auto_resolve", idle, threshold,
+			map[string]interface{}{"status": models.StatusResolved, "resolved_at": now, "resolved_by": StalePolicyActor},
+			fmt.Sprintf("Incident auto-resolved after %s without activity", formatIdle(idle)), true)
+	}
+	return nil
+}
+
+// lastActivity is the latest of the incident's own update (new events,
+// status changes), comments and task updates
+func (p *StalePolicy) lastActivity(incident *models.Incident) (time.Time, error) {
+	latest := incident.UpdatedAt
+
+	var comment models.IncidentComment
+	err := p.db.Where("incident_id = ?", incident.IncidentID).Order("created_at DESC").Limit(1).Find(&comment).Error
+	if err != nil {
+		return latest, fmt.Errorf("failed to fetch comments: %w", err)
+	}
+	if comment.CreatedAt.After(latest) {
+		latest = comment.CreatedAt
+	}
+
+	var task models.IncidentTask
+	err = p.db.Where("incident_id = ?", incident.IncidentID).Order("updated_at DESC").Limit(1).Find(&task).Error
+	if err != nil {
+		return latest, fmt.Errorf("failed to fetch tasks: %w", err)
+	}
+	if task.UpdatedAt.After(latest) {
+		latest = task.UpdatedAt
+	}
+	return latest, nil
+}
+
+// transition applies a policy step in one transaction: the incident columns
+// and a note are written without touching updated_at (so the policy's own
+// writes don't count as activity), the step is logged, and a notification is
+// queued when notify is set
+func (p *StalePolicy) transition(incident *models.Incident, step string, idle, threshold time.Duration, updates map[string]interface{}, message string, notify bool) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		note := fmt.Sprintf("[%s] %s", time.Now().UTC().Format(time.RFC3339), message)
+		if incident.Notes != "" {
+			note = incident.Notes + "\n" + note
+		}
+		updates["notes"] = note
+		if err := tx.Model(incident).UpdateColumns(updates).Error; err != nil {
+			return fmt.Errorf("failed to update incident: %w", err)
+		}
+
+		params, _ := json.Marshal(map[string]interface{}{
+			"severity":      incident.Severity,
+			"idle_seconds":  int(idle.Seconds()),
+			"threshold":     threshold.String(),
+			"grace":         p.grace.String(),
+			"policy_action": p.action,
+		})
+		completed := time.Now().UTC()
+		entry := &models.ActionLog{
+			ActionType:  step,
+			Status:      models.ActionCompleted,
+			IncidentID:  &incident.IncidentID,
+			Parameters:  string(params),
+			CompletedAt: &completed,
+			Notes:       message,
+		}
+		if err := tx.Create(entry).Error; err != nil {
+			return fmt.Errorf("failed to record stale policy step: %w", err)
+		}
+
+		if notify {
+			if err := p.outbox.Enqueue(tx, TopicNotify, map[string]interface{}{
+				"incident_id": incident.IncidentID,
+				"severity":    string(incident.Severity),
+				"category":    incident.Category,
+				"team":        incident.Team,
+				"tags":        decodeStringList(incident.Tags),
+				"message":     message,
+			}); err != nil {
+				return err
+			}
+		}
+
+		log.Printf("Stale policy %s for incident %s: %s", step, incident.IncidentID, message)
+		return nil
+	})
+}
+
+// decodeStringList decodes a JSON string array column, ignoring bad data
+func decodeStringList(raw string) []string {
+	var list []string
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &list)
+	}
+	return list
+}
+
+// formatIdle renders a duration rounded to the minute
+func formatIdle(d time.Duration) string {
+	return d.Round(time.Minute).String()
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
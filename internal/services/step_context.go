@@ -0,0 +1,111 @@
+package services
+
+import "context"
+
+// StepRun identifies the playbook run and step an action executes for
+type StepRun struct {
+	PlaybookID  string
+	RunID       string
+	StepID      string
+	IncidentID  string
+	Environment string
+	EventID     string
+	Inputs      map[string]interface{}
+	// Scope is what the run's executions count against in quotas
+	Scope ExecutionScope
+	// TriggeredBy is the principal that started a manual run
+	TriggeredBy string
+}
+
+// stepRunKey is the context key holding the step run
+type stepRunKey struct{}
+
+// WithStepRun returns a context carrying the step run
+func WithStepRun(ctx context.Context, step StepRun) context.Context {
+	return context.WithValue(ctx, stepRunKey{}, step)
+}
+
+// StepRunFrom returns the context's step run, if the action executes as part
+// of a playbook
+func StepRunFrom(ctx context.Context) (StepRun, bool) {
+	step, ok := ctx.Value(stepRunKey{}).(StepRun)
+	return step, ok
+}
+
+// playbookEnvironmentKey is the context key holding a run's environment
+type playbookEnvironmentKey struct{}
+
+// WithPlaybookEnvironment returns a context selecting the environment
+// profile for playbook runs
+func WithPlaybookEnvironment(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, playbookEnvironmentKey{}, name)
+}
+
+// PlaybookEnvironmentFrom returns the environment selected in the context,
+// or "" for the default
+func PlaybookEnvironmentFrom(ctx context.Context) string {
+	name, _ := ctx.Value(playbookEnvironmentKey{}).(string)
+	return name
+}
+
+// triggerEventKey is the context key holding the event that triggered a run
+type triggerEventKey struct{}
+
+// WithTriggerEvent returns a context recording the event a playbook run was
+// triggered by
+func WithTriggerEvent(ctx context.Context, eventID string) context.Context {
+	return context.WithValue(ctx, triggerEventKey{}, eventID)
+}
+
+// TriggerEventFrom returns the ID of the event that triggered the run, or ""
+func TriggerEventFrom(ctx context.Context) string {
+	eventID, _ := ctx.Value(triggerEventKey{}).(string)
+	return eventID
+}
+
+// executionScopeKey is the context key holding a run's execution scope
+type executionScopeKey struct{}
+
+// WithExecutionScope returns a context recording what a playbook run counts
+// against in execution quotas
+func WithExecutionScope(ctx context.Context, scope ExecutionScope) context.Context {
+	return context.WithValue(ctx, executionScopeKey{}, scope)
+}
+
+// ExecutionScopeFrom returns the run's execution scope, empty when there is
+// none
+func ExecutionScopeFrom(ctx context.Context) ExecutionScope {
+	scope, _ := ctx.Value(executionScopeKey{}).(ExecutionScope)
+	return scope
+}
+
+// triggeredByKey is the context key holding the principal that started a run
+type triggeredByKey struct{}
+
+// WithTriggeredBy returns a context recording the API key principal that
+// started a playbook run by hand
+func WithTriggeredBy(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, triggeredByKey{}, principal)
+}
+
+// TriggeredByFrom returns the principal that started the run, or "" for runs
+// triggered by rules
+func TriggeredByFrom(ctx context.Context) string {
+	principal, _ := ctx.Value(triggeredByKey{}).(string)
+	return principal
+}
+
+// exerciseKey is the context key marking a run triggered by an exercise
+type exerciseKey struct{}
+
+// WithExercise returns a context marking a playbook run as triggered by a
+// scenario exercise, so its remediation is held back
+func WithExercise(ctx context.Context, exercise bool) context.Context {
+	return context.WithValue(ctx, exerciseKey{}, exercise)
+}
+
+// ExerciseFrom reports whether the run was triggered by an exercise
+func ExerciseFrom(ctx context.Context) bool {
+	exercise, _ := ctx.Value(exerciseKey{}).(bool)
+	return exercise
+}
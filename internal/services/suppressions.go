@@ -0,0 +1,53 @@
+package services
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// FindSuppression returns the suppression covering a rule match for event,
+// or nil. ruleTags are the matched rule's tags.
+func FindSuppression(db *gorm.DB, event *models.Event, ruleTags []string) (*models.Suppression, error) {
+	var windows []models.Suppression
+	if err := db.Where("starts_at <= ? AND ends_at > ?", event.Timestamp, event.Timestamp).
+		Order("starts_at ASC").Find(&windows).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch suppressions: %w", err)
+	}
+
+	for i := range windows {
+		if SuppressionMatches(&windows[i], event.Source, ruleTags) {
+			return &windows[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// SuppressionMatches reports whether a suppression applies to a source and
+// rule tags, ignoring its window. A suppression with neither sources nor
+// tags matches nothing, so a bad import can't silence every rule.
+func SuppressionMatches(s *models.Suppression, source string, ruleTags []string) bool {
+	if len(s.Sources) == 0 && len(s.Tags) == 0 {
+		return false
+	}
+	if len(s.Sources) > 0 && !containsFold(s.Sources, source) {
+		return false
+	}
+	if len(s.Tags) > 0 {
+		for _, tag := range ruleTags {
+			if containsFold(s.Tags, tag) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// RecordSuppressed counts a rule match dropped by a suppression
+func RecordSuppressed(db *gorm.DB, suppressionID string) error {
+	return db.Model(&models.Suppression{}).Where("suppression_id = ?", suppressionID).
+		UpdateColumn("suppressed", gorm.Expr("suppressed + 1")).Error
+}
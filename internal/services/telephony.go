@@ -0,0 +1,221 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TelephonyProvider sends SMS messages and places voice calls
+type TelephonyProvider interface {
+	SendSMS(to, body string) (string, error)
+	// PlaceCall calls to and plays twiml; returns the provider's call ID
+	PlaceCall(to, twiml string) (string, error)
+	Simulated() bool
+}
+
+// TwilioProvider sends through the Twilio REST API
+type TwilioProvider struct {
+	accountSID string
+	authToken  string
+	from       string
+	baseURL    string
+	client     *http.Client
+}
+
+// NewTwilioProvider creates a Twilio provider
+func NewTwilioProvider(accountSID, authToken, from string) *TwilioProvider {
+	return &TwilioProvider{
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+		baseURL:    "https://api.twilio.com/2010-04-01",
+		client:     &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// SendSMS sends a text message
+func (p *TwilioProvider) SendSMS(to, body string) (string, error) {
+	return p.post("Messages.json", url.Values{
+		"To":   {to},
+		"From": {p.from},
+		"Body": {body},
+	})
+}
+
+// PlaceCall starts a voice call that plays twiml
+func (p *TwilioProvider) PlaceCall(to, twiml string) (string, error) {
+	return p.post("Calls.json", url.Values{
+		"To":    {to},
+		"From":  {p.from},
+		"Twiml": {twiml},
+	})
+}
+
+// Simulated reports false: Twilio really sends
+func (p *TwilioProvider) Simulated() bool {
+	return false
+}
+
+// post creates a Twilio resource and returns its SID
+func (p *TwilioProvider) post(resource string, form url.Values) (string, error) {
+	endpoint := fmt.Sprintf("%s/Accounts/%s/%s", p.baseURL, p.accountSID, resource)
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(p.accountSID, p.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("twilio request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		SID     string `json:"sid"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode twilio response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("twilio returned HTTP %d: %s", resp.StatusCode, result.Message)
+	}
+	return result.SID, nil
+}
+
+// ValidateTwilioSignature checks an X-Twilio-Signature header: base64
+// HMAC-SHA1 of the full callback URL followed by the sorted POST parameters
+func ValidateTwilioSignature(authToken, fullURL string, params url.Values, signature string) bool {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(fullURL)
+	for _, k := range keys {
+		for _, v := range params[k] {
+			b.WriteString(k)
+			b.WriteString(v)
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(b.String()))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// LogTelephonyProvider logs messages and calls instead of sending them; used
+// when Twilio is not configured
+type LogTelephonyProvider struct{}
+
+// SendSMS logs the message
+func (LogTelephonyProvider) SendSMS(to, body string) (string, error) {
+	log.Printf("[ACTION] [SMS] (simulated) to %s: %s", to, body)
+	return "simulated-" + uuid.New().String(), nil
+}
+
+// PlaceCall logs the call
+func (LogTelephonyProvider) PlaceCall(to, twiml string) (string, error) {
+	log.Printf("[ACTION] [VOICE] (simulated) calling %s", to)
+	return "simulated-" + uuid.New().String(), nil
+}
+
+// Simulated reports true
+func (LogTelephonyProvider) Simulated() bool {
+	return true
+}
+
+// NewTelephonyProvider returns a Twilio provider, or a logging one when no
+// account SID is configured
+func NewTelephonyProvider(accountSID, authToken, from string) TelephonyProvider {
+	if accountSID == "" {
+		return LogTelephonyProvider{}
+	}
+	return NewTwilioProvider(accountSID, authToken, from)
+}
+
+// SwitchableTelephonyProvider delegates to a provider that can be replaced
+// at runtime, so reloaded credentials take effect without a restart
+type SwitchableTelephonyProvider struct {
+	mu       sync.RWMutex
+	provider TelephonyProvider
+}
+
+// NewSwitchableTelephonyProvider wraps provider
+func NewSwitchableTelephonyProvider(provider TelephonyProvider) *SwitchableTelephonyProvider {
+	return &SwitchableTelephonyProvider{provider: provider}
+}
+
+// Set replaces the underlying provider
+func (s *SwitchableTelephonyProvider) Set(provider TelephonyProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.provider = provider
+}
+
+func (s *SwitchableTelephonyProvider) current() TelephonyProvider {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.provider
+}
+
+// SendSMS sends through the current provider
+func (s *SwitchableTelephonyProvider) SendSMS(to, body string) (string, error) {
+	return s.current().SendSMS(to, body)
+}
+
+// PlaceCall calls through the current provider
+func (s *SwitchableTelephonyProvider) PlaceCall(to, twiml string) (string, error) {
+	return s.current().PlaceCall(to, twiml)
+}
+
+// Simulated reports whether the current provider only logs
+func (s *SwitchableTelephonyProvider) Simulated() bool {
+	return s.current().Simulated()
+}
+
+// voiceCallTwiML builds the TwiML for an escalation call. With an incident
+// and callback URL, the message is read inside a <Gather> so pressing 1
+// acknowledges the incident.
+func voiceCallTwiML(message, incidentID, callbackBaseURL string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?><Response>`)
+
+	say := xmlEscape(message)
+	if incidentID != "" && callbackBaseURL != "" {
+		action := strings.TrimRight(callbackBaseURL, "/") + "/telephony/twilio/gather?incident_id=" + url.QueryEscape(incidentID)
+		fmt.Fprintf(&b, `<Gather numDigits="1" timeout="10" method="POST" action="%s">`, xmlEscape(action))
+		fmt.Fprintf(&b, `<Say>%s</Say><Say>Press 1 to acknowledge this incident.</Say>`, say)
+		b.WriteString(`</Gather><Say>No input received. Goodbye.</Say>`)
+	} else {
+		fmt.Fprintf(&b, `<Say>%s</Say>`, say)
+	}
+
+	b.WriteString(`</Response>`)
+	return b.String()
+}
+
+// xmlEscape escapes text for inclusion in TwiML
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// SMSNotifyAction texts one or more phone numbers
+type SMSNotifyAction struct {
+	provider TelephonyProvider
+}
+
+// NewSMSNotifyAction creates an SMS action using provider
+func NewSMSNotifyAction(provider TelephonyProvider) *SMSNotifyAction {
+	return &SMSNotifyAction{provider: provider}
+}
+
+func (a *SMSNotifyAction) Execute(params map[string]interface{}) (interface{}, error) {
+	recipients := getRecipients(params)
+	message := getStringParam(params, "message", "")
+	if len(recipients) == 0 || message == "" {
+		return nil, fmt.Errorf("to and message parameters are required")
+	}
+
+	return sendToRecipients(recipients, func(to string) (string, error) {
+		return a.provider.SendSMS(to, message)
+	}, a.provider.Simulated())
+}
+
+func (a *SMSNotifyAction) Describe() ActionDescriptor {
+	return ActionDescriptor{
+		Name:        "sms_notify",
+		Description: "Send an SMS through the telephony provider (Twilio)",
+		Parameters: []ActionParameter{
+			{Name: "to", Type: "any", Required: true, Description: "E.164 phone number or list of numbers"},
+			{Name: "message", Type: "string", Required: true, Description: "Message text"},
+		},
+		Outputs: []ActionOutput{
+			{Name: "results", Type: "array", Items: "object", Required: true, Description: "Per-recipient message ID or error"},
+			{Name: "simulated", Type: "boolean", Description: "Whether sends were only logged"},
+		},
+		SideEffect:   SideEffectExternal,
+		Notification: true,
+	}
+}
+
+// VoiceCallAction phones responders and reads a message; with an
+// incident_id, pressing 1 acknowledges the incident
+type VoiceCallAction struct {
+	provider TelephonyProvider
+
+	mu          sync.RWMutex
+	callbackURL string
+}
+
+// NewVoiceCallAction creates a voice call action; callbackURL is the public
+// API base Twilio posts keypresses to
+func NewVoiceCallAction(provider TelephonyProvider, callbackURL string) *VoiceCallAction {
+	return &VoiceCallAction{
+		provider:    provider,
+		callbackURL: callbackURL,
+	}
+}
+
+// SetCallbackURL changes the public API base used for keypress callbacks
+func (a *VoiceCallAction) SetCallbackURL(callbackURL string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.callbackURL = callbackURL
+}
+
+func (a *VoiceCallAction) Execute(params map[string]interface{}) (interface{}, error) {
+	recipients := getRecipients(params)
+	message := getStringParam(params, "message", "")
+	incidentID := getStringParam(params, "incident_id", "")
+	if len(recipients) == 0 || message == "" {
+		return nil, fmt.Errorf("to and message parameters are required")
+	}
+
+	a.mu.RLock()
+	callbackURL := a.callbackURL
+	a.mu.RUnlock()
+
+	if incidentID != "" && callbackURL == "" {
+		log.Printf("[ACTION] [VOICE] PUBLIC_API_URL not set; call for incident %s cannot be acknowledged by keypress", incidentID)
+	}
+	twiml := voiceCallTwiML(message, incidentID, callbackURL)
+
+	return sendToRecipients(recipients, func(to string) (string, error) {
+		return a.provider.PlaceCall(to, twiml)
+	}, a.provider.Simulated())
+}
+
+func (a *VoiceCallAction) Describe() ActionDescriptor {
+	return ActionDescriptor{
+		Name:        "voice_call",
+		Description: "Phone responders and read a message; pressing 1 acknowledges the incident",
+		Parameters: []ActionParameter{
+			{Name: "to", Type: "any", Required: true, Description: "E.164 phone number or list of numbers"},
+			{Name: "message", Type: "string", Required: true, Description: "Message read to the callee"},
+			{Name: "incident_id", Type: "string", Description: "Incident acknowledged by keypress"},
+		},
+		Outputs: []ActionOutput{
+			{Name: "results", Type: "array", Items: "object", Required: true, Description: "Per-recipient call ID or error"},
+			{Name: "simulated", Type: "boolean", Description: "Whether calls were only logged"},
+		},
+		SideEffect:   SideEffectExternal,
+		Notification: true,
+	}
+}
+
+// getRecipients reads the "to" parameter as one number, a comma-separated
+// list or an array
+func getRecipients(params map[string]interface{}) []string {
+	return getListParam(params, "to")
+}
+
+// getListParam reads a parameter given as one value, a comma-separated list
+// or an array
+func getListParam(params map[string]interface{}, key string) []string {
+	var items []string
+	switch value := params[key].(type) {
+	case string:
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			items = append(items, fmt.Sprintf("%v", item))
+		}
+	case []string:
+		items = value
+	}
+	return items
+}
+
+// sendToRecipients sends to each recipient, failing only if every send failed
+func sendToRecipients(recipients []string, send func(to string) (string, error), simulated bool) (interface{}, error) {
+	results := make([]map[string]interface{}, 0, len(recipients))
+	var errs []string
+	for _, to := range recipients {
+		id, err := send(to)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", to, err))
+			results = append(results, map[string]interface{}{"to": to, "error": err.Error()})
+			continue
+		}
+		results = append(results, map[string]interface{}{"to": to, "id": id})
+	}
+
+	if len(errs) == len(recipients) {
+		return nil, fmt.Errorf("all sends failed: %s", strings.Join(errs, "; "))
+	}
+	return map[string]interface{}{
+		"results":   results,
+		"simulated": simulated,
+	}, nil
+}
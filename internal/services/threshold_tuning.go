@@ -0,0 +1,251 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// Limits on one threshold sweep
+const (
+	maxTuningThresholds  = 50
+	maxTuningTimeWindows = 20
+)
+
+// ErrInvalidTuning is returned for a threshold sweep that can't be run
+var ErrInvalidTuning = errors.New("invalid threshold sweep")
+
+// TuningRequest is a sweep of a rule's count condition over thresholds and
+// time windows
+type TuningRequest struct {
+	RuleID string `json:"rule_id"`
+	// Condition is the index of the count condition to tune; the rule's
+	// first count condition when nil
+	Condition *int `json:"condition"`
+	// Thresholds to try; by default 1 up to twice the current threshold
+	Thresholds []int `json:"thresholds"`
+	// TimeWindows to try, in seconds; by default the current window
+	TimeWindows []int `json:"timewindows"`
+}
+
+// TuningReport is the incident volume of a rule across a sweep
+type TuningReport struct {
+	RuleID           string        `json:"rule_id"`
+	Condition        int           `json:"condition"`
+	Operator         string        `json:"operator"`
+	CurrentThreshold int           `json:"current_threshold"`
+	CurrentWindow    int           `json:"current_timewindow"`
+	EventsReplayed   int           `json:"events_replayed"`
+	Days             float64       `json:"days"`
+	Points           []TuningPoint `json:"points"`
+}
+
+// TuningPoint is what the rule would have done with one threshold and
+// window
+type TuningPoint struct {
+	Threshold       int     `json:"threshold"`
+	TimeWindow      int     `json:"timewindow"`
+	Matches         int     `json:"matches"`
+	Incidents       int     `json:"incidents"`
+	IncidentsPerDay float64 `json:"incidents_per_day"`
+	Current         bool    `json:"current,omitempty"`
+}
+
+// findRule returns a loaded rule by ID
+func (de *DetectionEngine) findRule(ruleID string) (Rule, bool) {
+	de.mu.RLock()
+	defer de.mu.RUnlock()
+	for _, rule := range de.rules {
+		if rule.Rule.ID == ruleID {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// tuningRule validates a sweep and returns the rule and condition index it
+// tunes
+func (s *Simulator) tuningRule(req TuningRequest) (Rule, int, error) {
+	rule, ok := s.engine.findRule(req.RuleID)
+	if !ok {
+		return Rule{}, 0, fmt.Errorf("%w: rule %q is not loaded", ErrInvalidTuning, req.RuleID)
+	}
+	conditions := rule.Rule.Conditions
+	if req.Condition != nil {
+		i := *req.Condition
+		if i < 0 || i >= len(conditions) || !isCountOperator(conditions[i].Operator) {
+			return Rule{}, 0, fmt.Errorf("%w: condition %d of %s is not a count condition", ErrInvalidTuning, i, req.RuleID)
+		}
+		return rule, i, nil
+	}
+	for i, cond := range conditions {
+		if isCountOperator(cond.Operator) {
+			return rule, i, nil
+		}
+	}
+	return Rule{}, 0, fmt.Errorf("%w: rule %s has no count condition", ErrInvalidTuning, req.RuleID)
+}
+
+// TuningEventTypes returns the event types a sweep needs to replay: those
+// the rule accepts, unless the tuned condition counts events selected by a
+// filter, in which case all events are needed (nil)
+func (s *Simulator) TuningEventTypes(req TuningRequest) ([]string, error) {
+	rule, index, err := s.tuningRule(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(rule.Rule.Conditions[index].Filter) > 0 {
+		return nil, nil
+	}
+	types, _ := indexableValues(rule, "event_type")
+	return types, nil
+}
+
+// TuneThresholds replays events, in timestamp order, through one rule with
+// each time window of the sweep and reports the matches and incidents each
+// threshold would have produced over the days the events span. Each window
+// is replayed once with the tuned condition passing on any count; an event
+// matches at a threshold when its count reaches it. Incidents are
+// deduplicated by correlation key as in Replay. Seen-value conditions after
+// the tuned condition record every event that reaches them, as if it had
+// passed.
+func (s *Simulator) TuneThresholds(events []*models.Event, req TuningRequest, from, to time.Time) (*TuningReport, error) {
+	rule, index, err := s.tuningRule(req)
+	if err != nil {
+		return nil, err
+	}
+	current := rule.Rule.Conditions[index]
+
+	if len(req.Thresholds) > maxTuningThresholds || len(req.TimeWindows) > maxTuningTimeWindows {
+		return nil, fmt.Errorf("%w: at most %d thresholds and %d time windows", ErrInvalidTuning, maxTuningThresholds, maxTuningTimeWindows)
+	}
+	thresholds := append([]int(nil), req.Thresholds...)
+	if len(thresholds) == 0 {
+		thresholds = defaultThresholds(current.Threshold)
+	}
+	windows := append([]int(nil), req.TimeWindows...)
+	if len(windows) == 0 {
+		windows = []int{current.TimeWindow}
+	}
+	for _, t := range thresholds {
+		if t <= 0 {
+			return nil, fmt.Errorf("%w: thresholds must be positive", ErrInvalidTuning)
+		}
+	}
+	for _, w := range windows {
+		if w <= 0 {
+			return nil, fmt.Errorf("%w: time windows must be positive", ErrInvalidTuning)
+		}
+	}
+	sort.Ints(thresholds)
+	sort.Ints(windows)
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+	normalized := make([]map[string]interface{}, len(events))
+	for i, event := range events {
+		if err := json.Unmarshal([]byte(event.Normalized), &normalized[i]); err != nil {
+			return nil, fmt.Errorf("event %s: failed to parse normalized data: %w", event.EventID, err)
+		}
+	}
+
+	days := to.Sub(from).Hours() / 24
+	report := &TuningReport{
+		RuleID:           rule.Rule.ID,
+		Condition:        index,
+		Operator:         current.Operator,
+		CurrentThreshold: current.Threshold,
+		CurrentWindow:    current.TimeWindow,
+		EventsReplayed:   len(events),
+		Days:             days,
+		Points:           []TuningPoint{},
+	}
+
+	for _, window := range windows {
+		variant := rule
+		variant.Rule.Conditions = append([]Condition(nil), rule.Rule.Conditions...)
+		variant.Rule.Conditions[index].TimeWindow = window
+		variant.Rule.Conditions[index].Threshold = 1
+
+		// The highest count each incident reached, and the count of each
+		// match, in order
+		replay := &replayWindow{}
+		var counts []int64
+		peaks := make(map[string]int64)
+		var unkeyed []int64
+		for i, event := range events {
+			replay.add(event, normalized[i])
+			trace := &RuleTrace{}
+			if !s.engine.matchesRule(event, normalized[i], variant, replay.count, nil, trace) {
+				continue
+			}
+			count := trace.Conditions[index].Count
+			if count == nil {
+				continue
+			}
+			counts = append(counts, *count)
+			key := s.engine.correlationKey(normalized[i], variant)
+			if key == "" {
+				unkeyed = append(unkeyed, *count)
+			} else if *count > peaks[key] {
+				peaks[key] = *count
+			}
+		}
+
+		for _, threshold := range thresholds {
+			point := TuningPoint{
+				Threshold:  threshold,
+				TimeWindow: window,
+				Current:    threshold == current.Threshold && window == current.TimeWindow,
+			}
+			for _, count := range counts {
+				if count >= int64(threshold) {
+					point.Matches++
+				}
+			}
+			for _, peak := range peaks {
+				if peak >= int64(threshold) {
+					point.Incidents++
+				}
+			}
+			for _, count := range unkeyed {
+				if count >= int64(threshold) {
+					point.Incidents++
+				}
+			}
+			if days > 0 {
+				point.IncidentsPerDay = float64(point.Incidents) / days
+			}
+			report.Points = append(report.Points, point)
+		}
+	}
+	return report, nil
+}
+
+// defaultThresholds spans 1 to twice the current threshold (at least 10) in
+// up to maxTuningThresholds steps, and includes the current threshold
+func defaultThresholds(current int) []int {
+	top := 2 * current
+	if top < 10 {
+		top = 10
+	}
+	step := (top + maxTuningThresholds - 1) / maxTuningThresholds
+	var thresholds []int
+	for t := 1; t <= top; t += step {
+		thresholds = append(thresholds, t)
+	}
+	if thresholds[len(thresholds)-1] != top {
+		thresholds[len(thresholds)-1] = top
+	}
+	for _, t := range thresholds {
+		if t == current {
+			return thresholds
+		}
+	}
+	return append(thresholds, current)
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// DefaultBusinessHours is the organization schedule used when none is
+// configured
+const DefaultBusinessHours = "mon-fri 09:00-17:00"
+
+// defaultBusinessHours parses DefaultBusinessHours in UTC
+func defaultBusinessHours() *BusinessHours {
+	hours, _ := ParseBusinessHours(DefaultBusinessHours, "")
+	return hours
+}
+
+// SetBusinessHours sets the organization schedule that business_hours,
+// outside_business_hours and weekend conditions test against
+func (de *DetectionEngine) SetBusinessHours(hours *BusinessHours) {
+	if hours != nil {
+		de.hours = hours
+	}
+}
+
+// weekdayNames maps day abbreviations to time.Weekday
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// isTimeOperator reports whether an operator tests the event's time rather
+// than a field
+func isTimeOperator(op string) bool {
+	return op == "business_hours" || op == "outside_business_hours" || op == "weekend" || op == "time_window"
+}
+
+// hoursWindow is a daily time range on some days of the week. A range whose
+// end is not after its start crosses midnight and belongs to the day it
+// starts on.
+type hoursWindow struct {
+	days       [7]bool
+	start, end int // minutes since midnight
+}
+
+// contains reports whether a local time falls in the window
+func (w hoursWindow) contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+	if w.start < w.end {
+		return w.days[day] && minute >= w.start && minute < w.end
+	}
+	yesterday := (day + 6) % 7
+	return (w.days[day] && minute >= w.start) || (w.days[yesterday] && minute < w.end)
+}
+
+// BusinessHours is an organization's weekly working schedule in its
+// timezone. Days without any hours are its weekend.
+type BusinessHours struct {
+	location *time.Location
+	windows  []hoursWindow
+}
+
+// ParseBusinessHours parses a schedule such as "mon-fri 09:00-17:00" or
+// "mon-thu 08:00-18:00,fri 08:00-14:00" in an IANA timezone (UTC when empty)
+func ParseBusinessHours(spec, timezone string) (*BusinessHours, error) {
+	location, err := loadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+	hours := &BusinessHours{location: location}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		days, span, ok := strings.Cut(entry, " ")
+		if !ok {
+			return nil, fmt.Errorf("invalid business hours %q: expected \"days HH:MM-HH:MM\"", entry)
+		}
+		start, end, ok := strings.Cut(strings.TrimSpace(span), "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid business hours %q: expected HH:MM-HH:MM", entry)
+		}
+		window, err := newHoursWindow(strings.Split(days, "/"), start, end)
+		if err == nil && window.start == window.end {
+			err = fmt.Errorf("start and end are equal")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid business hours %q: %w", entry, err)
+		}
+		hours.windows = append(hours.windows, window)
+	}
+	if len(hours.windows) == 0 {
+		return nil, fmt.Errorf("business hours %q define no working hours", spec)
+	}
+	return hours, nil
+}
+
+// Location returns the schedule's timezone
+func (b *BusinessHours) Location() *time.Location {
+	return b.location
+}
+
+// Contains reports whether t falls in working hours, read in loc or the
+// schedule's own timezone when loc is nil
+func (b *BusinessHours) Contains(t time.Time, loc *time.Location) bool {
+	if loc == nil {
+		loc = b.location
+	}
+	local := t.In(loc)
+	for _, window := range b.windows {
+		if window.contains(local) {
+			return true
+		}
+	}
+	return false
+}
+
+// Weekend reports whether t falls on a day without working hours, read in
+// loc or the schedule's own timezone when loc is nil
+func (b *BusinessHours) Weekend(t time.Time, loc *time.Location) bool {
+	if loc == nil {
+		loc = b.location
+	}
+	day := t.In(loc).Weekday()
+	for _, window := range b.windows {
+		if window.days[day] {
+			return false
+		}
+	}
+	return true
+}
+
+// newHoursWindow builds a window from day names or ranges ("mon-fri",
+// "sat"; all days when empty) and HH:MM bounds
+func newHoursWindow(days []string, start, end string) (hoursWindow, error) {
+	var window hoursWindow
+	var err error
+	if window.start, err = parseWindowBound(start); err != nil {
+		return window, err
+	}
+	if window.end, err = parseWindowBound(end); err != nil {
+		return window, err
+	}
+
+	if len(days) == 0 {
+		for i := range window.days {
+			window.days[i] = true
+		}
+		return window, nil
+	}
+	for _, spec := range days {
+		spec = strings.ToLower(strings.TrimSpace(spec))
+		from, to, isRange := strings.Cut(spec, "-")
+		first, ok := weekdayNames[from]
+		if !ok {
+			return window, fmt.Errorf("unknown day %q", from)
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdayNames[to]; !ok {
+				return window, fmt.Errorf("unknown day %q", to)
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			window.days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+	return window, nil
+}
+
+// parseWindowBound parses an HH:MM window bound; "24:00" ends a day
+func parseWindowBound(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "24:00" {
+		return 24 * 60, nil
+	}
+	return parseClock(value)
+}
+
+// loadLocation loads an IANA timezone, UTC when empty
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return location, nil
+}
+
+// compileTimeCondition resolves a time condition's timezone and, for
+// time_window, its days and hours
+func compileTimeCondition(c *Condition) error {
+	location, err := loadLocation(c.Timezone)
+	if err != nil {
+		return fmt.Errorf("%s condition: %w", c.Operator, err)
+	}
+	if c.Timezone != "" {
+		c.location = location
+	}
+	if c.Operator != "time_window" {
+		return nil
+	}
+
+	start, end := c.Start, c.End
+	if start == "" {
+		start = "00:00"
+	}
+	if end == "" {
+		end = "24:00"
+	}
+	window, err := newHoursWindow(c.Days, start, end)
+	if err != nil {
+		return fmt.Errorf("time_window condition: %w", err)
+	}
+	if window.start == window.end {
+		return fmt.Errorf("time_window condition: start and end are equal")
+	}
+	c.window = &window
+	if c.location == nil {
+		c.location = time.UTC
+	}
+	return nil
+}
+
+// evaluateTimeCondition tests the event's time against business hours or a
+// condition's own window
+func (de *DetectionEngine) evaluateTimeCondition(event *models.Event, cond Condition) bool {
+	switch cond.Operator {
+	case "business_hours":
+		return de.hours.Contains(event.Timestamp, cond.location)
+	case "outside_business_hours":
+		return !de.hours.Contains(event.Timestamp, cond.location)
+	case "weekend":
+		return de.hours.Weekend(event.Timestamp, cond.location)
+	case "time_window":
+		return cond.window != nil && cond.window.contains(event.Timestamp.In(cond.location))
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// compileTransform parses a transform step's template: a single expression
+// string, or an object or list whose string leaves are expressions and whose
+// other leaves are literals
+func compileTransform(template interface{}) (interface{}, error) {
+	switch t := template.(type) {
+	case string:
+		source := strings.TrimSpace(t)
+		if strings.HasPrefix(source, "{{") && strings.HasSuffix(source, "}}") {
+			source = strings.TrimSpace(source[2 : len(source)-2])
+		}
+		return compileExpression(source)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for key, value := range t {
+			compiled, err := compileTransform(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			out[key] = compiled
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, value := range t {
+			compiled, err := compileTransform(value)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			out[i] = compiled
+		}
+		return out, nil
+	default:
+		return t, nil
+	}
+}
+
+// evaluateTransform evaluates a compiled transform template against vars
+func evaluateTransform(compiled interface{}, vars map[string]interface{}) (interface{}, error) {
+	switch t := compiled.(type) {
+	case *expression:
+		return t.evaluate(vars)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for key, value := range t {
+			result, err := evaluateTransform(value, vars)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			out[key] = result
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, value := range t {
+			result, err := evaluateTransform(value, vars)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			out[i] = result
+		}
+		return out, nil
+	default:
+		return t, nil
+	}
+}
+
+// runTransform evaluates a transform step against the playbook context
+func (o *Orchestrator) runTransform(step PlaybookStep, context map[string]interface{}) (interface{}, error) {
+	compiled, err := compileTransform(step.Transform)
+	if err != nil {
+		return nil, err
+	}
+	vars, _ := plainValue(context).(map[string]interface{})
+	return evaluateTransform(compiled, vars)
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// ParseUpdateCadence parses a per-severity stakeholder update interval list
+// such as "critical=30m,high=2h"; severities not listed have no cadence
+func ParseUpdateCadence(spec string) (map[models.SeverityLevel]time.Duration, error) {
+	return parseSeverityDurations(spec, "update cadence")
+}
+
+// UpdateCadence reminds an incident's commander (its assignee when no
+// commander is assigned) when an
+// unresolved incident has gone its severity's interval without a
+// stakeholder update. The reminder repeats each interval until an update is
+// sent.
+type UpdateCadence struct {
+	db       *gorm.DB
+	outbox   *Outbox
+	cadences map[models.SeverityLevel]time.Duration
+}
+
+// NewUpdateCadence creates an update cadence reminder
+func NewUpdateCadence(db *gorm.DB, outbox *Outbox, cadences map[models.SeverityLevel]time.Duration) *UpdateCadence {
+	return &UpdateCadence{db: db, outbox: outbox, cadences: cadences}
+}
+
+// Enabled reports whether any severity has a cadence
+func (u *UpdateCadence) Enabled() bool {
+	return len(u.cadences) > 0
+}
+
+// Run queues a reminder for every unresolved incident with an overdue
+// stakeholder update that hasn't been reminded within its interval
+func (u *UpdateCadence) Run() error {
+	now := time.Now().UTC()
+	for severity, interval := range u.cadences {
+		due := now.Add(-interval)
+		var incidents []models.Incident
+		if err := u.db.Where("severity = ? AND status <> ?", severity, models.StatusResolved).
+			Where("COALESCE(last_stakeholder_update_at, created_at) <= ?", due).
+			Where("update_reminded_at IS NULL OR update_reminded_at <= ?", due).
+			Find(&incidents).Error; err != nil {
+			return fmt.Errorf("failed to fetch incidents: %w", err)
+		}
+		for i := range incidents {
+			if err := u.remind(&incidents[i], interval, now); err != nil {
+				log.Printf("Update reminder failed for incident %s: %v", incidents[i].IncidentID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// remind records the reminder and queues it, without touching updated_at so
+// reminders don't count as incident activity
+func (u *UpdateCadence) remind(incident *models.Incident, interval time.Duration, now time.Time) error {
+	since := incident.CreatedAt
+	last := "no stakeholder update yet"
+	if incident.LastStakeholderUpdateAt != nil {
+		since = *incident.LastStakeholderUpdateAt
+		last = "last stakeholder update " + formatIdle(now.Sub(since)) + " ago"
+	}
+	message := fmt.Sprintf("Stakeholder update overdue for %s incident '%s': %s (due every %s). Send one with POST /api/v1/incidents/%s/updates",
+		incident.Severity, incident.Title, last, formatIdle(interval), incident.IncidentID)
+
+	commander := incidentCommander(u.db, incident)
+	params, _ := json.Marshal(map[string]interface{}{
+		"severity":  incident.Severity,
+		"interval":  interval.String(),
+		"commander": commander,
+	})
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(incident).UpdateColumn("update_reminded_at", now).Error; err != nil {
+			return fmt.Errorf("failed to update incident: %w", err)
+		}
+		completed := time.Now().UTC()
+		entry := &models.ActionLog{
+			ActionType:  "stakeholder_update_reminder",
+			Status:      models.ActionCompleted,
+			IncidentID:  &incident.IncidentID,
+			Parameters:  string(params),
+			CompletedAt: &completed,
+			Notes:       message,
+		}
+		if err := tx.Create(entry).Error; err != nil {
+			return fmt.Errorf("failed to record update reminder: %w", err)
+		}
+		// The commander gets it at their notification target, or the
+		// incident's routes do when there's no commander or target
+		if err := u.outbox.Enqueue(tx, TopicNotifyUser, map[string]interface{}{
+			"incident_id": incident.IncidentID,
+			"user":        commander,
+			"severity":    string(incident.Severity),
+			"category":    incident.Category,
+			"team":        incident.Team,
+			"tags":        decodeStringList(incident.Tags),
+			"message":     message,
+		}); err != nil {
+			return err
+		}
+		log.Printf("Update reminder for incident %s: %s", incident.IncidentID, message)
+		return nil
+	})
+}
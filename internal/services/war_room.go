@@ -0,0 +1,353 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WarRoomSpec describes the war room to open for an incident
+type WarRoomSpec struct {
+	// Name is a channel-safe name, e.g. "inc-1a2b3c4d-ssh-brute-force"
+	Name    string
+	Topic   string
+	Summary string
+	// Invitees are the email addresses of the people to invite
+	Invitees []string
+}
+
+// WarRoom is a channel or meeting opened by a provider
+type WarRoom struct {
+	ID  string
+	URL string
+	// Invited are the invitees the provider could add
+	Invited []string
+}
+
+// WarRoomProvider opens a chat channel or meeting bridge for an incident
+type WarRoomProvider interface {
+	Open(ctx context.Context, spec WarRoomSpec) (*WarRoom, error)
+}
+
+// SlackWarRooms creates a Slack channel through the Web API, invites
+// members by email, sets the topic and posts the summary
+type SlackWarRooms struct {
+	token   string
+	baseURL string
+	client  *http.Client
+}
+
+// NewSlackWarRooms creates a Slack provider with a bot token holding the
+// channels:manage, chat:write and users:read.email scopes
+func NewSlackWarRooms(token string) *SlackWarRooms {
+	return &SlackWarRooms{token: token, baseURL: "https://slack.com/api", client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// Open creates the channel. Invitees without a Slack account are skipped.
+func (p *SlackWarRooms) Open(ctx context.Context, spec WarRoomSpec) (*WarRoom, error) {
+	var created struct {
+		Channel struct {
+			ID string `json:"id"`
+		} `json:"channel"`
+	}
+	if err := p.call(ctx, "conversations.create", map[string]interface{}{"name": spec.Name}, &created); err != nil {
+		return nil, err
+	}
+	room := &WarRoom{ID: created.Channel.ID, URL: "https://slack.com/app_redirect?channel=" + created.Channel.ID}
+
+	var userIDs []string
+	for _, email := range spec.Invitees {
+		var found struct {
+			User struct {
+				ID string `json:"id"`
+			} `json:"user"`
+		}
+		if err := p.call(ctx, "users.lookupByEmail", map[string]interface{}{"email": email}, &found); err != nil {
+			continue
+		}
+		userIDs = append(userIDs, found.User.ID)
+		room.Invited = append(room.Invited, email)
+	}
+	if len(userIDs) > 0 {
+		if err := p.call(ctx, "conversations.invite", map[string]interface{}{
+			"channel": room.ID, "users": strings.Join(userIDs, ","),
+		}, nil); err != nil {
+			return room, err
+		}
+	}
+	if err := p.call(ctx, "conversations.setTopic", map[string]interface{}{"channel": room.ID, "topic": spec.Topic}, nil); err != nil {
+		return room, err
+	}
+	return room, p.call(ctx, "chat.postMessage", map[string]interface{}{"channel": room.ID, "text": spec.Summary}, nil)
+}
+
+// call invokes a Web API method and decodes its response into out
+func (p *SlackWarRooms) call(ctx context.Context, method string, body map[string]interface{}, out interface{}) error {
+	var req *http.Request
+	var err error
+	if method == "users.lookupByEmail" {
+		// Lookups only accept form or query arguments
+		query := url.Values{"email": {fmt.Sprint(body["email"])}}
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/"+method+"?"+query.Encode(), nil)
+	} else {
+		payload, _ := json.Marshal(body)
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/"+method, bytes.NewReader(payload))
+		if req != nil {
+			req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+
+	data, err := doRequest(p.client, req)
+	if err != nil {
+		return fmt.Errorf("slack %s failed: %w", method, err)
+	}
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("failed to decode slack %s response: %w", method, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("slack %s: %s", method, result.Error)
+	}
+	if out != nil {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
+
+// TeamsWarRooms creates a Microsoft Teams meeting through Microsoft Graph,
+// with the invitees as attendees, using an app registration's client
+// credentials
+type TeamsWarRooms struct {
+	organizer string
+	token     *oauthToken
+	graphURL  string
+	client    *http.Client
+}
+
+// NewTeamsWarRooms creates a Teams provider creating meetings on behalf of
+// organizer (a user ID or principal name); the app needs the
+// OnlineMeetings.ReadWrite.All application permission
+func NewTeamsWarRooms(tenantID, clientID, clientSecret, organizer string) *TeamsWarRooms {
+	client := &http.Client{Timeout: 15 * time.Second}
+	return &TeamsWarRooms{
+		organizer: organizer,
+		token: &oauthToken{
+			endpoint: "https://login.microsoftonline.com/" + url.PathEscape(tenantID) + "/oauth2/v2.0/token",
+			form: url.Values{
+				"grant_type":    {"client_credentials"},
+				"client_id":     {clientID},
+				"client_secret": {clientSecret},
+				"scope":         {"https://graph.microsoft.com/.default"},
+			},
+			client: client,
+		},
+		graphURL: "https://graph.microsoft.com/v1.0",
+		client:   client,
+	}
+}
+
+// Open creates a meeting running for the next 24 hours with the topic as
+// its subject. Teams meetings have no chat to post to before they start, so
+// the summary isn't posted.
+func (p *TeamsWarRooms) Open(ctx context.Context, spec WarRoomSpec) (*WarRoom, error) {
+	attendees := make([]map[string]interface{}, 0, len(spec.Invitees))
+	for _, email := range spec.Invitees {
+		attendees = append(attendees, map[string]interface{}{"upn": email, "role": "presenter"})
+	}
+	start := time.Now().UTC()
+	body := map[string]interface{}{
+		"subject":       spec.Topic,
+		"startDateTime": start.Format(time.RFC3339),
+		"endDateTime":   start.Add(24 * time.Hour).Format(time.RFC3339),
+		"participants":  map[string]interface{}{"attendees": attendees},
+	}
+	var meeting struct {
+		ID         string `json:"id"`
+		JoinWebURL string `json:"joinWebUrl"`
+	}
+	endpoint := p.graphURL + "/users/" + url.PathEscape(p.organizer) + "/onlineMeetings"
+	if err := postJSON(ctx, p.client, p.token, endpoint, body, &meeting); err != nil {
+		return nil, fmt.Errorf("teams meeting: %w", err)
+	}
+	return &WarRoom{ID: meeting.ID, URL: meeting.JoinWebURL, Invited: spec.Invitees}, nil
+}
+
+// ZoomWarRooms starts an instant Zoom meeting through a Server-to-Server
+// OAuth app
+type ZoomWarRooms struct {
+	token  *oauthToken
+	apiURL string
+	client *http.Client
+}
+
+// NewZoomWarRooms creates a Zoom provider; the app needs the
+// meeting:write:admin scope
+func NewZoomWarRooms(accountID, clientID, clientSecret string) *ZoomWarRooms {
+	client := &http.Client{Timeout: 15 * time.Second}
+	return &ZoomWarRooms{
+		token: &oauthToken{
+			endpoint: "https://zoom.us/oauth/token",
+			form:     url.Values{"grant_type": {"account_credentials"}, "account_id": {accountID}},
+			username: clientID,
+			password: clientSecret,
+			client:   client,
+		},
+		apiURL: "https://api.zoom.us/v2",
+		client: client,
+	}
+}
+
+// Open starts an instant meeting with the summary as its agenda
+func (p *ZoomWarRooms) Open(ctx context.Context, spec WarRoomSpec) (*WarRoom, error) {
+	invitees := make([]map[string]string, 0, len(spec.Invitees))
+	for _, email := range spec.Invitees {
+		invitees = append(invitees, map[string]string{"email": email})
+	}
+	body := map[string]interface{}{
+		"topic":  truncate(spec.Topic, 200),
+		"type":   1, // instant
+		"agenda": truncate(spec.Summary, 2000),
+		"settings": map[string]interface{}{
+			"join_before_host": true,
+			"meeting_invitees": invitees,
+		},
+	}
+	var meeting struct {
+		ID      int64  `json:"id"`
+		JoinURL string `json:"join_url"`
+	}
+	if err := postJSON(ctx, p.client, p.token, p.apiURL+"/users/me/meetings", body, &meeting); err != nil {
+		return nil, fmt.Errorf("zoom meeting: %w", err)
+	}
+	return &WarRoom{ID: fmt.Sprint(meeting.ID), URL: meeting.JoinURL, Invited: spec.Invitees}, nil
+}
+
+// oauthToken fetches and caches a client credentials access token
+type oauthToken struct {
+	endpoint string
+	form     url.Values
+	// username and password, when set, authenticate the client with basic
+	// auth instead of form fields
+	username string
+	password string
+	client   *http.Client
+
+	mu      sync.Mutex
+	value   string
+	expires time.Time
+}
+
+// get returns a cached token, fetching a new one a minute before expiry
+func (t *oauthToken) get(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.value != "" && time.Now().Before(t.expires) {
+		return t.value, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, strings.NewReader(t.form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if t.username != "" {
+		req.SetBasicAuth(t.username, t.password)
+	}
+	data, err := doRequest(t.client, req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(data, &token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("token response has no access_token")
+	}
+	t.value = token.AccessToken
+	t.expires = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return t.value, nil
+}
+
+// postJSON posts body with a bearer token and decodes the response into out
+func postJSON(ctx context.Context, client *http.Client, token *oauthToken, endpoint string, body, out interface{}) error {
+	accessToken, err := token.get(ctx)
+	if err != nil {
+		return err
+	}
+	payload, _ := json.Marshal(body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+	data, err := doRequest(client, req)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// doRequest sends a request and returns the body of a 2xx response
+func doRequest(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, truncate(string(data), 200))
+	}
+	return data, nil
+}
+
+// channelUnsafe matches runs of characters Slack channel names can't hold
+var channelUnsafe = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// warRoomName derives a channel name from an incident: "inc-", the first
+// eight characters of its ID and its title, lowercased and at most 80
+// characters
+func warRoomName(incidentID, title string) string {
+	short := incidentID
+	if len(short) > 8 {
+		short = short[:8]
+	}
+	slug := strings.Trim(channelUnsafe.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	name := "inc-" + strings.ToLower(short)
+	if slug != "" {
+		name += "-" + slug
+	}
+	return strings.TrimRight(truncate(name, 80), "-")
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// WarRoomAction opens a dedicated Slack channel, Teams meeting or Zoom
+// bridge for an incident, invites the on-call responders and the owning
+// team, posts the incident summary and records the links on the incident
+type WarRoomAction struct {
+	db        *gorm.DB
+	providers map[string]WarRoomProvider
+	oncall    []string
+	teams     map[string][]string
+}
+
+// NewWarRoomAction creates a war room action. providers holds the
+// configured providers by name (slack, teams, zoom); oncall and teams give
+// the email addresses invited to every war room and to those of incidents
+// owned by each team.
+func NewWarRoomAction(db *gorm.DB, providers map[string]WarRoomProvider, oncall []string, teams map[string][]string) *WarRoomAction {
+	return &WarRoomAction{db: db, providers: providers, oncall: oncall, teams: teams}
+}
+
+// ParseWarRoomTeams parses "security=a@example.com|b@example.com,payments=c@example.com"
+func ParseWarRoomTeams(spec string) (map[string][]string, error) {
+	teams := make(map[string][]string)
+	for _, entry := range SplitList(spec) {
+		team, members, ok := strings.Cut(entry, "=")
+		team = strings.TrimSpace(team)
+		if !ok || team == "" {
+			return nil, fmt.Errorf("invalid war room team %q: expected team=email|email", entry)
+		}
+		for _, member := range strings.Split(members, "|") {
+			if member = strings.TrimSpace(member); member != "" {
+				teams[team] = append(teams[team], member)
+			}
+		}
+	}
+	return teams, nil
+}
+
+func (a *WarRoomAction) Execute(params map[string]interface{}) (interface{}, error) {
+	return a.ExecuteContext(context.Background(), params)
+}
+
+func (a *WarRoomAction) ExecuteContext(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	incidentID := getStringParam(params, "incident_id", "")
+	if incidentID == "" {
+		return nil, fmt.Errorf("incident_id parameter is required")
+	}
+	var incident models.Incident
+	if err := a.db.First(&incident, "incident_id = ?", incidentID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch incident %s: %w", incidentID, err)
+	}
+
+	minSeverity := models.SeverityLevel(getStringParam(params, "min_severity", string(models.SeverityCritical)))
+	if incident.Severity.Rank() < minSeverity.Rank() {
+		return map[string]interface{}{
+			"skipped": true,
+			"reason":  fmt.Sprintf("incident severity %s is below %s", incident.Severity, minSeverity),
+			"rooms":   []interface{}{},
+		}, nil
+	}
+
+	spec := WarRoomSpec{
+		Name:     warRoomName(incident.IncidentID, incident.Title),
+		Topic:    truncate(fmt.Sprintf("[%s] %s", strings.ToUpper(string(incident.Severity)), incident.Title), 250),
+		Summary:  warRoomSummary(&incident, getStringParam(params, "message", "")),
+		Invitees: a.invitees(&incident, getListParam(params, "invite")),
+	}
+
+	providers := getListParam(params, "providers")
+	if len(providers) == 0 {
+		providers = []string{"slack"}
+	}
+	rooms := make([]map[string]interface{}, 0, len(providers))
+	var opened []models.WarRoomLink
+	var errs []string
+	for _, name := range providers {
+		name = strings.ToLower(name)
+		if existing := findWarRoom(incident.WarRooms, name); existing != nil {
+			// Reruns reuse the room instead of opening another
+			rooms = append(rooms, map[string]interface{}{"provider": name, "id": existing.ID, "url": existing.URL, "existing": true})
+			continue
+		}
+		provider, ok := a.providers[name]
+		if !ok {
+			if !knownWarRoomProvider(name) {
+				errs = append(errs, fmt.Sprintf("%s: unknown provider", name))
+				continue
+			}
+			log.Printf("[ACTION] [WAR ROOM] (simulated) %s %s for incident %s, inviting %s", name, spec.Name, incident.IncidentID, strings.Join(spec.Invitees, ", "))
+			rooms = append(rooms, map[string]interface{}{"provider": name, "name": spec.Name, "simulated": true})
+			continue
+		}
+
+		room, err := provider.Open(ctx, spec)
+		if room != nil && room.ID != "" {
+			// Keep a partly set up room so a rerun doesn't open a second one
+			opened = append(opened, models.WarRoomLink{Provider: name, Name: spec.Name, ID: room.ID, URL: room.URL, CreatedAt: time.Now().UTC()})
+			entry := map[string]interface{}{"provider": name, "name": spec.Name, "id": room.ID, "url": room.URL, "invited": room.Invited}
+			if err != nil {
+				entry["error"] = err.Error()
+			}
+			rooms = append(rooms, entry)
+		}
+		if err != nil {
+			log.Printf("War room on %s for incident %s failed: %v", name, incident.IncidentID, err)
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+
+	if len(opened) > 0 {
+		if err := a.recordLinks(incident.IncidentID, opened); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	result := map[string]interface{}{"rooms": rooms, "invitees": spec.Invitees}
+	if len(errs) > 0 && len(rooms) == 0 {
+		return nil, fmt.Errorf("no war room opened: %s", strings.Join(errs, "; "))
+	}
+	if len(errs) > 0 {
+		result["errors"] = errs
+	}
+	return result, nil
+}
+
+// invitees are the on-call responders, the incident's owning team and any
+// extra addresses, without duplicates
+func (a *WarRoomAction) invitees(incident *models.Incident, extra []string) []string {
+	seen := make(map[string]bool)
+	var invitees []string
+	for _, group := range [][]string{a.oncall, a.teams[incident.Team], extra} {
+		for _, email := range group {
+			key := strings.ToLower(email)
+			if !seen[key] {
+				seen[key] = true
+				invitees = append(invitees, email)
+			}
+		}
+	}
+	return invitees
+}
+
+// recordLinks appends opened war rooms to the incident's links, re-reading
+// them so concurrent runs for other providers aren't lost
+func (a *WarRoomAction) recordLinks(incidentID string, opened []models.WarRoomLink) error {
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		var incident models.Incident
+		if err := tx.Select("incident_id", "war_rooms").First(&incident, "incident_id = ?", incidentID).Error; err != nil {
+			return fmt.Errorf("failed to fetch incident %s: %w", incidentID, err)
+		}
+		links := append(incident.WarRooms, opened...)
+		if err := tx.Model(&incident).Select("war_rooms").Updates(&models.Incident{WarRooms: links}).Error; err != nil {
+			return fmt.Errorf("failed to record war room links: %w", err)
+		}
+		return nil
+	})
+}
+
+// warRoomSummary is the incident summary posted to a new war room
+func warRoomSummary(incident *models.Incident, message string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "War room for incident %s\n", incident.IncidentID)
+	fmt.Fprintf(&b, "*%s* (severity %s, status %s)\n", incident.Title, incident.Severity, incident.Status)
+	if incident.Team != "" {
+		fmt.Fprintf(&b, "Owning team: %s\n", incident.Team)
+	}
+	if incident.Services != "" && incident.Services != "[]" && incident.Services != "null" {
+		fmt.Fprintf(&b, "Impacted services: %s\n", incident.Services)
+	}
+	fmt.Fprintf(&b, "Opened: %s\n", incident.CreatedAt.UTC().Format(time.RFC3339))
+	if incident.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", incident.Description)
+	}
+	if message != "" {
+		fmt.Fprintf(&b, "\n%s\n", message)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// findWarRoom returns the incident's war room on a provider, or nil
+func findWarRoom(links []models.WarRoomLink, provider string) *models.WarRoomLink {
+	for i := range links {
+		if links[i].Provider == provider {
+			return &links[i]
+		}
+	}
+	return nil
+}
+
+// knownWarRoomProvider reports whether name is a supported provider
+func knownWarRoomProvider(name string) bool {
+	return name == "slack" || name == "teams" || name == "zoom"
+}
+
+func (a *WarRoomAction) Describe() ActionDescriptor {
+	return ActionDescriptor{
+		Name:        "open_war_room",
+		Description: "Open a Slack channel, Teams meeting or Zoom bridge for an incident, invite the on-call and owning team, post the summary and record the links on the incident",
+		Parameters: []ActionParameter{
+			{Name: "incident_id", Type: "string", Required: true, Description: "Incident to open the war room for"},
+			{Name: "providers", Type: "any", Default: "slack", Description: "slack, teams and/or zoom, as a comma-separated list or an array"},
+			{Name: "invite", Type: "any", Description: "Email addresses to invite besides the on-call and owning team"},
+			{Name: "message", Type: "string", Description: "Text added to the posted summary"},
+			{Name: "min_severity", Type: "string", Default: "critical", Description: "Skip incidents below this severity"},
+		},
+		Outputs: []ActionOutput{
+			{Name: "rooms", Type: "array", Items: "object", Required: true, Description: "Per-provider room ID and link, or simulated"},
+			{Name: "invitees", Type: "array", Items: "string", Description: "Addresses invited"},
+			{Name: "skipped", Type: "boolean", Description: "Whether the incident was below min_severity"},
+			{Name: "errors", Type: "array", Items: "string", Description: "Providers that failed"},
+		},
+		SideEffect: SideEffectExternal,
+		// Opening a war room only brings people together, so it runs
+		// during containment pauses and change freezes
+		Notification: true,
+	}
+}
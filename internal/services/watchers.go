@@ -0,0 +1,121 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// TopicNotifyWatchers is the outbox topic for watched-incident updates
+const TopicNotifyWatchers = "notify_watchers"
+
+// TopicNotifyUser is the outbox topic for notifications addressed to one
+// responder, such as update reminders and shift handoffs
+const TopicNotifyUser = "notify_user"
+
+// WatchIncident subscribes user to an incident's updates. Watching an
+// incident twice keeps the original subscription.
+func WatchIncident(tx *gorm.DB, incidentID, user, reason string) error {
+	watcher := &models.IncidentWatcher{IncidentID: incidentID, User: user, Reason: reason}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(watcher).Error; err != nil {
+		return fmt.Errorf("failed to watch incident: %w", err)
+	}
+	return nil
+}
+
+// UnwatchIncident removes user's subscription to an incident
+func UnwatchIncident(tx *gorm.DB, incidentID, user string) error {
+	if err := tx.Where("incident_id = ? AND user = ?", incidentID, user).
+		Delete(&models.IncidentWatcher{}).Error; err != nil {
+		return fmt.Errorf("failed to unwatch incident: %w", err)
+	}
+	return nil
+}
+
+// NotifyWatchers queues a notification of an incident change to its
+// watchers, other than the actor who made it. Watchers are resolved when the
+// message is dispatched, so someone who stops watching first isn't notified.
+func NotifyWatchers(tx *gorm.DB, outbox *Outbox, incident *models.Incident, actor, change string) error {
+	return outbox.Enqueue(tx, TopicNotifyWatchers, map[string]interface{}{
+		"incident_id": incident.IncidentID,
+		"actor":       actor,
+		"message":     fmt.Sprintf("[%s] %s: %s", incident.Severity, incident.Title, change),
+	})
+}
+
+// WatcherNotifier delivers watched-incident updates to each watcher's
+// preferred target
+type WatcherNotifier struct {
+	db     *gorm.DB
+	router *NotificationRouter
+}
+
+// NewWatcherNotifier creates a watcher notifier
+func NewWatcherNotifier(db *gorm.DB, router *NotificationRouter) *WatcherNotifier {
+	return &WatcherNotifier{db: db, router: router}
+}
+
+// Dispatch handles a TopicNotifyWatchers message. Delivery failures are
+// recorded per target rather than retried, so one bad target doesn't
+// re-notify everyone else.
+func (w *WatcherNotifier) Dispatch(payload map[string]interface{}) error {
+	incidentID := getStringParam(payload, "incident_id", "")
+	actor := getStringParam(payload, "actor", "")
+
+	var watchers []models.IncidentWatcher
+	if err := w.db.Where("incident_id = ? AND user <> ?", incidentID, actor).Find(&watchers).Error; err != nil {
+		return fmt.Errorf("failed to fetch watchers: %w", err)
+	}
+	if len(watchers) == 0 {
+		return nil
+	}
+
+	users := make([]string, len(watchers))
+	for i, watcher := range watchers {
+		users[i] = watcher.User
+	}
+	var prefs []models.UserPreference
+	if err := w.db.Where("user IN ?", users).Find(&prefs).Error; err != nil {
+		return fmt.Errorf("failed to fetch notification preferences: %w", err)
+	}
+	targets := make(map[string]string, len(prefs))
+	for _, pref := range prefs {
+		targets[pref.User] = pref.NotifyTarget
+	}
+
+	n := Notification{
+		IncidentID: incidentID,
+		Message:    getStringParam(payload, "message", "Incident updated"),
+	}
+	for _, user := range users {
+		target := targets[user]
+		if target == "" {
+			log.Printf("Watcher %s of incident %s has no notification target set", user, incidentID)
+			continue
+		}
+		w.router.SendDirect(n, target)
+	}
+	return nil
+}
+
+// DispatchUser handles a TopicNotifyUser message: the "user" gets it at
+// their notification target. A message without a user, or whose user has no
+// reachable target, goes through the incident's notification routes.
+func (w *WatcherNotifier) DispatchUser(payload map[string]interface{}) error {
+	n := NotificationFromParams(payload)
+	if user := getStringParam(payload, "user", ""); user != "" {
+		var pref models.UserPreference
+		if err := w.db.Where("user = ?", user).Limit(1).Find(&pref).Error; err != nil {
+			return fmt.Errorf("failed to fetch notification preferences: %w", err)
+		}
+		if pref.NotifyTarget != "" && w.router.SendDirect(n, pref.NotifyTarget) {
+			return nil
+		}
+		log.Printf("%s has no reachable notification target for incident %s; using routes", user, n.IncidentID)
+	}
+	return w.router.Dispatch(n)
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// defaultStatuses is the built-in lifecycle, used by categories without a
+// workflow when the file defines no default
+var defaultStatuses = []string{
+	string(models.StatusOpen),
+	string(models.StatusInvestigating),
+	string(models.StatusContained),
+	string(models.StatusResolved),
+}
+
+// workflowFields reads the incident fields a transition can require
+var workflowFields = map[string]func(*models.Incident) string{
+	"resolution": func(i *models.Incident) string { return i.Resolution },
+	"assigned_to": func(i *models.Incident) string {
+		if i.AssignedTo == nil {
+			return ""
+		}
+		return *i.AssignedTo
+	},
+	"team":  func(i *models.Incident) string { return i.Team },
+	"notes": func(i *models.Incident) string { return i.Notes },
+}
+
+// WorkflowSpec is the YAML layout of one workflow. Transitions maps a status
+// to the statuses it may move to; statuses not listed may move anywhere.
+// Required maps a target status, or a "from->to" pair, to the incident
+// fields that must be set to enter it.
+type WorkflowSpec struct {
+	Statuses    []string            `yaml:"statuses" json:"statuses"`
+	Transitions map[string][]string `yaml:"transitions" json:"transitions,omitempty"`
+	Required    map[string][]string `yaml:"required" json:"required,omitempty"`
+}
+
+// WorkflowsFile is the YAML layout of the workflows file
+type WorkflowsFile struct {
+	Default    *WorkflowSpec           `yaml:"default" json:"default"`
+	Categories map[string]WorkflowSpec `yaml:"categories" json:"categories"`
+}
+
+// WorkflowError explains why a status change was refused
+type WorkflowError struct {
+	Category string   `json:"category"`
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Allowed  []string `json:"allowed,omitempty"`
+	Missing  []string `json:"missing_fields,omitempty"`
+}
+
+func (e *WorkflowError) Error() string {
+	switch {
+	case len(e.Missing) > 0:
+		return fmt.Sprintf("moving to %s requires %s", e.To, strings.Join(e.Missing, ", "))
+	case e.From == "":
+		return fmt.Sprintf("status %s is not part of the workflow for category %q", e.To, e.Category)
+	default:
+		return fmt.Sprintf("cannot move from %s to %s in the workflow for category %q", e.From, e.To, e.Category)
+	}
+}
+
+// workflow is a validated WorkflowSpec
+type workflow struct {
+	spec     WorkflowSpec
+	statuses map[string]bool
+}
+
+// Workflows holds the incident status workflows per category. A nil
+// *Workflows applies the built-in lifecycle: the four statuses, any
+// transition between them and no required fields.
+type Workflows struct {
+	file       WorkflowsFile
+	def        *workflow
+	categories map[string]*workflow
+}
+
+// LoadWorkflows reads a workflows file. Without the file every category uses
+// the built-in lifecycle.
+func LoadWorkflows(path string) (*Workflows, error) {
+	w := &Workflows{categories: make(map[string]*workflow)}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read workflows: %w", err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, &w.file); err != nil {
+			return nil, fmt.Errorf("failed to parse workflows: %w", err)
+		}
+	}
+
+	if w.file.Default == nil {
+		w.file.Default = &WorkflowSpec{Statuses: defaultStatuses}
+	}
+	if w.def, err = compileWorkflow(*w.file.Default); err != nil {
+		return nil, fmt.Errorf("invalid default workflow: %w", err)
+	}
+	for category, spec := range w.file.Categories {
+		if w.categories[category], err = compileWorkflow(spec); err != nil {
+			return nil, fmt.Errorf("invalid workflow for category %s: %w", category, err)
+		}
+	}
+	return w, nil
+}
+
+// compileWorkflow validates a workflow spec. Every workflow starts at open
+// and ends at resolved, and may only refer to its own statuses and to known
+// fields.
+func compileWorkflow(spec WorkflowSpec) (*workflow, error) {
+	if len(spec.Statuses) == 0 {
+		spec.Statuses = defaultStatuses
+	}
+	wf := &workflow{spec: spec, statuses: make(map[string]bool, len(spec.Statuses))}
+	for _, status := range spec.Statuses {
+		wf.statuses[status] = true
+	}
+	for _, required := range []models.IncidentStatus{models.StatusOpen, models.StatusResolved} {
+		if !wf.statuses[string(required)] {
+			return nil, fmt.Errorf("statuses must include %s", required)
+		}
+	}
+	for from, targets := range spec.Transitions {
+		if !wf.statuses[from] {
+			return nil, fmt.Errorf("transition from unknown status %s", from)
+		}
+		for _, to := range targets {
+			if !wf.statuses[to] {
+				return nil, fmt.Errorf("transition from %s to unknown status %s", from, to)
+			}
+		}
+	}
+	required := make(map[string][]string, len(spec.Required))
+	for key, fields := range spec.Required {
+		statuses := strings.Split(key, "->")
+		for i, status := range statuses {
+			statuses[i] = strings.TrimSpace(status)
+			if !wf.statuses[statuses[i]] {
+				return nil, fmt.Errorf("required fields for unknown status %s", statuses[i])
+			}
+		}
+		required[strings.Join(statuses, "->")] = fields
+		for _, field := range fields {
+			if workflowFields[field] == nil {
+				return nil, fmt.Errorf("unknown required field %s (want resolution, assigned_to, team or notes)", field)
+			}
+		}
+	}
+	wf.spec.Required = required
+	return wf, nil
+}
+
+// forCategory returns the workflow governing a category
+func (w *Workflows) forCategory(category string) *workflow {
+	if wf, ok := w.categories[category]; ok {
+		return wf
+	}
+	return w.def
+}
+
+// File returns the effective workflows, with the default filled in
+func (w *Workflows) File() WorkflowsFile {
+	if w == nil {
+		return WorkflowsFile{Default: &WorkflowSpec{Statuses: defaultStatuses}, Categories: map[string]WorkflowSpec{}}
+	}
+	return w.file
+}
+
+// CheckTransition reports whether the incident, with its changes applied,
+// may move from status from to its current status. Unchanged statuses are
+// always allowed.
+func (w *Workflows) CheckTransition(incident *models.Incident, from models.IncidentStatus) error {
+	to := string(incident.Status)
+	if to == string(from) {
+		return nil
+	}
+	wf := defaultWorkflow
+	if w != nil {
+		wf = w.forCategory(incident.Category)
+	}
+	if !wf.statuses[to] {
+		return &WorkflowError{Category: incident.Category, To: to, Allowed: wf.spec.Statuses}
+	}
+	if targets, ok := wf.spec.Transitions[string(from)]; ok && wf.statuses[string(from)] {
+		allowed := false
+		for _, target := range targets {
+			allowed = allowed || target == to
+		}
+		if !allowed {
+			return &WorkflowError{Category: incident.Category, From: string(from), To: to, Allowed: targets}
+		}
+	}
+
+	var missing []string
+	for _, key := range []string{to, string(from) + "->" + to} {
+		for _, field := range wf.spec.Required[key] {
+			if strings.TrimSpace(workflowFields[field](incident)) == "" {
+				missing = append(missing, field)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return &WorkflowError{Category: incident.Category, From: string(from), To: to, Missing: missing}
+	}
+	return nil
+}
+
+// defaultWorkflow is the built-in lifecycle
+var defaultWorkflow, _ = compileWorkflow(WorkflowSpec{Statuses: defaultStatuses})
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"gorm.io/gorm"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/database"
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// txKey is the context key of a transaction the GORM stores join
+type txKey struct{}
+
+// WithTx returns a context under which the GORM stores run in tx, so their
+// writes commit or roll back with the caller's other writes in it. Saves
+// going through a batch writer don't join it.
+func WithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFrom returns the GORM transaction ctx carries, for writes the stores
+// don't cover to join it
+func TxFrom(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return tx, ok
+}
+
+// conn returns the transaction ctx carries, or db
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := TxFrom(ctx); ok {
+		return tx
+	}
+	return db.WithContext(ctx)
+}
+
+// notFound maps GORM's missing record error to ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// GormEvents is the EventStore of a GORM database
+type GormEvents struct {
+	db     *gorm.DB
+	writer *database.BatchWriter
+}
+
+// NewGormEvents creates an event store on db. Saves go through writer,
+// batching them with concurrent ones, when it isn't nil.
+func NewGormEvents(db *gorm.DB, writer *database.BatchWriter) *GormEvents {
+	return &GormEvents{db: db, writer: writer}
+}
+
+// Save implements EventStore
+func (s *GormEvents) Save(ctx context.Context, events ...*models.Event) error {
+	if s.writer != nil {
+		values := make([]interface{}, len(events))
+		for i, event := range events {
+			values[i] = event
+		}
+		return s.writer.WriteAll(values...)
+	}
+	return conn(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		for _, event := range events {
+			if err := tx.Save(event).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Get implements EventStore
+func (s *GormEvents) Get(ctx context.Context, eventID string) (*models.Event, error) {
+	var event models.Event
+	if err := conn(ctx, s.db).First(&event, "event_id = ?", eventID).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &event, nil
+}
+
+// MarkProcessed implements EventStore
+func (s *GormEvents) MarkProcessed(ctx context.Context, event *models.Event, trace *models.DetectionTrace) error {
+	return conn(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(event).Select("normalized", "processed_at").Updates(event).Error; err != nil {
+			return err
+		}
+		if trace == nil {
+			return nil
+		}
+		return tx.Create(trace).Error
+	})
+}
+
+// GormIncidents is the IncidentStore of a GORM database
+type GormIncidents struct {
+	db *gorm.DB
+}
+
+// NewGormIncidents creates an incident store on db
+func NewGormIncidents(db *gorm.DB) *GormIncidents {
+	return &GormIncidents{db: db}
+}
+
+// Create implements IncidentStore
+func (s *GormIncidents) Create(ctx context.Context, incident *models.Incident) error {
+	return conn(ctx, s.db).Create(incident).Error
+}
+
+// Get implements IncidentStore
+func (s *GormIncidents) Get(ctx context.Context, incidentID string) (*models.Incident, error) {
+	var incident models.Incident
+	if err := conn(ctx, s.db).First(&incident, "incident_id = ?", incidentID).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &incident, nil
+}
+
+// Save implements IncidentStore
+func (s *GormIncidents) Save(ctx context.Context, incident *models.Incident) error {
+	return conn(ctx, s.db).Save(incident).Error
+}
+
+// RunInTx implements IncidentStore
+func (s *GormIncidents) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return conn(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		return fn(WithTx(ctx, tx))
+	})
+}
+
+// GormActionLogs is the ActionLogStore of a GORM database
+type GormActionLogs struct {
+	db     *gorm.DB
+	writer *database.BatchWriter
+}
+
+// NewGormActionLogs creates an action log store on db. Asynchronous saves
+// go through writer when it isn't nil.
+func NewGormActionLogs(db *gorm.DB, writer *database.BatchWriter) *GormActionLogs {
+	return &GormActionLogs{db: db, writer: writer}
+}
+
+// Save implements ActionLogStore
+func (s *GormActionLogs) Save(ctx context.Context, entry *models.ActionLog) error {
+	return conn(ctx, s.db).Save(entry).Error
+}
+
+// SaveAsync implements ActionLogStore
+func (s *GormActionLogs) SaveAsync(ctx context.Context, entry *models.ActionLog) {
+	if s.writer != nil {
+		s.writer.WriteAsync(entry)
+		return
+	}
+	if err := s.Save(ctx, entry); err != nil {
+		log.Printf("Action log: failed to save %s: %v", entry.ActionID, err)
+	}
+}
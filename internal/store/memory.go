@@ -0,0 +1,178 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// The memory stores keep records in maps, for tests and tools that don't
+// need a database. They copy records in and out, so callers can't change a
+// stored record without saving it, and assign IDs and defaults through the
+// models' BeforeCreate hooks as GORM does.
+
+// MemoryEvents is an in-memory EventStore
+type MemoryEvents struct {
+	mu     sync.Mutex
+	events map[string]models.Event
+	traces map[string][]models.DetectionTrace
+}
+
+// NewMemoryEvents creates an empty in-memory event store
+func NewMemoryEvents() *MemoryEvents {
+	return &MemoryEvents{events: make(map[string]models.Event), traces: make(map[string][]models.DetectionTrace)}
+}
+
+// Save implements EventStore
+func (s *MemoryEvents) Save(ctx context.Context, events ...*models.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range events {
+		event.BeforeCreate(nil)
+		if event.CreatedAt.IsZero() {
+			event.CreatedAt = time.Now()
+		}
+		s.events[event.EventID] = *event
+	}
+	return nil
+}
+
+// Get implements EventStore
+func (s *MemoryEvents) Get(ctx context.Context, eventID string) (*models.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event, ok := s.events[eventID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &event, nil
+}
+
+// MarkProcessed implements EventStore
+func (s *MemoryEvents) MarkProcessed(ctx context.Context, event *models.Event, trace *models.DetectionTrace) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.events[event.EventID]
+	if !ok {
+		return nil
+	}
+	stored.Normalized, stored.ProcessedAt = event.Normalized, event.ProcessedAt
+	s.events[event.EventID] = stored
+	if trace != nil {
+		s.traces[event.EventID] = append(s.traces[event.EventID], *trace)
+	}
+	return nil
+}
+
+// Traces returns the detection traces recorded for an event
+func (s *MemoryEvents) Traces(eventID string) []models.DetectionTrace {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.DetectionTrace{}, s.traces[eventID]...)
+}
+
+// Len returns how many events are stored
+func (s *MemoryEvents) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.events)
+}
+
+// MemoryIncidents is an in-memory IncidentStore
+type MemoryIncidents struct {
+	mu        sync.Mutex
+	incidents map[string]models.Incident
+}
+
+// NewMemoryIncidents creates an empty in-memory incident store
+func NewMemoryIncidents() *MemoryIncidents {
+	return &MemoryIncidents{incidents: make(map[string]models.Incident)}
+}
+
+// Create implements IncidentStore
+func (s *MemoryIncidents) Create(ctx context.Context, incident *models.Incident) error {
+	incident.BeforeCreate(nil)
+	now := time.Now()
+	if incident.CreatedAt.IsZero() {
+		incident.CreatedAt = now
+	}
+	incident.UpdatedAt = now
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.incidents[incident.IncidentID] = *incident
+	return nil
+}
+
+// Get implements IncidentStore
+func (s *MemoryIncidents) Get(ctx context.Context, incidentID string) (*models.Incident, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	incident, ok := s.incidents[incidentID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &incident, nil
+}
+
+// Save implements IncidentStore
+func (s *MemoryIncidents) Save(ctx context.Context, incident *models.Incident) error {
+	incident.UpdatedAt = time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.incidents[incident.IncidentID] = *incident
+	return nil
+}
+
+// RunInTx implements IncidentStore. The memory store has no transactions:
+// writes fn made before failing are kept.
+func (s *MemoryIncidents) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// MemoryActionLogs is an in-memory ActionLogStore
+type MemoryActionLogs struct {
+	mu      sync.Mutex
+	entries map[string]models.ActionLog
+}
+
+// NewMemoryActionLogs creates an empty in-memory action log store
+func NewMemoryActionLogs() *MemoryActionLogs {
+	return &MemoryActionLogs{entries: make(map[string]models.ActionLog)}
+}
+
+// Save implements ActionLogStore
+func (s *MemoryActionLogs) Save(ctx context.Context, entry *models.ActionLog) error {
+	entry.BeforeCreate(nil)
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[entry.ActionID] = *entry
+	return nil
+}
+
+// SaveAsync implements ActionLogStore; the memory store saves immediately
+func (s *MemoryActionLogs) SaveAsync(ctx context.Context, entry *models.ActionLog) {
+	s.Save(ctx, entry)
+}
+
+// Get returns an entry, or ErrNotFound
+func (s *MemoryActionLogs) Get(actionID string) (*models.ActionLog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[actionID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &entry, nil
+}
+
+// Len returns how many entries are stored
+func (s *MemoryActionLogs) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
@@ -0,0 +1,62 @@
+// Package store defines the storage the services and handlers depend on, so
+// they can run against the GORM implementations in production and the
+// in-memory ones in tests, or another backend, without holding a *gorm.DB.
+//
+// Queries that are specific to one feature still go through *gorm.DB; the
+// stores cover the records most of the code base reads and writes. Such
+// writes join a store transaction through TxFrom, and are left out under the
+// memory stores, which have no database.
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gixxerblade/incident-response-mvp/internal/models"
+)
+
+// ErrNotFound is returned when a record doesn't exist
+var ErrNotFound = errors.New("not found")
+
+// EventStore stores security events
+type EventStore interface {
+	// Save stores new or changed events, returning once all are committed
+	Save(ctx context.Context, events ...*models.Event) error
+	// Get returns an event, or ErrNotFound
+	Get(ctx context.Context, eventID string) (*models.Event, error)
+	// MarkProcessed records that detection finished with an event: its
+	// processed_at and the normalized payload enrichment may have changed,
+	// with trace, when not nil, in the same write. Other columns are left
+	// alone, so a payload moved to cold storage meanwhile isn't written back.
+	MarkProcessed(ctx context.Context, event *models.Event, trace *models.DetectionTrace) error
+}
+
+// IncidentStore stores incidents
+type IncidentStore interface {
+	// Create stores a new incident, assigning its ID
+	Create(ctx context.Context, incident *models.Incident) error
+	// Get returns an incident, or ErrNotFound
+	Get(ctx context.Context, incidentID string) (*models.Incident, error)
+	// Save stores every field of an existing incident
+	Save(ctx context.Context, incident *models.Incident) error
+	// RunInTx runs fn in a transaction: the store's writes under the context
+	// fn is given commit together, or not at all when fn returns an error
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// ActionLogStore stores the log of executed actions
+type ActionLogStore interface {
+	// Save stores a new or changed entry, returning once it is committed
+	Save(ctx context.Context, entry *models.ActionLog) error
+	// SaveAsync stores an entry without waiting; failures are logged
+	SaveAsync(ctx context.Context, entry *models.ActionLog)
+}
+
+var (
+	_ EventStore     = (*GormEvents)(nil)
+	_ EventStore     = (*MemoryEvents)(nil)
+	_ IncidentStore  = (*GormIncidents)(nil)
+	_ IncidentStore  = (*MemoryIncidents)(nil)
+	_ ActionLogStore = (*GormActionLogs)(nil)
+	_ ActionLogStore = (*MemoryActionLogs)(nil)
+)